@@ -1,6 +1,7 @@
 #!/bin/bash
 
-release=("badger"  "core"  "etcd"  "go-redis"  "nats"  "nuts"  "olric"  "otter"  "redis"  "simplefs")
+release=("azureblob"  "badger"  "cassandra"  "core"  "dynamodb"  "etcd"  "gcs"  "go-redis"  "memcached"  "mongo"  "nats"  "nuts"  "olric"  "otter"  "postgres"  "redis"  "s3"  "simplefs"  "sqlite")
+submodules=("core/metrics")
 
 IFS= read -r -d '' tpl <<EOF
 name: Tag submodules on release
@@ -49,4 +50,20 @@ for i in ${!release[@]}; do
 EOF
   workflow+="$tpl"
 done
+for submodule in ${submodules[@]}; do
+  IFS= read -d '' tpl <<EOF
+      -
+        name: Create $submodule tag
+        uses: actions/github-script@v7
+        with:
+          script: |
+            github.rest.git.createRef({
+              owner: context.repo.owner,
+              repo: context.repo.repo,
+              ref: 'refs/tags/$submodule/\${{ github.ref_name }}',
+              sha: context.sha
+            })
+EOF
+  workflow+="$tpl"
+done
 echo "${workflow%$'\n'}" >  "$( dirname -- "$0"; )/release.yml"
\ No newline at end of file
@@ -0,0 +1,896 @@
+// Package azureblob stores the cache entries as block blobs in an Azure Blob Storage container.
+//
+// Azure Blob Storage has no per-blob time to live, the expiry is stored in the expires_at blob
+// metadata and checked lazily on read, the expired blobs are treated as misses and removed when
+// delete_expired is enabled. The values larger than a single upload are staged as blocks by the
+// client, the block_size and concurrency options tune the staging.
+package azureblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/darkweak/storages/core"
+)
+
+// The metadata names must be valid C# identifiers, an hyphen isn't allowed.
+const expiresMetadata = "expires_at"
+
+// maxListResults is the maximum number of blobs Azure returns per listing page.
+const maxListResults = 5000
+
+// AzureBlob provider type.
+type AzureBlob struct {
+	*azblob.Client
+	stale         time.Duration
+	logger        core.Logger
+	mapper        core.Mapper
+	container     *container.Client
+	containerName string
+	compression   string
+	clock         core.Clock
+	deleteExpired bool
+	blockSize     int64
+	concurrency   uint16
+}
+
+// Factory function create new AzureBlob instance.
+func Factory(azureConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if azureConfiguration.Validate {
+		if err := core.ValidateConfig(azureConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Azure Blob configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(azureConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, azureConfiguration, logger)
+}
+
+func factory(azureConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	endpoint := azureConfiguration.URL
+	containerName := "souin"
+	accountName, accountKey, connectionString := "", "", ""
+	blockSize, concurrency := int64(0), uint64(0)
+	deleteExpired := false
+
+	if ac, ok := azureConfiguration.Configuration.(map[string]interface{}); ok && ac != nil {
+		if v, found := ac["endpoint"]; found && v != nil {
+			endpoint = fmt.Sprint(v)
+		}
+
+		if v, found := ac["container"]; found && v != nil {
+			containerName = fmt.Sprint(v)
+		}
+
+		if v, found := ac["account_name"]; found && v != nil {
+			accountName = fmt.Sprint(v)
+		}
+
+		if v, found := ac["account_key"]; found && v != nil {
+			accountKey = fmt.Sprint(v)
+		}
+
+		if v, found := ac["connection_string"]; found && v != nil {
+			connectionString = fmt.Sprint(v)
+		}
+
+		if v, found := ac["block_size"]; found && v != nil {
+			blockSize, _ = strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		}
+
+		if v, found := ac["concurrency"]; found && v != nil {
+			concurrency, _ = strconv.ParseUint(fmt.Sprint(v), 10, 16)
+		}
+
+		if v, found := ac["delete_expired"]; found && v != nil {
+			deleteExpired, _ = strconv.ParseBool(fmt.Sprint(v))
+		}
+	}
+
+	if endpoint == "" && accountName != "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+	}
+
+	var (
+		client *azblob.Client
+		err    error
+	)
+
+	switch {
+	case connectionString != "":
+		client, err = azblob.NewClientFromConnectionString(connectionString, nil)
+	case accountName != "" && accountKey != "":
+		var credential *azblob.SharedKeyCredential
+
+		credential, err = azblob.NewSharedKeyCredential(accountName, accountKey)
+		if err == nil {
+			client, err = azblob.NewClientWithSharedKeyCredential(endpoint, credential, nil)
+		}
+	default:
+		// The endpoint may embed a SAS token or target a public container.
+		client, err = azblob.NewClientWithNoCredential(endpoint, nil)
+	}
+
+	if err != nil {
+		logger.Error("Impossible to instantiate the AzureBlob client.", err)
+
+		return nil, err
+	}
+
+	handle := client.ServiceClient().NewContainerClient(containerName)
+
+	_, err = handle.Create(context.Background(), nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		logger.Errorf("Impossible to access the AzureBlob container %s, %v", containerName, err)
+
+		return nil, err
+	}
+
+	return &AzureBlob{
+		Client:        client,
+		stale:         stale,
+		logger:        logger,
+		mapper:        core.ConfiguredMapper(azureConfiguration),
+		container:     handle,
+		containerName: containerName,
+		compression:   core.ConfiguredCompression(azureConfiguration, logger),
+		clock:         core.ClockOrDefault(azureConfiguration.Clock),
+		deleteExpired: deleteExpired,
+		blockSize:     blockSize,
+		concurrency:   uint16(concurrency),
+	}, nil
+}
+
+// expiresAt returns the expiry stored in the blob metadata, the zero time means no expiration.
+// The service returns the metadata names with the HTTP header case so they are compared case-insensitively.
+func expiresAt(metadata map[string]*string) time.Time {
+	for name, value := range metadata {
+		if !strings.EqualFold(name, expiresMetadata) || value == nil {
+			continue
+		}
+
+		expiry, err := strconv.ParseInt(*value, 10, 64)
+		if err != nil || expiry == 0 {
+			return time.Time{}
+		}
+
+		return time.Unix(0, expiry)
+	}
+
+	return time.Time{}
+}
+
+func (provider *AzureBlob) expired(metadata map[string]*string) bool {
+	expiry := expiresAt(metadata)
+
+	return !expiry.IsZero() && !provider.clock.Now().Before(expiry)
+}
+
+// expiryMetadata returns the metadata storing the expiry, a non-positive duration means no expiration.
+func (provider *AzureBlob) expiryMetadata(duration time.Duration) map[string]*string {
+	expiry := "0"
+	if duration > 0 {
+		expiry = strconv.FormatInt(provider.clock.Now().Add(duration).UnixNano(), 10)
+	}
+
+	return map[string]*string{expiresMetadata: &expiry}
+}
+
+func isNotFound(err error) bool {
+	return bloberror.HasCode(err, bloberror.BlobNotFound)
+}
+
+// isConditionFailed reports the writes rejected by their access conditions, an existing blob with
+// If-None-Match: * or another ETag with If-Match.
+func isConditionFailed(err error) bool {
+	return bloberror.HasCode(err, bloberror.ConditionNotMet, bloberror.BlobAlreadyExists)
+}
+
+func ifMatch(etag *azcore.ETag) *blob.AccessConditions {
+	return &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: etag}}
+}
+
+func ifNotExists() *blob.AccessConditions {
+	etag := azcore.ETagAny
+
+	return &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &etag}}
+}
+
+// expire treats the blob as a miss and removes it if enabled, only if it hasn't been rewritten in between.
+func (provider *AzureBlob) expire(ctx context.Context, key string, etag *azcore.ETag) {
+	if !provider.deleteExpired {
+		return
+	}
+
+	_, err := provider.container.NewBlobClient(key).Delete(ctx, &blob.DeleteOptions{AccessConditions: ifMatch(etag)})
+	if err != nil && !isNotFound(err) && !isConditionFailed(err) {
+		provider.logger.Errorf("Impossible to delete the expired key %s in AzureBlob, %v", key, err)
+	}
+}
+
+// properties returns the blob properties, core.ErrKeyNotFound when missing.
+func (provider *AzureBlob) properties(ctx context.Context, key string) (blob.GetPropertiesResponse, error) {
+	properties, err := provider.container.NewBlobClient(key).GetProperties(ctx, nil)
+	if isNotFound(err) {
+		return properties, core.ErrKeyNotFound
+	}
+
+	return properties, err
+}
+
+// Name returns the storer name.
+func (provider *AzureBlob) Name() string {
+	return "AZUREBLOB"
+}
+
+// Uuid returns an unique identifier.
+func (provider *AzureBlob) Uuid() string {
+	return fmt.Sprintf("%s-%s-%s", provider.URL(), provider.containerName, provider.stale)
+}
+
+// list calls fn with the name and the properties of each blob matching the prefix until fn returns false.
+func (provider *AzureBlob) list(ctx context.Context, options *container.ListBlobsFlatOptions, fn func(*container.BlobItem) bool) error {
+	pager := provider.container.NewListBlobsFlatPager(options)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil && !fn(item) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// MapKeys method returns the map of existing keys, each value is read with its own request.
+func (provider *AzureBlob) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	err := provider.list(context.Background(), &container.ListBlobsFlatOptions{Prefix: &prefix}, func(item *container.BlobItem) bool {
+		if !core.ListedKey(prefix, *item.Name) {
+			return true
+		}
+
+		if value, err := provider.GetContext(context.Background(), *item.Name); err == nil {
+			k, _ := strings.CutPrefix(*item.Name, prefix)
+			keys[k] = string(value)
+		}
+
+		return true
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in AzureBlob, %v", err)
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *AzureBlob) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor.
+// The cursor is the opaque listing marker returned by Azure, a page may hold less keys than the limit.
+// The expired blobs are listed until they are read.
+func (provider *AzureBlob) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+	options := &container.ListBlobsFlatOptions{Prefix: &prefix}
+
+	if cursor != "" {
+		options.Marker = &cursor
+	}
+
+	if limit > 0 {
+		options.MaxResults = to.Ptr(int32(min(limit, maxListResults)))
+	}
+
+	pager := provider.container.NewListBlobsFlatPager(options)
+
+	for pager.More() {
+		page, err := pager.NextPage(context.Background())
+		if err != nil {
+			provider.logger.Errorf("Impossible to scan the keys in AzureBlob, %v", err)
+
+			return keys, ""
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil && core.ListedKey(prefix, *item.Name) {
+				keys = append(keys, *item.Name)
+			}
+		}
+
+		if limit > 0 {
+			if page.NextMarker != nil {
+				next = *page.NextMarker
+			}
+
+			break
+		}
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *AzureBlob) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *AzureBlob) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in AzureBlob: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *AzureBlob) GetContext(ctx context.Context, key string) ([]byte, error) {
+	reader, err := provider.open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	return io.ReadAll(reader)
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *AzureBlob) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// open returns the blob body if it exists and isn't expired, the download returns the metadata so a single request is sent.
+func (provider *AzureBlob) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	response, err := provider.container.NewBlobClient(key).DownloadStream(ctx, nil)
+	if isNotFound(err) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.expired(response.Metadata) {
+		_ = response.Body.Close()
+		provider.expire(ctx, key, response.ETag)
+
+		return nil, core.ErrKeyNotFound
+	}
+
+	return response.Body, nil
+}
+
+// GetMany method returns the values of the existing keys, each key is read with its own request.
+func (provider *AzureBlob) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value, err := provider.GetContext(context.Background(), key); err == nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *AzureBlob) GetTTL(key string) (time.Duration, bool) {
+	properties, err := provider.properties(context.Background(), key)
+	if err != nil || provider.expired(properties.Metadata) {
+		return 0, false
+	}
+
+	if expiry := expiresAt(properties.Metadata); !expiry.IsZero() {
+		return expiry.Sub(provider.clock.Now()), true
+	}
+
+	return core.NoExpiration, true
+}
+
+// Exists method will check the key in AzureBlob provider using the blob properties.
+func (provider *AzureBlob) Exists(key string) bool {
+	properties, err := provider.properties(context.Background(), key)
+
+	return err == nil && !provider.expired(properties.Metadata)
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *AzureBlob) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *AzureBlob) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in AzureBlob", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *AzureBlob) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into AzureBlob, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in AzureBlob, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in AzureBlob: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in AzureBlob", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// write uploads the value with the expiry metadata under the access conditions.
+// The values larger than blockblob.MaxUploadBlobBytes are staged as blocks committed once all uploaded.
+func (provider *AzureBlob) write(ctx context.Context, key string, value []byte, duration time.Duration, conditions *blob.AccessConditions) error {
+	contentType := "application/octet-stream"
+
+	_, err := provider.container.NewBlockBlobClient(key).UploadBuffer(ctx, value, &blockblob.UploadBufferOptions{
+		BlockSize:        provider.blockSize,
+		Concurrency:      provider.concurrency,
+		HTTPHeaders:      &blob.HTTPHeaders{BlobContentType: &contentType},
+		Metadata:         provider.expiryMetadata(duration),
+		AccessConditions: conditions,
+	})
+
+	return err
+}
+
+// Set method will store the response in AzureBlob provider.
+func (provider *AzureBlob) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response unless the context is done before the upload completes.
+func (provider *AzureBlob) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	err := provider.write(ctx, key, value, duration, nil)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into AzureBlob, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in AzureBlob provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *AzureBlob) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in AzureBlob provider only if the key doesn't exist yet.
+// The upload is conditioned on the blob absence, or on the expired blob ETag to replace it atomically.
+func (provider *AzureBlob) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	var conditions *blob.AccessConditions
+
+	properties, err := provider.properties(context.Background(), key)
+
+	switch {
+	case err == nil && !provider.expired(properties.Metadata):
+		return false, nil
+	case err == nil:
+		conditions = ifMatch(properties.ETag)
+	case errors.Is(err, core.ErrKeyNotFound):
+		conditions = ifNotExists()
+	default:
+		provider.logger.Errorf("Impossible to set the key %s if not exists into AzureBlob, %v", key, err)
+
+		return false, err
+	}
+
+	err = provider.write(context.Background(), key, value, duration, conditions)
+	if isConditionFailed(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into AzureBlob, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in AzureBlob provider only if the stored bytes equal old.
+// The upload is conditioned on the read blob ETag and retried when a concurrent writer updated the key in between.
+func (provider *AzureBlob) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	for {
+		swapped, conflict, err := provider.compareAndSwap(key, old, value, duration)
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into AzureBlob, %v", key, err)
+
+			return false, err
+		}
+
+		if !conflict {
+			return swapped, nil
+		}
+	}
+}
+
+func (provider *AzureBlob) compareAndSwap(key string, old, value []byte, duration time.Duration) (bool, bool, error) {
+	response, err := provider.container.NewBlobClient(key).DownloadStream(context.Background(), nil)
+
+	switch {
+	case isNotFound(err):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	}
+
+	if provider.expired(response.Metadata) {
+		_ = response.Body.Close()
+
+		return false, false, nil
+	}
+
+	current, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+
+	if err != nil {
+		return false, false, err
+	}
+
+	if !core.SwapMatches(current, old) {
+		return false, false, nil
+	}
+
+	err = provider.write(context.Background(), key, value, duration, ifMatch(response.ETag))
+	if isConditionFailed(err) {
+		return false, true, nil
+	}
+
+	return err == nil, false, err
+}
+
+// Increment method will add delta to the counter stored in AzureBlob provider.
+// The upload is conditioned on the read blob ETag and retried when a concurrent writer updated the counter in between.
+func (provider *AzureBlob) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		value, updated, err := provider.increment(key, delta, duration)
+		if err != nil {
+			if !errors.Is(err, core.ErrNotCounter) {
+				provider.logger.Errorf("Impossible to increment the key %s into AzureBlob, %v", key, err)
+			}
+
+			return 0, err
+		}
+
+		if updated {
+			return value, nil
+		}
+	}
+}
+
+func (provider *AzureBlob) increment(key string, delta int64, duration time.Duration) (int64, bool, error) {
+	var (
+		current    []byte
+		conditions *blob.AccessConditions
+	)
+
+	response, err := provider.container.NewBlobClient(key).DownloadStream(context.Background(), nil)
+
+	switch {
+	case isNotFound(err):
+		conditions = ifNotExists()
+	case err != nil:
+		return 0, false, err
+	default:
+		conditions = ifMatch(response.ETag)
+
+		if !provider.expired(response.Metadata) {
+			current, err = io.ReadAll(response.Body)
+		}
+
+		_ = response.Body.Close()
+
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, false, err
+	}
+
+	err = provider.write(context.Background(), key, encoded, duration, conditions)
+	if isConditionFailed(err) {
+		return 0, false, nil
+	}
+
+	return value, err == nil, err
+}
+
+// Decrement method will subtract delta from the counter stored in AzureBlob provider.
+func (provider *AzureBlob) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in AzureBlob provider.
+func (provider *AzureBlob) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into AzureBlob, %v", key, err)
+	}
+
+	return err
+}
+
+type blobReader struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (r *blobReader) Close() error {
+	_ = r.ReadCloser.Close()
+
+	return r.body.Close()
+}
+
+// GetStream method returns a reader decompressing lazily the blob stored by SetStream while it's downloaded.
+func (provider *AzureBlob) GetStream(key string) (io.ReadCloser, error) {
+	body, err := provider.open(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := core.DecompressStream(body)
+	if err != nil {
+		_ = body.Close()
+
+		return nil, err
+	}
+
+	return &blobReader{ReadCloser: reader, body: body}, nil
+}
+
+// Touch method will update the time to live of the key by updating the blob metadata.
+// The update is conditioned on the checked ETag to never revive a concurrently expired blob.
+func (provider *AzureBlob) Touch(key string, duration time.Duration) error {
+	properties, err := provider.properties(context.Background(), key)
+	if err == nil && provider.expired(properties.Metadata) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.container.NewBlobClient(key).SetMetadata(
+		context.Background(),
+		provider.expiryMetadata(duration),
+		&blob.SetMetadataOptions{AccessConditions: ifMatch(properties.ETag)},
+	)
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into AzureBlob, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in AzureBlob provider if exists corresponding to key param.
+func (provider *AzureBlob) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	_, err := provider.container.NewBlobClient(key).Delete(context.Background(), nil)
+	if err != nil && !isNotFound(err) {
+		provider.logger.Errorf("Impossible to delete the key %s in AzureBlob, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in AzureBlob provider if exists corresponding to the prefix pattern param.
+// The blob batch API requires the OAuth credentials, the listed blobs are deleted one by one.
+func (provider *AzureBlob) DeleteMany(pattern string) {
+	prefix := core.KeyPrefix(pattern)
+	keys := []string{}
+
+	// The blobs are deleted once listed to not invalidate the listing marker.
+	err := provider.list(context.Background(), &container.ListBlobsFlatOptions{Prefix: &prefix}, func(item *container.BlobItem) bool {
+		keys = append(keys, *item.Name)
+
+		return true
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in AzureBlob, %v", err)
+	}
+
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+}
+
+// DeleteKeys method will delete the keys in AzureBlob provider one by one, the missing keys are ignored.
+func (provider *AzureBlob) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in AzureBlob provider.
+func (provider *AzureBlob) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in AzureBlob provider.
+func (provider *AzureBlob) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in AzureBlob provider.
+func (provider *AzureBlob) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in AzureBlob provider without altering it.
+func (provider *AzureBlob) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in AzureBlob provider and its metadata under a parallel key.
+func (provider *AzureBlob) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in AzureBlob provider, core.ErrKeyNotFound if none.
+func (provider *AzureBlob) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number and the size of the blobs, Azure doesn't expose them so the container is listed.
+// The expired blobs are counted until they are read.
+func (provider *AzureBlob) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+	now := provider.clock.Now()
+
+	err := provider.list(context.Background(), nil, func(item *container.BlobItem) bool {
+		stats.KeyCount++
+
+		if item.Properties == nil {
+			return true
+		}
+
+		if item.Properties.ContentLength != nil {
+			stats.ApproxSizeBytes += *item.Properties.ContentLength
+		}
+
+		if item.Properties.LastModified != nil {
+			stats.OldestEntryAge = max(stats.OldestEntryAge, now.Sub(*item.Properties.LastModified))
+		}
+
+		return true
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in AzureBlob, %v", err)
+	}
+
+	return stats, err
+}
+
+// Ping method will check the AzureBlob container exists.
+func (provider *AzureBlob) Ping(ctx context.Context) error {
+	_, err := provider.container.GetProperties(ctx, nil)
+
+	return err
+}
+
+// Export method will stream every key of the AzureBlob provider paginating the scanned keys.
+func (provider *AzureBlob) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in AzureBlob provider by batches.
+func (provider *AzureBlob) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *AzureBlob) Init() error {
+	return nil
+}
+
+// Reset method will delete every blob of the container.
+func (provider *AzureBlob) Reset() error {
+	provider.DeleteMany("*")
+
+	return nil
+}
+
+// Close method will do nothing, the AzureBlob client holds no connection to release.
+func (provider *AzureBlob) Close() error {
+	return nil
+}
@@ -0,0 +1,337 @@
+//go:build azureblob
+
+package azureblob_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/azureblob"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getAzureBlobInstance() (core.Storer, error) {
+	endpoint := os.Getenv("AZUREBLOB_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://127.0.0.1:10000/devstoreaccount1"
+	}
+
+	// The well-known Azurite development account.
+	return azureblob.Factory(core.CacheProvider{
+		URL: endpoint,
+		Configuration: map[string]interface{}{
+			"container":      "souin-test",
+			"account_name":   "devstoreaccount1",
+			"account_key":    "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==",
+			"delete_expired": true,
+		},
+	}, zap.NewNop().Sugar(), 0)
+}
+
+func TestAzureBlobConnectionFactory(t *testing.T) {
+	instance, err := getAzureBlobInstance()
+
+	if nil != err {
+		t.Errorf("Shouldn't have panic: %v", err)
+	}
+
+	if nil == instance {
+		t.Error("AzureBlob should be instanciated")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInAzureBlob(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestAzureBlob_GetRequestInCache(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestAzureBlob_SetRequestInCache_TTL(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	_ = client.Set(byteKey, []byte(baseValue), time.Second)
+
+	if ttl, found := client.GetTTL(byteKey); !found || ttl <= 0 || ttl > time.Second {
+		t.Errorf("The TTL should be between 0 and 1s, %v provided", ttl)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	if res := client.Get(byteKey); res != nil {
+		t.Errorf("Key %s should be expired, %s provided", byteKey, res)
+	}
+
+	if keys, _ := client.ScanKeys(byteKey, "", 0); len(keys) != 0 {
+		t.Errorf("The expired key %s should have been deleted on read, %v provided", byteKey, keys)
+	}
+
+	if err := client.Touch(byteKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the expired key %s should return ErrKeyNotFound, %v provided", byteKey, err)
+	}
+}
+
+func TestAzureBlob_Touch(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	_ = client.Set("TouchKey", []byte(baseValue), 20*time.Second)
+
+	if err := client.Touch("TouchKey", time.Minute); err != nil {
+		t.Errorf("Impossible to touch the key TouchKey: %v", err)
+	}
+
+	if ttl, _ := client.GetTTL("TouchKey"); ttl <= 20*time.Second {
+		t.Errorf("The TTL should have been extended, %v provided", ttl)
+	}
+
+	if res := client.Get("TouchKey"); string(res) != baseValue {
+		t.Errorf("The touched value should be kept, %s provided", res)
+	}
+}
+
+func TestAzureBlob_SetMultiLevel(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("MultiLevelKey", "MultiLevelKey", []byte(response), http.Header{}, "", time.Minute, "MultiLevelKey"); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/multi-level", nil)
+
+	fresh, _ := client.GetMultiLevel("MultiLevelKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The body should be %s, %s provided", baseValue, body)
+	}
+}
+
+func TestAzureBlob_MapKeys(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	client.DeleteMany("MAP_")
+
+	_ = client.Set("MAP_first", []byte("1"), time.Minute)
+	_ = client.Set("MAP_second", []byte("2"), time.Minute)
+	_ = client.Set("OTHER_third", []byte("3"), time.Minute)
+
+	keys := client.MapKeys("MAP_")
+	if len(keys) != 2 || keys["first"] != "1" || keys["second"] != "2" {
+		t.Errorf("Only the keys with the MAP_ prefix should be mapped, %v provided", keys)
+	}
+}
+
+func TestAzureBlob_ScanKeys(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	client.DeleteMany("ScanKey")
+
+	items := make(map[string]core.Entry, 25)
+	for i := range 25 {
+		items[fmt.Sprintf("ScanKey%02d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	_ = client.SetMany(items)
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 25 {
+			t.Fatal("The pagination should have ended")
+		}
+
+		keys, next := client.ScanKeys("ScanKey", cursor, 10)
+		if len(keys) > 10 {
+			t.Errorf("A page should hold at most 10 keys, %d provided", len(keys))
+		}
+
+		for _, key := range keys {
+			if seen[key] {
+				t.Errorf("The key %s has already been returned", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != len(items) {
+		t.Errorf("The scan should return %d keys, %d provided", len(items), len(seen))
+	}
+}
+
+func TestAzureBlob_GetSetStream(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	value := strings.Repeat(baseValue, 1000)
+
+	if err := client.SetStream("StreamKey", strings.NewReader(value), time.Minute); err != nil {
+		t.Fatalf("Impossible to stream the key StreamKey: %v", err)
+	}
+
+	reader, err := client.GetStream("StreamKey")
+	if err != nil {
+		t.Fatalf("Impossible to get the stream of the key StreamKey: %v", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	if res, _ := io.ReadAll(reader); string(res) != value {
+		t.Errorf("The streamed value should be read back, %d bytes provided", len(res))
+	}
+}
+
+func TestAzureBlob_SetNX(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	client.Delete("SetNXKey")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 10 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := client.SetNX("SetNXKey", []byte(fmt.Sprintf("worker %d", i)), time.Minute)
+			if err != nil {
+				t.Errorf("Impossible to set the key SetNXKey if not exists: %v", err)
+			}
+
+			if ok {
+				created.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", created.Load())
+	}
+
+	_ = client.Set("SetNXExpiredKey", []byte(baseValue), time.Second)
+	time.Sleep(1500 * time.Millisecond)
+
+	if ok, _ := client.SetNX("SetNXExpiredKey", []byte(baseValue), time.Minute); !ok {
+		t.Error("The expired key SetNXExpiredKey should be replaced")
+	}
+}
+
+func TestAzureBlob_Increment(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 10 {
+		t.Errorf("The 10 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 5, time.Minute); value != 5 {
+		t.Errorf("The counter should be decremented to 5, %d provided", value)
+	}
+}
+
+func TestAzureBlob_Stats(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestAzureBlob_Exists(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	_ = client.Set("ExistsKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExistsExpiredKey", []byte(baseValue), time.Second)
+
+	if !client.Exists("ExistsKey") {
+		t.Error("The key ExistsKey should exist")
+	}
+
+	if client.Exists(nonExistentKey) {
+		t.Errorf("The key %s should not exist", nonExistentKey)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if client.Exists("ExistsExpiredKey") {
+		t.Error("The expired key ExistsExpiredKey should not exist")
+	}
+}
+
+func TestAzureBlob_LargeValue(t *testing.T) {
+	client, _ := getAzureBlobInstance()
+	value := strings.Repeat(baseValue, 1<<20)
+
+	if err := client.Set("LargeKey", []byte(value), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key LargeKey: %v", err)
+	}
+
+	if res := client.Get("LargeKey"); string(res) != value {
+		t.Errorf("The large value should be read back, %d bytes provided", len(res))
+	}
+}
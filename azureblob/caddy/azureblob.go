@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/azureblob"
+	"github.com/darkweak/storages/core"
+)
+
+const moduleName = "azureblob"
+
+// AzureBlob storage.
+type AzureBlob struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(AzureBlob{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (AzureBlob) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.azureblob",
+		New: func() caddy.Module { return new(AzureBlob) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *AzureBlob) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := azureblob.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *AzureBlob) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*AzureBlob)(nil)
+	_ caddyhttp.MiddlewareHandler = (*AzureBlob)(nil)
+)
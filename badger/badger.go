@@ -4,19 +4,22 @@ package badger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
-	"regexp"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"dario.cat/mergo"
 	"github.com/darkweak/storages/core"
 	"github.com/dgraph-io/badger/v4"
-	"github.com/pierrec/lz4/v4"
 	"go.uber.org/zap"
 )
 
@@ -24,13 +27,113 @@ import (
 type Badger struct {
 	*badger.DB
 
-	stale  time.Duration
-	logger core.Logger
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	shared      *sharedDB
+	closed      atomic.Bool
+	gc          *valueLogGC
+	evictor     *evictor
+	notifier    *core.EvictionNotifier
+	watchers    *core.WatchHub
+	compression string
+	clock       core.Clock
+	namespace   string
+	timeout     time.Duration
+	attempts    int
+}
+
+const (
+	defaultValueLogGCInterval     = 5 * time.Minute
+	defaultValueLogGCDiscardRatio = 0.5
+	// defaultIndexCacheSize and defaultBlockCacheSize bound the memory of the decrypted tables indexes and blocks.
+	defaultIndexCacheSize = 100 << 20
+	defaultBlockCacheSize = 256 << 20
+
+	// minBaseTableSize is the smallest BaseTableSize applied, the smaller tables multiply the files to compact.
+	minBaseTableSize = 1 << 20
+	// levelZeroStallRatio sets the stall threshold from the level zero tables like the badger defaults, 5 and 15.
+	levelZeroStallRatio = 3
+
+	// entryMetaFlag is set in the user meta byte of the entries stored with a metadata side record.
+	entryMetaFlag byte = 1 << 0
+
+	// The transactions aborted by a conflict are retried after a jittered backoff doubled on each attempt.
+	defaultConflictMaxAttempts = 10
+	conflictInitialBackoff     = time.Millisecond
+	conflictMaxBackoff         = 100 * time.Millisecond
+)
+
+// valueLogGC runs the value log garbage collection of a DB until stopped.
+type valueLogGC struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func startValueLogGC(db *badger.DB, interval time.Duration, ratio float64, logger core.Logger) *valueLogGC {
+	gc := &valueLogGC{stop: make(chan struct{}), done: make(chan struct{})}
+
+	go func() {
+		defer close(gc.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-gc.stop:
+				return
+			case <-ticker.C:
+			}
+
+			// Each successful run rewrites a single file, loop until nothing is left to reclaim.
+			for {
+				err := db.RunValueLogGC(ratio)
+				if err != nil {
+					if !errors.Is(err, badger.ErrNoRewrite) && !errors.Is(err, badger.ErrRejected) {
+						logger.Errorf("Impossible to run the Badger value log GC, %v", err)
+					}
+
+					break
+				}
+
+				select {
+				case <-gc.stop:
+					return
+				default:
+				}
+			}
+		}
+	}()
+
+	return gc
+}
+
+// Stop ends the garbage collection and waits for the running one to finish.
+func (gc *valueLogGC) Stop() {
+	if gc == nil {
+		return
+	}
+
+	gc.once.Do(func() { close(gc.stop) })
+	<-gc.done
+}
+
+// sharedDB is a DB opened once per path and shared by the instances of this path, it is closed with
+// the last of them.
+type sharedDB struct {
+	db      *badger.DB
+	gc      *valueLogGC
+	evictor *evictor
+	path    string
+	refs    int
 }
 
 var (
-	enabledBadgerInstances               = sync.Map{}
-	_                      badger.Logger = (*badgerLogger)(nil)
+	sharedDBsMu               = sync.Mutex{}
+	sharedDBs                 = map[string]*sharedDB{}
+	_           badger.Logger = (*badgerLogger)(nil)
 )
 
 type badgerLogger struct {
@@ -43,6 +146,39 @@ func (b *badgerLogger) Warningf(msg string, params ...interface{}) {
 
 // Factory function create new Badger instance.
 func Factory(badgerConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if badgerConfiguration.Validate {
+		if err := core.ValidateConfig(badgerConfiguration, core.CheckWritablePath); err != nil {
+			logger.Errorf("Impossible to validate the Badger configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	if err := core.CheckEncryptionKey(badgerConfiguration); err != nil {
+		logger.Errorf("Impossible to configure the Badger encryption, %v", err)
+
+		return nil, err
+	}
+
+	if badgerConfiguration.Shards > 1 {
+		storer, err := shardedFactory(badgerConfiguration, logger, stale)
+		if err != nil {
+			return nil, err
+		}
+
+		return core.Instrument(storer, badgerConfiguration, logger)
+	}
+
+	storer, err := factory(badgerConfiguration, logger, stale, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, badgerConfiguration, logger)
+}
+
+// factory opens the Badger instance, the subdirectory is appended to the DB directories when not empty.
+func factory(badgerConfiguration core.CacheProvider, logger core.Logger, stale time.Duration, subdirectory string) (*Badger, error) {
 	badgerOptions := badger.DefaultOptions(badgerConfiguration.Path)
 	badgerOptions.SyncWrites = true
 	badgerOptions.MemTableSize = 64 << 22
@@ -73,26 +209,213 @@ func Factory(badgerConfiguration core.CacheProvider, logger core.Logger, stale t
 		badgerOptions = badgerOptions.WithInMemory(true)
 	}
 
+	if badgerConfiguration.InMemory {
+		badgerOptions = badgerOptions.WithInMemory(true)
+		badgerOptions.Dir = ""
+		badgerOptions.ValueDir = ""
+	}
+
+	if subdirectory != "" && !badgerOptions.InMemory {
+		badgerOptions.Dir = filepath.Join(badgerOptions.Dir, subdirectory)
+		badgerOptions.ValueDir = filepath.Join(badgerOptions.ValueDir, subdirectory)
+	}
+
 	zapLogger, ok := logger.(*zap.SugaredLogger)
 	if ok {
 		badgerOptions.Logger = &badgerLogger{SugaredLogger: zapLogger}
 	}
 
-	uid := badgerOptions.Dir + badgerOptions.ValueDir + stale.String()
+	badgerOptions = applyCompactionOptions(badgerOptions, badgerConfiguration, logger)
+
+	// The writes are rejected by the Instrument decorator, the DB itself can't be written nor compacted.
+	if badgerConfiguration.ReadOnly {
+		badgerOptions.ReadOnly = true
+	}
+
+	// The encrypted tables are read through the index and block caches, badger panics without the block one.
+	// The sizes given by the configuration are kept.
+	if len(badgerConfiguration.EncryptionKey) > 0 {
+		badgerOptions = badgerOptions.WithEncryptionKey(badgerConfiguration.EncryptionKey)
+
+		if badgerConfiguration.EncryptionKeyRotationDuration > 0 {
+			badgerOptions = badgerOptions.WithEncryptionKeyRotationDuration(badgerConfiguration.EncryptionKeyRotationDuration)
+		}
+
+		if badgerOptions.IndexCacheSize == 0 {
+			badgerOptions = badgerOptions.WithIndexCacheSize(defaultIndexCacheSize)
+		}
+
+		if badgerOptions.BlockCacheSize == 0 {
+			badgerOptions = badgerOptions.WithBlockCacheSize(defaultBlockCacheSize)
+		}
+	}
+
+	// The DB locks its directories, the instances of the same path share it whatever their stale duration
+	// and keep the value log GC and eviction settings of the first opening. The InMemory instances have no
+	// directory and open their own DB.
+	path := badgerOptions.Dir + badgerOptions.ValueDir
+
+	sharedDBsMu.Lock()
+	defer sharedDBsMu.Unlock()
+
+	if shared, ok := sharedDBs[path]; ok && !badgerConfiguration.InMemory {
+		shared.refs++
+
+		// The expiries are reported by the evictor, started for the first instance listening to them.
+		if shared.evictor == nil && badgerConfiguration.OnEvict != nil && !badgerOptions.ReadOnly {
+			shared.evictor = startEvictor(shared.db, 0, defaultEvictionInterval, logger)
+		}
+
+		return (&Badger{
+			DB:          shared.db,
+			logger:      logger,
+			mapper:      core.ConfiguredMapper(badgerConfiguration),
+			shared:      shared,
+			gc:          shared.gc,
+			evictor:     shared.evictor,
+			notifier:    core.NewEvictionNotifier(badgerConfiguration.OnEvict, logger),
+			watchers:    core.NewWatchHub(logger),
+			stale:       stale,
+			compression: core.ConfiguredCompression(badgerConfiguration, logger),
+			clock:       core.ClockOrDefault(badgerConfiguration.Clock),
+			namespace:   core.NamespacePrefix(badgerConfiguration.Namespace),
+			timeout:     badgerConfiguration.OperationTimeout,
+			attempts:    conflictMaxAttempts(badgerConfiguration),
+		}).listenEvictions(), nil
+	}
+
+	// Badger creates the missing directories with 0700 unless their permissions are configured.
+	if !badgerOptions.InMemory && !badgerOptions.ReadOnly {
+		for _, dir := range []string{badgerOptions.Dir, badgerOptions.ValueDir} {
+			if err := core.CreateDirectory(dir, badgerConfiguration.DirPermissions); err != nil {
+				logger.Errorf("Impossible to create the Badger directory %s, %v", dir, err)
 
-	if instance, ok := enabledBadgerInstances.Load(uid); ok {
-		return instance.(*Badger), nil
+				return nil, err
+			}
+		}
 	}
 
 	db, e := badger.Open(badgerOptions)
 	if e != nil {
 		logger.Error("Impossible to open the Badger DB.", e)
+
+		return nil, e
+	}
+
+	interval := defaultValueLogGCInterval
+	if badgerConfiguration.ValueLogGCInterval != nil {
+		interval = *badgerConfiguration.ValueLogGCInterval
+	}
+
+	ratio := badgerConfiguration.ValueLogGCDiscardRatio
+	if ratio == 0 {
+		ratio = defaultValueLogGCDiscardRatio
+	}
+
+	shared := &sharedDB{db: db, path: path, refs: 1}
+
+	if interval > 0 && !badgerOptions.InMemory && !badgerOptions.ReadOnly {
+		shared.gc = startValueLogGC(db, interval, ratio, logger)
+	}
+
+	if (badgerConfiguration.MaxCacheSizeBytes > 0 || badgerConfiguration.OnEvict != nil) && !badgerOptions.ReadOnly {
+		shared.evictor = startEvictor(db, badgerConfiguration.MaxCacheSizeBytes, defaultEvictionInterval, logger)
+	}
+
+	if !badgerConfiguration.InMemory {
+		sharedDBs[path] = shared
+	}
+
+	return (&Badger{
+		DB:          db,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(badgerConfiguration),
+		shared:      shared,
+		gc:          shared.gc,
+		evictor:     shared.evictor,
+		notifier:    core.NewEvictionNotifier(badgerConfiguration.OnEvict, logger),
+		watchers:    core.NewWatchHub(logger),
+		stale:       stale,
+		compression: core.ConfiguredCompression(badgerConfiguration, logger),
+		clock:       core.ClockOrDefault(badgerConfiguration.Clock),
+		namespace:   core.NamespacePrefix(badgerConfiguration.Namespace),
+		timeout:     badgerConfiguration.OperationTimeout,
+		attempts:    conflictMaxAttempts(badgerConfiguration),
+	}).listenEvictions(), nil
+}
+
+func conflictMaxAttempts(badgerConfiguration core.CacheProvider) int {
+	if badgerConfiguration.ConflictMaxAttempts > 0 {
+		return badgerConfiguration.ConflictMaxAttempts
+	}
+
+	return defaultConflictMaxAttempts
+}
+
+// applyCompactionOptions sets the compaction and level options of the configuration over the badger ones. The
+// out of range values are warned and the current options are kept, the stall threshold is raised above the
+// level zero tables when needed as badger requires it.
+func applyCompactionOptions(badgerOptions badger.Options, badgerConfiguration core.CacheProvider, logger core.Logger) badger.Options {
+	switch {
+	case badgerConfiguration.NumCompactors == 1 || badgerConfiguration.NumCompactors < 0:
+		logger.Warnf("The badger num_compactors %d must be at least 2, %d is used.", badgerConfiguration.NumCompactors, badgerOptions.NumCompactors)
+	case badgerConfiguration.NumCompactors > 0:
+		badgerOptions.NumCompactors = badgerConfiguration.NumCompactors
+	}
+
+	switch {
+	case badgerConfiguration.NumLevelZeroTables < 0:
+		logger.Warnf("The badger num_level_zero_tables %d must be positive, %d is used.", badgerConfiguration.NumLevelZeroTables, badgerOptions.NumLevelZeroTables)
+	case badgerConfiguration.NumLevelZeroTables > 0:
+		badgerOptions.NumLevelZeroTables = badgerConfiguration.NumLevelZeroTables
+	}
+
+	switch {
+	case badgerConfiguration.NumLevelZeroTablesStall < 0:
+		logger.Warnf("The badger num_level_zero_tables_stall %d must be positive, %d is used.", badgerConfiguration.NumLevelZeroTablesStall, badgerOptions.NumLevelZeroTablesStall)
+	case badgerConfiguration.NumLevelZeroTablesStall > 0:
+		badgerOptions.NumLevelZeroTablesStall = badgerConfiguration.NumLevelZeroTablesStall
+	}
+
+	if badgerOptions.NumLevelZeroTablesStall <= badgerOptions.NumLevelZeroTables {
+		stall := badgerOptions.NumLevelZeroTables * levelZeroStallRatio
+		logger.Warnf(
+			"The badger num_level_zero_tables_stall %d must be greater than the num_level_zero_tables %d, %d is used.",
+			badgerOptions.NumLevelZeroTablesStall, badgerOptions.NumLevelZeroTables, stall,
+		)
+		badgerOptions.NumLevelZeroTablesStall = stall
+	}
+
+	switch {
+	case badgerConfiguration.BaseTableSize != 0 && badgerConfiguration.BaseTableSize < minBaseTableSize:
+		logger.Warnf("The badger base_table_size %d must be at least %d, %d is used.", badgerConfiguration.BaseTableSize, minBaseTableSize, badgerOptions.BaseTableSize)
+	case badgerConfiguration.BaseTableSize > 0:
+		badgerOptions.BaseTableSize = badgerConfiguration.BaseTableSize
 	}
 
-	i := &Badger{DB: db, logger: logger, stale: stale}
-	enabledBadgerInstances.Store(uid, i)
+	return badgerOptions
+}
+
+// listenEvictions subscribes the OnEvict notifier to the size evictions and the expiries of the shared DB.
+func (provider *Badger) listenEvictions() *Badger {
+	provider.evictor.listen(provider.namespace, provider.notifier, provider.clock)
+
+	return provider
+}
+
+// IsClosed returns true once the instance or the underlying DB is closed.
+func (provider *Badger) IsClosed() bool {
+	return provider.closed.Load() || provider.DB.IsClosed()
+}
+
+// key returns the key stored in the database, prefixed by the namespace if any.
+func (provider *Badger) key(key string) []byte {
+	return []byte(provider.namespace + key)
+}
 
-	return i, nil
+// operationContext returns a context expiring with the operation timeout if any.
+func (provider *Badger) operationContext() (context.Context, context.CancelFunc) {
+	return core.OperationContext(context.Background(), provider.timeout)
 }
 
 // Name returns the storer name.
@@ -107,25 +430,36 @@ func (provider *Badger) Uuid() string {
 		provider.DB.Opts().Dir,
 		provider.DB.Opts().ValueDir,
 		provider.stale,
-	)
+	) + provider.namespace
 }
 
 // MapKeys method returns a map with the key and value.
 func (provider *Badger) MapKeys(prefix string) map[string]string {
+	if provider.IsClosed() {
+		return map[string]string{}
+	}
+
 	keys := map[string]string{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
 
 	_ = provider.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = false
 		iterator := txn.NewIterator(opts)
-		p := []byte(prefix)
+		p := provider.key(prefix)
 
 		defer iterator.Close()
 
-		for iterator.Seek(p); iterator.ValidForPrefix(p); iterator.Next() {
+		for iterator.Seek(p); iterator.ValidForPrefix(p) && ctx.Err() == nil; iterator.Next() {
+			key := strings.TrimPrefix(string(iterator.Item().Key()), provider.namespace)
+			if !core.ListedKey(prefix, key) {
+				continue
+			}
+
 			_ = iterator.Item().Value(func(val []byte) error {
-				k, _ := strings.CutPrefix(string(iterator.Item().Key()), prefix)
-				keys[k] = string(val)
+				keys[strings.TrimPrefix(key, prefix)] = string(val)
 
 				return nil
 			})
@@ -139,7 +473,14 @@ func (provider *Badger) MapKeys(prefix string) map[string]string {
 
 // ListKeys method returns the list of existing keys.
 func (provider *Badger) ListKeys() []string {
+	if provider.IsClosed() {
+		return []string{}
+	}
+
 	keys := []string{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
 
 	err := provider.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
@@ -148,9 +489,9 @@ func (provider *Badger) ListKeys() []string {
 
 		defer it.Close()
 
-		for it.Seek([]byte(core.MappingKeyPrefix)); it.ValidForPrefix([]byte(core.MappingKeyPrefix)); it.Next() {
+		for it.Seek(provider.key(core.MappingKeyPrefix)); it.ValidForPrefix(provider.key(core.MappingKeyPrefix)) && ctx.Err() == nil; it.Next() {
 			_ = it.Item().Value(func(val []byte) error {
-				mapping, err := core.DecodeMapping(val)
+				mapping, err := provider.mapper.Decode(val)
 				if err == nil {
 					for _, v := range mapping.GetMapping() {
 						keys = append(keys, v.GetRealKey())
@@ -170,186 +511,1238 @@ func (provider *Badger) ListKeys() []string {
 	return keys
 }
 
-// Get method returns the populated response if exists, empty response then.
-func (provider *Badger) Get(key string) []byte {
-	var item *badger.Item
-
-	var result []byte
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Badger) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	if provider.IsClosed() {
+		return []string{}, ""
+	}
 
-	err := provider.View(func(txn *badger.Txn) error {
-		i, err := txn.Get([]byte(key))
-		item = i
+	keys = []string{}
+	ctx, cancel := provider.operationContext()
 
-		return err
-	})
+	defer cancel()
 
-	if errors.Is(err, badger.ErrKeyNotFound) {
-		return result
-	}
+	_ = provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = provider.key(prefix)
+		iterator := txn.NewIterator(opts)
 
-	if item != nil {
-		_ = item.Value(func(val []byte) error {
-			result = val
+		defer iterator.Close()
 
-			return nil
-		})
-	}
+		for iterator.Seek(provider.key(max(prefix, cursor))); iterator.ValidForPrefix(opts.Prefix); iterator.Next() {
+			if ctx.Err() != nil {
+				// The cursor lets the caller resume the interrupted scan.
+				next = strings.TrimPrefix(string(iterator.Item().Key()), provider.namespace)
 
-	return result
-}
+				break
+			}
 
-// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
-func (provider *Badger) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
-	_ = provider.View(func(tx *badger.Txn) error {
-		result, err := tx.Get([]byte(core.MappingKeyPrefix + key))
-		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
-			return err
-		}
+			key := strings.TrimPrefix(string(iterator.Item().Key()), provider.namespace)
+			if !core.ListedKey(prefix, key) {
+				continue
+			}
 
-		var val []byte
+			if limit > 0 && len(keys) == limit {
+				next = key
 
-		if result != nil {
-			_ = result.Value(func(b []byte) error {
-				val = b
+				break
+			}
 
-				return nil
-			})
+			keys = append(keys, key)
 		}
 
-		fresh, stale, err = core.MappingElection(provider, val, req, validator, provider.logger)
-
-		return err
+		return nil
 	})
 
-	return
+	return keys, next
 }
 
-// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
-func (provider *Badger) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+// SortedKeys method returns the keys matching the prefix in lexicographic order, the iterator already
+// yields them sorted.
+func (provider *Badger) SortedKeys(prefix string) []string {
+	if provider.IsClosed() {
+		return []string{}
+	}
 
-	err := provider.Update(func(btx *badger.Txn) error {
-		var err error
+	keys := []string{}
+	ctx, cancel := provider.operationContext()
 
-		compressed := new(bytes.Buffer)
-		writer := lz4.NewWriter(compressed)
+	defer cancel()
 
-		if _, err = writer.ReadFrom(bytes.NewReader(value)); err != nil {
-			_ = writer.Close()
+	_ = provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = provider.key(prefix)
+		iterator := txn.NewIterator(opts)
 
-			provider.logger.Errorf("Impossible to compress the key %s into Badger, %v", variedKey, err)
+		defer iterator.Close()
 
-			return err
+		for iterator.Seek(opts.Prefix); iterator.ValidForPrefix(opts.Prefix) && ctx.Err() == nil; iterator.Next() {
+			key := strings.TrimPrefix(string(iterator.Item().Key()), provider.namespace)
+			if core.ListedKey(prefix, key) {
+				keys = append(keys, key)
+			}
 		}
 
-		if err = writer.Close(); err != nil {
-			provider.logger.Errorf("Impossible to close the compressor for key %s into Badger, %v", variedKey, err)
+		return nil
+	})
+
+	return keys
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Badger) Get(key string) []byte {
+	result, _ := provider.GetContext(context.Background(), key)
+
+	return result
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Badger) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if provider.IsClosed() {
+		return nil, core.ErrClosed
+	}
+
+	var result []byte
 
+	err := provider.View(func(txn *badger.Txn) error {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		err = btx.SetEntry(badger.NewEntry([]byte(variedKey), compressed.Bytes()).WithTTL(duration + provider.stale))
+		item, err := txn.Get(provider.key(key))
 		if err != nil {
-			provider.logger.Errorf("Impossible to set the key %s into Badger, %v", variedKey, err)
-
 			return err
 		}
 
-		mappingKey := core.MappingKeyPrefix + baseKey
-		item, err := btx.Get([]byte(mappingKey))
+		provider.evictor.access(item.Key())
+		result, err = item.ValueCopy(nil)
 
-		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
-			provider.logger.Errorf("Impossible to get the base key %s in Badger, %v", mappingKey, err)
+		return err
+	})
 
-			return err
-		}
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, core.ErrKeyNotFound
+	}
 
-		var val []byte
+	return result, err
+}
 
-		if item != nil {
-			_ = item.Value(func(b []byte) error {
-				val = b
+// GetInto method calls fn with the value of the key read in place within the transaction, see core.ValueReader.
+func (provider *Badger) GetInto(key string, fn func([]byte) error) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
 
-				return nil
-			})
-		}
+	var valueErr error
 
-		val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	err := provider.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(provider.key(key))
 		if err != nil {
-			provider.logger.Errorf("Impossible to update the mapping for the key %s in Badger, %v", variedKey, err)
-
 			return err
 		}
 
-		provider.logger.Debugf("Store the new mapping for the key %s in Badger", variedKey)
+		provider.evictor.access(item.Key())
+		valueErr = item.Value(fn)
 
-		return btx.SetEntry(badger.NewEntry([]byte(mappingKey), val))
+		return nil
 	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return core.ErrKeyNotFound
+	}
+
 	if err != nil {
-		provider.logger.Errorf("Impossible to set value into Badger, %v", err)
+		return err
 	}
 
-	return err
+	return valueErr
 }
 
-// Set method will store the response in Badger provider.
-func (provider *Badger) Set(key string, value []byte, duration time.Duration) error {
-	err := provider.Update(func(txn *badger.Txn) error {
-		return txn.SetEntry(badger.NewEntry([]byte(key), value).WithTTL(duration))
-	})
-	if err != nil {
-		provider.logger.Errorf("Impossible to set value into Badger, %v", err)
-	}
+// update runs the read-write transaction, retried while it's aborted by a conflict with a concurrent
+// transaction. The badger.ErrConflict is returned once the configured attempts are exhausted.
+func (provider *Badger) update(fn func(txn *badger.Txn) error) error {
+	backoff := conflictInitialBackoff
 
-	return err
+	for attempt := 1; ; attempt++ {
+		err := provider.Update(fn)
+		if !errors.Is(err, badger.ErrConflict) || attempt >= provider.attempts {
+			return err
+		}
+
+		// The equal jitter randomizes half of the backoff to spread the retries of the conflicting writers.
+		time.Sleep(backoff/2 + rand.N(backoff/2+1))
+
+		backoff = min(2*backoff, conflictMaxBackoff)
+	}
 }
 
-// Delete method will delete the response in Badger provider if exists corresponding to key param.
-func (provider *Badger) Delete(key string) {
-	_ = provider.Update(func(txn *badger.Txn) error {
-		return txn.Delete([]byte(key))
-	})
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Badger) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
 }
 
-// DeleteMany method will delete the responses in Badger provider if exists corresponding to the regex key param.
-func (provider *Badger) DeleteMany(key string) {
-	rgKey, e := regexp.Compile(key)
-	if e != nil {
-		return
+// GetMany method returns the values of the existing keys using a single read transaction.
+func (provider *Badger) GetMany(keys []string) map[string][]byte {
+	if provider.IsClosed() {
+		return map[string][]byte{}
 	}
 
-	_ = provider.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		opts.PrefetchValues = false
-		it := txn.NewIterator(opts)
+	result := make(map[string][]byte, len(keys))
 
-		defer it.Close()
+	err := provider.View(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get(provider.key(key))
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
 
-		for it.Rewind(); it.Valid(); it.Next() {
-			k := string(it.Item().Key())
-			if rgKey.MatchString(k) {
-				provider.Delete(k)
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
 			}
+
+			provider.evictor.access(item.Key())
+			result[key] = value
 		}
 
 		return nil
 	})
-}
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys from Badger, %v", err)
+	}
 
-// Init method will.
-func (provider *Badger) Init() error {
-	return nil
+	return result
 }
 
-// Reset method will reset or close provider.
-func (provider *Badger) Reset() error {
-	if err := provider.DropAll(); err != nil {
-		provider.logger.Errorf("Impossible to reset the Badger DB, %v", err)
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Badger) GetTTL(key string) (time.Duration, bool) {
+	if provider.IsClosed() {
+		return 0, false
+	}
+
+	var expiresAt uint64
+
+	err := provider.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(provider.key(key))
+		if err != nil {
+			return err
+		}
+
+		expiresAt = item.ExpiresAt()
+
+		return nil
+	})
+	if err != nil {
+		return 0, false
 	}
 
-	if err := provider.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the Badger DB, %v", err)
+	if expiresAt == 0 {
+		return core.NoExpiration, true
 	}
 
-	return nil
+	//nolint:gosec
+	return time.Until(time.Unix(int64(expiresAt), 0)), true
+}
+
+// Exists method will check the key in Badger provider without reading its value.
+func (provider *Badger) Exists(key string) bool {
+	if provider.IsClosed() {
+		return false
+	}
+
+	err := provider.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(provider.key(key))
+
+		return err
+	})
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Badger) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Badger) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	return provider.getMultiLevel(provider, key, req, validator)
+}
+
+// getMultiLevel elects the response of the mapping stored in the provider, the varied keys are read from the storer.
+func (provider *Badger) getMultiLevel(storer core.Storer, key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	if provider.IsClosed() {
+		return nil, nil, match
+	}
+
+	_ = provider.View(func(tx *badger.Txn) error {
+		result, err := tx.Get(provider.key(core.MappingKeyPrefix + key))
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		var val []byte
+
+		if result != nil {
+			provider.evictor.access(result.Key())
+			_ = result.Value(func(b []byte) error {
+				val = b
+
+				return nil
+			})
+		}
+
+		fresh, stale, match, err = core.MappingElectionWith(provider.mapper, storer, val, req, validator, provider.logger)
+
+		return err
+	})
+
+	return
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+// A non-positive duration deletes the existing varied key and leaves the mapping untouched.
+func (provider *Badger) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	store, ttl := core.NormalizeTTL(duration + provider.stale)
+	if !store {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	err := provider.update(func(btx *badger.Txn) error {
+		if err := provider.setVaried(btx, variedKey, value, variedHeaders, ttl); err != nil {
+			return err
+		}
+
+		return provider.setMapping(btx, baseKey, variedKey, variedHeaders, etag, now, duration, realKey)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Badger, %v", err)
+
+		return err
+	}
+
+	provider.evictor.access(provider.key(variedKey), provider.key(core.MappingKeyPrefix+baseKey))
+	provider.watchers.Publish(core.EventSet, variedKey)
+	provider.watchers.Publish(core.EventSet, core.MappingKeyPrefix+baseKey)
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Badger, %v", variedKey, err)
+	}
+
+	return err
+}
+
+// setVaried stores the compressed varied response in the transaction.
+func (provider *Badger) setVaried(btx *badger.Txn, variedKey string, value []byte, variedHeaders http.Header, ttl time.Duration) error {
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Badger, %v", variedKey, err)
+
+		return err
+	}
+
+	err = btx.SetEntry(badger.NewEntry(provider.key(variedKey), compressed).WithTTL(ttl))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s into Badger, %v", variedKey, err)
+	}
+
+	return err
+}
+
+// setMapping adds the varied key to the mapping of the base key in the transaction.
+func (provider *Badger) setMapping(btx *badger.Txn, baseKey, variedKey string, variedHeaders http.Header, etag string, now time.Time, duration time.Duration, realKey string) error {
+	mappingKey := core.MappingKeyPrefix + baseKey
+	item, err := btx.Get(provider.key(mappingKey))
+
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the base key %s in Badger, %v", mappingKey, err)
+
+		return err
+	}
+
+	var val []byte
+
+	if item != nil {
+		_ = item.Value(func(b []byte) error {
+			val = b
+
+			return nil
+		})
+	}
+
+	val, err = core.MappingUpdaterWith(provider.mapper, variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping for the key %s in Badger, %v", variedKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in Badger", variedKey)
+
+	return btx.SetEntry(badger.NewEntry(provider.key(mappingKey), val))
+}
+
+// Set method will store the response in Badger provider. The write is committed when it returns, a
+// subsequent Get sees the value without waiting.
+func (provider *Badger) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response in Badger provider unless the context is done before the commit.
+// A non-positive duration deletes the existing key instead.
+func (provider *Badger) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+
+	err := provider.update(func(txn *badger.Txn) error {
+		if !store {
+			if err := txn.Delete(provider.key(key)); err != nil {
+				return err
+			}
+		} else if err := txn.SetEntry(badger.NewEntry(provider.key(key), value).WithTTL(ttl)); err != nil {
+			return err
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Badger, %v", err)
+	} else if store {
+		provider.evictor.access(provider.key(key))
+		provider.watchers.Publish(core.EventSet, key)
+	} else {
+		provider.watchers.Publish(core.EventDelete, key)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in Badger provider using a single write batch.
+// The batch may be committed in several transactions, so the entries flushed before
+// a failure are kept and the first error is returned. The entries with a non-positive duration delete their key.
+func (provider *Badger) SetMany(items map[string]core.Entry) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	batch := provider.NewWriteBatch()
+	defer batch.Cancel()
+
+	for key, item := range items {
+		store, ttl := core.NormalizeTTL(item.Duration)
+
+		var err error
+		if store {
+			err = batch.SetEntry(badger.NewEntry(provider.key(key), item.Value).WithTTL(ttl))
+		} else {
+			err = batch.Delete(provider.key(key))
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to set the key %s into Badger, %v", key, err)
+
+			return err
+		}
+
+		if store {
+			provider.evictor.access(provider.key(key))
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into Badger, %v", err)
+
+		return err
+	}
+
+	for key, item := range items {
+		if item.Duration > 0 {
+			provider.watchers.Publish(core.EventSet, key)
+		} else {
+			provider.watchers.Publish(core.EventDelete, key)
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Badger provider only if the key doesn't exist yet.
+// The transaction is retried when a concurrent writer updated the key in between.
+func (provider *Badger) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if provider.IsClosed() {
+		return false, core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		return false, nil
+	}
+
+	created := false
+
+	err := provider.update(func(txn *badger.Txn) error {
+		created = false
+
+		if _, err := txn.Get(provider.key(key)); !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		created = true
+		provider.evictor.access(provider.key(key))
+
+		return txn.SetEntry(badger.NewEntry(provider.key(key), value).WithTTL(ttl))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Badger, %v", key, err)
+
+		return false, err
+	}
+
+	if created {
+		provider.watchers.Publish(core.EventSet, key)
+	}
+
+	return created, nil
+}
+
+// CompareAndSwap method will store the response in Badger provider only if the stored bytes equal old, within
+// a single transaction. The transaction is retried when a concurrent writer updated the key in between.
+func (provider *Badger) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if provider.IsClosed() {
+		return false, core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		return false, nil
+	}
+
+	swapped := false
+
+	err := provider.update(func(txn *badger.Txn) error {
+		var current []byte
+
+		swapped = false
+
+		item, err := txn.Get(provider.key(key))
+		if err == nil {
+			current, err = item.ValueCopy(nil)
+		}
+
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		if !core.SwapMatches(current, old) {
+			return nil
+		}
+
+		swapped = true
+		provider.evictor.access(provider.key(key))
+
+		return txn.SetEntry(badger.NewEntry(provider.key(key), value).WithTTL(ttl))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Badger, %v", key, err)
+
+		return false, err
+	}
+
+	if swapped {
+		provider.watchers.Publish(core.EventSet, key)
+	}
+
+	return swapped, nil
+}
+
+// Increment method will add delta to the counter stored in Badger provider within a single transaction.
+// The transaction is retried when a concurrent writer updated the counter in between.
+func (provider *Badger) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if provider.IsClosed() {
+		return 0, core.ErrClosed
+	}
+
+	var value int64
+
+	err := provider.update(func(txn *badger.Txn) error {
+		var current []byte
+
+		item, err := txn.Get(provider.key(key))
+		if err == nil {
+			current, err = item.ValueCopy(nil)
+		}
+
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		var encoded []byte
+		if value, encoded, err = core.AddCounter(current, delta); err != nil {
+			return err
+		}
+
+		entry := badger.NewEntry(provider.key(key), encoded)
+		if duration > 0 {
+			entry = entry.WithTTL(duration)
+		}
+
+		provider.evictor.access(entry.Key)
+
+		return txn.SetEntry(entry)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into Badger, %v", key, err)
+
+		return 0, err
+	}
+
+	provider.watchers.Publish(core.EventSet, key)
+
+	return value, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Badger provider.
+func (provider *Badger) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Badger provider.
+func (provider *Badger) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Badger, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Badger) GetStream(key string) (io.ReadCloser, error) {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+// A non-positive duration deletes the key and its metadata instead.
+func (provider *Badger) Touch(key string, duration time.Duration) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+
+	err := provider.update(func(txn *badger.Txn) error {
+		item, err := txn.Get(provider.key(key))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return core.ErrKeyNotFound
+			}
+
+			return err
+		}
+
+		if !store {
+			if err = txn.Delete(provider.key(core.EntryMetaKeyPrefix + key)); err != nil {
+				return err
+			}
+
+			return txn.Delete(provider.key(key))
+		}
+
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		if item.UserMeta()&entryMetaFlag != 0 {
+			if err = provider.touchEntryMeta(txn, key, ttl); err != nil {
+				return err
+			}
+		}
+
+		return txn.SetEntry(badger.NewEntry(provider.key(key), value).WithMeta(item.UserMeta()).WithTTL(ttl))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Badger, %v", key, err)
+	} else if !store {
+		provider.watchers.Publish(core.EventDelete, key)
+	}
+
+	return err
+}
+
+// SetWithMeta method will store the response and its metadata side record in Badger provider in a single transaction.
+// The entry user meta byte flags the record so GetMeta ignores the record of an entry overwritten by a plain Set.
+func (provider *Badger) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	record, err := core.EncodeEntryMeta(meta)
+	if err != nil {
+		return err
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+
+	err = provider.update(func(txn *badger.Txn) error {
+		if !store || len(meta) == 0 {
+			if err := txn.Delete(provider.key(core.EntryMetaKeyPrefix + key)); err != nil {
+				return err
+			}
+		}
+
+		if !store {
+			return txn.Delete(provider.key(key))
+		}
+
+		entry := badger.NewEntry(provider.key(key), value).WithTTL(ttl)
+		if len(meta) == 0 {
+			return txn.SetEntry(entry)
+		}
+
+		if err := txn.SetEntry(badger.NewEntry(provider.key(core.EntryMetaKeyPrefix+key), record).WithTTL(ttl)); err != nil {
+			return err
+		}
+
+		return txn.SetEntry(entry.WithMeta(entryMetaFlag))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value with its metadata into Badger, %v", err)
+	} else if store {
+		provider.evictor.access(provider.key(key))
+		provider.watchers.Publish(core.EventSet, key)
+	} else {
+		provider.watchers.Publish(core.EventDelete, key)
+	}
+
+	return err
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta without reading the value, core.ErrKeyNotFound if none.
+func (provider *Badger) GetMeta(key string) (map[string]string, error) {
+	if provider.IsClosed() {
+		return nil, core.ErrClosed
+	}
+
+	var record []byte
+
+	err := provider.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(provider.key(key))
+		if err != nil {
+			return err
+		}
+
+		if item.UserMeta()&entryMetaFlag == 0 {
+			return badger.ErrKeyNotFound
+		}
+
+		item, err = txn.Get(provider.key(core.EntryMetaKeyPrefix + key))
+		if err != nil {
+			return err
+		}
+
+		record, err = item.ValueCopy(nil)
+
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the metadata of the key %s in Badger, %v", key, err)
+
+		return nil, err
+	}
+
+	return core.DecodeEntryMeta(record)
+}
+
+func (provider *Badger) touchEntryMeta(txn *badger.Txn, key string, duration time.Duration) error {
+	item, err := txn.Get(provider.key(core.EntryMetaKeyPrefix + key))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	record, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+
+	return txn.SetEntry(badger.NewEntry(provider.key(core.EntryMetaKeyPrefix+key), record).WithTTL(duration))
+}
+
+// Delete method will delete the response in Badger provider if exists corresponding to key param.
+func (provider *Badger) Delete(key string) {
+	if provider.IsClosed() {
+		return
+	}
+
+	core.UnindexSurrogateKeys(provider, key)
+
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	// The existence is read apart so the deletion never conflicts with a concurrent write.
+	existed := provider.notifier != nil && provider.Exists(key)
+
+	err := provider.update(func(txn *badger.Txn) error {
+		if err := txn.Delete(provider.key(key)); err != nil {
+			return err
+		}
+
+		if err := txn.Delete(provider.key(core.EntryMetaKeyPrefix + key)); err != nil {
+			return err
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return
+	}
+
+	provider.watchers.Publish(core.EventDelete, key)
+
+	if existed {
+		provider.notifier.Notify(key, core.EvictDeleted)
+	}
+}
+
+// DeleteMany method will delete the responses in Badger provider if exists corresponding to the prefix pattern param.
+// The keys are deleted using a single write batch.
+func (provider *Badger) DeleteMany(pattern string) {
+	if provider.IsClosed() {
+		return
+	}
+
+	prefix := provider.key(core.KeyPrefix(pattern))
+	keys := [][]byte{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	err := provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+
+		return nil
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys matching %s in Badger, %v", pattern, err)
+
+		return
+	}
+
+	batch := provider.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			provider.logger.Errorf("Impossible to delete the key %s in Badger, %v", key, err)
+
+			return
+		}
+	}
+
+	if err = ctx.Err(); err == nil {
+		err = batch.Flush()
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in Badger, %v", pattern, err)
+
+		return
+	}
+
+	for _, key := range keys {
+		deleted := strings.TrimPrefix(string(key), provider.namespace)
+
+		provider.watchers.Publish(core.EventDelete, deleted)
+		provider.notifier.Notify(deleted, core.EvictDeleted)
+	}
+}
+
+// DeleteKeys method will delete the keys with their metadata in Badger provider using a single write batch.
+// The missing keys are ignored.
+func (provider *Badger) DeleteKeys(keys []string) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+	existing := map[string]bool{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	// The existence is read apart so the deletion never conflicts with a concurrent write.
+	if provider.notifier != nil {
+		_ = provider.View(func(txn *badger.Txn) error {
+			for _, key := range keys {
+				_, err := txn.Get(provider.key(key))
+				existing[key] = err == nil
+			}
+
+			return nil
+		})
+	}
+
+	batch := provider.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, key := range deleted {
+		if err := batch.Delete(provider.key(key)); err != nil {
+			provider.logger.Errorf("Impossible to delete the key %s in Badger, %v", key, err)
+
+			return err
+		}
+	}
+
+	err := ctx.Err()
+	if err == nil {
+		err = batch.Flush()
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in Badger, %v", err)
+
+		return err
+	}
+
+	for _, key := range keys {
+		provider.watchers.Publish(core.EventDelete, key)
+
+		if existing[key] {
+			provider.notifier.Notify(key, core.EvictDeleted)
+		}
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Badger provider.
+func (provider *Badger) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Badger provider.
+func (provider *Badger) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Badger provider.
+func (provider *Badger) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Badger provider without altering it.
+func (provider *Badger) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// Stats method returns the number of keys counted with a keys-only iteration and the on-disk size of the LSM tree and value log.
+// The size is the shared database one when the provider is namespaced, an in-memory database reports no size.
+func (provider *Badger) Stats() (core.StorageStats, error) {
+	if provider.IsClosed() {
+		return core.StorageStats{}, core.ErrClosed
+	}
+
+	lsm, vlog := provider.Size()
+	stats := core.StorageStats{ApproxSizeBytes: lsm + vlog}
+
+	err := provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = provider.key("")
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			stats.KeyCount++
+		}
+
+		return nil
+	})
+
+	return stats, err
+}
+
+// Ping method will check the Badger DB is open using a no-op read transaction.
+func (provider *Badger) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	return provider.View(func(*badger.Txn) error { return nil })
+}
+
+// Export method will stream every key of the Badger provider using a single read transaction.
+func (provider *Badger) Export(w io.Writer) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	exporter := core.NewExportWriter(w)
+
+	err := provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = provider.key("")
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+			ttl := core.NoExpiration
+
+			if expiresAt := item.ExpiresAt(); expiresAt != 0 {
+				//nolint:gosec
+				ttl = time.Until(time.Unix(int64(expiresAt), 0))
+			}
+
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if err = exporter.Write(strings.TrimPrefix(string(item.Key()), provider.namespace), value, ttl); err != nil {
+				return err
+			}
+		}
+
+		return exporter.Flush()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to export the Badger keys, %v", err)
+	}
+
+	return err
+}
+
+// DumpMeta method will write the key, value size and remaining time to live of every key as JSON lines.
+// The values are not read, the size is the stored one so compressed when the compression is enabled.
+func (provider *Badger) DumpMeta(w io.Writer) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	dumper := core.NewMetaWriter(w)
+
+	err := provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = provider.key("")
+		opts.PrefetchValues = false
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+			ttl := core.NoExpiration
+
+			if expiresAt := item.ExpiresAt(); expiresAt != 0 {
+				//nolint:gosec
+				ttl = time.Until(time.Unix(int64(expiresAt), 0))
+			}
+
+			if err := dumper.Write(strings.TrimPrefix(string(item.Key()), provider.namespace), item.ValueSize(), ttl); err != nil {
+				return err
+			}
+		}
+
+		return dumper.Flush()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to dump the Badger keys metadata, %v", err)
+	}
+
+	return err
+}
+
+// Watch method will stream the changes made through this instance to the keys starting with the prefix, see
+// core.Watcher. The expiries, the size evictions and Reset are not reported.
+func (provider *Badger) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	return provider.watchers.Watch(ctx, prefix)
+}
+
+// Iterate method will stream every entry of the Badger provider from a single read transaction, see
+// core.Iterator. The values are not prefetched, each one is copied when its entry is sent.
+func (provider *Badger) Iterate(ctx context.Context) (<-chan core.KV, <-chan error) {
+	return core.IterateWith(ctx, provider.iterate)
+}
+
+// iterate calls yield with every entry of the namespace until it returns an error.
+func (provider *Badger) iterate(yield func(core.KV) error) error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	err := provider.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = provider.key("")
+		opts.PrefetchValues = false
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			if err = yield(core.KV{Key: strings.TrimPrefix(string(item.Key()), provider.namespace), Value: value}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		provider.logger.Errorf("Impossible to iterate over the Badger keys, %v", err)
+	}
+
+	return err
+}
+
+// Import method will store the exported records in Badger provider by write batches.
+func (provider *Badger) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Badger) Init() error {
+	return nil
+}
+
+// Reset method will drop every key and keep the DB open for the new writes.
+// A namespaced provider only drops its own keys.
+func (provider *Badger) Reset() error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	if provider.namespace != "" {
+		return provider.DropPrefix(provider.key(""))
+	}
+
+	if err := provider.DropAll(); err != nil {
+		provider.logger.Errorf("Impossible to reset the Badger DB, %v", err)
+
+		return err
+	}
+
+	return nil
+}
+
+// Evict method will report the expired entries to OnEvict then delete the least recently used entries until the
+// live entries fit the MaxCacheSizeBytes budget, as the background eviction does every minute. It does nothing
+// when neither OnEvict nor a budget is configured.
+func (provider *Badger) Evict() error {
+	if provider.IsClosed() {
+		return core.ErrClosed
+	}
+
+	if provider.evictor == nil {
+		return nil
+	}
+
+	err := provider.evictor.evict()
+	if err != nil {
+		provider.logger.Errorf("Impossible to evict the least recently used entries from Badger, %v", err)
+	}
+
+	return err
+}
+
+// Close method will release the instance, the last instance of the path stops the value log GC and the
+// eviction then closes the Badger DB, the next Factory call reopens it. An InMemory DB drops all its data.
+// The pending OnEvict notifications of the instance are delivered before it returns.
+func (provider *Badger) Close() error {
+	if provider.DB.IsClosed() || !provider.closed.CompareAndSwap(false, true) {
+		return core.ErrClosed
+	}
+
+	sharedDBsMu.Lock()
+	defer sharedDBsMu.Unlock()
+
+	provider.evictor.unlisten(provider.notifier)
+	provider.notifier.Close()
+	provider.watchers.Close()
+
+	provider.shared.refs--
+	if provider.shared.refs > 0 {
+		return nil
+	}
+
+	if sharedDBs[provider.shared.path] == provider.shared {
+		delete(sharedDBs, provider.shared.path)
+	}
+
+	provider.gc.Stop()
+	provider.shared.evictor.Stop()
+
+	return provider.DB.Close()
 }
@@ -4,12 +4,30 @@ package badger_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/darkweak/storages/badger"
 	"github.com/darkweak/storages/core"
+	badgerdb "github.com/dgraph-io/badger/v4"
 	"github.com/pierrec/lz4/v4"
 	"go.uber.org/zap"
 )
@@ -49,11 +67,82 @@ func TestBadgerConnectionFactory(t *testing.T) {
 	}
 }
 
+func TestBadger_ValidateConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("Impossible to create the file: %v", err)
+	}
+
+	_, err := badger.Factory(core.CacheProvider{Path: filepath.Join(file, "badger"), Compression: "gzip", Validate: true}, zap.NewNop().Sugar(), 0)
+	if !errors.Is(err, core.ErrInvalidConfig) || !errors.Is(err, core.ErrUnknownCompression) || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("The invalid path and codec should be reported, %v provided", err)
+	}
+
+	instance, err := badger.Factory(core.CacheProvider{Path: filepath.Join(t.TempDir(), "badger"), Validate: true}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("The valid configuration should be accepted, %v provided", err)
+	}
+
+	_ = instance.Close()
+}
+
+func TestBadger_EncryptionKey(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte("k"), 32)
+
+	open := func(key []byte) (core.Storer, error) {
+		return badger.Factory(core.CacheProvider{Path: dir, EncryptionKey: key}, zap.NewNop().Sugar(), 0)
+	}
+
+	client, err := open(key)
+	if err != nil {
+		t.Fatalf("Impossible to create the encrypted Badger instance: %v", err)
+	}
+
+	if err = client.Set("EncryptedKey", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key EncryptedKey: %v", err)
+	}
+
+	_ = client.Close()
+
+	err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		if content, _ := os.ReadFile(path); bytes.Contains(content, []byte(baseValue)) {
+			t.Errorf("The file %s should not hold the value in clear", entry.Name())
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Impossible to read the DB files: %v", err)
+	}
+
+	if client, err = open(key); err != nil {
+		t.Fatalf("Impossible to reopen the encrypted Badger instance: %v", err)
+	}
+
+	if res := client.Get("EncryptedKey"); string(res) != baseValue {
+		t.Errorf("The value should be read back with the same key, %s provided", res)
+	}
+
+	_ = client.Close()
+
+	if _, err = open(bytes.Repeat([]byte("w"), 32)); !errors.Is(err, badgerdb.ErrEncryptionKeyMismatch) {
+		t.Errorf("The DB should not be opened with another key, %v provided", err)
+	}
+
+	if _, err = open([]byte("short")); !errors.Is(err, core.ErrInvalidConfig) || !strings.Contains(err.Error(), "16, 24 or 32 bytes") {
+		t.Errorf("The 5 bytes key should be rejected, %v provided", err)
+	}
+}
+
 func TestIShouldBeAbleToReadAndWriteDataInBadger(t *testing.T) {
 	client, _ := getBadgerInstance()
 
 	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	res := client.Get("Test")
 	if len(res) == 0 {
@@ -77,7 +166,6 @@ func TestBadger_GetRequestInCache(t *testing.T) {
 func TestBadger_GetSetRequestInCache_OneByte(t *testing.T) {
 	client, _ := getBadgerInstance()
 	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	res := client.Get(byteKey)
 	if len(res) == 0 {
@@ -94,7 +182,6 @@ func TestBadger_SetRequestInCache_TTL(t *testing.T) {
 	client, _ := getBadgerInstance()
 	value := []byte("Hello world")
 	_ = client.Set(key, value, time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	newValue := client.Get(key)
 
@@ -108,19 +195,90 @@ func TestBadger_SetRequestInCache_Negative_TTL(t *testing.T) {
 	value := []byte("New value")
 	_ = client.Set(byteKey, value, -1)
 
-	time.Sleep(1 * time.Second)
-
 	newValue := client.Get(byteKey)
 
 	if len(newValue) != len([]byte{}) {
 		t.Errorf("Key %s should be equals to %s, %s provided", byteKey, []byte{}, newValue)
 	}
+
+	_ = client.Set("NegativeTTLKey", value, time.Minute)
+
+	if err := client.Set("NegativeTTLKey", value, -1); err != nil {
+		t.Errorf("Setting a negative TTL shouldn't return an error: %v", err)
+	}
+
+	if _, found := client.GetTTL("NegativeTTLKey"); found {
+		t.Error("The existing key NegativeTTLKey should have been removed")
+	}
+
+	_ = client.SetMultiLevel("NegativeTTLKey", "NegativeTTLKey", value, http.Header{}, "", -1, "NegativeTTLKey")
+
+	if _, found := client.GetTTL(core.MappingKeyPrefix + "NegativeTTLKey"); found {
+		t.Error("The mapping key shouldn't be stored for a negative TTL")
+	}
+
+	_ = client.Set("NegativeTTLKey", value, time.Minute)
+
+	if err := client.SetMany(map[string]core.Entry{"NegativeTTLKey": {Value: value, Duration: -1}}); err != nil {
+		t.Errorf("Setting many entries with a negative TTL shouldn't return an error: %v", err)
+	}
+
+	if _, found := client.GetTTL("NegativeTTLKey"); found {
+		t.Error("The existing key NegativeTTLKey should have been removed by SetMany")
+	}
+
+	_ = client.SetWithMeta("NegativeTTLKey", value, map[string]string{"origin": "backend-1"}, time.Minute)
+
+	if err := client.Touch("NegativeTTLKey", -1); err != nil {
+		t.Errorf("Touching with a negative TTL shouldn't return an error: %v", err)
+	}
+
+	if _, found := client.GetTTL("NegativeTTLKey"); found {
+		t.Error("The existing key NegativeTTLKey should have been removed by Touch")
+	}
+
+	if meta, _ := client.GetMeta("NegativeTTLKey"); len(meta) != 0 {
+		t.Errorf("The metadata of NegativeTTLKey should have been removed by Touch, %v provided", meta)
+	}
+}
+
+func TestBadger_SetThenGet(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the Badger instance: %v", err)
+	}
+
+	for i := range 100 {
+		key := fmt.Sprintf("SyncKey%d", i)
+		value := []byte(fmt.Sprintf("%s %d", baseValue, i))
+
+		if err = client.Set(key, value, time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key %s: %v", key, err)
+		}
+
+		if res := client.Get(key); !bytes.Equal(res, value) {
+			t.Fatalf("The key %s should be readable right after Set, %s provided", key, res)
+		}
+
+		client.Delete(key)
+
+		if res := client.Get(key); res != nil {
+			t.Fatalf("The key %s should be gone right after Delete, %s provided", key, res)
+		}
+	}
+
+	if err = client.SetMany(map[string]core.Entry{"SyncManyKey": {Value: []byte(baseValue), Duration: time.Minute}}); err != nil {
+		t.Fatalf("Impossible to set the key SyncManyKey: %v", err)
+	}
+
+	if res := client.GetMany([]string{"SyncManyKey"}); !bytes.Equal(res["SyncManyKey"], []byte(baseValue)) {
+		t.Errorf("The key SyncManyKey should be readable right after SetMany, %s provided", res["SyncManyKey"])
+	}
 }
 
 func TestBadger_DeleteRequestInCache(t *testing.T) {
 	client, _ := getBadgerInstance()
 	client.Delete(byteKey)
-	time.Sleep(1 * time.Second)
 
 	if 0 < len(client.Get(byteKey)) {
 		t.Errorf("Key %s should not exist", byteKey)
@@ -182,3 +340,2998 @@ func TestBadger_SetMultiLevel_LargeValue(t *testing.T) {
 			len(largeValue), len(retrieved), float64(len(retrieved))/float64(len(largeValue))*100)
 	}
 }
+
+func TestBadger_GetMultiLevelDebug(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	for _, encoding := range []string{"gzip", "br"} {
+		variedKey := "DebugKey-" + encoding
+		response := "HTTP/1.1 200 OK\r\nContent-Encoding: " + encoding + "\r\n\r\n" + baseValue
+		headers := http.Header{"Accept-Encoding": []string{encoding}}
+
+		if err := client.SetMultiLevel("DebugKey", variedKey, []byte(response), headers, `"etag-`+encoding+`"`, time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to store the variant %s: %v", variedKey, err)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/debug", nil)
+	req.Header.Set("Accept-Encoding", "br")
+
+	fresh, _, match := client.GetMultiLevelDebug("DebugKey", req, &core.Revalidator{})
+	if fresh == nil || fresh.Header.Get("Content-Encoding") != "br" {
+		t.Fatal("The br variant should be returned as fresh")
+	}
+
+	if match.VariedKey != "DebugKey-br" || match.ETag != `"etag-br"` || match.Stale {
+		t.Errorf("The br variant should be reported as the elected key, %+v provided", match)
+	}
+}
+
+func TestBadger_GetMultiLevelRange(t *testing.T) {
+	client, _ := getBadgerInstance()
+	body := strings.Repeat("0123456789", 100)
+	response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+
+	if err := client.SetMultiLevel("RangeKey", "RangeKey", []byte(response), http.Header{}, "", time.Minute, "RangeKey"); err != nil {
+		t.Fatalf("Impossible to store the response: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/range", nil)
+	req.Header.Set("Range", "bytes=100-199")
+
+	fresh, _ := client.GetMultiLevel("RangeKey", req, &core.Revalidator{})
+	if fresh == nil || fresh.StatusCode != http.StatusPartialContent {
+		t.Fatalf("The range request should return a 206, %v provided", fresh)
+	}
+
+	if fresh.Header.Get("Content-Range") != "bytes 100-199/1000" {
+		t.Errorf("The Content-Range should be bytes 100-199/1000, %s provided", fresh.Header.Get("Content-Range"))
+	}
+
+	if read, _ := io.ReadAll(fresh.Body); string(read) != body[100:200] {
+		t.Errorf("The body should be the bytes 100 to 199, %q provided", read)
+	}
+
+	req.Header.Set("Range", "bytes=2000-")
+
+	if fresh, _ = client.GetMultiLevel("RangeKey", req, &core.Revalidator{}); fresh == nil || fresh.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("The unsatisfiable range should return a 416, %v provided", fresh)
+	}
+}
+
+func TestBadger_GetMultiLevelAge(t *testing.T) {
+	client, _ := getBadgerInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("AgeKey", "AgeKey", []byte(response), http.Header{}, "", time.Minute, "AgeKey"); err != nil {
+		t.Fatalf("Impossible to store the key AgeKey: %v", err)
+	}
+
+	time.Sleep(time.Second)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/age", nil)
+
+	fresh, _, match := client.GetMultiLevelDebug("AgeKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if match.StoredAt.IsZero() || match.Age < time.Second {
+		t.Errorf("The age should be at least 1s, %v provided", match.Age)
+	}
+
+	if match.TTL <= 0 || match.TTL > time.Minute-time.Second {
+		t.Errorf("The remaining TTL should be between 0 and 59s, %v provided", match.TTL)
+	}
+}
+
+func TestBadger_SetMultiLevelVariants(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), MaxVariants: 3}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	store := func(language, body string) error {
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+
+		return client.SetMultiLevel("Variants", "Variants-"+language, []byte(response), http.Header{"Accept-Language": []string{language}}, "", time.Minute, "Variants-"+language)
+	}
+
+	elect := func(language string) string {
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/variants", nil)
+		req.Header.Set("Accept-Language", language)
+
+		fresh, _ := client.GetMultiLevel("Variants", req, &core.Revalidator{})
+		if fresh == nil {
+			return ""
+		}
+
+		body, _ := io.ReadAll(fresh.Body)
+
+		return string(body)
+	}
+
+	for _, language := range []string{"en", "fr", "de"} {
+		if err = store(language, "first "+language); err != nil {
+			t.Fatalf("Impossible to store the %s variant: %v", language, err)
+		}
+	}
+
+	for _, language := range []string{"en", "fr", "de"} {
+		if body := elect(language); body != "first "+language {
+			t.Errorf("The %s variant should be retrievable, %s provided", language, body)
+		}
+	}
+
+	if err = store("fr", "second fr"); err != nil {
+		t.Fatalf("Re-storing a variant shouldn't count against the limit: %v", err)
+	}
+
+	if body := elect("fr"); body != "second fr" {
+		t.Errorf("The fr variant should be updated in place, %s provided", body)
+	}
+
+	if body := elect("en"); body != "first en" {
+		t.Errorf("The sibling variants should be kept, %s provided", body)
+	}
+
+	mapping, _ := core.DecodeMapping(client.Get(core.MappingKeyPrefix + "Variants"))
+	if len(mapping.GetMapping()) != 3 {
+		t.Errorf("The mapping should hold 3 variants, %d provided", len(mapping.GetMapping()))
+	}
+
+	if err = store("es", "first es"); !errors.Is(err, core.ErrVariantLimit) {
+		t.Errorf("A fourth variant should return ErrVariantLimit, %v provided", err)
+	}
+}
+
+func TestBadger_GetTTL(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	_ = client.Set("TTLKey", []byte(baseValue), 20*time.Second)
+
+	ttl, found := client.GetTTL("TTLKey")
+	if !found {
+		t.Error("Key TTLKey should exist")
+	}
+
+	if ttl <= 0 || ttl > 20*time.Second {
+		t.Errorf("The TTL should be between 0 and 20s, %v provided", ttl)
+	}
+
+	_ = client.Set("TTLExpiredKey", []byte(baseValue), time.Second)
+	time.Sleep(2 * time.Second)
+
+	if _, found = client.GetTTL("TTLExpiredKey"); found {
+		t.Error("Key TTLExpiredKey should be expired")
+	}
+
+	if _, found = client.GetTTL(nonExistentKey); found {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+
+	_ = client.SetMultiLevel("TTLMappedKey", "TTLMappedKey", []byte(baseValue), http.Header{}, "", time.Minute, "TTLMappedKey")
+
+	if ttl, found = client.GetTTL(core.MappingKeyPrefix + "TTLMappedKey"); !found || ttl != core.NoExpiration {
+		t.Errorf("The mapping key should not expire, %v provided", ttl)
+	}
+}
+
+func TestBadger_Touch(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	_ = client.Set("TouchKey", []byte(baseValue), 5*time.Second)
+
+	if err := client.Touch("TouchKey", time.Minute); err != nil {
+		t.Errorf("Impossible to touch the key TouchKey: %v", err)
+	}
+
+	if res := client.Get("TouchKey"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+
+	if ttl, _ := client.GetTTL("TouchKey"); ttl <= 5*time.Second {
+		t.Errorf("The TTL should have been extended, %v provided", ttl)
+	}
+
+	if err := client.Touch(nonExistentKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestBadger_SetMultiLevel_Compression(t *testing.T) {
+	value := make([]byte, 512*1024)
+	for i := range value {
+		value[i] = byte(i % 256)
+	}
+
+	for _, codec := range []string{core.CompressionLZ4, core.CompressionZstd, core.CompressionNone} {
+		client, err := badger.Factory(core.CacheProvider{Compression: codec}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create badger instance: %v", err)
+		}
+
+		key := "compression_" + codec
+
+		if err = client.SetMultiLevel(key, key, value, http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Failed to set the value using the codec %s: %v", codec, err)
+		}
+
+		decompressed, err := core.Decompress(client.Get(key))
+		if err != nil {
+			t.Fatalf("Failed to decompress the value using the codec %s: %v", codec, err)
+		}
+
+		if !bytes.Equal(value, decompressed) {
+			t.Errorf("The value stored using the codec %s doesn't match the original one", codec)
+		}
+	}
+}
+
+func TestBadger_SetMultiLevel_CompressionMinSize(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{CompressionMinSize: 1024}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for name, tc := range map[string]struct {
+		value []byte
+		raw   bool
+	}{
+		"small": {[]byte("tiny value"), true},
+		"large": {bytes.Repeat([]byte(baseValue), 1<<20/len(baseValue)), false},
+	} {
+		key := "MinSizeKey_" + name
+
+		if err = client.SetMultiLevel(key, key, tc.value, http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Failed to set the %s value: %v", name, err)
+		}
+
+		stored := client.Get(key)
+		if raw := len(stored) == len(tc.value)+1 && bytes.HasSuffix(stored, tc.value); raw != tc.raw {
+			t.Errorf("The %s value should be stored raw: %t, %d bytes stored", name, tc.raw, len(stored))
+		}
+
+		if decompressed, err := core.Decompress(stored); err != nil || !bytes.Equal(decompressed, tc.value) {
+			t.Errorf("The %s value should round-trip, %v provided", name, err)
+		}
+	}
+}
+
+func TestBadger_SetMultiLevel_DisableCompression(t *testing.T) {
+	gzipped := new(bytes.Buffer)
+	writer := gzip.NewWriter(gzipped)
+	_, _ = writer.Write([]byte(strings.Repeat(baseValue, 100)))
+	_ = writer.Close()
+
+	response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Encoding: gzip\r\nContent-Length: %d\r\n\r\n%s", gzipped.Len(), gzipped.Bytes())
+
+	for name, tc := range map[string]struct {
+		configuration core.CacheProvider
+		headers       http.Header
+	}{
+		"disabled": {core.CacheProvider{DisableCompression: true}, http.Header{}},
+		"encoded":  {core.CacheProvider{}, http.Header{"Content-Encoding": []string{"gzip"}}},
+	} {
+		client, err := badger.Factory(tc.configuration, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create badger instance: %v", err)
+		}
+
+		key := "GzipKey_" + name
+
+		if err = client.SetMultiLevel(key, key, []byte(response), tc.headers, "", time.Minute, key); err != nil {
+			t.Fatalf("Failed to set the %s gzip response: %v", name, err)
+		}
+
+		if stored := client.Get(key); len(stored) != len(response)+1 || !bytes.HasSuffix(stored, []byte(response)) {
+			t.Errorf("The %s gzip response should be stored uncompressed, %d bytes provided", name, len(stored))
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/gzip", nil)
+		req.Header = tc.headers.Clone()
+
+		fresh, _ := client.GetMultiLevel(key, req, &core.Revalidator{})
+		if fresh == nil {
+			t.Fatalf("The %s gzip response should be fresh", name)
+		}
+
+		if body, _ := io.ReadAll(fresh.Body); !bytes.Equal(body, gzipped.Bytes()) {
+			t.Errorf("The %s gzip body should round-trip as is, %d bytes provided", name, len(body))
+		}
+	}
+}
+
+func TestBadger_SetMany(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	items := make(map[string]core.Entry, 100)
+	for i := range 100 {
+		items[fmt.Sprintf("SetManyKey%d", i)] = core.Entry{Value: []byte(fmt.Sprintf("%s %d", baseValue, i)), Duration: time.Minute}
+	}
+
+	if err := client.SetMany(items); err != nil {
+		t.Errorf("Impossible to set many values: %v", err)
+	}
+
+	for key, item := range items {
+		if res := client.Get(key); !bytes.Equal(res, item.Value) {
+			t.Errorf("%s not corresponding to %s for the key %s", string(res), string(item.Value), key)
+		}
+	}
+}
+
+func benchmarkEntries(b *testing.B) map[string]core.Entry {
+	b.Helper()
+
+	items := make(map[string]core.Entry, 10_000)
+	for i := range 10_000 {
+		items[fmt.Sprintf("BenchmarkKey%d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	return items
+}
+
+func BenchmarkBadger_Set(b *testing.B) {
+	client, _ := getBadgerInstance()
+	items := benchmarkEntries(b)
+
+	b.ResetTimer()
+
+	for range b.N {
+		for key, item := range items {
+			_ = client.Set(key, item.Value, item.Duration)
+		}
+	}
+}
+
+func BenchmarkBadger_SetMany(b *testing.B) {
+	client, _ := getBadgerInstance()
+	items := benchmarkEntries(b)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.SetMany(items)
+	}
+}
+
+func TestBadger_GetMany(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	_ = client.Set("GetManyKey1", []byte(baseValue), time.Minute)
+	_ = client.Set("GetManyKey2", []byte(baseValue), time.Minute)
+
+	res := client.GetMany([]string{"GetManyKey1", nonExistentKey, "GetManyKey2"})
+	if len(res) != 2 {
+		t.Errorf("The result should contain 2 keys, %d provided", len(res))
+	}
+
+	for _, key := range []string{"GetManyKey1", "GetManyKey2"} {
+		if string(res[key]) != baseValue {
+			t.Errorf("%s not corresponding to %s for the key %s", string(res[key]), baseValue, key)
+		}
+	}
+
+	if _, found := res[nonExistentKey]; found {
+		t.Errorf("The key %s should be absent from the result", nonExistentKey)
+	}
+}
+
+func BenchmarkBadger_Get(b *testing.B) {
+	client, _ := getBadgerInstance()
+	items := benchmarkEntries(b)
+	_ = client.SetMany(items)
+
+	b.ResetTimer()
+
+	for range b.N {
+		for key := range items {
+			_ = client.Get(key)
+		}
+	}
+}
+
+func BenchmarkBadger_GetMany(b *testing.B) {
+	client, _ := getBadgerInstance()
+	items := benchmarkEntries(b)
+	_ = client.SetMany(items)
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.GetMany(keys)
+	}
+}
+
+func BenchmarkBadger_GetLargeValue(b *testing.B) {
+	client, _ := getBadgerInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.Get("BenchmarkLargeKey")
+	}
+}
+
+func BenchmarkBadger_ExistsLargeValue(b *testing.B) {
+	client, _ := getBadgerInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.Exists("BenchmarkLargeKey")
+	}
+}
+
+func TestBadger_ContextCancelled(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := client.SetContext(ctx, "CancelledKey", []byte(baseValue), time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Setting with a cancelled context should return context.Canceled, %v provided", err)
+	}
+
+	if res := client.Get("CancelledKey"); res != nil {
+		t.Errorf("The key CancelledKey should not be persisted, %s provided", string(res))
+	}
+
+	_ = client.Set("ContextKey", []byte(baseValue), time.Minute)
+
+	if _, err := client.GetContext(ctx, "ContextKey"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Getting with a cancelled context should return context.Canceled, %v provided", err)
+	}
+
+	res, err := client.GetContext(context.Background(), "ContextKey")
+	if err != nil || string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s, %v", string(res), baseValue, err)
+	}
+
+	if _, err = client.GetContext(context.Background(), nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Getting the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestBadger_ScanKeys(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	items := make(map[string]core.Entry, 1000)
+	for i := range 1000 {
+		items[fmt.Sprintf("ScanKey%04d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	_ = client.SetMany(items)
+	_ = client.Set("ScanOtherKey", []byte(baseValue), time.Minute)
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("The pagination should have ended after 10 pages")
+		}
+
+		keys, next := client.ScanKeys("ScanKey", cursor, 100)
+		if len(keys) > 100 {
+			t.Errorf("The page should contain at most 100 keys, %d provided", len(keys))
+		}
+
+		for _, key := range keys {
+			if _, ok := items[key]; !ok {
+				t.Errorf("The key %s should not be part of the scan", key)
+			}
+
+			if seen[key] {
+				t.Errorf("The key %s has already been returned", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != len(items) {
+		t.Errorf("The scan should return %d keys, %d provided", len(items), len(seen))
+	}
+}
+
+func TestBadger_SortedKeys(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	// Inserted in reverse order so the sort doesn't come from the writes.
+	expected := []string{}
+	for i := 99; i >= 0; i-- {
+		key := fmt.Sprintf("SortedKey%02d", i)
+		expected = append(expected, key)
+
+		_ = client.Set(key, []byte(baseValue), time.Minute)
+	}
+
+	_ = client.Set("SortedOtherKey", []byte(baseValue), time.Minute)
+	_ = client.Set(core.EntryMetaKeyPrefix+"SortedKey00", []byte(baseValue), time.Minute)
+
+	keys := client.SortedKeys("SortedKey")
+	if !slices.IsSorted(keys) {
+		t.Errorf("The keys should be sorted, %v provided", keys)
+	}
+
+	slices.Sort(expected)
+
+	if !slices.Equal(keys, expected) {
+		t.Errorf("The %d keys prefixed by SortedKey should be returned exactly, %d provided", len(expected), len(keys))
+	}
+}
+
+func TestBadger_SetMultiLevel_CacheControl(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+	variedKey := "CacheControlKey" + core.VarySeparator + "s-maxage"
+
+	headers := http.Header{"Cache-Control": []string{"max-age=60, s-maxage=600"}}
+	if err := client.SetMultiLevel("CacheControlKey", variedKey, []byte(response), headers, "", time.Hour, variedKey); err != nil {
+		t.Fatalf("Impossible to set the multi-level key: %v", err)
+	}
+
+	if ttl, found := client.GetTTL(variedKey); !found || ttl <= 9*time.Minute || ttl > 10*time.Minute {
+		t.Errorf("The s-maxage should set the time to live, %v provided", ttl)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	// The Cache-Control directives are varied headers too, the request must carry the same ones.
+	if fresh, _ := client.GetMultiLevel("CacheControlKey", req, &core.Revalidator{}); fresh != nil {
+		t.Error("The request without the Cache-Control directives shouldn't match the response")
+	}
+
+	req.Header.Set("Cache-Control", "max-age=60, s-maxage=600")
+
+	if fresh, _ := client.GetMultiLevel("CacheControlKey", req, &core.Revalidator{}); fresh == nil {
+		t.Error("The response stored with its Cache-Control directives should be read back")
+	}
+
+	headers = http.Header{"Cache-Control": []string{"no-store"}}
+	if err := client.SetMultiLevel("CacheControlKey", variedKey, []byte(response), headers, "", time.Hour, variedKey); err != nil {
+		t.Fatalf("The no-store response should be ignored without error: %v", err)
+	}
+
+	if client.Exists(variedKey) {
+		t.Error("The no-store response should not be cached")
+	}
+
+	if fresh, _ := client.GetMultiLevel("CacheControlKey", req, &core.Revalidator{}); fresh != nil {
+		t.Error("The no-store response should replace the stored one")
+	}
+
+	if err := client.SetMultiLevel("CacheControlKey", variedKey, []byte(response), http.Header{}, "", time.Hour, variedKey); err != nil {
+		t.Fatalf("Impossible to set the multi-level key: %v", err)
+	}
+
+	if ttl, found := client.GetTTL(variedKey); !found || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("The given duration should be used without directive, %v provided", ttl)
+	}
+
+	if fresh, _ := client.GetMultiLevel("CacheControlKey", req, &core.Revalidator{}); fresh == nil {
+		t.Error("The response stored without directive should be read back")
+	}
+}
+
+func TestBadger_SetMultiLevel_Jitter(t *testing.T) {
+	for _, jitter := range []float64{0, 0.1} {
+		client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), TTLJitter: jitter}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance: %v", err)
+		}
+
+		response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+		variedKey := "JitterKey" + core.VarySeparator + "max-age"
+		headers := http.Header{"Cache-Control": []string{"max-age=600"}}
+
+		if err = client.SetMultiLevel("JitterKey", variedKey, []byte(response), headers, "", time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to set the multi-level key: %v", err)
+		}
+
+		if ttl, found := client.GetTTL(variedKey); !found || ttl < 9*time.Minute-time.Second || ttl > 11*time.Minute {
+			t.Errorf("The max-age should set the time to live with the %v jitter, %v provided", jitter, ttl)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+		if fresh, _ := client.GetMultiLevel("JitterKey", req, &core.Revalidator{}); fresh != nil {
+			t.Errorf("The %v jitter shouldn't change the varied headers matched by the request", jitter)
+		}
+
+		req.Header.Set("Cache-Control", "max-age=600")
+
+		if fresh, _ := client.GetMultiLevel("JitterKey", req, &core.Revalidator{}); fresh == nil {
+			t.Errorf("The request matching the varied headers should hit with the %v jitter", jitter)
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestBadger_EstimateCompressedSize(t *testing.T) {
+	for _, compression := range []string{core.CompressionLZ4, core.CompressionZstd, core.CompressionAuto} {
+		configuration := core.CacheProvider{Path: t.TempDir(), Compression: compression}
+
+		client, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance: %v", err)
+		}
+
+		codec := core.ConfiguredCompression(configuration, zap.NewNop().Sugar())
+
+		for i, value := range [][]byte{[]byte(baseValue), bytes.Repeat([]byte(baseValue), 1<<10), []byte(strings.Repeat("a", 1<<16))} {
+			key := fmt.Sprintf("EstimatedKey_%d", i)
+			if err = client.SetMultiLevel(key, key, value, http.Header{}, "", time.Minute, key); err != nil {
+				t.Fatalf("Impossible to set the multi-level key: %v", err)
+			}
+
+			estimate, err := core.EstimateCompressedSize(value, codec)
+			if err != nil {
+				t.Fatalf("Impossible to estimate the compressed size: %v", err)
+			}
+
+			if stored := len(client.Get(key)); estimate != stored {
+				t.Errorf("The %s estimate of the value %d should match the stored size %d, %d provided", compression, i, stored, estimate)
+			}
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestBadger_GetMultiLevel_NoCache(t *testing.T) {
+	client, _ := getBadgerInstance()
+	response := "HTTP/1.1 200 OK\r\nCache-Control: stale-while-revalidate=60\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("NoCacheKey", "NoCacheKey", []byte(response), http.Header{}, "", time.Minute, "NoCacheKey"); err != nil {
+		t.Fatalf("Impossible to set the multi-level key: %v", err)
+	}
+
+	for directive, forced := range map[string]bool{
+		"":                       false,
+		"max-age=60":             false,
+		"no-cache":               true,
+		"max-age=0":              true,
+		"no-transform, NO-CACHE": true,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/no-cache", nil)
+		if directive != "" {
+			req.Header.Set("Cache-Control", directive)
+		}
+
+		validator := &core.Revalidator{}
+
+		fresh, stale := client.GetMultiLevel("NoCacheKey", req, validator)
+		if (fresh == nil) != forced || (stale != nil) != forced {
+			t.Errorf("The fresh response should be returned as stale to the %q request: %t", directive, forced)
+		}
+
+		if validator.ForcedRevalidation != forced || validator.Revalidate {
+			t.Errorf("The %q request should force the revalidation: %t, %+v provided", directive, forced, validator)
+		}
+	}
+}
+
+func TestBadger_SetMultiLevel_VaryAll(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	for name, headers := range map[string]http.Header{
+		"vary":      {"Vary": []string{"*"}},
+		"vary list": {"Vary": []string{"Accept-Encoding, *"}},
+		"member":    {"*": []string{""}},
+	} {
+		key := "VaryAllKey_" + name
+		if err = client.SetMultiLevel(key, key, []byte(response), headers, "", time.Minute, key); err != nil {
+			t.Fatalf("Impossible to set the %s Vary: * response: %v", name, err)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/vary", nil)
+
+		fresh, stale := client.GetMultiLevel(key, req, &core.Revalidator{})
+		if fresh != nil {
+			t.Errorf("The %s Vary: * response should never be fresh", name)
+		}
+
+		if stale == nil {
+			t.Errorf("The %s Vary: * response should be kept stale for the revalidation", name)
+		}
+	}
+
+	headers := http.Header{"Accept-Encoding": []string{"gzip"}}
+	if err = client.SetMultiLevel("VariedKey", "VariedKey", []byte(response), headers, "", time.Minute, "VariedKey"); err != nil {
+		t.Fatalf("Impossible to set the varied response: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/vary", nil)
+	req.Header = headers.Clone()
+
+	if fresh, _ := client.GetMultiLevel("VariedKey", req, &core.Revalidator{}); fresh == nil {
+		t.Error("The response varied on a header should be fresh for a matching request")
+	}
+}
+
+func TestBadger_DeleteKeys(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 4 {
+		_ = client.SetWithMeta(fmt.Sprintf("DeleteKeys_%d", i), []byte(baseValue), map[string]string{"index": fmt.Sprint(i)}, time.Minute)
+	}
+
+	db := client.(*badger.Badger).DB
+	version := db.MaxVersion()
+
+	if err = client.DeleteKeys([]string{"DeleteKeys_0", nonExistentKey, "DeleteKeys_2"}); err != nil {
+		t.Fatalf("The missing keys should be ignored: %v", err)
+	}
+
+	if commits := db.MaxVersion() - version; commits != 1 {
+		t.Errorf("The keys should be deleted in a single transaction, %d provided", commits)
+	}
+
+	for i, deleted := range []bool{true, false, true, false} {
+		key := fmt.Sprintf("DeleteKeys_%d", i)
+		if client.Exists(key) == deleted {
+			t.Errorf("The key %s should be deleted: %t", key, deleted)
+		}
+
+		if _, err = client.GetMeta(key); errors.Is(err, core.ErrKeyNotFound) != deleted {
+			t.Errorf("The metadata of the key %s should be deleted: %t, %v provided", key, deleted, err)
+		}
+	}
+}
+
+func TestBadger_MapKeys_InternalKeys(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 3 {
+		key := fmt.Sprintf("UserKey_%d", i)
+		if err = client.SetMultiLevel(key, key, []byte(baseValue), http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Impossible to set the multi-level key %s: %v", key, err)
+		}
+	}
+
+	keys := client.MapKeys("")
+	if len(keys) != 3 {
+		t.Errorf("MapKeys should only return the 3 user keys, %v provided", keys)
+	}
+
+	for key := range keys {
+		if core.IsInternalKey(key) {
+			t.Errorf("The internal key %s should not be listed", key)
+		}
+	}
+
+	if scanned, next := client.ScanKeys("", "", 0); len(scanned) != 3 || next != "" {
+		t.Errorf("ScanKeys should only return the 3 user keys, %v provided with the next cursor %s", scanned, next)
+	}
+
+	if mappings := client.MapKeys(core.MappingKeyPrefix); len(mappings) != 3 || mappings["UserKey_0"] == "" {
+		t.Errorf("MapKeys should return the 3 mappings with the mapping prefix, %v provided", mappings)
+	}
+}
+
+func TestBadger_DeleteMany(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	for i := range 10 {
+		_ = client.Set(fmt.Sprintf("SURROGATE_first_%d", i), []byte(baseValue), time.Minute)
+		_ = client.Set(fmt.Sprintf("SURROGATE_second_%d", i), []byte(baseValue), time.Minute)
+	}
+
+	client.DeleteMany("SURROGATE_first_*")
+
+	if keys, _ := client.ScanKeys("SURROGATE_first_", "", 0); len(keys) != 0 {
+		t.Errorf("The keys %v should have been deleted", keys)
+	}
+
+	if keys, _ := client.ScanKeys("SURROGATE_second_", "", 0); len(keys) != 10 {
+		t.Errorf("The 10 keys with the other prefix should be kept, %d provided", len(keys))
+	}
+
+	client.DeleteMany("SURROGATE_second_")
+
+	if keys, _ := client.ScanKeys("SURROGATE_second_", "", 0); len(keys) != 0 {
+		t.Errorf("The keys %v should have been deleted", keys)
+	}
+}
+
+type countingMetrics struct {
+	hits, misses, errors int
+}
+
+func (m *countingMetrics) IncHit(string) { m.hits++ }
+
+func (m *countingMetrics) IncMiss(string) { m.misses++ }
+
+func (m *countingMetrics) IncError(string, string) { m.errors++ }
+
+func TestBadger_Metrics(t *testing.T) {
+	metrics := &countingMetrics{}
+
+	client, err := badger.Factory(core.CacheProvider{Metrics: metrics}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	_ = client.Set("MetricsKey", []byte(baseValue), time.Minute)
+	_ = client.Get("MetricsKey")
+	_ = client.Get(nonExistentKey)
+
+	if metrics.hits != 1 || metrics.misses != 1 || metrics.errors != 0 {
+		t.Errorf("Expected 1 hit and 1 miss, %+v provided", *metrics)
+	}
+}
+
+func TestBadger_SetGetStream(t *testing.T) {
+	// The in-memory mode caps the values to the value threshold, the streamed value needs the value log.
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	const size = 20 * 1024 * 1024
+
+	written := sha256.New()
+	source := io.TeeReader(io.LimitReader(rand.New(rand.NewSource(1)), size), written)
+
+	if err = client.SetStream("StreamKey", source, time.Minute); err != nil {
+		t.Fatalf("Impossible to stream the value: %v", err)
+	}
+
+	reader, err := client.GetStream("StreamKey")
+	if err != nil {
+		t.Fatalf("Impossible to get the stream: %v", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	read := sha256.New()
+
+	n, err := io.Copy(read, reader)
+	if err != nil {
+		t.Fatalf("Impossible to read the stream: %v", err)
+	}
+
+	if n != size || !bytes.Equal(written.Sum(nil), read.Sum(nil)) {
+		t.Errorf("The streamed value doesn't match the original one, %d bytes read", n)
+	}
+
+	if _, err = client.GetStream(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Streaming the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestBadger_Close(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	client, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	_ = client.Set("CloseKey", []byte(baseValue), time.Minute)
+
+	if err = client.Close(); err != nil {
+		t.Fatalf("Impossible to close the badger instance: %v", err)
+	}
+
+	if _, err = client.GetContext(context.Background(), "CloseKey"); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Getting a key after Close should return ErrClosed, %v provided", err)
+	}
+
+	if res := client.Get("CloseKey"); res != nil {
+		t.Errorf("Getting a key after Close should return nil, %s provided", res)
+	}
+
+	if err = client.Set("CloseKey", []byte(baseValue), time.Minute); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Setting a key after Close should return ErrClosed, %v provided", err)
+	}
+
+	if err = client.Close(); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Closing twice should return ErrClosed, %v provided", err)
+	}
+
+	client, err = badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if res := client.Get("CloseKey"); string(res) != baseValue {
+		t.Errorf("The reopened instance should contain the key CloseKey, %s provided", res)
+	}
+}
+
+func TestBadger_SetNX(t *testing.T) {
+	client, _ := getBadgerInstance()
+	client.Delete("SetNXKey")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := client.SetNX("SetNXKey", []byte(fmt.Sprintf("worker %d", i)), time.Minute)
+			if err != nil {
+				t.Errorf("Impossible to set the key SetNXKey if not exists: %v", err)
+			}
+
+			if ok {
+				created.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", created.Load())
+	}
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); ok {
+		t.Error("The existing key SetNXKey shouldn't be overridden")
+	}
+
+	client.Delete("SetNXKey")
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); !ok {
+		t.Error("The deleted key SetNXKey should be created again")
+	}
+}
+
+// lateMissStorer holds the first held misses until it's released, the callers miss the key but register their
+// fill only once released.
+type lateMissStorer struct {
+	core.Storer
+	held    int32
+	misses  atomic.Int32
+	missed  sync.WaitGroup
+	release chan struct{}
+}
+
+func (s *lateMissStorer) GetWithError(key string) ([]byte, error) {
+	value, err := s.Storer.GetWithError(key)
+	if errors.Is(err, core.ErrKeyNotFound) && s.misses.Add(1) <= s.held {
+		s.missed.Done()
+		<-s.release
+	}
+
+	return value, err
+}
+
+func TestBadger_GetOrSet(t *testing.T) {
+	client, _ := getBadgerInstance()
+	client.Delete("GetOrSetKey")
+
+	var (
+		wg    sync.WaitGroup
+		fills atomic.Int32
+	)
+
+	start := make(chan struct{})
+	fill := func() ([]byte, error) {
+		fills.Add(1)
+
+		return []byte(baseValue), nil
+	}
+
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+
+			value, err := core.GetOrSet(client, "GetOrSetKey", time.Minute, fill)
+			if err != nil || string(value) != baseValue {
+				t.Errorf("The value %s should be returned, %s provided with the error %v", baseValue, value, err)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	if fills.Load() != 1 {
+		t.Errorf("The fill should run exactly once, %d calls provided", fills.Load())
+	}
+
+	if value := client.Get("GetOrSetKey"); string(value) != baseValue {
+		t.Errorf("The filled value should be stored, %s provided", value)
+	}
+
+	// The late callers missed the key before the first flight and only register once it has completed.
+	client.Delete("GetOrSetKey")
+	fills.Store(0)
+
+	late := &lateMissStorer{Storer: client, held: 10, release: make(chan struct{})}
+	late.missed.Add(int(late.held))
+
+	for range late.held {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if value, err := core.GetOrSet(late, "GetOrSetKey", time.Minute, fill); err != nil || string(value) != baseValue {
+				t.Errorf("The value %s should be returned to the late caller, %s provided with the error %v", baseValue, value, err)
+			}
+		}()
+	}
+
+	late.missed.Wait()
+
+	if value, err := core.GetOrSet(client, "GetOrSetKey", time.Minute, fill); err != nil || string(value) != baseValue {
+		t.Errorf("The value %s should be filled, %s provided with the error %v", baseValue, value, err)
+	}
+
+	close(late.release)
+	wg.Wait()
+
+	if fills.Load() != 1 {
+		t.Errorf("The late callers shouldn't fill the key again, %d calls provided", fills.Load())
+	}
+
+	errFill := errors.New("fill failure")
+	if _, err := core.GetOrSet(client, "GetOrSetFailure", time.Minute, func() ([]byte, error) { return nil, errFill }); !errors.Is(err, errFill) {
+		t.Errorf("The fill error should be returned, %v provided", err)
+	}
+
+	if client.Exists("GetOrSetFailure") {
+		t.Error("The failed fill shouldn't store anything")
+	}
+}
+
+func TestBadger_ConflictRetries(t *testing.T) {
+	path := t.TempDir()
+
+	// The instances of the same path share the DB, each one retries the conflicts as configured.
+	clients := map[int]core.Storer{}
+
+	for _, attempts := range []int{1, 100} {
+		client, err := badger.Factory(core.CacheProvider{Path: path, ConflictMaxAttempts: attempts}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance: %v", err)
+		}
+
+		defer func() { _ = client.Close() }()
+
+		clients[attempts] = client
+	}
+
+	for attempts, client := range clients {
+		var (
+			wg     sync.WaitGroup
+			failed atomic.Int32
+		)
+
+		// The variants of the same base key all update its mapping, the concurrent transactions conflict.
+		for i := range 50 {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				for j := range 10 {
+					key := fmt.Sprintf("ConflictKey-%d-%d-%d", attempts, i, j)
+					if err := client.SetMultiLevel(fmt.Sprintf("ConflictBase-%d", attempts), key, []byte("HTTP/1.1 200 OK\r\n\r\n"), http.Header{}, "", time.Minute, key); err != nil {
+						if !errors.Is(err, badgerdb.ErrConflict) {
+							t.Errorf("Only the conflicts should fail the writes, %v provided", err)
+						}
+
+						failed.Add(1)
+					}
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		if attempts == 1 && failed.Load() == 0 {
+			t.Error("The concurrent writes should conflict without any retry")
+		}
+
+		if attempts > 1 && failed.Load() != 0 {
+			t.Errorf("The conflicting writes should succeed after the retries, %d failed", failed.Load())
+		}
+
+		failed.Store(0)
+
+		// The counters and the compare-and-swap conflict the same way and are retried as configured.
+		for i := range 50 {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				var err error
+				if i%2 == 0 {
+					_, err = client.Increment(fmt.Sprintf("ConflictCounter-%d", attempts), 1, time.Minute)
+				} else {
+					_, err = client.CompareAndSwap(fmt.Sprintf("ConflictSwap-%d", attempts), nil, []byte("value"), time.Minute)
+				}
+
+				if err != nil {
+					if !errors.Is(err, badgerdb.ErrConflict) {
+						t.Errorf("Only the conflicts should fail the writes, %v provided", err)
+					}
+
+					failed.Add(1)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		if attempts > 1 && failed.Load() != 0 {
+			t.Errorf("The conflicting counters should succeed after the retries, %d failed", failed.Load())
+		}
+
+		if value, _ := core.DecodeCounter(client.Get(fmt.Sprintf("ConflictCounter-%d", attempts))); attempts > 1 && value != 25 {
+			t.Errorf("The 25 concurrent increments should be counted, %d provided", value)
+		}
+	}
+}
+
+func TestBadger_CompareAndSwap(t *testing.T) {
+	client, _ := getBadgerInstance()
+	client.Delete("CASKey")
+
+	var (
+		wg      sync.WaitGroup
+		swapped atomic.Int32
+	)
+
+	contend := func(old []byte, prefix string) {
+		swapped.Store(0)
+
+		for i := range 50 {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				ok, err := client.CompareAndSwap("CASKey", old, []byte(fmt.Sprintf("%s %d", prefix, i)), time.Minute)
+				if err != nil {
+					t.Errorf("Impossible to compare and swap the key CASKey: %v", err)
+				}
+
+				if ok {
+					swapped.Add(1)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	}
+
+	contend(nil, "created")
+
+	if swapped.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", swapped.Load())
+	}
+
+	contend(client.Get("CASKey"), "swapped")
+
+	if swapped.Load() != 1 {
+		t.Errorf("Exactly one worker should have swapped the value it read, %d provided", swapped.Load())
+	}
+
+	_ = client.Set("CASCounterKey", core.EncodeCounter(0), time.Minute)
+	swapped.Store(0)
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for done := 0; done < 5; {
+				current := client.Get("CASCounterKey")
+				value, _ := core.DecodeCounter(current)
+
+				ok, err := client.CompareAndSwap("CASCounterKey", current, core.EncodeCounter(value+1), time.Minute)
+				if err != nil {
+					t.Errorf("Impossible to compare and swap the key CASCounterKey: %v", err)
+
+					return
+				}
+
+				if ok {
+					done++
+
+					swapped.Add(1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CASCounterKey")); value != 100 || swapped.Load() != 100 {
+		t.Errorf("The 100 swaps should be counted, %d swaps and the value %d provided", swapped.Load(), value)
+	}
+
+	if ok, _ := client.CompareAndSwap("CASKey", []byte("unexpected"), []byte(baseValue), time.Minute); ok {
+		t.Error("A different value shouldn't be swapped")
+	}
+
+	client.Delete("CASKey")
+
+	if ok, _ := client.CompareAndSwap("CASKey", []byte(baseValue), []byte(baseValue), time.Minute); ok {
+		t.Error("A missing key shouldn't be swapped with a non-nil old value")
+	}
+}
+
+func TestBadger_Increment(t *testing.T) {
+	client, _ := getBadgerInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 100 {
+		t.Errorf("The 100 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 30, time.Minute); value != 70 {
+		t.Errorf("The counter should be decremented to 70, %d provided", value)
+	}
+
+	_ = client.Set("NotCounterKey", []byte(baseValue), time.Minute)
+
+	if _, err := client.Increment("NotCounterKey", 1, time.Minute); !errors.Is(err, core.ErrNotCounter) {
+		t.Errorf("Incrementing a value that isn't a counter should return ErrNotCounter, %v provided", err)
+	}
+}
+
+func TestBadger_Namespace(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	configuration.Namespace = "first"
+	first, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the first badger instance: %v", err)
+	}
+
+	defer func() { _ = first.Close() }()
+
+	configuration.Namespace = "second"
+	second, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the second badger instance: %v", err)
+	}
+
+	_ = first.Set("NamespaceKey_1", []byte("first"), time.Minute)
+	_ = second.Set("NamespaceKey_1", []byte("second"), time.Minute)
+	_ = second.Set("NamespaceKey_2", []byte("second"), time.Minute)
+
+	if res := first.Get("NamespaceKey_1"); string(res) != "first" {
+		t.Errorf("The first namespace should return its own value, %s provided", res)
+	}
+
+	if res := second.Get("NamespaceKey_1"); string(res) != "second" {
+		t.Errorf("The second namespace should return its own value, %s provided", res)
+	}
+
+	if keys := first.MapKeys("NamespaceKey_"); len(keys) != 1 || keys["1"] != "first" {
+		t.Errorf("The first namespace should only map its own keys, %v provided", keys)
+	}
+
+	if keys, _ := second.ScanKeys("NamespaceKey_", "", 0); len(keys) != 2 || keys[0] != "NamespaceKey_1" || keys[1] != "NamespaceKey_2" {
+		t.Errorf("The second namespace should only scan its own unprefixed keys, %v provided", keys)
+	}
+
+	first.Delete("NamespaceKey_1")
+
+	if res := first.Get("NamespaceKey_1"); res != nil {
+		t.Errorf("The key NamespaceKey_1 should have been deleted from the first namespace, %s provided", res)
+	}
+
+	if res := second.Get("NamespaceKey_1"); string(res) != "second" {
+		t.Errorf("Deleting from the first namespace shouldn't affect the second one, %s provided", res)
+	}
+}
+
+func TestBadger_Encrypted(t *testing.T) {
+	client, _ := getBadgerInstance()
+	encrypted := core.Encrypted(client, bytes.Repeat([]byte("k"), 32))
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := encrypted.SetMultiLevel("EncryptedKey", "EncryptedKey", []byte(response), http.Header{}, "", time.Minute, "EncryptedKey"); err != nil {
+		t.Fatalf("Impossible to set the encrypted response: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/encrypted", nil)
+
+	fresh, _ := encrypted.GetMultiLevel("EncryptedKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The encrypted response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The decrypted body should be %s, %s provided", baseValue, body)
+	}
+
+	if fresh, stale := core.Encrypted(client, bytes.Repeat([]byte("w"), 32)).GetMultiLevel("EncryptedKey", req, &core.Revalidator{}); fresh != nil || stale != nil {
+		t.Error("The response shouldn't be readable using another key")
+	}
+}
+
+func TestBadger_Checksummed(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	checksummed, err := core.Checksummed(client, core.ChecksumCRC32C, nil)
+	if err != nil {
+		t.Fatalf("Impossible to checksum the values: %v", err)
+	}
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err = checksummed.SetMultiLevel("ChecksumKey", "ChecksumKey", []byte(response), http.Header{}, "", time.Minute, "ChecksumKey"); err != nil {
+		t.Fatalf("Impossible to set the checksummed response: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/checksum", nil)
+
+	fresh, _ := checksummed.GetMultiLevel("ChecksumKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The checksummed response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The body should be %s without trailer, %s provided", baseValue, body)
+	}
+
+	sealed, _ := core.Decompress(client.Get("ChecksumKey"))
+	sealed[len(sealed)-5] ^= 0xff
+	corrupted, _ := core.Compress(core.CompressionNone, sealed)
+	_ = client.Set("ChecksumKey", corrupted, time.Minute)
+
+	if fresh, stale := checksummed.GetMultiLevel("ChecksumKey", req, &core.Revalidator{}); fresh != nil || stale != nil {
+		t.Error("The corrupted response shouldn't be returned")
+	}
+}
+
+func TestBadger_ChecksummedReads(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	checksummed, _ := core.Checksummed(client, core.ChecksumCRC32C, nil)
+	response := "HTTP/1.1 200 OK\r\nSurrogate-Key: checksum\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err = checksummed.SetMultiLevel("ChecksumReads", "ChecksumReads", []byte(response), http.Header{}, "", time.Minute, "ChecksumReads"); err != nil {
+		t.Fatalf("Impossible to set the checksummed response: %v", err)
+	}
+
+	entries, err := checksummed.GetBySurrogate("checksum")
+	if decompressed, _ := core.Decompress(entries["ChecksumReads"]); err != nil || string(decompressed) != response {
+		t.Errorf("GetBySurrogate should return the response without trailer, %q and %v provided", decompressed, err)
+	}
+
+	if info, err := checksummed.Inspect("ChecksumReads"); err != nil || info.ValueLength != len(response) {
+		t.Errorf("Inspect should report the length without trailer, %+v and %v provided", info, err)
+	}
+
+	if err = checksummed.Export(io.Discard); err != nil {
+		t.Errorf("The verified values should be exported, %v provided", err)
+	}
+
+	sealed, _ := core.Decompress(client.Get("ChecksumReads"))
+	sealed[len(sealed)-5] ^= 0xff
+	corrupted, _ := core.Compress(core.CompressionNone, sealed)
+	_ = client.Set("ChecksumReads", corrupted, time.Minute)
+
+	if entries, _ = checksummed.GetBySurrogate("checksum"); len(entries) != 0 {
+		t.Error("GetBySurrogate shouldn't return the corrupted response")
+	}
+
+	if _, err = checksummed.Inspect("ChecksumReads"); !errors.Is(err, core.ErrChecksumMismatch) {
+		t.Errorf("Inspect should return ErrChecksumMismatch for the corrupted response, %v provided", err)
+	}
+
+	if err = checksummed.Export(io.Discard); !errors.Is(err, core.ErrChecksumMismatch) {
+		t.Errorf("Export should return ErrChecksumMismatch for the corrupted response, %v provided", err)
+	}
+}
+
+func TestBadger_ChecksummedMapper(t *testing.T) {
+	clock := core.NewManualClock(time.Now())
+
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), Checksum: core.ChecksumXXHash, Mapper: core.JSONMapper{}, Clock: clock}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+	variedKey := "ChecksumMapper" + core.VarySeparator + "gzip"
+
+	if err = client.SetMultiLevel("ChecksumMapper", variedKey, []byte(response), http.Header{}, "", time.Minute, variedKey); err != nil {
+		t.Fatalf("Impossible to set the checksummed response: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	if fresh, _ := client.GetMultiLevel("ChecksumMapper", req, &core.Revalidator{}); fresh == nil {
+		t.Fatal("The checksummed response should be elected with the JSON mapping")
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	if fresh, _ := client.GetMultiLevel("ChecksumMapper", req, &core.Revalidator{}); fresh != nil {
+		t.Error("The checksummed response should be elected with the configured clock")
+	}
+}
+
+func TestBadger_EncryptedReads(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	encrypted := core.Encrypted(client, bytes.Repeat([]byte("k"), 32))
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err = encrypted.SetMultiLevel("EncryptedReads", "EncryptedReads", []byte(response), http.Header{}, "", time.Minute, "EncryptedReads"); err != nil {
+		t.Fatalf("Impossible to set the encrypted response: %v", err)
+	}
+
+	if info, err := encrypted.Inspect("EncryptedReads"); err != nil || info.ValueLength != len(response) {
+		t.Errorf("Inspect should report the decrypted length, %+v and %v provided", info, err)
+	}
+
+	if err = encrypted.Export(io.Discard); err != nil {
+		t.Errorf("The authenticated values should be exported, %v provided", err)
+	}
+
+	wrongKey := core.Encrypted(client, bytes.Repeat([]byte("w"), 32))
+
+	if _, err = wrongKey.Inspect("EncryptedReads"); !errors.Is(err, core.ErrDecryption) {
+		t.Errorf("Inspect should return ErrDecryption with another key, %v provided", err)
+	}
+
+	if err = wrongKey.Export(io.Discard); !errors.Is(err, core.ErrDecryption) {
+		t.Errorf("Export should return ErrDecryption with another key, %v provided", err)
+	}
+}
+
+func TestBadger_EncryptedMapper(t *testing.T) {
+	clock := core.NewManualClock(time.Now())
+	configuration := core.CacheProvider{Path: t.TempDir(), Mapper: core.JSONMapper{}, Clock: clock}
+
+	client, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	encrypted := core.EncryptedWith(client, bytes.Repeat([]byte("k"), 32), core.ConfiguredMapper(configuration))
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+	variedKey := "EncryptedMapper" + core.VarySeparator + "gzip"
+
+	if err = encrypted.SetMultiLevel("EncryptedMapper", variedKey, []byte(response), http.Header{}, "", time.Minute, variedKey); err != nil {
+		t.Fatalf("Impossible to set the encrypted response: %v", err)
+	}
+
+	if info, err := client.Inspect(variedKey); err != nil || info.Codec != core.CompressionNone {
+		t.Errorf("The sealed response should be stored uncompressed, %+v and %v provided", info, err)
+	}
+
+	if mapping := client.Get(core.MappingKeyPrefix + "EncryptedMapper"); bytes.Contains(mapping, []byte(":compression")) {
+		t.Errorf("The codec handed to the storage shouldn't be mapped as a varied header, %s provided", mapping)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	if fresh, _ := encrypted.GetMultiLevel("EncryptedMapper", req, &core.Revalidator{}); fresh == nil {
+		t.Fatal("The encrypted response should be elected with the JSON mapping")
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	if fresh, _ := encrypted.GetMultiLevel("EncryptedMapper", req, &core.Revalidator{}); fresh != nil {
+		t.Error("The encrypted response should be elected with the configured clock")
+	}
+}
+
+func TestBadger_Stats(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestBadger_Ping(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	if err = client.Ping(context.Background()); err != nil {
+		t.Errorf("Pinging a healthy store shouldn't fail: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err = client.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pinging with a done context should return its error, %v provided", err)
+	}
+
+	_ = client.Close()
+
+	if err = client.Ping(context.Background()); err == nil {
+		t.Error("Pinging a closed store should fail")
+	}
+}
+
+func TestBadger_ValueLogGC(t *testing.T) {
+	dir := t.TempDir()
+	interval := 20 * time.Millisecond
+
+	client, err := badger.Factory(core.CacheProvider{
+		Configuration: map[string]interface{}{
+			"Dir":              dir,
+			"ValueDir":         dir,
+			"ValueThreshold":   1 << 10,
+			"ValueLogFileSize": 1 << 20,
+		},
+		ValueLogGCInterval:     &interval,
+		ValueLogGCDiscardRatio: 0.1,
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	value := bytes.Repeat([]byte(baseValue), 1<<10)
+
+	for i := range 300 {
+		_ = client.Set(fmt.Sprintf("GCKey%d", i), value, time.Minute)
+	}
+
+	client.DeleteMany("GCKey")
+	time.Sleep(10 * interval)
+
+	if err = client.Set("GCKey", []byte(baseValue), time.Minute); err != nil {
+		t.Errorf("Impossible to set the key GCKey after the GC: %v", err)
+	}
+
+	if res := client.Get("GCKey"); string(res) != baseValue {
+		t.Errorf("The key GCKey should be readable after the GC, %s provided", res)
+	}
+
+	if err = client.Close(); err != nil {
+		t.Errorf("Impossible to close the badger instance running the GC: %v", err)
+	}
+}
+
+func TestBadger_ValueLogGC_Disabled(t *testing.T) {
+	disabled := time.Duration(0)
+
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), ValueLogGCInterval: &disabled}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	_ = client.Set("GCDisabledKey", []byte(baseValue), time.Minute)
+
+	if res := client.Get("GCDisabledKey"); string(res) != baseValue {
+		t.Errorf("The key GCDisabledKey should be readable, %s provided", res)
+	}
+
+	if err = client.Close(); err != nil {
+		t.Errorf("Impossible to close the badger instance without GC: %v", err)
+	}
+}
+
+func TestBadger_Exists(t *testing.T) {
+	client, _ := getBadgerInstance()
+	_ = client.Set("ExistsKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExistsExpiredKey", []byte(baseValue), time.Second)
+
+	if !client.Exists("ExistsKey") {
+		t.Error("The key ExistsKey should exist")
+	}
+
+	if client.Exists(nonExistentKey) {
+		t.Errorf("The key %s should not exist", nonExistentKey)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if client.Exists("ExistsExpiredKey") {
+		t.Error("The expired key ExistsExpiredKey should not exist")
+	}
+}
+
+func TestBadger_KeyHashing(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir(), KeyHashing: core.KeyHashingSHA256}
+
+	client, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	first := "SURROGATE_" + strings.Repeat("https://domain.com/very/long/path", 100) + "/first"
+	second := "SURROGATE_" + strings.Repeat("https://domain.com/very/long/path", 100) + "/second"
+
+	_ = client.Set(first, []byte("first"), time.Minute)
+	_ = client.Set(second, []byte("second"), time.Minute)
+
+	if res := client.Get(first); string(res) != "first" {
+		t.Errorf("The first long key should be readable, %s provided", res)
+	}
+
+	if res := client.Get(second); string(res) != "second" {
+		t.Errorf("The second long key shouldn't collide with the first one, %s provided", res)
+	}
+
+	configuration.KeyHashing = ""
+	raw, _ := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+
+	if raw.Exists(first) {
+		t.Errorf("The key %s should be stored hashed", first)
+	}
+
+	sum := sha256.Sum256([]byte(first))
+	if res := raw.Get(hex.EncodeToString(sum[:])); string(res) != "first" {
+		t.Errorf("The value should be stored under the SHA-256 of the key, %s provided", res)
+	}
+
+	keys := client.MapKeys("SURROGATE_")
+	if len(keys) != 2 || keys[strings.TrimPrefix(first, "SURROGATE_")] != "first" {
+		t.Errorf("The reverse index should map the original keys, %v provided", keys)
+	}
+
+	client.DeleteMany("SURROGATE_")
+
+	if client.Exists(first) || client.Exists(second) || len(client.MapKeys("SURROGATE_")) != 0 {
+		t.Error("The hashed keys should be deleted through the reverse index")
+	}
+}
+
+func TestBadger_KeyHashingStale(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir(), KeyHashing: core.KeyHashingXXHash}
+
+	client, err := badger.Factory(configuration, zap.NewNop().Sugar(), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if err = client.SetMultiLevel("HashedBase", "HashedVaried", []byte("HTTP/1.1 200 OK\r\n\r\n"), http.Header{}, "", time.Minute, "HashedVaried"); err != nil {
+		t.Fatalf("Impossible to set the hashed response: %v", err)
+	}
+
+	configuration.KeyHashing = ""
+	raw, _ := badger.Factory(configuration, zap.NewNop().Sugar(), time.Hour)
+
+	indexed := false
+
+	for hashed, key := range raw.MapKeys(core.HashedKeyPrefix) {
+		if key != "HashedVaried" {
+			continue
+		}
+
+		indexed = true
+
+		if ttl, _ := raw.GetTTL(core.HashedKeyPrefix + hashed); ttl <= time.Minute {
+			t.Errorf("The reverse index should live as long as the stale response, %v provided", ttl)
+		}
+	}
+
+	if !indexed {
+		t.Error("The varied key should be indexed")
+	}
+
+	if keys := client.ListKeys(); !slices.Contains(keys, "HashedVaried") {
+		t.Errorf("The stale response should be listed by its real key, %v provided", keys)
+	}
+}
+
+func TestBadger_KeyHashingReads(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), KeyHashing: core.KeyHashingXXHash}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	_ = client.Set("HashedPlain", []byte(baseValue), time.Minute)
+
+	if info, err := client.Inspect("HashedPlain"); err != nil || info.Key != "HashedPlain" || info.ValueLength != len(baseValue) {
+		t.Errorf("The plain hashed key should be inspected, %+v and %v provided", info, err)
+	}
+
+	response := "HTTP/1.1 200 OK\r\nSurrogate-Key: hashed\r\nContent-Length: 13\r\n\r\n" + baseValue
+	variedKey := "HashedReads" + core.VarySeparator + "gzip"
+
+	if err = client.SetMultiLevel("HashedReads", variedKey, []byte(response), http.Header{}, "", time.Minute, variedKey); err != nil {
+		t.Fatalf("Impossible to set the hashed response: %v", err)
+	}
+
+	info, err := client.Inspect("HashedReads")
+	if err != nil || info.Key != "HashedReads" || !slices.Equal(info.VariedKeys, []string{variedKey}) {
+		t.Errorf("The hashed base key should be inspected with its original varied keys, %+v and %v provided", info, err)
+	}
+
+	entries, err := client.GetBySurrogate("hashed")
+	if _, found := entries[variedKey]; err != nil || len(entries) != 1 || !found {
+		t.Errorf("GetBySurrogate should key the responses by their original varied key, %v and %v provided", slices.Collect(maps.Keys(entries)), err)
+	}
+
+	if surrogateKeys, err := client.ListSurrogates(); err != nil || !slices.Equal(surrogateKeys, []string{"hashed"}) {
+		t.Errorf("ListSurrogates should return the surrogate keys in clear, %v and %v provided", surrogateKeys, err)
+	}
+}
+
+func TestBadger_GetWithError(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	_ = client.Set("GetWithErrorKey", []byte(baseValue), time.Minute)
+
+	if res, err := client.GetWithError("GetWithErrorKey"); err != nil || string(res) != baseValue {
+		t.Errorf("The key GetWithErrorKey should be returned without error, %s and %v provided", res, err)
+	}
+
+	if res, err := client.GetWithError(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) || res != nil {
+		t.Errorf("A missing key should return ErrKeyNotFound, %s and %v provided", res, err)
+	}
+
+	_ = client.Close()
+
+	if _, err = client.GetWithError("GetWithErrorKey"); !errors.Is(err, core.ErrClosed) || errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A closed store should return ErrClosed, %v provided", err)
+	}
+}
+
+func TestBadger_MaxCacheSizeBytes(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), MaxCacheSizeBytes: 64 << 10}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	provider := client.(*badger.Badger)
+	value := bytes.Repeat([]byte("v"), 1<<10)
+
+	for i := range 128 {
+		_ = client.Set(fmt.Sprintf("EvictionKey_%d", i), value, time.Minute)
+	}
+
+	if res := client.Get("EvictionKey_0"); res == nil {
+		t.Fatal("The first key should be stored before the eviction")
+	}
+
+	if err = provider.Evict(); err != nil {
+		t.Fatalf("Impossible to evict the entries: %v", err)
+	}
+
+	var total int64
+
+	_ = provider.View(func(txn *badgerdb.Txn) error {
+		iterator := txn.NewIterator(badgerdb.DefaultIteratorOptions)
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			total += iterator.Item().EstimatedSize()
+		}
+
+		return nil
+	})
+
+	if total > 64<<10 {
+		t.Errorf("The entries should fit the budget after the eviction, %d bytes provided", total)
+	}
+
+	if res := client.Get("EvictionKey_0"); res == nil {
+		t.Error("The recently accessed key should survive the eviction")
+	}
+
+	if res := client.Get("EvictionKey_127"); res == nil {
+		t.Error("The recently written key should survive the eviction")
+	}
+
+	if res := client.Get("EvictionKey_1"); res != nil {
+		t.Error("The least recently used key should be evicted")
+	}
+}
+
+func TestBadger_DirPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The directory permissions aren't supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "permissions")
+
+	client, err := badger.Factory(core.CacheProvider{Path: path, DirPermissions: 0o750}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("The directory should be created: %v", err)
+	}
+
+	if info.Mode().Perm() != 0o750 {
+		t.Errorf("The directory should be created with the configured permissions, %v provided", info.Mode().Perm())
+	}
+}
+
+func TestBadger_CompactionOptions(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{
+		Path:                    t.TempDir(),
+		NumCompactors:           3,
+		NumLevelZeroTables:      8,
+		NumLevelZeroTablesStall: 20,
+		BaseTableSize:           4 << 20,
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	opts := client.(*badger.Badger).Opts()
+	if opts.NumCompactors != 3 || opts.NumLevelZeroTables != 8 || opts.NumLevelZeroTablesStall != 20 || opts.BaseTableSize != 4<<20 {
+		t.Errorf("The compaction options should be applied, %+v provided", opts)
+	}
+
+	if err = client.SetMultiLevel("CompactionKey", "CompactionKey", []byte(baseValue), http.Header{}, "", time.Minute, "CompactionKey"); err != nil {
+		t.Errorf("Impossible to set the multi-level key: %v", err)
+	}
+
+	if err = client.Set("CompactionKey_raw", []byte(baseValue), time.Minute); err != nil {
+		t.Errorf("Impossible to set the key: %v", err)
+	}
+
+	if res := client.Get("CompactionKey_raw"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", res, baseValue)
+	}
+
+	client.Delete("CompactionKey_raw")
+
+	if client.Get("CompactionKey_raw") != nil {
+		t.Error("The deleted key should not be returned")
+	}
+
+	fallback, err := badger.Factory(core.CacheProvider{
+		Path:                    t.TempDir(),
+		NumCompactors:           1,
+		NumLevelZeroTables:      8,
+		NumLevelZeroTablesStall: 4,
+		BaseTableSize:           1 << 10,
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("The out of range options should fall back, %v provided", err)
+	}
+
+	defer func() { _ = fallback.Close() }()
+
+	opts = fallback.(*badger.Badger).Opts()
+	if opts.NumCompactors != 4 || opts.NumLevelZeroTables != 8 || opts.NumLevelZeroTablesStall != 24 || opts.BaseTableSize != 2<<20 {
+		t.Errorf("The out of range options should fall back, %+v provided", opts)
+	}
+
+	if err = fallback.Set("CompactionKey", []byte(baseValue), time.Minute); err != nil || string(fallback.Get("CompactionKey")) != baseValue {
+		t.Errorf("The store should remain usable with the fallback options: %v", err)
+	}
+}
+
+func TestBadger_Reset(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 5 {
+		if err = client.Set(fmt.Sprintf("ResetKey_%d", i), []byte(baseValue), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key ResetKey_%d: %v", i, err)
+		}
+	}
+
+	if err = client.Reset(); err != nil {
+		t.Fatalf("Impossible to reset the badger instance: %v", err)
+	}
+
+	if keys := client.MapKeys(""); len(keys) != 0 {
+		t.Errorf("No key should remain after the reset, %v provided", keys)
+	}
+
+	if err = client.Set("ResetKey_new", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("The store should remain usable after the reset: %v", err)
+	}
+
+	if res := client.Get("ResetKey_new"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", res, baseValue)
+	}
+}
+
+func TestBadger_SharedPath(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	first, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the first badger instance: %v", err)
+	}
+
+	second, err := badger.Factory(configuration, zap.NewNop().Sugar(), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create the second badger instance on the same path: %v", err)
+	}
+
+	if err = first.Set("SharedKey", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key from the first instance: %v", err)
+	}
+
+	if res := second.Get("SharedKey"); string(res) != baseValue {
+		t.Errorf("The second instance should read the key set by the first one, %s provided", res)
+	}
+
+	if err = first.Close(); err != nil {
+		t.Fatalf("Impossible to close the first instance: %v", err)
+	}
+
+	if err = first.Close(); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Closing the first instance twice should return ErrClosed, %v provided", err)
+	}
+
+	if err = second.Set("SharedKey", []byte("updated"), time.Minute); err != nil {
+		t.Errorf("The second instance should remain usable after closing the first one: %v", err)
+	}
+
+	if res := second.Get("SharedKey"); string(res) != "updated" {
+		t.Errorf("The second instance should remain usable after closing the first one, %s provided", res)
+	}
+
+	if err = second.Close(); err != nil {
+		t.Fatalf("Impossible to close the second instance: %v", err)
+	}
+
+	third, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("The path should be reopened once every instance is closed: %v", err)
+	}
+
+	defer func() { _ = third.Close() }()
+
+	if res := third.Get("SharedKey"); string(res) != "updated" {
+		t.Errorf("The reopened instance should read the persisted key, %s provided", res)
+	}
+}
+
+func TestBadger_SharedPathConcurrentFactory(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+	instances := make([]core.Storer, 8)
+	errs := make([]error, len(instances))
+
+	var wg sync.WaitGroup
+
+	for i := range instances {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			instances[i], errs[i] = badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance %d: %v", i, err)
+		}
+	}
+
+	for i, instance := range instances {
+		if err := instance.Set("ConcurrentKey", []byte(baseValue), time.Minute); err != nil {
+			t.Errorf("The instance %d should remain usable until every instance is closed: %v", i, err)
+		}
+
+		if err := instance.Close(); err != nil {
+			t.Errorf("Impossible to close the instance %d: %v", i, err)
+		}
+	}
+}
+
+func TestBadger_DumpMeta(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 3 {
+		if err = client.Set(fmt.Sprintf("MetaKey_%d", i), []byte(baseValue), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key MetaKey_%d: %v", i, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = client.(core.MetaDumper).DumpMeta(&buf); err != nil {
+		t.Fatalf("Impossible to dump the keys metadata: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("The dump should contain 3 lines, %d provided: %s", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		var record core.MetaRecord
+		if err = json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("The line %s should be valid JSON: %v", line, err)
+		}
+
+		if !strings.HasPrefix(record.Key, "MetaKey_") || record.Size <= 0 || record.TTL <= 0 || record.TTL > 60 {
+			t.Errorf("Unexpected metadata record %+v", record)
+		}
+	}
+}
+
+func TestBadger_Iterate(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 10 {
+		if err = client.Set(fmt.Sprintf("IterateKey_%d", i), []byte(fmt.Sprintf("IterateValue_%d", i)), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key IterateKey_%d: %v", i, err)
+		}
+	}
+
+	entries, errs := client.(core.Iterator).Iterate(context.Background())
+	seen := map[string]bool{}
+
+	for entry := range entries {
+		if string(entry.Value) != strings.Replace(entry.Key, "Key", "Value", 1) {
+			t.Errorf("Unexpected value %s for the key %s", entry.Value, entry.Key)
+		}
+
+		seen[entry.Key] = true
+	}
+
+	if err = <-errs; err != nil {
+		t.Errorf("The iteration shouldn't fail, %v provided", err)
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("The iteration should yield 10 entries, %d provided", len(seen))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, errs = client.(core.Iterator).Iterate(ctx)
+
+	<-entries
+	cancel()
+
+	remaining := 0
+	for range entries {
+		remaining++
+	}
+
+	if remaining > 1 {
+		t.Errorf("The cancelled iteration should stop, %d more entries provided", remaining)
+	}
+
+	if err = <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("The cancelled iteration should return context.Canceled, %v provided", err)
+	}
+}
+
+func TestBadger_Watch(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
+
+func TestBadger_ReadOnly(t *testing.T) {
+	path := t.TempDir()
+
+	writer, err := badger.Factory(core.CacheProvider{Path: path}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	if err = writer.Set("ReadOnlyKey", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key ReadOnlyKey: %v", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Impossible to close the badger instance: %v", err)
+	}
+
+	client, err := badger.Factory(core.CacheProvider{Path: path, ReadOnly: true}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to open the badger instance read-only: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if res := client.Get("ReadOnlyKey"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", res, baseValue)
+	}
+
+	if keys := client.MapKeys("ReadOnly"); keys["Key"] != baseValue {
+		t.Errorf("The read-only instance should map the stored keys, %v provided", keys)
+	}
+
+	if err = client.Set("ReadOnlyNew", []byte(baseValue), time.Minute); !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The Set should return ErrReadOnly, %v provided", err)
+	}
+
+	err = client.SetMultiLevel("ReadOnlyNew", "ReadOnlyNew", []byte(baseValue), http.Header{}, "", time.Minute, "ReadOnlyNew")
+	if !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The SetMultiLevel should return ErrReadOnly, %v provided", err)
+	}
+
+	client.Delete("ReadOnlyKey")
+
+	if res := client.Get("ReadOnlyKey"); string(res) != baseValue {
+		t.Errorf("The Delete shouldn't remove the key of a read-only instance, %s provided", res)
+	}
+}
+
+func TestBadger_InvalidateSurrogate(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	responses := map[string]string{
+		"SurrogateFirst":  "group first",
+		"SurrogateSecond": "group",
+		"SurrogateOther":  "other",
+	}
+
+	for key, surrogateKeys := range responses {
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: %s\r\nContent-Length: %d\r\n\r\n%s", surrogateKeys, len(baseValue), baseValue)
+		if err = client.SetMultiLevel(key, key, []byte(response), http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Impossible to set the response %s: %v", key, err)
+		}
+	}
+
+	client.Delete("SurrogateFirst")
+
+	if index := client.Get(core.SurrogateKeyPrefix + "first"); index != nil {
+		t.Errorf("The Delete should remove the emptied surrogate index, %s provided", index)
+	}
+
+	if err = client.SetMultiLevel("SurrogateFirst", "SurrogateFirst", []byte("HTTP/1.1 200 OK\r\nSurrogate-Key: group\r\nContent-Length: 0\r\n\r\n"), http.Header{}, "", time.Minute, "SurrogateFirst"); err != nil {
+		t.Fatalf("Impossible to set the response SurrogateFirst: %v", err)
+	}
+
+	deleted, err := client.InvalidateSurrogate("group")
+	if err != nil {
+		t.Fatalf("Impossible to invalidate the surrogate key group: %v", err)
+	}
+
+	if deleted != 2 {
+		t.Errorf("The invalidation should delete the 2 responses tagged by group, %d provided", deleted)
+	}
+
+	if client.Exists("SurrogateFirst") || client.Exists("SurrogateSecond") {
+		t.Error("The responses tagged by group should be deleted")
+	}
+
+	if !client.Exists("SurrogateOther") {
+		t.Error("The response tagged by other shouldn't be deleted")
+	}
+
+	if deleted, _ = client.InvalidateSurrogate("group"); deleted != 0 {
+		t.Errorf("A second invalidation shouldn't delete anything, %d provided", deleted)
+	}
+}
+
+func TestBadger_ListSurrogates(t *testing.T) {
+	for _, shards := range []int{0, 4} {
+		client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), Shards: shards}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance: %v", err)
+		}
+
+		// Spread over several shards, the shared surrogate keys are indexed by each of them.
+		expected := []string{"group", "other"}
+
+		for i := range 8 {
+			key := fmt.Sprintf("ListSurrogates_%d", i)
+			response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: group other item-%d\r\nContent-Length: 0\r\n\r\n", i)
+			expected = append(expected, fmt.Sprintf("item-%d", i))
+
+			if err = client.SetMultiLevel(key, key, []byte(response), http.Header{}, "", time.Minute, key); err != nil {
+				t.Fatalf("Impossible to set the response %s: %v", key, err)
+			}
+		}
+
+		// More surrogate keys than a single scanned page.
+		many := make([]string, 0, 1200)
+		for i := range 1200 {
+			many = append(many, fmt.Sprintf("many-%04d", i))
+		}
+
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: %s\r\nContent-Length: 0\r\n\r\n", strings.Join(many, " "))
+		if err = client.SetMultiLevel("ListSurrogates_many", "ListSurrogates_many", []byte(response), http.Header{}, "", time.Minute, "ListSurrogates_many"); err != nil {
+			t.Fatalf("Impossible to set the response ListSurrogates_many: %v", err)
+		}
+
+		expected = append(expected, many...)
+		slices.Sort(expected)
+
+		surrogateKeys, err := client.ListSurrogates()
+		if err != nil {
+			t.Fatalf("Impossible to list the surrogate keys with %d shards: %v", shards, err)
+		}
+
+		if !slices.Equal(surrogateKeys, expected) {
+			t.Errorf("The %d shards should list the %d surrogate keys once each, %d provided", shards, len(expected), len(surrogateKeys))
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestBadger_GetBySurrogate(t *testing.T) {
+	client, _ := getBadgerInstance()
+	expected := map[string][]byte{}
+
+	for i, surrogateKeys := range []string{"preview", "preview other", "other", "preview", "preview"} {
+		key := fmt.Sprintf("GetBySurrogate_%d", i)
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: %s\r\nContent-Length: 1\r\n\r\n%d", surrogateKeys, i)
+
+		duration := time.Minute
+		if i == 4 {
+			duration = time.Second
+		}
+
+		if err := client.SetMultiLevel(key, key, []byte(response), http.Header{}, "", duration, key); err != nil {
+			t.Fatalf("Impossible to set the response %s: %v", key, err)
+		}
+
+		if strings.Contains(surrogateKeys, "preview") && i != 4 {
+			expected[key] = client.Get(key)
+		}
+	}
+
+	// The short lived response stays in the index once expired.
+	time.Sleep(2100 * time.Millisecond)
+
+	entries, err := client.GetBySurrogate("preview")
+	if err != nil {
+		t.Fatalf("Impossible to get the entries by surrogate: %v", err)
+	}
+
+	if !maps.EqualFunc(entries, expected, bytes.Equal) {
+		t.Errorf("The %d live responses tagged by the surrogate key should be returned, %v provided", len(expected), slices.Collect(maps.Keys(entries)))
+	}
+
+	if entries, err = client.GetBySurrogate("unknown"); err != nil || len(entries) != 0 {
+		t.Errorf("An unknown surrogate key should return no entry, %v %v provided", entries, err)
+	}
+}
+
+func TestBadger_Inspect(t *testing.T) {
+	client, _ := getBadgerInstance()
+
+	if err := client.Set("InspectPlain", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the plain key: %v", err)
+	}
+
+	info, err := client.Inspect("InspectPlain")
+	if err != nil {
+		t.Fatalf("Impossible to inspect the plain key: %v", err)
+	}
+
+	if info.ValueLength != len(baseValue) || info.CompressedSize != len(baseValue) || info.Codec != "" {
+		t.Errorf("The plain key should be reported uncompressed with its length, %+v provided", info)
+	}
+
+	if info.TTL <= 0 || info.TTL > time.Minute || !info.CreatedAt.IsZero() || len(info.VariedKeys) != 0 || len(info.SurrogateKeys) != 0 {
+		t.Errorf("The plain key should only report its TTL, %+v provided", info)
+	}
+
+	before := time.Now()
+
+	for _, encoding := range []string{"gzip", "br"} {
+		variedKey := "InspectBase-" + encoding
+		body := strings.Repeat("inspected body ", 100)
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: inspect inspect-%s\r\nContent-Length: %d\r\n\r\n%s", encoding, len(body), body)
+
+		if err = client.SetMultiLevel("InspectBase", variedKey, []byte(response), http.Header{"Accept-Encoding": {encoding}}, "", time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to store the variant %s: %v", variedKey, err)
+		}
+	}
+
+	if info, err = client.Inspect("InspectBase"); err != nil {
+		t.Fatalf("Impossible to inspect the multi-level key: %v", err)
+	}
+
+	if !slices.Equal(info.VariedKeys, []string{"InspectBase-br", "InspectBase-gzip"}) {
+		t.Errorf("The varied keys should be reported, %v provided", info.VariedKeys)
+	}
+
+	if !slices.Equal(info.SurrogateKeys, []string{"inspect", "inspect-br", "inspect-gzip"}) {
+		t.Errorf("The surrogate keys of the mapped responses should be reported, %v provided", info.SurrogateKeys)
+	}
+
+	if info.CreatedAt.Before(before.Truncate(time.Second)) || info.CreatedAt.After(time.Now()) || info.TTL <= 0 {
+		t.Errorf("The multi-level key should report its storage time and TTL, %+v provided", info)
+	}
+
+	if info, err = client.Inspect("InspectBase-br"); err != nil {
+		t.Fatalf("Impossible to inspect the varied key: %v", err)
+	}
+
+	if info.Codec != core.CompressionLZ4 || info.ValueLength <= info.CompressedSize || info.CompressedSize == 0 {
+		t.Errorf("The varied key should be reported compressed with lz4, %+v provided", info)
+	}
+
+	if !slices.Equal(info.SurrogateKeys, []string{"inspect", "inspect-br"}) {
+		t.Errorf("The surrogate keys of the varied response should be reported, %v provided", info.SurrogateKeys)
+	}
+
+	if ttl, _ := client.GetTTL("InspectBase-br"); ttl < info.TTL-time.Second {
+		t.Errorf("The inspection shouldn't alter the TTL, %v then %v provided", info.TTL, ttl)
+	}
+
+	if _, err = client.Inspect("InspectMissing"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A missing key should return ErrKeyNotFound, %v provided", err)
+	}
+}
+
+func TestBadger_MaxKeys(t *testing.T) {
+	path := t.TempDir()
+
+	client, err := badger.Factory(core.CacheProvider{Path: path, MaxKeys: 3}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	for i := range 3 {
+		if err = client.Set(fmt.Sprintf("MaxKey%d", i), []byte(baseValue), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key MaxKey%d under the limit: %v", i, err)
+		}
+	}
+
+	if err = client.Set("MaxKey3", []byte(baseValue), time.Minute); !errors.Is(err, core.ErrKeyLimit) {
+		t.Errorf("The key over the limit should be rejected with ErrKeyLimit, %v provided", err)
+	}
+
+	if err = client.SetMultiLevel("MaxKey3", "MaxKey3", []byte(baseValue), http.Header{}, "", time.Minute, "MaxKey3"); !errors.Is(err, core.ErrKeyLimit) {
+		t.Errorf("The response over the limit should be rejected with ErrKeyLimit, %v provided", err)
+	}
+
+	if err = client.Set("MaxKey0", []byte("updated"), time.Minute); err != nil {
+		t.Errorf("The existing key should be updated at the limit, %v provided", err)
+	}
+
+	client.Delete("MaxKey1")
+
+	if err = client.Set("MaxKey3", []byte(baseValue), time.Minute); err != nil {
+		t.Errorf("The deletion should make room for a new key, %v provided", err)
+	}
+
+	_ = client.Close()
+
+	// The stored keys are counted on the next opening, the least recently written first.
+	client, err = badger.Factory(core.CacheProvider{Path: path, MaxKeys: 3, MaxKeysPolicy: core.MaxKeysEvictOldest}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if err = client.SetMultiLevel("MaxKey4", "MaxKey4", []byte(baseValue), http.Header{}, "", time.Minute, "MaxKey4"); err != nil {
+		t.Fatalf("The response over the limit should evict the oldest key, %v provided", err)
+	}
+
+	if err = client.Set("MaxKey5", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("The key over the limit should evict the oldest key, %v provided", err)
+	}
+
+	if keys := client.SortedKeys("MaxKey"); !slices.Equal(keys, []string{"MaxKey3", "MaxKey4", "MaxKey5"}) {
+		t.Errorf("The 2 oldest keys should be evicted, %v left", keys)
+	}
+
+	variedKey := "MaxBase" + core.VarySeparator + "gzip"
+	for _, keys := range [][2]string{{"MaxBase", variedKey}, {"MaxBase", "MaxVaried"}, {"MaxOther", "MaxOther"}} {
+		if err = client.SetMultiLevel(keys[0], keys[1], []byte("HTTP/1.1 200 OK\r\n\r\n"), http.Header{}, "", time.Minute, keys[1]); err != nil {
+			t.Fatalf("The response %s over the limit should evict the oldest key, %v provided", keys[1], err)
+		}
+	}
+
+	// The evicted MaxKey4 response was the only one of its mapping.
+	if client.Exists(core.MappingKeyPrefix + "MaxKey4") {
+		t.Error("The mapping of the evicted response should be deleted")
+	}
+
+	// The MaxBase varied key is the oldest one now, MaxVaried is left in the shared mapping.
+	_ = client.Set("MaxKey6", []byte(baseValue), time.Minute)
+
+	mapping, err := core.DecodeMapping(client.Get(core.MappingKeyPrefix + "MaxBase"))
+	if err != nil || len(mapping.GetMapping()) != 1 || mapping.GetMapping()["MaxVaried"] == nil {
+		t.Errorf("The evicted varied key should be removed from its mapping, %v and %v provided", mapping.GetMapping(), err)
+	}
+}
+
+func TestBadger_SetWithMeta(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	meta := map[string]string{"origin": "backend-1", "region": "eu-west", "content-type": "text/html"}
+	if err = client.SetWithMeta("MetaKey", []byte(baseValue), meta, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value with its metadata: %v", err)
+	}
+
+	stored, err := client.GetMeta("MetaKey")
+	if err != nil || !maps.Equal(stored, meta) {
+		t.Errorf("The metadata should round-trip, %v and %v provided", stored, err)
+	}
+
+	if value := client.Get("MetaKey"); string(value) != baseValue {
+		t.Errorf("The value should be stored as is, %s provided", value)
+	}
+
+	if _, err = client.GetMeta(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A key without metadata should return ErrKeyNotFound, %v provided", err)
+	}
+
+	if err = client.Touch("MetaKey", time.Hour); err != nil {
+		t.Fatalf("Impossible to touch the key: %v", err)
+	}
+
+	if ttl, _ := client.GetTTL(core.EntryMetaKeyPrefix + "MetaKey"); ttl <= time.Minute {
+		t.Errorf("The Touch should extend the metadata record, %v provided", ttl)
+	}
+
+	if err = client.Set("Overwritten", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value: %v", err)
+	}
+
+	if err = client.SetWithMeta("Overwritten", []byte(baseValue), meta, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value with its metadata: %v", err)
+	}
+
+	if err = client.Set("Overwritten", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to overwrite the value: %v", err)
+	}
+
+	if _, err = client.GetMeta("Overwritten"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("The metadata of a value overwritten by Set shouldn't be returned, %v provided", err)
+	}
+
+	client.Delete("MetaKey")
+
+	if _, err = client.GetMeta("MetaKey"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("The metadata should be deleted with the key, %v provided", err)
+	}
+
+	if record := client.Get(core.EntryMetaKeyPrefix + "MetaKey"); record != nil {
+		t.Errorf("The metadata record should be deleted with the key, %s provided", record)
+	}
+}
+
+func TestBadger_InMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in-memory")
+	configuration := core.CacheProvider{Path: path, InMemory: true}
+
+	client, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the in-memory badger instance: %v", err)
+	}
+
+	if err = client.Set("InMemoryKey", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value: %v", err)
+	}
+
+	if res := client.Get("InMemoryKey"); string(res) != baseValue {
+		t.Errorf("The value should be read back, %s provided", res)
+	}
+
+	if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("The in-memory mode shouldn't write the directory %s, %v provided", path, err)
+	}
+
+	other, err := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the second in-memory badger instance: %v", err)
+	}
+
+	defer func() { _ = other.Close() }()
+
+	if res := other.Get("InMemoryKey"); res != nil {
+		t.Errorf("The in-memory instances shouldn't share their data, %s provided", res)
+	}
+
+	_ = client.Close()
+
+	reopened, _ := badger.Factory(configuration, zap.NewNop().Sugar(), 0)
+	defer func() { _ = reopened.Close() }()
+
+	if res := reopened.Get("InMemoryKey"); res != nil {
+		t.Errorf("Close should drop the in-memory data, %s provided", res)
+	}
+}
+
+func BenchmarkBadger(b *testing.B) {
+	instance, _ := getBadgerInstance()
+
+	core.BenchmarkStorer(b, instance)
+}
+
+func TestBadger_CompressionBlockSize(t *testing.T) {
+	body := make([]byte, 5*1024*1024)
+	for i := range body {
+		body[i] = byte('a' + i%26)
+	}
+
+	response := append([]byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n", len(body))), body...)
+
+	for _, blockSize := range []int{64 << 10, 4 << 20} {
+		client, err := badger.Factory(core.CacheProvider{InMemory: true, CompressionBlockSize: blockSize}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the badger instance: %v", err)
+		}
+
+		if err = client.SetMultiLevel("BlockSize", "BlockSize-varied", response, http.Header{}, "", time.Minute, "BlockSize-varied"); err != nil {
+			t.Fatalf("Impossible to store the response using the block size %d: %v", blockSize, err)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/block-size", nil)
+
+		fresh, _ := client.GetMultiLevel("BlockSize", req, &core.Revalidator{})
+		if fresh == nil {
+			t.Fatalf("The response stored using the block size %d should be returned", blockSize)
+		}
+
+		if res, _ := io.ReadAll(fresh.Body); !bytes.Equal(res, body) {
+			t.Errorf("The body stored using the block size %d doesn't match the original one", blockSize)
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestBadger_OnEvict(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		evicted = map[string]core.EvictReason{}
+	)
+
+	onEvict := func(key string, reason core.EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		evicted[key] = reason
+	}
+
+	clock := core.NewManualClock(time.Now())
+
+	client, err := badger.Factory(core.CacheProvider{Path: t.TempDir(), MaxCacheSizeBytes: 64 << 10, OnEvict: onEvict, Clock: clock}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the badger instance: %v", err)
+	}
+
+	_ = client.Set("DeletedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExpiredKey", []byte(baseValue), time.Minute)
+
+	client.Delete("DeletedKey")
+	client.Delete(nonExistentKey)
+
+	// The expiries are told by the clock and reported by the eviction pass, not by the reads.
+	clock.Advance(2 * time.Minute)
+
+	if err = client.(*badger.Badger).Evict(); err != nil {
+		t.Fatalf("Impossible to evict the entries: %v", err)
+	}
+
+	if res := client.Get("ExpiredKey"); res != nil {
+		t.Fatalf("The expired key should be deleted by the eviction pass, %s provided", res)
+	}
+
+	value := bytes.Repeat([]byte("v"), 1<<10)
+	for i := range 128 {
+		_ = client.Set(fmt.Sprintf("EvictionKey_%d", i), value, time.Hour)
+	}
+
+	if err = client.(*badger.Badger).Evict(); err != nil {
+		t.Fatalf("Impossible to evict the entries: %v", err)
+	}
+
+	// Close delivers the pending notifications.
+	_ = client.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if reason, ok := evicted["DeletedKey"]; !ok || reason != core.EvictDeleted {
+		t.Errorf("The deleted key should be notified as deleted, %v provided", reason)
+	}
+
+	if reason, ok := evicted["ExpiredKey"]; !ok || reason != core.EvictExpired {
+		t.Errorf("The expired key should be notified as expired, %v provided", reason)
+	}
+
+	if _, ok := evicted[nonExistentKey]; ok {
+		t.Error("The deletion of a missing key shouldn't be notified")
+	}
+
+	if reason, ok := evicted["EvictionKey_0"]; !ok || reason != core.EvictSize {
+		t.Errorf("The oldest key should be notified as evicted for size, %v provided", reason)
+	}
+}
+
+// shardOf returns the index of the shard storing the key, -1 when none does.
+func shardOf(provider *badger.Sharded, key string) int {
+	for i, shard := range provider.Shards() {
+		if shard.Exists(key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestBadger_Sharded(t *testing.T) {
+	path := t.TempDir()
+
+	client, err := badger.Factory(core.CacheProvider{Path: path, Shards: 4}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the sharded badger instance: %v", err)
+	}
+
+	provider := client.(*badger.Sharded)
+	placement := map[string]int{}
+	used := map[int]bool{}
+
+	for i := range 100 {
+		key := fmt.Sprintf("ShardedKey_%d", i)
+		if err = client.Set(key, []byte(key), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key %s: %v", key, err)
+		}
+
+		placement[key] = shardOf(provider, key)
+		used[placement[key]] = true
+	}
+
+	for i := range 4 {
+		if _, err = os.Stat(filepath.Join(path, fmt.Sprintf("shard-%d", i))); err != nil {
+			t.Errorf("The shard %d should have its own subdirectory, %v provided", i, err)
+		}
+	}
+
+	if len(used) != 4 {
+		t.Errorf("The keys should be spread across the 4 shards, %d used", len(used))
+	}
+
+	mapped := client.MapKeys("ShardedKey_")
+	if len(mapped) != 100 {
+		t.Errorf("MapKeys should aggregate the 100 keys of the shards, %d provided", len(mapped))
+	}
+
+	if mapped["42"] != "ShardedKey_42" {
+		t.Errorf("MapKeys should return the value of the key, %s provided", mapped["42"])
+	}
+
+	scanned := []string{}
+	cursor := ""
+
+	for {
+		page, next := client.ScanKeys("ShardedKey_", cursor, 7)
+		scanned = append(scanned, page...)
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if !slices.IsSorted(scanned) || len(slices.Compact(slices.Clone(scanned))) != 100 {
+		t.Errorf("ScanKeys should page through the 100 sorted keys once, %d provided", len(scanned))
+	}
+
+	iterated := 0
+	entries, errs := client.(core.Iterator).Iterate(context.Background())
+
+	for entry := range entries {
+		if string(entry.Value) != entry.Key {
+			t.Errorf("Unexpected value %s for the key %s", entry.Value, entry.Key)
+		}
+
+		iterated++
+	}
+
+	if err = <-errs; err != nil || iterated != 100 {
+		t.Errorf("Iterate should stream the 100 keys of the shards, %d provided with %v", iterated, err)
+	}
+
+	watched, err := client.(core.Watcher).Watch(context.Background(), "ShardedKey_0")
+	if err != nil {
+		t.Fatalf("Impossible to watch the shards: %v", err)
+	}
+
+	client.Delete("ShardedKey_0")
+
+	if event := <-watched; event != (core.Event{Type: core.EventDelete, Key: "ShardedKey_0"}) {
+		t.Errorf("The deletion should be streamed from its shard, %s of %s provided", event.Type, event.Key)
+	}
+
+	if client.Exists("ShardedKey_0") || len(client.MapKeys("ShardedKey_")) != 99 {
+		t.Error("The deleted key should be removed from its shard")
+	}
+
+	_ = client.Close()
+
+	reopened, err := badger.Factory(core.CacheProvider{Path: path, Shards: 4}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen the sharded badger instance: %v", err)
+	}
+
+	defer func() { _ = reopened.Close() }()
+
+	for key, shard := range placement {
+		if key == "ShardedKey_0" {
+			continue
+		}
+
+		if current := shardOf(reopened.(*badger.Sharded), key); current != shard {
+			t.Errorf("The key %s should land in the shard %d after reopening, %d provided", key, shard, current)
+		}
+
+		if value := reopened.Get(key); string(value) != key {
+			t.Errorf("The key %s should be read back from its shard, %s provided", key, value)
+		}
+	}
+
+	if err = reopened.Reset(); err != nil || len(reopened.ListKeys()) != 0 {
+		t.Errorf("Reset should empty every shard, %v provided", err)
+	}
+}
+
+func TestBadger_ShardedMultiLevel(t *testing.T) {
+	client, err := badger.Factory(core.CacheProvider{InMemory: true, Shards: 4}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the sharded badger instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	provider := client.(*badger.Sharded)
+	split := 0
+
+	for i := range 20 {
+		baseKey := fmt.Sprintf("ShardedBase_%d", i)
+		variedKey := baseKey + "-varied"
+		response := []byte("HTTP/1.1 200 OK\r\nContent-Length: 4\r\n\r\nbody")
+
+		if err = client.SetMultiLevel(baseKey, variedKey, response, http.Header{}, "", time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to store the varied key %s: %v", variedKey, err)
+		}
+
+		if shardOf(provider, variedKey) != shardOf(provider, core.MappingKeyPrefix+baseKey) {
+			split++
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/sharded", nil)
+
+		fresh, _ := client.GetMultiLevel(baseKey, req, &core.Revalidator{})
+		if fresh == nil {
+			t.Fatalf("The response of the base key %s should be returned", baseKey)
+		}
+
+		if body, _ := io.ReadAll(fresh.Body); string(body) != "body" {
+			t.Errorf("The body of the base key %s should be returned, %s provided", baseKey, body)
+		}
+	}
+
+	if split == 0 {
+		t.Error("Some varied keys should be stored apart from their mapping")
+	}
+}
+
+func BenchmarkBadger_ShardedParallelSet(b *testing.B) {
+	for _, shards := range []int{1, 4} {
+		b.Run(fmt.Sprintf("shards-%d", shards), func(b *testing.B) {
+			client, err := badger.Factory(core.CacheProvider{Path: b.TempDir(), Shards: shards}, zap.NewNop().Sugar(), 0)
+			if err != nil {
+				b.Fatalf("Failed to create the badger instance: %v", err)
+			}
+
+			defer func() { _ = client.Close() }()
+
+			var counter atomic.Int64
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = client.Set(fmt.Sprintf("ParallelKey%d", counter.Add(1)), []byte(baseValue), time.Minute)
+				}
+			})
+		})
+	}
+}
+
+func TestBadger_GetInto(t *testing.T) {
+	client, _ := getBadgerInstance()
+	reader := client.(core.ValueReader)
+
+	_ = client.Set("GetIntoKey", []byte(baseValue), time.Minute)
+
+	var seen string
+
+	err := reader.GetInto("GetIntoKey", func(value []byte) error {
+		seen = string(value)
+
+		return nil
+	})
+	if err != nil || seen != baseValue {
+		t.Errorf("The callback should see the stored value, %s and %v provided", seen, err)
+	}
+
+	if err = reader.GetInto(nonExistentKey, func([]byte) error { return nil }); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A missing key should return ErrKeyNotFound, %v provided", err)
+	}
+
+	callbackErr := errors.New("callback error")
+	if err = reader.GetInto("GetIntoKey", func([]byte) error { return callbackErr }); !errors.Is(err, callbackErr) {
+		t.Errorf("The callback error should be returned, %v provided", err)
+	}
+}
+
+func BenchmarkBadger_GetIntoLargeValue(b *testing.B) {
+	client, _ := getBadgerInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	reader := client.(core.ValueReader)
+	size := 0
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = reader.GetInto("BenchmarkLargeKey", func(value []byte) error {
+			size = len(value)
+
+			return nil
+		})
+	}
+
+	_ = size
+}
@@ -0,0 +1,351 @@
+//go:build !wasm && !wasi
+
+package badger
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/dgraph-io/badger/v4"
+)
+
+const defaultEvictionInterval = time.Minute
+
+// evictor bounds the size of the live entries of a DB by deleting the least recently used ones until stopped,
+// and reports the expired entries of the listened namespaces. A zero limit only reports the expiries.
+// The eviction is approximate: the budget is checked periodically so it may be exceeded in between, it
+// applies to the estimated size of the keys and values rather than the files which only shrink after the
+// compaction and the value log GC, and the access times are only kept in memory so the entries not
+// accessed since the start are evicted first in their write order.
+type evictor struct {
+	db        *badger.DB
+	limit     int64
+	logger    core.Logger
+	mu        sync.Mutex
+	accessed  map[string]int64
+	listeners []evictionListener
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+}
+
+// evictionListener receives the evictions of the keys of its namespace, without the namespace prefix. The
+// expiries of these keys are told by its clock.
+type evictionListener struct {
+	namespace string
+	notifier  *core.EvictionNotifier
+	clock     core.Clock
+}
+
+type evictionCandidate struct {
+	key      []byte
+	size     int64
+	accessed int64
+	version  uint64
+}
+
+func startEvictor(db *badger.DB, limit int64, interval time.Duration, logger core.Logger) *evictor {
+	e := &evictor{
+		db:       db,
+		limit:    limit,
+		logger:   logger,
+		accessed: map[string]int64{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+			}
+
+			if err := e.evict(); err != nil {
+				logger.Errorf("Impossible to evict the least recently used entries from Badger, %v", err)
+			}
+		}
+	}()
+
+	return e
+}
+
+// access records the access time of the stored keys, when a size limit is set.
+func (e *evictor) access(keys ...[]byte) {
+	if e == nil || e.limit <= 0 {
+		return
+	}
+
+	now := time.Now().UnixNano()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, key := range keys {
+		e.accessed[string(key)] = now
+	}
+}
+
+// listen reports the evicted and expired keys of the namespace to the notifier until unlisten is called.
+func (e *evictor) listen(namespace string, notifier *core.EvictionNotifier, clock core.Clock) {
+	if e == nil || notifier == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.listeners = append(e.listeners, evictionListener{namespace: namespace, notifier: notifier, clock: clock})
+}
+
+func (e *evictor) unlisten(notifier *core.EvictionNotifier) {
+	if e == nil || notifier == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.listeners = slices.DeleteFunc(e.listeners, func(listener evictionListener) bool {
+		return listener.notifier == notifier
+	})
+}
+
+// listenersOf returns the listeners of the longest namespace prefixing the key.
+func listenersOf(listeners []evictionListener, key string) []evictionListener {
+	namespace, found := "", false
+
+	for _, listener := range listeners {
+		if strings.HasPrefix(key, listener.namespace) && (!found || len(listener.namespace) > len(namespace)) {
+			namespace, found = listener.namespace, true
+		}
+	}
+
+	return slices.DeleteFunc(slices.Clone(listeners), func(listener evictionListener) bool {
+		return !found || listener.namespace != namespace
+	})
+}
+
+// notify reports the removed key to its listeners, the caller holds the lock.
+func (e *evictor) notify(key string, reason core.EvictReason) {
+	for _, listener := range listenersOf(e.listeners, key) {
+		listener.notifier.Notify(strings.TrimPrefix(key, listener.namespace), reason)
+	}
+}
+
+// expiredAt reports whether the version of the key has a time to live elapsed at now, a deletion has none.
+func expiredAt(item *badger.Item, now time.Time) bool {
+	expiresAt := item.ExpiresAt()
+
+	//nolint:gosec
+	return expiresAt != 0 && expiresAt <= uint64(now.Unix())
+}
+
+// expire deletes the keys of the listened namespaces whose latest version has expired by the clock of their
+// listener and reports them as expired. The key is deleted so the expiry is reported once, a concurrent write
+// of the key aborts its transaction instead.
+func (e *evictor) expire() error {
+	e.mu.Lock()
+	listeners := slices.Clone(e.listeners)
+	e.mu.Unlock()
+
+	if len(listeners) == 0 {
+		return nil
+	}
+
+	expired := [][]byte{}
+
+	err := e.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.AllVersions = true
+		opts.PrefetchValues = false
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		var previous []byte
+
+		// The versions of a key are iterated from the latest one.
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+			if previous != nil && bytes.Equal(item.Key(), previous) {
+				continue
+			}
+
+			previous = item.KeyCopy(nil)
+
+			if keyListeners := listenersOf(listeners, string(previous)); len(keyListeners) > 0 && expiredAt(item, keyListeners[0].clock.Now()) {
+				expired = append(expired, previous)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range expired {
+		deleted := false
+
+		err = e.db.Update(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.AllVersions = true
+			opts.PrefetchValues = false
+			opts.Prefix = key
+			iterator := txn.NewIterator(opts)
+
+			defer iterator.Close()
+
+			iterator.Seek(key)
+
+			keyListeners := listenersOf(listeners, string(key))
+			if !iterator.Valid() || !bytes.Equal(iterator.Item().Key(), key) || !expiredAt(iterator.Item(), keyListeners[0].clock.Now()) {
+				return nil
+			}
+
+			deleted = true
+
+			return txn.Delete(key)
+		})
+		if errors.Is(err, badger.ErrConflict) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if deleted {
+			e.mu.Lock()
+			e.notify(string(key), core.EvictExpired)
+			e.mu.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// evict reports the expired entries then deletes the least recently used ones until the estimated size of the
+// live entries fits the limit. The access times of the keys that no longer exist are dropped.
+func (e *evictor) evict() error {
+	if err := e.expire(); err != nil || e.limit <= 0 {
+		return err
+	}
+
+	start := time.Now().UnixNano()
+	candidates := []evictionCandidate{}
+
+	var total int64
+
+	err := e.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		iterator := txn.NewIterator(opts)
+
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			item := iterator.Item()
+			total += item.EstimatedSize()
+			candidates = append(candidates, evictionCandidate{
+				key:     item.KeyCopy(nil),
+				size:    item.EstimatedSize(),
+				version: item.Version(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+
+	accessed := make(map[string]int64, len(candidates))
+	for i, candidate := range candidates {
+		if at, found := e.accessed[string(candidate.key)]; found {
+			candidates[i].accessed = at
+			accessed[string(candidate.key)] = at
+		}
+	}
+
+	for key, at := range e.accessed {
+		if at >= start {
+			accessed[key] = at
+		}
+	}
+
+	e.accessed = accessed
+	e.mu.Unlock()
+
+	if total <= e.limit {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].accessed != candidates[j].accessed {
+			return candidates[i].accessed < candidates[j].accessed
+		}
+
+		return candidates[i].version < candidates[j].version
+	})
+
+	batch := e.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	evicted := []string{}
+
+	for _, candidate := range candidates {
+		if total <= e.limit {
+			break
+		}
+
+		if err = batch.Delete(candidate.key); err != nil {
+			return err
+		}
+
+		total -= candidate.size
+		evicted = append(evicted, string(candidate.key))
+	}
+
+	if err = batch.Flush(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, key := range evicted {
+		if e.accessed[key] < start {
+			delete(e.accessed, key)
+		}
+
+		e.notify(key, core.EvictSize)
+	}
+
+	return nil
+}
+
+// Stop ends the eviction and waits for the running one to finish.
+func (e *evictor) Stop() {
+	if e == nil {
+		return
+	}
+
+	e.once.Do(func() { close(e.stop) })
+	<-e.done
+}
@@ -0,0 +1,499 @@
+package badger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// Sharded spreads the keys across independent Badger databases by their hash so the writes don't contend on a
+// single DB. A varied key and its mapping may land in different shards, the multi-level election reads the
+// varied keys through the sharded storer.
+type Sharded struct {
+	shards   []*Badger
+	watchers *core.WatchHub
+}
+
+// shardedFactory opens the configured number of shards, each one in the shard-N subdirectory of the path.
+func shardedFactory(badgerConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (*Sharded, error) {
+	shardConfiguration := badgerConfiguration
+	if badgerConfiguration.MaxCacheSizeBytes > 0 {
+		shardConfiguration.MaxCacheSizeBytes = max(badgerConfiguration.MaxCacheSizeBytes/int64(badgerConfiguration.Shards), 1)
+	}
+
+	provider := &Sharded{shards: make([]*Badger, 0, badgerConfiguration.Shards), watchers: core.NewWatchHub(logger)}
+
+	for i := range badgerConfiguration.Shards {
+		shard, err := factory(shardConfiguration, logger, stale, fmt.Sprintf("shard-%d", i))
+		if err != nil {
+			_ = provider.Close()
+
+			return nil, err
+		}
+
+		// The shards publish to a single hub so a watcher sees the keys of every shard.
+		shard.watchers.Close()
+		shard.watchers = provider.watchers
+		provider.shards = append(provider.shards, shard)
+	}
+
+	return provider, nil
+}
+
+// Shards returns the underlying Badger instances.
+func (provider *Sharded) Shards() []*Badger {
+	return provider.shards
+}
+
+// shard returns the Badger instance storing the key.
+func (provider *Sharded) shard(key string) *Badger {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return provider.shards[hash.Sum32()%uint32(len(provider.shards))] //nolint:gosec
+}
+
+// Name returns the storer name.
+func (provider *Sharded) Name() string {
+	return "BADGER"
+}
+
+// Uuid returns an unique identifier made of the shards ones.
+func (provider *Sharded) Uuid() string {
+	uuids := make([]string, 0, len(provider.shards))
+	for _, shard := range provider.shards {
+		uuids = append(uuids, shard.Uuid())
+	}
+
+	return strings.Join(uuids, ",")
+}
+
+// MapKeys method returns a map with the key and value of every shard.
+func (provider *Sharded) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for _, shard := range provider.shards {
+		for key, value := range shard.MapKeys(prefix) {
+			keys[key] = value
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys of every shard.
+func (provider *Sharded) ListKeys() []string {
+	keys := []string{}
+
+	for _, shard := range provider.shards {
+		keys = append(keys, shard.ListKeys()...)
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// Each shard returns its own page and its next key, the merged page holds the smallest ones.
+func (provider *Sharded) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	candidates := []string{}
+
+	for _, shard := range provider.shards {
+		page, shardNext := shard.ScanKeys(prefix, cursor, limit)
+		candidates = append(candidates, page...)
+
+		if shardNext != "" {
+			candidates = append(candidates, shardNext)
+		}
+	}
+
+	return core.PaginateKeys(candidates, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Sharded) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists in the key shard, empty response then.
+func (provider *Sharded) Get(key string) []byte {
+	return provider.shard(key).Get(key)
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Sharded) GetContext(ctx context.Context, key string) ([]byte, error) {
+	return provider.shard(key).GetContext(ctx, key)
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Sharded) GetWithError(key string) ([]byte, error) {
+	return provider.shard(key).GetWithError(key)
+}
+
+// GetInto method calls fn with the value of the key read in place from its shard, see core.ValueReader.
+func (provider *Sharded) GetInto(key string, fn func([]byte) error) error {
+	return provider.shard(key).GetInto(key, fn)
+}
+
+// GetMany method returns the values of the existing keys using a single read transaction per shard.
+func (provider *Sharded) GetMany(keys []string) map[string][]byte {
+	grouped := map[*Badger][]string{}
+	for _, key := range keys {
+		grouped[provider.shard(key)] = append(grouped[provider.shard(key)], key)
+	}
+
+	values := map[string][]byte{}
+
+	for shard, shardKeys := range grouped {
+		for key, value := range shard.GetMany(shardKeys) {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Sharded) GetTTL(key string) (time.Duration, bool) {
+	return provider.shard(key).GetTTL(key)
+}
+
+// Exists method will check the key in its shard without reading its value.
+func (provider *Sharded) Exists(key string) bool {
+	return provider.shard(key).Exists(key)
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Sharded) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Sharded) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	return provider.shard(core.MappingKeyPrefix+key).getMultiLevel(provider, key, req, validator)
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+// The varied key and the mapping are written in a single transaction when they share their shard, the varied
+// key is written first otherwise. A non-positive duration deletes the existing varied key and leaves the mapping untouched.
+func (provider *Sharded) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	varied, mapping := provider.shard(variedKey), provider.shard(core.MappingKeyPrefix+baseKey)
+	if varied == mapping {
+		return varied.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+	}
+
+	if varied.IsClosed() || mapping.IsClosed() {
+		return core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration + varied.stale)
+	if !store {
+		varied.Delete(variedKey)
+
+		return nil
+	}
+
+	now := mapping.clock.Now()
+
+	err := varied.update(func(btx *badger.Txn) error {
+		return varied.setVaried(btx, variedKey, value, variedHeaders, ttl)
+	})
+	if err == nil {
+		err = mapping.update(func(btx *badger.Txn) error {
+			return mapping.setMapping(btx, baseKey, variedKey, variedHeaders, etag, now, duration, realKey)
+		})
+	}
+
+	if err != nil {
+		varied.logger.Errorf("Impossible to set value into Badger, %v", err)
+
+		return err
+	}
+
+	varied.evictor.access(varied.key(variedKey))
+	mapping.evictor.access(mapping.key(core.MappingKeyPrefix + baseKey))
+
+	if err = core.IndexSurrogateKeys(varied, variedKey, value, duration+varied.stale); err != nil {
+		varied.logger.Errorf("Impossible to index the surrogate keys of the key %s in Badger, %v", variedKey, err)
+	}
+
+	return err
+}
+
+// Set method will store the response in the key shard.
+func (provider *Sharded) Set(key string, value []byte, duration time.Duration) error {
+	return provider.shard(key).Set(key, value, duration)
+}
+
+// SetContext method will store the response in the key shard unless the context is done before the commit.
+func (provider *Sharded) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return provider.shard(key).SetContext(ctx, key, value, duration)
+}
+
+// SetMany method will store the entries using a single write batch per shard and returns the first error.
+// The shards are written independently so a failure leaves the entries of the other shards stored.
+func (provider *Sharded) SetMany(items map[string]core.Entry) error {
+	grouped := map[*Badger]map[string]core.Entry{}
+
+	for key, item := range items {
+		shard := provider.shard(key)
+		if grouped[shard] == nil {
+			grouped[shard] = map[string]core.Entry{}
+		}
+
+		grouped[shard][key] = item
+	}
+
+	for shard, shardItems := range grouped {
+		if err := shard.SetMany(shardItems); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in the key shard only if the key doesn't exist yet.
+func (provider *Sharded) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	return provider.shard(key).SetNX(key, value, duration)
+}
+
+// CompareAndSwap method will store the response in the key shard only if the stored bytes equal old.
+func (provider *Sharded) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	return provider.shard(key).CompareAndSwap(key, old, value, duration)
+}
+
+// Increment method will add delta to the counter stored in the key shard.
+func (provider *Sharded) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.shard(key).Increment(key, delta, duration)
+}
+
+// Decrement method will subtract delta from the counter stored in the key shard.
+func (provider *Sharded) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.shard(key).Decrement(key, delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in the key shard.
+func (provider *Sharded) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	return provider.shard(key).SetStream(key, reader, duration)
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Sharded) GetStream(key string) (io.ReadCloser, error) {
+	return provider.shard(key).GetStream(key)
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Sharded) Touch(key string, duration time.Duration) error {
+	return provider.shard(key).Touch(key, duration)
+}
+
+// SetWithMeta method will store the response and its metadata side record in the key shard.
+func (provider *Sharded) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return provider.shard(key).SetWithMeta(key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta without reading the value, core.ErrKeyNotFound if none.
+func (provider *Sharded) GetMeta(key string) (map[string]string, error) {
+	return provider.shard(key).GetMeta(key)
+}
+
+// Delete method will delete the response in the key shard if exists.
+func (provider *Sharded) Delete(key string) {
+	provider.shard(key).Delete(key)
+}
+
+// DeleteMany method will delete the responses matching the prefix pattern param in every shard.
+func (provider *Sharded) DeleteMany(pattern string) {
+	for _, shard := range provider.shards {
+		shard.DeleteMany(pattern)
+	}
+}
+
+// DeleteKeys method will delete the keys using a single write batch per shard and returns the first error.
+// The shards are written independently so a failure leaves the keys of the other shards stored.
+func (provider *Sharded) DeleteKeys(keys []string) error {
+	grouped := map[*Badger][]string{}
+
+	for _, key := range keys {
+		shard := provider.shard(key)
+		grouped[shard] = append(grouped[shard], key)
+	}
+
+	for shard, shardKeys := range grouped {
+		if err := shard.DeleteKeys(shardKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in every shard.
+// The surrogate keys are indexed in the shard of the tagged response.
+func (provider *Sharded) InvalidateSurrogate(surrogateKey string) (int, error) {
+	deleted := 0
+
+	for _, shard := range provider.shards {
+		count, err := shard.InvalidateSurrogate(surrogateKey)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += count
+	}
+
+	return deleted, nil
+}
+
+// ListSurrogates method returns the surrogate keys indexed in every shard, once each.
+func (provider *Sharded) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in every shard.
+// The surrogate keys are indexed in the shard of the tagged response.
+func (provider *Sharded) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	entries := map[string][]byte{}
+
+	for _, shard := range provider.shards {
+		shardEntries, err := shard.GetBySurrogate(surrogateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(entries, shardEntries)
+	}
+
+	return entries, nil
+}
+
+// Inspect method returns the details of the key stored in its shard, its mapping may live in another one.
+func (provider *Sharded) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.shards[0].mapper, key)
+}
+
+// Stats method returns the sum of the shards statistics.
+func (provider *Sharded) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+
+	for _, shard := range provider.shards {
+		shardStats, err := shard.Stats()
+		if err != nil {
+			return stats, err
+		}
+
+		stats.KeyCount += shardStats.KeyCount
+		stats.ApproxSizeBytes += shardStats.ApproxSizeBytes
+	}
+
+	return stats, nil
+}
+
+// Ping method will check every shard is open.
+func (provider *Sharded) Ping(ctx context.Context) error {
+	errs := []error{}
+	for _, shard := range provider.shards {
+		errs = append(errs, shard.Ping(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Export method will stream every key of the shards one after the other.
+func (provider *Sharded) Export(w io.Writer) error {
+	for _, shard := range provider.shards {
+		if err := shard.Export(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DumpMeta method will write the key, value size and remaining time to live of every key of the shards as JSON lines.
+func (provider *Sharded) DumpMeta(w io.Writer) error {
+	for _, shard := range provider.shards {
+		if err := shard.DumpMeta(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Watch method will stream the changes made through this instance to the keys of every shard starting with
+// the prefix, see core.Watcher.
+func (provider *Sharded) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	return provider.watchers.Watch(ctx, prefix)
+}
+
+// Iterate method will stream every entry of the shards one after the other, see core.Iterator.
+func (provider *Sharded) Iterate(ctx context.Context) (<-chan core.KV, <-chan error) {
+	return core.IterateWith(ctx, func(yield func(core.KV) error) error {
+		for _, shard := range provider.shards {
+			if err := shard.iterate(yield); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Import method will store the exported records in their shard by write batches.
+func (provider *Sharded) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Sharded) Init() error {
+	return nil
+}
+
+// Reset method will drop every key of every shard.
+func (provider *Sharded) Reset() error {
+	errs := []error{}
+	for _, shard := range provider.shards {
+		errs = append(errs, shard.Reset())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Evict method will run the size eviction of every shard, see Badger.Evict.
+func (provider *Sharded) Evict() error {
+	errs := []error{}
+	for _, shard := range provider.shards {
+		errs = append(errs, shard.Evict())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close method will close every shard.
+func (provider *Sharded) Close() error {
+	errs := []error{}
+	for _, shard := range provider.shards {
+		errs = append(errs, shard.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/cassandra"
+	"github.com/darkweak/storages/core"
+)
+
+const moduleName = "cassandra"
+
+// Cassandra storage.
+type Cassandra struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(Cassandra{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (Cassandra) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.cassandra",
+		New: func() caddy.Module { return new(Cassandra) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *Cassandra) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := cassandra.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *Cassandra) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*Cassandra)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Cassandra)(nil)
+)
@@ -0,0 +1,733 @@
+// Package cassandra stores the cache entries as rows of a Cassandra or ScyllaDB table.
+//
+// Each row holds the key as its partition key and the value as a blob, written with the native
+// USING TTL clause so the cluster hides the expired rows and drops them on compaction. The time
+// to live has a one second resolution and is capped to 20 years. The partitions are spread by the
+// hash of their key, the prefix lookups scan every token range of the table.
+package cassandra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/gocql/gocql"
+)
+
+const (
+	defaultURL               = "127.0.0.1:9042"
+	defaultPort              = "9042"
+	defaultKeyspace          = "souin"
+	defaultTable             = "souin"
+	defaultReplicationFactor = 1
+
+	// maxTTL is the largest time to live accepted by Cassandra, 20 years in seconds.
+	maxTTL = 630720000
+)
+
+// identifier matches the keyspace and table names usable unquoted in the statements.
+var identifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,47}$`)
+
+// Cassandra provider type.
+type Cassandra struct {
+	*gocql.Session
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	hosts       []string
+	table       string
+	compression string
+	clock       core.Clock
+}
+
+// Factory function create new Cassandra instance.
+func Factory(cassandraConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if cassandraConfiguration.Validate {
+		if err := core.ValidateConfig(cassandraConfiguration, core.CheckURL, checkNames); err != nil {
+			logger.Errorf("Impossible to validate the Cassandra configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(cassandraConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, cassandraConfiguration, logger)
+}
+
+// names returns the keyspace, the table and the replication factor of the configuration map.
+func names(cassandraConfiguration core.CacheProvider) (keyspace, table string, replicationFactor int) {
+	keyspace, table, replicationFactor = defaultKeyspace, defaultTable, defaultReplicationFactor
+
+	if cc, ok := cassandraConfiguration.Configuration.(map[string]interface{}); ok && cc != nil {
+		if v, found := cc["keyspace"]; found && v != nil {
+			keyspace = fmt.Sprint(v)
+		}
+
+		if v, found := cc["table"]; found && v != nil {
+			table = fmt.Sprint(v)
+		}
+
+		if v, found := cc["replication_factor"]; found && v != nil {
+			if factor, err := strconv.Atoi(fmt.Sprint(v)); err == nil {
+				replicationFactor = factor
+			}
+		}
+	}
+
+	return keyspace, table, replicationFactor
+}
+
+// checkNames checks the keyspace and table names can be used in the statements, see core.ValidateConfig.
+func checkNames(cassandraConfiguration core.CacheProvider) error {
+	keyspace, table, replicationFactor := names(cassandraConfiguration)
+
+	var errs []error
+
+	for kind, name := range map[string]string{"keyspace": keyspace, "table": table} {
+		if !identifier.MatchString(name) {
+			errs = append(errs, fmt.Errorf("%w: the Cassandra %s %q must be alphanumeric, up to 48 characters", core.ErrInvalidConfig, kind, name))
+		}
+	}
+
+	if replicationFactor < 1 {
+		errs = append(errs, fmt.Errorf("%w: the Cassandra replication_factor %d must be positive", core.ErrInvalidConfig, replicationFactor))
+	}
+
+	return errors.Join(errs...)
+}
+
+func factory(cassandraConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	// The names are written into the statements, they are always checked.
+	if err := checkNames(cassandraConfiguration); err != nil {
+		logger.Errorf("Impossible to configure the Cassandra table, %v", err)
+
+		return nil, err
+	}
+
+	url := cassandraConfiguration.URL
+	if url == "" {
+		url = defaultURL
+	}
+
+	hosts, err := parseHosts(url)
+	if err != nil {
+		logger.Errorf("Impossible to parse the Cassandra hosts %s, %v", url, err)
+
+		return nil, err
+	}
+
+	keyspace, table, replicationFactor := names(cassandraConfiguration)
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Consistency = gocql.LocalQuorum
+	cluster.SerialConsistency = gocql.LocalSerial
+
+	// The single session holds the connection pool to every node, the statements name the keyspace.
+	session, err := cluster.CreateSession()
+	if err != nil {
+		logger.Error("Impossible to connect to Cassandra.", err)
+
+		return nil, err
+	}
+
+	provider := &Cassandra{
+		Session:     session,
+		stale:       stale,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(cassandraConfiguration),
+		hosts:       hosts,
+		table:       keyspace + "." + table,
+		compression: core.ConfiguredCompression(cassandraConfiguration, logger),
+		clock:       core.ClockOrDefault(cassandraConfiguration.Clock),
+	}
+
+	if err = provider.createTable(context.Background(), keyspace, replicationFactor); err != nil {
+		logger.Errorf("Impossible to create the Cassandra table %s, %v", provider.table, err)
+
+		session.Close()
+
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// parseHosts returns the comma separated hosts completed by the default Cassandra port, the driver only dials tcp.
+func parseHosts(url string) ([]string, error) {
+	hosts := []string{}
+
+	for _, value := range strings.Split(url, ",") {
+		network, address, err := core.ParseAddressWithDefaultPort(value, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+
+		if network != "tcp" {
+			return nil, fmt.Errorf("%w: the Cassandra host %q must be a tcp address", core.ErrInvalidConfig, value)
+		}
+
+		hosts = append(hosts, address)
+	}
+
+	return hosts, nil
+}
+
+// createTable creates the keyspace with a simple replication strategy and the table if they don't exist.
+func (provider *Cassandra) createTable(ctx context.Context, keyspace string, replicationFactor int) error {
+	err := provider.Query(fmt.Sprintf(
+		"CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': %d}",
+		keyspace, replicationFactor,
+	)).WithContext(ctx).Exec()
+	if err != nil {
+		return err
+	}
+
+	return provider.Query(provider.statement("CREATE TABLE IF NOT EXISTS %s (key text PRIMARY KEY, value blob)")).WithContext(ctx).Exec()
+}
+
+// statement returns the CQL statement on the table, the checked names can't inject anything.
+func (provider *Cassandra) statement(format string) string {
+	return fmt.Sprintf(format, provider.table)
+}
+
+// ttl returns the time to live of the duration in seconds rounded up, 0 means no expiration.
+func ttl(duration time.Duration) int {
+	if duration <= 0 {
+		return 0
+	}
+
+	return int(min((duration+time.Second-1)/time.Second, maxTTL))
+}
+
+// Name returns the storer name.
+func (provider *Cassandra) Name() string {
+	return "CASSANDRA"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Cassandra) Uuid() string {
+	return fmt.Sprintf("%s-%s-%s", strings.Join(provider.hosts, ","), provider.table, provider.stale)
+}
+
+// scan calls fn with the live rows whose key begins with the prefix, reading every token range page by page.
+func (provider *Cassandra) scan(ctx context.Context, prefix string, withValue bool, fn func(key string, value []byte)) error {
+	columns := "key"
+	if withValue {
+		columns = "key, value"
+	}
+
+	iter := provider.Query(provider.statement("SELECT " + columns + " FROM %s")).WithContext(ctx).Iter()
+	scanner := iter.Scanner()
+
+	for scanner.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		dest := []interface{}{&key}
+		if withValue {
+			dest = append(dest, &value)
+		}
+
+		if err := scanner.Scan(dest...); err != nil {
+			_ = iter.Close()
+
+			return err
+		}
+
+		if strings.HasPrefix(key, prefix) {
+			fn(key, value)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// MapKeys method returns the map of existing keys scanning the table with a prefix filter.
+func (provider *Cassandra) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	err := provider.scan(context.Background(), prefix, true, func(key string, value []byte) {
+		if core.ListedKey(prefix, key) {
+			k, _ := strings.CutPrefix(key, prefix)
+			keys[k] = string(value)
+		}
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in Cassandra, %v", err)
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Cassandra) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// Cassandra returns the rows in token order so the matching keys are all read then sorted.
+func (provider *Cassandra) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	err := provider.scan(context.Background(), prefix, false, func(key string, _ []byte) {
+		keys = append(keys, key)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to scan the keys in Cassandra, %v", err)
+	}
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Cassandra) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Cassandra) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in Cassandra: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Cassandra) GetContext(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+
+	err := provider.Query(provider.statement("SELECT value FROM %s WHERE key = ?"), key).WithContext(ctx).Scan(&value)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Cassandra) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys using a single IN query.
+func (provider *Cassandra) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return result
+	}
+
+	scanner := provider.Query(provider.statement("SELECT key, value FROM %s WHERE key IN ?"), keys).Iter().Scanner()
+
+	for scanner.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err := scanner.Scan(&key, &value); err != nil {
+			provider.logger.Errorf("Impossible to get the keys in Cassandra, %v", err)
+
+			return result
+		}
+
+		result[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		provider.logger.Errorf("Impossible to get the keys in Cassandra, %v", err)
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Cassandra) GetTTL(key string) (time.Duration, bool) {
+	var remaining *int
+
+	err := provider.Query(provider.statement("SELECT TTL(value) FROM %s WHERE key = ?"), key).Scan(&remaining)
+	if err != nil {
+		return 0, false
+	}
+
+	if remaining == nil {
+		return core.NoExpiration, true
+	}
+
+	return time.Duration(*remaining) * time.Second, true
+}
+
+// Exists method will check the key in Cassandra provider without reading its value.
+func (provider *Cassandra) Exists(key string) bool {
+	var found string
+
+	return provider.Query(provider.statement("SELECT key FROM %s WHERE key = ?"), key).Scan(&found) == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Cassandra) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Cassandra) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in Cassandra", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *Cassandra) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Cassandra, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Cassandra, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in Cassandra: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in Cassandra", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in Cassandra provider.
+func (provider *Cassandra) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will upsert the response unless the context is done before the row is written.
+// A non-positive duration stores the row without expiry.
+func (provider *Cassandra) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	err := provider.Query(provider.statement("INSERT INTO %s (key, value) VALUES (?, ?) USING TTL ?"), key, value, ttl(duration)).WithContext(ctx).Exec()
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Cassandra, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will upsert the entries in Cassandra provider one by one.
+// A batch spanning several partitions would load its coordinator and the large ones are rejected,
+// the rows written before a failure are kept and the error is returned.
+func (provider *Cassandra) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			provider.logger.Errorf("Impossible to set the entries into Cassandra, %v", err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Cassandra provider only if the key doesn't exist yet or is expired.
+// The insertion is a lightweight transaction, the expired rows aren't seen by its condition.
+func (provider *Cassandra) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	applied, err := provider.Query(
+		provider.statement("INSERT INTO %s (key, value) VALUES (?, ?) IF NOT EXISTS USING TTL ?"), key, value, ttl(duration),
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Cassandra, %v", key, err)
+
+		return false, err
+	}
+
+	return applied, nil
+}
+
+// CompareAndSwap method will store the response in Cassandra provider only if the stored bytes equal old.
+// The comparison is the condition of a lightweight transaction, a nil old value creates the key like SetNX.
+func (provider *Cassandra) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	applied, err := provider.Query(
+		provider.statement("UPDATE %s USING TTL ? SET value = ? WHERE key = ? IF value = ?"), ttl(duration), value, key, old,
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Cassandra, %v", key, err)
+
+		return false, err
+	}
+
+	return applied, nil
+}
+
+// Increment method will add delta to the counter stored in Cassandra provider.
+// The counter is swapped with the read value and retried when a concurrent writer updated it in between.
+func (provider *Cassandra) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		current, err := provider.GetWithError(key)
+		if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+			provider.logger.Errorf("Impossible to increment the key %s into Cassandra, %v", key, err)
+
+			return 0, err
+		}
+
+		value, encoded, err := core.AddCounter(current, delta)
+		if err != nil {
+			return 0, err
+		}
+
+		swapped, err := provider.CompareAndSwap(key, current, encoded, duration)
+		if err != nil {
+			return 0, err
+		}
+
+		if swapped {
+			return value, nil
+		}
+	}
+}
+
+// Decrement method will subtract delta from the counter stored in Cassandra provider.
+func (provider *Cassandra) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Cassandra provider.
+func (provider *Cassandra) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Cassandra, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Cassandra) GetStream(key string) (io.ReadCloser, error) {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+// Cassandra sets the time to live by cell so the read value is written back, swapped like CompareAndSwap
+// to keep a concurrent write.
+func (provider *Cassandra) Touch(key string, duration time.Duration) error {
+	for {
+		current, err := provider.GetWithError(key)
+		if err != nil {
+			if !errors.Is(err, core.ErrKeyNotFound) {
+				provider.logger.Errorf("Impossible to touch the key %s into Cassandra, %v", key, err)
+			}
+
+			return err
+		}
+
+		swapped, err := provider.CompareAndSwap(key, current, current, duration)
+		if err != nil {
+			return err
+		}
+
+		if swapped {
+			return nil
+		}
+	}
+}
+
+// Delete method will delete the response in Cassandra provider if exists corresponding to key param.
+func (provider *Cassandra) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if err := provider.Query(provider.statement("DELETE FROM %s WHERE key = ?"), key).Exec(); err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in Cassandra, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in Cassandra provider if exists corresponding to the prefix pattern param.
+// The keys are scanned first then deleted one by one.
+func (provider *Cassandra) DeleteMany(pattern string) {
+	keys := []string{}
+
+	err := provider.scan(context.Background(), core.KeyPrefix(pattern), false, func(key string, _ []byte) {
+		keys = append(keys, key)
+	})
+
+	for _, key := range keys {
+		if err != nil {
+			break
+		}
+
+		err = provider.Query(provider.statement("DELETE FROM %s WHERE key = ?"), key).Exec()
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in Cassandra, %v", pattern, err)
+	}
+}
+
+// DeleteKeys method will delete the keys in Cassandra provider one by one, the missing keys are ignored.
+func (provider *Cassandra) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Cassandra provider.
+func (provider *Cassandra) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Cassandra provider.
+func (provider *Cassandra) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Cassandra provider.
+func (provider *Cassandra) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Cassandra provider without altering it.
+func (provider *Cassandra) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Cassandra provider and its metadata under a parallel key.
+func (provider *Cassandra) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Cassandra provider, core.ErrKeyNotFound if none.
+func (provider *Cassandra) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the partition count and the size of the table estimated by the node.
+// Cassandra refreshes the estimates every five minutes and still counts the expired rows not compacted yet.
+func (provider *Cassandra) Stats() (core.StorageStats, error) {
+	keyspace, table, _ := strings.Cut(provider.table, ".")
+	iter := provider.Query(
+		"SELECT partitions_count, mean_partition_size FROM system.size_estimates WHERE keyspace_name = ? AND table_name = ?", keyspace, table,
+	).Iter()
+
+	var (
+		stats      core.StorageStats
+		partitions int64
+		size       int64
+	)
+
+	for iter.Scan(&partitions, &size) {
+		stats.KeyCount += partitions
+		stats.ApproxSizeBytes += partitions * size
+	}
+
+	if err := iter.Close(); err != nil {
+		provider.logger.Errorf("Impossible to get the Cassandra table %s estimates, %v", provider.table, err)
+
+		return core.StorageStats{}, err
+	}
+
+	return stats, nil
+}
+
+// Ping method will check the Cassandra node answers a query.
+func (provider *Cassandra) Ping(ctx context.Context) error {
+	return provider.Query("SELECT release_version FROM system.local").WithContext(ctx).Exec()
+}
+
+// Export method will stream every key of the Cassandra provider paginating the scanned keys.
+func (provider *Cassandra) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Cassandra provider.
+func (provider *Cassandra) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Cassandra) Init() error {
+	return nil
+}
+
+// Reset method will truncate the table.
+func (provider *Cassandra) Reset() error {
+	return provider.Query(provider.statement("TRUNCATE %s")).Exec()
+}
+
+// Close method will close the Cassandra session.
+func (provider *Cassandra) Close() error {
+	provider.Session.Close()
+
+	return nil
+}
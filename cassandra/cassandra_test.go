@@ -0,0 +1,364 @@
+//go:build cassandra
+
+package cassandra_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/cassandra"
+	"github.com/darkweak/storages/core"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getCassandraInstance() (core.Storer, error) {
+	url := os.Getenv("CASSANDRA_URL")
+	if url == "" {
+		url = "127.0.0.1:9042"
+	}
+
+	return cassandra.Factory(core.CacheProvider{
+		URL: url,
+		Configuration: map[string]interface{}{
+			"keyspace": "souin_test",
+			"table":    "souin_test",
+		},
+	}, zap.NewNop().Sugar(), 0)
+}
+
+func TestCassandraConnectionFactory(t *testing.T) {
+	instance, err := getCassandraInstance()
+
+	if nil != err {
+		t.Errorf("Shouldn't have panic: %v", err)
+	}
+
+	if nil == instance {
+		t.Error("Cassandra should be instanciated")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInCassandra(t *testing.T) {
+	client, _ := getCassandraInstance()
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestCassandra_GetRequestInCache(t *testing.T) {
+	client, _ := getCassandraInstance()
+
+	if _, err := client.GetWithError(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Key %s should not exist, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestCassandra_SetRequestInCache_TTL(t *testing.T) {
+	client, _ := getCassandraInstance()
+	_ = client.Set(byteKey, []byte(baseValue), time.Second)
+
+	if ttl, found := client.GetTTL(byteKey); !found || ttl <= 0 || ttl > 2*time.Second {
+		t.Errorf("The TTL should be between 0 and 2s, %v provided", ttl)
+	}
+
+	// Cassandra hides the rows past their native TTL.
+	time.Sleep(2500 * time.Millisecond)
+
+	if res := client.Get(byteKey); res != nil {
+		t.Errorf("Key %s should be expired, %s provided", byteKey, res)
+	}
+
+	if client.Exists(byteKey) {
+		t.Errorf("The expired key %s should not exist", byteKey)
+	}
+
+	if keys, _ := client.ScanKeys(byteKey, "", 0); len(keys) != 0 {
+		t.Errorf("The expired key %s shouldn't be scanned, %v provided", byteKey, keys)
+	}
+
+	if err := client.Touch(byteKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the expired key %s should return ErrKeyNotFound, %v provided", byteKey, err)
+	}
+}
+
+func TestCassandra_Touch(t *testing.T) {
+	client, _ := getCassandraInstance()
+	_ = client.Set("TouchKey", []byte(baseValue), 20*time.Second)
+
+	if err := client.Touch("TouchKey", time.Minute); err != nil {
+		t.Errorf("Impossible to touch the key TouchKey: %v", err)
+	}
+
+	if ttl, _ := client.GetTTL("TouchKey"); ttl <= 20*time.Second {
+		t.Errorf("The TTL should have been extended, %v provided", ttl)
+	}
+
+	if res := client.Get("TouchKey"); string(res) != baseValue {
+		t.Errorf("The touched value should be kept, %s provided", res)
+	}
+}
+
+func TestCassandra_SetMultiLevel(t *testing.T) {
+	client, _ := getCassandraInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("MultiLevelKey", "MultiLevelKey", []byte(response), http.Header{}, "", time.Minute, "MultiLevelKey"); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/multi-level", nil)
+
+	fresh, _ := client.GetMultiLevel("MultiLevelKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The body should be %s, %s provided", baseValue, body)
+	}
+}
+
+func TestCassandra_MapKeys(t *testing.T) {
+	client, _ := getCassandraInstance()
+	client.DeleteMany("MAP_")
+
+	_ = client.Set("MAP_first", []byte("1"), time.Minute)
+	_ = client.Set("MAP_second", []byte("2"), time.Minute)
+	_ = client.Set("OTHER_third", []byte("3"), time.Minute)
+
+	keys := client.MapKeys("MAP_")
+	if len(keys) != 2 || keys["first"] != "1" || keys["second"] != "2" {
+		t.Errorf("Only the keys with the MAP_ prefix should be mapped, %v provided", keys)
+	}
+}
+
+func TestCassandra_SetManyGetMany(t *testing.T) {
+	client, _ := getCassandraInstance()
+	client.DeleteMany("BatchKey")
+
+	items := make(map[string]core.Entry, 130)
+	keys := make([]string, 0, 131)
+
+	for i := range 130 {
+		key := fmt.Sprintf("BatchKey%03d", i)
+		items[key] = core.Entry{Value: []byte(key), Duration: time.Minute}
+		keys = append(keys, key)
+	}
+
+	if err := client.SetMany(items); err != nil {
+		t.Fatalf("Impossible to set the 130 entries: %v", err)
+	}
+
+	values := client.GetMany(append(keys, nonExistentKey))
+	if len(values) != len(items) {
+		t.Errorf("The 130 entries should be read back, %d provided", len(values))
+	}
+
+	for key, value := range values {
+		if string(value) != key {
+			t.Errorf("The key %s should store its own name, %s provided", key, value)
+		}
+	}
+}
+
+func TestCassandra_ScanKeys(t *testing.T) {
+	client, _ := getCassandraInstance()
+	client.DeleteMany("ScanKey")
+
+	items := make(map[string]core.Entry, 25)
+	for i := range 25 {
+		items[fmt.Sprintf("ScanKey%02d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	_ = client.SetMany(items)
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("The pagination should have ended after 3 pages")
+		}
+
+		keys, next := client.ScanKeys("ScanKey", cursor, 10)
+		for _, key := range keys {
+			if seen[key] {
+				t.Errorf("The key %s has already been returned", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != len(items) {
+		t.Errorf("The scan should return %d keys, %d provided", len(items), len(seen))
+	}
+}
+
+func TestCassandra_GetSetStream(t *testing.T) {
+	client, _ := getCassandraInstance()
+	value := strings.Repeat(baseValue, 1000)
+
+	if err := client.SetStream("StreamKey", strings.NewReader(value), time.Minute); err != nil {
+		t.Fatalf("Impossible to stream the key StreamKey: %v", err)
+	}
+
+	reader, err := client.GetStream("StreamKey")
+	if err != nil {
+		t.Fatalf("Impossible to get the stream of the key StreamKey: %v", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	if res, _ := io.ReadAll(reader); string(res) != value {
+		t.Errorf("The streamed value should be read back, %d bytes provided", len(res))
+	}
+}
+
+func TestCassandra_SetNX(t *testing.T) {
+	client, _ := getCassandraInstance()
+	client.Delete("SetNXKey")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 10 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := client.SetNX("SetNXKey", []byte(fmt.Sprintf("worker %d", i)), time.Minute)
+			if err != nil {
+				t.Errorf("Impossible to set the key SetNXKey if not exists: %v", err)
+			}
+
+			if ok {
+				created.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", created.Load())
+	}
+
+	_ = client.Set("SetNXExpiredKey", []byte(baseValue), time.Second)
+	time.Sleep(2500 * time.Millisecond)
+
+	if ok, _ := client.SetNX("SetNXExpiredKey", []byte(baseValue), time.Minute); !ok {
+		t.Error("The expired key SetNXExpiredKey should be replaced")
+	}
+}
+
+func TestCassandra_Increment(t *testing.T) {
+	client, _ := getCassandraInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 10 {
+		t.Errorf("The 10 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 5, time.Minute); value != 5 {
+		t.Errorf("The counter should be decremented to 5, %d provided", value)
+	}
+}
+
+func TestCassandra_Ping(t *testing.T) {
+	client, _ := getCassandraInstance()
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("The server should be reachable: %v", err)
+	}
+}
+
+func TestCassandra_RowShape(t *testing.T) {
+	client, _ := getCassandraInstance()
+	session := client.(*cassandra.Cassandra).Session
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("ShapeKey", "ShapeKey-varied", []byte(response), http.Header{}, "", time.Minute, "ShapeKey"); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	var value []byte
+	if err := session.Query("SELECT value FROM souin_test.souin_test WHERE key = ?", "ShapeKey-varied").Scan(&value); err != nil {
+		t.Fatalf("Impossible to read the stored row: %v", err)
+	}
+
+	// The lz4 frames begin with the 0x184D2204 magic number.
+	if !bytes.HasPrefix(value, []byte{0x04, 0x22, 0x4d, 0x18}) {
+		t.Errorf("The varied response should be stored in the lz4 format, %x provided", value[:min(len(value), 4)])
+	}
+
+	var ttl *int
+	if err := session.Query("SELECT TTL(value) FROM souin_test.souin_test WHERE key = ?", "ShapeKey-varied").Scan(&ttl); err != nil || ttl == nil || *ttl <= 0 || *ttl > 60 {
+		t.Errorf("The varied response should be written with a native TTL up to 60s, %v provided: %v", ttl, err)
+	}
+
+	ttl = nil
+	if err := session.Query("SELECT TTL(value) FROM souin_test.souin_test WHERE key = ?", core.MappingKeyPrefix+"ShapeKey").Scan(&ttl); err != nil || ttl != nil {
+		t.Errorf("The mapping should be stored without TTL, %v provided: %v", ttl, err)
+	}
+}
+
+func TestCassandra_InvalidNames(t *testing.T) {
+	_, err := cassandra.Factory(core.CacheProvider{
+		URL:           "127.0.0.1:9042",
+		Configuration: map[string]interface{}{"keyspace": "souin; DROP KEYSPACE system", "table": "souin-test"},
+	}, zap.NewNop().Sugar(), 0)
+
+	if !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("The keyspace and table names should be rejected, %v provided", err)
+	}
+}
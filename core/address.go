@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	unixScheme = "unix://"
+	tcpScheme  = "tcp://"
+)
+
+// ParseAddress returns the network and the dialable address of a configured address, a unix socket given as
+// unix:///path/to.sock or as an absolute path, or a host:port pair optionally prefixed by tcp:// where the
+// IPv6 literals are bracketed like [::1]:6379. The port is required, see ParseAddressWithDefaultPort.
+func ParseAddress(s string) (network, address string, err error) {
+	return ParseAddressWithDefaultPort(s, "")
+}
+
+// ParseAddressWithDefaultPort parses the address like ParseAddress, the host without port is completed by the
+// default port when it's not empty. A bare IPv6 literal like ::1 is then accepted as a host without port.
+func ParseAddressWithDefaultPort(s, defaultPort string) (network, address string, err error) {
+	s = strings.TrimSpace(s)
+
+	if path, found := strings.CutPrefix(s, unixScheme); found || strings.HasPrefix(s, "/") {
+		if !found {
+			path = s
+		}
+
+		if path == "" {
+			return "", "", fmt.Errorf("%w: the unix socket address %q has no path", ErrInvalidConfig, s)
+		}
+
+		return "unix", path, nil
+	}
+
+	hostport := strings.TrimPrefix(s, tcpScheme)
+	if strings.Contains(hostport, "://") {
+		return "", "", fmt.Errorf("%w: the address %q has an unsupported scheme", ErrInvalidConfig, s)
+	}
+
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		if defaultPort == "" {
+			return "", "", fmt.Errorf("%w: the address %q must be a host:port pair, %w", ErrInvalidConfig, s, err)
+		}
+
+		host, port = hostport, defaultPort
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = host[1 : len(host)-1]
+		}
+	}
+
+	if port == "" {
+		port = defaultPort
+	}
+
+	if host == "" {
+		return "", "", fmt.Errorf("%w: the address %q has no host", ErrInvalidConfig, s)
+	}
+
+	if strings.Contains(host, ":") || strings.HasPrefix(hostport, "[") {
+		if addr, err := netip.ParseAddr(host); err != nil || !addr.Is6() {
+			return "", "", fmt.Errorf("%w: the address %q has an invalid IPv6 host", ErrInvalidConfig, s)
+		}
+	} else if strings.ContainsAny(host, "[]/ ") {
+		return "", "", fmt.Errorf("%w: the address %q has an invalid host", ErrInvalidConfig, s)
+	}
+
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return "", "", fmt.Errorf("%w: the address %q has an invalid port %q", ErrInvalidConfig, s, port)
+	}
+
+	return "tcp", net.JoinHostPort(host, port), nil
+}
+
+// ParseAddresses parses the comma separated addresses like ParseAddressWithDefaultPort and returns them with
+// their network. A unix socket must be the only address as the clients dial them all on the same network.
+func ParseAddresses(list, defaultPort string) (addresses []string, network string, err error) {
+	network = "tcp"
+
+	for _, value := range strings.Split(list, ",") {
+		valueNetwork, address, err := ParseAddressWithDefaultPort(value, defaultPort)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if valueNetwork == "unix" {
+			network = valueNetwork
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	if network == "unix" && len(addresses) > 1 {
+		return nil, "", fmt.Errorf("%w: the unix socket must be the only address of %q", ErrInvalidConfig, list)
+	}
+
+	return addresses, network, nil
+}
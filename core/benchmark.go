@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const benchmarkKeyPrefix = "BENCH_"
+
+// BenchmarkOptions describes the value sizes and key cardinalities the storer benchmarks
+// are run with, each pair of them is run as a dedicated sub-benchmark.
+type BenchmarkOptions struct {
+	ValueSizes       []int
+	KeyCardinalities []int
+}
+
+// DefaultBenchmarkOptions are the options used by BenchmarkStorer.
+var DefaultBenchmarkOptions = BenchmarkOptions{
+	ValueSizes:       []int{128, 4 << 10, 64 << 10},
+	KeyCardinalities: []int{100, 1000},
+}
+
+// BenchmarkStorer runs the standardized Set, Get, Mixed and SetMultiLevel benchmarks
+// against the storer with the default options, a backend only has to call it from
+// its own benchmark function.
+func BenchmarkStorer(b *testing.B, s Storer) {
+	BenchmarkStorerWith(b, s, DefaultBenchmarkOptions)
+}
+
+// BenchmarkStorerWith runs the standardized benchmarks against the storer for every
+// value size and key cardinality of the options. The sub-benchmarks are named like
+// Set/size=4096/keys=100 and the benchmark keys are deleted once each of them is done.
+func BenchmarkStorerWith(b *testing.B, s Storer, options BenchmarkOptions) {
+	b.Helper()
+
+	benchmarks := []struct {
+		name string
+		run  func(*testing.B, Storer, []string, []byte)
+	}{
+		{name: "Set", run: benchmarkSet},
+		{name: "Get", run: benchmarkGet},
+		{name: "Mixed", run: benchmarkMixed},
+		{name: "SetMultiLevel", run: benchmarkSetMultiLevel},
+	}
+
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			for _, size := range options.ValueSizes {
+				for _, cardinality := range options.KeyCardinalities {
+					b.Run(fmt.Sprintf("size=%d/keys=%d", size, cardinality), func(b *testing.B) {
+						keys, value := benchmarkData(size, cardinality)
+
+						defer func() {
+							s.DeleteMany(benchmarkKeyPrefix)
+							s.DeleteMany(MappingKeyPrefix + benchmarkKeyPrefix)
+						}()
+
+						b.SetBytes(int64(size))
+						b.ReportAllocs()
+						benchmark.run(b, s, keys, value)
+					})
+				}
+			}
+		})
+	}
+}
+
+func benchmarkData(size, cardinality int) ([]string, []byte) {
+	keys := make([]string, cardinality)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%d", benchmarkKeyPrefix, i)
+	}
+
+	value := make([]byte, size)
+	for i := range value {
+		value[i] = byte('a' + i%26)
+	}
+
+	return keys, value
+}
+
+func benchmarkPopulate(b *testing.B, s Storer, keys []string, value []byte) {
+	b.Helper()
+
+	for _, key := range keys {
+		if err := s.Set(key, value, time.Hour); err != nil {
+			b.Fatalf("impossible to populate the key %s: %v", key, err)
+		}
+	}
+}
+
+func benchmarkSet(b *testing.B, s Storer, keys []string, value []byte) {
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := s.Set(keys[i%len(keys)], value, time.Hour); err != nil {
+			b.Fatalf("impossible to set the key %s: %v", keys[i%len(keys)], err)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, s Storer, keys []string, value []byte) {
+	benchmarkPopulate(b, s, keys, value)
+
+	misses := 0
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if s.Get(keys[i%len(keys)]) == nil {
+			misses++
+		}
+	}
+
+	b.ReportMetric(float64(misses)/float64(b.N), "misses/op")
+}
+
+// benchmarkMixed runs one write for four reads.
+func benchmarkMixed(b *testing.B, s Storer, keys []string, value []byte) {
+	benchmarkPopulate(b, s, keys, value)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+
+		if i%5 != 0 {
+			_ = s.Get(key)
+
+			continue
+		}
+
+		if err := s.Set(key, value, time.Hour); err != nil {
+			b.Fatalf("impossible to set the key %s: %v", key, err)
+		}
+	}
+}
+
+func benchmarkSetMultiLevel(b *testing.B, s Storer, keys []string, value []byte) {
+	headers := http.Header{"Content-Type": []string{"text/plain"}}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+
+		if err := s.SetMultiLevel(key, key+"-varied", value, headers, "", time.Hour, key); err != nil {
+			b.Fatalf("impossible to set the multi level key %s: %v", key, err)
+		}
+	}
+}
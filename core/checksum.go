@@ -0,0 +1,322 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	// ChecksumNone stores the values as is.
+	ChecksumNone = "none"
+	// ChecksumCRC32C appends the 4 bytes CRC-32 Castagnoli of the value.
+	ChecksumCRC32C = "crc32c"
+	// ChecksumXXHash appends the 8 bytes 64-bit xxHash digest of the value.
+	ChecksumXXHash = "xxhash"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksummed wraps the storer to append a checksum trailer to each stored value and verify it on read,
+// the corrupted or truncated values return ErrChecksumMismatch instead of the altered bytes. The trailer
+// is stripped before the value is returned or parsed by the multi-level election.
+// The mapping metadata, the counters and the keys are stored without trailer, the mapping is read with the
+// mapper of the storage, see ConfiguredMapper, the ProtobufMapper when nil. The export keeps the verified
+// trailers, it must be imported into a checksummed storer.
+// An unknown algorithm returns an error wrapping ErrInvalidConfig, ChecksumNone returns the storer as is.
+func Checksummed(storer Storer, algorithm string, mapper Mapper) (Storer, error) {
+	switch algorithm {
+	case "", ChecksumNone:
+		return storer, nil
+	case ChecksumCRC32C:
+		return &checksummedStorer{Storer: storer, mapper: MapperOrDefault(mapper), hash: func() hash.Hash { return crc32.New(crc32cTable) }}, nil
+	case ChecksumXXHash:
+		return &checksummedStorer{Storer: storer, mapper: MapperOrDefault(mapper), hash: func() hash.Hash { return xxhash.New() }}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown checksum %q", ErrInvalidConfig, algorithm)
+}
+
+type checksummedStorer struct {
+	Storer
+	mapper Mapper
+	hash   func() hash.Hash
+}
+
+func (s *checksummedStorer) seal(value []byte) []byte {
+	digest := s.hash()
+	_, _ = digest.Write(value)
+
+	sealed := make([]byte, len(value), len(value)+digest.Size())
+	copy(sealed, value)
+
+	return digest.Sum(sealed)
+}
+
+func (s *checksummedStorer) verify(value []byte) ([]byte, error) {
+	digest := s.hash()
+	if len(value) < digest.Size() {
+		return nil, ErrChecksumMismatch
+	}
+
+	plain, trailer := value[:len(value)-digest.Size()], value[len(value)-digest.Size():]
+	_, _ = digest.Write(plain)
+
+	if !bytes.Equal(digest.Sum(nil), trailer) {
+		return nil, ErrChecksumMismatch
+	}
+
+	return plain, nil
+}
+
+// verifyResponse returns the response stored compressed by SetMultiLevel verified and uncompressed.
+func (s *checksummedStorer) verifyResponse(compressed []byte) ([]byte, error) {
+	sealed, err := Decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := s.verify(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{noneHeader}, plain...), nil
+}
+
+// verifyStored verifies a value stored by Set or a response stored by SetMultiLevel.
+func (s *checksummedStorer) verifyStored(value []byte) error {
+	if _, err := s.verify(value); err == nil {
+		return nil
+	}
+
+	_, err := s.verifyResponse(value)
+	if err != nil {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+func (s *checksummedStorer) MapKeys(prefix string) map[string]string {
+	keys := s.Storer.MapKeys(prefix)
+	for key, value := range keys {
+		plain, err := s.verify([]byte(value))
+		if err != nil {
+			delete(keys, key)
+
+			continue
+		}
+
+		keys[key] = string(plain)
+	}
+
+	return keys
+}
+
+func (s *checksummedStorer) Get(key string) []byte {
+	value, _ := s.GetContext(context.Background(), key)
+
+	return value
+}
+
+func (s *checksummedStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.Storer.GetContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verify(value)
+}
+
+func (s *checksummedStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *checksummedStorer) GetMany(keys []string) map[string][]byte {
+	values := s.Storer.GetMany(keys)
+	for key, value := range values {
+		plain, err := s.verify(value)
+		if err != nil {
+			delete(values, key)
+
+			continue
+		}
+
+		values[key] = plain
+	}
+
+	return values
+}
+
+// GetMultiLevel runs the election on the verified values, the wrapped storer would hand
+// the trailer to the response parser otherwise.
+func (s *checksummedStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = s.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+func (s *checksummedStorer) GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch) {
+	mapping := s.Storer.Get(MappingKeyPrefix + key)
+	if mapping == nil {
+		return nil, nil, match
+	}
+
+	fresh, stale, match, _ = MappingElectionWith(s.mapper, &verifiedResponses{s}, mapping, req, validator, nopLogger{})
+
+	return fresh, stale, match
+}
+
+func (s *checksummedStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *checksummedStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.Storer.SetContext(ctx, key, s.seal(value), duration)
+}
+
+func (s *checksummedStorer) SetMany(items map[string]Entry) error {
+	sealed := make(map[string]Entry, len(items))
+
+	for key, item := range items {
+		sealed[key] = Entry{Value: s.seal(item.Value), Duration: item.Duration}
+	}
+
+	return s.Storer.SetMany(sealed)
+}
+
+func (s *checksummedStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	return s.Storer.SetNX(key, s.seal(value), duration)
+}
+
+// CompareAndSwap compares the sealed values, the checksum trailer being deterministic.
+func (s *checksummedStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old != nil {
+		old = s.seal(old)
+	}
+
+	return s.Storer.CompareAndSwap(key, old, s.seal(value), duration)
+}
+
+func (s *checksummedStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return s.Storer.SetWithMeta(key, s.seal(value), meta, duration)
+}
+
+// SetStream computes the checksum while the content is streamed and appends it once the reader is drained.
+func (s *checksummedStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	digest := s.hash()
+
+	return s.Storer.SetStream(key, io.MultiReader(io.TeeReader(reader, digest), &trailerReader{hash: digest}), duration)
+}
+
+// GetStream reads the whole stored content to verify it before returning the reader.
+func (s *checksummedStorer) GetStream(key string) (io.ReadCloser, error) {
+	stream, err := s.Storer.GetStream(key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	sealed, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := s.verify(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(plain)), nil
+}
+
+// GetBySurrogate returns the verified responses uncompressed, the corrupted ones are omitted.
+func (s *checksummedStorer) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	entries, err := s.Storer.GetBySurrogate(surrogateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range entries {
+		plain, err := s.verifyResponse(value)
+		if err != nil {
+			delete(entries, key)
+
+			continue
+		}
+
+		entries[key] = plain
+	}
+
+	return entries, nil
+}
+
+// Inspect verifies the stored value and reports its length without the trailer.
+func (s *checksummedStorer) Inspect(key string) (*EntryInfo, error) {
+	info, err := s.Storer.Inspect(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err := s.Storer.GetWithError(key); err == nil {
+		if err = s.verifyStored(value); err != nil {
+			return nil, err
+		}
+
+		info.ValueLength -= s.hash().Size()
+	}
+
+	return info, nil
+}
+
+// Export verifies each sealed value before writing it with its trailer.
+func (s *checksummedStorer) Export(w io.Writer) error {
+	return exportVerified(s.Storer, w, s.verifyStored)
+}
+
+func (s *checksummedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return s.Storer.SetMultiLevel(baseKey, variedKey, s.seal(value), variedHeaders, etag, duration, realKey)
+}
+
+// trailerReader returns the digest of the content read so far, once the content reader is drained.
+type trailerReader struct {
+	hash    hash.Hash
+	trailer *bytes.Reader
+}
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	if r.trailer == nil {
+		r.trailer = bytes.NewReader(r.hash.Sum(nil))
+	}
+
+	return r.trailer.Read(p)
+}
+
+// verifiedResponses exposes the responses stored by SetMultiLevel in the format expected by MappingElection.
+// The wrapped storer compressed the sealed value, it's decompressed then verified and served uncompressed.
+type verifiedResponses struct {
+	*checksummedStorer
+}
+
+func (s *verifiedResponses) Get(key string) []byte {
+	compressed := s.Storer.Get(key)
+	if compressed == nil {
+		return nil
+	}
+
+	plain, err := s.verifyResponse(compressed)
+	if err != nil {
+		return nil
+	}
+
+	return plain
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time to the expiry computations, see CacheProvider.Clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the real time clock, it's the default one.
+type SystemClock struct{}
+
+// Now method will return the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockOrDefault returns the clock, or the SystemClock if it's nil.
+func ClockOrDefault(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock{}
+	}
+
+	return clock
+}
+
+// ManualClock is a clock only moving when it's told to, it simulates the elapsed time and the skew
+// between the instances without waiting for the real time.
+type ManualClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewManualClock returns a clock stopped at the given time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now method will return the time the clock is stopped at.
+func (c *ManualClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.now
+}
+
+// Advance method will move the clock forward by the duration, a negative duration moves it backward.
+func (c *ManualClock) Advance(duration time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(duration)
+	c.mu.Unlock()
+}
+
+// Set method will stop the clock at the given time.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
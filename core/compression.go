@@ -0,0 +1,379 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+const (
+	// CompressionLZ4 compresses the values using lz4, this is the default codec.
+	CompressionLZ4 = "lz4"
+	// CompressionZstd compresses the values using zstd.
+	CompressionZstd = "zstd"
+	// CompressionNone stores the values as is.
+	CompressionNone = "none"
+	// CompressionAuto compresses each value using the codec giving the smallest result, see autoCodec.
+	CompressionAuto = "auto"
+
+	// MaxLZ4CompressionLevel is the highest lz4 compression level, 0 uses the fast default one.
+	MaxLZ4CompressionLevel = 9
+
+	// optionSeparator appends the lz4 level then block size options to the codec returned by ConfiguredCompression.
+	optionSeparator = ":"
+	// minSizeSeparator appends the size under which Compress stores the values uncompressed to the codec.
+	minSizeSeparator = "/"
+
+	// autoMinSize is the size under which CompressionAuto uses lz4 without trying the other codecs.
+	autoMinSize = 1 << 10
+	// autoSampleSize bounds the leading bytes of the value compressed by each CompressionAuto candidate.
+	autoSampleSize = 64 << 10
+)
+
+// The lz4 frames are self-describing and stay written without header to keep
+// the stored format backward compatible. The other codecs are prefixed by a
+// one-byte header that can't be mistaken for the lz4 frame magic.
+const (
+	noneHeader     byte = 0x01
+	zstdHeader     byte = 0x02
+	zstdDictHeader byte = 0x03
+)
+
+var (
+	lz4Magic  = []byte{0x04, 0x22, 0x4d, 0x18}
+	lz4Levels = []lz4.CompressionLevel{
+		lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4, lz4.Level5, lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+	}
+	lz4BlockSizes = []int{int(lz4.Block64Kb), int(lz4.Block256Kb), int(lz4.Block1Mb), int(lz4.Block4Mb)}
+
+	// ErrUnknownCompression is returned when the codec is not supported.
+	ErrUnknownCompression = errors.New("unknown compression codec")
+	// ErrUnknownCompressionHeader is returned when the stored value codec can't be detected.
+	ErrUnknownCompressionHeader = errors.New("unknown compression header")
+)
+
+// ConfiguredCompression returns the codec declared in the cache provider, CompressionNone when the compression is disabled.
+// The lz4 codec carries the compression level and block size when configured, an invalid one falls back to the default.
+// The zstd and auto codecs carry the identity of the compression dictionary when configured, the values are
+// compressed without it when it can't be read. The codec carries the compression minimum size too when configured.
+func ConfiguredCompression(cfg CacheProvider, logger Logger) string {
+	if cfg.DisableCompression {
+		return CompressionNone
+	}
+
+	codec := configuredCodec(cfg, logger)
+
+	switch dictionary, err := configuredDictionary(cfg); {
+	case err != nil:
+		logger.Warnf("Impossible to read the compression dictionary %s, the values are compressed without it, %v", cfg.CompressionDictionaryPath, err)
+	case len(dictionary) == 0:
+	case codec != CompressionZstd && codec != CompressionAuto:
+		logger.Warnf("The compression dictionary is only used by the %s and %s codecs, it's ignored.", CompressionZstd, CompressionAuto)
+	default:
+		codec += optionSeparator + fmt.Sprintf("%08x", registerDictionary(dictionary))
+	}
+
+	switch {
+	case cfg.CompressionMinSize < 0:
+		logger.Warnf("Invalid compression minimum size %d, it must not be negative, every value is compressed.", cfg.CompressionMinSize)
+	case cfg.CompressionMinSize > 0:
+		codec += minSizeSeparator + strconv.Itoa(cfg.CompressionMinSize)
+	}
+
+	return codec
+}
+
+// configuredCodec returns the codec declared in the cache provider with its lz4 options.
+func configuredCodec(cfg CacheProvider, logger Logger) string {
+	if cfg.Compression != "" && cfg.Compression != CompressionLZ4 {
+		return cfg.Compression
+	}
+
+	level := cfg.CompressionLevel
+	if level < 0 || level > MaxLZ4CompressionLevel {
+		logger.Warnf(
+			"Invalid lz4 compression level %d, it must be between 0 and %d, the default one is used.",
+			level,
+			MaxLZ4CompressionLevel,
+		)
+
+		level = 0
+	}
+
+	blockSize := cfg.CompressionBlockSize
+	if blockSize != 0 && !slices.Contains(lz4BlockSizes, blockSize) {
+		logger.Warnf("Invalid lz4 block size %d, it must be one of %v, the default one is used.", blockSize, lz4BlockSizes)
+
+		blockSize = 0
+	}
+
+	switch {
+	case blockSize != 0:
+		return CompressionLZ4 + optionSeparator + strconv.Itoa(level) + optionSeparator + strconv.Itoa(blockSize)
+	case level != 0:
+		return CompressionLZ4 + optionSeparator + strconv.Itoa(level)
+	default:
+		return cfg.Compression
+	}
+}
+
+// lz4Writer returns a lz4 writer using the level and block size carried by the codec if any, a zero level
+// keeps the default one.
+func lz4Writer(buf io.Writer, codec string) (io.WriteCloser, error) {
+	writer := lz4.NewWriter(buf)
+
+	options := strings.Split(codec, optionSeparator)[1:]
+	if len(options) > 2 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCompression, codec)
+	}
+
+	var applied []lz4.Option
+
+	if len(options) > 0 {
+		level, err := strconv.Atoi(options[0])
+		if err != nil || level < 0 || level > MaxLZ4CompressionLevel {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCompression, codec)
+		}
+
+		if level > 0 {
+			applied = append(applied, lz4.CompressionLevelOption(lz4Levels[level-1]))
+		}
+	}
+
+	if len(options) > 1 {
+		blockSize, err := strconv.Atoi(options[1])
+		if err != nil || !slices.Contains(lz4BlockSizes, blockSize) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCompression, codec)
+		}
+
+		applied = append(applied, lz4.BlockSizeOption(lz4.BlockSize(blockSize)))
+	}
+
+	if err := writer.Apply(applied...); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// multiLevelCompressionKey carries in the varied headers handed to SetMultiLevel the codec a decorator requires
+// for the response, see multiLevelTTLKey. The sealed responses of Encrypted don't compress and require
+// CompressionNone.
+const multiLevelCompressionKey = ":compression"
+
+// MultiLevelCompression returns the codec of the response stored by SetMultiLevel, the responses already
+// encoded, declaring a Content-Encoding, are stored uncompressed as compressing them again saves nothing.
+func MultiLevelCompression(codec string, variedHeaders http.Header) string {
+	if values := variedHeaders[multiLevelCompressionKey]; len(values) == 1 {
+		return values[0]
+	}
+
+	if variedHeaders.Get("Content-Encoding") != "" {
+		return CompressionNone
+	}
+
+	return codec
+}
+
+// splitMinSize returns the codec without the size under which the values are stored uncompressed, zero
+// when the codec doesn't carry it.
+func splitMinSize(codec string) (string, int, error) {
+	codec, option, found := strings.Cut(codec, minSizeSeparator)
+	if !found {
+		return codec, 0, nil
+	}
+
+	minSize, err := strconv.Atoi(option)
+	if err != nil || minSize < 0 {
+		return "", 0, fmt.Errorf("%w: %s%s%s", ErrUnknownCompression, codec, minSizeSeparator, option)
+	}
+
+	return codec, minSize, nil
+}
+
+// Compress compresses the data using the given codec and records the codec in the result. The data shorter
+// than the minimum size carried by the codec are recorded uncompressed.
+func Compress(codec string, data []byte) ([]byte, error) {
+	codec, minSize, err := splitMinSize(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < minSize {
+		codec = CompressionNone
+	}
+
+	if options, found := strings.CutPrefix(codec, CompressionAuto); found {
+		codec = autoCodec(data, options)
+	}
+
+	compressed := new(bytes.Buffer)
+
+	if err := compressTo(compressed, codec, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// autoCodec returns the codec used by CompressionAuto for the data, lz4 and zstd compress a sample of the data
+// and the smallest result wins, CompressionNone when none of them saves space. The extra compressions are
+// bounded by the sample size and skipped for the data shorter than autoMinSize which use lz4. The zstd candidate
+// gets the dictionary options of the auto codec.
+func autoCodec(data []byte, zstdOptions string) string {
+	if len(data) < autoMinSize {
+		return CompressionLZ4
+	}
+
+	sample := data[:min(len(data), autoSampleSize)]
+	elected, smallest := CompressionNone, len(sample)+1
+
+	for _, candidate := range []string{CompressionLZ4, CompressionZstd + zstdOptions} {
+		compressed := new(bytes.Buffer)
+		if err := compressTo(compressed, candidate, bytes.NewReader(sample)); err != nil {
+			continue
+		}
+
+		if compressed.Len() < smallest {
+			elected, smallest = candidate, compressed.Len()
+		}
+	}
+
+	return elected
+}
+
+// EstimateCompressedSize returns the size of the data once compressed by Compress using the codec, the size
+// SetMultiLevel stores for a response without Content-Encoding, see ConfiguredCompression for the codec.
+func EstimateCompressedSize(data []byte, codec string) (int, error) {
+	compressed, err := Compress(codec, data)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(compressed), nil
+}
+
+// Decompress detects the codec used to store the data and decompresses it.
+func Decompress(data []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if err := decompressTo(buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompressTo(buf *bytes.Buffer, data []byte) error {
+	if bytes.HasPrefix(data, lz4Magic) {
+		_, err := lz4.NewReader(bytes.NewReader(data)).WriteTo(buf)
+
+		return err
+	}
+
+	if len(data) == 0 {
+		return ErrUnknownCompressionHeader
+	}
+
+	switch data[0] {
+	case noneHeader:
+		_, err := buf.Write(data[1:])
+
+		return err
+	case zstdHeader:
+		reader, err := zstd.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+
+		defer reader.Close()
+
+		_, err = reader.WriteTo(buf)
+
+		return err
+	case zstdDictHeader:
+		reader, err := zstdDictReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+
+		defer reader.Close()
+
+		_, err = reader.WriteTo(buf)
+
+		return err
+	}
+
+	return ErrUnknownCompressionHeader
+}
+
+func compressTo(buf *bytes.Buffer, codec string, reader io.Reader) error {
+	var writer io.WriteCloser
+
+	// The streams are compressed whatever their size, unknown before reading them, using lz4 when the codec
+	// is elected per value.
+	codec, _, err := splitMinSize(codec)
+	if err != nil {
+		return err
+	}
+
+	codecName, option, _ := strings.Cut(codec, optionSeparator)
+
+	switch codecName {
+	case "", CompressionLZ4, CompressionAuto:
+		if codecName == CompressionAuto {
+			codec = CompressionLZ4
+		}
+
+		lw, err := lz4Writer(buf, codec)
+		if err != nil {
+			return err
+		}
+
+		writer = lw
+	case CompressionZstd:
+		if option != "" {
+			zw, err := zstdDictWriter(buf, option)
+			if err != nil {
+				return err
+			}
+
+			writer = zw
+
+			break
+		}
+
+		buf.WriteByte(zstdHeader)
+
+		zw, err := zstd.NewWriter(buf)
+		if err != nil {
+			return err
+		}
+
+		writer = zw
+	case CompressionNone:
+		buf.WriteByte(noneHeader)
+
+		_, err := buf.ReadFrom(reader)
+
+		return err
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownCompression, codec)
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		_ = writer.Close()
+
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// ConcurrencyOption configures the WithConcurrencyLimit decorator.
+type ConcurrencyOption func(*concurrencyStorer)
+
+// FailWhenSaturated makes the operations return ErrSaturated instead of waiting when every slot is taken.
+func FailWhenSaturated() ConcurrencyOption {
+	return func(s *concurrencyStorer) {
+		s.failFast = true
+	}
+}
+
+// WithConcurrencyLimit wraps the storer to bound the number of in-flight Get, GetContext, GetWithError, Set,
+// SetContext, Delete and DeleteKeys calls to max. A saturated call waits for a slot until its context is done
+// and returns the context error, or ErrSaturated right away with FailWhenSaturated. Delete doesn't report its
+// errors so it always waits for a slot. A non-positive max returns the storer as is.
+func WithConcurrencyLimit(storer Storer, max int, opts ...ConcurrencyOption) Storer {
+	if max <= 0 {
+		return storer
+	}
+
+	s := &concurrencyStorer{Storer: storer, slots: make(chan struct{}, max)}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+type concurrencyStorer struct {
+	Storer
+	slots    chan struct{}
+	failFast bool
+}
+
+// acquire takes a slot, the caller must release it once the operation returns.
+func (s *concurrencyStorer) acquire(ctx context.Context, failFast bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if failFast {
+		select {
+		case s.slots <- struct{}{}:
+			return nil
+		default:
+			return ErrSaturated
+		}
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *concurrencyStorer) release() {
+	<-s.slots
+}
+
+func (s *concurrencyStorer) run(ctx context.Context, operation func() error) error {
+	if err := s.acquire(ctx, s.failFast); err != nil {
+		return err
+	}
+	defer s.release()
+
+	return operation()
+}
+
+func (s *concurrencyStorer) Get(key string) []byte {
+	value, _ := s.GetContext(context.Background(), key)
+
+	return value
+}
+
+func (s *concurrencyStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+
+	err := s.run(ctx, func() error {
+		var err error
+		value, err = s.Storer.GetContext(ctx, key)
+
+		return err
+	})
+
+	return value, err
+}
+
+func (s *concurrencyStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *concurrencyStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *concurrencyStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.run(ctx, func() error {
+		return s.Storer.SetContext(ctx, key, value, duration)
+	})
+}
+
+func (s *concurrencyStorer) Delete(key string) {
+	_ = s.acquire(context.Background(), false)
+	defer s.release()
+
+	s.Storer.Delete(key)
+}
+
+func (s *concurrencyStorer) DeleteKeys(keys []string) error {
+	return s.run(context.Background(), func() error {
+		return s.Storer.DeleteKeys(keys)
+	})
+}
@@ -2,6 +2,13 @@ package core
 
 import "time"
 
+const (
+	// IndexModeKeyValue keeps the nuts keys and values in memory, the fastest reads and the default index mode.
+	IndexModeKeyValue = "key_value"
+	// IndexModeKey only keeps the nuts keys in memory and reads the values from disk, a smaller memory footprint.
+	IndexModeKey = "key"
+)
+
 type Configuration struct {
 	Provider CacheProvider `json:"provider"`
 	Stale    time.Duration `json:"stale"`
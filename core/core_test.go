@@ -1 +1,2320 @@
 package core_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCompressDecompress(t *testing.T) {
+	value := make([]byte, 5*1024*1024)
+	for i := range value {
+		value[i] = byte(i % 256)
+	}
+
+	for _, codec := range []string{"", core.CompressionLZ4, core.CompressionZstd, core.CompressionNone} {
+		compressed, err := core.Compress(codec, value)
+		if err != nil {
+			t.Fatalf("Impossible to compress using the codec %q: %v", codec, err)
+		}
+
+		decompressed, err := core.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("Impossible to decompress using the codec %q: %v", codec, err)
+		}
+
+		if !bytes.Equal(value, decompressed) {
+			t.Errorf("The decompressed value doesn't match the original one using the codec %q", codec)
+		}
+	}
+}
+
+type warnLogger struct {
+	core.Logger
+	warnings []string
+}
+
+func (l *warnLogger) Warnf(template string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(template, args...))
+}
+
+func TestCompressionLevel(t *testing.T) {
+	value := bytes.Repeat([]byte("The lz4 compression level trades CPU for ratio. "), 1024)
+	logger := &warnLogger{}
+	sizes := map[int]int{}
+
+	for _, level := range []int{1, 9} {
+		codec := core.ConfiguredCompression(core.CacheProvider{CompressionLevel: level}, logger)
+
+		compressed, err := core.Compress(codec, value)
+		if err != nil {
+			t.Fatalf("Impossible to compress at the level %d: %v", level, err)
+		}
+
+		decompressed, err := core.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("Impossible to decompress the value compressed at the level %d: %v", level, err)
+		}
+
+		if !bytes.Equal(value, decompressed) {
+			t.Errorf("The value compressed at the level %d doesn't match the original one", level)
+		}
+
+		sizes[level] = len(compressed)
+	}
+
+	if sizes[9] > sizes[1] {
+		t.Errorf("The level 9 shouldn't compress less than the level 1, %v provided", sizes)
+	}
+
+	if len(logger.warnings) != 0 {
+		t.Errorf("The valid levels shouldn't log any warning, %v provided", logger.warnings)
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{CompressionLevel: 42}, logger); codec != "" {
+		t.Errorf("An invalid level should fall back to the default codec, %q provided", codec)
+	}
+
+	if len(logger.warnings) != 1 {
+		t.Errorf("An invalid level should log a warning, %v provided", logger.warnings)
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd, CompressionLevel: 9}, logger); codec != core.CompressionZstd {
+		t.Errorf("The level should only apply to lz4, %q provided", codec)
+	}
+}
+
+func TestCompressionBlockSize(t *testing.T) {
+	value := make([]byte, 5*1024*1024)
+	for i := range value {
+		value[i] = byte(i % 251)
+	}
+
+	logger := &warnLogger{}
+
+	// The frame descriptor BD byte holds the block maximum size identifier, 4 for 64KB up to 7 for 4MB.
+	for blockSize, identifier := range map[int]byte{64 << 10: 4, 4 << 20: 7} {
+		codec := core.ConfiguredCompression(core.CacheProvider{CompressionBlockSize: blockSize, CompressionLevel: 1}, logger)
+
+		compressed, err := core.Compress(codec, value)
+		if err != nil {
+			t.Fatalf("Impossible to compress using the block size %d: %v", blockSize, err)
+		}
+
+		if compressed[5]>>4&0x07 != identifier {
+			t.Errorf("The frame should declare the block size %d, the identifier %d provided", blockSize, compressed[5]>>4&0x07)
+		}
+
+		decompressed, err := core.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("Impossible to decompress the value compressed using the block size %d: %v", blockSize, err)
+		}
+
+		if !bytes.Equal(value, decompressed) {
+			t.Errorf("The value compressed using the block size %d doesn't match the original one", blockSize)
+		}
+	}
+
+	if len(logger.warnings) != 0 {
+		t.Errorf("The valid block sizes shouldn't log any warning, %v provided", logger.warnings)
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{CompressionBlockSize: 1000}, logger); codec != "" {
+		t.Errorf("An invalid block size should fall back to the default codec, %q provided", codec)
+	}
+
+	if len(logger.warnings) != 1 {
+		t.Errorf("An invalid block size should log a warning, %v provided", logger.warnings)
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd, CompressionBlockSize: 64 << 10}, logger); codec != core.CompressionZstd {
+		t.Errorf("The block size should only apply to lz4, %q provided", codec)
+	}
+}
+
+func TestCompressionMinSize(t *testing.T) {
+	logger := &warnLogger{}
+	large := bytes.Repeat([]byte("compressible value "), 1<<20/19)
+
+	for _, compression := range []string{core.CompressionLZ4, core.CompressionZstd} {
+		codec := core.ConfiguredCompression(core.CacheProvider{Compression: compression, CompressionLevel: 1, CompressionMinSize: 128}, logger)
+
+		for name, tc := range map[string]struct {
+			value []byte
+			raw   bool
+		}{
+			"below": {[]byte("tiny value"), true},
+			"above": {large, false},
+		} {
+			compressed, err := core.Compress(codec, tc.value)
+			if err != nil {
+				t.Fatalf("Impossible to compress the value %s the threshold using %s: %v", name, compression, err)
+			}
+
+			if raw := len(compressed) == len(tc.value)+1 && bytes.HasSuffix(compressed, tc.value); raw != tc.raw {
+				t.Errorf("The value %s the threshold using %s should be stored raw: %t, %d bytes provided", name, compression, tc.raw, len(compressed))
+			}
+
+			decompressed, err := core.Decompress(compressed)
+			if err != nil || !bytes.Equal(decompressed, tc.value) {
+				t.Errorf("The value %s the threshold using %s should round-trip, %v provided", name, compression, err)
+			}
+		}
+	}
+
+	if compressed, _ := core.Compress(core.ConfiguredCompression(core.CacheProvider{}, logger), []byte("tiny value")); !bytes.HasPrefix(compressed, []byte{0x04, 0x22, 0x4d, 0x18}) {
+		t.Error("Every value should be compressed without minimum size")
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{CompressionMinSize: -1}, logger); codec != "" || len(logger.warnings) != 1 {
+		t.Errorf("A negative minimum size should be ignored with a warning, %q and %v provided", codec, logger.warnings)
+	}
+
+	if _, err := core.Compress("lz4/abc", []byte("value")); !errors.Is(err, core.ErrUnknownCompression) {
+		t.Errorf("An invalid minimum size should return ErrUnknownCompression, %v provided", err)
+	}
+
+	var stream bytes.Buffer
+
+	err := core.CompressStream(core.ConfiguredCompression(core.CacheProvider{CompressionMinSize: 128}, logger), bytes.NewReader([]byte("tiny value")), func(compressed []byte) error {
+		_, err := stream.Write(compressed)
+
+		return err
+	})
+	if err != nil || !bytes.HasPrefix(stream.Bytes(), []byte{0x04, 0x22, 0x4d, 0x18}) {
+		t.Errorf("The streams should be compressed whatever the minimum size, %v provided", err)
+	}
+}
+
+func TestCompressionAuto(t *testing.T) {
+	random := make([]byte, 1<<16)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+
+	for name, tc := range map[string]struct {
+		value  []byte
+		header []byte
+	}{
+		"text":   {bytes.Repeat([]byte("highly compressible text "), 1<<12), []byte{0x02}},
+		"random": {random, []byte{0x01}},
+		"small":  {[]byte("small value"), []byte{0x04, 0x22, 0x4d, 0x18}},
+	} {
+		compressed, err := core.Compress(core.CompressionAuto, tc.value)
+		if err != nil {
+			t.Fatalf("Impossible to compress the %s value: %v", name, err)
+		}
+
+		if !bytes.HasPrefix(compressed, tc.header) {
+			t.Errorf("The %s value should be stored with the header %x, %x provided", name, tc.header, compressed[:4])
+		}
+
+		decompressed, err := core.Decompress(compressed)
+		if err != nil || !bytes.Equal(decompressed, tc.value) {
+			t.Errorf("The %s value should round-trip, %v provided", name, err)
+		}
+	}
+
+	if err := core.ValidateConfig(core.CacheProvider{Compression: core.CompressionAuto}); err != nil {
+		t.Errorf("The auto compression should be valid, %v provided", err)
+	}
+}
+
+func TestEstimateCompressedSize(t *testing.T) {
+	logger := &warnLogger{}
+	large := bytes.Repeat([]byte("compressible value "), 1<<16)
+
+	for name, tc := range map[string]struct {
+		codec string
+		value []byte
+		size  int
+	}{
+		"none":      {core.CompressionNone, large, len(large) + 1},
+		"below min": {core.ConfiguredCompression(core.CacheProvider{CompressionMinSize: 128}, logger), []byte("tiny value"), len("tiny value") + 1},
+		"lz4":       {core.ConfiguredCompression(core.CacheProvider{}, logger), large, 0},
+		"zstd":      {core.CompressionZstd, large, 0},
+	} {
+		size, err := core.EstimateCompressedSize(tc.value, tc.codec)
+		if err != nil {
+			t.Fatalf("Impossible to estimate the %s compressed size: %v", name, err)
+		}
+
+		compressed, _ := core.Compress(tc.codec, tc.value)
+		if size != len(compressed) || (tc.size != 0 && size != tc.size) {
+			t.Errorf("The %s estimate should match the compressed size %d, %d provided", name, len(compressed), size)
+		}
+
+		if tc.size == 0 && size >= len(tc.value)/10 {
+			t.Errorf("The %s estimate should reflect the compression ratio, %d bytes of %d provided", name, size, len(tc.value))
+		}
+	}
+
+	if _, err := core.EstimateCompressedSize([]byte("value"), "unknown"); !errors.Is(err, core.ErrUnknownCompression) {
+		t.Errorf("An unknown codec should return ErrUnknownCompression, %v provided", err)
+	}
+}
+
+func TestVaryAll(t *testing.T) {
+	for name, tc := range map[string]struct {
+		headers http.Header
+		all     bool
+	}{
+		"none":      {nil, false},
+		"varied":    {http.Header{"Accept-Encoding": []string{"gzip"}}, false},
+		"vary":      {http.Header{"Vary": []string{"*"}}, true},
+		"vary list": {http.Header{"Vary": []string{"Accept-Encoding , *"}}, true},
+		"member":    {http.Header{"*": []string{""}}, true},
+		"wildcard":  {http.Header{"Vary": []string{"Accept-*"}}, false},
+	} {
+		if all := core.VaryAll(tc.headers); all != tc.all {
+			t.Errorf("The %s headers should vary on everything: %t, %t provided", name, tc.all, all)
+		}
+	}
+
+	now := time.Now()
+
+	item, err := core.MappingUpdater("VaryAllKey", nil, &warnLogger{}, now, now.Add(time.Minute), now.Add(time.Hour), http.Header{"Vary": []string{"*"}}, "", "VaryAllKey")
+	if err != nil {
+		t.Fatalf("Impossible to map the Vary: * response: %v", err)
+	}
+
+	mapping, _ := core.DecodeMapping(item)
+	if entry := mapping.GetMapping()["VaryAllKey"]; entry.GetFreshTime().AsTime().After(now) || !entry.GetStaleTime().AsTime().After(now) || len(entry.GetVariedHeaders()) != 0 {
+		t.Errorf("The Vary: * response should be mapped stale only without the Vary header, %v provided", entry)
+	}
+}
+
+func TestCompressUnknownCodec(t *testing.T) {
+	if _, err := core.Compress("unknown", []byte("value")); !errors.Is(err, core.ErrUnknownCompression) {
+		t.Errorf("An unknown codec should return ErrUnknownCompression, %v provided", err)
+	}
+
+	if _, err := core.Decompress([]byte{0xff, 0x00}); !errors.Is(err, core.ErrUnknownCompressionHeader) {
+		t.Errorf("An unknown header should return ErrUnknownCompressionHeader, %v provided", err)
+	}
+}
+
+func TestCompressDecompressStream(t *testing.T) {
+	value := make([]byte, 5*1024*1024)
+	for i := range value {
+		value[i] = byte(i % 256)
+	}
+
+	for _, codec := range []string{"", core.CompressionLZ4, core.CompressionZstd, core.CompressionNone} {
+		var compressed []byte
+
+		err := core.CompressStream(codec, bytes.NewReader(value), func(b []byte) error {
+			compressed = bytes.Clone(b)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Impossible to compress using the codec %q: %v", codec, err)
+		}
+
+		// The streamed format must stay readable by Decompress and the other way around.
+		if decompressed, err := core.Decompress(compressed); err != nil || !bytes.Equal(value, decompressed) {
+			t.Errorf("The streamed value doesn't match the original one using the codec %q: %v", codec, err)
+		}
+
+		reader, err := core.DecompressStream(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("Impossible to decompress using the codec %q: %v", codec, err)
+		}
+
+		decompressed, err := io.ReadAll(reader)
+		_ = reader.Close()
+
+		if err != nil || !bytes.Equal(value, decompressed) {
+			t.Errorf("The decompressed stream doesn't match the original one using the codec %q: %v", codec, err)
+		}
+	}
+
+	if _, err := core.DecompressStream(bytes.NewReader([]byte{0xff, 0x00})); !errors.Is(err, core.ErrUnknownCompressionHeader) {
+		t.Errorf("An unknown header should return ErrUnknownCompressionHeader, %v provided", err)
+	}
+}
+
+func TestPaginateKeys(t *testing.T) {
+	keys := []string{"b2", "a1", "b1", "b3", "c1"}
+
+	page, next := core.PaginateKeys(keys, "b", "", 2)
+	if len(page) != 2 || page[0] != "b1" || page[1] != "b2" || next != "b3" {
+		t.Errorf("Unexpected first page %v with the next cursor %s", page, next)
+	}
+
+	page, next = core.PaginateKeys(keys, "b", next, 2)
+	if len(page) != 1 || page[0] != "b3" || next != "" {
+		t.Errorf("Unexpected last page %v with the next cursor %s", page, next)
+	}
+}
+
+func TestInternalKeys(t *testing.T) {
+	keys := []string{"user", core.MappingKeyPrefix + "user", core.SurrogateKeyPrefix + "tag", core.EntryMetaKeyPrefix + "user", core.HashedKeyPrefix + "abc"}
+
+	for _, key := range keys[1:] {
+		if !core.IsInternalKey(key) || core.ListedKey("", key) {
+			t.Errorf("The key %s should be internal and not listed without prefix", key)
+		}
+	}
+
+	if core.IsInternalKey("user") || !core.ListedKey("", "user") {
+		t.Error("The key user should be listed")
+	}
+
+	if !core.ListedKey(core.MappingKeyPrefix, core.MappingKeyPrefix+"user") {
+		t.Error("The mapping key should be listed with the mapping prefix")
+	}
+
+	if page, next := core.PaginateKeys(keys, "", "", 0); len(page) != 1 || page[0] != "user" || next != "" {
+		t.Errorf("Only the user key should be paginated, %v provided with the next cursor %s", page, next)
+	}
+
+	if page, _ := core.PaginateKeys(keys, core.SurrogateKeyPrefix, "", 0); len(page) != 1 || page[0] != core.SurrogateKeyPrefix+"tag" {
+		t.Errorf("The surrogate key should be paginated with its prefix, %v provided", page)
+	}
+
+	if next := core.NextKey("user"); next <= "user" || next >= "user0" {
+		t.Errorf("The next key %q should be sorted right after the key", next)
+	}
+}
+
+type fakeStorer struct {
+	core.Storer
+	values map[string][]byte
+}
+
+func (s *fakeStorer) Name() string { return "FAKE" }
+
+func (s *fakeStorer) Get(key string) []byte { return s.values[key] }
+
+func (s *fakeStorer) Set(key string, _ []byte, _ time.Duration) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+
+	return nil
+}
+
+func (s *fakeStorer) SetMultiLevel(_, _ string, _ []byte, _ http.Header, _ string, _ time.Duration, _ string) error {
+	return nil
+}
+
+type slowGetStorer struct {
+	fakeStorer
+	delay time.Duration
+}
+
+func (s *slowGetStorer) Get(key string) []byte {
+	time.Sleep(s.delay)
+
+	return s.fakeStorer.Get(key)
+}
+
+func TestSlowLogged(t *testing.T) {
+	storer := &slowGetStorer{fakeStorer: fakeStorer{values: map[string][]byte{"slow": []byte("slow value")}}, delay: 20 * time.Millisecond}
+	if instrumented := mustInstrument(t, storer, core.CacheProvider{}, nil); instrumented != core.Storer(storer) {
+		t.Error("The storer shouldn't be wrapped when the slow log is disabled")
+	}
+
+	observed, logs := observer.New(zap.WarnLevel)
+	logged := mustInstrument(t, storer, core.CacheProvider{SlowLogThreshold: 10 * time.Millisecond}, zap.New(observed).Sugar())
+
+	_ = logged.Get("slow")
+	_ = logged.Set("fast", []byte("value"), time.Minute)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Only the slow Get should be logged, %d entries provided", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	if fields["op"] != "get" || fields["key"] != "slow" || fields["size"] != int64(len("slow value")) {
+		t.Errorf("The warning should hold the op, key and size fields, %v provided", fields)
+	}
+
+	if duration, ok := fields["duration"].(time.Duration); !ok || duration < 20*time.Millisecond {
+		t.Errorf("The warning should hold the operation duration, %v provided", fields["duration"])
+	}
+}
+
+type fakeMetrics struct {
+	hits, misses int
+	errors       map[string]int
+}
+
+func (m *fakeMetrics) IncHit(string) { m.hits++ }
+
+func (m *fakeMetrics) IncMiss(string) { m.misses++ }
+
+func (m *fakeMetrics) IncError(backend, op string) { m.errors[backend+"."+op]++ }
+
+func TestNormalizeTTL(t *testing.T) {
+	for _, duration := range []time.Duration{-time.Minute, -1, 0} {
+		if store, ttl := core.NormalizeTTL(duration); store || ttl != 0 {
+			t.Errorf("The duration %v shouldn't be stored, %v %v provided", duration, store, ttl)
+		}
+	}
+
+	if store, ttl := core.NormalizeTTL(time.Minute); !store || ttl != time.Minute {
+		t.Errorf("The duration 1m should be stored as is, %v %v provided", store, ttl)
+	}
+}
+
+func TestMultiLevelTTL(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cacheControl []string
+		expected     time.Duration
+		cacheable    bool
+	}{
+		"fallback":            {expected: time.Hour, cacheable: true},
+		"max-age":             {cacheControl: []string{"public, max-age=60"}, expected: time.Minute, cacheable: true},
+		"s-maxage precedence": {cacheControl: []string{"max-age=60, s-maxage=120"}, expected: 2 * time.Minute, cacheable: true},
+		"split headers":       {cacheControl: []string{"S-MAXAGE=\"30\"", "max-age=60"}, expected: 30 * time.Second, cacheable: true},
+		"zero max-age":        {cacheControl: []string{"max-age=0"}, expected: 0, cacheable: true},
+		"invalid max-age":     {cacheControl: []string{"max-age=soon, no-cache"}, expected: time.Hour, cacheable: true},
+		"no-store":            {cacheControl: []string{"max-age=60, no-store"}, cacheable: false},
+	} {
+		headers := http.Header{"Cache-Control": tc.cacheControl}
+
+		ttl, cacheable := core.MultiLevelTTL(headers, time.Hour)
+		if cacheable != tc.cacheable || (cacheable && ttl != tc.expected) {
+			t.Errorf("%s: the ttl %v and cacheable %v were expected, %v and %v provided", name, tc.expected, tc.cacheable, ttl, cacheable)
+		}
+	}
+}
+
+func TestAddCounter(t *testing.T) {
+	value, encoded, err := core.AddCounter(nil, 5)
+	if err != nil || value != 5 {
+		t.Errorf("A missing counter should start from 0, %d provided: %v", value, err)
+	}
+
+	if value, _, _ = core.AddCounter(encoded, -7); value != -2 {
+		t.Errorf("The counter should be decremented to -2, %d provided", value)
+	}
+
+	if _, _, err = core.AddCounter([]byte("not a counter"), 1); !errors.Is(err, core.ErrNotCounter) {
+		t.Errorf("Incrementing a malformed value should return ErrNotCounter, %v provided", err)
+	}
+}
+
+func TestMappers(t *testing.T) {
+	now := time.Now()
+	mapping := &core.StorageMapper{Mapping: map[string]*core.KeyIndex{}}
+
+	for _, encoding := range []string{"gzip", "br", "zstd"} {
+		mapping.Mapping["varied-"+encoding] = &core.KeyIndex{
+			StoredAt:  timestamppb.New(now),
+			FreshTime: timestamppb.New(now.Add(time.Minute)),
+			StaleTime: timestamppb.New(now.Add(2 * time.Minute)),
+			VariedHeaders: map[string]*core.KeyIndexStringList{
+				"Accept-Encoding": {HeaderValue: []string{encoding}},
+				"Accept-Language": {HeaderValue: []string{"en", "fr"}},
+			},
+			Etag:    "etag-" + encoding,
+			RealKey: "real-" + encoding,
+		}
+	}
+
+	for _, mapper := range []core.Mapper{core.ProtobufMapper{}, core.JSONMapper{}} {
+		item, err := mapper.Encode(mapping)
+		if err != nil {
+			t.Fatalf("Impossible to encode the mapping with %T: %v", mapper, err)
+		}
+
+		decoded, err := mapper.Decode(item)
+		if err != nil {
+			t.Fatalf("Impossible to decode the mapping with %T: %v", mapper, err)
+		}
+
+		if !proto.Equal(mapping, decoded) {
+			t.Errorf("The mapping should round-trip with %T, %v provided", mapper, decoded)
+		}
+	}
+
+	if item, _ := (core.JSONMapper{}).Encode(mapping); !bytes.HasPrefix(item, []byte("{")) {
+		t.Errorf("The JSONMapper should store JSON, %s provided", item)
+	}
+}
+
+func TestInstrumentMetrics(t *testing.T) {
+	storer := &fakeStorer{values: map[string][]byte{"key": []byte("value")}}
+
+	if instrumented := mustInstrument(t, storer, core.CacheProvider{}, nil); instrumented != core.Storer(storer) {
+		t.Error("The storer should not be wrapped without metrics")
+	}
+
+	metrics := &fakeMetrics{errors: map[string]int{}}
+	instrumented := mustInstrument(t, storer, core.CacheProvider{Metrics: metrics}, nil)
+
+	_ = instrumented.Get("key")
+	_ = instrumented.Get("missing")
+	_ = instrumented.Get("missing")
+	_ = instrumented.Set("key", nil, 0)
+	_ = instrumented.Set("", nil, 0)
+
+	if metrics.hits != 1 || metrics.misses != 2 {
+		t.Errorf("Expected 1 hit and 2 misses, %d hits and %d misses provided", metrics.hits, metrics.misses)
+	}
+
+	if metrics.errors["fake.Set"] != 1 || len(metrics.errors) != 1 {
+		t.Errorf("Expected one Set error, %v provided", metrics.errors)
+	}
+}
+
+func TestInstrumentTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	instrumented := mustInstrument(t, &fakeStorer{values: map[string][]byte{}}, core.CacheProvider{TracerProvider: provider}, nil)
+
+	_ = instrumented.Get("key")
+	_ = instrumented.Set("", []byte("value"), 0)
+	_ = instrumented.SetMultiLevel("key", "key", []byte("value"), http.Header{}, "", 0, "key")
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, %d provided", len(spans))
+	}
+
+	for i, name := range []string{"storages.fake.Get", "storages.fake.Set", "storages.fake.SetMultiLevel"} {
+		if spans[i].Name() != name {
+			t.Errorf("Expected the span %s, %s provided", name, spans[i].Name())
+		}
+	}
+
+	if len(spans[1].Events()) != 1 || spans[1].Status().Code != codes.Error {
+		t.Error("The Set error should be recorded on the span")
+	}
+}
+
+type memoryStorer struct {
+	core.Storer
+	values map[string][]byte
+	closed bool
+}
+
+func (s *memoryStorer) GetContext(_ context.Context, key string) ([]byte, error) {
+	value, ok := s.values[key]
+	if !ok {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+func (s *memoryStorer) SetContext(_ context.Context, key string, value []byte, _ time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *memoryStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *memoryStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *memoryStorer) CompareAndSwap(key string, old, value []byte, _ time.Duration) (bool, error) {
+	if !core.SwapMatches(s.values[key], old) {
+		return false, nil
+	}
+
+	s.values[key] = value
+
+	return true, nil
+}
+
+func (s *memoryStorer) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestEncrypted(t *testing.T) {
+	storer := &memoryStorer{values: map[string][]byte{}}
+	encrypted := core.Encrypted(storer, bytes.Repeat([]byte("k"), 32))
+
+	if err := encrypted.Set("key", []byte("secret value"), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the encrypted value: %v", err)
+	}
+
+	if bytes.Contains(storer.values["key"], []byte("secret value")) {
+		t.Error("The stored value shouldn't contain the plain text")
+	}
+
+	if res := encrypted.Get("key"); string(res) != "secret value" {
+		t.Errorf("The decrypted value should be secret value, %s provided", res)
+	}
+
+	_ = encrypted.Set("other", []byte("secret value"), time.Minute)
+
+	if bytes.Equal(storer.values["key"], storer.values["other"]) {
+		t.Error("The same value should be sealed with different nonces")
+	}
+
+	wrongKey := core.Encrypted(storer, bytes.Repeat([]byte("w"), 32))
+
+	if _, err := wrongKey.GetContext(context.Background(), "key"); !errors.Is(err, core.ErrDecryption) {
+		t.Errorf("Reading with another key should return ErrDecryption, %v provided", err)
+	}
+
+	if res := wrongKey.Get("key"); res != nil {
+		t.Errorf("Reading with another key shouldn't return any value, %s provided", res)
+	}
+
+	storer.values["key"] = storer.values["key"][:4]
+
+	if _, err := encrypted.GetContext(context.Background(), "key"); !errors.Is(err, core.ErrDecryption) {
+		t.Errorf("Reading a truncated value should return ErrDecryption, %v provided", err)
+	}
+}
+
+func TestEncryptedInvalidKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("An invalid key length should panic")
+		}
+	}()
+
+	_ = core.Encrypted(&memoryStorer{}, []byte("short"))
+}
+
+// mustInstrument instruments the storer and fails the test on error.
+func mustInstrument(t *testing.T, storer core.Storer, cfg core.CacheProvider, logger core.Logger) core.Storer {
+	t.Helper()
+
+	instrumented, err := core.Instrument(storer, cfg, logger)
+	if err != nil {
+		t.Fatalf("Impossible to instrument the storer: %v", err)
+	}
+
+	return instrumented
+}
+
+func TestKeyHashed(t *testing.T) {
+	storer := &memoryStorer{values: map[string][]byte{}}
+
+	hashed, err := core.KeyHashed(storer, core.KeyHashingXXHash)
+	if err != nil {
+		t.Fatalf("Impossible to hash the keys: %v", err)
+	}
+
+	key := strings.Repeat("https://domain.com/long/path", 100)
+
+	if err := hashed.Set(key, []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the hashed key: %v", err)
+	}
+
+	for stored, value := range storer.values {
+		if len(stored) > len(core.HashedKeyPrefix)+16 {
+			t.Errorf("The stored key %s should be bounded", stored)
+		}
+
+		if strings.HasPrefix(stored, core.HashedKeyPrefix) && string(value) != key {
+			t.Errorf("The reverse index should store the original key, %s provided", value)
+		}
+	}
+
+	if res, _ := hashed.GetContext(context.Background(), key); string(res) != "value" {
+		t.Errorf("The hashed key should be readable, %s provided", res)
+	}
+
+	if none, _ := core.KeyHashed(storer, core.KeyHashingNone); none != core.Storer(storer) {
+		t.Error("The none key hashing should return the storer as is")
+	}
+
+	if _, err := core.KeyHashed(storer, "unknown"); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("An unknown key hashing should return ErrInvalidConfig, %v provided", err)
+	}
+
+	if _, err := core.Instrument(storer, core.CacheProvider{KeyHashing: "unknown"}, nil); !errors.Is(err, core.ErrInvalidConfig) || !storer.closed {
+		t.Errorf("Instrument should close the storer and return the unknown key hashing error, %v provided", err)
+	}
+}
+
+func TestInstrumentMaxValueSize(t *testing.T) {
+	storer := &memoryStorer{values: map[string][]byte{}}
+	limited := mustInstrument(t, storer, core.CacheProvider{MaxValueSize: 10}, nil)
+
+	if err := limited.Set("key", bytes.Repeat([]byte("a"), 10), time.Minute); err != nil {
+		t.Errorf("A value at the limit should be stored, %v provided", err)
+	}
+
+	if err := limited.Set("over", bytes.Repeat([]byte("a"), 11), time.Minute); !errors.Is(err, core.ErrValueTooLarge) {
+		t.Errorf("A value over the limit should return ErrValueTooLarge, %v provided", err)
+	}
+
+	if _, found := storer.values["over"]; found {
+		t.Error("The value over the limit shouldn't be stored")
+	}
+
+	err := limited.SetMultiLevel("over", "over", bytes.Repeat([]byte("a"), 11), http.Header{}, "", time.Minute, "over")
+	if !errors.Is(err, core.ErrValueTooLarge) {
+		t.Errorf("A response over the limit should return ErrValueTooLarge, %v provided", err)
+	}
+}
+
+func TestInstrumentReadOnly(t *testing.T) {
+	storer := &memoryStorer{values: map[string][]byte{"key": []byte("value")}}
+	readOnly := mustInstrument(t, storer, core.CacheProvider{ReadOnly: true}, nil)
+
+	if value, err := readOnly.GetContext(context.Background(), "key"); err != nil || string(value) != "value" {
+		t.Errorf("The reads should reach the storer, %s and %v provided", value, err)
+	}
+
+	if err := readOnly.Set("other", []byte("value"), time.Minute); !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The Set should return ErrReadOnly, %v provided", err)
+	}
+
+	if _, err := readOnly.SetNX("other", []byte("value"), time.Minute); !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The SetNX should return ErrReadOnly, %v provided", err)
+	}
+
+	err := readOnly.SetMultiLevel("other", "other", []byte("value"), http.Header{}, "", time.Minute, "other")
+	if !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The SetMultiLevel should return ErrReadOnly, %v provided", err)
+	}
+
+	if err = readOnly.DeleteKeys([]string{"key"}); !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The DeleteKeys should return ErrReadOnly, %v provided", err)
+	}
+
+	readOnly.Delete("key")
+
+	if _, found := storer.values["other"]; found || len(storer.values) != 1 {
+		t.Errorf("The read-only storer shouldn't be written, %v provided", storer.values)
+	}
+}
+
+func TestSurrogateKeys(t *testing.T) {
+	response := "HTTP/1.1 200 OK\r\nSurrogate-Key: first  second\r\nSurrogate-Key: third\r\nContent-Length: 0\r\n\r\n"
+
+	keys := core.SurrogateKeys([]byte(response))
+	if strings.Join(keys, ",") != "first,second,third" {
+		t.Errorf("The surrogate keys should be read from every Surrogate-Key header, %v provided", keys)
+	}
+
+	if keys = core.SurrogateKeys([]byte("not a response")); len(keys) != 0 {
+		t.Errorf("A value that isn't a response shouldn't have any surrogate key, %v provided", keys)
+	}
+}
+
+// surrogateStorer counts the reads of the stored values and fails the DeleteKeys calls with the failure if any.
+type surrogateStorer struct {
+	core.Storer
+	values  map[string][]byte
+	reads   int
+	failure error
+}
+
+func (s *surrogateStorer) Get(key string) []byte {
+	s.reads++
+
+	return s.values[key]
+}
+
+func (s *surrogateStorer) Exists(key string) bool {
+	_, found := s.values[key]
+
+	return found
+}
+
+func (s *surrogateStorer) GetTTL(key string) (time.Duration, bool) {
+	_, found := s.values[key]
+
+	return time.Minute, found
+}
+
+func (s *surrogateStorer) Set(key string, value []byte, _ time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *surrogateStorer) Delete(key string) {
+	delete(s.values, key)
+}
+
+func (s *surrogateStorer) DeleteKeys(keys []string) error {
+	if s.failure != nil {
+		return s.failure
+	}
+
+	deleted, err := core.KeysToDelete(s, keys)
+	for _, key := range deleted {
+		delete(s.values, key)
+	}
+
+	return err
+}
+
+func TestSurrogateIndex(t *testing.T) {
+	response := []byte("HTTP/1.1 200 OK\r\nSurrogate-Key: group\r\nContent-Length: 0\r\n\r\n")
+	compressed, _ := core.Compress(core.CompressionNone, response)
+	storer := &surrogateStorer{values: map[string][]byte{"first": compressed, "second": compressed}}
+
+	core.UnindexSurrogateKeys(storer, "first")
+
+	if storer.reads != 0 {
+		t.Errorf("The deletions shouldn't read anything without surrogate index, %d reads provided", storer.reads)
+	}
+
+	for _, key := range []string{"first", "second"} {
+		if err := core.IndexSurrogateKeys(storer, key, response, time.Minute); err != nil {
+			t.Fatalf("Impossible to index the key %s: %v", key, err)
+		}
+	}
+
+	if err := storer.DeleteKeys([]string{"first"}); err != nil {
+		t.Errorf("Impossible to delete the indexed key: %v", err)
+	}
+
+	if index := string(storer.values[core.SurrogateKeyPrefix+"group"]); index != "second" {
+		t.Errorf("The deleted key should be unindexed once an index is written, %s provided", index)
+	}
+
+	storer.failure = errors.New("backend failure")
+
+	if deleted, err := core.InvalidateSurrogateKeys(storer, "group"); deleted != 0 || !errors.Is(err, storer.failure) {
+		t.Errorf("The invalidation should return the deletion errors, %d deleted and %v provided", deleted, err)
+	}
+}
+
+func TestImportRecords(t *testing.T) {
+	var snapshot bytes.Buffer
+
+	exporter := core.NewExportWriter(&snapshot)
+	_ = exporter.Write("key", []byte("value"), time.Minute)
+	_ = exporter.Write("expired", []byte("value"), 0)
+	_ = exporter.Write("persistent", []byte{}, core.NoExpiration)
+	_ = exporter.Flush()
+
+	imported := map[string]core.Entry{}
+
+	err := core.ImportRecords(&snapshot, func(items map[string]core.Entry) error {
+		for key, item := range items {
+			imported[key] = item
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Impossible to import the records: %v", err)
+	}
+
+	if len(imported) != 2 || string(imported["key"].Value) != "value" || imported["key"].Duration != time.Minute {
+		t.Errorf("The unexpired records should be imported, %v provided", imported)
+	}
+
+	if imported["persistent"].Duration != core.ImportNoExpirationTTL {
+		t.Errorf("The record without expiry should be imported with ImportNoExpirationTTL, %v provided", imported["persistent"].Duration)
+	}
+
+	if err = core.ImportRecords(bytes.NewReader([]byte{3, 'k'}), func(map[string]core.Entry) error { return nil }); !errors.Is(err, core.ErrMalformedExport) {
+		t.Errorf("A truncated record should return ErrMalformedExport, %v provided", err)
+	}
+
+	var gzipped bytes.Buffer
+
+	writer := gzip.NewWriter(&gzipped)
+	exporter = core.NewExportWriter(writer)
+	_ = exporter.Write("gzipped", []byte("value"), time.Minute)
+	_ = exporter.Flush()
+	_ = writer.Close()
+
+	clear(imported)
+
+	err = core.ImportRecords(&gzipped, func(items map[string]core.Entry) error {
+		for key, item := range items {
+			imported[key] = item
+		}
+
+		return nil
+	})
+	if err != nil || len(imported) != 1 || string(imported["gzipped"].Value) != "value" {
+		t.Errorf("The gzipped records should be detected and imported, %v provided: %v", imported, err)
+	}
+
+	if err = core.ImportRecords(bytes.NewReader([]byte{0x1f, 0x8b, 0x08, 0x00}), func(map[string]core.Entry) error { return nil }); !errors.Is(err, core.ErrMalformedExport) {
+		t.Errorf("A truncated gzip stream should return ErrMalformedExport, %v provided", err)
+	}
+}
+
+var errTransient = errors.New("transient error")
+
+type flakyStorer struct {
+	core.Storer
+	failures int
+	err      error
+	calls    int
+}
+
+func (s *flakyStorer) GetContext(context.Context, string) ([]byte, error) {
+	if s.calls++; s.calls <= s.failures {
+		return nil, s.err
+	}
+
+	return []byte("value"), nil
+}
+
+func (s *flakyStorer) SetContext(context.Context, string, []byte, time.Duration) error {
+	if s.calls++; s.calls <= s.failures {
+		return s.err
+	}
+
+	return nil
+}
+
+func TestWithRetry(t *testing.T) {
+	opts := core.RetryOptions{MaxAttempts: 4, InitialBackoff: time.Millisecond}
+
+	storer := &flakyStorer{failures: 3, err: errTransient}
+	if res := core.WithRetry(storer, opts).Get("key"); string(res) != "value" || storer.calls != 4 {
+		t.Errorf("The Get should succeed on the 4th attempt, %s provided after %d calls", res, storer.calls)
+	}
+
+	storer = &flakyStorer{failures: 4, err: errTransient}
+	if err := core.WithRetry(storer, opts).Set("key", []byte("value"), time.Minute); !errors.Is(err, errTransient) || storer.calls != 4 {
+		t.Errorf("The Set should fail after 4 attempts, %v provided after %d calls", err, storer.calls)
+	}
+
+	for _, err := range []error{context.Canceled, core.ErrValueTooLarge, core.ErrKeyNotFound} {
+		storer = &flakyStorer{failures: 1, err: err}
+		if e := core.WithRetry(storer, opts).Set("key", []byte("value"), time.Minute); !errors.Is(e, err) || storer.calls != 1 {
+			t.Errorf("The error %v shouldn't be retried, %d calls provided", err, storer.calls)
+		}
+	}
+
+	opts.Retryable = func(err error) bool { return !errors.Is(err, errTransient) }
+	storer = &flakyStorer{failures: 1, err: errTransient}
+
+	if err := core.WithRetry(storer, opts).Set("key", []byte("value"), time.Minute); !errors.Is(err, errTransient) || storer.calls != 1 {
+		t.Errorf("The classifier should prevent the retry, %d calls provided", storer.calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	storer = &flakyStorer{failures: 1, err: errTransient}
+	if _, err := core.WithRetry(storer, core.RetryOptions{InitialBackoff: time.Hour}).GetContext(ctx, "key"); !errors.Is(err, errTransient) || storer.calls != 1 {
+		t.Errorf("A done context should stop the retries, %d calls provided", storer.calls)
+	}
+}
+
+type ttlStorer struct {
+	core.Storer
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newTTLStorer() *ttlStorer {
+	return &ttlStorer{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *ttlStorer) Get(key string) []byte { return s.values[key] }
+
+func (s *ttlStorer) GetTTL(key string) (time.Duration, bool) {
+	ttl, found := s.ttls[key]
+
+	return ttl, found
+}
+
+func (s *ttlStorer) Set(key string, value []byte, duration time.Duration) error {
+	s.values[key] = value
+	s.ttls[key] = duration
+
+	return nil
+}
+
+func (s *ttlStorer) Delete(key string) {
+	delete(s.values, key)
+	delete(s.ttls, key)
+}
+
+func TestTiered(t *testing.T) {
+	front, back := newTTLStorer(), newTTLStorer()
+	tiered := core.Tiered(front, back)
+
+	if err := tiered.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value: %v", err)
+	}
+
+	if string(front.values["key"]) != "value" || string(back.values["key"]) != "value" {
+		t.Error("The value should be written to both tiers")
+	}
+
+	if front.ttls["key"] != time.Minute || back.ttls["key"] != time.Minute {
+		t.Error("The duration should be given to both tiers")
+	}
+
+	_ = back.Set("back-only", []byte("back value"), 30*time.Second)
+
+	if res := tiered.Get("back-only"); string(res) != "back value" {
+		t.Errorf("The back hit should be returned, %s provided", res)
+	}
+
+	if string(front.values["back-only"]) != "back value" || front.ttls["back-only"] != 30*time.Second {
+		t.Errorf("The back hit should be promoted with its remaining ttl, %v provided", front.ttls["back-only"])
+	}
+
+	front.values["back-only"] = []byte("front value")
+
+	if res := tiered.Get("back-only"); string(res) != "front value" {
+		t.Errorf("The front should be read first, %s provided", res)
+	}
+
+	tiered.Delete("back-only")
+
+	if _, found := front.values["back-only"]; found {
+		t.Error("The delete should evict the front copy")
+	}
+
+	if _, found := back.values["back-only"]; found {
+		t.Error("The delete should remove the back entry")
+	}
+
+	if res := tiered.Get("back-only"); res != nil {
+		t.Errorf("A deleted key shouldn't be returned, %s provided", res)
+	}
+}
+
+// multiLevelTier serves the fresh response when set, otherwise marks the validator it's given as a stale match.
+type multiLevelTier struct {
+	core.Storer
+	fresh    *http.Response
+	received core.Revalidator
+}
+
+func (s *multiLevelTier) GetMultiLevelDebug(_ string, _ *http.Request, validator *core.Revalidator) (*http.Response, *http.Response, core.MultiLevelMatch) {
+	s.received = *validator
+
+	if s.fresh != nil {
+		validator.Matched = true
+
+		return s.fresh, nil, core.MultiLevelMatch{}
+	}
+
+	validator.NeedRevalidation, validator.ResponseETag = true, `"front"`
+
+	return nil, &http.Response{}, core.MultiLevelMatch{}
+}
+
+func TestTieredMultiLevel(t *testing.T) {
+	front, back := &multiLevelTier{}, &multiLevelTier{fresh: &http.Response{}}
+	validator := &core.Revalidator{IfNoneMatch: []string{`"back"`}}
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	if fresh, _ := core.Tiered(front, back).GetMultiLevel("key", req, validator); fresh != back.fresh {
+		t.Fatal("The back fresh response should be returned on a front miss")
+	}
+
+	if back.received.NeedRevalidation || back.received.ResponseETag != "" || !slices.Equal(back.received.IfNoneMatch, []string{`"back"`}) {
+		t.Errorf("The back tier should receive the caller validator, %+v provided", back.received)
+	}
+
+	if !validator.Matched || validator.NeedRevalidation || validator.ResponseETag != "" {
+		t.Errorf("The validator should hold the back tier state, %+v provided", *validator)
+	}
+}
+
+type slowStorer struct {
+	core.Storer
+	delay time.Duration
+}
+
+func (s *slowStorer) GetContext(context.Context, string) ([]byte, error) {
+	time.Sleep(s.delay)
+
+	return []byte("value"), nil
+}
+
+func (s *slowStorer) SetContext(ctx context.Context, _ string, _ []byte, _ time.Duration) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slowStorer) Delete(string) { time.Sleep(s.delay) }
+
+type ringMember struct {
+	*ttlStorer
+	uuid string
+}
+
+func newRingMember(uuid string) *ringMember {
+	return &ringMember{ttlStorer: newTTLStorer(), uuid: uuid}
+}
+
+func (s *ringMember) Uuid() string { return s.uuid }
+
+func (s *ringMember) Exists(key string) bool {
+	_, found := s.values[key]
+
+	return found
+}
+
+func (s *ringMember) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for key, value := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			keys[strings.TrimPrefix(key, prefix)] = string(value)
+		}
+	}
+
+	return keys
+}
+
+func (s *ringMember) SetMultiLevel(baseKey, variedKey string, value []byte, _ http.Header, _ string, duration time.Duration, _ string) error {
+	if err := s.Set(variedKey, value, duration); err != nil {
+		return err
+	}
+
+	return s.Set(core.MappingKeyPrefix+baseKey, []byte(variedKey), duration)
+}
+
+func (s *ringMember) Reset() error {
+	clear(s.values)
+	clear(s.ttls)
+
+	return nil
+}
+
+// ringOwners sets the keys through the ring and returns the uuid of the member storing each one.
+func ringOwners(t *testing.T, members []*ringMember, keys []string) map[string]string {
+	t.Helper()
+
+	storers := make([]core.Storer, 0, len(members))
+	for _, member := range members {
+		storers = append(storers, member)
+	}
+
+	ring := core.NewRing(storers, 0)
+	owners := map[string]string{}
+
+	for _, key := range keys {
+		if err := ring.Set(key, []byte(key), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key %s: %v", key, err)
+		}
+
+		if string(ring.Get(key)) != key {
+			t.Errorf("The key %s should be read back from its member", key)
+		}
+
+		for _, member := range members {
+			if _, found := member.values[key]; found {
+				if owners[key] != "" {
+					t.Errorf("The key %s should be stored by a single member", key)
+				}
+
+				owners[key] = member.uuid
+			}
+		}
+	}
+
+	return owners
+}
+
+func TestRing(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("RingKey_%d", i)
+	}
+
+	members := []*ringMember{newRingMember("a"), newRingMember("b"), newRingMember("c")}
+	owners := ringOwners(t, members, keys)
+
+	counts := map[string]int{}
+	for _, owner := range owners {
+		counts[owner]++
+	}
+
+	for _, member := range members {
+		if counts[member.uuid] < 200 {
+			t.Errorf("The keys should be spread across the members, %v provided", counts)
+		}
+	}
+
+	if again := ringOwners(t, []*ringMember{newRingMember("a"), newRingMember("b"), newRingMember("c")}, keys); !maps.Equal(owners, again) {
+		t.Error("A key should always be routed to the same member")
+	}
+
+	moved := 0
+	added := ringOwners(t, []*ringMember{newRingMember("a"), newRingMember("b"), newRingMember("c"), newRingMember("d")}, keys)
+
+	for key, owner := range added {
+		if owner == owners[key] {
+			continue
+		}
+
+		moved++
+
+		if owner != "d" {
+			t.Errorf("Adding a member should only move keys to it, %s moved from %s to %s", key, owners[key], owner)
+		}
+	}
+
+	if moved == 0 || moved > len(keys)/2 {
+		t.Errorf("Adding a member should move a fraction of the keys, %d moved", moved)
+	}
+
+	removed := ringOwners(t, []*ringMember{newRingMember("a"), newRingMember("c")}, keys)
+	for key, owner := range removed {
+		if owners[key] != "b" && owner != owners[key] {
+			t.Errorf("Removing a member should only move its keys, %s moved from %s to %s", key, owners[key], owner)
+		}
+	}
+
+	storers := []core.Storer{members[0], members[1], members[2]}
+	ring := core.NewRing(storers, 0)
+
+	if mapped := ring.MapKeys("RingKey_"); len(mapped) != len(keys) || mapped["42"] != "RingKey_42" {
+		t.Errorf("MapKeys should merge the keys of every member, %d provided", len(mapped))
+	}
+
+	ring.Delete("RingKey_42")
+
+	if ring.Exists("RingKey_42") || len(ring.MapKeys("RingKey_")) != len(keys)-1 {
+		t.Error("The deleted key should be removed from its member")
+	}
+
+	if err := ring.Reset(); err != nil || len(ring.MapKeys("")) != 0 {
+		t.Errorf("Reset should empty every member, %v provided", err)
+	}
+}
+
+func TestRingMultiLevel(t *testing.T) {
+	members := []*ringMember{newRingMember("a"), newRingMember("b"), newRingMember("c")}
+	ring := core.NewRing([]core.Storer{members[0], members[1], members[2]}, 0)
+
+	for i := range 50 {
+		baseKey := fmt.Sprintf("GET-http-example.com-/%d", i)
+		variedKey := baseKey + core.VarySeparator + "Accept-Encoding:gzip"
+		mappingKey := core.MappingKeyPrefix + baseKey
+
+		if err := ring.SetMultiLevel(baseKey, variedKey, []byte("value"), http.Header{}, "", time.Minute, baseKey); err != nil {
+			t.Fatalf("Impossible to set the multi-level entry %s: %v", baseKey, err)
+		}
+
+		if string(ring.Get(variedKey)) != "value" || string(ring.Get(mappingKey)) != variedKey {
+			t.Errorf("The varied key and the mapping of %s should be read from their member", baseKey)
+		}
+
+		ring.Delete(variedKey)
+		ring.Delete(mappingKey)
+
+		for _, member := range members {
+			if member.Exists(variedKey) || member.Exists(mappingKey) {
+				t.Errorf("The varied key and the mapping of %s should be deleted from %s", baseKey, member.uuid)
+			}
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	storer := &slowStorer{delay: time.Second}
+	if core.WithTimeout(storer, 0) != core.Storer(storer) {
+		t.Error("A zero timeout should return the storer as is")
+	}
+
+	timeout := core.WithTimeout(storer, 10*time.Millisecond)
+	start := time.Now()
+
+	if _, err := timeout.GetWithError("key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("The slow Get should exceed the deadline, %v provided", err)
+	}
+
+	if err := timeout.Set("key", []byte("value"), time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("The slow Set should exceed the deadline, %v provided", err)
+	}
+
+	timeout.Delete("key")
+
+	if elapsed := time.Since(start); elapsed >= storer.delay {
+		t.Errorf("The timeout should return before the backend, %v elapsed", elapsed)
+	}
+
+	timeout = core.WithTimeout(&slowStorer{}, time.Second)
+	if res, err := timeout.GetWithError("key"); err != nil || string(res) != "value" {
+		t.Errorf("The fast Get should succeed, %s and %v provided", res, err)
+	}
+
+	if err := timeout.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Errorf("The fast Set should succeed, %v provided", err)
+	}
+}
+
+type gatedStorer struct {
+	core.Storer
+	gate     chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (s *gatedStorer) wait() {
+	current := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+
+	for peak := s.peak.Load(); current > peak && !s.peak.CompareAndSwap(peak, current); peak = s.peak.Load() {
+	}
+
+	<-s.gate
+}
+
+func (s *gatedStorer) GetContext(context.Context, string) ([]byte, error) {
+	s.wait()
+
+	return []byte("value"), nil
+}
+
+func (s *gatedStorer) SetContext(context.Context, string, []byte, time.Duration) error {
+	s.wait()
+
+	return nil
+}
+
+func (s *gatedStorer) Delete(string) { s.wait() }
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	storer := &gatedStorer{gate: make(chan struct{})}
+	if core.WithConcurrencyLimit(storer, 0) != core.Storer(storer) {
+		t.Error("A zero limit should return the storer as is")
+	}
+
+	limited := core.WithConcurrencyLimit(storer, 3)
+	wg := sync.WaitGroup{}
+
+	for i := range 12 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			switch i % 3 {
+			case 0:
+				if res := limited.Get("key"); string(res) != "value" {
+					t.Errorf("The limited Get should succeed, %s provided", res)
+				}
+			case 1:
+				if err := limited.Set("key", []byte("value"), time.Minute); err != nil {
+					t.Errorf("The limited Set should succeed, %v provided", err)
+				}
+			default:
+				limited.Delete("key")
+			}
+		}()
+	}
+
+	for storer.inFlight.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := limited.GetContext(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("The saturated Get should wait until its deadline, %v provided", err)
+	}
+
+	if peak := storer.peak.Load(); peak != 3 {
+		t.Errorf("The in-flight operations shouldn't exceed the limit of 3, %d provided", peak)
+	}
+
+	failFast := core.WithConcurrencyLimit(storer, 1, core.FailWhenSaturated())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = failFast.Set("key", []byte("value"), time.Minute)
+	}()
+
+	for storer.inFlight.Load() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := failFast.Set("key", []byte("value"), time.Minute); !errors.Is(err, core.ErrSaturated) {
+		t.Errorf("The saturated Set should fail fast, %v provided", err)
+	}
+
+	close(storer.gate)
+	wg.Wait()
+	<-done
+
+	if peak := storer.peak.Load(); peak != 4 {
+		t.Errorf("The limited storers should never exceed 4 in-flight operations together, %d provided", peak)
+	}
+}
+
+func TestCompareAndSwapWrappers(t *testing.T) {
+	wrappers := map[string]func(core.Storer) core.Storer{
+		"encrypted": func(s core.Storer) core.Storer { return core.Encrypted(s, bytes.Repeat([]byte("k"), 32)) },
+		"checksummed": func(s core.Storer) core.Storer {
+			checksummed, _ := core.Checksummed(s, core.ChecksumCRC32C, nil)
+
+			return checksummed
+		},
+	}
+
+	for name, wrap := range wrappers {
+		storer := wrap(&memoryStorer{values: map[string][]byte{}})
+
+		if ok, err := storer.CompareAndSwap("key", nil, []byte("first"), time.Minute); !ok || err != nil {
+			t.Errorf("The %s storer should create the missing key with a nil old value, %v provided", name, err)
+		}
+
+		if ok, _ := storer.CompareAndSwap("key", nil, []byte("other"), time.Minute); ok {
+			t.Errorf("The %s storer shouldn't create the existing key", name)
+		}
+
+		if ok, _ := storer.CompareAndSwap("key", []byte("other"), []byte("second"), time.Minute); ok {
+			t.Errorf("The %s storer shouldn't swap a different value", name)
+		}
+
+		if ok, err := storer.CompareAndSwap("key", []byte("first"), []byte("second"), time.Minute); !ok || err != nil {
+			t.Errorf("The %s storer should swap the matching value, %v provided", name, err)
+		}
+
+		if res := storer.Get("key"); string(res) != "second" {
+			t.Errorf("The %s storer should return the swapped value, %s provided", name, res)
+		}
+
+		if ok, _ := storer.CompareAndSwap("missing", []byte("first"), []byte("second"), time.Minute); ok {
+			t.Errorf("The %s storer shouldn't swap a missing key with a non-nil old value", name)
+		}
+	}
+}
+
+func TestChecksummed(t *testing.T) {
+	storer := &memoryStorer{values: map[string][]byte{}}
+
+	checksummed, err := core.Checksummed(storer, core.ChecksumCRC32C, nil)
+	if err != nil {
+		t.Fatalf("Impossible to checksum the values: %v", err)
+	}
+
+	if err := checksummed.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the checksummed value: %v", err)
+	}
+
+	if len(storer.values["key"]) != len("value")+4 {
+		t.Errorf("The stored value should hold a 4 bytes trailer, %d bytes provided", len(storer.values["key"]))
+	}
+
+	if res, err := checksummed.GetWithError("key"); err != nil || string(res) != "value" {
+		t.Errorf("The trailer should be stripped, %s and %v provided", res, err)
+	}
+
+	storer.values["key"][1] ^= 0xff
+
+	if _, err := checksummed.GetWithError("key"); !errors.Is(err, core.ErrChecksumMismatch) {
+		t.Errorf("A corrupted byte should return ErrChecksumMismatch, %v provided", err)
+	}
+
+	if res := checksummed.Get("key"); res != nil {
+		t.Errorf("A corrupted value shouldn't be returned, %s provided", res)
+	}
+
+	xxhashed, _ := core.Checksummed(storer, core.ChecksumXXHash, nil)
+	_ = xxhashed.Set("key", []byte("value"), time.Minute)
+	storer.values["key"] = storer.values["key"][:6]
+
+	if _, err := xxhashed.GetWithError("key"); !errors.Is(err, core.ErrChecksumMismatch) {
+		t.Errorf("A truncated value should return ErrChecksumMismatch, %v provided", err)
+	}
+
+	if none, _ := core.Checksummed(storer, core.ChecksumNone, nil); none != core.Storer(storer) {
+		t.Error("The none checksum should return the storer as is")
+	}
+
+	if _, err := core.Checksummed(storer, "md5", nil); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("An unknown checksum should return ErrInvalidConfig, %v provided", err)
+	}
+
+	if _, err := core.Instrument(storer, core.CacheProvider{Checksum: "md5"}, nil); !errors.Is(err, core.ErrInvalidConfig) || !storer.closed {
+		t.Errorf("Instrument should close the storer and return the unknown checksum error, %v provided", err)
+	}
+}
+
+type benchStorer struct {
+	memoryStorer
+	multiLevel int
+}
+
+func (s *benchStorer) Get(key string) []byte { return s.values[key] }
+
+func (s *benchStorer) SetMultiLevel(_, variedKey string, value []byte, _ http.Header, _ string, _ time.Duration, _ string) error {
+	s.multiLevel++
+	s.values[variedKey] = value
+
+	return nil
+}
+
+func (s *benchStorer) DeleteMany(pattern string) {
+	for key := range s.values {
+		if strings.HasPrefix(key, core.KeyPrefix(pattern)) {
+			delete(s.values, key)
+		}
+	}
+}
+
+func TestBenchmarkStorer(t *testing.T) {
+	benchtime := flag.Lookup("test.benchtime").Value.String()
+	_ = flag.Set("test.benchtime", "10x")
+
+	defer func() { _ = flag.Set("test.benchtime", benchtime) }()
+
+	storer := &benchStorer{memoryStorer: memoryStorer{values: map[string][]byte{}}}
+	result := testing.Benchmark(func(b *testing.B) {
+		core.BenchmarkStorerWith(b, storer, core.BenchmarkOptions{
+			ValueSizes:       []int{16, 1024},
+			KeyCardinalities: []int{1, 10},
+		})
+	})
+
+	if result.N == 0 {
+		t.Fatal("The benchmark harness shouldn't fail against a working storer")
+	}
+
+	if storer.multiLevel == 0 {
+		t.Error("The SetMultiLevel benchmark should have been run")
+	}
+
+	if len(storer.values) != 0 {
+		t.Errorf("The benchmark keys should be deleted once done, %d keys remaining", len(storer.values))
+	}
+}
+
+func TestSetWithEntryMeta(t *testing.T) {
+	storer := newTTLStorer()
+	meta := map[string]string{"origin": "backend-1", "region": "eu-west"}
+
+	if err := core.SetWithEntryMeta(storer, "key", []byte("value"), meta, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value with its metadata: %v", err)
+	}
+
+	if storer.ttls[core.EntryMetaKeyPrefix+"key"] != time.Minute {
+		t.Errorf("The metadata record should share the value duration, %v provided", storer.ttls[core.EntryMetaKeyPrefix+"key"])
+	}
+
+	if stored, err := core.GetEntryMeta(storer, "key"); err != nil || !maps.Equal(stored, meta) {
+		t.Errorf("The metadata should round-trip, %v and %v provided", stored, err)
+	}
+
+	core.DeleteEntryMeta(storer, "key")
+
+	if _, err := core.GetEntryMeta(storer, "key"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("The deleted metadata should return ErrKeyNotFound, %v provided", err)
+	}
+
+	if string(storer.values["key"]) != "value" {
+		t.Error("DeleteEntryMeta shouldn't delete the value")
+	}
+}
+
+type quietLogger struct {
+	core.Logger
+}
+
+func (quietLogger) Debugf(string, ...interface{}) {}
+
+func (quietLogger) Errorf(string, ...interface{}) {}
+
+// electStored runs the mapping election of a single fresh entry holding the stored bytes.
+func electStored(t testing.TB, stored []byte) (*http.Response, error) {
+	t.Helper()
+
+	return electStoredWith(t, stored, nil)
+}
+
+// electStoredWith works like electStored with the given request headers.
+func electStoredWith(t testing.TB, stored []byte, header http.Header) (*http.Response, error) {
+	t.Helper()
+
+	now := time.Now()
+
+	mapping, err := core.MappingUpdater("key", nil, quietLogger{}, now, now.Add(time.Minute), now.Add(2*time.Minute), nil, "", "key")
+	if err != nil {
+		t.Fatalf("Impossible to build the mapping: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+	maps.Copy(req.Header, header)
+
+	fresh, _, err := core.MappingElection(&fakeStorer{values: map[string][]byte{"key": stored}}, mapping, req, &core.Revalidator{}, quietLogger{})
+
+	return fresh, err
+}
+
+func compressed(t testing.TB, codec, response string) []byte {
+	t.Helper()
+
+	value, err := core.Compress(codec, []byte(response))
+	if err != nil {
+		t.Fatalf("Impossible to compress the response: %v", err)
+	}
+
+	return value
+}
+
+func TestReadResponse(t *testing.T) {
+	body := strings.Repeat("b", 64*1024)
+
+	fresh, err := electStored(t, compressed(t, core.CompressionLZ4, fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body)))
+	if err != nil || fresh == nil {
+		t.Fatalf("A well-formed response should be elected, %v provided", err)
+	}
+
+	if read, _ := io.ReadAll(fresh.Body); string(read) != body {
+		t.Errorf("The whole body should be readable once elected, %d bytes provided", len(read))
+	}
+
+	for name, stored := range map[string][]byte{
+		"empty":               {},
+		"unknown compression": []byte("\x42garbage"),
+		"truncated lz4":       compressed(t, core.CompressionLZ4, "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhello")[:10],
+		"empty response":      compressed(t, core.CompressionNone, ""),
+		"garbage":             compressed(t, core.CompressionNone, "\x00\xff not a response"),
+		"invalid status":      compressed(t, core.CompressionNone, "HTTP/1.1 abc OK\r\n\r\n"),
+		"zero status":         compressed(t, core.CompressionNone, "HTTP/1.1 000 OK\r\n\r\n"),
+		"truncated headers":   compressed(t, core.CompressionNone, "HTTP/1.1 200 OK\r\nContent-Le"),
+		"truncated body":      compressed(t, core.CompressionNone, "HTTP/1.1 200 OK\r\nContent-Length: 50\r\n\r\nshort"),
+		"truncated chunk":     compressed(t, core.CompressionNone, "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n10\r\nshort"),
+	} {
+		if fresh, err = electStored(t, stored); !errors.Is(err, core.ErrCorruptEntry) {
+			t.Errorf("The %s entry should return ErrCorruptEntry, %v provided", name, err)
+		}
+
+		if fresh != nil {
+			t.Errorf("The %s entry shouldn't return a response", name)
+		}
+	}
+}
+
+func FuzzReadResponse(f *testing.F) {
+	response := "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nContent-Length: 5\r\n\r\nhello"
+
+	f.Add(compressed(f, core.CompressionNone, response))
+	f.Add(compressed(f, core.CompressionLZ4, response))
+	f.Add(compressed(f, core.CompressionZstd, response))
+	f.Add(compressed(f, core.CompressionNone, "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n"))
+	f.Add(compressed(f, core.CompressionNone, response[:40]))
+	f.Add([]byte("garbage"))
+
+	f.Fuzz(func(t *testing.T, stored []byte) {
+		fresh, err := electStored(t, stored)
+		if err != nil {
+			if !errors.Is(err, core.ErrCorruptEntry) {
+				t.Errorf("A malformed entry should return ErrCorruptEntry, %v provided", err)
+			}
+
+			return
+		}
+
+		if _, err = io.ReadAll(fresh.Body); err != nil {
+			t.Errorf("The body of an elected response should be readable, %v provided", err)
+		}
+	})
+}
+
+// htmlPage returns a page sharing its boilerplate with the other pages and the compression dictionary.
+func htmlPage(title string) string {
+	return `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">` +
+		`<link rel="stylesheet" href="/assets/main.css"><script defer src="/assets/main.js"></script><title>` + title + `</title></head>` +
+		`<body><header class="site-header"><nav class="navigation"><a href="/">Home</a><a href="/products">Products</a>` +
+		`<a href="/about">About us</a><a href="/contact">Contact</a></nav></header><main class="content"><h1>` + title + `</h1>` +
+		`<p>Welcome to the ` + title + ` page of our website.</p></main><footer class="site-footer"><p>Copyright 2024, all rights reserved.</p>` +
+		`<ul class="links"><li><a href="/privacy">Privacy policy</a></li><li><a href="/terms">Terms of service</a></li></ul></footer></body></html>`
+}
+
+func TestCompressionDictionary(t *testing.T) {
+	dictionary := []byte(htmlPage("Dictionary"))
+	logger := zap.NewNop().Sugar()
+	plain := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd}, logger)
+	withDictionary := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd, CompressionDictionary: dictionary}, logger)
+
+	var plainSize, dictionarySize int
+
+	for i := range 20 {
+		page := []byte(htmlPage(fmt.Sprintf("Page %d", i)))
+
+		compressedPlain, err := core.Compress(plain, page)
+		if err != nil {
+			t.Fatalf("Impossible to compress without dictionary: %v", err)
+		}
+
+		compressedDictionary, err := core.Compress(withDictionary, page)
+		if err != nil {
+			t.Fatalf("Impossible to compress with the dictionary: %v", err)
+		}
+
+		plainSize += len(compressedPlain)
+		dictionarySize += len(compressedDictionary)
+
+		if decompressed, err := core.Decompress(compressedDictionary); err != nil || !bytes.Equal(decompressed, page) {
+			t.Fatalf("The page compressed with the dictionary should round-trip, %v provided", err)
+		}
+	}
+
+	if dictionarySize*2 > plainSize {
+		t.Errorf("The dictionary should at least halve the compressed size, %d bytes with it and %d without provided", dictionarySize, plainSize)
+	}
+
+	path := filepath.Join(t.TempDir(), "dictionary")
+	_ = os.WriteFile(path, dictionary, 0o600)
+
+	if fromPath := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd, CompressionDictionaryPath: path}, logger); fromPath != withDictionary {
+		t.Errorf("The dictionary read from its path should have the same identity, %s and %s provided", fromPath, withDictionary)
+	}
+
+	page := htmlPage("Elected")
+	response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(page), page)
+
+	if fresh, err := electStored(t, compressed(t, withDictionary, response)); err != nil || fresh == nil {
+		t.Errorf("The response compressed with the dictionary should be elected, %v provided", err)
+	} else if body, _ := io.ReadAll(fresh.Body); string(body) != page {
+		t.Errorf("The elected response should have the whole body, %q provided", body)
+	}
+
+	auto := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionAuto, CompressionDictionary: dictionary}, logger)
+	if value, err := core.Compress(auto, []byte(page)); err != nil {
+		t.Errorf("Impossible to compress with the auto codec and the dictionary: %v", err)
+	} else if decompressed, err := core.Decompress(value); err != nil || string(decompressed) != page {
+		t.Errorf("The page compressed by the auto codec should round-trip, %v provided", err)
+	}
+
+	// A value compressed with a dictionary this process doesn't know is reported, not decoded with another one.
+	other := core.ConfiguredCompression(core.CacheProvider{Compression: core.CompressionZstd, CompressionDictionary: []byte(htmlPage("Other"))}, logger)
+
+	value, _ := core.Compress(other, []byte(page))
+	value[1] ^= 0xff
+
+	if _, err := core.Decompress(value); !errors.Is(err, core.ErrCompressionDictionary) {
+		t.Errorf("An unknown dictionary should return ErrCompressionDictionary, %v provided", err)
+	}
+
+	if _, err := core.DecompressStream(bytes.NewReader(value)); !errors.Is(err, core.ErrCompressionDictionary) {
+		t.Errorf("An unknown dictionary should return ErrCompressionDictionary when streamed, %v provided", err)
+	}
+
+	if codec := core.ConfiguredCompression(core.CacheProvider{CompressionDictionary: dictionary}, logger); codec != "" {
+		t.Errorf("The dictionary should be ignored by the lz4 codec, %s provided", codec)
+	}
+}
+
+func TestRangeResponse(t *testing.T) {
+	body := make([]byte, 1000)
+	for i := range body {
+		body[i] = byte(i % 251)
+	}
+
+	stored := compressed(t, core.CompressionLZ4, fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nEtag: \"v1\"\r\nContent-Length: %d\r\n\r\n%s", len(body), body))
+
+	for name, tc := range map[string]struct {
+		header       http.Header
+		status       int
+		contentRange string
+		body         []byte
+	}{
+		"no range":         {nil, http.StatusOK, "", body},
+		"closed range":     {http.Header{"Range": {"bytes=100-199"}}, http.StatusPartialContent, "bytes 100-199/1000", body[100:200]},
+		"open range":       {http.Header{"Range": {"bytes=900-"}}, http.StatusPartialContent, "bytes 900-999/1000", body[900:]},
+		"suffix range":     {http.Header{"Range": {"bytes=-10"}}, http.StatusPartialContent, "bytes 990-999/1000", body[990:]},
+		"clamped range":    {http.Header{"Range": {"bytes=990-5000"}}, http.StatusPartialContent, "bytes 990-999/1000", body[990:]},
+		"unsatisfiable":    {http.Header{"Range": {"bytes=1000-1100"}}, http.StatusRequestedRangeNotSatisfiable, "bytes */1000", []byte{}},
+		"other unit":       {http.Header{"Range": {"items=0-1"}}, http.StatusOK, "", body},
+		"malformed":        {http.Header{"Range": {"bytes=200-100"}}, http.StatusOK, "", body},
+		"if-range match":   {http.Header{"Range": {"bytes=0-9"}, "If-Range": {`"v1"`}}, http.StatusPartialContent, "bytes 0-9/1000", body[:10]},
+		"if-range changed": {http.Header{"Range": {"bytes=0-9"}, "If-Range": {`"v2"`}}, http.StatusOK, "", body},
+	} {
+		fresh, err := electStoredWith(t, stored, tc.header)
+		if err != nil || fresh == nil {
+			t.Fatalf("The %s response should be elected, %v provided", name, err)
+		}
+
+		read, _ := io.ReadAll(fresh.Body)
+
+		if fresh.StatusCode != tc.status || fresh.Header.Get("Content-Range") != tc.contentRange || !bytes.Equal(read, tc.body) {
+			t.Errorf("The %s response should be a %d with the Content-Range %q and %d bytes, a %d with %q and %d bytes provided",
+				name, tc.status, tc.contentRange, len(tc.body), fresh.StatusCode, fresh.Header.Get("Content-Range"), len(read))
+		}
+
+		if fresh.ContentLength != int64(len(read)) {
+			t.Errorf("The %s response should have a %d Content-Length, %d provided", name, len(read), fresh.ContentLength)
+		}
+	}
+
+	fresh, _ := electStoredWith(t, stored, http.Header{"Range": {"bytes=0-1,10-11"}})
+	if fresh.StatusCode != http.StatusPartialContent || !strings.HasPrefix(fresh.Header.Get("Content-Type"), "multipart/byteranges; boundary=") {
+		t.Errorf("Several ranges should return a multipart/byteranges 206, a %d of %s provided", fresh.StatusCode, fresh.Header.Get("Content-Type"))
+	}
+
+	if read, _ := io.ReadAll(fresh.Body); !bytes.Contains(read, []byte("Content-Range: bytes 0-1/1000")) || !bytes.Contains(read, []byte("Content-Range: bytes 10-11/1000")) {
+		t.Errorf("Each range should be a part with its Content-Range, %q provided", read)
+	}
+}
+
+func TestMappingUpdaterVariantLimit(t *testing.T) {
+	mapper := core.ConfiguredMapper(core.CacheProvider{MaxVariants: 1})
+	now := time.Now()
+
+	mapping, err := core.MappingUpdaterWith(mapper, "first", nil, quietLogger{}, now, now.Add(time.Second), now.Add(time.Second), nil, "", "first")
+	if err != nil {
+		t.Fatalf("Impossible to map the first variant: %v", err)
+	}
+
+	if _, err = core.MappingUpdaterWith(mapper, "second", mapping, quietLogger{}, now, now.Add(time.Second), now.Add(time.Second), nil, "", "second"); !errors.Is(err, core.ErrVariantLimit) {
+		t.Errorf("A second variant should return ErrVariantLimit, %v provided", err)
+	}
+
+	later := now.Add(time.Minute)
+
+	mapping, err = core.MappingUpdaterWith(mapper, "second", mapping, quietLogger{}, later, later.Add(time.Second), later.Add(time.Second), nil, "", "second")
+	if err != nil {
+		t.Fatalf("The expired variant shouldn't count against the limit: %v", err)
+	}
+
+	decoded, _ := core.DecodeMapping(mapping)
+	if _, found := decoded.GetMapping()["first"]; found || len(decoded.GetMapping()) != 1 {
+		t.Errorf("The expired variant should be dropped, %v provided", decoded.GetMapping())
+	}
+}
+
+func TestMappingElectionClock(t *testing.T) {
+	clock := core.NewManualClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	mapper := core.ConfiguredMapper(core.CacheProvider{Clock: clock})
+	now := clock.Now()
+
+	mapping, err := core.MappingUpdaterWith(mapper, "key", nil, quietLogger{}, now, now.Add(time.Minute), now.Add(2*time.Minute), nil, "", "key")
+	if err != nil {
+		t.Fatalf("Impossible to build the mapping: %v", err)
+	}
+
+	storer := &fakeStorer{values: map[string][]byte{"key": compressed(t, "", "HTTP/1.1 200 OK\r\n\r\nvalue")}}
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	elect := func() (fresh, stale *http.Response, match core.MultiLevelMatch) {
+		fresh, stale, match, err = core.MappingElectionWith(mapper, storer, mapping, req, &core.Revalidator{}, quietLogger{})
+		if err != nil {
+			t.Fatalf("Impossible to run the election: %v", err)
+		}
+
+		return fresh, stale, match
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if fresh, _, match := elect(); fresh == nil || match.TTL != 30*time.Second || match.Age != 30*time.Second {
+		t.Errorf("The entry should be fresh for 30s more after 30s, %+v provided", match)
+	}
+
+	clock.Advance(time.Minute)
+
+	if fresh, stale, match := elect(); fresh != nil || stale == nil || match.TTL != 30*time.Second {
+		t.Errorf("The entry should be stale for 30s more after 90s, %+v provided", match)
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if fresh, stale, _ := elect(); fresh != nil || stale != nil {
+		t.Error("The entry should be neither fresh nor stale once the stale time is reached")
+	}
+
+	// An instance whose clock runs an hour late still sees the entry as fresh.
+	skewed := core.ConfiguredMapper(core.CacheProvider{Clock: core.NewManualClock(now.Add(-time.Hour))})
+	if fresh, _, _, _ := core.MappingElectionWith(skewed, storer, mapping, req, &core.Revalidator{}, quietLogger{}); fresh == nil {
+		t.Error("The entry should be fresh for the late clock")
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"":                                      0,
+		"max-age=60":                            0,
+		"max-age=60, stale-while-revalidate=30": 30 * time.Second,
+		`Stale-While-Revalidate="15", public`:   15 * time.Second,
+		"stale-while-revalidate=-5":             0,
+		"stale-while-revalidate=soon":           0,
+		"stale-while-revalidate":                0,
+		"stale-if-error=60, stale-while-revalidate=1": time.Second,
+	} {
+		if window := core.StaleWhileRevalidate(http.Header{"Cache-Control": []string{value}}); window != expected {
+			t.Errorf("The window of %q should be %v, %v provided", value, expected, window)
+		}
+	}
+}
+
+func TestMappingElectionStaleWhileRevalidate(t *testing.T) {
+	clock := core.NewManualClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	mapper := core.ConfiguredMapper(core.CacheProvider{Clock: clock})
+	now := clock.Now()
+
+	mapping, err := core.MappingUpdaterWith(mapper, "key", nil, quietLogger{}, now, now.Add(time.Minute), now.Add(2*time.Minute), nil, "", "key")
+	if err != nil {
+		t.Fatalf("Impossible to build the mapping: %v", err)
+	}
+
+	response := "HTTP/1.1 200 OK\r\nCache-Control: max-age=60, stale-while-revalidate=30\r\n\r\nvalue"
+	storer := &fakeStorer{values: map[string][]byte{"key": compressed(t, "", response)}}
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/", nil)
+
+	elect := func() (fresh, stale *http.Response, validator *core.Revalidator) {
+		validator = &core.Revalidator{}
+
+		fresh, stale, _, err = core.MappingElectionWith(mapper, storer, mapping, req, validator, quietLogger{})
+		if err != nil {
+			t.Fatalf("Impossible to run the election: %v", err)
+		}
+
+		return fresh, stale, validator
+	}
+
+	if fresh, _, validator := elect(); fresh == nil || validator.Revalidate || validator.StaleWhileRevalidate != 30*time.Second {
+		t.Errorf("The fresh entry shouldn't need a revalidation, %+v provided", validator)
+	}
+
+	clock.Advance(70 * time.Second)
+
+	if fresh, stale, validator := elect(); fresh != nil || stale == nil || !validator.Revalidate || validator.StaleWhileRevalidate != 30*time.Second {
+		t.Errorf("The stale entry should be usable while revalidating 10s after its fresh time, %+v provided", validator)
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if fresh, stale, validator := elect(); fresh != nil || stale == nil || validator.Revalidate {
+		t.Errorf("The stale entry should need a synchronous revalidation once the window is over, %+v provided", validator)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := core.ValidateConfig(core.CacheProvider{}); err != nil {
+		t.Errorf("The default configuration should be valid, %v provided", err)
+	}
+
+	err := core.ValidateConfig(core.CacheProvider{
+		Compression:            "gzip",
+		CompressionLevel:       12,
+		CompressionBlockSize:   1000,
+		KeyHashing:             "md5",
+		Checksum:               "md5",
+		MaxValueSize:           -1,
+		Shards:                 -2,
+		OperationTimeout:       -time.Second,
+		ValueLogGCDiscardRatio: 1.5,
+		NumCompactors:          1,
+		DirPermissions:         0o1777,
+		MaxKeysPolicy:          "lru",
+
+		CompressionDictionaryPath: "/nonexistent/dictionary",
+	})
+	if !errors.Is(err, core.ErrInvalidConfig) || !errors.Is(err, core.ErrUnknownCompression) {
+		t.Fatalf("The configuration should be reported as invalid, %v provided", err)
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 14 {
+		t.Errorf("Every problem should be reported, %v provided", err)
+	}
+
+	for _, expected := range []string{
+		`unknown compression codec "gzip"`,
+		"the compression level 12 must be between 0 and 9",
+		"the compression block size 1000 must be one of",
+		`unknown key hashing "md5"`,
+		`unknown checksum "md5"`,
+		"the max_value_size -1 must not be negative",
+		"the shards -2 must not be negative",
+		"the operation_timeout -1s must not be negative",
+		"the value_log_gc_discard_ratio 1.5 must be between 0 and 1",
+		"the num_compactors 1 must be at least 2",
+		"the dir_permissions 01777 must only hold permission bits",
+		`unknown max_keys_policy "lru"`,
+		`the compression dictionary requires the zstd or auto compression, "gzip" provided`,
+		"the compression_dictionary_path /nonexistent/dictionary can't be read",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("The error should report %q, %v provided", expected, err)
+		}
+	}
+
+	custom := errors.New("custom check")
+	if err = core.ValidateConfig(core.CacheProvider{}, func(core.CacheProvider) error { return custom }); !errors.Is(err, custom) {
+		t.Errorf("The storage checks should be run, %v provided", err)
+	}
+}
+
+func TestCheckWritablePath(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/file"
+
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("Impossible to create the file: %v", err)
+	}
+
+	for _, path := range []string{dir, file, dir + "/missing/nested", ":memory:", ""} {
+		if err := core.CheckWritablePath(core.CacheProvider{Path: path}); err != nil {
+			t.Errorf("The path %s should be valid, %v provided", path, err)
+		}
+	}
+
+	err := core.CheckWritablePath(core.CacheProvider{Path: file + "/nested"})
+	if !errors.Is(err, core.ErrInvalidConfig) || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("The path below a file should be rejected, %v provided", err)
+	}
+
+	if err = core.CheckWritablePath(core.CacheProvider{Path: file + "/nested", InMemory: true}); err != nil {
+		t.Errorf("The path of an in-memory storage should be ignored, %v provided", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, valid := range []string{"", "127.0.0.1:6379", "redis://localhost:6379/0,127.0.0.1:6380", "http://[::1]:8000", "unix:///tmp/redis.sock"} {
+		if err := core.CheckURL(core.CacheProvider{URL: valid}); err != nil {
+			t.Errorf("The url %q should be valid, %v provided", valid, err)
+		}
+	}
+
+	err := core.CheckURL(core.CacheProvider{URL: "localhost,redis://,http://[::1"})
+	if !errors.Is(err, core.ErrInvalidConfig) {
+		t.Fatalf("The invalid addresses should be reported, %v provided", err)
+	}
+
+	for _, expected := range []string{`the address "localhost" must be a host:port pair`, `the url "redis://" has no host`, `the url "http://[::1" can't be parsed`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("The error should report %q, %v provided", expected, err)
+		}
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	for _, tc := range []struct {
+		address, defaultPort, network, expected string
+	}{
+		{"127.0.0.1:6379", "", "tcp", "127.0.0.1:6379"},
+		{" localhost:6379 ", "", "tcp", "localhost:6379"},
+		{"tcp://redis.local:6380", "", "tcp", "redis.local:6380"},
+		{"[::1]:6379", "", "tcp", "[::1]:6379"},
+		{"[fe80::1%eth0]:6379", "", "tcp", "[fe80::1%eth0]:6379"},
+		{"unix:///var/run/redis.sock", "", "unix", "/var/run/redis.sock"},
+		{"/var/run/redis.sock", "", "unix", "/var/run/redis.sock"},
+		{"localhost", "6379", "tcp", "localhost:6379"},
+		{"localhost:", "6379", "tcp", "localhost:6379"},
+		{"[::1]", "6379", "tcp", "[::1]:6379"},
+		{"::1", "6379", "tcp", "[::1]:6379"},
+		{"[::1]:6380", "6379", "tcp", "[::1]:6380"},
+	} {
+		network, address, err := core.ParseAddressWithDefaultPort(tc.address, tc.defaultPort)
+		if err != nil || network != tc.network || address != tc.expected {
+			t.Errorf("The address %q should be parsed as %s %s, %s %s and %v provided", tc.address, tc.network, tc.expected, network, address, err)
+		}
+	}
+
+	for _, invalid := range []string{"", "localhost", "::1", "[::1]", "localhost:", ":6379", "localhost:redis", "localhost:0", "localhost:65536", "[::1:6379", "[localhost]:6379", "[::g]:6379", "unix://", "redis://localhost:6379"} {
+		if _, _, err := core.ParseAddress(invalid); !errors.Is(err, core.ErrInvalidConfig) {
+			t.Errorf("The address %q should be invalid, %v provided", invalid, err)
+		}
+	}
+
+	if _, _, err := core.ParseAddressWithDefaultPort("[::1", "6379"); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("The unclosed IPv6 literal should be invalid, %v provided", err)
+	}
+
+	addresses, network, err := core.ParseAddresses("localhost, [::1]:6380", "6379")
+	if err != nil || network != "tcp" || !slices.Equal(addresses, []string{"localhost:6379", "[::1]:6380"}) {
+		t.Errorf("The addresses should be parsed on tcp, %v %s and %v provided", addresses, network, err)
+	}
+
+	if addresses, network, err = core.ParseAddresses("unix:///var/run/redis.sock", "6379"); err != nil || network != "unix" || addresses[0] != "/var/run/redis.sock" {
+		t.Errorf("The unix socket should be parsed on unix, %v %s and %v provided", addresses, network, err)
+	}
+
+	if _, _, err = core.ParseAddresses("/var/run/redis.sock,localhost", "6379"); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("The unix socket mixed with other addresses should be invalid, %v provided", err)
+	}
+}
+
+type durationStorer struct {
+	core.Storer
+	durations []time.Duration
+}
+
+func (s *durationStorer) Set(_ string, _ []byte, duration time.Duration) error {
+	s.durations = append(s.durations, duration)
+
+	return nil
+}
+
+func (s *durationStorer) SetMany(items map[string]core.Entry) error {
+	for _, item := range items {
+		s.durations = append(s.durations, item.Duration)
+	}
+
+	return nil
+}
+
+// SetMultiLevel records the duration the storages derive from the varied headers.
+func (s *durationStorer) SetMultiLevel(_, _ string, _ []byte, variedHeaders http.Header, _ string, duration time.Duration, _ string) error {
+	duration, _ = core.MultiLevelTTL(variedHeaders, duration)
+	s.durations = append(s.durations, duration)
+
+	return nil
+}
+
+func TestTTLJitter(t *testing.T) {
+	if jitter := core.NewTTLJitter(0, 1); jitter != nil || jitter.Apply(time.Minute) != time.Minute {
+		t.Error("A zero fraction should keep the durations as is")
+	}
+
+	first, second := core.NewTTLJitter(0.1, 42), core.NewTTLJitter(0.1, 42)
+	for range 100 {
+		if first.Apply(time.Minute) != second.Apply(time.Minute) {
+			t.Fatal("The same seed should reproduce the same durations")
+		}
+	}
+
+	if first.Apply(-1) != -1 {
+		t.Error("The non-positive durations should be kept as is")
+	}
+
+	if err := core.ValidateConfig(core.CacheProvider{TTLJitter: 1}); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("A jitter fraction of 1 should be rejected, %v provided", err)
+	}
+
+	recorder := &durationStorer{}
+	storer := mustInstrument(t, recorder, core.CacheProvider{TTLJitter: 0.1, TTLJitterSeed: 7}, nil)
+	items := map[string]core.Entry{}
+	headers := http.Header{"Cache-Control": []string{"public, s-maxage=100"}}
+
+	for i := range 500 {
+		_ = storer.Set(fmt.Sprint("key", i), nil, 100*time.Second)
+		_ = storer.SetMultiLevel("base", fmt.Sprint("varied", i), nil, headers, "", 10*time.Second, "base")
+		items[fmt.Sprint("many", i)] = core.Entry{Duration: 100 * time.Second}
+	}
+
+	_ = storer.SetMany(items)
+
+	if len(recorder.durations) != 1500 {
+		t.Fatalf("Every write should reach the storer, %d provided", len(recorder.durations))
+	}
+
+	lowest, highest := recorder.durations[0], recorder.durations[0]
+	for _, duration := range recorder.durations {
+		if duration < 90*time.Second || duration > 110*time.Second {
+			t.Fatalf("The duration %v should be within ±10%% of 100s", duration)
+		}
+
+		lowest, highest = min(lowest, duration), max(highest, duration)
+	}
+
+	if lowest > 91*time.Second || highest < 109*time.Second {
+		t.Errorf("The durations should be spread across the window, %v to %v provided", lowest, highest)
+	}
+
+	if values := headers.Values("Cache-Control"); len(values) != 1 || values[0] != "public, s-maxage=100" {
+		t.Errorf("The varied headers of the caller shouldn't be altered, %v provided", values)
+	}
+}
+
+func TestIterateWith(t *testing.T) {
+	failure := errors.New("backend failure")
+	entries, errs := core.IterateWith(context.Background(), func(yield func(core.KV) error) error {
+		for i := range 3 {
+			if err := yield(core.KV{Key: fmt.Sprintf("key_%d", i)}); err != nil {
+				return err
+			}
+		}
+
+		return failure
+	})
+
+	count := 0
+	for range entries {
+		count++
+	}
+
+	if err := <-errs; !errors.Is(err, failure) || count != 3 {
+		t.Errorf("The 3 entries then the backend error should be streamed, %d entries and %v provided", count, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries, errs = core.IterateWith(ctx, func(yield func(core.KV) error) error {
+		return yield(core.KV{Key: "key"})
+	})
+
+	if _, ok := <-entries; ok {
+		t.Error("No entry should be streamed once the context is done")
+	}
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("The context error should be streamed, %v provided", err)
+	}
+}
+
+func TestWatchHub(t *testing.T) {
+	hub := core.NewWatchHub(&warnLogger{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := hub.Watch(ctx, "key_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the hub: %v", err)
+	}
+
+	remaining, _ := hub.Watch(context.Background(), "")
+
+	hub.Publish(core.EventSet, "other")
+	hub.Publish(core.EventDelete, "key_1")
+
+	if event := <-events; event != (core.Event{Type: core.EventDelete, Key: "key_1"}) {
+		t.Errorf("Only the events of the prefix should be streamed, %s of %s provided", event.Type, event.Key)
+	}
+
+	cancel()
+
+	if _, ok := <-events; ok {
+		t.Error("The events should stop once the context is cancelled")
+	}
+
+	hub.Close()
+
+	count := 0
+	for range remaining {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("The queued events should be streamed before the channel is closed, %d provided", count)
+	}
+
+	if _, err = hub.Watch(context.Background(), ""); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Watch should return ErrClosed once the hub is closed, %v provided", err)
+	}
+}
@@ -3,31 +3,105 @@
 package core
 
 import (
-	"bufio"
-	"bytes"
+	"context"
+	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
-	"github.com/pierrec/lz4/v4"
-	"google.golang.org/protobuf/proto"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Storer interface {
 	MapKeys(prefix string) map[string]string
 	ListKeys() []string
+	// ScanKeys returns a page of at most limit keys matching the prefix starting from the cursor.
+	// An empty next cursor signals the end of the iteration.
+	ScanKeys(prefix, cursor string, limit int) (keys []string, next string)
+	// SortedKeys returns every key matching the prefix in lexicographic order, the internal keys are
+	// skipped as by ScanKeys.
+	SortedKeys(prefix string) []string
 	Get(key string) []byte
+	// GetContext returns the value of the key or the context error if it's done before.
+	GetContext(ctx context.Context, key string) ([]byte, error)
+	// GetWithError returns the value of the key, ErrKeyNotFound when it's missing and the backend error otherwise.
+	GetWithError(key string) ([]byte, error)
+	// GetMany returns the values of the existing keys, the missing ones are absent from the map.
+	GetMany(keys []string) map[string][]byte
+	// GetTTL returns the remaining time to live of the key and whether it exists.
+	// A key without expiry returns NoExpiration.
+	GetTTL(key string) (time.Duration, bool)
+	// Exists reports whether the key is stored and not expired without reading its value when possible.
+	Exists(key string) bool
 	Set(key string, value []byte, duration time.Duration) error
+	// SetContext stores the value unless the context is done before the write is committed.
+	SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error
+	// SetMany stores all the entries at once and returns the first error.
+	// The partial failure semantics depend on the backend transaction support.
+	SetMany(items map[string]Entry) error
+	// SetNX stores the value only if the key doesn't exist yet and reports whether it has been created.
+	SetNX(key string, value []byte, duration time.Duration) (bool, error)
+	// CompareAndSwap stores the value only if the current one equals old and reports whether it has been swapped.
+	// A nil old value expects the key to be missing, the swap then creates it.
+	CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error)
+	// Increment atomically adds delta to the counter stored at key and returns the new value.
+	// A missing key starts from 0, the counter is stored as 8 bytes big-endian and its time to live
+	// is reset to the duration on each call, a non-positive duration keeps it without expiry.
+	Increment(key string, delta int64, duration time.Duration) (int64, error)
+	// Decrement atomically subtracts delta from the counter stored at key, see Increment.
+	Decrement(key string, delta int64, duration time.Duration) (int64, error)
+	// SetStream compresses the reader content incrementally and stores it, GetStream must be used to read it back.
+	SetStream(key string, reader io.Reader, duration time.Duration) error
+	// GetStream returns a reader decompressing lazily the value stored by SetStream.
+	GetStream(key string) (io.ReadCloser, error)
+	// Touch updates the expiry of an existing key without rewriting its value.
+	Touch(key string, duration time.Duration) error
+	// SetWithMeta stores the value with small metadata pairs that GetMeta reads back without the value.
+	// The metadata expires and is deleted with the key.
+	SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error
+	// GetMeta returns the metadata stored by SetWithMeta, ErrKeyNotFound when the key has none.
+	GetMeta(key string) (map[string]string, error)
 	Delete(key string)
-	DeleteMany(key string)
+	// DeleteMany deletes every key beginning with the pattern, a trailing * is treated as a wildcard.
+	DeleteMany(pattern string)
+	// DeleteKeys deletes the listed keys with their metadata at once, the missing keys are ignored.
+	// The partial failure semantics depend on the backend transaction support, see SetMany.
+	DeleteKeys(keys []string) error
+	// InvalidateSurrogate deletes the responses tagged by the surrogate key in their Surrogate-Key header
+	// and returns the number of deleted responses, see IndexSurrogateKeys.
+	InvalidateSurrogate(surrogateKey string) (int, error)
+	// ListSurrogates returns the sorted surrogate keys indexed by the stored responses, see
+	// ListSurrogateKeys.
+	ListSurrogates() ([]string, error)
+	// GetBySurrogate returns the values of the responses tagged by the surrogate key keyed by their varied key,
+	// the indexed keys expired since are omitted, see GetSurrogateEntries.
+	GetBySurrogate(surrogateKey string) (map[string][]byte, error)
+	// Inspect returns the details of the stored key without altering it, see InspectEntry.
+	Inspect(key string) (*EntryInfo, error)
 	Init() error
 	Name() string
 	Uuid() string
+	// Reset removes every key of the instance namespace or bucket, the store remains usable afterwards.
 	Reset() error
-
-	// Multi level storer to handle fresh/stale at once
+	// Close releases the underlying resources, the operations return ErrClosed afterwards when supported.
+	Close() error
+	// Stats returns the storage statistics, see StorageStats for the approximations.
+	Stats() (StorageStats, error)
+	// Ping checks the storage is reachable and returns the context error if it's done before.
+	Ping(ctx context.Context) error
+	// Export streams every key with its value and remaining time to live, see ExportWriter for the format.
+	Export(w io.Writer) error
+	// Import stores the records written by Export, using their remaining time to live as duration.
+	Import(r io.Reader) error
+
+	// Multi level storer to handle fresh/stale at once, the validator Revalidate flag reports the stale
+	// response is usable within its stale-while-revalidate window. A fresh response is returned as stale to a
+	// request with the Cache-Control no-cache or max-age=0 directive, see Revalidator.ForcedRevalidation.
 	GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response)
+	// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag, age and remaining TTL.
+	GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch)
 	SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error
 }
 
@@ -39,27 +113,157 @@ type CacheProvider struct {
 	Path string `json:"path" yaml:"path"`
 	// Declare the cache provider directly in the Souin configuration.
 	Configuration any `json:"configuration" yaml:"configuration"`
+	// Compression codec used to store the responses (lz4, zstd, auto or none), lz4 by default. The auto codec
+	// elects lz4, zstd or none per response larger than 1KB by compressing a sample, the streams use lz4.
+	Compression string `json:"compression" yaml:"compression"`
+	// CompressionLevel is the lz4 compression level from 1 (fastest) to 9 (smallest), the fast default when zero.
+	CompressionLevel int `json:"compression_level" yaml:"compression_level"`
+	// CompressionBlockSize is the lz4 block size in bytes (65536, 262144, 1048576 or 4194304), 4MB when zero.
+	// Larger blocks compress the large values better at the cost of more memory per writer.
+	CompressionBlockSize int `json:"compression_block_size" yaml:"compression_block_size"`
+	// CompressionMinSize stores the responses shorter than this size in bytes uncompressed as the codec frame
+	// overhead would make them larger, every response is compressed when zero.
+	CompressionMinSize int `json:"compression_min_size" yaml:"compression_min_size"`
+	// DisableCompression stores the responses uncompressed whatever the compression codec.
+	DisableCompression bool `json:"disable_compression" yaml:"disable_compression"`
+	// CompressionDictionary is a zstd dictionary, trained or raw content, shrinking the similar responses compressed
+	// by the zstd and auto codecs. The values record its identity and can only be read with it, see ErrCompressionDictionary.
+	// It's base64 encoded in JSON.
+	CompressionDictionary []byte `json:"compression_dictionary" yaml:"compression_dictionary"`
+	// CompressionDictionaryPath loads the CompressionDictionary from this file.
+	CompressionDictionaryPath string `json:"compression_dictionary_path" yaml:"compression_dictionary_path"`
+	// Namespace prefixes every key to share a single database between several stores.
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// KeyHashing stores the entries under a hash of their key (none, sha256 or xxhash), see KeyHashed.
+	KeyHashing string `json:"key_hashing" yaml:"key_hashing"`
+	// Checksum appends a checksum trailer verified on read to each value (none, crc32c or xxhash), see Checksummed.
+	Checksum string `json:"checksum" yaml:"checksum"`
+	// MaxValueSize rejects the values larger than this number of bytes before compression, zero means unlimited.
+	MaxValueSize int64 `json:"max_value_size" yaml:"max_value_size"`
+	// MaxKeys bounds the number of keys written through the instance, a new key over the limit is handled by the
+	// MaxKeysPolicy. Zero means unlimited, see KeyLimited.
+	MaxKeys int `json:"max_keys" yaml:"max_keys"`
+	// MaxKeysPolicy is MaxKeysReject to return ErrKeyLimit or MaxKeysEvictOldest to delete the least recently
+	// written key, reject when empty.
+	MaxKeysPolicy string `json:"max_keys_policy" yaml:"max_keys_policy"`
+	// OperationTimeout bounds each Get, Set and Delete call and the badger and nuts iterations, zero means no timeout.
+	OperationTimeout time.Duration `json:"operation_timeout" yaml:"operation_timeout"`
+	// SlowLogThreshold logs a warning for each Get, Set, GetMultiLevel and SetMultiLevel lasting at least this
+	// duration, with the operation, key, duration and value size. Zero disables it, see SlowLogged.
+	SlowLogThreshold time.Duration `json:"slow_log_threshold" yaml:"slow_log_threshold"`
+	// Metrics receives the hits, misses and errors of the storage when set.
+	Metrics Metrics `json:"-" yaml:"-"`
+	// TracerProvider creates the spans around the storage operations when set.
+	TracerProvider trace.TracerProvider `json:"-" yaml:"-"`
+	// Mapper serializes the multi-level mapping, the protobuf binary format is used when nil.
+	Mapper Mapper `json:"-" yaml:"-"`
+	// OnEvict is called from a dedicated goroutine with the entries removed by Delete, DeleteMany, their expiry
+	// or the size eviction, see EvictReason. Only the badger and nuts storages report the evictions, the badger expiries
+	// are reported by its eviction pass every minute and the nuts ones by its sweep, see SweepInterval.
+	OnEvict func(key string, reason EvictReason) `json:"-" yaml:"-"`
+	// Clock tells the time to the multi-level mapping election and to the storages checking the expiry themselves,
+	// the real time is used when nil. The storages delegating the expiry to their database keep its clock for the values.
+	Clock Clock `json:"-" yaml:"-"`
+	// ValueLogGCInterval is the period of the badger value log garbage collection, 5 minutes when nil, zero disables it.
+	ValueLogGCInterval *time.Duration `json:"value_log_gc_interval" yaml:"value_log_gc_interval"`
+	// ValueLogGCDiscardRatio is the minimum ratio of stale data to rewrite a badger value log file, 0.5 when zero.
+	ValueLogGCDiscardRatio float64 `json:"value_log_gc_discard_ratio" yaml:"value_log_gc_discard_ratio"`
+	// ConflictMaxAttempts is the maximum number of attempts of a badger write transaction aborted by a conflict with
+	// a concurrent one, including the first attempt, 10 when zero. The badger.ErrConflict is returned past it.
+	ConflictMaxAttempts int `json:"conflict_max_attempts" yaml:"conflict_max_attempts"`
+	// EncryptionKey enables the badger encryption at rest with this AES key of 16, 24 or 32 bytes, the same
+	// key is required to reopen the DB. It's base64 encoded in JSON, see CheckEncryptionKey.
+	EncryptionKey []byte `json:"encryption_key" yaml:"encryption_key"`
+	// EncryptionKeyRotationDuration is the period badger rotates the data keys encrypted by EncryptionKey, 10 days when zero.
+	EncryptionKeyRotationDuration time.Duration `json:"encryption_key_rotation_duration" yaml:"encryption_key_rotation_duration"`
+	// MaxCacheSizeBytes bounds the size of the badger entries by evicting the least recently used ones, zero means unlimited.
+	MaxCacheSizeBytes int64 `json:"max_cache_size_bytes" yaml:"max_cache_size_bytes"`
+	// NumCompactors is the number of badger compaction workers, 4 when zero. Badger requires at least 2.
+	NumCompactors int `json:"num_compactors" yaml:"num_compactors"`
+	// NumLevelZeroTables is the number of badger level zero tables starting the compaction, 5 when zero.
+	NumLevelZeroTables int `json:"num_level_zero_tables" yaml:"num_level_zero_tables"`
+	// NumLevelZeroTablesStall is the number of badger level zero tables stalling the writes until the compaction
+	// catches up, 15 when zero. It must be greater than NumLevelZeroTables.
+	NumLevelZeroTablesStall int `json:"num_level_zero_tables_stall" yaml:"num_level_zero_tables_stall"`
+	// BaseTableSize is the maximum size in bytes of a badger table in the base level, 2MB when zero.
+	BaseTableSize int64 `json:"base_table_size" yaml:"base_table_size"`
+	// Bucket is the nuts bucket holding the entries, souin-bucket when empty.
+	Bucket string `json:"bucket" yaml:"bucket"`
+	// SegmentSize is the maximum size in bytes of a nuts data file before the rotation, 256MB when zero.
+	SegmentSize int64 `json:"segment_size" yaml:"segment_size"`
+	// SweepInterval is the period of the nuts sweep deleting the expired entries, zero disables it.
+	SweepInterval time.Duration `json:"sweep_interval" yaml:"sweep_interval"`
+	// SyncWrites tells whether nuts fsyncs its data file on each commit, true when nil. The unsynced commits
+	// are faster but the ones still buffered by the system are lost on a crash, Close flushes them.
+	SyncWrites *bool `json:"sync_writes" yaml:"sync_writes"`
+	// IndexMode is the nuts entry index mode, IndexModeKeyValue when empty keeps the values in memory next to the
+	// keys and IndexModeKey reads them from disk to bound the memory to the keys. It applies when the DB is opened.
+	IndexMode string `json:"index_mode" yaml:"index_mode"`
+	// ReadCacheSize bounds the number of nuts values kept in memory for the next reads of the same keys, zero
+	// disables it. The entries are evicted by the writes made through the instance and never outlive their TTL.
+	ReadCacheSize int `json:"read_cache_size" yaml:"read_cache_size"`
+	// ReadOnly rejects the writes with ErrReadOnly and opens the badger DB read-only, see ReadOnly.
+	ReadOnly bool `json:"read_only" yaml:"read_only"`
+	// ExportGzip gzips the Export stream to shrink the snapshots, Import detects the gzipped streams whatever
+	// this option, see GzipExport.
+	ExportGzip bool `json:"export_gzip" yaml:"export_gzip"`
+	// MaxVariants bounds the number of varied responses mapped per base key, storing a new one over the limit
+	// returns ErrVariantLimit, zero means unlimited.
+	MaxVariants int `json:"max_variants" yaml:"max_variants"`
+	// DirPermissions are the permissions of the badger and nuts directories created by the storage, the storage
+	// defaults are kept when zero (0700 for badger, 0777 minus the umask for nuts). The owner must have all of them.
+	DirPermissions os.FileMode `json:"dir_permissions" yaml:"dir_permissions"`
+	// InMemory keeps the badger DB in memory without any file, the path is ignored and Close drops all the data.
+	InMemory bool `json:"in_memory" yaml:"in_memory"`
+	// Shards spreads the badger keys by their hash across this number of databases, each one in its own
+	// subdirectory of the path, a single database is used when lower than two. MaxCacheSizeBytes is split between them.
+	Shards int `json:"shards" yaml:"shards"`
+	// Validate checks the configuration with ValidateConfig when the storage is created, an invalid one is
+	// rejected before any connection or file is opened.
+	Validate bool `json:"validate" yaml:"validate"`
+	// TTLJitter spreads the time to live of each written entry by up to this fraction, 0.1 for ±10%, so the
+	// entries written together don't expire together. It must be lower than 1, zero disables it, see TTLJitter.
+	TTLJitter float64 `json:"ttl_jitter" yaml:"ttl_jitter"`
+	// TTLJitterSeed seeds the TTLJitter random source to reproduce the jittered durations, a random seed is used when zero.
+	TTLJitterSeed uint64 `json:"ttl_jitter_seed" yaml:"ttl_jitter_seed"`
 }
 
-const MappingKeyPrefix = "IDX_"
+const (
+	MappingKeyPrefix   = "IDX_"
+	SurrogateKeyPrefix = "SURROGATE_"
+	EntryMetaKeyPrefix = "META_"
+)
 
 func DecodeMapping(item []byte) (*StorageMapper, error) {
-	mapping := &StorageMapper{}
-	e := proto.Unmarshal(item, mapping)
-
-	return mapping, e
+	return ProtobufMapper{}.Decode(item)
 }
 
 func MappingElection(provider Storer, item []byte, req *http.Request, validator *Revalidator, logger Logger) (resultFresh *http.Response, resultStale *http.Response, e error) {
+	resultFresh, resultStale, _, e = MappingElectionDebug(provider, item, req, validator, logger)
+
+	return resultFresh, resultStale, e
+}
+
+// MappingElectionDebug works like MappingElection and reports the elected mapping entry.
+func MappingElectionDebug(provider Storer, item []byte, req *http.Request, validator *Revalidator, logger Logger) (resultFresh *http.Response, resultStale *http.Response, match MultiLevelMatch, e error) {
+	return MappingElectionWith(ProtobufMapper{}, provider, item, req, validator, logger)
+}
+
+// MappingElectionWith works like MappingElectionDebug with a mapping serialized by the given mapper.
+// The validator gets the stale-while-revalidate window of the elected response, see Revalidator.Revalidate.
+func MappingElectionWith(mapper Mapper, provider Storer, item []byte, req *http.Request, validator *Revalidator, logger Logger) (resultFresh *http.Response, resultStale *http.Response, match MultiLevelMatch, e error) {
 	mapping := &StorageMapper{}
 
 	if len(item) != 0 {
-		mapping, e = DecodeMapping(item)
+		mapping, e = mapper.Decode(item)
 		if e != nil {
-			return resultFresh, resultStale, e
+			return resultFresh, resultStale, match, e
 		}
 	}
 
+	now := mapperClock(mapper).Now()
+	noCache := requestNoCache(req.Header)
+
 	for keyName, keyItem := range mapping.GetMapping() {
 		valid := true
 
@@ -78,41 +282,40 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 		ValidateETagFromHeader(keyItem.GetEtag(), validator)
 
 		if validator.Matched {
-			// If the key is fresh enough.
-			if time.Since(keyItem.GetFreshTime().AsTime()) < 0 {
+			// If the key is fresh enough, the no-cache requests elect it as stale to revalidate it.
+			fresh := keyItem.GetFreshTime().AsTime().After(now)
+			if fresh && !noCache {
 				response := provider.Get(keyName)
 				if response != nil {
-					bufW := new(bytes.Buffer)
-					reader := lz4.NewReader(bytes.NewBuffer(response))
-					_, _ = reader.WriteTo(bufW)
-
-					if resultFresh, e = http.ReadResponse(bufio.NewReader(bufW), req); e != nil {
+					if resultFresh, e = readResponse(response, req); e != nil {
 						logger.Errorf("An error occurred while reading response for the key %s: %v", keyName, e)
 
-						return resultFresh, resultStale, e
+						return resultFresh, resultStale, match, e
 					}
 
 					logger.Debugf("The stored key %s matched the current iteration key ETag %+v", keyName, validator)
+					electStaleWhileRevalidate(validator, resultFresh, keyItem.GetFreshTime().AsTime(), now, false)
+					match = electedMatch(keyName, keyItem, false, now)
 
-					return resultFresh, resultStale, e
+					return resultFresh, resultStale, match, e
 				}
 			}
 
 			// If the key is still stale.
-			if time.Since(keyItem.GetStaleTime().AsTime()) < 0 {
+			if keyItem.GetStaleTime().AsTime().After(now) {
 				response := provider.Get(keyName)
 				if response != nil {
-					bufW := new(bytes.Buffer)
-					reader := lz4.NewReader(bytes.NewBuffer(response))
-					_, _ = reader.WriteTo(bufW)
-
-					if resultStale, e = http.ReadResponse(bufio.NewReader(bufW), req); e != nil {
+					if resultStale, e = readResponse(response, req); e != nil {
 						logger.Errorf("An error occurred while reading response for the key %s: %v", keyName, e)
 
-						return resultFresh, resultStale, e
+						return resultFresh, resultStale, match, e
 					}
 
 					logger.Debugf("The stored key %s matched the current iteration key ETag %+v as stale", keyName, validator)
+					electStaleWhileRevalidate(validator, resultStale, keyItem.GetFreshTime().AsTime(), now, true)
+					validator.ForcedRevalidation = fresh
+					validator.Revalidate = validator.Revalidate && !fresh
+					match = electedMatch(keyName, keyItem, true, now)
 				}
 			}
 		} else {
@@ -120,13 +323,21 @@ func MappingElection(provider Storer, item []byte, req *http.Request, validator
 		}
 	}
 
-	return resultFresh, resultStale, e
+	return resultFresh, resultStale, match, e
 }
 
 func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, realKey string) (val []byte, e error) {
+	return MappingUpdaterWith(ProtobufMapper{}, key, item, logger, now, freshTime, staleTime, variedHeaders, etag, realKey)
+}
+
+// MappingUpdaterWith works like MappingUpdater with a mapping serialized by the given mapper. The key is
+// added next to the other variants of the mapping or replaces its own entry when it's already mapped,
+// the expired variants are dropped and ErrVariantLimit is returned over the mapper limit, see ConfiguredMapper.
+// A Vary: * variant is mapped without freshness, see VaryAll.
+func MappingUpdaterWith(mapper Mapper, key string, item []byte, logger Logger, now, freshTime, staleTime time.Time, variedHeaders http.Header, etag, realKey string) (val []byte, e error) {
 	mapping := &StorageMapper{}
 	if len(item) != 0 {
-		e = proto.Unmarshal(item, mapping)
+		mapping, e = mapper.Decode(item)
 		if e != nil {
 			logger.Errorf("Impossible to decode the key %s, %v", key, e)
 
@@ -138,6 +349,20 @@ func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, stal
 		mapping.Mapping = make(map[string]*KeyIndex)
 	}
 
+	if e = admitVariant(mapper, mapping, key, now); e != nil {
+		logger.Errorf("Impossible to map the variant %s, %v", key, e)
+
+		return nil, e
+	}
+
+	variedHeaders = withoutInternalHeaders(variedHeaders)
+
+	// A Vary: * response is never fresh for another request, its stale window is kept for the revalidation.
+	if VaryAll(variedHeaders) {
+		freshTime = now
+		variedHeaders = withoutVaryAll(variedHeaders)
+	}
+
 	var pbvariedeheader map[string]*KeyIndexStringList
 	if variedHeaders != nil {
 		pbvariedeheader = make(map[string]*KeyIndexStringList)
@@ -156,7 +381,7 @@ func MappingUpdater(key string, item []byte, logger Logger, now, freshTime, stal
 		RealKey:       realKey,
 	}
 
-	val, e = proto.Marshal(mapping)
+	val, e = mapper.Encode(mapping)
 	if e != nil {
 		logger.Errorf("Impossible to encode the mapping value for the key %s, %v", key, e)
 
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const counterSize = 8
+
+// EncodeCounter returns the stored representation of the counter, 8 bytes big-endian.
+func EncodeCounter(value int64) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, counterSize), uint64(value))
+}
+
+// DecodeCounter parses a value written by EncodeCounter, ErrNotCounter is returned if it's malformed.
+func DecodeCounter(value []byte) (int64, error) {
+	if len(value) != counterSize {
+		return 0, ErrNotCounter
+	}
+
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+// AddCounter decodes the current value, a nil one meaning a missing key, and returns the incremented
+// counter with its stored representation.
+func AddCounter(current []byte, delta int64) (int64, []byte, error) {
+	var value int64
+
+	if current != nil {
+		var err error
+		if value, err = DecodeCounter(current); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	value += delta
+
+	return value, EncodeCounter(value), nil
+}
+
+// SwapMatches reports whether the current value, a nil one meaning a missing key, is the old value expected
+// by CompareAndSwap. A nil old value only matches a missing key.
+func SwapMatches(current, old []byte) bool {
+	if old == nil || current == nil {
+		return old == nil && current == nil
+	}
+
+	return bytes.Equal(current, old)
+}
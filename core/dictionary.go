@@ -0,0 +1,103 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// dictionaryIDSize is the size of the dictionary identity following the zstdDictHeader.
+const dictionaryIDSize = 4
+
+var (
+	// zstdDictMagic starts the trained zstd dictionaries, the other dictionaries are raw content.
+	zstdDictMagic = []byte{0x37, 0xa4, 0x30, 0xec}
+
+	// The dictionaries registered by ConfiguredCompression are shared by the decompressions of the process,
+	// whatever the storage reading the value.
+	zstdDictionaries sync.Map
+)
+
+// configuredDictionary returns the zstd dictionary declared in the cache provider, read from its path when
+// it isn't given inline.
+func configuredDictionary(cfg CacheProvider) ([]byte, error) {
+	if len(cfg.CompressionDictionary) > 0 || cfg.CompressionDictionaryPath == "" {
+		return cfg.CompressionDictionary, nil
+	}
+
+	return os.ReadFile(cfg.CompressionDictionaryPath)
+}
+
+// registerDictionary records the dictionary for the compressions and decompressions of the process and returns
+// its identity, derived from its content so every instance sharing the dictionary agrees on it.
+func registerDictionary(content []byte) uint32 {
+	sum := sha256.Sum256(content)
+	id := binary.BigEndian.Uint32(sum[:dictionaryIDSize])
+
+	zstdDictionaries.LoadOrStore(id, bytes.Clone(content))
+
+	return id
+}
+
+// lookupDictionary returns the registered dictionary of the identity, ErrCompressionDictionary when it
+// isn't configured in this process.
+func lookupDictionary(id uint32) ([]byte, error) {
+	content, found := zstdDictionaries.Load(id)
+	if !found {
+		return nil, fmt.Errorf("%w: the value was compressed with the zstd dictionary %08x", ErrCompressionDictionary, id)
+	}
+
+	return content.([]byte), nil
+}
+
+// zstdDictWriter writes the zstdDictHeader and the identity of the dictionary carried by the codec options then
+// returns a zstd writer compressing with it.
+func zstdDictWriter(buf *bytes.Buffer, option string) (io.WriteCloser, error) {
+	id, err := strconv.ParseUint(option, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s%s%s", ErrUnknownCompression, CompressionZstd, optionSeparator, option)
+	}
+
+	content, err := lookupDictionary(uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	dict := zstd.WithEncoderDictRaw(uint32(id), content)
+	if bytes.HasPrefix(content, zstdDictMagic) {
+		dict = zstd.WithEncoderDict(content)
+	}
+
+	buf.WriteByte(zstdDictHeader)
+	_ = binary.Write(buf, binary.BigEndian, uint32(id))
+
+	return zstd.NewWriter(buf, dict)
+}
+
+// zstdDictReader reads the identity of the dictionary following the zstdDictHeader then returns a zstd reader
+// decompressing with it, ErrCompressionDictionary when the dictionary isn't configured.
+func zstdDictReader(reader io.Reader) (*zstd.Decoder, error) {
+	var id uint32
+	if err := binary.Read(reader, binary.BigEndian, &id); err != nil {
+		return nil, fmt.Errorf("%w: truncated dictionary identity", ErrUnknownCompressionHeader)
+	}
+
+	content, err := lookupDictionary(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := zstd.WithDecoderDictRaw(id, content)
+	if bytes.HasPrefix(content, zstdDictMagic) {
+		dict = zstd.WithDecoderDicts(content)
+	}
+
+	return zstd.NewReader(reader, dict)
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// CreateDirectory creates the missing storage directory with the permissions, the missing parents get them
+// too. The permissions are applied whatever the umask, an existing directory is kept as is and a zero mode
+// leaves the creation to the storage.
+func CreateDirectory(path string, permissions os.FileMode) error {
+	if permissions == 0 || path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(path, permissions); err != nil {
+		return err
+	}
+
+	return os.Chmod(path, permissions)
+}
@@ -0,0 +1,365 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrDecryption is returned when a stored value can't be authenticated with the encryption key,
+// either because it has been written using another key or because it has been altered.
+var ErrDecryption = errors.New("impossible to decrypt the value, the encryption key may be wrong")
+
+// Encrypted wraps the storer to encrypt the values at rest using AES-GCM.
+// Each value is sealed with a random nonce stored as a prefix of the stored blob.
+// The key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256, Encrypted panics otherwise.
+// The mapping metadata, the counters and the keys are stored in clear, the mapping is read with the default
+// ProtobufMapper, see EncryptedWith. The sealed responses don't compress, the storages store them uncompressed.
+// The export keeps the values encrypted once authenticated, it must be imported into a storer encrypted with the same key.
+func Encrypted(storer Storer, key []byte) Storer {
+	return EncryptedWith(storer, key, nil)
+}
+
+// EncryptedWith works like Encrypted with the mappings read by the mapper of the storage, ConfiguredMapper
+// carries its Mapper, MaxVariants and Clock.
+func EncryptedWith(storer Storer, key []byte, mapper Mapper) Storer {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(fmt.Sprintf("core: invalid encryption key: %v", err))
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(fmt.Sprintf("core: invalid encryption key: %v", err))
+	}
+
+	return &encryptedStorer{Storer: storer, aead: aead, mapper: MapperOrDefault(mapper)}
+}
+
+type encryptedStorer struct {
+	Storer
+	aead   cipher.AEAD
+	mapper Mapper
+}
+
+func (s *encryptedStorer) encrypt(value []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(value)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return s.aead.Seal(nonce, nonce, value, nil), nil
+}
+
+func (s *encryptedStorer) decrypt(value []byte) ([]byte, error) {
+	if len(value) < s.aead.NonceSize() {
+		return nil, ErrDecryption
+	}
+
+	plain, err := s.aead.Open(nil, value[:s.aead.NonceSize()], value[s.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return plain, nil
+}
+
+// decryptResponse returns the response stored compressed by SetMultiLevel decrypted and uncompressed.
+func (s *encryptedStorer) decryptResponse(compressed []byte) ([]byte, error) {
+	sealed, err := Decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := s.decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{noneHeader}, plain...), nil
+}
+
+// authenticate checks a value stored by Set or a response stored by SetMultiLevel can be decrypted.
+func (s *encryptedStorer) authenticate(value []byte) error {
+	if _, err := s.decrypt(value); err == nil {
+		return nil
+	}
+
+	if _, err := s.decryptResponse(value); err != nil {
+		return ErrDecryption
+	}
+
+	return nil
+}
+
+func (s *encryptedStorer) MapKeys(prefix string) map[string]string {
+	keys := s.Storer.MapKeys(prefix)
+	for key, value := range keys {
+		plain, err := s.decrypt([]byte(value))
+		if err != nil {
+			delete(keys, key)
+
+			continue
+		}
+
+		keys[key] = string(plain)
+	}
+
+	return keys
+}
+
+func (s *encryptedStorer) Get(key string) []byte {
+	value, _ := s.GetContext(context.Background(), key)
+
+	return value
+}
+
+func (s *encryptedStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.Storer.GetContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decrypt(value)
+}
+
+func (s *encryptedStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *encryptedStorer) GetMany(keys []string) map[string][]byte {
+	values := s.Storer.GetMany(keys)
+	for key, value := range values {
+		plain, err := s.decrypt(value)
+		if err != nil {
+			delete(values, key)
+
+			continue
+		}
+
+		values[key] = plain
+	}
+
+	return values
+}
+
+// GetMultiLevel runs the election on the decrypted values, the wrapped storer would hand
+// the sealed bytes to the response parser otherwise.
+func (s *encryptedStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = s.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+func (s *encryptedStorer) GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch) {
+	mapping := s.Storer.Get(MappingKeyPrefix + key)
+	if mapping == nil {
+		return nil, nil, match
+	}
+
+	fresh, stale, match, _ = MappingElectionWith(s.mapper, &decryptedResponses{s}, mapping, req, validator, nopLogger{})
+
+	return fresh, stale, match
+}
+
+func (s *encryptedStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *encryptedStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	return s.Storer.SetContext(ctx, key, sealed, duration)
+}
+
+func (s *encryptedStorer) SetMany(items map[string]Entry) error {
+	sealed := make(map[string]Entry, len(items))
+
+	for key, item := range items {
+		value, err := s.encrypt(item.Value)
+		if err != nil {
+			return err
+		}
+
+		sealed[key] = Entry{Value: value, Duration: item.Duration}
+	}
+
+	return s.Storer.SetMany(sealed)
+}
+
+func (s *encryptedStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return false, err
+	}
+
+	return s.Storer.SetNX(key, sealed, duration)
+}
+
+// CompareAndSwap decrypts the current value to compare it, the swap is then conditioned on the stored sealed
+// value since each encryption draws a new nonce.
+func (s *encryptedStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	var current []byte
+
+	if old != nil {
+		var err error
+
+		current, err = s.Storer.GetWithError(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		plain, err := s.decrypt(current)
+		if err != nil {
+			return false, err
+		}
+
+		if !bytes.Equal(plain, old) {
+			return false, nil
+		}
+	}
+
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return false, err
+	}
+
+	return s.Storer.CompareAndSwap(key, current, sealed, duration)
+}
+
+// SetWithMeta encrypts the value only, the metadata is stored in plain text.
+func (s *encryptedStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	return s.Storer.SetWithMeta(key, sealed, meta, duration)
+}
+
+// SetStream reads the whole content to seal it at once, AES-GCM can't authenticate a partial stream.
+func (s *encryptedStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	value, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	return s.Storer.SetStream(key, bytes.NewReader(sealed), duration)
+}
+
+// GetStream reads the whole stored content to authenticate it before returning the decrypted reader.
+func (s *encryptedStorer) GetStream(key string) (io.ReadCloser, error) {
+	stream, err := s.Storer.GetStream(key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	sealed, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := s.decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(plain)), nil
+}
+
+// GetBySurrogate returns the decrypted responses uncompressed, the ones that can't be decrypted are omitted.
+func (s *encryptedStorer) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	entries, err := s.Storer.GetBySurrogate(surrogateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range entries {
+		plain, err := s.decryptResponse(value)
+		if err != nil {
+			delete(entries, key)
+
+			continue
+		}
+
+		entries[key] = plain
+	}
+
+	return entries, nil
+}
+
+// Inspect authenticates the stored value and reports its length without the nonce and the tag.
+func (s *encryptedStorer) Inspect(key string) (*EntryInfo, error) {
+	info, err := s.Storer.Inspect(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err := s.Storer.GetWithError(key); err == nil {
+		if err = s.authenticate(value); err != nil {
+			return nil, err
+		}
+
+		info.ValueLength -= s.aead.NonceSize() + s.aead.Overhead()
+	}
+
+	return info, nil
+}
+
+// Export authenticates each encrypted value before writing it as stored.
+func (s *encryptedStorer) Export(w io.Writer) error {
+	return exportVerified(s.Storer, w, s.authenticate)
+}
+
+// SetMultiLevel stores the sealed response uncompressed, compressing it would only spend time, see MultiLevelCompression.
+func (s *encryptedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	sealed, err := s.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	variedHeaders = withInternalHeader(variedHeaders, multiLevelCompressionKey, CompressionNone)
+
+	return s.Storer.SetMultiLevel(baseKey, variedKey, sealed, variedHeaders, etag, duration, realKey)
+}
+
+// decryptedResponses exposes the responses stored by SetMultiLevel in the format expected by MappingElection.
+// The wrapped storer compressed the sealed value, it's decompressed then decrypted and served uncompressed.
+type decryptedResponses struct {
+	*encryptedStorer
+}
+
+func (s *decryptedResponses) Get(key string) []byte {
+	compressed := s.Storer.Get(key)
+	if compressed == nil {
+		return nil
+	}
+
+	plain, err := s.decryptResponse(compressed)
+	if err != nil {
+		return nil
+	}
+
+	return plain
+}
@@ -0,0 +1,43 @@
+package core
+
+import "time"
+
+// Entry represents a value to store with its time to live.
+type Entry struct {
+	Value    []byte
+	Duration time.Duration
+}
+
+// MultiLevelMatch describes the mapping entry elected by GetMultiLevelDebug.
+type MultiLevelMatch struct {
+	// VariedKey is the key storing the elected response, empty when nothing matched.
+	VariedKey string
+	// ETag is the ETag stored along the elected response.
+	ETag string
+	// Stale reports whether the elected response is only usable as stale.
+	Stale bool
+	// StoredAt is the time SetMultiLevel stored the elected response, zero when the mapping doesn't know it.
+	StoredAt time.Time
+	// Age is the time elapsed since StoredAt at the election, it's meant to compute the HTTP Age header.
+	Age time.Duration
+	// TTL is the remaining time the elected response is usable, fresh or stale.
+	TTL time.Duration
+}
+
+// electedMatch returns the match of the elected mapping entry at the time of the election.
+func electedMatch(variedKey string, keyIndex *KeyIndex, stale bool, now time.Time) MultiLevelMatch {
+	match := MultiLevelMatch{VariedKey: variedKey, ETag: keyIndex.GetEtag(), Stale: stale}
+
+	if stale {
+		match.TTL = keyIndex.GetStaleTime().AsTime().Sub(now)
+	} else {
+		match.TTL = keyIndex.GetFreshTime().AsTime().Sub(now)
+	}
+
+	if keyIndex.GetStoredAt() != nil {
+		match.StoredAt = keyIndex.GetStoredAt().AsTime()
+		match.Age = max(now.Sub(match.StoredAt), 0)
+	}
+
+	return match
+}
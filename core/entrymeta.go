@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// EncodeEntryMeta returns the JSON object of the metadata pairs.
+func EncodeEntryMeta(meta map[string]string) ([]byte, error) {
+	return json.Marshal(meta)
+}
+
+// DecodeEntryMeta returns the metadata pairs of the record written by EncodeEntryMeta.
+func DecodeEntryMeta(record []byte) (map[string]string, error) {
+	meta := map[string]string{}
+	if err := json.Unmarshal(record, &meta); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// SetWithEntryMeta stores the value then its metadata record under the EntryMetaKeyPrefix parallel key with
+// the same duration, through the storer Set. An empty metadata deletes the record of a previous call. The record
+// outlives a later plain Set of the key until the key is deleted or expires.
+func SetWithEntryMeta(storer Storer, key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if err := storer.Set(key, value, duration); err != nil {
+		return err
+	}
+
+	if len(meta) == 0 {
+		storer.Delete(EntryMetaKeyPrefix + key)
+
+		return nil
+	}
+
+	record, err := EncodeEntryMeta(meta)
+	if err != nil {
+		return err
+	}
+
+	return storer.Set(EntryMetaKeyPrefix+key, record, duration)
+}
+
+// GetEntryMeta returns the metadata stored by SetWithEntryMeta without reading the value, ErrKeyNotFound is
+// returned when the key has no metadata record.
+func GetEntryMeta(storer Storer, key string) (map[string]string, error) {
+	record := storer.Get(EntryMetaKeyPrefix + key)
+	if record == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return DecodeEntryMeta(record)
+}
+
+// DeleteEntryMeta deletes the metadata record of the key, the backends call it from their Delete so the
+// metadata is deleted with the key. The metadata records themselves have none.
+func DeleteEntryMeta(storer Storer, key string) {
+	if strings.HasPrefix(key, EntryMetaKeyPrefix) {
+		return
+	}
+
+	storer.Delete(EntryMetaKeyPrefix + key)
+}
+
+// KeysToDelete unindexes the surrogate keys of the responses stored under the keys and returns the keys
+// with their metadata records, the backends delete them at once from their DeleteKeys. The unindexing
+// errors are joined for DeleteKeys to return them, the keys are deleted anyway.
+func KeysToDelete(storer Storer, keys []string) ([]string, error) {
+	deleted := make([]string, 0, 2*len(keys))
+	errs := []error{}
+
+	for _, key := range keys {
+		if err := unindexSurrogateKeys(storer, key); err != nil {
+			errs = append(errs, err)
+		}
+
+		deleted = append(deleted, key)
+		if !strings.HasPrefix(key, EntryMetaKeyPrefix) {
+			deleted = append(deleted, EntryMetaKeyPrefix+key)
+		}
+	}
+
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,30 @@
+package core
+
+import "errors"
+
+var (
+	// ErrKeyNotFound is returned when the given key doesn't exist in the storage.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrClosed is returned when the storage is used after being closed.
+	ErrClosed = errors.New("the storage is closed")
+	// ErrValueTooLarge is returned when the value exceeds the storage size limit.
+	ErrValueTooLarge = errors.New("the value exceeds the storage size limit")
+	// ErrNotCounter is returned when a counter operation targets a value that isn't a counter.
+	ErrNotCounter = errors.New("the value is not a counter")
+	// ErrMalformedExport is returned when an imported record is truncated or malformed.
+	ErrMalformedExport = errors.New("the export record is malformed")
+	// ErrChecksumMismatch is returned when a stored value doesn't match its checksum trailer.
+	ErrChecksumMismatch = errors.New("the stored value doesn't match its checksum")
+	// ErrReadOnly is returned by the writes of a storage configured as read-only.
+	ErrReadOnly = errors.New("the storage is read-only")
+	// ErrCorruptEntry is returned when a stored response can't be decompressed or parsed back.
+	ErrCorruptEntry = errors.New("the stored response is corrupt")
+	// ErrVariantLimit is returned when a new variant exceeds the maximum number of variants of its base key.
+	ErrVariantLimit = errors.New("the base key has too many variants")
+	// ErrKeyLimit is returned when a new key exceeds the maximum number of keys of the storage.
+	ErrKeyLimit = errors.New("the storage holds too many keys")
+	// ErrCompressionDictionary is returned when a value was compressed with a zstd dictionary not configured.
+	ErrCompressionDictionary = errors.New("unknown compression dictionary")
+	// ErrSaturated is returned when every concurrency slot of a storage is taken and it fails fast.
+	ErrSaturated = errors.New("the storage is saturated")
+)
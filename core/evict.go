@@ -0,0 +1,122 @@
+package core
+
+import "sync"
+
+// EvictReason tells why an entry has been removed, see CacheProvider.OnEvict.
+type EvictReason int
+
+const (
+	// EvictDeleted reports an entry deleted by Delete or DeleteMany.
+	EvictDeleted EvictReason = iota
+	// EvictExpired reports an entry whose time to live elapsed, detected when the storage sweeps it.
+	EvictExpired
+	// EvictSize reports an entry evicted to fit the storage size budget.
+	EvictSize
+)
+
+// evictionQueueSize bounds the notifications waiting for the OnEvict callback.
+const evictionQueueSize = 1024
+
+// String returns the reason name.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictDeleted:
+		return "deleted"
+	case EvictExpired:
+		return "expired"
+	case EvictSize:
+		return "size"
+	default:
+		return "unknown"
+	}
+}
+
+type eviction struct {
+	key    string
+	reason EvictReason
+}
+
+// EvictionNotifier runs the OnEvict callback from its own goroutine fed by a bounded queue, the storage
+// operations never wait for the callback and the notifications are dropped with a warning once the queue is full.
+type EvictionNotifier struct {
+	callback func(key string, reason EvictReason)
+	logger   Logger
+	mu       sync.RWMutex
+	queue    chan eviction
+	closed   bool
+	done     chan struct{}
+}
+
+// NewEvictionNotifier starts the notifier of the callback, nil is returned when the callback is nil.
+// The nil notifier ignores the notifications.
+func NewEvictionNotifier(callback func(key string, reason EvictReason), logger Logger) *EvictionNotifier {
+	if callback == nil {
+		return nil
+	}
+
+	n := &EvictionNotifier{
+		callback: callback,
+		logger:   logger,
+		queue:    make(chan eviction, evictionQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(n.done)
+
+		for evicted := range n.queue {
+			n.run(evicted)
+		}
+	}()
+
+	return n
+}
+
+// run calls the callback, a panic is logged instead of taking the process down.
+func (n *EvictionNotifier) run(evicted eviction) {
+	defer func() {
+		if r := recover(); r != nil {
+			n.logger.Errorf("The eviction callback panicked for the key %s, %v", evicted.key, r)
+		}
+	}()
+
+	n.callback(evicted.key, evicted.reason)
+}
+
+// Notify queues the eviction of the key without blocking, it's ignored once the notifier is closed.
+func (n *EvictionNotifier) Notify(key string, reason EvictReason) {
+	if n == nil {
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return
+	}
+
+	select {
+	case n.queue <- eviction{key: key, reason: reason}:
+	default:
+		n.logger.Warnf("The eviction queue is full, the %s eviction of the key %s is not notified.", reason, key)
+	}
+}
+
+// Close stops the notifier once the queued notifications are delivered.
+func (n *EvictionNotifier) Close() {
+	if n == nil {
+		return
+	}
+
+	n.mu.Lock()
+
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+
+	n.mu.Unlock()
+
+	<-n.done
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// ExportBatchSize is the number of records stored per SetMany call on import
+	// and the page size used to scan the keys on export.
+	ExportBatchSize = 1000
+	// ImportNoExpirationTTL is the duration given to the records exported without expiry,
+	// a non-positive duration removes the key in most backends.
+	ImportNoExpirationTTL = 365 * 24 * time.Hour
+)
+
+// gzipMagic starts the gzip streams, its deflate method byte included.
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// ExportWriter writes the length-prefixed export records. Each record is the uvarint length of
+// the key, the key, the uvarint length of the value, the value and the varint remaining time to
+// live in nanoseconds, NoExpiration for a key without expiry.
+type ExportWriter struct {
+	writer *bufio.Writer
+	buf    []byte
+}
+
+// NewExportWriter returns an ExportWriter buffering the records to w, Flush must be called once done.
+func NewExportWriter(w io.Writer) *ExportWriter {
+	return &ExportWriter{writer: bufio.NewWriter(w)}
+}
+
+// Write appends the record to the export, the already expired keys are skipped.
+func (e *ExportWriter) Write(key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 && ttl != NoExpiration {
+		return nil
+	}
+
+	e.buf = binary.AppendUvarint(e.buf[:0], uint64(len(key)))
+	e.buf = append(e.buf, key...)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(value)))
+
+	if _, err := e.writer.Write(e.buf); err != nil {
+		return err
+	}
+
+	if _, err := e.writer.Write(value); err != nil {
+		return err
+	}
+
+	e.buf = binary.AppendVarint(e.buf[:0], int64(ttl))
+	_, err := e.writer.Write(e.buf)
+
+	return err
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (e *ExportWriter) Flush() error {
+	return e.writer.Flush()
+}
+
+// ExportKeys writes every key of the storer to w, paginating with ScanKeys for the backends
+// without a native iteration. The keys a backend can't enumerate are not exported. The user
+// keys are scanned then the internal keys of each of the InternalKeyPrefixes.
+func ExportKeys(storer Storer, w io.Writer) error {
+	exporter := NewExportWriter(w)
+
+	for _, prefix := range append([]string{""}, InternalKeyPrefixes...) {
+		if err := exportPrefix(storer, exporter, prefix); err != nil {
+			return err
+		}
+	}
+
+	return exporter.Flush()
+}
+
+// exportPrefix writes the keys listed by ScanKeys for the prefix.
+func exportPrefix(storer Storer, exporter *ExportWriter, prefix string) error {
+	cursor := ""
+
+	for {
+		keys, next := storer.ScanKeys(prefix, cursor, ExportBatchSize)
+		values := storer.GetMany(keys)
+
+		for _, key := range keys {
+			value, found := values[key]
+			if !found {
+				continue
+			}
+
+			ttl, found := storer.GetTTL(key)
+			if !found {
+				continue
+			}
+
+			if err := exporter.Write(key, value, ttl); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		cursor = next
+	}
+}
+
+// exportVerified streams the Export of the storer to w, the value of each record is checked by verify first
+// and the export stops with its error. The internal keys and the counters are stored by the decorators as
+// is, they're exported without check.
+func exportVerified(storer Storer, w io.Writer, verify func(value []byte) error) error {
+	reader, writer := io.Pipe()
+	go func() { _ = writer.CloseWithError(storer.Export(writer)) }()
+
+	defer func() { _ = reader.Close() }()
+
+	records := bufio.NewReader(reader)
+	exporter := NewExportWriter(w)
+
+	for {
+		key, value, ttl, err := readRecord(records)
+		if errors.Is(err, io.EOF) {
+			return exporter.Flush()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !IsInternalKey(key) && len(value) != counterSize {
+			if err = verify(value); err != nil {
+				return fmt.Errorf("the exported key %s is invalid: %w", key, err)
+			}
+		}
+
+		if err = exporter.Write(key, value, ttl); err != nil {
+			return err
+		}
+	}
+}
+
+// GzipExport writes the Export stream of the storer to w gzipped, ImportRecords detects it.
+func GzipExport(storer Storer, w io.Writer) error {
+	writer := gzip.NewWriter(w)
+
+	if err := storer.Export(writer); err != nil {
+		_ = writer.Close()
+
+		return err
+	}
+
+	return writer.Close()
+}
+
+type gzipExportStorer struct {
+	Storer
+}
+
+func (s *gzipExportStorer) Export(w io.Writer) error {
+	return GzipExport(s.Storer, w)
+}
+
+// ImportRecords reads the records written by an ExportWriter and hands them to store by batches
+// of ExportBatchSize. The remaining time to live of each record is used as its duration. A stream
+// starting with the gzip magic bytes is decompressed first, see GzipExport.
+func ImportRecords(r io.Reader, store func(items map[string]Entry) error) error {
+	reader := bufio.NewReader(r)
+
+	if magic, _ := reader.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gzipped, err := gzip.NewReader(reader)
+		if err != nil {
+			return malformedRecord(err)
+		}
+
+		defer func() { _ = gzipped.Close() }()
+
+		reader = bufio.NewReader(gzipped)
+	}
+
+	items := make(map[string]Entry, ExportBatchSize)
+
+	for {
+		key, value, ttl, err := readRecord(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if ttl == NoExpiration {
+			ttl = ImportNoExpirationTTL
+		}
+
+		items[key] = Entry{Value: value, Duration: ttl}
+
+		if len(items) == ExportBatchSize {
+			if err = store(items); err != nil {
+				return err
+			}
+
+			items = make(map[string]Entry, ExportBatchSize)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	return store(items)
+}
+
+// readRecord returns io.EOF only when the reader ends between two records.
+func readRecord(reader *bufio.Reader) (string, []byte, time.Duration, error) {
+	keyLength, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	key, err := readChunk(reader, keyLength)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	valueLength, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return "", nil, 0, malformedRecord(err)
+	}
+
+	value, err := readChunk(reader, valueLength)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	ttl, err := binary.ReadVarint(reader)
+	if err != nil {
+		return "", nil, 0, malformedRecord(err)
+	}
+
+	return string(key), value, time.Duration(ttl), nil
+}
+
+func readChunk(reader *bufio.Reader, length uint64) ([]byte, error) {
+	if length > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: %d bytes chunk", ErrMalformedExport, length)
+	}
+
+	chunk := make([]byte, length)
+	if _, err := io.ReadFull(reader, chunk); err != nil {
+		return nil, malformedRecord(err)
+	}
+
+	return chunk, nil
+}
+
+func malformedRecord(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fmt.Errorf("%w: %w", ErrMalformedExport, err)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type fillCall struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// The fills in flight are shared by the callers missing the same key of the same storage in the process.
+var (
+	fillMu    sync.Mutex
+	fillCalls = map[string]*fillCall{}
+)
+
+// GetOrSet returns the stored value of the key or the one returned by fill, stored for the duration. The
+// concurrent misses of the key in the process wait for a single fill call and share its result, the fill
+// error is returned without storing anything. The value is stored with SetNX so a value stored meanwhile
+// by another process is kept and returned instead.
+func GetOrSet(storer Storer, key string, duration time.Duration, fill func() ([]byte, error)) ([]byte, error) {
+	value, err := storer.GetWithError(key)
+	if err == nil {
+		return value, nil
+	}
+
+	if !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+
+	flight := storer.Uuid() + "\x00" + key
+
+	fillMu.Lock()
+
+	if call, found := fillCalls[flight]; found {
+		fillMu.Unlock()
+		<-call.done
+
+		return call.value, call.err
+	}
+
+	call := &fillCall{done: make(chan struct{})}
+	fillCalls[flight] = call
+
+	fillMu.Unlock()
+
+	defer func() {
+		fillMu.Lock()
+		delete(fillCalls, flight)
+		fillMu.Unlock()
+
+		close(call.done)
+	}()
+
+	call.value, call.err = fillKey(storer, key, duration, fill)
+
+	return call.value, call.err
+}
+
+func fillKey(storer Storer, key string, duration time.Duration, fill func() ([]byte, error)) ([]byte, error) {
+	// A flight completed between the miss and the registration of this one has stored the key.
+	value, err := storer.GetWithError(key)
+	if err == nil || !errors.Is(err, ErrKeyNotFound) {
+		return value, err
+	}
+
+	if value, err = fill(); err != nil {
+		return nil, err
+	}
+
+	stored, err := storer.SetNX(key, value, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored {
+		return value, nil
+	}
+
+	// Another process stored the key since the miss.
+	current, err := storer.GetWithError(key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return value, nil
+	}
+
+	return current, err
+}
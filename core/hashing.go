@@ -0,0 +1,356 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	// KeyHashingNone stores the keys as is.
+	KeyHashingNone = "none"
+	// KeyHashingSHA256 stores the keys as the 64 hexadecimal characters of their SHA-256 digest.
+	KeyHashingSHA256 = "sha256"
+	// KeyHashingXXHash stores the keys as the 16 hexadecimal characters of their 64-bit xxHash digest.
+	KeyHashingXXHash = "xxhash"
+
+	// HashedKeyPrefix prefixes the reverse index entries storing the original key of each hashed key.
+	HashedKeyPrefix = "HASHED_"
+)
+
+// KeyHashed wraps the storer to store the entries under a hash of their key, bounding the key length.
+// A reverse index entry maps each hashed key to the original one, with the same time to live, to keep
+// MapKeys, ScanKeys and DeleteMany working at the cost of an extra write per stored key. The counters
+// stored without expiry are indexed for ImportNoExpirationTTL.
+//
+// The collisions are not detected, two colliding keys share the same entry. SHA-256 makes them
+// practically impossible while xxhash, faster and shorter, should be limited to the caches holding
+// far fewer than 2^32 keys, the birthday bound of a 64-bit digest.
+// The multi-level mapping keeps the real keys in clear and only the varied keys are indexed.
+// An unknown algorithm returns an error wrapping ErrInvalidConfig, KeyHashingNone returns the storer as is.
+func KeyHashed(storer Storer, algorithm string) (Storer, error) {
+	switch algorithm {
+	case "", KeyHashingNone:
+		return storer, nil
+	case KeyHashingSHA256:
+		return &hashedStorer{Storer: storer, hash: func(key string) string {
+			sum := sha256.Sum256([]byte(key))
+
+			return hex.EncodeToString(sum[:])
+		}}, nil
+	case KeyHashingXXHash:
+		return &hashedStorer{Storer: storer, hash: func(key string) string {
+			return fmt.Sprintf("%016x", xxhash.Sum64String(key))
+		}}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown key hashing %q", ErrInvalidConfig, algorithm)
+}
+
+type hashedStorer struct {
+	Storer
+	hash func(key string) string
+}
+
+// index stores the reverse index entry of the key, a non-positive duration removes it.
+func (s *hashedStorer) index(key string, duration time.Duration) error {
+	hashed := HashedKeyPrefix + s.hash(key)
+
+	if duration <= 0 {
+		s.Storer.Delete(hashed)
+
+		return nil
+	}
+
+	return s.Storer.Set(hashed, []byte(key), duration)
+}
+
+// originals returns the original keys matching the prefix by hashed key.
+func (s *hashedStorer) originals(prefix string) map[string]string {
+	originals := map[string]string{}
+
+	for hashed, key := range s.Storer.MapKeys(HashedKeyPrefix) {
+		if strings.HasPrefix(key, prefix) && ListedKey(prefix, key) {
+			originals[hashed] = key
+		}
+	}
+
+	return originals
+}
+
+// unhashed returns the original keys of the hashed ones read from their reverse index entries, the hashed
+// keys without entry are kept as is.
+func (s *hashedStorer) unhashed(hashes []string) map[string]string {
+	indexKeys := make([]string, 0, len(hashes))
+	for _, hashed := range hashes {
+		indexKeys = append(indexKeys, HashedKeyPrefix+hashed)
+	}
+
+	stored := s.Storer.GetMany(indexKeys)
+	originals := make(map[string]string, len(hashes))
+
+	for _, hashed := range hashes {
+		originals[hashed] = hashed
+		if key, found := stored[HashedKeyPrefix+hashed]; found {
+			originals[hashed] = string(key)
+		}
+	}
+
+	return originals
+}
+
+func (s *hashedStorer) MapKeys(prefix string) map[string]string {
+	originals := s.originals(prefix)
+	hashes := make([]string, 0, len(originals))
+
+	for hashed := range originals {
+		hashes = append(hashes, hashed)
+	}
+
+	keys := map[string]string{}
+
+	for hashed, value := range s.Storer.GetMany(hashes) {
+		keys[strings.TrimPrefix(originals[hashed], prefix)] = string(value)
+	}
+
+	return keys
+}
+
+func (s *hashedStorer) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	originals := s.originals(prefix)
+	keys = make([]string, 0, len(originals))
+
+	for _, key := range originals {
+		keys = append(keys, key)
+	}
+
+	return PaginateKeys(keys, prefix, cursor, limit)
+}
+
+func (s *hashedStorer) Get(key string) []byte {
+	return s.Storer.Get(s.hash(key))
+}
+
+func (s *hashedStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	return s.Storer.GetContext(ctx, s.hash(key))
+}
+
+func (s *hashedStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *hashedStorer) GetMany(keys []string) map[string][]byte {
+	originals := make(map[string]string, len(keys))
+	hashes := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		hashed := s.hash(key)
+		originals[hashed] = key
+		hashes = append(hashes, hashed)
+	}
+
+	values := s.Storer.GetMany(hashes)
+	result := make(map[string][]byte, len(values))
+
+	for hashed, value := range values {
+		result[originals[hashed]] = value
+	}
+
+	return result
+}
+
+func (s *hashedStorer) GetTTL(key string) (time.Duration, bool) {
+	return s.Storer.GetTTL(s.hash(key))
+}
+
+func (s *hashedStorer) Exists(key string) bool {
+	return s.Storer.Exists(s.hash(key))
+}
+
+func (s *hashedStorer) Set(key string, value []byte, duration time.Duration) error {
+	if err := s.Storer.Set(s.hash(key), value, duration); err != nil {
+		return err
+	}
+
+	return s.index(key, duration)
+}
+
+func (s *hashedStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := s.Storer.SetContext(ctx, s.hash(key), value, duration); err != nil {
+		return err
+	}
+
+	return s.index(key, duration)
+}
+
+func (s *hashedStorer) SetMany(items map[string]Entry) error {
+	hashed := make(map[string]Entry, 2*len(items))
+
+	for key, item := range items {
+		hashed[s.hash(key)] = item
+		hashed[HashedKeyPrefix+s.hash(key)] = Entry{Value: []byte(key), Duration: item.Duration}
+	}
+
+	return s.Storer.SetMany(hashed)
+}
+
+func (s *hashedStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	created, err := s.Storer.SetNX(s.hash(key), value, duration)
+	if !created || err != nil {
+		return created, err
+	}
+
+	return true, s.index(key, duration)
+}
+
+func (s *hashedStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	swapped, err := s.Storer.CompareAndSwap(s.hash(key), old, value, duration)
+	if !swapped || err != nil {
+		return swapped, err
+	}
+
+	return true, s.index(key, duration)
+}
+
+func (s *hashedStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if err := s.Storer.SetWithMeta(s.hash(key), value, meta, duration); err != nil {
+		return err
+	}
+
+	return s.index(key, duration)
+}
+
+func (s *hashedStorer) GetMeta(key string) (map[string]string, error) {
+	return s.Storer.GetMeta(s.hash(key))
+}
+
+func (s *hashedStorer) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := s.Storer.Increment(s.hash(key), delta, duration)
+	if err != nil {
+		return value, err
+	}
+
+	if duration <= 0 {
+		duration = ImportNoExpirationTTL
+	}
+
+	return value, s.index(key, duration)
+}
+
+func (s *hashedStorer) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return s.Increment(key, -delta, duration)
+}
+
+func (s *hashedStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	if err := s.Storer.SetStream(s.hash(key), reader, duration); err != nil {
+		return err
+	}
+
+	return s.index(key, duration)
+}
+
+func (s *hashedStorer) GetStream(key string) (io.ReadCloser, error) {
+	return s.Storer.GetStream(s.hash(key))
+}
+
+func (s *hashedStorer) Touch(key string, duration time.Duration) error {
+	if err := s.Storer.Touch(s.hash(key), duration); err != nil {
+		return err
+	}
+
+	return s.Storer.Touch(HashedKeyPrefix+s.hash(key), duration)
+}
+
+func (s *hashedStorer) Delete(key string) {
+	s.Storer.Delete(s.hash(key))
+	s.Storer.Delete(HashedKeyPrefix + s.hash(key))
+}
+
+func (s *hashedStorer) DeleteKeys(keys []string) error {
+	hashed := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		hashed = append(hashed, s.hash(key), HashedKeyPrefix+s.hash(key))
+	}
+
+	return s.Storer.DeleteKeys(hashed)
+}
+
+func (s *hashedStorer) DeleteMany(pattern string) {
+	for hashed := range s.originals(KeyPrefix(pattern)) {
+		s.Storer.Delete(hashed)
+		s.Storer.Delete(HashedKeyPrefix + hashed)
+	}
+}
+
+// GetBySurrogate keys the values by their original varied key.
+func (s *hashedStorer) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	values, err := s.Storer.GetBySurrogate(surrogateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(values))
+	for hashed := range values {
+		hashes = append(hashes, hashed)
+	}
+
+	originals := s.unhashed(hashes)
+	entries := make(map[string][]byte, len(values))
+
+	for hashed, value := range values {
+		entries[originals[hashed]] = value
+	}
+
+	return entries, nil
+}
+
+// Inspect reports the inspected key and the varied keys as the caller knows them.
+func (s *hashedStorer) Inspect(key string) (*EntryInfo, error) {
+	info, err := s.Storer.Inspect(s.hash(key))
+	if err != nil {
+		return nil, err
+	}
+
+	originals := s.unhashed(info.VariedKeys)
+	for i, variedKey := range info.VariedKeys {
+		info.VariedKeys[i] = originals[variedKey]
+	}
+
+	info.Key = key
+	slices.Sort(info.VariedKeys)
+
+	return info, nil
+}
+
+func (s *hashedStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return s.Storer.GetMultiLevel(s.hash(key), req, validator)
+}
+
+func (s *hashedStorer) GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch) {
+	return s.Storer.GetMultiLevelDebug(s.hash(key), req, validator)
+}
+
+func (s *hashedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if err := s.Storer.SetMultiLevel(s.hash(baseKey), s.hash(variedKey), value, variedHeaders, etag, duration, realKey); err != nil {
+		return err
+	}
+
+	// The response is kept for its stale duration too, the reverse index expires with it.
+	ttl, found := s.Storer.GetTTL(s.hash(variedKey))
+	switch {
+	case !found:
+		ttl = duration
+	case ttl == NoExpiration:
+		ttl = ImportNoExpirationTTL
+	}
+
+	return s.index(variedKey, ttl)
+}
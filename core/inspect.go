@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EntryInfo describes a stored key, see InspectEntry.
+type EntryInfo struct {
+	// Key is the inspected key.
+	Key string
+	// ValueLength is the length of the value once decompressed, the stored length when it isn't compressed.
+	ValueLength int
+	// CompressedSize is the length of the stored value, zero when only the key mapping is stored.
+	CompressedSize int
+	// TTL is the remaining time to live of the value, NoExpiration when it never expires. The multi-level key
+	// without value reports the longest time its mapped responses remain usable, fresh or stale.
+	TTL time.Duration
+	// CreatedAt is the last time SetMultiLevel stored a response mapped by the key, zero when it isn't a
+	// multi-level key as the storages don't record the creation of the other keys.
+	CreatedAt time.Time
+	// Codec is the codec of the stored value, as returned by ConfiguredCompression, empty when the value
+	// isn't compressed by the storage like the ones stored with Set.
+	Codec string
+	// VariedKeys are the sorted keys of the responses mapped by the key when it's a multi-level one.
+	VariedKeys []string
+	// SurrogateKeys are the sorted surrogate keys declared by the stored response and the mapped ones.
+	SurrogateKeys []string
+}
+
+// InspectEntry returns the details of the key read through the storer Get and GetTTL, nothing is written.
+// The key is inspected as a value and as the base key of a multi-level mapping decoded by the mapper, the
+// responses it maps are read for their surrogate keys. ErrKeyNotFound is returned when neither is stored.
+func InspectEntry(storer Storer, mapper Mapper, key string) (*EntryInfo, error) {
+	info := &EntryInfo{Key: key, VariedKeys: []string{}, SurrogateKeys: []string{}}
+
+	value, err := storer.GetWithError(key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+
+	found := err == nil
+	if found {
+		info.CompressedSize = len(value)
+		info.TTL, _ = storer.GetTTL(key)
+		info.Codec, info.ValueLength = inspectValue(value)
+		info.SurrogateKeys = append(info.SurrogateKeys, storedSurrogateKeys(value)...)
+	}
+
+	if stored := storer.Get(MappingKeyPrefix + key); len(stored) > 0 {
+		mapping, err := mapper.Decode(stored)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
+		}
+
+		now := mapperClock(mapper).Now()
+
+		for variedKey, keyIndex := range mapping.GetMapping() {
+			info.VariedKeys = append(info.VariedKeys, variedKey)
+
+			if !found {
+				info.TTL = max(info.TTL, keyIndex.GetStaleTime().AsTime().Sub(now))
+			}
+
+			if storedAt := keyIndex.GetStoredAt(); storedAt != nil && storedAt.AsTime().After(info.CreatedAt) {
+				info.CreatedAt = storedAt.AsTime()
+			}
+
+			if variedKey != key {
+				info.SurrogateKeys = append(info.SurrogateKeys, storedSurrogateKeys(storer.Get(variedKey))...)
+			}
+		}
+
+		found = true
+	}
+
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+
+	slices.Sort(info.VariedKeys)
+	slices.Sort(info.SurrogateKeys)
+	info.SurrogateKeys = slices.Compact(info.SurrogateKeys)
+
+	return info, nil
+}
+
+// storedCodec returns the codec recorded by Compress in the data, empty when none is recognized.
+func storedCodec(data []byte) string {
+	if bytes.HasPrefix(data, lz4Magic) {
+		return CompressionLZ4
+	}
+
+	if len(data) == 0 {
+		return ""
+	}
+
+	switch data[0] {
+	case noneHeader:
+		return CompressionNone
+	case zstdHeader:
+		return CompressionZstd
+	case zstdDictHeader:
+		if len(data) > dictionaryIDSize {
+			return CompressionZstd + optionSeparator + fmt.Sprintf("%08x", binary.BigEndian.Uint32(data[1:1+dictionaryIDSize]))
+		}
+	}
+
+	return ""
+}
+
+// inspectValue returns the codec and the decompressed length of the stored value, the value that can't be
+// decompressed is reported as stored uncompressed.
+func inspectValue(value []byte) (string, int) {
+	codec := storedCodec(value)
+	if codec == "" {
+		return "", len(value)
+	}
+
+	decompressed, err := Decompress(value)
+	if err != nil {
+		return "", len(value)
+	}
+
+	return codec, len(decompressed)
+}
+
+// storedSurrogateKeys returns the surrogate keys declared by the stored compressed response, none when the
+// value isn't one.
+func storedSurrogateKeys(value []byte) []string {
+	if storedCodec(value) == "" {
+		return nil
+	}
+
+	response, err := Decompress(value)
+	if err != nil {
+		return nil
+	}
+
+	return SurrogateKeys(response)
+}
@@ -0,0 +1,50 @@
+package core
+
+import "context"
+
+// KV is an entry streamed by Iterator.Iterate.
+type KV struct {
+	Key   string
+	Value []byte
+}
+
+// Iterator is implemented by the storers able to stream all their entries.
+type Iterator interface {
+	// Iterate streams every live entry, the namespace excepted, with its stored value. The entries are read
+	// one at a time as the channel is consumed so the memory holds a single value, the store may be written
+	// meanwhile. Both channels are closed once the store is exhausted or the context is done, the error
+	// channel receives the context error or the backend one first.
+	Iterate(ctx context.Context) (<-chan KV, <-chan error)
+}
+
+// IterateWith runs iterate in its own goroutine and returns the channels of Iterator.Iterate. The yield
+// function given to iterate blocks until the entry is received and returns the context error once done,
+// iterate must stop and return it.
+func IterateWith(ctx context.Context, iterate func(yield func(KV) error) error) (<-chan KV, <-chan error) {
+	entries := make(chan KV)
+	errs := make(chan error, 1)
+
+	yield := func(entry KV) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case entries <- entry:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	go func() {
+		defer close(errs)
+		defer close(entries)
+
+		if err := iterate(yield); err != nil {
+			errs <- err
+		}
+	}()
+
+	return entries, errs
+}
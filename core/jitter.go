@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TTLJitter spreads the durations by a random fraction so the entries written together don't expire together.
+type TTLJitter struct {
+	fraction float64
+	mu       sync.Mutex
+	random   *rand.Rand
+}
+
+// NewTTLJitter returns the jitter spreading the durations by up to the fraction in both directions, 0.1 for
+// ±10%. The same non-zero seed reproduces the same durations, a random seed is used when zero.
+// The nil jitter returned for a non-positive fraction keeps the durations as is.
+func NewTTLJitter(fraction float64, seed uint64) *TTLJitter {
+	if fraction <= 0 {
+		return nil
+	}
+
+	if seed == 0 {
+		seed = rand.Uint64() //nolint:gosec
+	}
+
+	return &TTLJitter{fraction: fraction, random: rand.New(rand.NewPCG(seed, seed))} //nolint:gosec
+}
+
+// Apply method will return the jittered duration, the non-positive durations are kept as is.
+func (j *TTLJitter) Apply(duration time.Duration) time.Duration {
+	if j == nil || duration <= 0 {
+		return duration
+	}
+
+	j.mu.Lock()
+	r := j.random.Float64()
+	j.mu.Unlock()
+
+	return max(time.Duration(float64(duration)*(1+j.fraction*(2*r-1))), time.Nanosecond)
+}
+
+type jitteredStorer struct {
+	Storer
+	jitter *TTLJitter
+}
+
+func (s *jitteredStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.Storer.Set(key, value, s.jitter.Apply(duration))
+}
+
+func (s *jitteredStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.Storer.SetContext(ctx, key, value, s.jitter.Apply(duration))
+}
+
+// SetMany jitters each entry on its own.
+func (s *jitteredStorer) SetMany(items map[string]Entry) error {
+	jittered := make(map[string]Entry, len(items))
+	for key, item := range items {
+		jittered[key] = Entry{Value: item.Value, Duration: s.jitter.Apply(item.Duration)}
+	}
+
+	return s.Storer.SetMany(jittered)
+}
+
+func (s *jitteredStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	return s.Storer.SetNX(key, value, s.jitter.Apply(duration))
+}
+
+func (s *jitteredStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	return s.Storer.CompareAndSwap(key, old, value, s.jitter.Apply(duration))
+}
+
+func (s *jitteredStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return s.Storer.SetWithMeta(key, value, meta, s.jitter.Apply(duration))
+}
+
+func (s *jitteredStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	return s.Storer.SetStream(key, reader, s.jitter.Apply(duration))
+}
+
+func (s *jitteredStorer) Touch(key string, duration time.Duration) error {
+	return s.Storer.Touch(key, s.jitter.Apply(duration))
+}
+
+// SetMultiLevel jitters the duration the storages derive from the Cache-Control directives of the varied headers,
+// the fresh one when none is set, and hands it along the headers, see MultiLevelTTL. The mapping fresh and stale
+// times move along the stored entry expiry, the mapped varied headers are kept as is.
+func (s *jitteredStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if ttl, cacheable := MultiLevelTTL(variedHeaders, duration); cacheable {
+		duration = s.jitter.Apply(ttl)
+		variedHeaders = withInternalHeader(variedHeaders, multiLevelTTLKey, duration.String())
+	}
+
+	return s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
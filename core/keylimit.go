@@ -0,0 +1,415 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxKeysReject rejects the writes of new keys with ErrKeyLimit once MaxKeys is reached.
+	MaxKeysReject = "reject"
+	// MaxKeysEvictOldest deletes the least recently written key to make room for a new one once MaxKeys is reached.
+	MaxKeysEvictOldest = "evict_oldest"
+)
+
+// keyLimitedStorer bounds the number of keys written through it. The keys are tracked in memory in their
+// write order, seeded on the first write with the keys already stored sorted as the oldest ones, and the count
+// is kept by the writes and deletions made through the instance. The keys expired or deleted by someone else
+// are only dropped when they reach the front of the order, so the limit may be reached a bit early until then.
+type keyLimitedStorer struct {
+	Storer
+	limit  int
+	policy string
+	mapper Mapper
+	seeded sync.Once
+	mu     sync.Mutex
+	keys   map[string]*list.Element
+	order  *list.List
+	// bases are the base keys of the varied keys written by SetMultiLevel, to unmap them once evicted.
+	bases map[string]string
+}
+
+// KeyLimited bounds the number of keys of the storer, the policy tells what happens to the new keys once the
+// limit is reached, MaxKeysReject when empty. The mapper decodes the mappings of the evicted varied keys, the
+// ProtobufMapper when nil. A non-positive limit returns the storer as is.
+func KeyLimited(storer Storer, limit int, policy string, mapper Mapper) Storer {
+	if limit <= 0 {
+		return storer
+	}
+
+	return &keyLimitedStorer{
+		Storer: storer,
+		limit:  limit,
+		policy: policy,
+		mapper: MapperOrDefault(mapper),
+		keys:   map[string]*list.Element{},
+		order:  list.New(),
+		bases:  map[string]string{},
+	}
+}
+
+// seed tracks the keys already stored before the ones written through the instance.
+func (s *keyLimitedStorer) seed() {
+	stored := s.Storer.SortedKeys("")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(stored) - 1; i >= 0; i-- {
+		if _, found := s.keys[stored[i]]; !found {
+			s.keys[stored[i]] = s.order.PushFront(stored[i])
+		}
+	}
+}
+
+// reserve tracks the keys before their write and returns the new ones, to release if the write fails.
+// The keys already tracked are moved to the back first so making room never drops them. The backend is
+// only queried and the evicted keys only deleted outside of the lock.
+func (s *keyLimitedStorer) reserve(keys ...string) ([]string, error) {
+	s.seeded.Do(s.seed)
+
+	s.mu.Lock()
+
+	added, overflow, err := s.room(keys)
+	if err == nil && overflow > 0 && s.policy != MaxKeysEvictOldest {
+		// The oldest keys expired or deleted by someone else make room, checked without the lock.
+		oldest := s.oldest(overflow)
+		s.mu.Unlock()
+		s.forgetMissing(oldest)
+		s.mu.Lock()
+
+		if added, overflow, err = s.room(keys); err == nil && overflow > 0 {
+			err = fmt.Errorf("%w: the %d keys limit is reached", ErrKeyLimit, s.limit)
+		}
+	}
+
+	if err != nil {
+		s.mu.Unlock()
+
+		return nil, err
+	}
+
+	evicted := map[string]string{}
+
+	for _, key := range s.oldest(overflow) {
+		evicted[key] = s.bases[key]
+		s.untrack(key)
+	}
+
+	for _, key := range added {
+		s.keys[key] = s.order.PushBack(key)
+	}
+
+	s.mu.Unlock()
+
+	for key, baseKey := range evicted {
+		s.evict(key, baseKey)
+	}
+
+	return added, nil
+}
+
+// room moves the tracked keys to the back and returns the new ones with the number of keys to drop to fit
+// them. The caller holds the lock.
+func (s *keyLimitedStorer) room(keys []string) ([]string, int, error) {
+	added := []string{}
+
+	for _, key := range keys {
+		if element, found := s.keys[key]; found {
+			s.order.MoveToBack(element)
+		} else if !slices.Contains(added, key) {
+			added = append(added, key)
+		}
+	}
+
+	if len(added) > s.limit {
+		return nil, 0, fmt.Errorf("%w: %d new keys over the %d keys limit", ErrKeyLimit, len(added), s.limit)
+	}
+
+	return added, len(s.keys) + len(added) - s.limit, nil
+}
+
+// oldest returns up to count keys from the front of the order. The caller holds the lock.
+func (s *keyLimitedStorer) oldest(count int) []string {
+	keys := []string{}
+
+	for element := s.order.Front(); element != nil && len(keys) < count; element = element.Next() {
+		keys = append(keys, element.Value.(string))
+	}
+
+	return keys
+}
+
+// untrack forgets the key. The caller holds the lock.
+func (s *keyLimitedStorer) untrack(key string) {
+	if element, found := s.keys[key]; found {
+		s.order.Remove(element)
+		delete(s.keys, key)
+		delete(s.bases, key)
+	}
+}
+
+// forgetMissing forgets the keys no longer stored.
+func (s *keyLimitedStorer) forgetMissing(keys []string) {
+	for _, key := range keys {
+		if !s.Storer.Exists(key) {
+			s.forget(key)
+		}
+	}
+}
+
+// evict deletes the key and removes it from the mapping of its base key, the one written by SetMultiLevel or
+// the key before the VarySeparator. The mapping is swapped so a concurrent update of it is kept, the mapping
+// left without any varied key is deleted.
+func (s *keyLimitedStorer) evict(key, baseKey string) {
+	s.Storer.Delete(key)
+
+	if baseKey == "" {
+		baseKey, _, _ = strings.Cut(key, VarySeparator)
+	}
+
+	mappingKey := MappingKeyPrefix + baseKey
+
+	stored := s.Storer.Get(mappingKey)
+	if len(stored) == 0 {
+		return
+	}
+
+	mapping, err := s.mapper.Decode(stored)
+	if err != nil {
+		return
+	}
+
+	if _, found := mapping.GetMapping()[key]; !found {
+		return
+	}
+
+	delete(mapping.Mapping, key)
+
+	if len(mapping.GetMapping()) == 0 {
+		s.Storer.Delete(mappingKey)
+
+		return
+	}
+
+	updated, err := s.mapper.Encode(mapping)
+	if err != nil {
+		return
+	}
+
+	ttl, found := s.Storer.GetTTL(mappingKey)
+	if !found {
+		return
+	}
+
+	if ttl == NoExpiration {
+		ttl = ImportNoExpirationTTL
+	}
+
+	_, _ = s.Storer.CompareAndSwap(mappingKey, stored, updated, ttl)
+}
+
+func (s *keyLimitedStorer) forget(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.untrack(key)
+	}
+}
+
+// release forgets the reserved keys when the write failed or didn't store them.
+func (s *keyLimitedStorer) release(added []string, stored bool, err error) {
+	for _, key := range added {
+		if err != nil || (!stored && !s.Storer.Exists(key)) {
+			s.forget(key)
+		}
+	}
+}
+
+func (s *keyLimitedStorer) Set(key string, value []byte, duration time.Duration) error {
+	added, err := s.reserve(key)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.Set(key, value, duration)
+	s.release(added, true, err)
+
+	return err
+}
+
+func (s *keyLimitedStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	added, err := s.reserve(key)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.SetContext(ctx, key, value, duration)
+	s.release(added, true, err)
+
+	return err
+}
+
+func (s *keyLimitedStorer) SetMany(items map[string]Entry) error {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	added, err := s.reserve(keys...)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.SetMany(items)
+	s.release(added, true, err)
+
+	return err
+}
+
+func (s *keyLimitedStorer) Import(r io.Reader) error {
+	return ImportRecords(r, s.SetMany)
+}
+
+func (s *keyLimitedStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	added, err := s.reserve(key)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := s.Storer.SetNX(key, value, duration)
+	s.release(added, stored, err)
+
+	return stored, err
+}
+
+func (s *keyLimitedStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	added, err := s.reserve(key)
+	if err != nil {
+		return false, err
+	}
+
+	swapped, err := s.Storer.CompareAndSwap(key, old, value, duration)
+	s.release(added, swapped, err)
+
+	return swapped, err
+}
+
+func (s *keyLimitedStorer) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	added, err := s.reserve(key)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := s.Storer.Increment(key, delta, duration)
+	s.release(added, true, err)
+
+	return value, err
+}
+
+func (s *keyLimitedStorer) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	added, err := s.reserve(key)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := s.Storer.Decrement(key, delta, duration)
+	s.release(added, true, err)
+
+	return value, err
+}
+
+func (s *keyLimitedStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	added, err := s.reserve(key)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.SetStream(key, reader, duration)
+	s.release(added, true, err)
+
+	return err
+}
+
+func (s *keyLimitedStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	added, err := s.reserve(key)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.SetWithMeta(key, value, meta, duration)
+	s.release(added, true, err)
+
+	return err
+}
+
+// SetMultiLevel counts the varied key holding the response, the mapping of the base key isn't counted.
+func (s *keyLimitedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	added, err := s.reserve(variedKey)
+	if err != nil {
+		return err
+	}
+
+	err = s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+	s.release(added, true, err)
+
+	if err == nil {
+		s.mu.Lock()
+		if _, found := s.keys[variedKey]; found {
+			s.bases[variedKey] = baseKey
+		}
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+func (s *keyLimitedStorer) Delete(key string) {
+	s.Storer.Delete(key)
+	s.forget(key)
+}
+
+func (s *keyLimitedStorer) DeleteMany(pattern string) {
+	s.Storer.DeleteMany(pattern)
+
+	prefix := KeyPrefix(pattern)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.keys {
+		if strings.HasPrefix(key, prefix) {
+			s.untrack(key)
+		}
+	}
+}
+
+func (s *keyLimitedStorer) DeleteKeys(keys []string) error {
+	err := s.Storer.DeleteKeys(keys)
+	if err == nil {
+		s.forget(keys...)
+	}
+
+	return err
+}
+
+func (s *keyLimitedStorer) Reset() error {
+	err := s.Storer.Reset()
+	if err == nil {
+		s.mu.Lock()
+		s.keys = map[string]*list.Element{}
+		s.bases = map[string]string{}
+		s.order.Init()
+		s.mu.Unlock()
+	}
+
+	return err
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CheckValueSize returns ErrValueTooLarge when the value exceeds the limit, a non-positive limit means unlimited.
+func CheckValueSize(limit int64, value []byte) error {
+	if limit > 0 && int64(len(value)) > limit {
+		return fmt.Errorf("%w: %d bytes over the %d bytes limit", ErrValueTooLarge, len(value), limit)
+	}
+
+	return nil
+}
+
+type sizeLimitedStorer struct {
+	Storer
+	limit int64
+}
+
+func (s *sizeLimitedStorer) Set(key string, value []byte, duration time.Duration) error {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return err
+	}
+
+	return s.Storer.Set(key, value, duration)
+}
+
+func (s *sizeLimitedStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return err
+	}
+
+	return s.Storer.SetContext(ctx, key, value, duration)
+}
+
+func (s *sizeLimitedStorer) SetMany(items map[string]Entry) error {
+	for _, item := range items {
+		if err := CheckValueSize(s.limit, item.Value); err != nil {
+			return err
+		}
+	}
+
+	return s.Storer.SetMany(items)
+}
+
+func (s *sizeLimitedStorer) Import(r io.Reader) error {
+	return ImportRecords(r, s.SetMany)
+}
+
+func (s *sizeLimitedStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return false, err
+	}
+
+	return s.Storer.SetNX(key, value, duration)
+}
+
+func (s *sizeLimitedStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return false, err
+	}
+
+	return s.Storer.CompareAndSwap(key, old, value, duration)
+}
+
+func (s *sizeLimitedStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return err
+	}
+
+	return s.Storer.SetWithMeta(key, value, meta, duration)
+}
+
+// SetStream fails once the reader content exceeds the limit, before the value is stored.
+func (s *sizeLimitedStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	return s.Storer.SetStream(key, &limitedReader{reader: reader, remaining: s.limit}, duration)
+}
+
+func (s *sizeLimitedStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if err := CheckValueSize(s.limit, value); err != nil {
+		return err
+	}
+
+	return s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
+
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if r.remaining -= int64(n); r.remaining < 0 {
+		return n, fmt.Errorf("%w: the stream is over the limit", ErrValueTooLarge)
+	}
+
+	return n, err
+}
@@ -16,3 +16,21 @@ type Logger interface {
 	Panicf(template string, args ...interface{})
 	Fatalf(template string, args ...interface{})
 }
+
+// nopLogger discards the logs of the internal helpers called without any logger.
+type nopLogger struct{}
+
+func (nopLogger) Debug(...interface{})           {}
+func (nopLogger) Info(...interface{})            {}
+func (nopLogger) Warn(...interface{})            {}
+func (nopLogger) Error(...interface{})           {}
+func (nopLogger) DPanic(...interface{})          {}
+func (nopLogger) Panic(...interface{})           {}
+func (nopLogger) Fatal(...interface{})           {}
+func (nopLogger) Debugf(string, ...interface{})  {}
+func (nopLogger) Infof(string, ...interface{})   {}
+func (nopLogger) Warnf(string, ...interface{})   {}
+func (nopLogger) Errorf(string, ...interface{})  {}
+func (nopLogger) DPanicf(string, ...interface{}) {}
+func (nopLogger) Panicf(string, ...interface{})  {}
+func (nopLogger) Fatalf(string, ...interface{})  {}
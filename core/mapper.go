@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Mapper serializes the mapping linking a base key to its varied keys.
+type Mapper interface {
+	Encode(mapping *StorageMapper) ([]byte, error)
+	Decode(item []byte) (*StorageMapper, error)
+}
+
+// ProtobufMapper stores the mapping in the protobuf binary format, it's the default one.
+type ProtobufMapper struct{}
+
+// Encode method will marshal the mapping in the protobuf binary format.
+func (ProtobufMapper) Encode(mapping *StorageMapper) ([]byte, error) {
+	return proto.Marshal(mapping)
+}
+
+// Decode method will unmarshal the mapping from the protobuf binary format.
+func (ProtobufMapper) Decode(item []byte) (*StorageMapper, error) {
+	mapping := &StorageMapper{}
+	e := proto.Unmarshal(item, mapping)
+
+	return mapping, e
+}
+
+// JSONMapper stores the mapping as JSON, it's larger but human readable to debug the stored mappings.
+type JSONMapper struct{}
+
+// Encode method will marshal the mapping as JSON.
+func (JSONMapper) Encode(mapping *StorageMapper) ([]byte, error) {
+	return protojson.Marshal(mapping)
+}
+
+// Decode method will unmarshal the mapping from JSON.
+func (JSONMapper) Decode(item []byte) (*StorageMapper, error) {
+	mapping := &StorageMapper{}
+	e := protojson.Unmarshal(item, mapping)
+
+	return mapping, e
+}
+
+// MapperOrDefault returns the mapper, or the ProtobufMapper if it's nil.
+func MapperOrDefault(mapper Mapper) Mapper {
+	if mapper == nil {
+		return ProtobufMapper{}
+	}
+
+	return mapper
+}
+
+// configuredMapper carries the maximum number of variants per base key to MappingUpdaterWith and
+// the clock electing the fresh and stale variants to MappingElectionWith.
+type configuredMapper struct {
+	Mapper
+	limit int
+	clock Clock
+}
+
+// ConfiguredMapper returns the configured mapper, or the ProtobufMapper, limiting the number of
+// variants per base key to the MaxVariants of the configuration and electing the variants with its Clock.
+func ConfiguredMapper(cfg CacheProvider) Mapper {
+	mapper := MapperOrDefault(cfg.Mapper)
+	if cfg.MaxVariants > 0 || cfg.Clock != nil {
+		return configuredMapper{Mapper: mapper, limit: cfg.MaxVariants, clock: cfg.Clock}
+	}
+
+	return mapper
+}
+
+// mapperClock returns the clock carried by the mapper, or the SystemClock.
+func mapperClock(mapper Mapper) Clock {
+	if configured, ok := mapper.(configuredMapper); ok {
+		return ClockOrDefault(configured.clock)
+	}
+
+	return SystemClock{}
+}
+
+// admitVariant drops the variants of the mapping whose stale time is over and returns ErrVariantLimit
+// when the key is a new variant over the limit carried by the mapper. A stored variant is always replaced.
+func admitVariant(mapper Mapper, mapping *StorageMapper, key string, now time.Time) error {
+	for variant, index := range mapping.GetMapping() {
+		if variant != key && index.GetStaleTime() != nil && index.GetStaleTime().AsTime().Before(now) {
+			delete(mapping.Mapping, variant)
+		}
+	}
+
+	limited, ok := mapper.(configuredMapper)
+	if !ok || limited.limit <= 0 {
+		return nil
+	}
+
+	if _, found := mapping.GetMapping()[key]; !found && len(mapping.GetMapping()) >= limited.limit {
+		return fmt.Errorf("%w: %d variants already mapped", ErrVariantLimit, len(mapping.GetMapping()))
+	}
+
+	return nil
+}
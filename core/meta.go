@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+)
+
+// MetaNoExpiration is the ttl of a MetaRecord for a key without expiry.
+const MetaNoExpiration int64 = -1
+
+// MetaRecord is a line of the metadata dump, the size is the stored value length in bytes and the ttl the
+// remaining time to live in seconds rounded up, MetaNoExpiration for a key without expiry.
+type MetaRecord struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	TTL  int64  `json:"ttl"`
+}
+
+// MetaDumper is implemented by the storers able to list their keys metadata without loading the values.
+type MetaDumper interface {
+	DumpMeta(w io.Writer) error
+}
+
+// MetaWriter writes one JSON MetaRecord per line.
+type MetaWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewMetaWriter returns a MetaWriter buffering the records to w, Flush must be called once done.
+func NewMetaWriter(w io.Writer) *MetaWriter {
+	writer := bufio.NewWriter(w)
+
+	return &MetaWriter{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+// Write appends the record to the dump, the already expired keys are skipped.
+func (m *MetaWriter) Write(key string, size int64, ttl time.Duration) error {
+	if ttl <= 0 && ttl != NoExpiration {
+		return nil
+	}
+
+	seconds := MetaNoExpiration
+	if ttl != NoExpiration {
+		seconds = int64(math.Ceil(ttl.Seconds()))
+	}
+
+	return m.encoder.Encode(MetaRecord{Key: key, Size: size, TTL: seconds})
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (m *MetaWriter) Flush() error {
+	return m.writer.Flush()
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Metrics collects the outcome of the storage operations per backend.
+type Metrics interface {
+	IncHit(backend string)
+	IncMiss(backend string)
+	IncError(backend, op string)
+}
+
+// Instrument wraps the storer with the optional checksum, key hashing, TTL jitter, value size limit, read-only mode,
+// key limit, timeout, slow operations log and instrumentation declared in the cache provider. The storer is returned
+// as is when nothing is configured, a nil logger discards the logs. An unknown checksum or key hashing closes the
+// storer and returns an error wrapping ErrInvalidConfig, for the Factory to return.
+func Instrument(storer Storer, cfg CacheProvider, logger Logger) (Storer, error) {
+	instrumented, err := instrument(storer, cfg, logger)
+	if err != nil {
+		if logger != nil {
+			logger.Errorf("Impossible to instrument the %s storage, %v", storer.Name(), err)
+		}
+
+		_ = storer.Close()
+
+		return nil, err
+	}
+
+	return instrumented, nil
+}
+
+func instrument(storer Storer, cfg CacheProvider, logger Logger) (Storer, error) {
+	storer, err := Checksummed(storer, cfg.Checksum, ConfiguredMapper(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	if storer, err = KeyHashed(storer, cfg.KeyHashing); err != nil {
+		return nil, err
+	}
+
+	if jitter := NewTTLJitter(cfg.TTLJitter, cfg.TTLJitterSeed); jitter != nil {
+		storer = &jitteredStorer{Storer: storer, jitter: jitter}
+	}
+
+	if cfg.MaxValueSize > 0 {
+		storer = &sizeLimitedStorer{Storer: storer, limit: cfg.MaxValueSize}
+	}
+
+	if cfg.ReadOnly {
+		storer = ReadOnly(storer)
+	} else {
+		storer = KeyLimited(storer, cfg.MaxKeys, cfg.MaxKeysPolicy, ConfiguredMapper(cfg))
+	}
+
+	if cfg.ExportGzip {
+		storer = &gzipExportStorer{Storer: storer}
+	}
+
+	storer = WithTimeout(storer, cfg.OperationTimeout)
+	storer = SlowLogged(storer, cfg.SlowLogThreshold, logger)
+
+	if cfg.Metrics != nil {
+		storer = &metricsStorer{Storer: storer, metrics: cfg.Metrics, backend: strings.ToLower(storer.Name())}
+	}
+
+	if cfg.TracerProvider != nil {
+		storer = newTracingStorer(storer, cfg.TracerProvider)
+	}
+
+	return storer, nil
+}
+
+type metricsStorer struct {
+	Storer
+	metrics Metrics
+	backend string
+}
+
+func (s *metricsStorer) found(found bool) {
+	if found {
+		s.metrics.IncHit(s.backend)
+	} else {
+		s.metrics.IncMiss(s.backend)
+	}
+}
+
+func (s *metricsStorer) failed(op string, err error) error {
+	if err != nil {
+		s.metrics.IncError(s.backend, op)
+	}
+
+	return err
+}
+
+func (s *metricsStorer) Get(key string) []byte {
+	value := s.Storer.Get(key)
+	s.found(value != nil)
+
+	return value
+}
+
+func (s *metricsStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.Storer.GetContext(ctx, key)
+	if err == nil || errors.Is(err, ErrKeyNotFound) {
+		s.found(err == nil)
+
+		return value, err
+	}
+
+	return value, s.failed("GetContext", err)
+}
+
+func (s *metricsStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *metricsStorer) GetMany(keys []string) map[string][]byte {
+	values := s.Storer.GetMany(keys)
+	for _, key := range keys {
+		_, found := values[key]
+		s.found(found)
+	}
+
+	return values
+}
+
+func (s *metricsStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale = s.Storer.GetMultiLevel(key, req, validator)
+	s.found(fresh != nil || stale != nil)
+
+	return fresh, stale
+}
+
+func (s *metricsStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.failed("Set", s.Storer.Set(key, value, duration))
+}
+
+func (s *metricsStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.failed("SetContext", s.Storer.SetContext(ctx, key, value, duration))
+}
+
+func (s *metricsStorer) SetMany(items map[string]Entry) error {
+	return s.failed("SetMany", s.Storer.SetMany(items))
+}
+
+func (s *metricsStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	created, err := s.Storer.SetNX(key, value, duration)
+
+	return created, s.failed("SetNX", err)
+}
+
+func (s *metricsStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	swapped, err := s.Storer.CompareAndSwap(key, old, value, duration)
+
+	return swapped, s.failed("CompareAndSwap", err)
+}
+
+func (s *metricsStorer) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := s.Storer.Increment(key, delta, duration)
+
+	return value, s.failed("Increment", err)
+}
+
+func (s *metricsStorer) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := s.Storer.Decrement(key, delta, duration)
+
+	return value, s.failed("Decrement", err)
+}
+
+func (s *metricsStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return s.failed("SetMultiLevel", s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey))
+}
+
+func (s *metricsStorer) Touch(key string, duration time.Duration) error {
+	err := s.Storer.Touch(key, duration)
+	if errors.Is(err, ErrKeyNotFound) {
+		s.found(false)
+
+		return err
+	}
+
+	return s.failed("Touch", err)
+}
+
+func (s *metricsStorer) Init() error {
+	return s.failed("Init", s.Storer.Init())
+}
+
+func (s *metricsStorer) Reset() error {
+	return s.failed("Reset", s.Storer.Reset())
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/darkweak/storages/core"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus is the core.Metrics implementation backed by Prometheus counters.
+type Prometheus struct {
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+	errors *prometheus.CounterVec
+}
+
+var _ core.Metrics = (*Prometheus)(nil)
+
+// New function create the Prometheus metrics, they must be registered to be exposed.
+func New() *Prometheus {
+	return &Prometheus{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "storages_hits_total",
+			Help: "The number of keys found in the storage.",
+		}, []string{"backend"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "storages_misses_total",
+			Help: "The number of keys not found in the storage.",
+		}, []string{"backend"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "storages_errors_total",
+			Help: "The number of failed storage operations.",
+		}, []string{"backend", "op"}),
+	}
+}
+
+// IncHit increments the hits counter of the backend.
+func (p *Prometheus) IncHit(backend string) {
+	p.hits.WithLabelValues(backend).Inc()
+}
+
+// IncMiss increments the misses counter of the backend.
+func (p *Prometheus) IncMiss(backend string) {
+	p.misses.WithLabelValues(backend).Inc()
+}
+
+// IncError increments the errors counter of the backend operation.
+func (p *Prometheus) IncError(backend, op string) {
+	p.errors.WithLabelValues(backend, op).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (p *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	p.hits.Describe(ch)
+	p.misses.Describe(ch)
+	p.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	p.hits.Collect(ch)
+	p.misses.Collect(ch)
+	p.errors.Collect(ch)
+}
@@ -0,0 +1,38 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/darkweak/storages/core/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheus(t *testing.T) {
+	collector := metrics.New()
+
+	collector.IncHit("badger")
+	collector.IncHit("badger")
+	collector.IncMiss("badger")
+	collector.IncError("nuts", "Set")
+
+	expected := `
+# HELP storages_errors_total The number of failed storage operations.
+# TYPE storages_errors_total counter
+storages_errors_total{backend="nuts",op="Set"} 1
+# HELP storages_hits_total The number of keys found in the storage.
+# TYPE storages_hits_total counter
+storages_hits_total{backend="badger"} 2
+# HELP storages_misses_total The number of keys not found in the storage.
+# TYPE storages_misses_total counter
+storages_misses_total{backend="badger"} 1
+`
+
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+
+	if count := testutil.CollectAndCount(collector, "storages_hits_total"); count != 1 {
+		t.Errorf("The hits should be collected for one backend, %d provided", count)
+	}
+}
@@ -0,0 +1,13 @@
+package core
+
+// NamespaceSeparator separates the namespace from the keys.
+const NamespaceSeparator = ":"
+
+// NamespacePrefix returns the prefix added to every key stored in the namespace, empty without namespace.
+func NamespacePrefix(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+
+	return namespace + NamespaceSeparator
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// errRangeNotSatisfiable is returned by parseRange when none of the requested ranges overlaps the body.
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+type byteRange struct {
+	start, length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseRange returns the byte ranges of the Range header value within the body size. The malformed headers and
+// the other units return an error to ignore them, errRangeNotSatisfiable when no range overlaps the body.
+func parseRange(header string, size int64) ([]byteRange, error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		return nil, errors.New("unsupported range unit")
+	}
+
+	ranges := []byteRange{}
+	parsed := 0
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		parsed++
+
+		first, last, found := strings.Cut(part, "-")
+		if !found {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r byteRange
+
+		if first == "" {
+			// The suffix range bytes=-n selects the last n bytes.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+
+			n = min(n, size)
+			r = byteRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+
+			end := size - 1
+
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, fmt.Errorf("invalid range %q", part)
+				}
+
+				end = min(end, size-1)
+			}
+
+			r = byteRange{start: start, length: end - start + 1}
+		}
+
+		if r.start < size && r.length > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+
+	if parsed == 0 {
+		return nil, fmt.Errorf("invalid range %q", header)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errRangeNotSatisfiable
+	}
+
+	return ranges, nil
+}
+
+// rangeResponse turns the full 200 response into the partial response requested by the Range header of the
+// GET request, a 206 with the sliced body or a 416 when the ranges are unsatisfiable. The response is returned
+// as is without a Range header, with a malformed one or when the If-Range validator doesn't match it.
+func rangeResponse(res *http.Response, body []byte, req *http.Request) *http.Response {
+	header := req.Header.Get("Range")
+	if header == "" || req.Method != http.MethodGet || res.StatusCode != http.StatusOK {
+		return res
+	}
+
+	if ifRange := req.Header.Get("If-Range"); ifRange != "" && ifRange != res.Header.Get("Etag") && ifRange != res.Header.Get("Last-Modified") {
+		return res
+	}
+
+	size := int64(len(body))
+
+	ranges, err := parseRange(header, size)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		res.StatusCode = http.StatusRequestedRangeNotSatisfiable
+		res.Status = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+		res.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		res.Header.Set("Content-Length", "0")
+		res.ContentLength = 0
+		res.Body = io.NopCloser(bytes.NewReader(nil))
+
+		return res
+	}
+
+	if err != nil {
+		return res
+	}
+
+	var partial []byte
+
+	if len(ranges) == 1 {
+		partial = body[ranges[0].start : ranges[0].start+ranges[0].length]
+		res.Header.Set("Content-Range", ranges[0].contentRange(size))
+	} else {
+		partial, err = multipartRanges(res, body, ranges)
+		if err != nil {
+			return res
+		}
+	}
+
+	res.StatusCode = http.StatusPartialContent
+	res.Status = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	res.Header.Set("Content-Length", strconv.Itoa(len(partial)))
+	res.ContentLength = int64(len(partial))
+	res.Body = io.NopCloser(bytes.NewReader(partial))
+
+	return res
+}
+
+// multipartRanges returns the multipart/byteranges body of the ranges and sets its content type on the response.
+func multipartRanges(res *http.Response, body []byte, ranges []byteRange) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer := multipart.NewWriter(buf)
+	size := int64(len(body))
+
+	for _, r := range ranges {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {res.Header.Get("Content-Type")},
+			"Content-Range": {r.contentRange(size)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = part.Write(body[r.start : r.start+r.length]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	res.Header.Set("Content-Type", "multipart/byteranges; boundary="+writer.Boundary())
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ReadOnly wraps the storer to reject every write with ErrReadOnly while the reads reach the storer.
+// Delete and DeleteMany have no error to return and do nothing, DeleteKeys returns ErrReadOnly.
+func ReadOnly(storer Storer) Storer {
+	return &readOnlyStorer{Storer: storer}
+}
+
+type readOnlyStorer struct {
+	Storer
+}
+
+func (s *readOnlyStorer) Set(string, []byte, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetContext(context.Context, string, []byte, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetMany(map[string]Entry) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetNX(string, []byte, time.Duration) (bool, error) {
+	return false, ErrReadOnly
+}
+
+func (s *readOnlyStorer) CompareAndSwap(string, []byte, []byte, time.Duration) (bool, error) {
+	return false, ErrReadOnly
+}
+
+func (s *readOnlyStorer) Increment(string, int64, time.Duration) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (s *readOnlyStorer) Decrement(string, int64, time.Duration) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetStream(string, io.Reader, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetWithMeta(string, []byte, map[string]string, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) Touch(string, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) Delete(string) {}
+
+func (s *readOnlyStorer) DeleteMany(string) {}
+
+func (s *readOnlyStorer) DeleteKeys([]string) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) InvalidateSurrogate(string) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (s *readOnlyStorer) Reset() error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) Import(io.Reader) error {
+	return ErrReadOnly
+}
+
+func (s *readOnlyStorer) SetMultiLevel(string, string, []byte, http.Header, string, time.Duration, string) error {
+	return ErrReadOnly
+}
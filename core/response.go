@@ -0,0 +1,60 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+var bufPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// readResponse returns the response serialized in the compressed data. The body is read eagerly so a
+// truncated entry is reported here, the errors of a corrupt or malformed entry wrap ErrCorruptEntry. A full
+// response is sliced to the ranges requested by the Range header of the request, see rangeResponse.
+func readResponse(data []byte, req *http.Request) (res *http.Response, err error) {
+	buf := bufPool.Get().(*bytes.Buffer)
+
+	defer func() {
+		buf.Reset()
+		bufPool.Put(buf)
+	}()
+
+	// The decoders and the parser must not take the process down on hostile data.
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, fmt.Errorf("%w: %v", ErrCorruptEntry, r)
+		}
+	}()
+
+	if err = decompressTo(buf, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
+	}
+
+	if buf.Len() == 0 {
+		return nil, fmt.Errorf("%w: empty response", ErrCorruptEntry)
+	}
+
+	res, err = http.ReadResponse(bufio.NewReader(buf), req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptEntry, err)
+	}
+
+	if res.StatusCode < 100 {
+		return nil, fmt.Errorf("%w: invalid status code %d", ErrCorruptEntry, res.StatusCode)
+	}
+
+	// The body must be copied before the buffer returns to the pool.
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: truncated body: %w", ErrCorruptEntry, err)
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	return rangeResponse(res, body, req), nil
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 10 * time.Millisecond
+	defaultRetryMaxBackoff     = time.Second
+)
+
+// RetryOptions configures the WithRetry decorator.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of calls including the first one, 3 when zero.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled on each attempt, 10ms when zero.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, 1s when zero.
+	MaxBackoff time.Duration
+	// Retryable tells if the error is transient, every error is retried when nil.
+	Retryable func(err error) bool
+}
+
+// WithRetry wraps the storer to retry the Get, GetContext, Set and SetContext calls failing with a
+// retryable error, waiting an exponential backoff with jitter between the attempts. The context
+// errors, ErrKeyNotFound, ErrValueTooLarge and ErrClosed are never retried whatever the classifier.
+// Delete doesn't report its errors so it's called once.
+func WithRetry(storer Storer, opts RetryOptions) Storer {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultRetryInitialBackoff
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultRetryMaxBackoff
+	}
+
+	return &retryStorer{Storer: storer, opts: opts}
+}
+
+type retryStorer struct {
+	Storer
+	opts RetryOptions
+}
+
+func (s *retryStorer) retryable(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, ErrKeyNotFound),
+		errors.Is(err, ErrValueTooLarge),
+		errors.Is(err, ErrClosed):
+		return false
+	}
+
+	return s.opts.Retryable == nil || s.opts.Retryable(err)
+}
+
+// do calls the operation until it succeeds, returns a non-retryable error or runs out of attempts.
+func (s *retryStorer) do(ctx context.Context, operation func() error) error {
+	backoff := s.opts.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt == s.opts.MaxAttempts || !s.retryable(err) {
+			return err
+		}
+
+		// The equal jitter randomizes half of the backoff to spread the retries of the concurrent callers.
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+		case <-timer.C:
+		}
+
+		backoff = min(2*backoff, s.opts.MaxBackoff)
+	}
+}
+
+func (s *retryStorer) Get(key string) []byte {
+	value, _ := s.GetContext(context.Background(), key)
+
+	return value
+}
+
+func (s *retryStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+
+	err := s.do(ctx, func() error {
+		var err error
+		value, err = s.Storer.GetContext(ctx, key)
+
+		return err
+	})
+
+	return value, err
+}
+
+func (s *retryStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *retryStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *retryStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.do(ctx, func() error {
+		return s.Storer.SetContext(ctx, key, value, duration)
+	})
+}
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,6 +23,62 @@ type Revalidator struct {
 	IfMatch                     []string
 	RequestETags                []string
 	ResponseETag                string
+	// StaleWhileRevalidate is the stale-while-revalidate window of the elected response, zero without directive.
+	StaleWhileRevalidate time.Duration
+	// Revalidate reports the elected stale response is usable within its stale-while-revalidate window,
+	// it's meant to be served while a background request revalidates it.
+	Revalidate bool
+	// ForcedRevalidation reports the elected stale response is still fresh but the request Cache-Control
+	// no-cache or max-age=0 directive requires its revalidation before being served, see GetMultiLevel.
+	ForcedRevalidation bool
+}
+
+// requestNoCache tells if the request Cache-Control forbids a stored response without revalidation using
+// the no-cache or max-age=0 directives.
+func requestNoCache(header http.Header) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "no-cache":
+				return true
+			case "max-age":
+				if seconds, err := strconv.Atoi(strings.Trim(strings.TrimSpace(argument), `"`)); err == nil && seconds == 0 {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// StaleWhileRevalidate returns the stale-while-revalidate window of the Cache-Control header, zero when
+// the directive is missing or invalid.
+func StaleWhileRevalidate(header http.Header) time.Duration {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, seconds, found := strings.Cut(strings.TrimSpace(directive), "=")
+			if !found || !strings.EqualFold(strings.TrimSpace(name), "stale-while-revalidate") {
+				continue
+			}
+
+			window, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(seconds), `"`), 10, 64)
+			if err == nil && window > 0 {
+				return time.Duration(min(window, math.MaxInt64/int64(time.Second))) * time.Second
+			}
+		}
+	}
+
+	return 0
+}
+
+// electStaleWhileRevalidate fills the stale-while-revalidate window of the elected response, the stale
+// one needs a revalidation while the window following its fresh time isn't over.
+func electStaleWhileRevalidate(validator *Revalidator, response *http.Response, freshTime, now time.Time, stale bool) {
+	validator.StaleWhileRevalidate = StaleWhileRevalidate(response.Header)
+	validator.Revalidate = stale && validator.StaleWhileRevalidate > 0 && freshTime.Add(validator.StaleWhileRevalidate).After(now)
 }
 
 func ValidateETagFromHeader(etag string, validator *Revalidator) {
@@ -0,0 +1,404 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// VarySeparator separates the base key from the varied part in the varied keys of the multi-level entries,
+// the ring routes a varied key by the base key before it.
+const VarySeparator = "{-VARY-}"
+
+// DefaultRingReplicas is the number of points of each member on the ring used by NewRing when the
+// replicas are not positive.
+const DefaultRingReplicas = 100
+
+// NewRing spreads the keys across the members with a consistent hash ring, each member owns the keys
+// hashed between its points and the previous ones. The points are derived from the Uuid of the member
+// so adding or removing a member only moves the keys of its points, about 1/n of them. More replicas
+// balance the keys better at the cost of a larger ring.
+//
+// The multi-level entries are routed by their base key so a member holds the mapping with its varied
+// keys, the mapping and varied keys passed to the other methods are routed the same way. The keys
+// listing, the reset and the other bulk operations run on every member. It panics without any member.
+func NewRing(members []Storer, replicas int) Storer {
+	if len(members) == 0 {
+		panic("core: ring without member")
+	}
+
+	if replicas <= 0 {
+		replicas = DefaultRingReplicas
+	}
+
+	s := &ringStorer{members: members, owners: make(map[uint64]Storer, len(members)*replicas)}
+	seen := map[string]int{}
+
+	for _, member := range members {
+		// The members sharing the same Uuid are told apart by their occurrence.
+		name := member.Uuid()
+		if seen[name] > 0 {
+			name += "#" + strconv.Itoa(seen[name])
+		}
+
+		seen[member.Uuid()]++
+
+		for replica := range replicas {
+			point := xxhash.Sum64String(name + "-" + strconv.Itoa(replica))
+			if _, taken := s.owners[point]; taken {
+				continue
+			}
+
+			s.owners[point] = member
+			s.points = append(s.points, point)
+		}
+	}
+
+	slices.Sort(s.points)
+
+	return s
+}
+
+type ringStorer struct {
+	members []Storer
+	points  []uint64
+	owners  map[uint64]Storer
+}
+
+// member returns the member owning the key, the first point at or after the hash of its routing key.
+func (s *ringStorer) member(key string) Storer {
+	hash := xxhash.Sum64String(routingKey(key))
+
+	i, _ := slices.BinarySearch(s.points, hash)
+	if i == len(s.points) {
+		i = 0
+	}
+
+	return s.owners[s.points[i]]
+}
+
+// routingKey returns the base key of a mapping or a varied key, the key itself otherwise.
+func routingKey(key string) string {
+	base, _, _ := strings.Cut(strings.TrimPrefix(key, MappingKeyPrefix), VarySeparator)
+
+	return base
+}
+
+func (s *ringStorer) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for _, member := range s.members {
+		for key, value := range member.MapKeys(prefix) {
+			keys[key] = value
+		}
+	}
+
+	return keys
+}
+
+func (s *ringStorer) ListKeys() []string {
+	keys := []string{}
+
+	for _, member := range s.members {
+		keys = append(keys, member.ListKeys()...)
+	}
+
+	return keys
+}
+
+// ScanKeys merges the page and the next key of every member and returns the smallest ones.
+func (s *ringStorer) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	candidates := []string{}
+
+	for _, member := range s.members {
+		page, memberNext := member.ScanKeys(prefix, cursor, limit)
+		candidates = append(candidates, page...)
+
+		if memberNext != "" {
+			candidates = append(candidates, memberNext)
+		}
+	}
+
+	return PaginateKeys(candidates, prefix, cursor, limit)
+}
+
+func (s *ringStorer) SortedKeys(prefix string) []string {
+	return SortedKeys(s, prefix)
+}
+
+func (s *ringStorer) Get(key string) []byte {
+	return s.member(key).Get(key)
+}
+
+func (s *ringStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	return s.member(key).GetContext(ctx, key)
+}
+
+func (s *ringStorer) GetWithError(key string) ([]byte, error) {
+	return s.member(key).GetWithError(key)
+}
+
+func (s *ringStorer) GetMany(keys []string) map[string][]byte {
+	grouped := map[Storer][]string{}
+	for _, key := range keys {
+		member := s.member(key)
+		grouped[member] = append(grouped[member], key)
+	}
+
+	values := map[string][]byte{}
+
+	for member, memberKeys := range grouped {
+		for key, value := range member.GetMany(memberKeys) {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+func (s *ringStorer) GetTTL(key string) (time.Duration, bool) {
+	return s.member(key).GetTTL(key)
+}
+
+func (s *ringStorer) Exists(key string) bool {
+	return s.member(key).Exists(key)
+}
+
+func (s *ringStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.member(key).Set(key, value, duration)
+}
+
+func (s *ringStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.member(key).SetContext(ctx, key, value, duration)
+}
+
+// SetMany writes the entries of each member at once, a failure leaves the entries of the other members stored.
+func (s *ringStorer) SetMany(items map[string]Entry) error {
+	grouped := map[Storer]map[string]Entry{}
+
+	for key, item := range items {
+		member := s.member(key)
+		if grouped[member] == nil {
+			grouped[member] = map[string]Entry{}
+		}
+
+		grouped[member][key] = item
+	}
+
+	for member, memberItems := range grouped {
+		if err := member.SetMany(memberItems); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ringStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	return s.member(key).SetNX(key, value, duration)
+}
+
+func (s *ringStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	return s.member(key).CompareAndSwap(key, old, value, duration)
+}
+
+func (s *ringStorer) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	return s.member(key).Increment(key, delta, duration)
+}
+
+func (s *ringStorer) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return s.member(key).Decrement(key, delta, duration)
+}
+
+func (s *ringStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	return s.member(key).SetStream(key, reader, duration)
+}
+
+func (s *ringStorer) GetStream(key string) (io.ReadCloser, error) {
+	return s.member(key).GetStream(key)
+}
+
+func (s *ringStorer) Touch(key string, duration time.Duration) error {
+	return s.member(key).Touch(key, duration)
+}
+
+func (s *ringStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return s.member(key).SetWithMeta(key, value, meta, duration)
+}
+
+func (s *ringStorer) GetMeta(key string) (map[string]string, error) {
+	return s.member(key).GetMeta(key)
+}
+
+func (s *ringStorer) Delete(key string) {
+	s.member(key).Delete(key)
+}
+
+func (s *ringStorer) DeleteMany(pattern string) {
+	for _, member := range s.members {
+		member.DeleteMany(pattern)
+	}
+}
+
+// DeleteKeys deletes the keys of each member at once, a failure leaves the keys of the other members stored.
+func (s *ringStorer) DeleteKeys(keys []string) error {
+	grouped := map[Storer][]string{}
+	for _, key := range keys {
+		member := s.member(key)
+		grouped[member] = append(grouped[member], key)
+	}
+
+	for member, memberKeys := range grouped {
+		if err := member.DeleteKeys(memberKeys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate sums the responses deleted by every member, each one indexes the surrogate keys
+// of the responses it holds.
+func (s *ringStorer) InvalidateSurrogate(surrogateKey string) (int, error) {
+	deleted := 0
+
+	for _, member := range s.members {
+		count, err := member.InvalidateSurrogate(surrogateKey)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += count
+	}
+
+	return deleted, nil
+}
+
+func (s *ringStorer) ListSurrogates() ([]string, error) {
+	return ListSurrogateKeys(s)
+}
+
+// GetBySurrogate merges the responses of every member, each one indexes the surrogate keys of the responses
+// it holds.
+func (s *ringStorer) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	entries := map[string][]byte{}
+
+	for _, member := range s.members {
+		memberEntries, err := member.GetBySurrogate(surrogateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(entries, memberEntries)
+	}
+
+	return entries, nil
+}
+
+// Inspect reads the key from the member holding its multi-level responses.
+func (s *ringStorer) Inspect(key string) (*EntryInfo, error) {
+	return s.member(key).Inspect(key)
+}
+
+func (s *ringStorer) Init() error {
+	errs := []error{}
+	for _, member := range s.members {
+		errs = append(errs, member.Init())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *ringStorer) Name() string {
+	return "RING"
+}
+
+func (s *ringStorer) Uuid() string {
+	uuids := make([]string, 0, len(s.members))
+	for _, member := range s.members {
+		uuids = append(uuids, member.Uuid())
+	}
+
+	return strings.Join(uuids, ",")
+}
+
+func (s *ringStorer) Reset() error {
+	errs := []error{}
+	for _, member := range s.members {
+		errs = append(errs, member.Reset())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *ringStorer) Close() error {
+	errs := []error{}
+	for _, member := range s.members {
+		errs = append(errs, member.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Stats sums the statistics of the members and returns the oldest entry age among them.
+func (s *ringStorer) Stats() (StorageStats, error) {
+	stats := StorageStats{}
+
+	for _, member := range s.members {
+		memberStats, err := member.Stats()
+		if err != nil {
+			return stats, err
+		}
+
+		stats.KeyCount += memberStats.KeyCount
+		stats.ApproxSizeBytes += memberStats.ApproxSizeBytes
+		stats.OldestEntryAge = max(stats.OldestEntryAge, memberStats.OldestEntryAge)
+	}
+
+	return stats, nil
+}
+
+func (s *ringStorer) Ping(ctx context.Context) error {
+	errs := []error{}
+	for _, member := range s.members {
+		errs = append(errs, member.Ping(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Export streams the keys of the members one after the other.
+func (s *ringStorer) Export(w io.Writer) error {
+	for _, member := range s.members {
+		if err := member.Export(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import stores the exported records in their member, a record may move to another member than the exporting one.
+func (s *ringStorer) Import(r io.Reader) error {
+	return ImportRecords(r, s.SetMany)
+}
+
+func (s *ringStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	return s.member(key).GetMultiLevel(key, req, validator)
+}
+
+func (s *ringStorer) GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch) {
+	return s.member(key).GetMultiLevelDebug(key, req, validator)
+}
+
+func (s *ringStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	return s.member(baseKey).SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
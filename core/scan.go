@@ -0,0 +1,89 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// InternalKeyPrefixes reserve the namespaces of the internal keys: the multi-level mappings, the surrogate keys
+// index, the entries metadata and the hashed keys index. The user keys must not start with them, MapKeys and
+// ScanKeys skip the internal keys unless the prefix targets one of these namespaces, see ListedKey.
+var InternalKeyPrefixes = []string{MappingKeyPrefix, SurrogateKeyPrefix, EntryMetaKeyPrefix, HashedKeyPrefix}
+
+// IsInternalKey returns true when the key belongs to one of the InternalKeyPrefixes namespaces.
+func IsInternalKey(key string) bool {
+	for _, prefix := range InternalKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListedKey returns true when MapKeys and ScanKeys list the key matching the prefix, the internal keys are
+// only listed when the prefix targets their namespace, MapKeys(MappingKeyPrefix) for example.
+func ListedKey(prefix, key string) bool {
+	return !IsInternalKey(key) || IsInternalKey(prefix)
+}
+
+// NextKey returns the cursor resuming a scan right after the key, the smallest key sorted after it.
+func NextKey(key string) string {
+	return key + "\x00"
+}
+
+// PaginateKeys returns a page of at most limit sorted keys matching the prefix starting
+// from the cursor key, and the cursor of the next page that is empty once all keys are
+// returned. A limit lower than one returns all the remaining keys. The keys skipped by
+// ListedKey are not returned.
+func PaginateKeys(keys []string, prefix, cursor string, limit int) ([]string, string) {
+	start := max(prefix, cursor)
+	page := []string{}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if key < start || !strings.HasPrefix(key, prefix) || !ListedKey(prefix, key) {
+			continue
+		}
+
+		if limit > 0 && len(page) == limit {
+			return page, key
+		}
+
+		page = append(page, key)
+	}
+
+	return page, ""
+}
+
+// sortedScanPage bounds the keys held by each ScanKeys call of SortedKeys.
+const sortedScanPage = 1000
+
+// SortedKeys returns the keys matching the prefix in lexicographic order, scanned page by page through
+// the storer ScanKeys. The keys listed by several backends behind the storer are only returned once.
+func SortedKeys(storer Storer, prefix string) []string {
+	keys := []string{}
+	cursor := ""
+
+	for {
+		page, next := storer.ScanKeys(prefix, cursor, sortedScanPage)
+		keys = append(keys, page...)
+
+		if next == "" || next == cursor {
+			break
+		}
+
+		cursor = next
+	}
+
+	slices.Sort(keys)
+
+	return slices.Compact(keys)
+}
+
+// KeyPrefix returns the key prefix described by the pattern, a trailing * is treated as
+// a wildcard and a plain prefix matches any key beginning with it.
+func KeyPrefix(pattern string) string {
+	return strings.TrimSuffix(pattern, "*")
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"net/http"
+	"time"
+)
+
+// structuredLogger is implemented by the zap SugaredLogger, the slow operations are logged with fields through it.
+type structuredLogger interface {
+	Warnw(msg string, keysAndValues ...interface{})
+}
+
+type slowLogStorer struct {
+	Storer
+	threshold time.Duration
+	logger    Logger
+}
+
+// SlowLogged logs a warning for each Get, Set, GetMultiLevel and SetMultiLevel of the storer lasting at least
+// the threshold, with the op, key, duration and size fields when the logger is structured like the zap
+// SugaredLogger. A non-positive threshold returns the storer as is so the disabled log costs nothing.
+func SlowLogged(storer Storer, threshold time.Duration, logger Logger) Storer {
+	if threshold <= 0 {
+		return storer
+	}
+
+	if logger == nil {
+		logger = nopLogger{}
+	}
+
+	return &slowLogStorer{Storer: storer, threshold: threshold, logger: logger}
+}
+
+func (s *slowLogStorer) observe(op, key string, start time.Time, size int) {
+	duration := time.Since(start)
+	if duration < s.threshold {
+		return
+	}
+
+	if logger, ok := s.logger.(structuredLogger); ok {
+		logger.Warnw("Slow storage operation", "op", op, "key", key, "duration", duration, "size", size)
+
+		return
+	}
+
+	s.logger.Warnf("Slow storage operation %s on the key %s, %v for %d bytes", op, key, duration, size)
+}
+
+func (s *slowLogStorer) Get(key string) []byte {
+	start := time.Now()
+	value := s.Storer.Get(key)
+	s.observe("get", key, start, len(value))
+
+	return value
+}
+
+func (s *slowLogStorer) Set(key string, value []byte, duration time.Duration) error {
+	start := time.Now()
+	err := s.Storer.Set(key, value, duration)
+	s.observe("set", key, start, len(value))
+
+	return err
+}
+
+// GetMultiLevel reports the content length of the returned response, the fresh one first, as its size.
+func (s *slowLogStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	start := time.Now()
+	fresh, stale = s.Storer.GetMultiLevel(key, req, validator)
+
+	var size int64
+
+	switch {
+	case fresh != nil:
+		size = fresh.ContentLength
+	case stale != nil:
+		size = stale.ContentLength
+	}
+
+	s.observe("get_multi_level", key, start, int(max(size, 0)))
+
+	return fresh, stale
+}
+
+func (s *slowLogStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	start := time.Now()
+	err := s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+	s.observe("set_multi_level", variedKey, start, len(value))
+
+	return err
+}
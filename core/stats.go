@@ -0,0 +1,14 @@
+package core
+
+import "time"
+
+// StorageStats describes the storage content. The backends fill the values they can compute
+// without a full scan, the sizes are estimations and the zero value means unknown.
+type StorageStats struct {
+	// KeyCount is the number of stored keys, the mapping keys included.
+	KeyCount int64
+	// ApproxSizeBytes is the estimated space used by the storage.
+	ApproxSizeBytes int64
+	// OldestEntryAge is the time elapsed since the oldest entry has been written.
+	OldestEntryAge time.Duration
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Buffers grown beyond this size are released instead of being pooled,
+// a single huge value must not pin its memory for the process lifetime.
+const maxPooledBufferSize = 32 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// CompressStream compresses the reader content using the given codec into a pooled buffer and
+// hands the compressed bytes to the store function. The bytes are only valid until store returns,
+// store must copy them if they are retained afterwards.
+func CompressStream(codec string, reader io.Reader, store func(compressed []byte) error) error {
+	buf, _ := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := compressTo(buf, codec, reader); err != nil {
+		return err
+	}
+
+	return store(buf.Bytes())
+}
+
+// DecompressStream detects the codec used to store the data and returns a reader
+// decompressing it lazily.
+func DecompressStream(reader io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(reader)
+
+	header, err := buffered.Peek(len(lz4Magic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if bytes.Equal(header, lz4Magic) {
+		return io.NopCloser(lz4.NewReader(buffered)), nil
+	}
+
+	if len(header) == 0 {
+		return nil, ErrUnknownCompressionHeader
+	}
+
+	_, _ = buffered.Discard(1)
+
+	switch header[0] {
+	case noneHeader:
+		return io.NopCloser(buffered), nil
+	case zstdHeader:
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+	case zstdDictHeader:
+		zr, err := zstdDictReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+	}
+
+	return nil, ErrUnknownCompressionHeader
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SurrogateKeyHeader is the response header declaring the space separated surrogate keys.
+	SurrogateKeyHeader = "Surrogate-Key"
+
+	// surrogateSeparator separates the keys of a surrogate index, as Souin does under the same prefix.
+	surrogateSeparator = ","
+	// surrogateMarkerKey is stored as long as the longest lived surrogate index written since, the deletions
+	// skip the unindexing without it. ListSurrogateKeys skips it as it declares no surrogate key.
+	surrogateMarkerKey = SurrogateKeyPrefix
+)
+
+// The index updates are read-modify-write sequences, they are serialized per surrogate key in the process.
+var surrogateLocks [64]sync.Mutex
+
+func surrogateLock(surrogateKey string) *sync.Mutex {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(surrogateKey))
+
+	return &surrogateLocks[hash.Sum32()%uint32(len(surrogateLocks))]
+}
+
+// SurrogateKeys returns the surrogate keys declared by the Surrogate-Key header of the serialized response.
+func SurrogateKeys(response []byte) []string {
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(response)), nil)
+	if err != nil {
+		return nil
+	}
+
+	_ = res.Body.Close()
+
+	keys := []string{}
+	for _, value := range res.Header.Values(SurrogateKeyHeader) {
+		keys = append(keys, strings.Fields(value)...)
+	}
+
+	return keys
+}
+
+// decodeSurrogateIndex returns the keys of the index, the empty ones left by the leading separator
+// Souin writes are skipped.
+func decodeSurrogateIndex(index []byte) []string {
+	keys := []string{}
+
+	for _, key := range strings.Split(string(index), surrogateSeparator) {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func encodeSurrogateIndex(keys []string) []byte {
+	return []byte(strings.Join(keys, surrogateSeparator))
+}
+
+// IndexSurrogateKeys adds the key to the index of each surrogate key declared by the response, through
+// the storer Get and Set. The index lives as long as its longest lived response. The updates are only
+// serialized in the process, the backends shared by several instances may lose concurrent updates of
+// the same surrogate key. The responses encrypted before reaching the backend can't be indexed.
+func IndexSurrogateKeys(storer Storer, key string, response []byte, duration time.Duration) error {
+	var longest time.Duration
+
+	for _, surrogateKey := range SurrogateKeys(response) {
+		indexed, err := indexSurrogateKey(storer, surrogateKey, key, duration)
+		if err != nil {
+			return err
+		}
+
+		longest = max(longest, indexed)
+	}
+
+	if longest <= 0 {
+		return nil
+	}
+
+	return markSurrogateIndex(storer, longest)
+}
+
+// markSurrogateIndex extends the lifetime of the surrogateMarkerKey to the duration of an index.
+func markSurrogateIndex(storer Storer, duration time.Duration) error {
+	lock := surrogateLock(surrogateMarkerKey)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if ttl, found := storer.GetTTL(surrogateMarkerKey); found && (ttl >= duration || ttl == NoExpiration) {
+		return nil
+	}
+
+	return storer.Set(surrogateMarkerKey, []byte{1}, duration)
+}
+
+// indexSurrogateKey adds the key to the index of the surrogate key and returns the duration it's stored for.
+func indexSurrogateKey(storer Storer, surrogateKey, key string, duration time.Duration) (time.Duration, error) {
+	lock := surrogateLock(surrogateKey)
+	lock.Lock()
+	defer lock.Unlock()
+
+	indexKey := SurrogateKeyPrefix + surrogateKey
+	keys := decodeSurrogateIndex(storer.Get(indexKey))
+	indexed := false
+
+	for _, k := range keys {
+		if k == key {
+			indexed = true
+
+			break
+		}
+	}
+
+	if !indexed {
+		keys = append(keys, key)
+	}
+
+	// The index is rewritten even when the key is already indexed to extend its lifetime.
+	if ttl, found := storer.GetTTL(indexKey); found && (ttl > duration || ttl == NoExpiration) {
+		duration = ttl
+	}
+
+	if duration == NoExpiration {
+		duration = ImportNoExpirationTTL
+	}
+
+	return duration, storer.Set(indexKey, encodeSurrogateIndex(keys), duration)
+}
+
+// UnindexSurrogateKeys removes the key from the index of each surrogate key declared by its stored
+// response, it must be called before deleting the key. The values that aren't compressed responses
+// and the internal keys are ignored, nothing is read while no index has been written, see
+// surrogateMarkerKey. The index updates are best effort, see unindexSurrogateKeys for their errors.
+func UnindexSurrogateKeys(storer Storer, key string) {
+	_ = unindexSurrogateKeys(storer, key)
+}
+
+func unindexSurrogateKeys(storer Storer, key string) error {
+	if strings.HasPrefix(key, SurrogateKeyPrefix) || strings.HasPrefix(key, MappingKeyPrefix) ||
+		strings.HasPrefix(key, EntryMetaKeyPrefix) || !storer.Exists(surrogateMarkerKey) {
+		return nil
+	}
+
+	stored := storer.Get(key)
+	if len(stored) == 0 {
+		return nil
+	}
+
+	response, err := Decompress(stored)
+	if err != nil {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, surrogateKey := range SurrogateKeys(response) {
+		if err = unindexSurrogateKey(storer, surrogateKey, key); err != nil {
+			errs = append(errs, fmt.Errorf("impossible to unindex the key %s from %s: %w", key, surrogateKey, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func unindexSurrogateKey(storer Storer, surrogateKey, key string) error {
+	lock := surrogateLock(surrogateKey)
+	lock.Lock()
+	defer lock.Unlock()
+
+	indexKey := SurrogateKeyPrefix + surrogateKey
+	keys := decodeSurrogateIndex(storer.Get(indexKey))
+	remaining := make([]string, 0, len(keys))
+
+	for _, indexed := range keys {
+		if indexed != key {
+			remaining = append(remaining, indexed)
+		}
+	}
+
+	if len(remaining) == len(keys) {
+		return nil
+	}
+
+	if len(remaining) == 0 {
+		storer.Delete(indexKey)
+
+		return nil
+	}
+
+	ttl, found := storer.GetTTL(indexKey)
+	if !found || (ttl <= 0 && ttl != NoExpiration) {
+		return nil
+	}
+
+	if ttl == NoExpiration {
+		ttl = ImportNoExpirationTTL
+	}
+
+	return storer.Set(indexKey, encodeSurrogateIndex(remaining), ttl)
+}
+
+// InvalidateSurrogateKeys deletes the index of the surrogate key then every key it lists with DeleteKeys, and
+// returns the number of keys still stored that have been deleted with the joined errors of the others.
+func InvalidateSurrogateKeys(storer Storer, surrogateKey string) (int, error) {
+	lock := surrogateLock(surrogateKey)
+	lock.Lock()
+
+	indexKey := SurrogateKeyPrefix + surrogateKey
+	keys := decodeSurrogateIndex(storer.Get(indexKey))
+	storer.Delete(indexKey)
+
+	lock.Unlock()
+
+	deleted := 0
+	errs := []error{}
+
+	for _, key := range keys {
+		if !storer.Exists(key) {
+			continue
+		}
+
+		if err := storer.DeleteKeys([]string{key}); err != nil {
+			errs = append(errs, fmt.Errorf("impossible to delete the key %s: %w", key, err))
+
+			continue
+		}
+
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// ListSurrogateKeys returns the sorted surrogate keys having an index, scanned from the surrogate index
+// namespace with SortedKeys.
+func ListSurrogateKeys(storer Storer) ([]string, error) {
+	surrogateKeys := []string{}
+
+	for _, indexKey := range SortedKeys(storer, SurrogateKeyPrefix) {
+		if surrogateKey := strings.TrimPrefix(indexKey, SurrogateKeyPrefix); surrogateKey != "" {
+			surrogateKeys = append(surrogateKeys, surrogateKey)
+		}
+	}
+
+	return surrogateKeys, nil
+}
+
+// GetSurrogateEntries returns the stored values of the keys listed by the index of the surrogate key, read at once
+// with GetMany. The indexed keys expired or deleted since are omitted.
+func GetSurrogateEntries(storer Storer, surrogateKey string) (map[string][]byte, error) {
+	keys := decodeSurrogateIndex(storer.Get(SurrogateKeyPrefix + surrogateKey))
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	return storer.GetMany(keys), nil
+}
@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Tiered composes a fast front storer, usually in memory, with a persistent back storer. The reads
+// check the front first and promote the back hits to the front with the remaining time to live of
+// the back entry, so the front copy never outlives it. The writes go through the back then the front,
+// the front is left untouched when the back write fails. The keys listing, the stats, the export and
+// the import only rely on the back storer.
+func Tiered(front, back Storer) Storer {
+	return &tieredStorer{Storer: back, front: front}
+}
+
+type tieredStorer struct {
+	Storer
+	front Storer
+}
+
+// promote copies the back entry to the front, the entries without a known time to live are skipped.
+func (s *tieredStorer) promote(key string, value []byte) {
+	ttl, found := s.Storer.GetTTL(key)
+	if !found || (ttl <= 0 && ttl != NoExpiration) {
+		return
+	}
+
+	if ttl == NoExpiration {
+		ttl = ImportNoExpirationTTL
+	}
+
+	_ = s.front.Set(key, value, ttl)
+}
+
+func (s *tieredStorer) Get(key string) []byte {
+	if value := s.front.Get(key); value != nil {
+		return value
+	}
+
+	value := s.Storer.Get(key)
+	if value != nil {
+		s.promote(key, value)
+	}
+
+	return value
+}
+
+func (s *tieredStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if value, err := s.front.GetContext(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, err := s.Storer.GetContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.promote(key, value)
+
+	return value, nil
+}
+
+func (s *tieredStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *tieredStorer) GetMany(keys []string) map[string][]byte {
+	values := s.front.GetMany(keys)
+	missing := make([]string, 0, len(keys)-len(values))
+
+	for _, key := range keys {
+		if _, found := values[key]; !found {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return values
+	}
+
+	for key, value := range s.Storer.GetMany(missing) {
+		values[key] = value
+		s.promote(key, value)
+	}
+
+	return values
+}
+
+func (s *tieredStorer) Exists(key string) bool {
+	return s.front.Exists(key) || s.Storer.Exists(key)
+}
+
+func (s *tieredStorer) Set(key string, value []byte, duration time.Duration) error {
+	if err := s.Storer.Set(key, value, duration); err != nil {
+		return err
+	}
+
+	return s.front.Set(key, value, duration)
+}
+
+func (s *tieredStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := s.Storer.SetContext(ctx, key, value, duration); err != nil {
+		return err
+	}
+
+	return s.front.SetContext(ctx, key, value, duration)
+}
+
+func (s *tieredStorer) SetMany(items map[string]Entry) error {
+	if err := s.Storer.SetMany(items); err != nil {
+		return err
+	}
+
+	return s.front.SetMany(items)
+}
+
+func (s *tieredStorer) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	created, err := s.Storer.SetNX(key, value, duration)
+	if !created || err != nil {
+		return created, err
+	}
+
+	return true, s.front.Set(key, value, duration)
+}
+
+// CompareAndSwap compares the value of the back storer, the front copy is evicted when the swap fails.
+func (s *tieredStorer) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	swapped, err := s.Storer.CompareAndSwap(key, old, value, duration)
+	if !swapped || err != nil {
+		s.front.Delete(key)
+
+		return swapped, err
+	}
+
+	return true, s.front.Set(key, value, duration)
+}
+
+// SetWithMeta stores the metadata in the back storer only, GetMeta reads it from there.
+func (s *tieredStorer) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if err := s.Storer.SetWithMeta(key, value, meta, duration); err != nil {
+		return err
+	}
+
+	return s.front.Set(key, value, duration)
+}
+
+// Increment runs on the back storer only, the front copy is evicted to be promoted again on the next read.
+func (s *tieredStorer) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := s.Storer.Increment(key, delta, duration)
+	s.front.Delete(key)
+
+	return value, err
+}
+
+func (s *tieredStorer) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return s.Increment(key, -delta, duration)
+}
+
+// SetStream writes to the back storer only to avoid buffering the stream, the front copy is evicted.
+func (s *tieredStorer) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := s.Storer.SetStream(key, reader, duration)
+	s.front.Delete(key)
+
+	return err
+}
+
+func (s *tieredStorer) Touch(key string, duration time.Duration) error {
+	if err := s.Storer.Touch(key, duration); err != nil {
+		s.front.Delete(key)
+
+		return err
+	}
+
+	if s.front.Touch(key, duration) != nil {
+		s.front.Delete(key)
+	}
+
+	return nil
+}
+
+func (s *tieredStorer) Delete(key string) {
+	s.Storer.Delete(key)
+	s.front.Delete(key)
+}
+
+func (s *tieredStorer) DeleteMany(pattern string) {
+	s.Storer.DeleteMany(pattern)
+	s.front.DeleteMany(pattern)
+}
+
+func (s *tieredStorer) DeleteKeys(keys []string) error {
+	return errors.Join(s.Storer.DeleteKeys(keys), s.front.DeleteKeys(keys))
+}
+
+// InvalidateSurrogate returns the number of responses deleted from the back, the front copies promoted
+// from the back aren't indexed by the front so they are deleted using the back index.
+func (s *tieredStorer) InvalidateSurrogate(surrogateKey string) (int, error) {
+	for _, key := range decodeSurrogateIndex(s.Storer.Get(SurrogateKeyPrefix + surrogateKey)) {
+		s.front.Delete(key)
+	}
+
+	if _, err := s.front.InvalidateSurrogate(surrogateKey); err != nil {
+		return 0, err
+	}
+
+	return s.Storer.InvalidateSurrogate(surrogateKey)
+}
+
+func (s *tieredStorer) Init() error {
+	if err := s.Storer.Init(); err != nil {
+		return err
+	}
+
+	return s.front.Init()
+}
+
+func (s *tieredStorer) Reset() error {
+	return errors.Join(s.Storer.Reset(), s.front.Reset())
+}
+
+func (s *tieredStorer) Close() error {
+	return errors.Join(s.Storer.Close(), s.front.Close())
+}
+
+func (s *tieredStorer) Ping(ctx context.Context) error {
+	return errors.Join(s.Storer.Ping(ctx), s.front.Ping(ctx))
+}
+
+// Import writes to the back storer only, the imported entries are promoted on their first read.
+func (s *tieredStorer) Import(r io.Reader) error {
+	return s.Storer.Import(r)
+}
+
+func (s *tieredStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = s.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug looks each tier up with its own copy of the validator, the one of the tier serving the
+// response is kept so the front miss state doesn't leak into the back lookup.
+func (s *tieredStorer) GetMultiLevelDebug(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response, match MultiLevelMatch) {
+	front := copyRevalidator(validator)
+
+	fresh, stale, match = s.front.GetMultiLevelDebug(key, req, front)
+	if fresh != nil {
+		keepRevalidator(validator, front)
+
+		return fresh, stale, match
+	}
+
+	back := copyRevalidator(validator)
+	fresh, stale, match = s.Storer.GetMultiLevelDebug(key, req, back)
+	keepRevalidator(validator, back)
+
+	return fresh, stale, match
+}
+
+func copyRevalidator(validator *Revalidator) *Revalidator {
+	if validator == nil {
+		return nil
+	}
+
+	clone := *validator
+
+	return &clone
+}
+
+func keepRevalidator(validator, tier *Revalidator) {
+	if validator != nil {
+		*validator = *tier
+	}
+}
+
+func (s *tieredStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	if err := s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey); err != nil {
+		return err
+	}
+
+	return s.front.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+}
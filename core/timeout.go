@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// OperationContext derives a context bounded by the operation timeout, a non-positive timeout
+// keeps the parent deadline only.
+func OperationContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, timeout)
+}
+
+// WithTimeout wraps the storer to bound the Get, GetContext, GetWithError, Set, SetContext and Delete
+// calls by the timeout. The context given to the backend expires with the timeout and the call
+// returns context.DeadlineExceeded once exceeded even if the backend ignores its context, the
+// abandoned operation completing in the background. A non-positive timeout returns the storer as is.
+func WithTimeout(storer Storer, timeout time.Duration) Storer {
+	if timeout <= 0 {
+		return storer
+	}
+
+	return &timeoutStorer{Storer: storer, timeout: timeout}
+}
+
+type timeoutStorer struct {
+	Storer
+	timeout time.Duration
+}
+
+// run waits for the operation until the deadline, the result channel is buffered to never block
+// the operation finishing after the deadline.
+func (s *timeoutStorer) run(parent context.Context, operation func(ctx context.Context) error) error {
+	ctx, cancel := OperationContext(parent, s.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- operation(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *timeoutStorer) Get(key string) []byte {
+	value, _ := s.GetContext(context.Background(), key)
+
+	return value
+}
+
+func (s *timeoutStorer) GetContext(ctx context.Context, key string) ([]byte, error) {
+	result := make(chan []byte, 1)
+
+	err := s.run(ctx, func(ctx context.Context) error {
+		value, err := s.Storer.GetContext(ctx, key)
+		result <- value
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return <-result, nil
+}
+
+func (s *timeoutStorer) GetWithError(key string) ([]byte, error) {
+	return s.GetContext(context.Background(), key)
+}
+
+func (s *timeoutStorer) Set(key string, value []byte, duration time.Duration) error {
+	return s.SetContext(context.Background(), key, value, duration)
+}
+
+func (s *timeoutStorer) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	return s.run(ctx, func(ctx context.Context) error {
+		return s.Storer.SetContext(ctx, key, value, duration)
+	})
+}
+
+func (s *timeoutStorer) Delete(key string) {
+	_ = s.run(context.Background(), func(context.Context) error {
+		s.Storer.Delete(key)
+
+		return nil
+	})
+}
+
+func (s *timeoutStorer) DeleteKeys(keys []string) error {
+	return s.run(context.Background(), func(context.Context) error {
+		return s.Storer.DeleteKeys(keys)
+	})
+}
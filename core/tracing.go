@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/darkweak/storages/core"
+
+type tracingStorer struct {
+	Storer
+	tracer trace.Tracer
+	prefix string
+}
+
+func newTracingStorer(storer Storer, provider trace.TracerProvider) *tracingStorer {
+	return &tracingStorer{
+		Storer: storer,
+		tracer: provider.Tracer(tracerName),
+		prefix: "storages." + strings.ToLower(storer.Name()) + ".",
+	}
+}
+
+func (s *tracingStorer) start(op, key string) trace.Span {
+	_, span := s.tracer.Start(context.Background(), s.prefix+op, trace.WithAttributes(attribute.Int("storages.key.length", len(key))))
+
+	return span
+}
+
+func endSpan(span trace.Span, size int, err error) {
+	span.SetAttributes(attribute.Int("storages.value.size", size))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func (s *tracingStorer) Get(key string) []byte {
+	span := s.start("Get", key)
+	value := s.Storer.Get(key)
+	endSpan(span, len(value), nil)
+
+	return value
+}
+
+func (s *tracingStorer) GetWithError(key string) ([]byte, error) {
+	span := s.start("Get", key)
+	value, err := s.Storer.GetWithError(key)
+
+	if errors.Is(err, ErrKeyNotFound) {
+		endSpan(span, 0, nil)
+	} else {
+		endSpan(span, len(value), err)
+	}
+
+	return value, err
+}
+
+func (s *tracingStorer) Set(key string, value []byte, duration time.Duration) error {
+	span := s.start("Set", key)
+	err := s.Storer.Set(key, value, duration)
+	endSpan(span, len(value), err)
+
+	return err
+}
+
+func (s *tracingStorer) GetMultiLevel(key string, req *http.Request, validator *Revalidator) (fresh *http.Response, stale *http.Response) {
+	span := s.start("GetMultiLevel", key)
+	fresh, stale = s.Storer.GetMultiLevel(key, req, validator)
+
+	size := 0
+	if fresh != nil {
+		size = int(fresh.ContentLength)
+	} else if stale != nil {
+		size = int(stale.ContentLength)
+	}
+
+	endSpan(span, size, nil)
+
+	return fresh, stale
+}
+
+func (s *tracingStorer) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	span := s.start("SetMultiLevel", variedKey)
+	err := s.Storer.SetMultiLevel(baseKey, variedKey, value, variedHeaders, etag, duration, realKey)
+	endSpan(span, len(value), err)
+
+	return err
+}
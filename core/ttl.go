@@ -0,0 +1,75 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoExpiration is returned by GetTTL when the key exists without any expiry.
+const NoExpiration = time.Duration(-1)
+
+// NormalizeTTL tells if a value stored for the given duration must be kept.
+// A non-positive duration means the value must not be stored and any existing key removed.
+func NormalizeTTL(duration time.Duration) (store bool, ttl time.Duration) {
+	if duration <= 0 {
+		return false, 0
+	}
+
+	return true, duration
+}
+
+// multiLevelTTLKey carries in the varied headers handed to SetMultiLevel the duration a decorator derived from
+// the Cache-Control directives, the jittered one. It isn't a valid header name so no request header can match
+// it, MappingUpdaterWith drops it before mapping the varied headers.
+const multiLevelTTLKey = ":ttl"
+
+// MultiLevelTTL returns the duration of the response stored by SetMultiLevel from the Cache-Control directives
+// of the varied headers and whether it must be stored at all, see ttlFromHeaders. The storages delete the
+// existing varied key instead of storing a no-store response. The directives stay in the mapped varied headers,
+// they're part of the variant match so only the requests carrying the same Cache-Control elect the response.
+func MultiLevelTTL(variedHeaders http.Header, duration time.Duration) (time.Duration, bool) {
+	if values := variedHeaders[multiLevelTTLKey]; len(values) == 1 {
+		if ttl, err := time.ParseDuration(values[0]); err == nil {
+			return ttl, true
+		}
+	}
+
+	return ttlFromHeaders(variedHeaders, duration)
+}
+
+// ttlFromHeaders returns the s-maxage of the Cache-Control directives, the shared caches one, then the max-age
+// and the fallback when none is valid. It returns false when a no-store directive forbids the caching.
+func ttlFromHeaders(h http.Header, fallback time.Duration) (time.Duration, bool) {
+	maxAge, sharedMaxAge := -1, -1
+
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			seconds, err := strconv.Atoi(strings.Trim(argument, `"`))
+
+			switch strings.ToLower(name) {
+			case "no-store":
+				return 0, false
+			case "s-maxage":
+				if err == nil && seconds >= 0 {
+					sharedMaxAge = seconds
+				}
+			case "max-age":
+				if err == nil && seconds >= 0 {
+					maxAge = seconds
+				}
+			}
+		}
+	}
+
+	switch {
+	case sharedMaxAge >= 0:
+		return time.Duration(sharedMaxAge) * time.Second, true
+	case maxAge >= 0:
+		return time.Duration(maxAge) * time.Second, true
+	default:
+		return fallback, true
+	}
+}
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidConfig is wrapped by each problem reported by ValidateConfig.
+var ErrInvalidConfig = errors.New("invalid storage configuration")
+
+// ConfigCheck validates the options specific to a storage, see ValidateConfig.
+type ConfigCheck func(cp CacheProvider) error
+
+// ValidateConfig checks the cache provider options shared by the storages, the compression, key hashing
+// and checksum names, the sizes and the durations, then runs the checks given by the storage. Every
+// problem is reported, the returned error joins them and each one wraps ErrInvalidConfig.
+// The storages run it before connecting when the Validate option is set.
+func ValidateConfig(cp CacheProvider, checks ...ConfigCheck) error {
+	var errs []error
+
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	if !slices.Contains([]string{"", CompressionLZ4, CompressionZstd, CompressionAuto, CompressionNone}, cp.Compression) {
+		invalid("%w %q, it must be %s, %s, %s or %s", ErrUnknownCompression, cp.Compression, CompressionLZ4, CompressionZstd, CompressionAuto, CompressionNone)
+	}
+
+	if cp.CompressionLevel < 0 || cp.CompressionLevel > MaxLZ4CompressionLevel {
+		invalid("the compression level %d must be between 0 and %d", cp.CompressionLevel, MaxLZ4CompressionLevel)
+	}
+
+	if cp.CompressionBlockSize != 0 && !slices.Contains(lz4BlockSizes, cp.CompressionBlockSize) {
+		invalid("the compression block size %d must be one of %v", cp.CompressionBlockSize, lz4BlockSizes)
+	}
+
+	if len(cp.CompressionDictionary) > 0 || cp.CompressionDictionaryPath != "" {
+		if cp.Compression != CompressionZstd && cp.Compression != CompressionAuto {
+			invalid("the compression dictionary requires the %s or %s compression, %q provided", CompressionZstd, CompressionAuto, cp.Compression)
+		}
+
+		if _, err := configuredDictionary(cp); err != nil {
+			invalid("the compression_dictionary_path %s can't be read, %v", cp.CompressionDictionaryPath, err)
+		}
+	}
+
+	if !slices.Contains([]string{"", KeyHashingNone, KeyHashingSHA256, KeyHashingXXHash}, cp.KeyHashing) {
+		invalid("unknown key hashing %q, it must be %s, %s or %s", cp.KeyHashing, KeyHashingNone, KeyHashingSHA256, KeyHashingXXHash)
+	}
+
+	if !slices.Contains([]string{"", ChecksumNone, ChecksumCRC32C, ChecksumXXHash}, cp.Checksum) {
+		invalid("unknown checksum %q, it must be %s, %s or %s", cp.Checksum, ChecksumNone, ChecksumCRC32C, ChecksumXXHash)
+	}
+
+	if !slices.Contains([]string{"", MaxKeysReject, MaxKeysEvictOldest}, cp.MaxKeysPolicy) {
+		invalid("unknown max_keys_policy %q, it must be %s or %s", cp.MaxKeysPolicy, MaxKeysReject, MaxKeysEvictOldest)
+	}
+
+	if !slices.Contains([]string{"", IndexModeKeyValue, IndexModeKey}, cp.IndexMode) {
+		invalid("unknown index_mode %q, it must be %s or %s", cp.IndexMode, IndexModeKeyValue, IndexModeKey)
+	}
+
+	for name, size := range map[string]int64{
+		"max_value_size":              cp.MaxValueSize,
+		"max_keys":                    int64(cp.MaxKeys),
+		"max_cache_size_bytes":        cp.MaxCacheSizeBytes,
+		"segment_size":                cp.SegmentSize,
+		"max_variants":                int64(cp.MaxVariants),
+		"compression_min_size":        int64(cp.CompressionMinSize),
+		"shards":                      int64(cp.Shards),
+		"num_compactors":              int64(cp.NumCompactors),
+		"num_level_zero_tables":       int64(cp.NumLevelZeroTables),
+		"num_level_zero_tables_stall": int64(cp.NumLevelZeroTablesStall),
+		"base_table_size":             cp.BaseTableSize,
+		"read_cache_size":             int64(cp.ReadCacheSize),
+		"conflict_max_attempts":       int64(cp.ConflictMaxAttempts),
+	} {
+		if size < 0 {
+			invalid("the %s %d must not be negative", name, size)
+		}
+	}
+
+	if cp.NumCompactors == 1 {
+		invalid("the num_compactors %d must be at least 2", cp.NumCompactors)
+	}
+
+	if cp.NumLevelZeroTablesStall > 0 && cp.NumLevelZeroTablesStall <= cp.NumLevelZeroTables {
+		invalid("the num_level_zero_tables_stall %d must be greater than the num_level_zero_tables %d", cp.NumLevelZeroTablesStall, cp.NumLevelZeroTables)
+	}
+
+	if cp.DirPermissions&^os.ModePerm != 0 || (cp.DirPermissions != 0 && cp.DirPermissions&0o700 != 0o700) {
+		invalid("the dir_permissions %#o must only hold permission bits including the owner ones", cp.DirPermissions)
+	}
+
+	if cp.OperationTimeout < 0 {
+		invalid("the operation_timeout %v must not be negative", cp.OperationTimeout)
+	}
+
+	if cp.SlowLogThreshold < 0 {
+		invalid("the slow_log_threshold %v must not be negative", cp.SlowLogThreshold)
+	}
+
+	if cp.SweepInterval < 0 {
+		invalid("the sweep_interval %v must not be negative", cp.SweepInterval)
+	}
+
+	if cp.ValueLogGCInterval != nil && *cp.ValueLogGCInterval < 0 {
+		invalid("the value_log_gc_interval %v must not be negative", *cp.ValueLogGCInterval)
+	}
+
+	if cp.ValueLogGCDiscardRatio < 0 || cp.ValueLogGCDiscardRatio >= 1 {
+		invalid("the value_log_gc_discard_ratio %v must be between 0 and 1", cp.ValueLogGCDiscardRatio)
+	}
+
+	if cp.TTLJitter < 0 || cp.TTLJitter >= 1 {
+		invalid("the ttl_jitter %v must be between 0 and 1", cp.TTLJitter)
+	}
+
+	if err := CheckEncryptionKey(cp); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cp.EncryptionKeyRotationDuration < 0 {
+		invalid("the encryption_key_rotation_duration %v must not be negative", cp.EncryptionKeyRotationDuration)
+	}
+
+	for _, check := range checks {
+		if err := check(cp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckEncryptionKey checks the encryption key is empty or an AES key of 16, 24 or 32 bytes.
+func CheckEncryptionKey(cp CacheProvider) error {
+	switch len(cp.EncryptionKey) {
+	case 0, 16, 24, 32:
+		return nil
+	}
+
+	return fmt.Errorf("%w: the encryption_key must be 16, 24 or 32 bytes long, %d provided", ErrInvalidConfig, len(cp.EncryptionKey))
+}
+
+// CheckWritablePath checks the path is a writable file or directory, or can be created in a writable
+// directory. The in-memory storages and the empty path using the storage default are accepted.
+func CheckWritablePath(cp CacheProvider) error {
+	if cp.InMemory || cp.Path == "" || cp.Path == ":memory:" {
+		return nil
+	}
+
+	info, err := os.Stat(cp.Path)
+
+	switch {
+	case err == nil && info.IsDir():
+		return writableDirectory(cp.Path, cp.Path)
+	case err == nil:
+		file, err := os.OpenFile(cp.Path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("%w: the path %s is not writable, %w", ErrInvalidConfig, cp.Path, err)
+		}
+
+		return file.Close()
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: the path %s is not accessible, %w", ErrInvalidConfig, cp.Path, err)
+	}
+
+	// The storage creates the missing directories, the closest existing parent must be a writable one.
+	parent := filepath.Dir(filepath.Clean(cp.Path))
+	for {
+		if _, err = os.Stat(parent); err == nil {
+			return writableDirectory(cp.Path, parent)
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) || filepath.Dir(parent) == parent {
+			return fmt.Errorf("%w: the path %s can't be created, %w", ErrInvalidConfig, cp.Path, err)
+		}
+
+		parent = filepath.Dir(parent)
+	}
+}
+
+// writableDirectory checks a file can be created in the directory.
+func writableDirectory(path, directory string) error {
+	file, err := os.CreateTemp(directory, ".storages-validate-*")
+	if err != nil {
+		return fmt.Errorf("%w: the path %s is not writable, %w", ErrInvalidConfig, path, err)
+	}
+
+	_ = file.Close()
+
+	return os.Remove(file.Name())
+}
+
+// CheckURL checks each comma separated address of the URL is either a URL with a host, a unix socket URL
+// or an address accepted by ParseAddress.
+func CheckURL(cp CacheProvider) error {
+	if cp.URL == "" {
+		return nil
+	}
+
+	var errs []error
+
+	for _, address := range strings.Split(cp.URL, ",") {
+		address = strings.TrimSpace(address)
+
+		if strings.Contains(address, "://") {
+			parsed, err := url.Parse(address)
+
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%w: the url %q can't be parsed, %w", ErrInvalidConfig, address, err))
+			case parsed.Host == "" && parsed.Scheme != "unix":
+				errs = append(errs, fmt.Errorf("%w: the url %q has no host", ErrInvalidConfig, address))
+			}
+
+			continue
+		}
+
+		if _, _, err := ParseAddress(address); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
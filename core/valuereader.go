@@ -0,0 +1,9 @@
+package core
+
+// ValueReader is implemented by the storers able to hand the stored value to a callback without copying it.
+type ValueReader interface {
+	// GetInto calls fn with the value of the key within the read transaction and returns the fn error,
+	// ErrKeyNotFound is returned when the key is missing. The slice is only valid until fn returns, it must
+	// never be modified nor retained afterwards, a copy must be made to keep it.
+	GetInto(key string, fn func([]byte) error) error
+}
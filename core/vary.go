@@ -0,0 +1,72 @@
+package core
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// varyAllHeaders are the varied header names telling the response varies on everything, the Vary header
+// itself listing the * member or the * member stored as a header name.
+var varyAllHeaders = []string{"Vary", "*"}
+
+// VaryAll tells if the varied headers declare a Vary: * response. Such a response never matches another
+// request so SetMultiLevel maps it without freshness, GetMultiLevel only elects it as stale for the
+// revalidation and never as fresh.
+func VaryAll(variedHeaders http.Header) bool {
+	if _, found := variedHeaders["*"]; found {
+		return true
+	}
+
+	for _, value := range variedHeaders.Values("Vary") {
+		for _, member := range strings.Split(value, ",") {
+			if strings.TrimSpace(member) == "*" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// withoutVaryAll returns a copy of the varied headers without the ones declaring Vary: *, they aren't
+// request headers to compare.
+func withoutVaryAll(variedHeaders http.Header) http.Header {
+	headers := variedHeaders.Clone()
+	for _, name := range varyAllHeaders {
+		delete(headers, name)
+	}
+
+	return headers
+}
+
+// internalHeaders are the values the decorators hand to the storages through the varied headers of
+// SetMultiLevel, see multiLevelTTLKey and multiLevelCompressionKey.
+var internalHeaders = []string{multiLevelTTLKey, multiLevelCompressionKey}
+
+// withInternalHeader returns a copy of the varied headers carrying the internal value.
+func withInternalHeader(variedHeaders http.Header, name, value string) http.Header {
+	headers := variedHeaders.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	headers[name] = []string{value}
+
+	return headers
+}
+
+// withoutInternalHeaders returns the varied headers without the internal values, the headers as is when
+// they carry none.
+func withoutInternalHeaders(variedHeaders http.Header) http.Header {
+	if !slices.ContainsFunc(internalHeaders, func(name string) bool { return variedHeaders[name] != nil }) {
+		return variedHeaders
+	}
+
+	headers := variedHeaders.Clone()
+	for _, name := range internalHeaders {
+		delete(headers, name)
+	}
+
+	return headers
+}
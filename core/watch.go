@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// EventType tells the change reported by an Event.
+type EventType int
+
+const (
+	// EventSet reports a key written by any of the set operations.
+	EventSet EventType = iota
+	// EventDelete reports a key deleted, the native change feeds report the expired keys as deleted too.
+	EventDelete
+)
+
+// watchQueueSize bounds the events waiting to be received by each watcher.
+const watchQueueSize = 1024
+
+// String returns the event type name.
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a key change reported by Watcher.Watch.
+type Event struct {
+	Type EventType
+	Key  string
+}
+
+// Watcher is implemented by the storers able to report the changes of their keys.
+type Watcher interface {
+	// Watch streams the events of the keys starting with the prefix, the namespace excepted, until the
+	// context is done then closes the channel. The events are not replayed, only the changes made after
+	// Watch returned are streamed.
+	Watch(ctx context.Context, prefix string) (<-chan Event, error)
+}
+
+type watch struct {
+	prefix string
+	events chan Event
+}
+
+// WatchHub streams the events published by the storages without native change feed to their watchers.
+// Only the changes made through the instance are published, the writers never wait for the watchers and
+// the events are dropped with a warning once the queue of a watcher is full.
+type WatchHub struct {
+	logger   Logger
+	mu       sync.RWMutex
+	watchers map[*watch]struct{}
+	closed   bool
+	done     chan struct{}
+}
+
+// NewWatchHub returns a hub without watcher.
+func NewWatchHub(logger Logger) *WatchHub {
+	return &WatchHub{logger: logger, watchers: map[*watch]struct{}{}, done: make(chan struct{})}
+}
+
+// Watch registers a watcher of the keys starting with the prefix until the context is done, see Watcher.
+// ErrClosed is returned once the hub is closed.
+func (h *WatchHub) Watch(ctx context.Context, prefix string) (<-chan Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w := &watch{prefix: prefix, events: make(chan Event, watchQueueSize)}
+
+	h.mu.Lock()
+
+	if h.closed {
+		h.mu.Unlock()
+
+		return nil, ErrClosed
+	}
+
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			h.unwatch(w)
+		case <-h.done:
+		}
+	}()
+
+	return w.events, nil
+}
+
+// unwatch closes the channel of the watcher unless the hub closed it already.
+func (h *WatchHub) unwatch(w *watch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.watchers[w]; ok {
+		delete(h.watchers, w)
+		close(w.events)
+	}
+}
+
+// Publish queues the event of the key to the matching watchers without blocking.
+func (h *WatchHub) Publish(eventType EventType, key string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for w := range h.watchers {
+		if !strings.HasPrefix(key, w.prefix) {
+			continue
+		}
+
+		select {
+		case w.events <- Event{Type: eventType, Key: key}:
+		default:
+			h.logger.Warnf("The watch queue is full, the %s event of the key %s is not streamed.", eventType, key)
+		}
+	}
+}
+
+// Close closes the channel of every watcher, the next Watch calls return ErrClosed.
+func (h *WatchHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.closed = true
+	close(h.done)
+
+	for w := range h.watchers {
+		delete(h.watchers, w)
+		close(w.events)
+	}
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/dynamodb"
+)
+
+const moduleName = "dynamodb"
+
+// DynamoDB storage.
+type DynamoDB struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(DynamoDB{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (DynamoDB) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.dynamodb",
+		New: func() caddy.Module { return new(DynamoDB) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *DynamoDB) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := dynamodb.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *DynamoDB) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*DynamoDB)(nil)
+	_ caddyhttp.MiddlewareHandler = (*DynamoDB)(nil)
+)
@@ -0,0 +1,879 @@
+// Package dynamodb stores the cache entries as items of a DynamoDB table.
+//
+// Each item holds the key as its partition key, the value as a binary attribute and the expiry
+// in the ttl numeric attribute as epoch seconds, configured as the table time to live attribute.
+// DynamoDB deletes the expired items asynchronously, up to a few days later, so the items past
+// their ttl are treated as misses on read. The expiry has a one second resolution and an item
+// can't exceed 400KB.
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/darkweak/storages/core"
+)
+
+const (
+	keyAttribute   = "key"
+	valueAttribute = "value"
+	ttlAttribute   = "ttl"
+
+	// The BatchGetItem and BatchWriteItem limits per request.
+	batchGetSize   = 100
+	batchWriteSize = 25
+
+	tableCreationTimeout = 2 * time.Minute
+)
+
+// The attribute names are DynamoDB reserved words, the expressions use these placeholders.
+var attributeNames = map[string]string{"#k": keyAttribute, "#v": valueAttribute, "#t": ttlAttribute}
+
+// DynamoDB provider type.
+type DynamoDB struct {
+	*dynamodb.Client
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	table       string
+	endpoint    string
+	compression string
+	clock       core.Clock
+}
+
+// Factory function create new DynamoDB instance.
+func Factory(dynamoConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if dynamoConfiguration.Validate {
+		if err := core.ValidateConfig(dynamoConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the DynamoDB configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(dynamoConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, dynamoConfiguration, logger)
+}
+
+func factory(dynamoConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	endpoint := dynamoConfiguration.URL
+	table := "souin"
+	region := ""
+	accessKey, secretKey, sessionToken := "", "", ""
+
+	if dc, ok := dynamoConfiguration.Configuration.(map[string]interface{}); ok && dc != nil {
+		if v, found := dc["endpoint"]; found && v != nil {
+			endpoint = fmt.Sprint(v)
+		}
+
+		if v, found := dc["table"]; found && v != nil {
+			table = fmt.Sprint(v)
+		}
+
+		if v, found := dc["region"]; found && v != nil {
+			region = fmt.Sprint(v)
+		}
+
+		if v, found := dc["access_key_id"]; found && v != nil {
+			accessKey = fmt.Sprint(v)
+		}
+
+		if v, found := dc["secret_access_key"]; found && v != nil {
+			secretKey = fmt.Sprint(v)
+		}
+
+		if v, found := dc["session_token"]; found && v != nil {
+			sessionToken = fmt.Sprint(v)
+		}
+	}
+
+	// The default chain reads the environment, the shared files and the role of the instance.
+	options := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if accessKey != "" {
+		options = append(options, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, sessionToken)))
+	}
+
+	awsConfiguration, err := config.LoadDefaultConfig(context.Background(), options...)
+	if err != nil {
+		logger.Error("Impossible to load the AWS configuration.", err)
+
+		return nil, err
+	}
+
+	client := dynamodb.NewFromConfig(awsConfiguration, func(o *dynamodb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	provider := &DynamoDB{
+		Client:      client,
+		stale:       stale,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(dynamoConfiguration),
+		table:       table,
+		endpoint:    endpoint,
+		compression: core.ConfiguredCompression(dynamoConfiguration, logger),
+		clock:       core.ClockOrDefault(dynamoConfiguration.Clock),
+	}
+
+	if err = provider.createTable(context.Background()); err != nil {
+		logger.Errorf("Impossible to create the DynamoDB table %s, %v", table, err)
+
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// createTable creates the on-demand table with its time to live attribute if it doesn't exist.
+func (provider *DynamoDB) createTable(ctx context.Context) error {
+	_, err := provider.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(provider.table)})
+	if err == nil {
+		return nil
+	}
+
+	if !isNotFound(err) {
+		return err
+	}
+
+	_, err = provider.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:            aws.String(provider.table),
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String(keyAttribute), AttributeType: types.ScalarAttributeTypeS}},
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String(keyAttribute), KeyType: types.KeyTypeHash}},
+		BillingMode:          types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = dynamodb.NewTableExistsWaiter(provider.Client).Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(provider.table)}, tableCreationTimeout)
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName:               aws.String(provider.table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{AttributeName: aws.String(ttlAttribute), Enabled: aws.Bool(true)},
+	})
+
+	return err
+}
+
+func isNotFound(err error) bool {
+	var notFound *types.ResourceNotFoundException
+
+	return errors.As(err, &notFound)
+}
+
+func isConditionFailed(err error) bool {
+	var conditionFailed *types.ConditionalCheckFailedException
+
+	return errors.As(err, &conditionFailed)
+}
+
+func itemKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{keyAttribute: &types.AttributeValueMemberS{Value: key}}
+}
+
+// expiresAt returns the expiry in epoch seconds rounded up, zero means no expiration.
+func (provider *DynamoDB) expiresAt(duration time.Duration) int64 {
+	if duration <= 0 {
+		return 0
+	}
+
+	expiry := provider.clock.Now().Add(duration)
+	if expiry.Nanosecond() > 0 {
+		return expiry.Unix() + 1
+	}
+
+	return expiry.Unix()
+}
+
+// namesOf returns the placeholders of the attribute names used by the expression.
+func namesOf(expression string) map[string]string {
+	names := map[string]string{}
+
+	for placeholder, name := range attributeNames {
+		if strings.Contains(expression, placeholder) {
+			names[placeholder] = name
+		}
+	}
+
+	return names
+}
+
+func (provider *DynamoDB) nowValue() types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(provider.clock.Now().Unix(), 10)}
+}
+
+func (provider *DynamoDB) newItem(key string, value []byte, duration time.Duration) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		keyAttribute:   &types.AttributeValueMemberS{Value: key},
+		valueAttribute: &types.AttributeValueMemberB{Value: value},
+	}
+
+	if expiry := provider.expiresAt(duration); expiry > 0 {
+		item[ttlAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiry, 10)}
+	}
+
+	return item
+}
+
+func itemString(item map[string]types.AttributeValue, attribute string) string {
+	if value, ok := item[attribute].(*types.AttributeValueMemberS); ok {
+		return value.Value
+	}
+
+	return ""
+}
+
+func itemValue(item map[string]types.AttributeValue) []byte {
+	if value, ok := item[valueAttribute].(*types.AttributeValueMemberB); ok {
+		return value.Value
+	}
+
+	return nil
+}
+
+// itemExpiry returns the ttl attribute of the item, the zero time means no expiration.
+func itemExpiry(item map[string]types.AttributeValue) time.Time {
+	value, ok := item[ttlAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return time.Time{}
+	}
+
+	expiry, err := strconv.ParseInt(value.Value, 10, 64)
+	if err != nil || expiry == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(expiry, 0)
+}
+
+// expired reports whether DynamoDB didn't delete yet the item past its ttl.
+func (provider *DynamoDB) expired(item map[string]types.AttributeValue) bool {
+	expiry := itemExpiry(item)
+
+	return !expiry.IsZero() && !provider.clock.Now().Before(expiry)
+}
+
+// Name returns the storer name.
+func (provider *DynamoDB) Name() string {
+	return "DYNAMODB"
+}
+
+// Uuid returns an unique identifier.
+func (provider *DynamoDB) Uuid() string {
+	return fmt.Sprintf("%s-%s-%s-%s", provider.endpoint, provider.Options().Region, provider.table, provider.stale)
+}
+
+// scan calls fn with the live items whose key begins with the prefix, stopping on the first error.
+func (provider *DynamoDB) scan(ctx context.Context, prefix string, projection string, fn func(item map[string]types.AttributeValue)) error {
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(provider.table),
+		ConsistentRead:           aws.Bool(true),
+		ProjectionExpression:     aws.String(projection),
+		ExpressionAttributeNames: namesOf(projection),
+	}
+
+	if prefix != "" {
+		input.FilterExpression = aws.String("begins_with(#k, :prefix)")
+		input.ExpressionAttributeNames["#k"] = keyAttribute
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{":prefix": &types.AttributeValueMemberS{Value: prefix}}
+	}
+
+	paginator := dynamodb.NewScanPaginator(provider.Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Items {
+			if !provider.expired(item) {
+				fn(item)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MapKeys method returns the map of existing keys scanning the table with a prefix filter.
+func (provider *DynamoDB) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	err := provider.scan(context.Background(), prefix, "#k, #v, #t", func(item map[string]types.AttributeValue) {
+		key := itemString(item, keyAttribute)
+		if core.ListedKey(prefix, key) {
+			keys[strings.TrimPrefix(key, prefix)] = string(itemValue(item))
+		}
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in DynamoDB, %v", err)
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *DynamoDB) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// DynamoDB scans the items in hash order so the matching keys are all read then sorted.
+func (provider *DynamoDB) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	err := provider.scan(context.Background(), prefix, "#k, #t", func(item map[string]types.AttributeValue) {
+		keys = append(keys, itemString(item, keyAttribute))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to scan the keys in DynamoDB, %v", err)
+	}
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *DynamoDB) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *DynamoDB) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in DynamoDB: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *DynamoDB) GetContext(ctx context.Context, key string) ([]byte, error) {
+	item, err := provider.getItem(ctx, key, "#k, #v, #t")
+	if err != nil {
+		return nil, err
+	}
+
+	return itemValue(item), nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *DynamoDB) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// getItem returns the projected attributes of the item if it exists and isn't expired.
+func (provider *DynamoDB) getItem(ctx context.Context, key, projection string) (map[string]types.AttributeValue, error) {
+	res, err := provider.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:                aws.String(provider.table),
+		Key:                      itemKey(key),
+		ConsistentRead:           aws.Bool(true),
+		ProjectionExpression:     aws.String(projection),
+		ExpressionAttributeNames: namesOf(projection),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Item) == 0 || provider.expired(res.Item) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return res.Item, nil
+}
+
+// GetMany method returns the values of the existing keys by batches of 100 keys.
+func (provider *DynamoDB) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for start := 0; start < len(keys); start += batchGetSize {
+		batch := keys[start:min(start+batchGetSize, len(keys))]
+		requested := make([]map[string]types.AttributeValue, 0, len(batch))
+		seen := make(map[string]bool, len(batch))
+
+		// BatchGetItem rejects the duplicated keys.
+		for _, key := range batch {
+			if !seen[key] {
+				seen[key] = true
+				requested = append(requested, itemKey(key))
+			}
+		}
+
+		request := map[string]types.KeysAndAttributes{provider.table: {Keys: requested, ConsistentRead: aws.Bool(true)}}
+
+		for len(request) > 0 {
+			res, err := provider.BatchGetItem(context.Background(), &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				provider.logger.Errorf("Impossible to get the keys in DynamoDB, %v", err)
+
+				break
+			}
+
+			for _, item := range res.Responses[provider.table] {
+				if !provider.expired(item) {
+					result[itemString(item, keyAttribute)] = itemValue(item)
+				}
+			}
+
+			request = res.UnprocessedKeys
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *DynamoDB) GetTTL(key string) (time.Duration, bool) {
+	item, err := provider.getItem(context.Background(), key, "#k, #t")
+	if err != nil {
+		return 0, false
+	}
+
+	if expiry := itemExpiry(item); !expiry.IsZero() {
+		return expiry.Sub(provider.clock.Now()), true
+	}
+
+	return core.NoExpiration, true
+}
+
+// Exists method will check the key in DynamoDB provider without reading its value.
+func (provider *DynamoDB) Exists(key string) bool {
+	_, err := provider.getItem(context.Background(), key, "#k, #t")
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *DynamoDB) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *DynamoDB) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in DynamoDB", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *DynamoDB) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into DynamoDB, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in DynamoDB, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in DynamoDB: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in DynamoDB", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in DynamoDB provider.
+func (provider *DynamoDB) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response unless the context is done before the item is written.
+// A non-positive duration stores the item without expiry.
+func (provider *DynamoDB) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(provider.table), Item: provider.newItem(key, value, duration)})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into DynamoDB, %v", err)
+	}
+
+	return err
+}
+
+// writeBatches sends the write requests by batches of 25, resending the unprocessed ones.
+func (provider *DynamoDB) writeBatches(requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += batchWriteSize {
+		pending := map[string][]types.WriteRequest{provider.table: requests[start:min(start+batchWriteSize, len(requests))]}
+
+		for len(pending) > 0 {
+			res, err := provider.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+
+			pending = res.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// SetMany method will store the entries in DynamoDB provider by batches of 25 items.
+// The batches written before a failure are kept and the first error is returned.
+func (provider *DynamoDB) SetMany(items map[string]core.Entry) error {
+	requests := make([]types.WriteRequest, 0, len(items))
+
+	for key, item := range items {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: provider.newItem(key, item.Value, item.Duration)}})
+	}
+
+	err := provider.writeBatches(requests)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the entries into DynamoDB, %v", err)
+	}
+
+	return err
+}
+
+// SetNX method will store the response in DynamoDB provider only if the key doesn't exist yet or is expired.
+func (provider *DynamoDB) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	_, err := provider.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:                 aws.String(provider.table),
+		Item:                      provider.newItem(key, value, duration),
+		ConditionExpression:       aws.String("attribute_not_exists(#k) OR #t <= :now"),
+		ExpressionAttributeNames:  namesOf("#k #t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":now": provider.nowValue()},
+	})
+	if isConditionFailed(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into DynamoDB, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in DynamoDB provider only if the stored bytes equal old.
+// The comparison is the condition of the write, a nil old value creates the key like SetNX.
+func (provider *DynamoDB) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	_, err := provider.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:                aws.String(provider.table),
+		Item:                     provider.newItem(key, value, duration),
+		ConditionExpression:      aws.String("#v = :old AND (attribute_not_exists(#t) OR #t > :now)"),
+		ExpressionAttributeNames: namesOf("#v #t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":old": &types.AttributeValueMemberB{Value: old},
+			":now": provider.nowValue(),
+		},
+	})
+	if isConditionFailed(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into DynamoDB, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Increment method will add delta to the counter stored in DynamoDB provider.
+// The write is conditioned on the read value and retried when a concurrent writer updated the counter in between.
+func (provider *DynamoDB) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		value, updated, err := provider.increment(key, delta, duration)
+		if err != nil {
+			if !errors.Is(err, core.ErrNotCounter) {
+				provider.logger.Errorf("Impossible to increment the key %s into DynamoDB, %v", key, err)
+			}
+
+			return 0, err
+		}
+
+		if updated {
+			return value, nil
+		}
+	}
+}
+
+func (provider *DynamoDB) increment(key string, delta int64, duration time.Duration) (int64, bool, error) {
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String(provider.table),
+		ConditionExpression:       aws.String("attribute_not_exists(#k) OR #t <= :now"),
+		ExpressionAttributeNames:  namesOf("#k #t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":now": provider.nowValue()},
+	}
+
+	current, err := provider.getItem(context.Background(), key, "#k, #v, #t")
+
+	switch {
+	case errors.Is(err, core.ErrKeyNotFound):
+	case err != nil:
+		return 0, false, err
+	default:
+		input.ConditionExpression = aws.String("#v = :current")
+		input.ExpressionAttributeNames = namesOf("#v")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{":current": current[valueAttribute]}
+	}
+
+	value, encoded, err := core.AddCounter(itemValue(current), delta)
+	if err != nil {
+		return 0, false, err
+	}
+
+	input.Item = provider.newItem(key, encoded, duration)
+
+	_, err = provider.PutItem(context.Background(), input)
+	if isConditionFailed(err) {
+		return 0, false, nil
+	}
+
+	return value, err == nil, err
+}
+
+// Decrement method will subtract delta from the counter stored in DynamoDB provider.
+func (provider *DynamoDB) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in DynamoDB provider.
+func (provider *DynamoDB) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into DynamoDB, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *DynamoDB) GetStream(key string) (io.ReadCloser, error) {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *DynamoDB) Touch(key string, duration time.Duration) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(provider.table),
+		Key:                       itemKey(key),
+		UpdateExpression:          aws.String("REMOVE #t"),
+		ConditionExpression:       aws.String("attribute_exists(#k) AND (attribute_not_exists(#t) OR #t > :now)"),
+		ExpressionAttributeNames:  namesOf("#k #t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":now": provider.nowValue()},
+	}
+
+	if expiry := provider.expiresAt(duration); expiry > 0 {
+		input.UpdateExpression = aws.String("SET #t = :ttl")
+		input.ExpressionAttributeValues[":ttl"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiry, 10)}
+	}
+
+	_, err := provider.UpdateItem(context.Background(), input)
+	if isConditionFailed(err) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into DynamoDB, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in DynamoDB provider if exists corresponding to key param.
+func (provider *DynamoDB) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	_, err := provider.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{TableName: aws.String(provider.table), Key: itemKey(key)})
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in DynamoDB, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in DynamoDB provider if exists corresponding to the prefix pattern param.
+// The scanned keys are deleted by batches of 25.
+func (provider *DynamoDB) DeleteMany(pattern string) {
+	requests := []types.WriteRequest{}
+
+	err := provider.scan(context.Background(), core.KeyPrefix(pattern), "#k", func(item map[string]types.AttributeValue) {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: itemKey(itemString(item, keyAttribute))}})
+	})
+	if err == nil {
+		err = provider.writeBatches(requests)
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in DynamoDB, %v", pattern, err)
+	}
+}
+
+// DeleteKeys method will delete the keys with their metadata in DynamoDB provider by batches of 25 items.
+// The missing keys are ignored, the batches written before a failure are kept and the first error is returned.
+func (provider *DynamoDB) DeleteKeys(keys []string) error {
+	requests := []types.WriteRequest{}
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+
+	for _, key := range deleted {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: itemKey(key)}})
+	}
+
+	err := provider.writeBatches(requests)
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in DynamoDB, %v", err)
+
+		return err
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in DynamoDB provider.
+func (provider *DynamoDB) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in DynamoDB provider.
+func (provider *DynamoDB) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in DynamoDB provider.
+func (provider *DynamoDB) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in DynamoDB provider without altering it.
+func (provider *DynamoDB) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in DynamoDB provider and its metadata under a parallel key.
+func (provider *DynamoDB) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in DynamoDB provider, core.ErrKeyNotFound if none.
+func (provider *DynamoDB) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the item count and the size of the table.
+// DynamoDB refreshes them about every six hours and still counts the expired items not deleted yet.
+func (provider *DynamoDB) Stats() (core.StorageStats, error) {
+	res, err := provider.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String(provider.table)})
+	if err != nil {
+		provider.logger.Errorf("Impossible to describe the DynamoDB table %s, %v", provider.table, err)
+
+		return core.StorageStats{}, err
+	}
+
+	return core.StorageStats{
+		KeyCount:        aws.ToInt64(res.Table.ItemCount),
+		ApproxSizeBytes: aws.ToInt64(res.Table.TableSizeBytes),
+	}, nil
+}
+
+// Ping method will check the DynamoDB table exists.
+func (provider *DynamoDB) Ping(ctx context.Context) error {
+	_, err := provider.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(provider.table)})
+
+	return err
+}
+
+// Export method will stream every key of the DynamoDB provider paginating the scanned keys.
+func (provider *DynamoDB) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in DynamoDB provider by batches.
+func (provider *DynamoDB) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *DynamoDB) Init() error {
+	return nil
+}
+
+// Reset method will delete every item of the table.
+func (provider *DynamoDB) Reset() error {
+	provider.DeleteMany("*")
+
+	return nil
+}
+
+// Close method will do nothing, the DynamoDB client holds no dedicated resource.
+func (provider *DynamoDB) Close() error {
+	return nil
+}
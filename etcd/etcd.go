@@ -6,13 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
-	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/darkweak/storages/core"
-	"github.com/pierrec/lz4/v4"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/connectivity"
@@ -25,12 +27,71 @@ type Etcd struct {
 	stale         time.Duration
 	ctx           context.Context
 	logger        core.Logger
+	mapper        core.Mapper
+	compression   string
+	clock         core.Clock
 	reconnecting  bool
 	configuration clientv3.Config
+	leases        *leasePool
+}
+
+const (
+	// defaultPort completes the configured endpoints without port.
+	defaultPort = "2379"
+
+	// leaseSlackRatio is the inverse share of its TTL a pooled lease outlives the writes reusing it by.
+	leaseSlackRatio = 10
+	minLeaseSlack   = time.Second
+)
+
+type pooledLease struct {
+	id            clientv3.LeaseID
+	reusableUntil time.Time
+}
+
+// leasePool shares a lease between the writes of the same TTL in seconds instead of granting one per write.
+// A lease is granted for the TTL plus a slack of a tenth of it, at least 1s, and reused during this slack so
+// the keys expire between their TTL and their TTL plus the slack.
+type leasePool struct {
+	mu     sync.Mutex
+	leases map[int64]pooledLease
+}
+
+func newLeasePool() *leasePool {
+	return &leasePool{leases: map[int64]pooledLease{}}
+}
+
+// forget drops the lease unknown to the cluster, the next write of its TTL grants a new one.
+func (pool *leasePool) forget(id clientv3.LeaseID) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for ttl, lease := range pool.leases {
+		if lease.id == id {
+			delete(pool.leases, ttl)
+		}
+	}
 }
 
 // Factory function create new Etcd instance.
 func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if etcdCfg.Validate {
+		if err := core.ValidateConfig(etcdCfg, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Etcd configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(etcdCfg, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, etcdCfg, logger)
+}
+
+func factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	etcdConfiguration := clientv3.Config{
 		DialTimeout:      5 * time.Second,
 		AutoSyncInterval: 1 * time.Second,
@@ -42,7 +103,14 @@ func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration
 	}
 
 	if etcdCfg.URL != "" {
-		etcdConfiguration.Endpoints = strings.Split(etcdCfg.URL, ",")
+		endpoints, err := parseEndpoints(etcdCfg.URL)
+		if err != nil {
+			logger.Errorf("Impossible to parse the Etcd endpoints %s, %v", etcdCfg.URL, err)
+
+			return nil, err
+		}
+
+		etcdConfiguration.Endpoints = endpoints
 	} else {
 		bc, err := json.Marshal(etcdCfg.Configuration)
 		if err != nil {
@@ -71,10 +139,92 @@ func Factory(etcdCfg core.CacheProvider, logger core.Logger, stale time.Duration
 		ctx:           context.Background(),
 		stale:         stale,
 		logger:        logger,
+		mapper:        core.ConfiguredMapper(etcdCfg),
+		compression:   core.ConfiguredCompression(etcdCfg, logger),
+		clock:         core.ClockOrDefault(etcdCfg.Clock),
 		configuration: etcdConfiguration,
+		leases:        newLeasePool(),
 	}, nil
 }
 
+// lease returns the pooled lease of the duration rounded up to the second, granting it when there is
+// no reusable one.
+func (provider *Etcd) lease(duration time.Duration) (clientv3.LeaseID, error) {
+	ttl := max(int64(math.Ceil(duration.Seconds())), 1)
+	slack := max(time.Duration(ttl)*time.Second/leaseSlackRatio, minLeaseSlack)
+	now := time.Now()
+
+	provider.leases.mu.Lock()
+	defer provider.leases.mu.Unlock()
+
+	if lease, ok := provider.leases.leases[ttl]; ok && now.Before(lease.reusableUntil) {
+		return lease.id, nil
+	}
+
+	rs, err := provider.Grant(provider.ctx, ttl+int64(math.Ceil(slack.Seconds())))
+	if err != nil {
+		return 0, err
+	}
+
+	for bucket, lease := range provider.leases.leases {
+		if !now.Before(lease.reusableUntil) {
+			delete(provider.leases.leases, bucket)
+		}
+	}
+
+	provider.leases.leases[ttl] = pooledLease{id: rs.ID, reusableUntil: now.Add(slack)}
+
+	return rs.ID, nil
+}
+
+// forgetLease drops the pooled lease when the error reports the cluster doesn't know it anymore.
+func (provider *Etcd) forgetLease(id clientv3.LeaseID, err error) {
+	if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		provider.leases.forget(id)
+	}
+}
+
+// put stores the value under the pooled lease of the duration.
+func (provider *Etcd) put(key, value string, duration time.Duration, opts ...clientv3.OpOption) error {
+	id, err := provider.lease(duration)
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.Put(provider.ctx, key, value, append(opts, clientv3.WithLease(id))...)
+	provider.forgetLease(id, err)
+
+	return err
+}
+
+// parseEndpoints returns the comma separated endpoints, the URLs are given as is to the client parsing their
+// scheme and the addresses are completed by the default etcd port, the unix sockets being named unix://path.
+func parseEndpoints(url string) ([]string, error) {
+	endpoints := []string{}
+
+	for _, value := range strings.Split(url, ",") {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "://") && !strings.HasPrefix(value, "unix://") && !strings.HasPrefix(value, "tcp://") {
+			endpoints = append(endpoints, value)
+
+			continue
+		}
+
+		network, address, err := core.ParseAddressWithDefaultPort(value, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+
+		if network == "unix" {
+			address = "unix://" + address
+		}
+
+		endpoints = append(endpoints, address)
+	}
+
+	return endpoints, nil
+}
+
 // Name returns the storer name.
 func (provider *Etcd) Name() string {
 	return "ETCD"
@@ -111,7 +261,7 @@ func (provider *Etcd) ListKeys() []string {
 	}
 
 	for _, k := range result.Kvs {
-		mapping, err := core.DecodeMapping(k.Value)
+		mapping, err := provider.mapper.Decode(k.Value)
 		if err == nil {
 			for _, v := range mapping.GetMapping() {
 				keys = append(keys, v.GetRealKey())
@@ -122,7 +272,7 @@ func (provider *Etcd) ListKeys() []string {
 	return keys
 }
 
-// MapKeys method returns the map of existing keys.
+// MapKeys method returns the map of existing keys using a range query on the prefix.
 func (provider *Etcd) MapKeys(prefix string) map[string]string {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to list the etcd keys while reconnecting.")
@@ -132,7 +282,7 @@ func (provider *Etcd) MapKeys(prefix string) map[string]string {
 
 	keys := map[string]string{}
 
-	result, err := provider.Client.Get(provider.ctx, "\x00", clientv3.WithFromKey())
+	result, err := provider.Client.Get(provider.ctx, prefix, clientv3.WithPrefix())
 	if err != nil {
 		if !provider.reconnecting {
 			go provider.Reconnect()
@@ -142,16 +292,63 @@ func (provider *Etcd) MapKeys(prefix string) map[string]string {
 	}
 
 	for _, k := range result.Kvs {
-		key := string(k.Key)
-		if strings.HasPrefix(key, prefix) {
-			nk, _ := strings.CutPrefix(key, prefix)
-			keys[nk] = string(k.Value)
+		if core.ListedKey(prefix, string(k.Key)) {
+			keys[strings.TrimPrefix(string(k.Key), prefix)] = string(k.Value)
 		}
 	}
 
 	return keys
 }
 
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Etcd) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to scan the etcd keys while reconnecting.")
+
+		return []string{}, ""
+	}
+
+	opts := []clientv3.OpOption{clientv3.WithRange(clientv3.GetPrefixRangeEnd(prefix)), clientv3.WithKeysOnly()}
+	if limit > 0 {
+		opts = append(opts, clientv3.WithLimit(int64(limit)+1))
+	}
+
+	result, err := provider.Client.Get(provider.ctx, max(prefix, cursor), opts...)
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return []string{}, ""
+	}
+
+	keys = []string{}
+
+	for _, k := range result.Kvs {
+		if !core.ListedKey(prefix, string(k.Key)) {
+			continue
+		}
+
+		if limit > 0 && len(keys) == limit {
+			return keys, string(k.Key)
+		}
+
+		keys = append(keys, string(k.Key))
+	}
+
+	// The skipped internal keys shortened the page, the scan resumes after the last key read.
+	if limit > 0 && len(result.Kvs) > limit {
+		return keys, core.NextKey(string(result.Kvs[len(result.Kvs)-1].Key))
+	}
+
+	return keys, ""
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Etcd) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Etcd) Get(key string) (item []byte) {
 	if provider.reconnecting {
@@ -174,8 +371,100 @@ func (provider *Etcd) Get(key string) (item []byte) {
 	return
 }
 
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Etcd) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Etcd) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Etcd) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Etcd) GetTTL(key string) (time.Duration, bool) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the etcd key ttl while reconnecting.")
+
+		return 0, false
+	}
+
+	result, err := provider.Client.Get(provider.ctx, key)
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return 0, false
+	}
+
+	if len(result.Kvs) == 0 {
+		return 0, false
+	}
+
+	if result.Kvs[0].Lease == 0 {
+		return core.NoExpiration, true
+	}
+
+	lease, err := provider.TimeToLive(provider.ctx, clientv3.LeaseID(result.Kvs[0].Lease))
+	if err != nil || lease.TTL < 0 {
+		return 0, false
+	}
+
+	return time.Duration(lease.TTL) * time.Second, true
+}
+
+// Exists method will check the key in Etcd provider with a count only request.
+func (provider *Etcd) Exists(key string) bool {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to check the etcd key while reconnecting.")
+
+		return false
+	}
+
+	result, err := provider.Client.Get(provider.ctx, key, clientv3.WithCountOnly())
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return false
+	}
+
+	return result.Count > 0
+}
+
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Etcd) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Etcd) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to get the etcd key while reconnecting.")
 
@@ -186,14 +475,14 @@ func (provider *Etcd) GetMultiLevel(key string, req *http.Request, validator *co
 	if err != nil {
 		go provider.Reconnect()
 
-		return fresh, stale
+		return fresh, stale, match
 	}
 
 	if len(result.Kvs) > 0 {
-		fresh, stale, _ = core.MappingElection(provider, result.Kvs[0].Value, req, validator, provider.logger)
+		fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, result.Kvs[0].Value, req, validator, provider.logger)
 	}
 
-	return fresh, stale
+	return fresh, stale, match
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
@@ -204,7 +493,14 @@ func (provider *Etcd) SetMultiLevel(baseKey, variedKey string, value []byte, var
 		return errors.New("reconnecting error")
 	}
 
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
 
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to set the etcd value while reconnecting.")
@@ -216,29 +512,14 @@ func (provider *Etcd) SetMultiLevel(baseKey, variedKey string, value []byte, var
 		return fmt.Errorf("the connection is not ready: %v", provider.Client.ActiveConnection().GetState())
 	}
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
-
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
-
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Etcd, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Etcd, %v", variedKey, err)
-
-		return err
-	}
-
-	rs, err := provider.Grant(context.TODO(), int64(duration.Seconds()))
-	if err == nil {
-		_, err = provider.Put(provider.ctx, variedKey, compressed.String(), clientv3.WithLease(rs.ID))
-	}
-
-	if err != nil {
+	if err = provider.put(variedKey, string(compressed), duration+provider.stale); err != nil {
 		if !provider.reconnecting {
 			go provider.Reconnect()
 		}
@@ -248,10 +529,16 @@ func (provider *Etcd) SetMultiLevel(baseKey, variedKey string, value []byte, var
 		return err
 	}
 
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Etcd, %v", variedKey, err)
+
+		return err
+	}
+
 	mappingKey := core.MappingKeyPrefix + baseKey
 	result := provider.Get(mappingKey)
 
-	val, e := core.MappingUpdater(variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, e := core.MappingUpdaterWith(provider.mapper, variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if e != nil {
 		return e
 	}
@@ -271,11 +558,7 @@ func (provider *Etcd) Set(key string, value []byte, duration time.Duration) erro
 		return fmt.Errorf("the connection is not ready: %v", provider.Client.ActiveConnection().GetState())
 	}
 
-	rs, err := provider.Grant(context.TODO(), int64(duration.Seconds()))
-	if err == nil {
-		_, err = provider.Put(provider.ctx, key, string(value), clientv3.WithLease(rs.ID))
-	}
-
+	err := provider.put(key, string(value), duration)
 	if err != nil {
 		if !provider.reconnecting {
 			go provider.Reconnect()
@@ -287,6 +570,233 @@ func (provider *Etcd) Set(key string, value []byte, duration time.Duration) erro
 	return err
 }
 
+// SetContext method will store the response unless the context is done.
+func (provider *Etcd) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Etcd provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Etcd) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Etcd provider only if the key doesn't exist yet.
+func (provider *Etcd) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the etcd value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	id, err := provider.lease(duration)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Etcd, %v", key, err)
+
+		return false, err
+	}
+
+	res, err := provider.Txn(provider.ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(value), clientv3.WithLease(id))).
+		Commit()
+	if err != nil {
+		provider.forgetLease(id, err)
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Etcd, %v", key, err)
+
+		return false, err
+	}
+
+	return res.Succeeded, nil
+}
+
+// CompareAndSwap method will store the response in Etcd provider only if the stored bytes equal old.
+// The write is conditioned on the read revision and retried when a concurrent writer updated the key in between.
+func (provider *Etcd) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the etcd value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	for {
+		swapped, conflict, err := provider.compareAndSwap(key, old, value, duration)
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into Etcd, %v", key, err)
+
+			return false, err
+		}
+
+		if !conflict {
+			return swapped, nil
+		}
+	}
+}
+
+func (provider *Etcd) compareAndSwap(key string, old, value []byte, duration time.Duration) (bool, bool, error) {
+	res, err := provider.Client.Get(provider.ctx, key)
+	if err != nil {
+		return false, false, err
+	}
+
+	if len(res.Kvs) == 0 || !core.SwapMatches(res.Kvs[0].Value, old) {
+		return false, false, nil
+	}
+
+	id, err := provider.lease(duration)
+	if err != nil {
+		return false, false, err
+	}
+
+	txn, err := provider.Txn(provider.ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", res.Kvs[0].ModRevision)).
+		Then(clientv3.OpPut(key, string(value), clientv3.WithLease(id))).
+		Commit()
+	if err != nil {
+		provider.forgetLease(id, err)
+
+		return false, false, err
+	}
+
+	return txn.Succeeded, !txn.Succeeded, nil
+}
+
+// Increment method will add delta to the counter stored in Etcd provider.
+// The write is conditioned on the read revision and retried when a concurrent writer updated the counter in between.
+func (provider *Etcd) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the etcd value while reconnecting.")
+
+		return 0, errors.New("reconnecting error")
+	}
+
+	for {
+		value, updated, err := provider.increment(key, delta, duration)
+		if err != nil {
+			if !errors.Is(err, core.ErrNotCounter) {
+				provider.logger.Errorf("Impossible to increment the key %s into Etcd, %v", key, err)
+			}
+
+			return 0, err
+		}
+
+		if updated {
+			return value, nil
+		}
+	}
+}
+
+func (provider *Etcd) increment(key string, delta int64, duration time.Duration) (int64, bool, error) {
+	res, err := provider.Client.Get(provider.ctx, key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		current  []byte
+		revision int64
+	)
+
+	if len(res.Kvs) > 0 {
+		current, revision = res.Kvs[0].Value, res.Kvs[0].ModRevision
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		opts []clientv3.OpOption
+		id   clientv3.LeaseID
+	)
+
+	if duration > 0 {
+		if id, err = provider.lease(duration); err != nil {
+			return 0, false, err
+		}
+
+		opts = append(opts, clientv3.WithLease(id))
+	}
+
+	txn, err := provider.Txn(provider.ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", revision)).
+		Then(clientv3.OpPut(key, string(encoded), opts...)).
+		Commit()
+	if err != nil {
+		provider.forgetLease(id, err)
+
+		return 0, false, err
+	}
+
+	return value, txn.Succeeded, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Etcd provider.
+func (provider *Etcd) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Etcd provider.
+func (provider *Etcd) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Etcd, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Etcd) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Etcd) Touch(key string, duration time.Duration) error {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to touch the etcd key while reconnecting.")
+
+		return errors.New("reconnecting error")
+	}
+
+	result, err := provider.Client.Get(provider.ctx, key, clientv3.WithCountOnly())
+	if err != nil {
+		return err
+	}
+
+	if result.Count == 0 {
+		return core.ErrKeyNotFound
+	}
+
+	if err = provider.put(key, "", duration, clientv3.WithIgnoreValue()); err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Etcd, %v", key, err)
+	}
+
+	return err
+}
+
 // Delete method will delete the response in Etcd provider if exists corresponding to key param.
 func (provider *Etcd) Delete(key string) {
 	if provider.reconnecting {
@@ -295,30 +805,165 @@ func (provider *Etcd) Delete(key string) {
 		return
 	}
 
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	_, _ = provider.Client.Delete(provider.ctx, key)
 }
 
-// DeleteMany method will delete the responses in Etcd provider if exists corresponding to the regex key param.
-func (provider *Etcd) DeleteMany(key string) {
+// DeleteMany method will delete the responses in Etcd provider if exists corresponding to the prefix pattern param.
+func (provider *Etcd) DeleteMany(pattern string) {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to delete the etcd keys while reconnecting.")
 
 		return
 	}
 
-	rgKey, e := regexp.Compile(key)
-	if e != nil {
-		return
+	_, _ = provider.Client.Delete(provider.ctx, core.KeyPrefix(pattern), clientv3.WithPrefix())
+}
+
+// DeleteKeys method will delete the keys in Etcd provider one by one, the missing keys are ignored.
+func (provider *Etcd) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
 	}
 
-	if r, e := provider.Client.Get(provider.ctx, "\x00", clientv3.WithFromKey()); e == nil {
-		for _, k := range r.Kvs {
-			key := string(k.Key)
-			if rgKey.MatchString(key) {
-				provider.Delete(key)
-			}
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Etcd provider.
+func (provider *Etcd) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Etcd provider.
+func (provider *Etcd) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Etcd provider.
+func (provider *Etcd) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Etcd provider without altering it.
+func (provider *Etcd) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Etcd provider and its metadata under a parallel key.
+func (provider *Etcd) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Etcd provider, core.ErrKeyNotFound if none.
+func (provider *Etcd) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the key count computed by the server and the database size reported by the first endpoint.
+func (provider *Etcd) Stats() (core.StorageStats, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the etcd stats while reconnecting.")
+
+		return core.StorageStats{}, errors.New("reconnecting error")
+	}
+
+	res, err := provider.Client.Get(provider.ctx, "\x00", clientv3.WithFromKey(), clientv3.WithCountOnly())
+	if err != nil {
+		provider.logger.Errorf("Impossible to count the keys in Etcd, %v", err)
+
+		return core.StorageStats{}, err
+	}
+
+	stats := core.StorageStats{KeyCount: res.Count}
+
+	if endpoints := provider.Client.Endpoints(); len(endpoints) > 0 {
+		status, err := provider.Client.Status(provider.ctx, endpoints[0])
+		if err != nil {
+			provider.logger.Errorf("Impossible to get the Etcd status, %v", err)
+
+			return stats, err
 		}
+
+		stats.ApproxSizeBytes = status.DbSizeInUse
+	}
+
+	return stats, nil
+}
+
+// Ping method will send a count only request to the Etcd cluster.
+func (provider *Etcd) Ping(ctx context.Context) error {
+	if provider.reconnecting {
+		return errors.New("reconnecting error")
+	}
+
+	_, err := provider.Client.Get(ctx, "\x00", clientv3.WithCountOnly())
+
+	return err
+}
+
+// Watch method will stream the changes of the keys starting with the prefix using the Etcd watch API, see
+// core.Watcher. The keys expired with their lease are reported as deleted. The watch starts at the current
+// revision and the channel is closed too when the cluster cancels it, on a compaction for example.
+func (provider *Etcd) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to watch the etcd keys while reconnecting.")
+
+		return nil, errors.New("reconnecting error")
+	}
+
+	res, err := provider.Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Etcd revision to watch the prefix %s, %v", prefix, err)
+
+		return nil, err
 	}
+
+	changes := provider.Client.Watch(
+		clientv3.WithRequireLeader(ctx),
+		prefix,
+		clientv3.WithPrefix(),
+		clientv3.WithRev(res.Header.Revision+1),
+	)
+	events := make(chan core.Event)
+
+	go func() {
+		defer close(events)
+
+		for change := range changes {
+			if err := change.Err(); err != nil {
+				provider.logger.Errorf("Impossible to watch the prefix %s in Etcd, %v", prefix, err)
+
+				continue
+			}
+
+			for _, event := range change.Events {
+				eventType := core.EventDelete
+				if event.Type == clientv3.EventTypePut {
+					eventType = core.EventSet
+				}
+
+				select {
+				case events <- core.Event{Type: eventType, Key: string(event.Kv.Key)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Export method will stream every key of the Etcd provider paginating the scanned keys.
+func (provider *Etcd) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Etcd provider by batches.
+func (provider *Etcd) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
 }
 
 // Init method will.
@@ -326,9 +971,11 @@ func (provider *Etcd) Init() error {
 	return nil
 }
 
-// Reset method will reset or close provider.
+// Reset method will delete every key.
 func (provider *Etcd) Reset() error {
-	return provider.Close()
+	provider.DeleteMany("*")
+
+	return nil
 }
 
 func (provider *Etcd) Reconnect() {
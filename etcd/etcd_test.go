@@ -1,11 +1,21 @@
+//go:build etcd
+
 package etcd_test
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/darkweak/storages/core"
 	"github.com/darkweak/storages/etcd"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
 	"go.uber.org/zap"
 )
 
@@ -13,12 +23,51 @@ const (
 	byteKey        = "MyByteKey"
 	nonExistentKey = "NonExistentKey"
 	baseValue      = "My first data"
+
+	etcdEndpoint = "http://127.0.0.1:22379"
+	etcdPeer     = "http://127.0.0.1:22380"
 )
 
+// TestMain runs the tests against an embedded etcd server.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "souin-etcd")
+	if err != nil {
+		panic(err)
+	}
+
+	clientURL, _ := url.Parse(etcdEndpoint)
+	peerURL, _ := url.Parse(etcdPeer)
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls, cfg.AdvertiseClientUrls = []url.URL{*clientURL}, []url.URL{*clientURL}
+	cfg.ListenPeerUrls, cfg.AdvertisePeerUrls = []url.URL{*peerURL}, []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		panic("The embedded etcd server isn't ready")
+	}
+
+	code := m.Run()
+
+	server.Close()
+	_ = os.RemoveAll(dir)
+
+	os.Exit(code)
+}
+
 func getEtcdInstance() (core.Storer, error) {
 	return etcd.Factory(core.CacheProvider{
 		Configuration: map[string]interface{}{
-			"Endpoints": []string{"http://etcd:2379"},
+			"Endpoints": []string{etcdEndpoint},
 		},
 	}, zap.NewNop().Sugar(), 0)
 }
@@ -120,3 +169,145 @@ func TestEtcd_Init(t *testing.T) {
 		t.Error("Impossible to init Etcd provider")
 	}
 }
+
+func TestEtcd_Stats(t *testing.T) {
+	client, _ := getEtcdInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestEtcd_LeasePool(t *testing.T) {
+	client, _ := getEtcdInstance()
+
+	admin, err := clientv3.New(clientv3.Config{Endpoints: []string{etcdEndpoint}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Impossible to connect to the embedded etcd server: %v", err)
+	}
+
+	defer func() { _ = admin.Close() }()
+
+	before, err := admin.Leases(context.Background())
+	if err != nil {
+		t.Fatalf("Impossible to list the leases: %v", err)
+	}
+
+	for i := range 50 {
+		if err = client.Set(fmt.Sprintf("LeaseKey%d", i), []byte(baseValue), 30*time.Second); err != nil {
+			t.Fatalf("Impossible to set the key LeaseKey%d: %v", i, err)
+		}
+	}
+
+	after, _ := admin.Leases(context.Background())
+	if granted := len(after.Leases) - len(before.Leases); granted != 1 {
+		t.Errorf("The writes of the same TTL should share a single lease, %d granted", granted)
+	}
+
+	if ttl, found := client.GetTTL("LeaseKey49"); !found || ttl < 29*time.Second || ttl > 33*time.Second {
+		t.Errorf("The TTL should be between 30s and its 3s slack, %v provided", ttl)
+	}
+
+	_ = client.Set("LeaseOtherTTLKey", []byte(baseValue), time.Minute)
+
+	if other, _ := admin.Leases(context.Background()); len(other.Leases) != len(after.Leases)+1 {
+		t.Error("A write of another TTL should grant its own lease")
+	}
+}
+
+func TestEtcd_SetMultiLevel(t *testing.T) {
+	client, _ := getEtcdInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("MultiLevelKey", "MultiLevelKey-varied", []byte(response), http.Header{}, "", time.Minute, "MultiLevelKey"); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	if stored, err := core.Decompress(client.Get("MultiLevelKey-varied")); err != nil || string(stored) != response {
+		t.Errorf("The varied key should be stored compressed, %s provided: %v", stored, err)
+	}
+
+	mapping, err := core.DecodeMapping(client.Get(core.MappingKeyPrefix + "MultiLevelKey"))
+	if err != nil || mapping.GetMapping()["MultiLevelKey-varied"].GetRealKey() != "MultiLevelKey" {
+		t.Errorf("The mapping should link the base key to its variant, %v provided: %v", mapping, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/multi-level", nil)
+
+	fresh, _ := client.GetMultiLevel("MultiLevelKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The body should be %s, %s provided", baseValue, body)
+	}
+}
+
+func TestEtcd_MapKeys(t *testing.T) {
+	client, _ := getEtcdInstance()
+	client.DeleteMany("MAP_")
+
+	_ = client.Set("MAP_first", []byte("1"), time.Minute)
+	_ = client.Set("MAP_second", []byte("2"), time.Minute)
+	_ = client.Set("OTHER_third", []byte("3"), time.Minute)
+
+	keys := client.MapKeys("MAP_")
+	if len(keys) != 2 || keys["first"] != "1" || keys["second"] != "2" {
+		t.Errorf("Only the keys with the MAP_ prefix should be mapped, %v provided", keys)
+	}
+}
+
+func TestEtcd_Watch(t *testing.T) {
+	client, err := getEtcdInstance()
+	if err != nil {
+		t.Fatalf("Failed to create the instance: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
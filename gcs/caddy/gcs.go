@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/gcs"
+)
+
+const moduleName = "gcs"
+
+// GCS storage.
+type GCS struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(GCS{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (GCS) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.gcs",
+		New: func() caddy.Module { return new(GCS) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *GCS) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := gcs.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *GCS) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*GCS)(nil)
+	_ caddyhttp.MiddlewareHandler = (*GCS)(nil)
+)
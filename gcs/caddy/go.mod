@@ -0,0 +1,14 @@
+module github.com/darkweak/storages/gcs/caddy
+
+go 1.23.0
+
+require (
+	github.com/caddyserver/caddy/v2 v2.8.4
+	github.com/darkweak/storages/core v0.0.18
+	github.com/darkweak/storages/gcs v0.0.18
+)
+
+replace (
+	github.com/darkweak/storages/core => ../../core
+	github.com/darkweak/storages/gcs => ..
+)
@@ -0,0 +1,870 @@
+// Package gcs stores the cache entries as objects in a Google Cloud Storage bucket.
+//
+// GCS has no per-object time to live, the expiry is stored in the expires-at object
+// metadata and checked lazily on read, the expired objects are treated as misses and
+// removed when delete_expired is enabled. The lifecycle_age_days option adds a lifecycle
+// rule to the created bucket to eventually remove the objects that are never read again.
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/darkweak/storages/core"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const expiresMetadata = "expires-at"
+
+// GCS provider type.
+type GCS struct {
+	*storage.Client
+	stale         time.Duration
+	logger        core.Logger
+	mapper        core.Mapper
+	bucket        *storage.BucketHandle
+	bucketName    string
+	compression   string
+	clock         core.Clock
+	deleteExpired bool
+}
+
+// Factory function create new GCS instance.
+func Factory(gcsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if gcsConfiguration.Validate {
+		if err := core.ValidateConfig(gcsConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the GCS configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(gcsConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, gcsConfiguration, logger)
+}
+
+func factory(gcsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	endpoint := gcsConfiguration.URL
+	bucket := "souin"
+	projectID, credentialsFile, credentialsJSON := "", "", ""
+	lifecycleAgeDays := int64(0)
+	deleteExpired := false
+
+	if gc, ok := gcsConfiguration.Configuration.(map[string]interface{}); ok && gc != nil {
+		if v, found := gc["endpoint"]; found && v != nil {
+			endpoint = fmt.Sprint(v)
+		}
+
+		if v, found := gc["bucket"]; found && v != nil {
+			bucket = fmt.Sprint(v)
+		}
+
+		if v, found := gc["project_id"]; found && v != nil {
+			projectID = fmt.Sprint(v)
+		}
+
+		if v, found := gc["credentials_file"]; found && v != nil {
+			credentialsFile = fmt.Sprint(v)
+		}
+
+		if v, found := gc["credentials_json"]; found && v != nil {
+			credentialsJSON = fmt.Sprint(v)
+		}
+
+		if v, found := gc["lifecycle_age_days"]; found && v != nil {
+			lifecycleAgeDays, _ = strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		}
+
+		if v, found := gc["delete_expired"]; found && v != nil {
+			deleteExpired, _ = strconv.ParseBool(fmt.Sprint(v))
+		}
+	}
+
+	opts := []option.ClientOption{}
+
+	switch {
+	case credentialsJSON != "":
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentialsJSON)))
+	case credentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	case endpoint != "":
+		// The emulators don't authenticate the requests.
+		opts = append(opts, option.WithoutAuthentication())
+	}
+
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(endpoint, "/")+"/storage/v1/"))
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		logger.Error("Impossible to instantiate the GCS client.", err)
+
+		return nil, err
+	}
+
+	handle := client.Bucket(bucket)
+
+	_, err = handle.Attrs(context.Background())
+	if errors.Is(err, storage.ErrBucketNotExist) {
+		attrs := &storage.BucketAttrs{}
+		if lifecycleAgeDays > 0 {
+			attrs.Lifecycle = storage.Lifecycle{Rules: []storage.LifecycleRule{{
+				Action:    storage.LifecycleAction{Type: storage.DeleteAction},
+				Condition: storage.LifecycleCondition{AgeInDays: lifecycleAgeDays},
+			}}}
+		}
+
+		err = handle.Create(context.Background(), projectID, attrs)
+	}
+
+	if err != nil {
+		_ = client.Close()
+
+		logger.Errorf("Impossible to access the GCS bucket %s, %v", bucket, err)
+
+		return nil, err
+	}
+
+	return &GCS{
+		Client:        client,
+		stale:         stale,
+		logger:        logger,
+		mapper:        core.ConfiguredMapper(gcsConfiguration),
+		bucket:        handle,
+		bucketName:    bucket,
+		compression:   core.ConfiguredCompression(gcsConfiguration, logger),
+		clock:         core.ClockOrDefault(gcsConfiguration.Clock),
+		deleteExpired: deleteExpired,
+	}, nil
+}
+
+// expiresAt returns the expiry stored in the object metadata, the zero time means no expiration.
+func expiresAt(attrs *storage.ObjectAttrs) time.Time {
+	value, err := strconv.ParseInt(attrs.Metadata[expiresMetadata], 10, 64)
+	if err != nil || value == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, value)
+}
+
+func (provider *GCS) expired(attrs *storage.ObjectAttrs) bool {
+	expiry := expiresAt(attrs)
+
+	return !expiry.IsZero() && !provider.clock.Now().Before(expiry)
+}
+
+// expiryMetadata returns the metadata storing the expiry, a non-positive duration means no expiration.
+func (provider *GCS) expiryMetadata(duration time.Duration) map[string]string {
+	expiry := "0"
+	if duration > 0 {
+		expiry = strconv.FormatInt(provider.clock.Now().Add(duration).UnixNano(), 10)
+	}
+
+	return map[string]string{expiresMetadata: expiry}
+}
+
+func isPreconditionFailed(err error) bool {
+	var apiErr *googleapi.Error
+
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed
+}
+
+// expire treats the object as a miss and removes it if enabled, only if it hasn't been rewritten in between.
+func (provider *GCS) expire(ctx context.Context, attrs *storage.ObjectAttrs) {
+	if !provider.deleteExpired {
+		return
+	}
+
+	err := provider.bucket.Object(attrs.Name).If(storage.Conditions{GenerationMatch: attrs.Generation}).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) && !isPreconditionFailed(err) {
+		provider.logger.Errorf("Impossible to delete the expired key %s in GCS, %v", attrs.Name, err)
+	}
+}
+
+// attrs returns the object attributes if it exists and isn't expired.
+func (provider *GCS) attrs(ctx context.Context, key string) (*storage.ObjectAttrs, error) {
+	attrs, err := provider.bucket.Object(key).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.expired(attrs) {
+		provider.expire(ctx, attrs)
+
+		return nil, core.ErrKeyNotFound
+	}
+
+	return attrs, nil
+}
+
+// Name returns the storer name.
+func (provider *GCS) Name() string {
+	return "GCS"
+}
+
+// Uuid returns an unique identifier.
+func (provider *GCS) Uuid() string {
+	return fmt.Sprintf("%s-%s", provider.bucketName, provider.stale)
+}
+
+// MapKeys method returns the map of existing keys, each value is read with its own request.
+func (provider *GCS) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+	objects := provider.bucket.Objects(context.Background(), &storage.Query{Prefix: prefix})
+
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to list the keys in GCS, %v", err)
+
+			break
+		}
+
+		if !core.ListedKey(prefix, attrs.Name) {
+			continue
+		}
+
+		if value, err := provider.GetContext(context.Background(), attrs.Name); err == nil {
+			k, _ := strings.CutPrefix(attrs.Name, prefix)
+			keys[k] = string(value)
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *GCS) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// The expired objects are listed until they are read or removed by a lifecycle rule.
+func (provider *GCS) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	// StartOffset is inclusive so the cursor key itself is listed.
+	query := &storage.Query{Prefix: prefix, StartOffset: cursor}
+	if err := query.SetAttrSelection([]string{"Name"}); err != nil {
+		provider.logger.Errorf("Impossible to scan the keys in GCS, %v", err)
+
+		return keys, next
+	}
+
+	objects := provider.bucket.Objects(context.Background(), query)
+
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to scan the keys in GCS, %v", err)
+
+			break
+		}
+
+		if !core.ListedKey(prefix, attrs.Name) {
+			continue
+		}
+
+		if limit > 0 && len(keys) == limit {
+			next = attrs.Name
+
+			break
+		}
+
+		keys = append(keys, attrs.Name)
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *GCS) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *GCS) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in GCS: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *GCS) GetContext(ctx context.Context, key string) ([]byte, error) {
+	reader, err := provider.open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	return io.ReadAll(reader)
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *GCS) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// open returns the object reader if it exists and isn't expired, the read generation is the checked one.
+func (provider *GCS) open(ctx context.Context, key string) (*storage.Reader, error) {
+	attrs, err := provider.attrs(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := provider.bucket.Object(key).Generation(attrs.Generation).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return reader, err
+}
+
+// GetMany method returns the values of the existing keys, each key is read with its own request.
+func (provider *GCS) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value, err := provider.GetContext(context.Background(), key); err == nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *GCS) GetTTL(key string) (time.Duration, bool) {
+	attrs, err := provider.bucket.Object(key).Attrs(context.Background())
+	if err != nil || provider.expired(attrs) {
+		return 0, false
+	}
+
+	if expiry := expiresAt(attrs); !expiry.IsZero() {
+		return expiry.Sub(provider.clock.Now()), true
+	}
+
+	return core.NoExpiration, true
+}
+
+// Exists method will check the key in GCS provider using the object metadata.
+func (provider *GCS) Exists(key string) bool {
+	attrs, err := provider.bucket.Object(key).Attrs(context.Background())
+
+	return err == nil && !provider.expired(attrs)
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *GCS) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *GCS) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in GCS", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *GCS) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into GCS, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in GCS, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in GCS: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in GCS", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// write uploads the value with the expiry metadata using the object handle conditions.
+func (provider *GCS) write(ctx context.Context, object *storage.ObjectHandle, reader io.Reader, duration time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := object.NewWriter(ctx)
+	writer.ContentType = "application/octet-stream"
+	writer.Metadata = provider.expiryMetadata(duration)
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		// Cancelling the context aborts the upload instead of committing a partial object.
+		cancel()
+		_ = writer.Close()
+
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Set method will store the response in GCS provider.
+func (provider *GCS) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response unless the context is done before the upload completes.
+func (provider *GCS) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	err := provider.write(ctx, provider.bucket.Object(key), bytes.NewReader(value), duration)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into GCS, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in GCS provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *GCS) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in GCS provider only if the key doesn't exist yet.
+// The upload is conditioned on the object absence, or on the expired object generation to replace it atomically.
+func (provider *GCS) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	object := provider.bucket.Object(key)
+
+	attrs, err := object.Attrs(context.Background())
+
+	switch {
+	case err == nil && !provider.expired(attrs):
+		return false, nil
+	case err == nil:
+		object = object.If(storage.Conditions{GenerationMatch: attrs.Generation})
+	case errors.Is(err, storage.ErrObjectNotExist):
+		object = object.If(storage.Conditions{DoesNotExist: true})
+	default:
+		provider.logger.Errorf("Impossible to set the key %s if not exists into GCS, %v", key, err)
+
+		return false, err
+	}
+
+	err = provider.write(context.Background(), object, bytes.NewReader(value), duration)
+	if isPreconditionFailed(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into GCS, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in GCS provider only if the stored bytes equal old.
+// The upload is conditioned on the read object generation and retried when a concurrent writer updated the key in between.
+func (provider *GCS) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	for {
+		swapped, conflict, err := provider.compareAndSwap(key, old, value, duration)
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into GCS, %v", key, err)
+
+			return false, err
+		}
+
+		if !conflict {
+			return swapped, nil
+		}
+	}
+}
+
+func (provider *GCS) compareAndSwap(key string, old, value []byte, duration time.Duration) (bool, bool, error) {
+	object := provider.bucket.Object(key)
+
+	attrs, err := object.Attrs(context.Background())
+
+	switch {
+	case errors.Is(err, storage.ErrObjectNotExist):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	case provider.expired(attrs):
+		return false, false, nil
+	}
+
+	reader, err := object.Generation(attrs.Generation).NewReader(context.Background())
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, true, nil
+	}
+
+	if err != nil {
+		return false, false, err
+	}
+
+	current, err := io.ReadAll(reader)
+	_ = reader.Close()
+
+	if err != nil {
+		return false, false, err
+	}
+
+	if !core.SwapMatches(current, old) {
+		return false, false, nil
+	}
+
+	err = provider.write(context.Background(), object.If(storage.Conditions{GenerationMatch: attrs.Generation}), bytes.NewReader(value), duration)
+	if isPreconditionFailed(err) {
+		return false, true, nil
+	}
+
+	return err == nil, false, err
+}
+
+// Increment method will add delta to the counter stored in GCS provider.
+// The upload is conditioned on the read object generation and retried when a concurrent writer updated the counter in between.
+func (provider *GCS) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		value, updated, err := provider.increment(key, delta, duration)
+		if err != nil {
+			if !errors.Is(err, core.ErrNotCounter) {
+				provider.logger.Errorf("Impossible to increment the key %s into GCS, %v", key, err)
+			}
+
+			return 0, err
+		}
+
+		if updated {
+			return value, nil
+		}
+	}
+}
+
+func (provider *GCS) increment(key string, delta int64, duration time.Duration) (int64, bool, error) {
+	object := provider.bucket.Object(key)
+
+	var current []byte
+
+	attrs, err := object.Attrs(context.Background())
+
+	switch {
+	case errors.Is(err, storage.ErrObjectNotExist):
+		object = object.If(storage.Conditions{DoesNotExist: true})
+	case err != nil:
+		return 0, false, err
+	default:
+		object = object.If(storage.Conditions{GenerationMatch: attrs.Generation})
+
+		if !provider.expired(attrs) {
+			reader, err := provider.bucket.Object(key).Generation(attrs.Generation).NewReader(context.Background())
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				return 0, false, nil
+			}
+
+			if err != nil {
+				return 0, false, err
+			}
+
+			current, err = io.ReadAll(reader)
+			_ = reader.Close()
+
+			if err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, false, err
+	}
+
+	err = provider.write(context.Background(), object, bytes.NewReader(encoded), duration)
+	if isPreconditionFailed(err) {
+		return 0, false, nil
+	}
+
+	return value, err == nil, err
+}
+
+// Decrement method will subtract delta from the counter stored in GCS provider.
+func (provider *GCS) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in GCS provider.
+func (provider *GCS) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into GCS, %v", key, err)
+	}
+
+	return err
+}
+
+type objectReader struct {
+	io.ReadCloser
+	object *storage.Reader
+}
+
+func (r *objectReader) Close() error {
+	_ = r.ReadCloser.Close()
+
+	return r.object.Close()
+}
+
+// GetStream method returns a reader decompressing lazily the object stored by SetStream while it's downloaded.
+func (provider *GCS) GetStream(key string) (io.ReadCloser, error) {
+	object, err := provider.open(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := core.DecompressStream(object)
+	if err != nil {
+		_ = object.Close()
+
+		return nil, err
+	}
+
+	return &objectReader{ReadCloser: reader, object: object}, nil
+}
+
+// Touch method will update the time to live of the key by updating the object metadata.
+// The update is conditioned on the checked metageneration to never revive a concurrently expired object.
+func (provider *GCS) Touch(key string, duration time.Duration) error {
+	attrs, err := provider.bucket.Object(key).Attrs(context.Background())
+	if (err == nil && provider.expired(attrs)) || errors.Is(err, storage.ErrObjectNotExist) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.bucket.Object(key).
+		If(storage.Conditions{GenerationMatch: attrs.Generation, MetagenerationMatch: attrs.Metageneration}).
+		Update(context.Background(), storage.ObjectAttrsToUpdate{Metadata: provider.expiryMetadata(duration)})
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into GCS, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in GCS provider if exists corresponding to key param.
+func (provider *GCS) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	err := provider.bucket.Object(key).Delete(context.Background())
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		provider.logger.Errorf("Impossible to delete the key %s in GCS, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in GCS provider if exists corresponding to the prefix pattern param.
+// GCS has no batch deletion in its client, the listed objects are deleted one by one.
+func (provider *GCS) DeleteMany(pattern string) {
+	query := &storage.Query{Prefix: core.KeyPrefix(pattern)}
+	_ = query.SetAttrSelection([]string{"Name"})
+	objects := provider.bucket.Objects(context.Background(), query)
+
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			return
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to list the keys in GCS, %v", err)
+
+			return
+		}
+
+		provider.Delete(attrs.Name)
+	}
+}
+
+// DeleteKeys method will delete the keys in GCS provider one by one, the missing keys are ignored.
+func (provider *GCS) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in GCS provider.
+func (provider *GCS) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in GCS provider.
+func (provider *GCS) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in GCS provider.
+func (provider *GCS) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in GCS provider without altering it.
+func (provider *GCS) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in GCS provider and its metadata under a parallel key.
+func (provider *GCS) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in GCS provider, core.ErrKeyNotFound if none.
+func (provider *GCS) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number and the size of the objects, GCS doesn't expose them so the bucket is listed.
+// The expired objects are counted until they are read or removed by a lifecycle rule.
+func (provider *GCS) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+	now := provider.clock.Now()
+	objects := provider.bucket.Objects(context.Background(), nil)
+
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			return stats, nil
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to list the keys in GCS, %v", err)
+
+			return stats, err
+		}
+
+		stats.KeyCount++
+		stats.ApproxSizeBytes += attrs.Size
+		stats.OldestEntryAge = max(stats.OldestEntryAge, now.Sub(attrs.Updated))
+	}
+}
+
+// Ping method will check the GCS bucket exists.
+func (provider *GCS) Ping(ctx context.Context) error {
+	_, err := provider.bucket.Attrs(ctx)
+
+	return err
+}
+
+// Export method will stream every key of the GCS provider paginating the scanned keys.
+func (provider *GCS) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in GCS provider by batches.
+func (provider *GCS) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *GCS) Init() error {
+	return nil
+}
+
+// Reset method will delete every object of the bucket.
+func (provider *GCS) Reset() error {
+	provider.DeleteMany("*")
+
+	return nil
+}
+
+// Close method will close the GCS client.
+func (provider *GCS) Close() error {
+	return provider.Client.Close()
+}
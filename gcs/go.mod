@@ -0,0 +1,12 @@
+module github.com/darkweak/storages/gcs
+
+go 1.23.0
+
+replace github.com/darkweak/storages/core => ../core
+
+require (
+	cloud.google.com/go/storage v1.56.0
+	github.com/darkweak/storages/core v0.0.18
+	go.uber.org/zap v1.27.0
+	google.golang.org/api v0.243.0
+)
@@ -7,22 +7,127 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/darkweak/storages/core"
-	"github.com/pierrec/lz4/v4"
 	"github.com/redis/go-redis/v9"
 )
 
+// incrementScript adds ARGV[1] to the 8 bytes big-endian counter and sets its ARGV[2] milliseconds expiry.
+// It returns nil when the value isn't a counter, the Lua numbers being doubles the counters are exact up to 2^53.
+const incrementScript = `local current = redis.call('GET', KEYS[1])
+local value = 0
+if current then
+  if #current ~= 8 then
+    return false
+  end
+  value = struct.unpack('>i8', current)
+end
+value = value + tonumber(ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+  redis.call('SET', KEYS[1], struct.pack('>i8', value), 'PX', ARGV[2])
+else
+  redis.call('SET', KEYS[1], struct.pack('>i8', value))
+end
+return value`
+
+var increment = redis.NewScript(incrementScript)
+
+// compareAndSwapScript sets ARGV[2] with its ARGV[3] milliseconds expiry when the value is ARGV[1].
+// It returns 1 when the value has been swapped and 0 otherwise.
+const compareAndSwapScript = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+  return 0
+end
+if tonumber(ARGV[3]) > 0 then
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+  redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1`
+
+var compareAndSwap = redis.NewScript(compareAndSwapScript)
+
+// keyspaceEvents are the notify-keyspace-events flags required by Watch, the keyspace channel (K) with the
+// generic (g), string ($), expired (x) and evicted (e) events. The A flag stands for every event class.
+const keyspaceEvents = "Kg$xe"
+
+// defaultPort completes the configured addresses without port.
+const defaultPort = "6379"
+
+// keyspaceEventTypes maps the keyspace notifications changing the values to the Watch events, the other ones
+// as the expiry updates are ignored.
+var keyspaceEventTypes = map[string]core.EventType{
+	"set":         core.EventSet,
+	"setrange":    core.EventSet,
+	"append":      core.EventSet,
+	"incrby":      core.EventSet,
+	"incrbyfloat": core.EventSet,
+	"rename_to":   core.EventSet,
+	"copy_to":     core.EventSet,
+	"restore":     core.EventSet,
+	"del":         core.EventDelete,
+	"rename_from": core.EventDelete,
+	"expired":     core.EventDelete,
+	"evicted":     core.EventDelete,
+}
+
+// missingKeyspaceEvents returns the configured notify-keyspace-events flags completed with the ones required
+// by Watch, empty when none is missing.
+func missingKeyspaceEvents(configured string) string {
+	flags := configured
+
+	for _, flag := range keyspaceEvents {
+		if !strings.ContainsRune(flags, flag) && (flag == 'K' || !strings.ContainsRune(flags, 'A')) {
+			flags += string(flag)
+		}
+	}
+
+	if flags == configured {
+		return ""
+	}
+
+	return flags
+}
+
+// keyspacePattern returns the PSUBSCRIBE pattern of the keys starting with the prefix in the database, the
+// glob characters of the prefix are escaped.
+func keyspacePattern(database int, prefix string) string {
+	var pattern strings.Builder
+
+	pattern.WriteString(keyspaceChannel(database))
+
+	for _, r := range prefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			pattern.WriteByte('\\')
+		}
+
+		pattern.WriteRune(r)
+	}
+
+	pattern.WriteByte('*')
+
+	return pattern.String()
+}
+
+// keyspaceChannel returns the prefix of the keyspace notifications channels of the database.
+func keyspaceChannel(database int) string {
+	return fmt.Sprintf("__keyspace@%d__:", database)
+}
+
 // Redis provider type.
 type Redis struct {
 	inClient      redis.UniversalClient
 	stale         time.Duration
 	ctx           context.Context
 	logger        core.Logger
+	mapper        core.Mapper
+	compression   string
+	clock         core.Clock
 	configuration redis.UniversalOptions
 	close         func() error
 	reconnecting  bool
@@ -31,6 +136,23 @@ type Redis struct {
 
 // Factory function create new Redis instance.
 func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if redisConfiguration.Validate {
+		if err := core.ValidateConfig(redisConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Redis configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(redisConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, redisConfiguration, logger)
+}
+
+func factory(redisConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	var options redis.UniversalOptions
 
 	var hashtags string
@@ -67,10 +189,25 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 			}
 		}
 	} else {
+		addresses, network, err := core.ParseAddresses(redisConfiguration.URL, defaultPort)
+		if err != nil {
+			logger.Errorf("Impossible to parse the redis addresses %s, %v", redisConfiguration.URL, err)
+
+			return nil, err
+		}
+
 		options = redis.UniversalOptions{
-			Addrs:    strings.Split(redisConfiguration.URL, ","),
+			Addrs:    addresses,
 			PoolSize: 1000,
 		}
+
+		if network == "unix" {
+			options.Dialer = func(ctx context.Context, _, address string) (net.Conn, error) {
+				var dialer net.Dialer
+
+				return dialer.DialContext(ctx, network, address)
+			}
+		}
 	}
 
 	if len(options.Addrs) == 0 {
@@ -89,6 +226,9 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 		stale:         stale,
 		configuration: options,
 		logger:        logger,
+		mapper:        core.ConfiguredMapper(redisConfiguration),
+		compression:   core.ConfiguredCompression(redisConfiguration, logger),
+		clock:         core.ClockOrDefault(redisConfiguration.Clock),
 		close:         cli.Close,
 		hashtags:      hashtags,
 	}, nil
@@ -125,13 +265,13 @@ func (provider *Redis) ListKeys() []string {
 	for iter.Next(provider.ctx) {
 		value := provider.Get(iter.Val())
 
-		mapping, err := core.DecodeMapping(value)
+		mapping, err := provider.mapper.Decode(value)
 		if err != nil {
 			continue
 		}
 
 		for _, v := range mapping.GetMapping() {
-			if v.GetFreshTime().AsTime().Before(time.Now()) && v.GetStaleTime().AsTime().Before(time.Now()) {
+			if v.GetFreshTime().AsTime().Before(provider.clock.Now()) && v.GetStaleTime().AsTime().Before(provider.clock.Now()) {
 				continue
 			}
 
@@ -159,7 +299,9 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 
 	iter := provider.inClient.Scan(provider.ctx, 0, prefix+"*", 0).Iterator()
 	for iter.Next(provider.ctx) {
-		keys = append(keys, iter.Val())
+		if provider.listed(prefix, iter.Val()) {
+			keys = append(keys, iter.Val())
+		}
 	}
 
 	if err := iter.Err(); err != nil {
@@ -184,39 +326,49 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Redis) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Redis) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	b, e := provider.inClient.Get(provider.ctx, provider.hashtags+core.MappingKeyPrefix+key).Bytes()
 	if e != nil {
-		return fresh, stale
+		return fresh, stale, match
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, b, req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, b, req, validator, provider.logger)
 
-	return fresh, stale
+	return fresh, stale, match
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
 func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(provider.hashtags + variedKey)
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+		return nil
+	}
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	now := provider.clock.Now()
 
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Redis, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Redis, %v", variedKey, err)
+	if err := provider.Set(provider.hashtags+variedKey, compressed, duration); err != nil {
+		provider.logger.Errorf("Impossible to set value into Redis, %v", err)
 
 		return err
 	}
 
-	if err := provider.Set(provider.hashtags+variedKey, compressed.Bytes(), duration); err != nil {
-		provider.logger.Errorf("Impossible to set value into Redis, %v", err)
+	if err = core.IndexSurrogateKeys(provider, provider.hashtags+variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Redis, %v", variedKey, err)
 
 		return err
 	}
@@ -228,7 +380,7 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	val, err := core.MappingUpdater(provider.hashtags+variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err := core.MappingUpdaterWith(provider.mapper, provider.hashtags+variedKey, result, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if err != nil {
 		return err
 	}
@@ -240,6 +392,34 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 	return err
 }
 
+// listed returns true when MapKeys and ScanKeys list the key, the internal keys are prefixed by the hashtags.
+func (provider *Redis) listed(prefix, key string) bool {
+	return core.ListedKey(strings.TrimPrefix(prefix, provider.hashtags), strings.TrimPrefix(key, provider.hashtags))
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Redis) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	iter := provider.inClient.Scan(provider.ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(provider.ctx) {
+		if provider.listed(prefix, iter.Val()) {
+			keys = append(keys, iter.Val())
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return []string{}, ""
+	}
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Redis) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Redis) Get(key string) (item []byte) {
 	if provider.reconnecting {
@@ -262,6 +442,86 @@ func (provider *Redis) Get(key string) (item []byte) {
 	return
 }
 
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Redis) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Redis) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Redis) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Redis) GetTTL(key string) (time.Duration, bool) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the redis key ttl while reconnecting.")
+
+		return 0, false
+	}
+
+	ttl, err := provider.inClient.PTTL(provider.ctx, key).Result()
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return 0, false
+	}
+
+	// go-redis maps the -2 (missing) and -1 (no expiry) replies to those durations.
+	switch ttl {
+	case -2:
+		return 0, false
+	case -1:
+		return core.NoExpiration, true
+	}
+
+	return ttl, true
+}
+
+// Exists method will check the key in Redis provider using the EXISTS command.
+func (provider *Redis) Exists(key string) bool {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to check the redis key while reconnecting.")
+
+		return false
+	}
+
+	count, err := provider.inClient.Exists(provider.ctx, key).Result()
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return false
+	}
+
+	return count > 0
+}
+
 // Prefix method returns the keys that match the prefix key.
 func (provider *Redis) Prefix(key string) []string {
 	// keys, _ := provider.inClient.Do(provider.ctx, provider.inClient.B().Keys().Pattern(key+"*").Build()).AsStrSlice()
@@ -294,6 +554,150 @@ func (provider *Redis) Set(key string, value []byte, duration time.Duration) err
 	return err
 }
 
+// SetContext method will store the response unless the context is done.
+func (provider *Redis) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Redis provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Redis) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Redis provider only if the key doesn't exist yet.
+func (provider *Redis) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the redis value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	if duration == -1 {
+		duration = 0
+	} else {
+		duration += provider.stale
+	}
+
+	created, err := provider.inClient.SetNX(provider.ctx, key, value, duration).Result()
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Redis, %v", key, err)
+	}
+
+	return created, err
+}
+
+// CompareAndSwap method will store the response in Redis provider only if the stored bytes equal old,
+// using a Lua script to run atomically. A nil old value creates the key like SetNX.
+func (provider *Redis) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the redis value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	var expiry int64
+	if duration != -1 {
+		expiry = (duration + provider.stale).Milliseconds()
+	}
+
+	swapped, err := compareAndSwap.Run(provider.ctx, provider.inClient, []string{key}, old, value, expiry).Int64()
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Redis, %v", key, err)
+
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
+// Increment method will add delta to the counter stored in Redis provider using a Lua script to run atomically.
+func (provider *Redis) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the redis value while reconnecting.")
+
+		return 0, errors.New("reconnecting error")
+	}
+
+	value, err := increment.Run(provider.ctx, provider.inClient, []string{key}, delta, duration.Milliseconds()).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, core.ErrNotCounter
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into Redis, %v", key, err)
+	}
+
+	return value, err
+}
+
+// Decrement method will subtract delta from the counter stored in Redis provider.
+func (provider *Redis) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Redis provider.
+func (provider *Redis) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Redis, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Redis) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Redis) Touch(key string, duration time.Duration) error {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to touch the redis key while reconnecting.")
+
+		return errors.New("reconnecting error")
+	}
+
+	updated, err := provider.inClient.PExpire(provider.ctx, key, duration+provider.stale).Result()
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		provider.logger.Errorf("Impossible to touch the key %s into Redis, %v", key, err)
+
+		return err
+	}
+
+	if !updated {
+		return core.ErrKeyNotFound
+	}
+
+	return nil
+}
+
 // Delete method will delete the response in Etcd provider if exists corresponding to key param.
 func (provider *Redis) Delete(key string) {
 	if provider.reconnecting {
@@ -302,29 +706,25 @@ func (provider *Redis) Delete(key string) {
 		return
 	}
 
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	_ = provider.inClient.Del(provider.ctx, key)
 }
 
-// DeleteMany method will delete the responses in Redis provider if exists corresponding to the regex key param.
-func (provider *Redis) DeleteMany(key string) {
+// DeleteMany method will delete the responses in Redis provider if exists corresponding to the prefix pattern param.
+func (provider *Redis) DeleteMany(pattern string) {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to delete the redis keys while reconnecting.")
 
 		return
 	}
 
-	rgKey, err := regexp.Compile(key)
-	if err != nil {
-		return
-	}
-
 	keys := []string{}
-	iter := provider.inClient.Scan(provider.ctx, 0, "*", 0).Iterator()
+	iter := provider.inClient.Scan(provider.ctx, 0, core.KeyPrefix(pattern)+"*", 0).Iterator()
 
 	for iter.Next(provider.ctx) {
-		if rgKey.MatchString(iter.Val()) {
-			keys = append(keys, iter.Val())
-		}
+		keys = append(keys, iter.Val())
 	}
 
 	if iter.Err() != nil && !provider.reconnecting {
@@ -333,7 +733,177 @@ func (provider *Redis) DeleteMany(key string) {
 		return
 	}
 
-	provider.inClient.Del(provider.ctx, keys...)
+	if len(keys) > 0 {
+		provider.inClient.Del(provider.ctx, keys...)
+	}
+}
+
+// DeleteKeys method will delete the keys in Redis provider one by one, the missing keys are ignored.
+func (provider *Redis) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Redis provider.
+func (provider *Redis) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Redis provider.
+func (provider *Redis) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Redis provider.
+func (provider *Redis) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Redis provider without altering it.
+func (provider *Redis) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Redis provider and its metadata under a parallel key.
+func (provider *Redis) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Redis provider, core.ErrKeyNotFound if none.
+func (provider *Redis) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the DBSIZE key count and the used_memory reported by INFO.
+// The count includes the keys written by other clients of the same database.
+func (provider *Redis) Stats() (core.StorageStats, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the redis stats while reconnecting.")
+
+		return core.StorageStats{}, errors.New("reconnecting error")
+	}
+
+	count, err := provider.inClient.DBSize(provider.ctx).Result()
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Redis database size, %v", err)
+
+		return core.StorageStats{}, err
+	}
+
+	info, err := provider.inClient.Info(provider.ctx, "memory").Result()
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Redis memory info, %v", err)
+
+		return core.StorageStats{KeyCount: count}, err
+	}
+
+	return core.StorageStats{KeyCount: count, ApproxSizeBytes: usedMemory(info)}, nil
+}
+
+func usedMemory(info string) int64 {
+	for _, line := range strings.Split(info, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "used_memory:"); found {
+			size, _ := strconv.ParseInt(value, 10, 64)
+
+			return size
+		}
+	}
+
+	return 0
+}
+
+// Ping method will send a PING command to the Redis server.
+func (provider *Redis) Ping(ctx context.Context) error {
+	return provider.inClient.Ping(ctx).Err()
+}
+
+// enableKeyspaceEvents adds the notify-keyspace-events flags required by Watch to the server configuration,
+// a warning is logged when the configuration can't be read or written, on the managed services for example.
+func (provider *Redis) enableKeyspaceEvents(ctx context.Context) {
+	configured, err := provider.inClient.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err == nil {
+		flags := missingKeyspaceEvents(configured["notify-keyspace-events"])
+		if flags == "" {
+			return
+		}
+
+		err = provider.inClient.ConfigSet(ctx, "notify-keyspace-events", flags).Err()
+	}
+
+	if err != nil {
+		provider.logger.Warnf("Impossible to enable the %s keyspace events, they must be enabled on the server for Watch, %v", keyspaceEvents, err)
+	}
+}
+
+// Watch method will stream the changes of the keys starting with the prefix using the keyspace notifications,
+// see core.Watcher. The required notify-keyspace-events flags are enabled when missing and the expired or
+// evicted keys are reported as deleted. On a cluster only the keys of the node serving the subscription are
+// reported.
+func (provider *Redis) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to watch the redis keys while reconnecting.")
+
+		return nil, errors.New("reconnecting error")
+	}
+
+	provider.enableKeyspaceEvents(ctx)
+
+	channel := keyspaceChannel(provider.configuration.DB)
+	pubsub := provider.inClient.PSubscribe(ctx, keyspacePattern(provider.configuration.DB, prefix))
+
+	// The subscription is confirmed before returning so the next changes are streamed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		provider.logger.Errorf("Impossible to watch the prefix %s in Redis, %v", prefix, err)
+
+		return nil, err
+	}
+
+	events := make(chan core.Event)
+
+	go func() {
+		defer close(events)
+		defer func() { _ = pubsub.Close() }()
+
+		messages := pubsub.Channel()
+
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				eventType, ok := keyspaceEventTypes[message.Payload]
+				if !ok {
+					continue
+				}
+
+				select {
+				case events <- core.Event{Type: eventType, Key: strings.TrimPrefix(message.Channel, channel)}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Export method will stream every key of the Redis provider paginating the scanned keys.
+func (provider *Redis) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Redis provider by batches.
+func (provider *Redis) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
 }
 
 // Init method will.
@@ -341,7 +911,7 @@ func (provider *Redis) Init() error {
 	return nil
 }
 
-// Reset method will reset or close provider.
+// Reset method will delete every key.
 func (provider *Redis) Reset() error {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to reset the redis instance while reconnecting.")
@@ -349,7 +919,9 @@ func (provider *Redis) Reset() error {
 		return nil
 	}
 
-	return provider.inClient.Close()
+	provider.DeleteMany("*")
+
+	return nil
 }
 
 func (provider *Redis) Reconnect() {
@@ -362,3 +934,8 @@ func (provider *Redis) Reconnect() {
 		provider.Reconnect()
 	}
 }
+
+// Close method will close the Redis client.
+func (provider *Redis) Close() error {
+	return provider.close()
+}
@@ -1,6 +1,7 @@
 package redis_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -161,9 +162,71 @@ func TestRedis_DeleteMany(t *testing.T) {
 		t.Error("The map should contain 2 element")
 	}
 
-	client.DeleteMany(".*")
+	client.DeleteMany("*")
 
 	if len(client.MapKeys("")) != 0 {
 		t.Error("The map should be empty")
 	}
 }
+
+func TestRedis_Stats(t *testing.T) {
+	client, _ := getRedisInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestRedis_Watch(t *testing.T) {
+	client, err := getRedisInstance()
+	if err != nil {
+		t.Fatalf("Failed to create the instance: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
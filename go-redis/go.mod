@@ -6,7 +6,6 @@ replace github.com/darkweak/storages/core => ../core
 
 require (
 	github.com/darkweak/storages/core v0.0.18
-	github.com/pierrec/lz4/v4 v4.1.23
 	github.com/redis/go-redis/v9 v9.17.2
 	go.uber.org/zap v1.27.0
 )
@@ -14,6 +13,10 @@ require (
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.23 // indirect
+	go.opentelemetry.io/otel v1.28.0 // indirect
+	go.opentelemetry.io/otel/trace v1.28.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	google.golang.org/protobuf v1.36.5 // indirect
 )
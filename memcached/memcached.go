@@ -0,0 +1,741 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/darkweak/storages/core"
+)
+
+const (
+	// DefaultMaxItemSize is the default memcached item size limit.
+	DefaultMaxItemSize = 1024 * 1024
+	// defaultPort completes the configured servers without port.
+	defaultPort = "11211"
+	// The item header and key are stored alongside the value in the memcached slab.
+	itemOverhead = 512
+	// Memcached considers the relative expirations over 30 days as unix timestamps.
+	maxRelativeExpiration = 30 * 24 * time.Hour
+	maxKeyLength          = 250
+	hashedKeyPrefix       = "HASHED_"
+)
+
+// ErrValueTooLarge is returned when the value exceeds the memcached item size limit, it matches core.ErrValueTooLarge.
+var ErrValueTooLarge = core.ErrValueTooLarge
+
+// Memcached provider type.
+type Memcached struct {
+	*memcache.Client
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	servers     []string
+	maxItemSize int
+	compression string
+	clock       core.Clock
+}
+
+// Factory function create new Memcached instance.
+func Factory(memcachedConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if memcachedConfiguration.Validate {
+		if err := core.ValidateConfig(memcachedConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Memcached configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(memcachedConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, memcachedConfiguration, logger)
+}
+
+func factory(memcachedConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	servers := []string{}
+	if memcachedConfiguration.URL != "" {
+		servers = strings.Split(memcachedConfiguration.URL, ",")
+	}
+
+	maxItemSize := DefaultMaxItemSize
+	timeout := memcache.DefaultTimeout
+	maxIdleConns := memcache.DefaultMaxIdleConns
+
+	if mc, ok := memcachedConfiguration.Configuration.(map[string]interface{}); ok && mc != nil {
+		if v, found := mc["servers"]; found && v != nil {
+			switch val := v.(type) {
+			case string:
+				servers = strings.Split(val, ",")
+			case []interface{}:
+				servers = []string{}
+				for _, server := range val {
+					servers = append(servers, fmt.Sprint(server))
+				}
+			case []string:
+				servers = val
+			}
+		}
+
+		if v, found := mc["timeout"]; found && v != nil {
+			if d, err := time.ParseDuration(fmt.Sprint(v)); err == nil {
+				timeout = d
+			}
+		}
+
+		if v, found := mc["max_idle_conns"]; found && v != nil {
+			if val, err := strconv.Atoi(fmt.Sprint(v)); err == nil && val > 0 {
+				maxIdleConns = val
+			}
+		}
+
+		if v, found := mc["max_item_size"]; found && v != nil {
+			if val, err := strconv.Atoi(fmt.Sprint(v)); err == nil && val > 0 {
+				maxItemSize = val
+			}
+		}
+	}
+
+	addresses := make([]string, 0, len(servers))
+
+	for _, server := range servers {
+		network, address, err := core.ParseAddressWithDefaultPort(server, defaultPort)
+		if err != nil {
+			logger.Errorf("Impossible to parse the Memcached server %s, %v", server, err)
+
+			return nil, err
+		}
+
+		// The client dials the servers containing a slash as unix sockets, the relative socket paths are
+		// anchored to the working directory to keep them one.
+		if network == "unix" && !strings.Contains(address, "/") {
+			address = "./" + address
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	servers = addresses
+
+	if len(servers) == 0 {
+		err := errors.New("no memcached servers given")
+		logger.Error("Impossible to connect to the Memcached servers.", err)
+
+		return nil, err
+	}
+
+	client := memcache.New(servers...)
+	client.Timeout = timeout
+	client.MaxIdleConns = maxIdleConns
+
+	if err := client.Ping(); err != nil {
+		logger.Error("Impossible to connect to the Memcached servers.", err)
+
+		return nil, err
+	}
+
+	return &Memcached{
+		Client:      client,
+		stale:       stale,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(memcachedConfiguration),
+		servers:     servers,
+		maxItemSize: maxItemSize,
+		compression: core.ConfiguredCompression(memcachedConfiguration, logger),
+		clock:       core.ClockOrDefault(memcachedConfiguration.Clock),
+	}, nil
+}
+
+// storedKey returns the key as is if memcached accepts it, its hash otherwise.
+func storedKey(key string) string {
+	if len(key) <= maxKeyLength && !strings.ContainsFunc(key, func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hashedKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// expiration converts the duration to a memcached expiration, zero means no expiration.
+func (provider *Memcached) expiration(duration time.Duration) (int32, uint32) {
+	if duration <= 0 {
+		return 0, 0
+	}
+
+	expiresAt := provider.clock.Now().Add(duration)
+	//nolint:gosec
+	flags := uint32(expiresAt.Unix())
+
+	if duration > maxRelativeExpiration {
+		//nolint:gosec
+		return int32(expiresAt.Unix()), flags
+	}
+
+	//nolint:gosec
+	return int32((duration + time.Second - 1) / time.Second), flags
+}
+
+// Name returns the storer name.
+func (provider *Memcached) Name() string {
+	return "MEMCACHED"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Memcached) Uuid() string {
+	return fmt.Sprintf("%s-%s", strings.Join(provider.servers, ","), provider.stale)
+}
+
+// MapKeys method returns an empty map, memcached doesn't support the keys enumeration.
+func (provider *Memcached) MapKeys(_ string) map[string]string {
+	return map[string]string{}
+}
+
+// ListKeys method returns an empty list, memcached doesn't support the keys enumeration.
+func (provider *Memcached) ListKeys() []string {
+	return []string{}
+}
+
+// ScanKeys method returns an empty page, memcached doesn't support the keys enumeration.
+func (provider *Memcached) ScanKeys(_, _ string, _ int) (keys []string, next string) {
+	return []string{}, ""
+}
+
+// SortedKeys method returns no key as Memcached provider can't list its keys.
+func (provider *Memcached) SortedKeys(_ string) []string {
+	return []string{}
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Memcached) Get(key string) []byte {
+	item, err := provider.Client.Get(storedKey(key))
+	if err != nil {
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			provider.logger.Errorf("Impossible to get the key %s in Memcached: %v", key, err)
+		}
+
+		return nil
+	}
+
+	return item.Value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Memcached) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	item, err := provider.Client.Get(storedKey(key))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return item.Value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Memcached) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys using a single request per server.
+func (provider *Memcached) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+	stored := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		stored = append(stored, storedKey(key))
+	}
+
+	items, err := provider.GetMulti(stored)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys in Memcached: %v", err)
+
+		return result
+	}
+
+	for i, key := range keys {
+		if item, ok := items[stored[i]]; ok {
+			result[key] = item.Value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+// The expiry is stored in the item flags because memcached doesn't expose it.
+func (provider *Memcached) GetTTL(key string) (time.Duration, bool) {
+	item, err := provider.Client.Get(storedKey(key))
+	if err != nil {
+		return 0, false
+	}
+
+	if item.Flags == 0 {
+		return core.NoExpiration, true
+	}
+
+	return time.Unix(int64(item.Flags), 0).Sub(provider.clock.Now()), true
+}
+
+// Exists method will check the key in Memcached provider, the protocol always returns the value.
+func (provider *Memcached) Exists(key string) bool {
+	_, err := provider.Client.Get(storedKey(key))
+	if err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		provider.logger.Errorf("Impossible to check the key %s in Memcached: %v", key, err)
+	}
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Memcached) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Memcached) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in Memcached", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *Memcached) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Memcached, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Memcached, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in Memcached: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in Memcached", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in Memcached provider.
+// A value larger than the item size limit is rejected with ErrValueTooLarge.
+func (provider *Memcached) Set(key string, value []byte, duration time.Duration) error {
+	if len(value)+itemOverhead > provider.maxItemSize {
+		err := fmt.Errorf("%w: %d bytes for the key %s", ErrValueTooLarge, len(value), key)
+		provider.logger.Errorf("Impossible to set value into Memcached, %v", err)
+
+		return err
+	}
+
+	exp, flags := provider.expiration(duration)
+
+	err := provider.Client.Set(&memcache.Item{Key: storedKey(key), Value: value, Expiration: exp, Flags: flags})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Memcached, %v", err)
+	}
+
+	return err
+}
+
+// SetContext method will store the response unless the context is done.
+func (provider *Memcached) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Memcached provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Memcached) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Memcached provider only if the key doesn't exist yet.
+func (provider *Memcached) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if len(value)+itemOverhead > provider.maxItemSize {
+		err := fmt.Errorf("%w: %d bytes for the key %s", ErrValueTooLarge, len(value), key)
+		provider.logger.Errorf("Impossible to set value into Memcached, %v", err)
+
+		return false, err
+	}
+
+	exp, flags := provider.expiration(duration)
+
+	err := provider.Add(&memcache.Item{Key: storedKey(key), Value: value, Expiration: exp, Flags: flags})
+	if errors.Is(err, memcache.ErrNotStored) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Memcached, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Memcached provider only if the stored bytes equal old.
+// The native check-and-set is conditioned on the read item and retried when a concurrent writer updated it in between.
+func (provider *Memcached) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	if len(value)+itemOverhead > provider.maxItemSize {
+		err := fmt.Errorf("%w: %d bytes for the key %s", ErrValueTooLarge, len(value), key)
+		provider.logger.Errorf("Impossible to set value into Memcached, %v", err)
+
+		return false, err
+	}
+
+	exp, flags := provider.expiration(duration)
+
+	for {
+		item, err := provider.Client.Get(storedKey(key))
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return false, nil
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into Memcached, %v", key, err)
+
+			return false, err
+		}
+
+		if !core.SwapMatches(item.Value, old) {
+			return false, nil
+		}
+
+		item.Value, item.Expiration, item.Flags = value, exp, flags
+
+		err = provider.Client.CompareAndSwap(item)
+		if errors.Is(err, memcache.ErrNotStored) || errors.Is(err, memcache.ErrCASConflict) {
+			continue
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into Memcached, %v", key, err)
+
+			return false, err
+		}
+
+		return true, nil
+	}
+}
+
+// Increment method will add delta to the counter stored in Memcached provider.
+// The native incr command stores decimal values, the counter is updated with a check-and-set retried on conflict.
+func (provider *Memcached) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	exp, flags := provider.expiration(duration)
+
+	for {
+		var current []byte
+
+		item, err := provider.Client.Get(storedKey(key))
+		if err == nil {
+			current = item.Value
+		} else if !errors.Is(err, memcache.ErrCacheMiss) {
+			provider.logger.Errorf("Impossible to increment the key %s into Memcached, %v", key, err)
+
+			return 0, err
+		}
+
+		value, encoded, err := core.AddCounter(current, delta)
+		if err != nil {
+			return 0, err
+		}
+
+		if item == nil {
+			err = provider.Add(&memcache.Item{Key: storedKey(key), Value: encoded, Expiration: exp, Flags: flags})
+		} else {
+			item.Value, item.Expiration, item.Flags = encoded, exp, flags
+			err = provider.Client.CompareAndSwap(item)
+		}
+
+		if errors.Is(err, memcache.ErrNotStored) || errors.Is(err, memcache.ErrCASConflict) {
+			continue
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to increment the key %s into Memcached, %v", key, err)
+
+			return 0, err
+		}
+
+		return value, nil
+	}
+}
+
+// Decrement method will subtract delta from the counter stored in Memcached provider.
+func (provider *Memcached) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Memcached provider.
+func (provider *Memcached) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Memcached, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Memcached) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Memcached) Touch(key string, duration time.Duration) error {
+	item, err := provider.Client.Get(storedKey(key))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	item.Expiration, item.Flags = provider.expiration(duration)
+
+	err = provider.Client.CompareAndSwap(item)
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Memcached, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in Memcached provider if exists corresponding to key param.
+func (provider *Memcached) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if err := provider.Client.Delete(storedKey(key)); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		provider.logger.Errorf("Impossible to delete the key %s in Memcached, %v", key, err)
+	}
+}
+
+// DeleteMany method will flush Memcached provider when the prefix pattern matches every key.
+// Memcached doesn't support the keys enumeration so the other patterns are ignored.
+func (provider *Memcached) DeleteMany(pattern string) {
+	if core.KeyPrefix(pattern) != "" {
+		provider.logger.Warnf("Memcached can't delete the keys matching %s, only the wildcard is supported", pattern)
+
+		return
+	}
+
+	if err := provider.DeleteAll(); err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in Memcached, %v", err)
+	}
+}
+
+// DeleteKeys method will delete the keys in Memcached provider one by one, the missing keys are ignored.
+func (provider *Memcached) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Memcached provider.
+func (provider *Memcached) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns no surrogate key as Memcached provider can't list its keys.
+func (provider *Memcached) ListSurrogates() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Memcached provider.
+func (provider *Memcached) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Memcached provider without altering it.
+func (provider *Memcached) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Memcached provider and its metadata under a parallel key.
+func (provider *Memcached) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Memcached provider, core.ErrKeyNotFound if none.
+func (provider *Memcached) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the curr_items and bytes counters of the stats command summed over the servers.
+// The memcache client doesn't expose the stats command so each server is queried on a dedicated connection.
+func (provider *Memcached) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+
+	for _, server := range provider.servers {
+		items, size, err := serverStats(server, provider.Timeout)
+		if err != nil {
+			provider.logger.Errorf("Impossible to get the Memcached stats of the server %s, %v", server, err)
+
+			return stats, err
+		}
+
+		stats.KeyCount += items
+		stats.ApproxSizeBytes += size
+	}
+
+	return stats, nil
+}
+
+func serverStats(server string, timeout time.Duration) (items, size int64, err error) {
+	network := "tcp"
+	if strings.Contains(server, "/") {
+		network = "unix"
+	}
+
+	conn, err := net.DialTimeout(network, server, timeout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err = conn.Write([]byte("stats\r\n")); err != nil {
+		return 0, 0, err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 && fields[0] == "END" {
+			return items, size, nil
+		}
+
+		if len(fields) != 3 || fields[0] != "STAT" {
+			continue
+		}
+
+		switch fields[1] {
+		case "curr_items":
+			items, _ = strconv.ParseInt(fields[2], 10, 64)
+		case "bytes":
+			size, _ = strconv.ParseInt(fields[2], 10, 64)
+		}
+	}
+
+	if err = scanner.Err(); err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return items, size, err
+}
+
+// Ping method will check every Memcached server is reachable.
+// The client doesn't support contexts, the ping is abandoned when the context is done.
+func (provider *Memcached) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- provider.Client.Ping()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// Export method writes an empty export, memcached doesn't support the keys enumeration.
+func (provider *Memcached) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Memcached provider by batches.
+func (provider *Memcached) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Memcached) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *Memcached) Reset() error {
+	return provider.DeleteAll()
+}
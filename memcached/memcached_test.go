@@ -0,0 +1,258 @@
+//go:build memcached
+
+package memcached_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/memcached"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getMemcachedInstance() (core.Storer, error) {
+	url := os.Getenv("MEMCACHED_URL")
+	if url == "" {
+		url = "localhost:11211"
+	}
+
+	return memcached.Factory(core.CacheProvider{URL: url}, zap.NewNop().Sugar(), 0)
+}
+
+func TestMemcachedConnectionFactory(t *testing.T) {
+	instance, err := getMemcachedInstance()
+
+	if nil != err {
+		t.Errorf("Shouldn't have panic: %v", err)
+	}
+
+	if nil == instance {
+		t.Error("Memcached should be instanciated")
+	}
+}
+
+func TestMemcachedServerAddresses(t *testing.T) {
+	instance, err := memcached.Factory(core.CacheProvider{
+		Configuration: map[string]interface{}{"servers": []interface{}{" localhost "}},
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("The server without port should use the default one, %v provided", err)
+	}
+
+	if uuid := instance.Uuid(); uuid != "localhost:11211-0s" {
+		t.Errorf("The server should be completed by the default port, %s provided", uuid)
+	}
+
+	for _, invalid := range []string{"localhost:0", "[::1", "redis://localhost:11211"} {
+		if _, err = memcached.Factory(core.CacheProvider{URL: invalid}, zap.NewNop().Sugar(), 0); !errors.Is(err, core.ErrInvalidConfig) {
+			t.Errorf("The server %q should be invalid, %v provided", invalid, err)
+		}
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInMemcached(t *testing.T) {
+	client, _ := getMemcachedInstance()
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestMemcached_GetRequestInCache(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestMemcached_GetSetRequestInCache_OneByte(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
+
+	res := client.Get(byteKey)
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", byteKey)
+	}
+
+	if string(res) != "A" {
+		t.Errorf("%s not corresponding to %v", res, 65)
+	}
+}
+
+func TestMemcached_SetRequestInCache_TTL(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	_ = client.Set("TTLKey", []byte(baseValue), time.Second)
+	time.Sleep(2 * time.Second)
+
+	if res := client.Get("TTLKey"); res != nil {
+		t.Errorf("Key TTLKey should be expired, %s provided", res)
+	}
+}
+
+func TestMemcached_InvalidKey(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	key := "GET-http-example.com-/{-VARY-}Accept-Encoding:gzip, deflate" + string(bytes.Repeat([]byte("a"), 300))
+
+	if err := client.Set(key, []byte(baseValue), time.Minute); err != nil {
+		t.Errorf("The key containing spaces should be stored: %v", err)
+	}
+
+	if res := client.Get(key); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestMemcached_DeleteRequestInCache(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	client.Delete(byteKey)
+
+	if 0 < len(client.Get(byteKey)) {
+		t.Errorf("Key %s should not exist", byteKey)
+	}
+}
+
+func TestMemcached_Init(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	err := client.Init()
+
+	if nil != err {
+		t.Error("Impossible to init Memcached provider")
+	}
+}
+
+func TestMemcached_SetMultiLevel(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	key := "multi_level_test"
+
+	if err := client.SetMultiLevel(key, key, []byte(baseValue), http.Header{}, "", time.Minute, key); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	decompressed, err := core.Decompress(client.Get(key))
+	if err != nil || string(decompressed) != baseValue {
+		t.Errorf("%s not corresponding to %s, %v", string(decompressed), baseValue, err)
+	}
+
+	if client.Get(core.MappingKeyPrefix+key) == nil {
+		t.Error("The mapping key should be stored")
+	}
+}
+
+func TestMemcached_SetLargeValue(t *testing.T) {
+	client, _ := getMemcachedInstance()
+
+	err := client.Set("large_value_test", make([]byte, memcached.DefaultMaxItemSize), time.Minute)
+	if !errors.Is(err, memcached.ErrValueTooLarge) {
+		t.Errorf("Setting a large value should return ErrValueTooLarge, %v provided", err)
+	}
+}
+
+func TestMemcached_GetTTL(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	_ = client.Set("TTLKey", []byte(baseValue), 20*time.Second)
+
+	ttl, found := client.GetTTL("TTLKey")
+	if !found || ttl <= 0 || ttl > 20*time.Second {
+		t.Errorf("The TTL should be between 0 and 20s, %v provided", ttl)
+	}
+
+	if err := client.Touch("TTLKey", time.Minute); err != nil {
+		t.Errorf("Impossible to touch the key TTLKey: %v", err)
+	}
+
+	if ttl, _ = client.GetTTL("TTLKey"); ttl <= 20*time.Second {
+		t.Errorf("The TTL should have been extended, %v provided", ttl)
+	}
+
+	if err := client.Touch(nonExistentKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestMemcached_Increment(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 100 {
+		t.Errorf("The 100 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 5, time.Minute); value != 95 {
+		t.Errorf("The counter should be decremented to 95, %d provided", value)
+	}
+}
+
+func TestMemcached_Stats(t *testing.T) {
+	client, _ := getMemcachedInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestMemcached_Exists(t *testing.T) {
+	client, _ := getMemcachedInstance()
+	_ = client.Set("ExistsKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExistsExpiredKey", []byte(baseValue), time.Second)
+
+	if !client.Exists("ExistsKey") {
+		t.Error("The key ExistsKey should exist")
+	}
+
+	if client.Exists(nonExistentKey) {
+		t.Errorf("The key %s should not exist", nonExistentKey)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if client.Exists("ExistsExpiredKey") {
+		t.Error("The expired key ExistsExpiredKey should not exist")
+	}
+}
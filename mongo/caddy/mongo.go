@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/mongo"
+)
+
+const moduleName = "mongo"
+
+// MongoDB storage.
+type Mongo struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(Mongo{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (Mongo) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.mongo",
+		New: func() caddy.Module { return new(Mongo) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *Mongo) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := mongo.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *Mongo) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*Mongo)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Mongo)(nil)
+)
@@ -0,0 +1,746 @@
+// Package mongo stores the cache entries as documents of a MongoDB collection.
+//
+// Each document holds the key as its _id, the value as binary data and the expiry in the expiresAt
+// date field, indexed by a TTL index. MongoDB deletes the expired documents in the background about
+// every minute, so the documents past their expiresAt are treated as misses on read. A document
+// can't exceed 16MB.
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	keyField       = "_id"
+	valueField     = "value"
+	expiresAtField = "expiresAt"
+
+	defaultURL        = "mongodb://localhost:27017"
+	defaultDatabase   = "souin"
+	defaultCollection = "souin"
+)
+
+// document is the stored entry, a document without expiresAt never expires.
+type document struct {
+	Key       string     `bson:"_id"`
+	Value     []byte     `bson:"value"`
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty"`
+}
+
+// Mongo provider type.
+type Mongo struct {
+	*mongo.Collection
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	url         string
+	compression string
+	clock       core.Clock
+}
+
+// Factory function create new Mongo instance.
+func Factory(mongoConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if mongoConfiguration.Validate {
+		if err := core.ValidateConfig(mongoConfiguration, checkURL); err != nil {
+			logger.Errorf("Impossible to validate the MongoDB configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(mongoConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, mongoConfiguration, logger)
+}
+
+// configuredURL returns the url of the configuration map or of the cache provider, the local server when none is given.
+func configuredURL(mongoConfiguration core.CacheProvider) string {
+	url := mongoConfiguration.URL
+
+	if mc, ok := mongoConfiguration.Configuration.(map[string]interface{}); ok && mc != nil {
+		if v, found := mc["url"]; found && v != nil {
+			url = fmt.Sprint(v)
+		}
+	}
+
+	if url == "" {
+		return defaultURL
+	}
+
+	return url
+}
+
+// checkURL checks the connection string options, see core.ValidateConfig.
+func checkURL(mongoConfiguration core.CacheProvider) error {
+	if err := options.Client().ApplyURI(configuredURL(mongoConfiguration)).Validate(); err != nil {
+		return fmt.Errorf("%w: the MongoDB url is invalid, %w", core.ErrInvalidConfig, err)
+	}
+
+	return nil
+}
+
+func factory(mongoConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	url := configuredURL(mongoConfiguration)
+	database := defaultDatabase
+	collection := defaultCollection
+
+	if mc, ok := mongoConfiguration.Configuration.(map[string]interface{}); ok && mc != nil {
+		if v, found := mc["database"]; found && v != nil {
+			database = fmt.Sprint(v)
+		}
+
+		if v, found := mc["collection"]; found && v != nil {
+			collection = fmt.Sprint(v)
+		}
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(url))
+	if err != nil {
+		logger.Error("Impossible to connect to MongoDB.", err)
+
+		return nil, err
+	}
+
+	provider := &Mongo{
+		Collection:  client.Database(database).Collection(collection),
+		stale:       stale,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(mongoConfiguration),
+		url:         url,
+		compression: core.ConfiguredCompression(mongoConfiguration, logger),
+		clock:       core.ClockOrDefault(mongoConfiguration.Clock),
+	}
+
+	if err = provider.createIndex(context.Background()); err != nil {
+		logger.Errorf("Impossible to create the TTL index of the MongoDB collection %s, %v", collection, err)
+
+		_ = client.Disconnect(context.Background())
+
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// createIndex creates the TTL index deleting the documents once their expiresAt date is reached.
+func (provider *Mongo) createIndex(ctx context.Context) error {
+	_, err := provider.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: expiresAtField, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+// expiresAt returns the expiry of the duration, nil means no expiration.
+func (provider *Mongo) expiresAt(duration time.Duration) *time.Time {
+	if duration <= 0 {
+		return nil
+	}
+
+	expiry := provider.clock.Now().Add(duration)
+
+	return &expiry
+}
+
+// expired reports whether MongoDB didn't delete yet the document past its expiresAt.
+func (provider *Mongo) expired(doc *document) bool {
+	return doc.ExpiresAt != nil && !provider.clock.Now().Before(*doc.ExpiresAt)
+}
+
+// live returns the filter matching the documents not expired yet.
+func (provider *Mongo) live() bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: expiresAtField, Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: expiresAtField, Value: bson.D{{Key: "$gt", Value: provider.clock.Now()}}}},
+	}}
+}
+
+// prefixed returns the filter matching the live keys beginning with the prefix and the other key conditions.
+func (provider *Mongo) prefixed(prefix string, conditions ...bson.E) bson.D {
+	if prefix != "" {
+		conditions = append(conditions, bson.E{Key: "$regex", Value: "^" + regexp.QuoteMeta(prefix)})
+	}
+
+	filter := bson.D{provider.live()}
+	if len(conditions) > 0 {
+		filter = append(filter, bson.E{Key: keyField, Value: bson.D(conditions)})
+	}
+
+	return filter
+}
+
+func byKey(key string) bson.D {
+	return bson.D{{Key: keyField, Value: key}}
+}
+
+func (provider *Mongo) newDocument(key string, value []byte, duration time.Duration) document {
+	return document{Key: key, Value: value, ExpiresAt: provider.expiresAt(duration)}
+}
+
+// Name returns the storer name.
+func (provider *Mongo) Name() string {
+	return "MONGO"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Mongo) Uuid() string {
+	return fmt.Sprintf("%s-%s-%s-%s", provider.url, provider.Database().Name(), provider.Collection.Name(), provider.stale)
+}
+
+// find calls fn with the documents matching the filter, stopping on the first error.
+func (provider *Mongo) find(ctx context.Context, filter bson.D, opts *options.FindOptions, fn func(doc *document)) error {
+	cursor, err := provider.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		doc := &document{}
+		if err = cursor.Decode(doc); err != nil {
+			return err
+		}
+
+		fn(doc)
+	}
+
+	return cursor.Err()
+}
+
+// MapKeys method returns the map of existing keys using a prefix regex on their _id.
+func (provider *Mongo) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	err := provider.find(context.Background(), provider.prefixed(prefix), options.Find(), func(doc *document) {
+		if core.ListedKey(prefix, doc.Key) {
+			k, _ := strings.CutPrefix(doc.Key, prefix)
+			keys[k] = string(doc.Value)
+		}
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys in MongoDB, %v", err)
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Mongo) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// The _id index returns the keys sorted so only the page and the next key are read.
+func (provider *Mongo) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+	filter := provider.prefixed(prefix, bson.E{Key: "$gte", Value: max(prefix, cursor)})
+	opts := options.Find().SetSort(bson.D{{Key: keyField, Value: 1}}).SetProjection(bson.D{{Key: valueField, Value: 0}})
+
+	if limit > 0 {
+		opts.SetLimit(int64(limit) + 1)
+	}
+
+	read, last := 0, ""
+
+	err := provider.find(context.Background(), filter, opts, func(doc *document) {
+		read, last = read+1, doc.Key
+
+		if next != "" || !core.ListedKey(prefix, doc.Key) {
+			return
+		}
+
+		if limit > 0 && len(keys) == limit {
+			next = doc.Key
+
+			return
+		}
+
+		keys = append(keys, doc.Key)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to scan the keys in MongoDB, %v", err)
+	}
+
+	// The skipped internal keys shortened the page, the scan resumes after the last key read.
+	if next == "" && limit > 0 && read > limit {
+		next = core.NextKey(last)
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Mongo) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Mongo) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in MongoDB: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Mongo) GetContext(ctx context.Context, key string) ([]byte, error) {
+	doc, err := provider.getDocument(ctx, key, options.FindOne())
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Mongo) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// getDocument returns the document of the key if it exists and isn't expired.
+func (provider *Mongo) getDocument(ctx context.Context, key string, opts *options.FindOneOptions) (*document, error) {
+	doc := &document{}
+
+	err := provider.FindOne(ctx, byKey(key), opts).Decode(doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.expired(doc) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return doc, nil
+}
+
+// withoutValue returns the options reading the document without its value.
+func withoutValue() *options.FindOneOptions {
+	return options.FindOne().SetProjection(bson.D{{Key: valueField, Value: 0}})
+}
+
+// GetMany method returns the values of the existing keys using a single $in query.
+func (provider *Mongo) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return result
+	}
+
+	filter := bson.D{{Key: keyField, Value: bson.D{{Key: "$in", Value: keys}}}, provider.live()}
+
+	err := provider.find(context.Background(), filter, options.Find(), func(doc *document) {
+		result[doc.Key] = doc.Value
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys in MongoDB, %v", err)
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Mongo) GetTTL(key string) (time.Duration, bool) {
+	doc, err := provider.getDocument(context.Background(), key, withoutValue())
+	if err != nil {
+		return 0, false
+	}
+
+	if doc.ExpiresAt != nil {
+		return doc.ExpiresAt.Sub(provider.clock.Now()), true
+	}
+
+	return core.NoExpiration, true
+}
+
+// Exists method will check the key in MongoDB provider without reading its value.
+func (provider *Mongo) Exists(key string) bool {
+	_, err := provider.getDocument(context.Background(), key, withoutValue())
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Mongo) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Mongo) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in MongoDB", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *Mongo) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into MongoDB, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in MongoDB, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in MongoDB: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in MongoDB", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in MongoDB provider.
+func (provider *Mongo) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will upsert the response unless the context is done before the document is written.
+// A non-positive duration stores the document without expiry.
+func (provider *Mongo) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.ReplaceOne(ctx, byKey(key), provider.newDocument(key, value, duration), options.Replace().SetUpsert(true))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into MongoDB, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will upsert the entries in MongoDB provider using a single unordered bulk write.
+// The documents written before a failure are kept and the error is returned.
+func (provider *Mongo) SetMany(items map[string]core.Entry) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(items))
+
+	for key, item := range items {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(byKey(key)).
+			SetReplacement(provider.newDocument(key, item.Value, item.Duration)).
+			SetUpsert(true))
+	}
+
+	_, err := provider.BulkWrite(context.Background(), models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the entries into MongoDB, %v", err)
+	}
+
+	return err
+}
+
+// SetNX method will store the response in MongoDB provider only if the key doesn't exist yet or is expired.
+// The upsert only matches an expired document, the unique _id rejects the insertion over a live one.
+func (provider *Mongo) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	filter := bson.D{{Key: keyField, Value: key}, {Key: expiresAtField, Value: bson.D{{Key: "$lte", Value: provider.clock.Now()}}}}
+
+	_, err := provider.ReplaceOne(context.Background(), filter, provider.newDocument(key, value, duration), options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into MongoDB, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in MongoDB provider only if the stored bytes equal old.
+// The comparison is the filter of the replacement, a nil old value creates the key like SetNX.
+func (provider *Mongo) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	filter := bson.D{{Key: keyField, Value: key}, {Key: valueField, Value: old}, provider.live()}
+
+	res, err := provider.ReplaceOne(context.Background(), filter, provider.newDocument(key, value, duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into MongoDB, %v", key, err)
+
+		return false, err
+	}
+
+	return res.MatchedCount == 1, nil
+}
+
+// Increment method will add delta to the counter stored in MongoDB provider.
+// The counter is swapped with the read value and retried when a concurrent writer updated it in between.
+func (provider *Mongo) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		current, err := provider.GetWithError(key)
+		if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+			provider.logger.Errorf("Impossible to increment the key %s into MongoDB, %v", key, err)
+
+			return 0, err
+		}
+
+		value, encoded, err := core.AddCounter(current, delta)
+		if err != nil {
+			return 0, err
+		}
+
+		swapped, err := provider.CompareAndSwap(key, current, encoded, duration)
+		if err != nil {
+			return 0, err
+		}
+
+		if swapped {
+			return value, nil
+		}
+	}
+}
+
+// Decrement method will subtract delta from the counter stored in MongoDB provider.
+func (provider *Mongo) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in MongoDB provider.
+func (provider *Mongo) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into MongoDB, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Mongo) GetStream(key string) (io.ReadCloser, error) {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Mongo) Touch(key string, duration time.Duration) error {
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: expiresAtField, Value: ""}}}}
+	if expiry := provider.expiresAt(duration); expiry != nil {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: expiresAtField, Value: *expiry}}}}
+	}
+
+	res, err := provider.UpdateOne(context.Background(), bson.D{{Key: keyField, Value: key}, provider.live()}, update)
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into MongoDB, %v", key, err)
+
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return core.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// Delete method will delete the response in MongoDB provider if exists corresponding to key param.
+func (provider *Mongo) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	_, err := provider.DeleteOne(context.Background(), byKey(key))
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in MongoDB, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in MongoDB provider if exists corresponding to the prefix pattern param.
+// The live documents are deleted by a single query on the prefix regex, the expired ones are left to the TTL index.
+func (provider *Mongo) DeleteMany(pattern string) {
+	if _, err := provider.Collection.DeleteMany(context.Background(), provider.prefixed(core.KeyPrefix(pattern))); err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in MongoDB, %v", pattern, err)
+	}
+}
+
+// DeleteKeys method will delete the keys with their metadata in MongoDB provider using a single command.
+// The missing keys are ignored.
+func (provider *Mongo) DeleteKeys(keys []string) error {
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+
+	_, err := provider.Collection.DeleteMany(context.Background(), bson.D{{Key: keyField, Value: bson.D{{Key: "$in", Value: deleted}}}})
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in MongoDB, %v", err)
+
+		return err
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in MongoDB provider.
+func (provider *Mongo) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in MongoDB provider.
+func (provider *Mongo) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in MongoDB provider.
+func (provider *Mongo) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in MongoDB provider without altering it.
+func (provider *Mongo) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in MongoDB provider and its metadata under a parallel key.
+func (provider *Mongo) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in MongoDB provider, core.ErrKeyNotFound if none.
+func (provider *Mongo) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the live document count and the data size of the collection.
+// The size still counts the expired documents not deleted yet.
+func (provider *Mongo) Stats() (core.StorageStats, error) {
+	count, err := provider.CountDocuments(context.Background(), bson.D{provider.live()})
+	if err != nil {
+		provider.logger.Errorf("Impossible to count the MongoDB documents, %v", err)
+
+		return core.StorageStats{}, err
+	}
+
+	stats := core.StorageStats{KeyCount: count}
+	pipeline := mongo.Pipeline{{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}}
+
+	cursor, err := provider.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the MongoDB collection %s statistics, %v", provider.Collection.Name(), err)
+
+		return stats, err
+	}
+
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	// A sharded collection returns the statistics of each shard.
+	for cursor.Next(context.Background()) {
+		var collStats struct {
+			StorageStats struct {
+				Size int64 `bson:"size"`
+			} `bson:"storageStats"`
+		}
+
+		if err = cursor.Decode(&collStats); err != nil {
+			return stats, err
+		}
+
+		stats.ApproxSizeBytes += collStats.StorageStats.Size
+	}
+
+	return stats, cursor.Err()
+}
+
+// Ping method will check the MongoDB server is reachable.
+func (provider *Mongo) Ping(ctx context.Context) error {
+	return provider.Database().Client().Ping(ctx, nil)
+}
+
+// Export method will stream every key of the MongoDB provider paginating the scanned keys.
+func (provider *Mongo) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in MongoDB provider by bulk writes.
+func (provider *Mongo) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Mongo) Init() error {
+	return nil
+}
+
+// Reset method will delete every document of the collection.
+func (provider *Mongo) Reset() error {
+	_, err := provider.Collection.DeleteMany(context.Background(), bson.D{})
+
+	return err
+}
+
+// Close method will disconnect the MongoDB client.
+func (provider *Mongo) Close() error {
+	return provider.Database().Client().Disconnect(context.Background())
+}
@@ -1,23 +1,27 @@
 module github.com/darkweak/storages/nats
 
-go 1.22.1
+go 1.23.0
 
 replace github.com/darkweak/storages/core => ../core
 
 require (
 	dario.cat/mergo v1.0.0
 	github.com/darkweak/storages/core v0.0.18
-	github.com/nats-io/nats.go v1.39.1
-	github.com/pierrec/lz4/v4 v4.1.23
+	github.com/nats-io/nats-server/v2 v2.11.1
+	github.com/nats-io/nats.go v1.42.0
 	go.uber.org/zap v1.27.0
 )
 
 require (
-	github.com/klauspost/compress v1.17.9 // indirect
-	github.com/nats-io/nkeys v0.4.9 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/nats-io/nkeys v0.4.11 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/pierrec/lz4/v4 v4.1.23 // indirect
+	go.opentelemetry.io/otel v1.28.0 // indirect
+	go.opentelemetry.io/otel/trace v1.28.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
-	golang.org/x/crypto v0.31.0 // indirect
-	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
 	google.golang.org/protobuf v1.36.5 // indirect
 )
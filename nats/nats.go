@@ -2,12 +2,12 @@ package nats
 
 import (
 	"bytes"
-	"encoding/gob"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -15,21 +15,27 @@ import (
 	"dario.cat/mergo"
 	"github.com/darkweak/storages/core"
 	nats "github.com/nats-io/nats.go"
-	lz4 "github.com/pierrec/lz4/v4"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
+// limitMarkerTTL is how long the bucket keeps the markers of the expired keys, enabling it allows the
+// per-message TTLs on the servers supporting them.
+const limitMarkerTTL = time.Second
+
 // Nats provider type.
 type Nats struct {
-	// keyvalue     jetstream.KeyValue
-	jsCtx  nats.JetStreamContext
-	bucket string
-	stale  time.Duration
-	logger core.Logger
-}
-
-type item struct {
-	invalidAt time.Time
-	value     []byte
+	js          jetstream.JetStream
+	keyvalue    jetstream.KeyValue
+	stream      jetstream.Stream
+	conn        *nats.Conn
+	bucket      string
+	bucketTTL   time.Duration
+	perKeyTTL   bool
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	compression string
+	clock       core.Clock
 }
 
 func sanitizeProperties(configMap map[string]interface{}) map[string]interface{} {
@@ -109,8 +115,26 @@ func sanitizeProperties(configMap map[string]interface{}) map[string]interface{}
 
 // Factory function create new Nats instance.
 func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if natsConfiguration.Validate {
+		if err := core.ValidateConfig(natsConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Nats configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(natsConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, natsConfiguration, logger)
+}
+
+func factory(natsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	natsOptions := nats.GetDefaultOptions()
 	bucketName := "souin-bucket"
+	bucketTTL := time.Duration(0)
 
 	if natsConfiguration.Configuration != nil {
 		var parsedNats nats.Options
@@ -119,6 +143,10 @@ func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale tim
 			bucketName, _ = bucket.(string)
 		}
 
+		if ttl, ok := natsConfiguration.Configuration.(map[string]interface{})["ttl"]; ok && ttl != nil {
+			bucketTTL, _ = time.ParseDuration(fmt.Sprint(ttl))
+		}
+
 		natsConfiguration.Configuration = sanitizeProperties(natsConfiguration.Configuration.(map[string]interface{}))
 		if b, e := json.Marshal(natsConfiguration.Configuration); e == nil {
 			if e = json.Unmarshal(b, &parsedNats); e != nil {
@@ -144,23 +172,90 @@ func Factory(natsConfiguration core.CacheProvider, logger core.Logger, stale tim
 		return nil, err
 	}
 
-	stream, err := natsConn.JetStream()
+	js, err := jetstream.New(natsConn)
 	if err != nil {
+		natsConn.Close()
 		logger.Error("Impossible to instantiate the Nats DB.", err)
 
 		return nil, err
 	}
 
-	_, err = stream.CreateKeyValue(&nats.KeyValueConfig{
-		Bucket: bucketName,
-	})
+	// The per-message TTLs need the limit markers, the servers before 2.11 only support the bucket TTL.
+	perKeyTTL := true
+	config := jetstream.KeyValueConfig{Bucket: bucketName, TTL: bucketTTL, LimitMarkerTTL: limitMarkerTTL}
+
+	keyvalue, err := js.CreateOrUpdateKeyValue(context.Background(), config)
+	if errors.Is(err, jetstream.ErrLimitMarkerTTLNotSupported) {
+		logger.Warnf("The Nats server doesn't support the per-message TTL, the keys of the bucket %s expire with the bucket TTL %s", bucketName, bucketTTL)
+
+		perKeyTTL = false
+		config.LimitMarkerTTL = 0
+		keyvalue, err = js.CreateOrUpdateKeyValue(context.Background(), config)
+	}
+
+	if err != nil {
+		natsConn.Close()
+		logger.Errorf("Impossible to create the Nats bucket %s, %v", bucketName, err)
+
+		return nil, err
+	}
+
+	stream, err := js.Stream(context.Background(), "KV_"+bucketName)
 	if err != nil {
-		logger.Error("Impossible to create the Nats bucket %s.", err, bucketName)
+		natsConn.Close()
+		logger.Errorf("Impossible to get the stream of the Nats bucket %s, %v", bucketName, err)
 
 		return nil, err
 	}
 
-	return &Nats{jsCtx: stream, bucket: bucketName, logger: logger, stale: stale}, nil
+	return &Nats{
+		js:          js,
+		keyvalue:    keyvalue,
+		stream:      stream,
+		conn:        natsConn,
+		bucket:      bucketName,
+		bucketTTL:   bucketTTL,
+		perKeyTTL:   perKeyTTL,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(natsConfiguration),
+		compression: core.ConfiguredCompression(natsConfiguration, logger),
+		clock:       core.ClockOrDefault(natsConfiguration.Clock),
+		stale:       stale,
+	}, nil
+}
+
+// messageTTL returns the per-message TTL of the duration, zero when the bucket TTL applies.
+// The server rejects the TTLs below one second so they are rounded up.
+func (provider *Nats) messageTTL(duration time.Duration) time.Duration {
+	if !provider.perKeyTTL || duration <= 0 {
+		return 0
+	}
+
+	return max(duration.Round(time.Second), time.Second)
+}
+
+// subject returns the subject storing the key in the bucket stream.
+func (provider *Nats) subject(key string) string {
+	return "$KV." + provider.bucket + "." + key
+}
+
+// put publishes the value of the key with its per-message TTL, the options add the publish expectations.
+func (provider *Nats) put(ctx context.Context, key string, value []byte, duration time.Duration, opts ...jetstream.PublishOpt) (uint64, error) {
+	ttl := provider.messageTTL(duration)
+	if ttl == 0 && len(opts) == 0 {
+		return provider.keyvalue.Put(ctx, key, value)
+	}
+
+	if ttl > 0 {
+		opts = append(opts, jetstream.WithMsgTTL(ttl))
+	}
+
+	ack, err := provider.js.PublishMsg(ctx, &nats.Msg{Subject: provider.subject(key), Data: value}, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return ack.Sequence, nil
 }
 
 // Name returns the storer name.
@@ -173,24 +268,32 @@ func (provider *Nats) Uuid() string {
 	return fmt.Sprintf("%s-%s", provider.bucket, provider.stale)
 }
 
+// keys returns the keys of the bucket, the empty bucket has no key.
+func (provider *Nats) keys(ctx context.Context) ([]string, error) {
+	keys, err := provider.keyvalue.Keys(ctx)
+	if errors.Is(err, jetstream.ErrNoKeysFound) {
+		return []string{}, nil
+	}
+
+	return keys, err
+}
+
 // MapKeys method returns a map with the key and value.
 func (provider *Nats) MapKeys(prefix string) map[string]string {
 	keys := map[string]string{}
 
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	keysList, err := provider.keys(context.Background())
 	if err != nil {
-		return keys
-	}
+		provider.logger.Errorf("Impossible to list the keys in Nats, %v", err)
 
-	keysList, err := keyvalue.Keys()
-	if err != nil {
 		return keys
 	}
 
 	for _, key := range keysList {
-		if strings.HasPrefix(key, prefix) {
-			val, _ := keyvalue.Get(key)
-			keys[strings.TrimPrefix(key, prefix)] = string(val.Value())
+		if strings.HasPrefix(key, prefix) && core.ListedKey(prefix, key) {
+			if value := provider.Get(key); value != nil {
+				keys[strings.TrimPrefix(key, prefix)] = string(value)
+			}
 		}
 	}
 
@@ -199,137 +302,347 @@ func (provider *Nats) MapKeys(prefix string) map[string]string {
 
 // ListKeys method returns the list of existing keys.
 func (provider *Nats) ListKeys() []string {
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	keys, _ := provider.keys(context.Background())
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Nats) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys, err := provider.keys(context.Background())
 	if err != nil {
-		return []string{}
+		return []string{}, ""
 	}
 
-	keys, _ := keyvalue.Keys()
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
 
-	return keys
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Nats) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
 }
 
 // Get method returns the populated response if exists, empty response then.
 func (provider *Nats) Get(key string) []byte {
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in Nats: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Nats) GetContext(ctx context.Context, key string) ([]byte, error) {
+	entry, err := provider.keyvalue.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, core.ErrKeyNotFound
+	}
+
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	value, err := keyvalue.Get(key)
-	if err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
-		provider.logger.Errorf("Impossible to get the key %s in Nats: %v", key, err)
+	return entry.Value(), nil
+}
 
-		return nil
-	} else if err != nil {
-		return nil
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Nats) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Nats) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
 	}
 
-	var res item
+	return result
+}
 
-	err = gob.NewDecoder(bytes.NewBuffer(value.Value())).Decode(&res)
+// GetTTL method returns the remaining time to live of the key if exists.
+// The per-message TTL is read from the stored message headers, the bucket TTL applies otherwise.
+func (provider *Nats) GetTTL(key string) (time.Duration, bool) {
+	message, err := provider.stream.GetLastMsgForSubject(context.Background(), provider.subject(key))
 	if err != nil {
-		return value.Value()
+		return 0, false
+	}
+
+	if operation := message.Header.Get("KV-Operation"); operation == "DEL" || operation == "PURGE" {
+		return 0, false
+	}
+
+	ttl := provider.bucketTTL
+	if value := message.Header.Get(jetstream.MsgTTLHeader); value != "" {
+		ttl, _ = time.ParseDuration(value)
+	}
+
+	if ttl <= 0 {
+		return core.NoExpiration, true
 	}
 
-	if res.invalidAt.After(time.Now()) {
-		return res.value
+	remaining := ttl - time.Since(message.Time)
+	if remaining <= 0 {
+		return 0, false
 	}
 
-	_ = keyvalue.Delete(key)
+	return remaining, true
+}
+
+// Exists method will check the key in Nats provider, the expired entries are purged by the bucket.
+func (provider *Nats) Exists(key string) bool {
+	_, err := provider.keyvalue.Get(context.Background(), key)
 
-	return value.Value()
+	return err == nil
 }
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Nats) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
-	if err != nil {
-		return
-	}
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
 
-	value, err := keyvalue.Get(core.MappingKeyPrefix + key)
-	if err != nil {
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Nats) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
 		provider.logger.Debugf("Impossible to get the mapping key %s in Nats", core.MappingKeyPrefix+key)
 
-		return
+		return fresh, stale, match
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, value.Value(), req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
 
-	return
+	return fresh, stale, match
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+// The value is stored compressed as is so the entries are readable by the other storers.
 func (provider *Nats) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+		return nil
+	}
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	now := provider.clock.Now()
 
-		provider.logger.Errorf("Impossible to compress the key %s into Nats: %v", variedKey, err)
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Nats, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Nats, %v", variedKey, err)
-
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
 		return err
 	}
 
-	property := item{
-		invalidAt: now.Add(duration + provider.stale),
-		value:     compressed.Bytes(),
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Nats, %v", variedKey, err)
+
+		return err
 	}
 
-	buf := new(bytes.Buffer)
+	mappingKey := core.MappingKeyPrefix + baseKey
 
-	err := gob.NewEncoder(buf).Encode(property)
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if err != nil {
-		provider.logger.Errorf("Impossible to encode the key %s in Nats: %v", variedKey, err)
+		provider.logger.Errorf("Impossible to update the mapping key %s in Nats: %v", mappingKey, err)
 
-		return nil
+		return err
 	}
 
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	return provider.Set(mappingKey, val, duration+provider.stale)
+}
+
+// Set method will store the response in Nats provider.
+// The duration is the per-message TTL if the server supports it, the bucket TTL applies otherwise.
+func (provider *Nats) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response unless the context is done before the server acknowledges it.
+func (provider *Nats) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.put(ctx, key, value, duration)
 	if err != nil {
-		return err
+		provider.logger.Errorf("Impossible to set value into Nats, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in Nats provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Nats) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Nats provider only if the key doesn't exist yet.
+func (provider *Nats) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	opts := []jetstream.KVCreateOpt{}
+	if ttl := provider.messageTTL(duration); ttl > 0 {
+		opts = append(opts, jetstream.KeyTTL(ttl))
+	}
+
+	_, err := provider.keyvalue.Create(context.Background(), key, value, opts...)
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return false, nil
 	}
 
-	_, err = keyvalue.Put(variedKey, buf.Bytes())
 	if err != nil {
-		provider.logger.Errorf("Impossible to set value into Nats for the key %s, %v", variedKey, err)
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Nats, %v", key, err)
 
-		return err
+		return false, err
 	}
 
-	mappingKey := core.MappingKeyPrefix + baseKey
-	r := provider.Get(mappingKey)
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Nats provider only if the stored bytes equal old.
+// The write is conditioned on the read revision and retried when a concurrent writer updated the key in between.
+func (provider *Nats) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	for {
+		entry, err := provider.keyvalue.Get(context.Background(), key)
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return false, nil
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into Nats, %v", key, err)
+
+			return false, err
+		}
+
+		if !core.SwapMatches(entry.Value(), old) {
+			return false, nil
+		}
+
+		_, err = provider.put(context.Background(), key, value, duration, jetstream.WithExpectLastSequencePerSubject(entry.Revision()))
+		if errors.Is(err, jetstream.ErrKeyExists) {
+			continue
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into Nats, %v", key, err)
+
+			return false, err
+		}
+
+		return true, nil
+	}
+}
+
+// Increment method will add delta to the counter stored in Nats provider.
+// The write is conditioned on the read revision and retried when a concurrent writer updated the counter in between.
+func (provider *Nats) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		var (
+			current  []byte
+			revision uint64
+		)
+
+		entry, err := provider.keyvalue.Get(context.Background(), key)
+		if err == nil {
+			current, revision = entry.Value(), entry.Revision()
+		} else if !errors.Is(err, jetstream.ErrKeyNotFound) {
+			provider.logger.Errorf("Impossible to increment the key %s into Nats, %v", key, err)
+
+			return 0, err
+		}
+
+		value, encoded, err := core.AddCounter(current, delta)
+		if err != nil {
+			return 0, err
+		}
+
+		if current == nil {
+			var created bool
 
-	val, err := core.MappingUpdater(variedKey, r, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+			created, err = provider.SetNX(key, encoded, duration)
+			if err == nil && !created {
+				continue
+			}
+		} else {
+			_, err = provider.put(context.Background(), key, encoded, duration, jetstream.WithExpectLastSequencePerSubject(revision))
+			if errors.Is(err, jetstream.ErrKeyExists) {
+				continue
+			}
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to increment the key %s into Nats, %v", key, err)
+
+			return 0, err
+		}
+
+		return value, nil
+	}
+}
+
+// Decrement method will subtract delta from the counter stored in Nats provider.
+func (provider *Nats) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Nats provider.
+func (provider *Nats) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
 	if err != nil {
-		provider.logger.Errorf("Impossible to update the mapping key %s in Nats: %v", mappingKey, err)
+		provider.logger.Errorf("Impossible to stream the key %s into Nats, %v", key, err)
+	}
 
-		return err
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Nats) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
 	}
 
-	return provider.Set(mappingKey, val, duration+provider.stale)
+	return core.DecompressStream(bytes.NewReader(value))
 }
 
-// Set method will store the response in Nats provider.
-func (provider *Nats) Set(key string, value []byte, _ time.Duration) error {
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+// Touch method will update the time to live of the key without altering its value.
+// The same value is put again with the new per-message TTL, or to reset its age against the bucket TTL.
+func (provider *Nats) Touch(key string, duration time.Duration) error {
+	entry, err := provider.keyvalue.Get(context.Background(), key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return core.ErrKeyNotFound
+	}
+
 	if err != nil {
 		return err
 	}
 
-	_, err = keyvalue.Put(key, value)
+	_, err = provider.put(context.Background(), key, entry.Value(), duration, jetstream.WithExpectLastSequencePerSubject(entry.Revision()))
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		// The key has been rewritten in between, the new value carries its own time to live.
+		return nil
+	}
+
 	if err != nil {
-		provider.logger.Errorf("Impossible to set value into Nats, %v", err)
+		provider.logger.Errorf("Impossible to touch the key %s into Nats, %v", key, err)
 	}
 
 	return err
@@ -337,38 +650,149 @@ func (provider *Nats) Set(key string, value []byte, _ time.Duration) error {
 
 // Delete method will delete the response in Nats provider if exists corresponding to key param.
 func (provider *Nats) Delete(key string) {
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if err := provider.keyvalue.Purge(context.Background(), key); err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in Nats, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in Nats provider if exists corresponding to the prefix pattern param.
+func (provider *Nats) DeleteMany(pattern string) {
+	prefix := core.KeyPrefix(pattern)
+
+	keys, err := provider.keys(context.Background())
 	if err != nil {
-		provider.logger.Errorf("Impossible to delete the key %s in Nats %s, %v", key, err)
+		provider.logger.Errorf("Impossible to list the keys in Nats, %v", err)
 
 		return
 	}
 
-	_ = keyvalue.Purge(key)
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			_ = provider.keyvalue.Purge(context.Background(), key)
+		}
+	}
 }
 
-// DeleteMany method will delete the responses in Nats provider if exists corresponding to the regex key param.
-func (provider *Nats) DeleteMany(key string) {
-	rgKey, err := regexp.Compile(key)
-	if err != nil {
-		return
+// DeleteKeys method will delete the keys in Nats provider one by one, the missing keys are ignored.
+func (provider *Nats) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
 	}
 
-	keyvalue, err := provider.jsCtx.KeyValue(provider.bucket)
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Nats provider.
+func (provider *Nats) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Nats provider.
+func (provider *Nats) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Nats provider.
+func (provider *Nats) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Nats provider without altering it.
+func (provider *Nats) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Nats provider and its metadata under a parallel key.
+func (provider *Nats) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Nats provider, core.ErrKeyNotFound if none.
+func (provider *Nats) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the message count and size of the bucket stream.
+// The delete markers are counted until the stream discards them.
+func (provider *Nats) Stats() (core.StorageStats, error) {
+	status, err := provider.keyvalue.Status(context.Background())
 	if err != nil {
-		return
+		provider.logger.Errorf("Impossible to get the Nats bucket status, %v", err)
+
+		return core.StorageStats{}, err
 	}
 
-	keys, err := keyvalue.Keys()
+	//nolint:gosec
+	return core.StorageStats{KeyCount: int64(status.Values()), ApproxSizeBytes: int64(status.Bytes())}, nil
+}
+
+// Ping method will flush the Nats connection, waiting for the server PONG.
+func (provider *Nats) Ping(ctx context.Context) error {
+	return provider.conn.FlushWithContext(ctx)
+}
+
+// Watch method will stream the changes of the keys starting with the prefix using a watcher of the bucket,
+// see core.Watcher. The prefix doesn't map to the subject wildcards so every update of the bucket is received
+// and filtered, the expired keys are reported as deleted by the servers writing the limit markers.
+func (provider *Nats) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	watcher, err := provider.keyvalue.WatchAll(ctx, jetstream.UpdatesOnly())
 	if err != nil {
-		return
+		provider.logger.Errorf("Impossible to watch the prefix %s in Nats, %v", prefix, err)
+
+		return nil, err
 	}
 
-	for _, key := range keys {
-		if rgKey.MatchString(key) {
-			_ = keyvalue.Purge(key)
+	events := make(chan core.Event)
+
+	go func() {
+		defer close(events)
+		defer func() { _ = watcher.Stop() }()
+
+		for {
+			var entry jetstream.KeyValueEntry
+
+			select {
+			case update, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+
+				entry = update
+			case <-ctx.Done():
+				return
+			}
+
+			if entry == nil || !strings.HasPrefix(entry.Key(), prefix) {
+				continue
+			}
+
+			eventType := core.EventSet
+			if entry.Operation() != jetstream.KeyValuePut {
+				eventType = core.EventDelete
+			}
+
+			select {
+			case events <- core.Event{Type: eventType, Key: entry.Key()}:
+			case <-ctx.Done():
+				return
+			}
 		}
-	}
+	}()
+
+	return events, nil
+}
+
+// Export method will stream every key of the Nats provider paginating the scanned keys.
+func (provider *Nats) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Nats provider by batches.
+func (provider *Nats) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
 }
 
 // Init method will.
@@ -376,7 +800,16 @@ func (provider *Nats) Init() error {
 	return nil
 }
 
-// Reset method will reset or close provider.
+// Reset method will delete every key of the bucket.
 func (provider *Nats) Reset() error {
+	provider.DeleteMany("*")
+
+	return nil
+}
+
+// Close method will close the Nats connection.
+func (provider *Nats) Close() error {
+	provider.conn.Close()
+
 	return nil
 }
@@ -1,11 +1,19 @@
+//go:build nats
+
 package nats_test
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/darkweak/storages/core"
 	"github.com/darkweak/storages/nats"
+	"github.com/nats-io/nats-server/v2/server"
 	"go.uber.org/zap"
 )
 
@@ -15,10 +23,39 @@ const (
 	baseValue      = "My first data"
 )
 
+var natsURL string
+
+// TestMain runs the tests against an embedded NATS server with JetStream enabled.
+func TestMain(m *testing.M) {
+	storeDir, err := os.MkdirTemp("", "souin-nats")
+	if err != nil {
+		panic(err)
+	}
+
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: storeDir})
+	if err != nil {
+		panic(err)
+	}
+
+	go srv.Start()
+
+	if !srv.ReadyForConnections(10 * time.Second) {
+		panic("The embedded NATS server isn't ready")
+	}
+
+	natsURL = srv.ClientURL()
+	code := m.Run()
+
+	srv.Shutdown()
+	_ = os.RemoveAll(storeDir)
+
+	os.Exit(code)
+}
+
 func getNatsInstance() (core.Storer, error) {
 	z, _ := zap.NewDevelopment()
 
-	return nats.Factory(core.CacheProvider{}, z.Sugar(), 0)
+	return nats.Factory(core.CacheProvider{URL: natsURL}, z.Sugar(), 0)
 }
 
 func TestNatsConnectionFactory(t *testing.T) {
@@ -105,3 +142,149 @@ func TestNats_Init(t *testing.T) {
 		t.Error("Impossible to init Nats provider")
 	}
 }
+
+func TestNats_Stats(t *testing.T) {
+	client, _ := getNatsInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestNats_SetMultiLevel(t *testing.T) {
+	client, _ := getNatsInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("MultiLevelKey", "MultiLevelKey-varied", []byte(response), http.Header{}, "", time.Minute, "MultiLevelKey"); err != nil {
+		t.Fatalf("Failed to set the value: %v", err)
+	}
+
+	// The varied key is stored in the lz4 format shared by the storers.
+	if stored, err := core.Decompress(client.Get("MultiLevelKey-varied")); err != nil || string(stored) != response {
+		t.Errorf("The varied key should be stored compressed, %s provided: %v", stored, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/multi-level", nil)
+
+	fresh, _ := client.GetMultiLevel("MultiLevelKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if body, _ := io.ReadAll(fresh.Body); string(body) != baseValue {
+		t.Errorf("The body should be %s, %s provided", baseValue, body)
+	}
+}
+
+func TestNats_PerKeyTTL(t *testing.T) {
+	client, _ := getNatsInstance()
+	_ = client.Set("PerKeyTTLKey", []byte(baseValue), time.Second)
+	_ = client.Set("PerKeyTTLLongKey", []byte(baseValue), time.Minute)
+
+	if ttl, found := client.GetTTL("PerKeyTTLKey"); !found || ttl <= 0 || ttl > time.Second {
+		t.Errorf("The TTL should be between 0 and 1s, %v provided", ttl)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if res := client.Get("PerKeyTTLKey"); res != nil {
+		t.Errorf("The key PerKeyTTLKey should be expired, %s provided", res)
+	}
+
+	if res := client.Get("PerKeyTTLLongKey"); string(res) != baseValue {
+		t.Errorf("The key PerKeyTTLLongKey should be kept, %s provided", res)
+	}
+}
+
+func TestNats_BucketTTL(t *testing.T) {
+	client, err := nats.Factory(core.CacheProvider{
+		Configuration: map[string]interface{}{
+			"Servers":  natsURL,
+			"keyvalue": "souin-bucket-ttl",
+			"ttl":      "1s",
+		},
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the bucket with a TTL: %v", err)
+	}
+
+	_ = client.Set("BucketTTLKey", []byte(baseValue), 0)
+
+	if ttl, found := client.GetTTL("BucketTTLKey"); !found || ttl <= 0 || ttl > time.Second {
+		t.Errorf("The bucket TTL should apply, %v provided", ttl)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if res := client.Get("BucketTTLKey"); res != nil {
+		t.Errorf("The key BucketTTLKey should be expired with the bucket, %s provided", res)
+	}
+}
+
+func TestNats_MapKeys(t *testing.T) {
+	client, _ := getNatsInstance()
+	client.DeleteMany("MAP_")
+
+	_ = client.Set("MAP_first", []byte("1"), time.Minute)
+	_ = client.Set("MAP_second", []byte("2"), time.Minute)
+	_ = client.Set("OTHER_third", []byte("3"), time.Minute)
+
+	keys := client.MapKeys("MAP_")
+	if len(keys) != 2 || keys["first"] != "1" || keys["second"] != "2" {
+		t.Errorf("Only the keys with the MAP_ prefix should be mapped, %v provided", keys)
+	}
+}
+
+func TestNats_Watch(t *testing.T) {
+	client, err := getNatsInstance()
+	if err != nil {
+		t.Fatalf("Failed to create the instance: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
@@ -2,12 +2,15 @@ package nuts
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"os"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,23 +19,37 @@ import (
 	"dario.cat/mergo"
 	"github.com/darkweak/storages/core"
 	"github.com/nutsdb/nutsdb"
-	lz4 "github.com/pierrec/lz4/v4"
 )
 
 var nutsInstanceMap = sync.Map{}
 
+// nutsIndexModes holds the entry index mode each opened DB got, the instances sharing it keep that one.
+var nutsIndexModes = sync.Map{}
+
 // Nuts provider type.
 type Nuts struct {
 	*nutsdb.DB
 
-	stale  time.Duration
-	logger core.Logger
-	uuid   string
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	uuid        string
+	compression string
+	clock       core.Clock
+	namespace   string
+	bucket      string
+	dir         string
+	timeout     time.Duration
+	sweeper     *sweeper
+	notifier    *core.EvictionNotifier
+	watchers    *core.WatchHub
+	expiries    *expiries
+	reads       *readCache
 }
 
 const (
-	bucket    = "souin-bucket"
-	nutsLimit = 1 << 16
+	defaultBucket = "souin-bucket"
+	nutsLimit     = 1 << 16
 )
 
 func sanitizeProperties(configMap map[string]interface{}) map[string]interface{} {
@@ -126,6 +143,23 @@ func sanitizeProperties(configMap map[string]interface{}) map[string]interface{}
 
 // Factory function create new Nuts instance.
 func Factory(nutsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if nutsConfiguration.Validate {
+		if err := core.ValidateConfig(nutsConfiguration, core.CheckWritablePath); err != nil {
+			logger.Errorf("Impossible to validate the Nuts configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(nutsConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, nutsConfiguration, logger)
+}
+
+func factory(nutsConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	nutsOptions := nutsdb.DefaultOptions
 	nutsOptions.Dir = "/tmp/souin-nuts"
 
@@ -149,22 +183,70 @@ func Factory(nutsConfiguration core.CacheProvider, logger core.Logger, stale tim
 		}
 	}
 
+	// The segment size, the sync writes and the index mode only apply when the database is opened, the
+	// instances sharing a directory keep the ones of the first opening.
+	if nutsConfiguration.SegmentSize > 0 {
+		nutsOptions.SegmentSize = nutsConfiguration.SegmentSize
+	}
+
+	if nutsConfiguration.SyncWrites != nil {
+		nutsOptions.SyncEnable = *nutsConfiguration.SyncWrites
+	}
+
+	switch nutsConfiguration.IndexMode {
+	case "":
+	case core.IndexModeKeyValue:
+		nutsOptions.EntryIdxMode = nutsdb.HintKeyValAndRAMIdxMode
+	case core.IndexModeKey:
+		nutsOptions.EntryIdxMode = nutsdb.HintKeyAndRAMIdxMode
+	default:
+		logger.Warnf("Unknown index mode %q, it must be %s or %s, the configured one is used.", nutsConfiguration.IndexMode, core.IndexModeKeyValue, core.IndexModeKey)
+	}
+
+	bucketName, uuidDir := defaultBucket, nutsOptions.Dir
+	if nutsConfiguration.Bucket != "" {
+		bucketName, uuidDir = nutsConfiguration.Bucket, nutsOptions.Dir+"/"+nutsConfiguration.Bucket
+	}
+
+	// Nuts has no read-only mode, the writes are rejected by the Instrument decorator and the
+	// expired entries are left to the reads skipping them.
+	sweepInterval := nutsConfiguration.SweepInterval
+	if nutsConfiguration.ReadOnly {
+		sweepInterval = 0
+	}
+
 	if instance, ok := nutsInstanceMap.Load(nutsOptions.Dir); ok && instance != nil {
-		return &Nuts{
-			DB:     instance.(*nutsdb.DB),
-			stale:  stale,
-			logger: logger,
-		}, nil
+		return (&Nuts{
+			DB:          instance.(*nutsdb.DB),
+			stale:       stale,
+			logger:      logger,
+			mapper:      core.ConfiguredMapper(nutsConfiguration),
+			compression: core.ConfiguredCompression(nutsConfiguration, logger),
+			clock:       core.ClockOrDefault(nutsConfiguration.Clock),
+			namespace:   core.NamespacePrefix(nutsConfiguration.Namespace),
+			bucket:      bucketName,
+			dir:         nutsOptions.Dir,
+			timeout:     nutsConfiguration.OperationTimeout,
+			notifier:    core.NewEvictionNotifier(nutsConfiguration.OnEvict, logger),
+			watchers:    core.NewWatchHub(logger),
+			reads:       newReadCache(nutsConfiguration.ReadCacheSize),
+		}).withSweeper(sweepInterval), nil
+	}
+
+	if err := core.CreateDirectory(nutsOptions.Dir, nutsConfiguration.DirPermissions); err != nil {
+		logger.Errorf("Impossible to create the Nuts directory %s, %v", nutsOptions.Dir, err)
+
+		return nil, err
 	}
 
 	database, err := nutsdb.Open(nutsOptions)
 	if err != nil {
 		logger.Error("Impossible to open the Nuts DB.", err)
 
-		if errors.Is(err, nutsdb.ErrCrc) {
+		if errors.Is(err, nutsdb.ErrCrc) && !nutsConfiguration.ReadOnly {
 			_ = os.Remove(nutsOptions.Dir)
 
-			return Factory(nutsConfiguration, logger, stale)
+			return factory(nutsConfiguration, logger, stale)
 		}
 
 		if errors.Is(err, nutsdb.ErrDirLocked) {
@@ -172,11 +254,21 @@ func Factory(nutsConfiguration core.CacheProvider, logger core.Logger, stale tim
 			time.Sleep(time.Second)
 
 			if instance, ok := nutsInstanceMap.Load(nutsOptions.Dir); ok && instance != nil {
-				return &Nuts{
-					DB:     instance.(*nutsdb.DB),
-					stale:  stale,
-					logger: logger,
-				}, nil
+				return (&Nuts{
+					DB:          instance.(*nutsdb.DB),
+					stale:       stale,
+					logger:      logger,
+					mapper:      core.ConfiguredMapper(nutsConfiguration),
+					compression: core.ConfiguredCompression(nutsConfiguration, logger),
+					clock:       core.ClockOrDefault(nutsConfiguration.Clock),
+					namespace:   core.NamespacePrefix(nutsConfiguration.Namespace),
+					bucket:      bucketName,
+					dir:         nutsOptions.Dir,
+					timeout:     nutsConfiguration.OperationTimeout,
+					notifier:    core.NewEvictionNotifier(nutsConfiguration.OnEvict, logger),
+					watchers:    core.NewWatchHub(logger),
+					reads:       newReadCache(nutsConfiguration.ReadCacheSize),
+				}).withSweeper(sweepInterval), nil
 			} else {
 				return nil, err
 			}
@@ -186,14 +278,60 @@ func Factory(nutsConfiguration core.CacheProvider, logger core.Logger, stale tim
 	}
 
 	instance := &Nuts{
-		DB:     database,
-		stale:  stale,
-		logger: logger,
-		uuid:   fmt.Sprintf("%s-%s", nutsOptions.Dir, stale),
+		DB:          database,
+		stale:       stale,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(nutsConfiguration),
+		uuid:        fmt.Sprintf("%s-%s%s", uuidDir, stale, nutsConfiguration.Namespace),
+		compression: core.ConfiguredCompression(nutsConfiguration, logger),
+		clock:       core.ClockOrDefault(nutsConfiguration.Clock),
+		namespace:   core.NamespacePrefix(nutsConfiguration.Namespace),
+		bucket:      bucketName,
+		dir:         nutsOptions.Dir,
+		timeout:     nutsConfiguration.OperationTimeout,
+		notifier:    core.NewEvictionNotifier(nutsConfiguration.OnEvict, logger),
+		watchers:    core.NewWatchHub(logger),
+		reads:       newReadCache(nutsConfiguration.ReadCacheSize),
 	}
 	nutsInstanceMap.Store(nutsOptions.Dir, instance.DB)
+	nutsIndexModes.Store(instance.DB, nutsOptions.EntryIdxMode)
+
+	return instance.withSweeper(sweepInterval), nil
+}
+
+// withSweeper starts the sweep of the expired entries when the interval is positive, the expiries are tracked
+// for the sweep to report them when the OnEvict callback is set.
+func (provider *Nuts) withSweeper(interval time.Duration) *Nuts {
+	if provider.notifier != nil {
+		provider.expiries = newExpiries()
+	}
+
+	if interval > 0 {
+		provider.sweeper = startSweeper(provider, interval)
+	}
+
+	return provider
+}
+
+// key returns the key stored in the database, prefixed by the namespace if any.
+func (provider *Nuts) key(key string) []byte {
+	return []byte(provider.namespace + key)
+}
+
+// put stores the value of the key in the transaction and tracks its expiry.
+func (provider *Nuts) put(tx *nutsdb.Tx, key string, value []byte, ttl uint32) error {
+	if err := tx.Put(provider.bucket, provider.key(key), value, ttl); err != nil {
+		return err
+	}
+
+	provider.expiries.watch(key, ttl)
+
+	return nil
+}
 
-	return instance, nil
+// operationContext returns a context expiring with the operation timeout if any.
+func (provider *Nuts) operationContext() (context.Context, context.CancelFunc) {
+	return core.OperationContext(context.Background(), provider.timeout)
 }
 
 // Name returns the storer name.
@@ -208,12 +346,23 @@ func (provider *Nuts) Uuid() string {
 
 // ListKeys method returns the list of existing keys.
 func (provider *Nuts) ListKeys() []string {
+	if provider.IsClose() {
+		return []string{}
+	}
+
 	keys := []string{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
 
 	err := provider.View(func(tx *nutsdb.Tx) error {
-		values, _ := tx.PrefixScan(bucket, []byte(core.MappingKeyPrefix), 0, 100)
+		values, _ := tx.PrefixScan(provider.bucket, provider.key(core.MappingKeyPrefix), 0, 100)
 		for _, v := range values {
-			mapping, err := core.DecodeMapping(v)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			mapping, err := provider.mapper.Decode(v)
 			if err == nil {
 				for _, v := range mapping.GetMapping() {
 					keys = append(keys, v.GetRealKey())
@@ -232,15 +381,26 @@ func (provider *Nuts) ListKeys() []string {
 
 // MapKeys method returns the map of existing keys.
 func (provider *Nuts) MapKeys(prefix string) map[string]string {
+	if provider.IsClose() {
+		return map[string]string{}
+	}
+
 	keys := map[string]string{}
-	bytePrefix := []byte(prefix)
+	bytePrefix := provider.key(prefix)
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
 
 	err := provider.View(func(tx *nutsdb.Tx) error {
-		nKeys, values, _ := tx.GetAll(bucket)
+		nKeys, values, _ := tx.GetAll(provider.bucket)
 		for iteration, v := range values {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			k := nKeys[iteration]
-			if bytes.HasPrefix(k, bytePrefix) {
-				nk, _ := strings.CutPrefix(string(k), prefix)
+			if bytes.HasPrefix(k, bytePrefix) && core.ListedKey(prefix, strings.TrimPrefix(string(k), provider.namespace)) {
+				nk, _ := strings.CutPrefix(string(k), provider.namespace+prefix)
 				keys[nk] = string(v)
 			}
 		}
@@ -254,26 +414,260 @@ func (provider *Nuts) MapKeys(prefix string) map[string]string {
 	return keys
 }
 
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Nuts) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	if provider.IsClose() {
+		return []string{}, ""
+	}
+
+	keys = []string{}
+	start := max(prefix, cursor)
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	_ = provider.View(func(tx *nutsdb.Tx) error {
+		nKeys, err := tx.GetKeys(provider.bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range nKeys {
+			key, found := strings.CutPrefix(string(k), provider.namespace)
+			if !found || key < start || !strings.HasPrefix(key, prefix) || !core.ListedKey(prefix, key) {
+				continue
+			}
+
+			// The cursor lets the caller resume the interrupted scan.
+			if (limit > 0 && len(keys) == limit) || ctx.Err() != nil {
+				next = key
+
+				break
+			}
+
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix sorted after collecting them.
+func (provider *Nuts) SortedKeys(prefix string) []string {
+	if provider.IsClose() {
+		return []string{}
+	}
+
+	keys := []string{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	_ = provider.View(func(tx *nutsdb.Tx) error {
+		nKeys, err := tx.GetKeys(provider.bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range nKeys {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			key, found := strings.CutPrefix(string(k), provider.namespace)
+			if found && strings.HasPrefix(key, prefix) && core.ListedKey(prefix, key) {
+				keys = append(keys, key)
+			}
+		}
+
+		return nil
+	})
+
+	slices.Sort(keys)
+
+	return keys
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Nuts) Get(key string) []byte {
+	item, _ := provider.GetContext(context.Background(), key)
+
+	return item
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+// The value is served from memory when the read cache holds it, see core.CacheProvider.ReadCacheSize.
+func (provider *Nuts) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if provider.IsClose() {
+		return nil, core.ErrClosed
+	}
+
+	if item, found := provider.reads.get(key); found {
+		return item, nil
+	}
+
 	var item []byte
 
-	_ = provider.View(func(tx *nutsdb.Tx) error {
-		v, e := tx.Get(bucket, []byte(key))
+	ttl := int64(-1)
+	generation := provider.reads.generation()
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v, e := tx.Get(provider.bucket, provider.key(key))
 		if v != nil {
 			item = v
 		}
 
+		if e == nil && provider.reads != nil {
+			ttl, e = tx.GetTTL(provider.bucket, provider.key(key))
+		}
+
 		return e
 	})
+	if err != nil {
+		if errors.Is(err, nutsdb.ErrKeyNotFound) || errors.Is(err, nutsdb.ErrBucketNotFound) {
+			return nil, core.ErrKeyNotFound
+		}
 
-	return item
+		return nil, err
+	}
+
+	// The remaining seconds are rounded down so the entry never outlives the key.
+	switch {
+	case ttl < 0:
+		provider.reads.set(key, item, time.Time{}, generation)
+	case ttl > 0:
+		provider.reads.set(key, item, time.Now().Add(time.Duration(ttl)*time.Second), generation)
+	}
+
+	return item, nil
+}
+
+// ReadCacheHits method returns the number of reads served from memory by the read cache, zero when disabled.
+func (provider *Nuts) ReadCacheHits() uint64 {
+	if provider.reads == nil {
+		return 0
+	}
+
+	return provider.reads.hits.Load()
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Nuts) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetInto method calls fn with the value of the key within the read transaction, see core.ValueReader.
+// The value is the in-memory record of Nuts when the values are kept in RAM so it must not be retained either.
+func (provider *Nuts) GetInto(key string, fn func([]byte) error) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	var valueErr error
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		value, err := tx.Get(provider.bucket, provider.key(key))
+		if err != nil {
+			return err
+		}
+
+		valueErr = fn(value)
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, nutsdb.ErrKeyNotFound) || errors.Is(err, nutsdb.ErrBucketNotFound) {
+			return core.ErrKeyNotFound
+		}
+
+		return err
+	}
+
+	return valueErr
+}
+
+// GetMany method returns the values of the existing keys using a single read transaction.
+func (provider *Nuts) GetMany(keys []string) map[string][]byte {
+	if provider.IsClose() {
+		return map[string][]byte{}
+	}
+
+	result := make(map[string][]byte, len(keys))
+
+	_ = provider.View(func(tx *nutsdb.Tx) error {
+		for _, key := range keys {
+			if v, e := tx.Get(provider.bucket, provider.key(key)); e == nil && v != nil {
+				result[key] = v
+			}
+		}
+
+		return nil
+	})
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Nuts) GetTTL(key string) (time.Duration, bool) {
+	if provider.IsClose() {
+		return 0, false
+	}
+
+	var ttl int64
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		var e error
+		ttl, e = tx.GetTTL(provider.bucket, provider.key(key))
+
+		return e
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	if ttl < 0 {
+		return core.NoExpiration, true
+	}
+
+	return time.Duration(ttl) * time.Second, true
+}
+
+// Exists method will check the key in Nuts provider using the record metadata.
+func (provider *Nuts) Exists(key string) bool {
+	if provider.IsClose() {
+		return false
+	}
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		_, e := tx.GetTTL(provider.bucket, provider.key(key))
+
+		return e
+	})
+
+	return err == nil
 }
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Nuts) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Nuts) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	if provider.IsClose() {
+		return nil, nil, match
+	}
+
 	_ = provider.View(func(tx *nutsdb.Tx) error {
-		value, err := tx.Get(bucket, []byte(core.MappingKeyPrefix+key))
+		value, err := tx.Get(provider.bucket, provider.key(core.MappingKeyPrefix+key))
 		if err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) {
 			return err
 		}
@@ -283,7 +677,7 @@ func (provider *Nuts) GetMultiLevel(key string, req *http.Request, validator *co
 			val = value
 		}
 
-		fresh, stale, err = core.MappingElection(provider, val, req, validator, provider.logger)
+		fresh, stale, match, err = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
 
 		return err
 	})
@@ -292,32 +686,41 @@ func (provider *Nuts) GetMultiLevel(key string, req *http.Request, validator *co
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+// A non-positive duration deletes the existing varied key and leaves the mapping untouched.
 func (provider *Nuts) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+		return nil
+	}
 
-		provider.logger.Errorf("Impossible to compress the key %s into Nuts, %v", variedKey, err)
+	store, ttl := core.NormalizeTTL(duration + provider.stale)
+	if !store {
+		provider.Delete(variedKey)
 
-		return err
+		return nil
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Nuts, %v", variedKey, err)
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Nuts, %v", variedKey, err)
 
 		return err
 	}
 
 	_ = provider.Update(func(tx *nutsdb.Tx) error {
-		return tx.NewBucket(nutsdb.DataStructureBTree, bucket)
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
 	})
 
-	err := provider.Update(func(tx *nutsdb.Tx) error {
-		e := tx.Put(bucket, []byte(variedKey), compressed.Bytes(), uint32((duration + provider.stale).Seconds()))
+	err = provider.Update(func(tx *nutsdb.Tx) error {
+		e := provider.put(tx, variedKey, compressed, uint32(ttl.Seconds()))
 		if e != nil {
 			provider.logger.Errorf("Impossible to set the key %s into Nuts, %v", variedKey, e)
 		}
@@ -330,7 +733,7 @@ func (provider *Nuts) SetMultiLevel(baseKey, variedKey string, value []byte, var
 
 	err = provider.Update(func(ntx *nutsdb.Tx) error {
 		mappingKey := core.MappingKeyPrefix + baseKey
-		item, err := ntx.Get(bucket, []byte(mappingKey))
+		item, err := ntx.Get(provider.bucket, provider.key(mappingKey))
 
 		if err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) {
 			provider.logger.Errorf("Impossible to get the base key %s in Nuts, %v", baseKey, err)
@@ -343,77 +746,856 @@ func (provider *Nuts) SetMultiLevel(baseKey, variedKey string, value []byte, var
 			val = item
 		}
 
-		val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+		val, err = core.MappingUpdaterWith(provider.mapper, variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 		if err != nil {
 			return err
 		}
 
 		provider.logger.Debugf("Store the new mapping for the key %s in Nuts", variedKey)
 
-		return ntx.Put(bucket, []byte(mappingKey), val, nutsdb.Persistent)
+		return ntx.Put(provider.bucket, provider.key(mappingKey), val, nutsdb.Persistent)
 	})
 	if err != nil {
 		provider.logger.Errorf("Impossible to set value into Nuts, %v", err)
+
+		return err
+	}
+
+	provider.reads.evict(variedKey, core.MappingKeyPrefix+baseKey)
+	provider.watchers.Publish(core.EventSet, variedKey)
+	provider.watchers.Publish(core.EventSet, core.MappingKeyPrefix+baseKey)
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Nuts, %v", variedKey, err)
 	}
 
 	return err
 }
 
-// Set method will store the response in Nuts provider.
+// Set method will store the response in Nuts provider. The write is committed when it returns, a
+// subsequent Get sees the value without waiting.
 func (provider *Nuts) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response in Nuts provider unless the context is done before the commit.
+// A non-positive duration deletes the existing key instead.
+func (provider *Nuts) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		provider.Delete(key)
+
+		return nil
+	}
+
 	_ = provider.Update(func(tx *nutsdb.Tx) error {
-		return tx.NewBucket(nutsdb.DataStructureBTree, bucket)
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
 	})
 
 	err := provider.Update(func(tx *nutsdb.Tx) error {
-		return tx.Put(bucket, []byte(key), value, uint32(duration.Seconds()))
+		if err := provider.put(tx, key, value, uint32(ttl.Seconds())); err != nil {
+			return err
+		}
+
+		return ctx.Err()
 	})
 	if err != nil {
 		provider.logger.Errorf("Impossible to set value into Nuts, %v", err)
+
+		return err
 	}
 
-	return err
-}
+	provider.reads.evict(key)
+	provider.watchers.Publish(core.EventSet, key)
 
-// Delete method will delete the response in Nuts provider if exists corresponding to key param.
-func (provider *Nuts) Delete(key string) {
-	_ = provider.Update(func(tx *nutsdb.Tx) error {
-		return tx.Delete(bucket, []byte(key))
-	})
+	return nil
 }
 
-// DeleteMany method will delete the responses in Nuts provider if exists corresponding to the regex key param.
-func (provider *Nuts) DeleteMany(key string) {
-	rgKey, err := regexp.Compile(key)
-	if err != nil {
-		provider.logger.Errorf("The key %s is not a valid regexp: %v", key, err)
-
-		return
+// SetMany method will store the entries in Nuts provider using a single transaction.
+// The transaction is all-or-nothing, none of the entries are stored if one fails.
+func (provider *Nuts) SetMany(items map[string]core.Entry) error {
+	if provider.IsClose() {
+		return core.ErrClosed
 	}
 
-	_ = provider.Update(func(ntx *nutsdb.Tx) error {
-		if entries, err := ntx.GetKeys(bucket); err != nil {
-			return err
-		} else {
-			for _, entry := range entries {
-				if rgKey.Match(entry) {
-					_ = ntx.Delete(bucket, entry)
-				}
+	_ = provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
+	})
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		for key, item := range items {
+			if err := provider.put(tx, key, item.Value, uint32(item.Duration.Seconds())); err != nil {
+				return err
 			}
 		}
 
 		return nil
 	})
-}
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into Nuts, %v", err)
+
+		return err
+	}
+
+	provider.reads.evict(slices.Collect(maps.Keys(items))...)
+
+	for key := range items {
+		provider.watchers.Publish(core.EventSet, key)
+	}
 
-// Init method will.
-func (provider *Nuts) Init() error {
 	return nil
 }
 
-// Reset method will reset or close provider.
-func (provider *Nuts) Reset() error {
-	return provider.Update(func(tx *nutsdb.Tx) error {
-		return tx.DeleteBucket(1, bucket)
+// SetNX method will store the response in Nuts provider only if the key doesn't exist yet.
+func (provider *Nuts) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if provider.IsClose() {
+		return false, core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		return false, nil
+	}
+
+	_ = provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
 	})
+
+	created := false
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		if _, err := tx.Get(provider.bucket, provider.key(key)); !errors.Is(err, nutsdb.ErrKeyNotFound) {
+			return err
+		}
+
+		created = true
+
+		return provider.put(tx, key, value, uint32(ttl.Seconds()))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Nuts, %v", key, err)
+
+		return false, err
+	}
+
+	if created {
+		provider.reads.evict(key)
+		provider.watchers.Publish(core.EventSet, key)
+	}
+
+	return created, nil
+}
+
+// CompareAndSwap method will store the response in Nuts provider only if the stored bytes equal old, within
+// a single write transaction.
+func (provider *Nuts) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if provider.IsClose() {
+		return false, core.ErrClosed
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		return false, nil
+	}
+
+	_ = provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
+	})
+
+	swapped := false
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		current, err := tx.Get(provider.bucket, provider.key(key))
+		if err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) {
+			return err
+		}
+
+		if !core.SwapMatches(current, old) {
+			return nil
+		}
+
+		swapped = true
+
+		return provider.put(tx, key, value, uint32(ttl.Seconds()))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Nuts, %v", key, err)
+
+		return false, err
+	}
+
+	if swapped {
+		provider.reads.evict(key)
+		provider.watchers.Publish(core.EventSet, key)
+	}
+
+	return swapped, nil
+}
+
+// Increment method will add delta to the counter stored in Nuts provider within a single write transaction.
+func (provider *Nuts) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if provider.IsClose() {
+		return 0, core.ErrClosed
+	}
+
+	_ = provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
+	})
+
+	ttl := nutsdb.Persistent
+	if duration > 0 {
+		ttl = uint32(duration.Seconds())
+	}
+
+	var value int64
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		current, err := tx.Get(provider.bucket, provider.key(key))
+		if err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) {
+			return err
+		}
+
+		var encoded []byte
+		if value, encoded, err = core.AddCounter(current, delta); err != nil {
+			return err
+		}
+
+		return provider.put(tx, key, encoded, ttl)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into Nuts, %v", key, err)
+
+		return 0, err
+	}
+
+	provider.reads.evict(key)
+	provider.watchers.Publish(core.EventSet, key)
+
+	return value, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Nuts provider.
+func (provider *Nuts) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Nuts provider.
+// The compressed value is copied because Nuts may keep a reference to the stored bytes.
+func (provider *Nuts) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, bytes.Clone(compressed), duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Nuts, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Nuts) GetStream(key string) (io.ReadCloser, error) {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Nuts) Touch(key string, duration time.Duration) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		value, err := tx.Get(provider.bucket, provider.key(key))
+		if err != nil {
+			if errors.Is(err, nutsdb.ErrKeyNotFound) || errors.Is(err, nutsdb.ErrBucketNotFound) {
+				return core.ErrKeyNotFound
+			}
+
+			return err
+		}
+
+		if record, err := tx.Get(provider.bucket, provider.key(core.EntryMetaKeyPrefix+key)); err == nil {
+			if err = tx.Put(provider.bucket, provider.key(core.EntryMetaKeyPrefix+key), record, uint32(duration.Seconds())); err != nil {
+				return err
+			}
+		}
+
+		return provider.put(tx, key, value, uint32(duration.Seconds()))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Nuts, %v", key, err)
+
+		return err
+	}
+
+	provider.reads.evict(key, core.EntryMetaKeyPrefix+key)
+
+	return nil
+}
+
+// SetWithMeta method will store the response and its metadata under a parallel key in Nuts provider using a single transaction.
+// The metadata is kept by a later plain Set of the key until it's deleted or expires.
+func (provider *Nuts) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	record, err := core.EncodeEntryMeta(meta)
+	if err != nil {
+		return err
+	}
+
+	store, ttl := core.NormalizeTTL(duration)
+	if !store {
+		provider.Delete(key)
+
+		return nil
+	}
+
+	_ = provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.NewBucket(nutsdb.DataStructureBTree, provider.bucket)
+	})
+
+	err = provider.Update(func(tx *nutsdb.Tx) error {
+		if len(meta) == 0 {
+			if err := provider.deleteEntryMeta(tx, key); err != nil {
+				return err
+			}
+		} else if err := tx.Put(provider.bucket, provider.key(core.EntryMetaKeyPrefix+key), record, uint32(ttl.Seconds())); err != nil {
+			return err
+		}
+
+		return provider.put(tx, key, value, uint32(ttl.Seconds()))
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value with its metadata into Nuts, %v", err)
+
+		return err
+	}
+
+	provider.reads.evict(key, core.EntryMetaKeyPrefix+key)
+	provider.watchers.Publish(core.EventSet, key)
+
+	return nil
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta without reading the value, core.ErrKeyNotFound if none.
+func (provider *Nuts) GetMeta(key string) (map[string]string, error) {
+	record, err := provider.GetWithError(core.EntryMetaKeyPrefix + key)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.DecodeEntryMeta(record)
+}
+
+func (provider *Nuts) deleteEntryMeta(tx *nutsdb.Tx, key string) error {
+	err := tx.Delete(provider.bucket, provider.key(core.EntryMetaKeyPrefix+key))
+	if errors.Is(err, nutsdb.ErrKeyNotFound) || errors.Is(err, nutsdb.ErrNotFoundBucket) {
+		return nil
+	}
+
+	return err
+}
+
+// Delete method will delete the response in Nuts provider if exists corresponding to key param.
+func (provider *Nuts) Delete(key string) {
+	if provider.IsClose() {
+		return
+	}
+
+	core.UnindexSurrogateKeys(provider, key)
+
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	existed := false
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		if provider.notifier != nil {
+			_, err := tx.Get(provider.bucket, provider.key(key))
+			existed = err == nil
+		}
+
+		if err := tx.Delete(provider.bucket, provider.key(key)); err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) {
+			return err
+		}
+
+		if err := provider.deleteEntryMeta(tx, key); err != nil {
+			return err
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return
+	}
+
+	provider.expiries.forget(key)
+	provider.reads.evict(key, core.EntryMetaKeyPrefix+key)
+	provider.watchers.Publish(core.EventDelete, key)
+
+	if existed {
+		provider.notifier.Notify(key, core.EvictDeleted)
+	}
+}
+
+// DeleteMany method will delete the responses in Nuts provider if exists corresponding to the prefix pattern param.
+// The keys are deleted in a single transaction.
+func (provider *Nuts) DeleteMany(pattern string) {
+	if provider.IsClose() {
+		return
+	}
+
+	prefix := provider.key(core.KeyPrefix(pattern))
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	deleted := []string{}
+
+	err := provider.Update(func(ntx *nutsdb.Tx) error {
+		entries, err := ntx.GetKeys(provider.bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if bytes.HasPrefix(entry, prefix) {
+				if err = ntx.Delete(provider.bucket, entry); err != nil {
+					return err
+				}
+
+				deleted = append(deleted, strings.TrimPrefix(string(entry), provider.namespace))
+			}
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in Nuts, %v", pattern, err)
+
+		return
+	}
+
+	provider.expiries.forget(deleted...)
+	provider.reads.evict(deleted...)
+
+	for _, key := range deleted {
+		provider.watchers.Publish(core.EventDelete, key)
+		provider.notifier.Notify(key, core.EvictDeleted)
+	}
+}
+
+// DeleteKeys method will delete the keys with their metadata in Nuts provider using a single transaction.
+// The missing keys are ignored.
+func (provider *Nuts) DeleteKeys(keys []string) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+	existing := map[string]bool{}
+	ctx, cancel := provider.operationContext()
+
+	defer cancel()
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		if provider.notifier != nil {
+			for _, key := range keys {
+				_, err := tx.Get(provider.bucket, provider.key(key))
+				existing[key] = err == nil
+			}
+		}
+
+		for _, key := range deleted {
+			err := tx.Delete(provider.bucket, provider.key(key))
+			if err != nil && !errors.Is(err, nutsdb.ErrKeyNotFound) && !errors.Is(err, nutsdb.ErrNotFoundBucket) &&
+				!errors.Is(err, nutsdb.ErrBucketNotFound) {
+				return err
+			}
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in Nuts, %v", err)
+
+		return err
+	}
+
+	provider.expiries.forget(keys...)
+	provider.reads.evict(deleted...)
+
+	for _, key := range keys {
+		provider.watchers.Publish(core.EventDelete, key)
+
+		if existing[key] {
+			provider.notifier.Notify(key, core.EvictDeleted)
+		}
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Nuts provider.
+func (provider *Nuts) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Nuts provider.
+func (provider *Nuts) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Nuts provider.
+func (provider *Nuts) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Nuts provider without altering it.
+func (provider *Nuts) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// Stats method returns the number of keys read from the in-memory index and the size of the data files on disk.
+// The size is the shared database one when the provider is namespaced.
+func (provider *Nuts) Stats() (core.StorageStats, error) {
+	if provider.IsClose() {
+		return core.StorageStats{}, core.ErrClosed
+	}
+
+	stats := core.StorageStats{}
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		entries, err := tx.GetKeys(provider.bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if bytes.HasPrefix(entry, provider.key("")) {
+				stats.KeyCount++
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, nutsdb.ErrBucketNotFound) {
+		provider.logger.Errorf("Impossible to count the keys in Nuts, %v", err)
+
+		return stats, err
+	}
+
+	files, err := os.ReadDir(provider.dir)
+	if err != nil {
+		provider.logger.Errorf("Impossible to read the Nuts directory %s, %v", provider.dir, err)
+
+		return stats, err
+	}
+
+	for _, file := range files {
+		if info, e := file.Info(); e == nil && !info.IsDir() {
+			stats.ApproxSizeBytes += info.Size()
+		}
+	}
+
+	return stats, nil
+}
+
+// Ping method will check the Nuts DB is open using a no-op read transaction.
+func (provider *Nuts) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	return provider.View(func(*nutsdb.Tx) error { return nil })
+}
+
+// Export method will stream every key of the Nuts provider scanning the bucket.
+func (provider *Nuts) Export(w io.Writer) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	exporter := core.NewExportWriter(w)
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		nKeys, values, _ := tx.GetAll(provider.bucket)
+		for iteration, v := range values {
+			key, found := strings.CutPrefix(string(nKeys[iteration]), provider.namespace)
+			if !found {
+				continue
+			}
+
+			ttl, err := tx.GetTTL(provider.bucket, nKeys[iteration])
+			if err != nil {
+				continue
+			}
+
+			duration := core.NoExpiration
+			if ttl >= 0 {
+				duration = time.Duration(ttl) * time.Second
+			}
+
+			if err = exporter.Write(key, v, duration); err != nil {
+				return err
+			}
+		}
+
+		return exporter.Flush()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to export the Nuts keys, %v", err)
+	}
+
+	return err
+}
+
+// DumpMeta method will write the key, value size and remaining time to live of every key as JSON lines.
+// Nuts doesn't expose the value size of its index records so the length is read from the values kept
+// in memory by the default index mode, the size is the stored one so compressed when enabled.
+func (provider *Nuts) DumpMeta(w io.Writer) error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	dumper := core.NewMetaWriter(w)
+
+	err := provider.View(func(tx *nutsdb.Tx) error {
+		nKeys, err := tx.GetKeys(provider.bucket)
+		if err != nil && !errors.Is(err, nutsdb.ErrBucketNotFound) {
+			return err
+		}
+
+		for _, nKey := range nKeys {
+			key, found := strings.CutPrefix(string(nKey), provider.namespace)
+			if !found {
+				continue
+			}
+
+			ttl, err := tx.GetTTL(provider.bucket, nKey)
+			if err != nil {
+				continue
+			}
+
+			size, err := tx.ValueLen(provider.bucket, nKey)
+			if err != nil {
+				continue
+			}
+
+			duration := core.NoExpiration
+			if ttl >= 0 {
+				duration = time.Duration(ttl) * time.Second
+			}
+
+			if err = dumper.Write(key, int64(size), duration); err != nil {
+				return err
+			}
+		}
+
+		return dumper.Flush()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to dump the Nuts keys metadata, %v", err)
+	}
+
+	return err
+}
+
+// Watch method will stream the changes made through this instance to the keys starting with the prefix, see
+// core.Watcher. The expiries and Reset are not reported.
+func (provider *Nuts) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	return provider.watchers.Watch(ctx, prefix)
+}
+
+// Iterate method will stream every entry of the Nuts provider, see core.Iterator. The keys are listed
+// first then each value is read lazily by its own transaction so a slow consumer doesn't hold the lock
+// blocking the writes, a key deleted or expired meanwhile is skipped.
+func (provider *Nuts) Iterate(ctx context.Context) (<-chan core.KV, <-chan error) {
+	return core.IterateWith(ctx, func(yield func(core.KV) error) error {
+		if provider.IsClose() {
+			return core.ErrClosed
+		}
+
+		var nKeys [][]byte
+
+		err := provider.View(func(tx *nutsdb.Tx) (err error) {
+			nKeys, err = tx.GetKeys(provider.bucket)
+
+			return err
+		})
+		if err != nil && !errors.Is(err, nutsdb.ErrBucketNotFound) {
+			provider.logger.Errorf("Impossible to iterate over the Nuts keys, %v", err)
+
+			return err
+		}
+
+		for _, nKey := range nKeys {
+			key, found := strings.CutPrefix(string(nKey), provider.namespace)
+			if !found {
+				continue
+			}
+
+			var value []byte
+
+			err = provider.GetInto(key, func(v []byte) error {
+				value = bytes.Clone(v)
+
+				return nil
+			})
+			if errors.Is(err, core.ErrKeyNotFound) {
+				continue
+			}
+
+			if err != nil {
+				provider.logger.Errorf("Impossible to iterate over the Nuts keys, %v", err)
+
+				return err
+			}
+
+			if err = yield(core.KV{Key: key, Value: value}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Import method will store the exported records in Nuts provider by batches.
+func (provider *Nuts) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Nuts) Init() error {
+	return nil
+}
+
+// Reset method will delete every key of the bucket, the next writes recreate it.
+// A namespaced provider only deletes its own keys.
+func (provider *Nuts) Reset() error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	provider.expiries.reset()
+	provider.reads.clear()
+
+	if provider.namespace != "" {
+		return provider.Update(func(tx *nutsdb.Tx) error {
+			entries, err := tx.GetKeys(provider.bucket)
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				if bytes.HasPrefix(entry, provider.key("")) {
+					if err = tx.Delete(provider.bucket, entry); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return provider.Update(func(tx *nutsdb.Tx) error {
+		return tx.DeleteBucket(nutsdb.DataStructureBTree, provider.bucket)
+	})
+}
+
+// Sweep method will delete the expired entries of the bucket.
+// The expired entries are skipped by the reads and only removed on disk once deleted, the keys read
+// in the read-write transaction record the deletion of the expired ones which is committed atomically
+// with the check so a key written concurrently is never removed. The tracked keys gone once their
+// deadline elapsed are reported to the OnEvict callback.
+func (provider *Nuts) Sweep() error {
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	due := provider.expiries.due(time.Now())
+	expired := []string{}
+
+	err := provider.Update(func(tx *nutsdb.Tx) error {
+		for _, key := range due {
+			if _, err := tx.GetTTL(provider.bucket, provider.key(key)); err != nil {
+				expired = append(expired, key)
+			}
+		}
+
+		_, err := tx.GetKeys(provider.bucket)
+
+		return err
+	})
+	if err != nil && !errors.Is(err, nutsdb.ErrBucketNotFound) {
+		provider.logger.Errorf("Impossible to sweep the expired entries from Nuts, %v", err)
+
+		return err
+	}
+
+	for _, key := range expired {
+		provider.notifier.Notify(key, core.EvictExpired)
+	}
+
+	return nil
+}
+
+// IndexMode method returns the entry index mode the Nuts DB was opened with.
+func (provider *Nuts) IndexMode() nutsdb.EntryIdxMode {
+	mode, _ := nutsIndexModes.Load(provider.DB)
+	indexMode, _ := mode.(nutsdb.EntryIdxMode)
+
+	return indexMode
+}
+
+// Close method will close the Nuts DB, the next Factory call reopens it.
+func (provider *Nuts) Close() error {
+	provider.sweeper.Stop()
+	provider.notifier.Close()
+	provider.watchers.Close()
+
+	if provider.IsClose() {
+		return core.ErrClosed
+	}
+
+	nutsInstanceMap.Range(func(key, value any) bool {
+		if value.(*nutsdb.DB) == provider.DB {
+			nutsInstanceMap.Delete(key)
+		}
+
+		return true
+	})
+	nutsIndexModes.Delete(provider.DB)
+
+	err := provider.DB.Close()
+	if errors.Is(err, nutsdb.ErrDBClosed) {
+		return core.ErrClosed
+	}
+
+	return err
 }
@@ -2,12 +2,29 @@ package nuts_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/darkweak/storages/badger"
 	"github.com/darkweak/storages/core"
 	"github.com/darkweak/storages/nuts"
+	"github.com/nutsdb/nutsdb"
 	"github.com/pierrec/lz4/v4"
 	"go.uber.org/zap"
 )
@@ -42,7 +59,6 @@ func TestIShouldBeAbleToReadAndWriteDataInNuts(t *testing.T) {
 	client, _ := getNutsInstance()
 
 	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	res := client.Get("Test")
 	if len(res) == 0 {
@@ -66,7 +82,6 @@ func TestNuts_GetRequestInCache(t *testing.T) {
 func TestNuts_GetSetRequestInCache_OneByte(t *testing.T) {
 	client, _ := getNutsInstance()
 	_ = client.Set(byteKey, []byte("A"), time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	res := client.Get(byteKey)
 	if len(res) == 0 {
@@ -83,7 +98,6 @@ func TestNuts_SetRequestInCache_TTL(t *testing.T) {
 	client, _ := getNutsInstance()
 	value := []byte("Hello world")
 	_ = client.Set(key, value, time.Duration(20)*time.Second)
-	time.Sleep(1 * time.Second)
 
 	newValue := client.Get(key)
 
@@ -92,10 +106,72 @@ func TestNuts_SetRequestInCache_TTL(t *testing.T) {
 	}
 }
 
+func TestNuts_SetRequestInCache_Negative_TTL(t *testing.T) {
+	client, _ := getNutsInstance()
+	value := []byte("New value")
+
+	if err := client.Set("NegativeTTLNewKey", value, -1); err != nil {
+		t.Errorf("Setting a negative TTL shouldn't return an error: %v", err)
+	}
+
+	if res := client.Get("NegativeTTLNewKey"); len(res) != 0 {
+		t.Errorf("Key NegativeTTLNewKey shouldn't be stored, %s provided", res)
+	}
+
+	_ = client.Set("NegativeTTLKey", value, time.Minute)
+
+	if err := client.Set("NegativeTTLKey", value, -1); err != nil {
+		t.Errorf("Setting a negative TTL shouldn't return an error: %v", err)
+	}
+
+	if _, found := client.GetTTL("NegativeTTLKey"); found {
+		t.Error("The existing key NegativeTTLKey should have been removed")
+	}
+
+	_ = client.SetMultiLevel("NegativeTTLKey", "NegativeTTLKey", value, http.Header{}, "", -1, "NegativeTTLKey")
+
+	if _, found := client.GetTTL(core.MappingKeyPrefix + "NegativeTTLKey"); found {
+		t.Error("The mapping key shouldn't be stored for a negative TTL")
+	}
+}
+
+func TestNuts_SetThenGet(t *testing.T) {
+	client, err := getNutsInstance()
+	if err != nil {
+		t.Fatalf("Impossible to create the Nuts instance: %v", err)
+	}
+
+	for i := range 100 {
+		key := fmt.Sprintf("SyncKey%d", i)
+		value := []byte(fmt.Sprintf("%s %d", baseValue, i))
+
+		if err = client.Set(key, value, time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key %s: %v", key, err)
+		}
+
+		if res := client.Get(key); !bytes.Equal(res, value) {
+			t.Fatalf("The key %s should be readable right after Set, %s provided", key, res)
+		}
+
+		client.Delete(key)
+
+		if res := client.Get(key); res != nil {
+			t.Fatalf("The key %s should be gone right after Delete, %s provided", key, res)
+		}
+	}
+
+	if err = client.SetMany(map[string]core.Entry{"SyncManyKey": {Value: []byte(baseValue), Duration: time.Minute}}); err != nil {
+		t.Fatalf("Impossible to set the key SyncManyKey: %v", err)
+	}
+
+	if res := client.GetMany([]string{"SyncManyKey"}); !bytes.Equal(res["SyncManyKey"], []byte(baseValue)) {
+		t.Errorf("The key SyncManyKey should be readable right after SetMany, %s provided", res["SyncManyKey"])
+	}
+}
+
 func TestNuts_DeleteRequestInCache(t *testing.T) {
 	client, _ := getNutsInstance()
 	client.Delete(byteKey)
-	time.Sleep(1 * time.Second)
 
 	if 0 < len(client.Get(byteKey)) {
 		t.Errorf("Key %s should not exist", byteKey)
@@ -157,3 +233,1758 @@ func TestNuts_SetMultiLevel_LargeValue(t *testing.T) {
 			len(largeValue), len(retrieved), float64(len(retrieved))/float64(len(largeValue))*100)
 	}
 }
+
+func TestNuts_GetMultiLevelDebug(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	for _, encoding := range []string{"gzip", "br"} {
+		variedKey := "DebugKey-" + encoding
+		response := "HTTP/1.1 200 OK\r\nContent-Encoding: " + encoding + "\r\n\r\n" + baseValue
+		headers := http.Header{"Accept-Encoding": []string{encoding}}
+
+		if err := client.SetMultiLevel("DebugKey", variedKey, []byte(response), headers, `"etag-`+encoding+`"`, time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to store the variant %s: %v", variedKey, err)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/debug", nil)
+	req.Header.Set("Accept-Encoding", "br")
+
+	fresh, _, match := client.GetMultiLevelDebug("DebugKey", req, &core.Revalidator{})
+	if fresh == nil || fresh.Header.Get("Content-Encoding") != "br" {
+		t.Fatal("The br variant should be returned as fresh")
+	}
+
+	if match.VariedKey != "DebugKey-br" || match.ETag != `"etag-br"` || match.Stale {
+		t.Errorf("The br variant should be reported as the elected key, %+v provided", match)
+	}
+}
+
+func TestNuts_GetMultiLevelAge(t *testing.T) {
+	client, _ := getNutsInstance()
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 13\r\n\r\n" + baseValue
+
+	if err := client.SetMultiLevel("AgeKey", "AgeKey", []byte(response), http.Header{}, "", time.Minute, "AgeKey"); err != nil {
+		t.Fatalf("Impossible to store the key AgeKey: %v", err)
+	}
+
+	time.Sleep(time.Second)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/age", nil)
+
+	fresh, _, match := client.GetMultiLevelDebug("AgeKey", req, &core.Revalidator{})
+	if fresh == nil {
+		t.Fatal("The response should be fresh")
+	}
+
+	if match.StoredAt.IsZero() || match.Age < time.Second {
+		t.Errorf("The age should be at least 1s, %v provided", match.Age)
+	}
+
+	if match.TTL <= 0 || match.TTL > time.Minute-time.Second {
+		t.Errorf("The remaining TTL should be between 0 and 59s, %v provided", match.TTL)
+	}
+}
+
+func TestNuts_SetMultiLevelVariants(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir(), MaxVariants: 3}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	store := func(language, body string) error {
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+
+		return client.SetMultiLevel("Variants", "Variants-"+language, []byte(response), http.Header{"Accept-Language": []string{language}}, "", time.Minute, "Variants-"+language)
+	}
+
+	elect := func(language string) string {
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/variants", nil)
+		req.Header.Set("Accept-Language", language)
+
+		fresh, _ := client.GetMultiLevel("Variants", req, &core.Revalidator{})
+		if fresh == nil {
+			return ""
+		}
+
+		body, _ := io.ReadAll(fresh.Body)
+
+		return string(body)
+	}
+
+	for _, language := range []string{"en", "fr", "de"} {
+		if err = store(language, "first "+language); err != nil {
+			t.Fatalf("Impossible to store the %s variant: %v", language, err)
+		}
+	}
+
+	for _, language := range []string{"en", "fr", "de"} {
+		if body := elect(language); body != "first "+language {
+			t.Errorf("The %s variant should be retrievable, %s provided", language, body)
+		}
+	}
+
+	if err = store("fr", "second fr"); err != nil {
+		t.Fatalf("Re-storing a variant shouldn't count against the limit: %v", err)
+	}
+
+	if body := elect("fr"); body != "second fr" {
+		t.Errorf("The fr variant should be updated in place, %s provided", body)
+	}
+
+	if body := elect("en"); body != "first en" {
+		t.Errorf("The sibling variants should be kept, %s provided", body)
+	}
+
+	mapping, _ := core.DecodeMapping(client.Get(core.MappingKeyPrefix + "Variants"))
+	if len(mapping.GetMapping()) != 3 {
+		t.Errorf("The mapping should hold 3 variants, %d provided", len(mapping.GetMapping()))
+	}
+
+	if err = store("es", "first es"); !errors.Is(err, core.ErrVariantLimit) {
+		t.Errorf("A fourth variant should return ErrVariantLimit, %v provided", err)
+	}
+}
+
+func TestNuts_GetTTL(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	_ = client.Set("TTLKey", []byte(baseValue), 20*time.Second)
+
+	ttl, found := client.GetTTL("TTLKey")
+	if !found {
+		t.Error("Key TTLKey should exist")
+	}
+
+	if ttl <= 0 || ttl > 20*time.Second {
+		t.Errorf("The TTL should be between 0 and 20s, %v provided", ttl)
+	}
+
+	_ = client.Set("TTLExpiredKey", []byte(baseValue), time.Second)
+	time.Sleep(2 * time.Second)
+
+	if _, found = client.GetTTL("TTLExpiredKey"); found {
+		t.Error("Key TTLExpiredKey should be expired")
+	}
+
+	if _, found = client.GetTTL(nonExistentKey); found {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+
+	_ = client.SetMultiLevel("TTLMappedKey", "TTLMappedKey", []byte(baseValue), http.Header{}, "", time.Minute, "TTLMappedKey")
+
+	if ttl, found = client.GetTTL(core.MappingKeyPrefix + "TTLMappedKey"); !found || ttl != core.NoExpiration {
+		t.Errorf("The mapping key should not expire, %v provided", ttl)
+	}
+}
+
+func TestNuts_Touch(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	_ = client.Set("TouchKey", []byte(baseValue), 5*time.Second)
+
+	if err := client.Touch("TouchKey", time.Minute); err != nil {
+		t.Errorf("Impossible to touch the key TouchKey: %v", err)
+	}
+
+	if res := client.Get("TouchKey"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+
+	if ttl, _ := client.GetTTL("TouchKey"); ttl <= 5*time.Second {
+		t.Errorf("The TTL should have been extended, %v provided", ttl)
+	}
+
+	if err := client.Touch(nonExistentKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestNuts_SetMultiLevel_Compression(t *testing.T) {
+	value := make([]byte, 512*1024)
+	for i := range value {
+		value[i] = byte(i % 256)
+	}
+
+	for _, codec := range []string{core.CompressionLZ4, core.CompressionZstd, core.CompressionNone} {
+		client, err := nuts.Factory(core.CacheProvider{Compression: codec}, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create nuts instance: %v", err)
+		}
+
+		key := "compression_" + codec
+
+		if err = client.SetMultiLevel(key, key, value, http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Failed to set the value using the codec %s: %v", codec, err)
+		}
+
+		decompressed, err := core.Decompress(client.Get(key))
+		if err != nil {
+			t.Fatalf("Failed to decompress the value using the codec %s: %v", codec, err)
+		}
+
+		if !bytes.Equal(value, decompressed) {
+			t.Errorf("The value stored using the codec %s doesn't match the original one", codec)
+		}
+	}
+}
+
+func TestNuts_SetMany(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	items := make(map[string]core.Entry, 100)
+	for i := range 100 {
+		items[fmt.Sprintf("SetManyKey%d", i)] = core.Entry{Value: []byte(fmt.Sprintf("%s %d", baseValue, i)), Duration: time.Minute}
+	}
+
+	if err := client.SetMany(items); err != nil {
+		t.Errorf("Impossible to set many values: %v", err)
+	}
+
+	for key, item := range items {
+		if res := client.Get(key); !bytes.Equal(res, item.Value) {
+			t.Errorf("%s not corresponding to %s for the key %s", string(res), string(item.Value), key)
+		}
+	}
+}
+
+func benchmarkEntries(b *testing.B) map[string]core.Entry {
+	b.Helper()
+
+	items := make(map[string]core.Entry, 10_000)
+	for i := range 10_000 {
+		items[fmt.Sprintf("BenchmarkKey%d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	return items
+}
+
+func BenchmarkNuts_Set(b *testing.B) {
+	client, _ := getNutsInstance()
+	items := benchmarkEntries(b)
+
+	b.ResetTimer()
+
+	for range b.N {
+		for key, item := range items {
+			_ = client.Set(key, item.Value, item.Duration)
+		}
+	}
+}
+
+func BenchmarkNuts_SetMany(b *testing.B) {
+	client, _ := getNutsInstance()
+	items := benchmarkEntries(b)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.SetMany(items)
+	}
+}
+
+func TestNuts_GetMany(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	_ = client.Set("GetManyKey1", []byte(baseValue), time.Minute)
+	_ = client.Set("GetManyKey2", []byte(baseValue), time.Minute)
+
+	res := client.GetMany([]string{"GetManyKey1", nonExistentKey, "GetManyKey2"})
+	if len(res) != 2 {
+		t.Errorf("The result should contain 2 keys, %d provided", len(res))
+	}
+
+	for _, key := range []string{"GetManyKey1", "GetManyKey2"} {
+		if string(res[key]) != baseValue {
+			t.Errorf("%s not corresponding to %s for the key %s", string(res[key]), baseValue, key)
+		}
+	}
+
+	if _, found := res[nonExistentKey]; found {
+		t.Errorf("The key %s should be absent from the result", nonExistentKey)
+	}
+}
+
+func BenchmarkNuts_Get(b *testing.B) {
+	client, _ := getNutsInstance()
+	items := benchmarkEntries(b)
+	_ = client.SetMany(items)
+
+	b.ResetTimer()
+
+	for range b.N {
+		for key := range items {
+			_ = client.Get(key)
+		}
+	}
+}
+
+func BenchmarkNuts_GetMany(b *testing.B) {
+	client, _ := getNutsInstance()
+	items := benchmarkEntries(b)
+	_ = client.SetMany(items)
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.GetMany(keys)
+	}
+}
+
+func BenchmarkNuts_GetLargeValue(b *testing.B) {
+	client, _ := getNutsInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.Get("BenchmarkLargeKey")
+	}
+}
+
+func BenchmarkNuts_ExistsLargeValue(b *testing.B) {
+	client, _ := getNutsInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = client.Exists("BenchmarkLargeKey")
+	}
+}
+
+func TestNuts_ContextCancelled(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := client.SetContext(ctx, "CancelledKey", []byte(baseValue), time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Setting with a cancelled context should return context.Canceled, %v provided", err)
+	}
+
+	if res := client.Get("CancelledKey"); res != nil {
+		t.Errorf("The key CancelledKey should not be persisted, %s provided", string(res))
+	}
+
+	_ = client.Set("ContextKey", []byte(baseValue), time.Minute)
+
+	if _, err := client.GetContext(ctx, "ContextKey"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Getting with a cancelled context should return context.Canceled, %v provided", err)
+	}
+
+	res, err := client.GetContext(context.Background(), "ContextKey")
+	if err != nil || string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s, %v", string(res), baseValue, err)
+	}
+
+	if _, err = client.GetContext(context.Background(), nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Getting the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestNuts_ScanKeys(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	items := make(map[string]core.Entry, 1000)
+	for i := range 1000 {
+		items[fmt.Sprintf("ScanKey%04d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	_ = client.SetMany(items)
+	_ = client.Set("ScanOtherKey", []byte(baseValue), time.Minute)
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("The pagination should have ended after 10 pages")
+		}
+
+		keys, next := client.ScanKeys("ScanKey", cursor, 100)
+		if len(keys) > 100 {
+			t.Errorf("The page should contain at most 100 keys, %d provided", len(keys))
+		}
+
+		for _, key := range keys {
+			if _, ok := items[key]; !ok {
+				t.Errorf("The key %s should not be part of the scan", key)
+			}
+
+			if seen[key] {
+				t.Errorf("The key %s has already been returned", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != len(items) {
+		t.Errorf("The scan should return %d keys, %d provided", len(items), len(seen))
+	}
+}
+
+func TestNuts_SortedKeys(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	// Inserted in reverse order so the sort doesn't come from the writes.
+	expected := []string{}
+	for i := 99; i >= 0; i-- {
+		key := fmt.Sprintf("SortedKey%02d", i)
+		expected = append(expected, key)
+
+		_ = client.Set(key, []byte(baseValue), time.Minute)
+	}
+
+	_ = client.Set("SortedOtherKey", []byte(baseValue), time.Minute)
+	_ = client.Set(core.EntryMetaKeyPrefix+"SortedKey00", []byte(baseValue), time.Minute)
+
+	keys := client.SortedKeys("SortedKey")
+	if !slices.IsSorted(keys) {
+		t.Errorf("The keys should be sorted, %v provided", keys)
+	}
+
+	slices.Sort(expected)
+
+	if !slices.Equal(keys, expected) {
+		t.Errorf("The %d keys prefixed by SortedKey should be returned exactly, %d provided", len(expected), len(keys))
+	}
+}
+
+func TestNuts_DeleteMany(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	for i := range 10 {
+		_ = client.Set(fmt.Sprintf("SURROGATE_first_%d", i), []byte(baseValue), time.Minute)
+		_ = client.Set(fmt.Sprintf("SURROGATE_second_%d", i), []byte(baseValue), time.Minute)
+	}
+
+	client.DeleteMany("SURROGATE_first_*")
+
+	if keys, _ := client.ScanKeys("SURROGATE_first_", "", 0); len(keys) != 0 {
+		t.Errorf("The keys %v should have been deleted", keys)
+	}
+
+	if keys, _ := client.ScanKeys("SURROGATE_second_", "", 0); len(keys) != 10 {
+		t.Errorf("The 10 keys with the other prefix should be kept, %d provided", len(keys))
+	}
+
+	client.DeleteMany("SURROGATE_second_")
+
+	if keys, _ := client.ScanKeys("SURROGATE_second_", "", 0); len(keys) != 0 {
+		t.Errorf("The keys %v should have been deleted", keys)
+	}
+}
+
+type countingMetrics struct {
+	hits, misses, errors int
+}
+
+func (m *countingMetrics) IncHit(string) { m.hits++ }
+
+func (m *countingMetrics) IncMiss(string) { m.misses++ }
+
+func (m *countingMetrics) IncError(string, string) { m.errors++ }
+
+func TestNuts_Metrics(t *testing.T) {
+	metrics := &countingMetrics{}
+
+	client, err := nuts.Factory(core.CacheProvider{Metrics: metrics}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create nuts instance: %v", err)
+	}
+
+	_ = client.Set("MetricsKey", []byte(baseValue), time.Minute)
+	_ = client.Get("MetricsKey")
+	_ = client.Get(nonExistentKey)
+
+	if metrics.hits != 1 || metrics.misses != 1 || metrics.errors != 0 {
+		t.Errorf("Expected 1 hit and 1 miss, %+v provided", *metrics)
+	}
+}
+
+func TestNuts_SetGetStream(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	const size = 20 * 1024 * 1024
+
+	written := sha256.New()
+	source := io.TeeReader(io.LimitReader(rand.New(rand.NewSource(1)), size), written)
+
+	if err := client.SetStream("StreamKey", source, time.Minute); err != nil {
+		t.Fatalf("Impossible to stream the value: %v", err)
+	}
+
+	reader, err := client.GetStream("StreamKey")
+	if err != nil {
+		t.Fatalf("Impossible to get the stream: %v", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	read := sha256.New()
+
+	n, err := io.Copy(read, reader)
+	if err != nil {
+		t.Fatalf("Impossible to read the stream: %v", err)
+	}
+
+	if n != size || !bytes.Equal(written.Sum(nil), read.Sum(nil)) {
+		t.Errorf("The streamed value doesn't match the original one, %d bytes read", n)
+	}
+
+	if _, err = client.GetStream(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Streaming the key %s should return ErrKeyNotFound, %v provided", nonExistentKey, err)
+	}
+}
+
+func TestNuts_Close(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	client, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create nuts instance: %v", err)
+	}
+
+	_ = client.Set("CloseKey", []byte(baseValue), time.Minute)
+
+	if err = client.Close(); err != nil {
+		t.Fatalf("Impossible to close the nuts instance: %v", err)
+	}
+
+	if _, err = client.GetContext(context.Background(), "CloseKey"); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Getting a key after Close should return ErrClosed, %v provided", err)
+	}
+
+	if res := client.Get("CloseKey"); res != nil {
+		t.Errorf("Getting a key after Close should return nil, %s provided", res)
+	}
+
+	if err = client.Set("CloseKey", []byte(baseValue), time.Minute); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Setting a key after Close should return ErrClosed, %v provided", err)
+	}
+
+	if err = client.Close(); !errors.Is(err, core.ErrClosed) {
+		t.Errorf("Closing twice should return ErrClosed, %v provided", err)
+	}
+
+	client, err = nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if res := client.Get("CloseKey"); string(res) != baseValue {
+		t.Errorf("The reopened instance should contain the key CloseKey, %s provided", res)
+	}
+}
+
+func TestNuts_SetNX(t *testing.T) {
+	client, _ := getNutsInstance()
+	client.Delete("SetNXKey")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := client.SetNX("SetNXKey", []byte(fmt.Sprintf("worker %d", i)), time.Minute)
+			if err != nil {
+				t.Errorf("Impossible to set the key SetNXKey if not exists: %v", err)
+			}
+
+			if ok {
+				created.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", created.Load())
+	}
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); ok {
+		t.Error("The existing key SetNXKey shouldn't be overridden")
+	}
+
+	client.Delete("SetNXKey")
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); !ok {
+		t.Error("The deleted key SetNXKey should be created again")
+	}
+}
+
+func TestNuts_CompareAndSwap(t *testing.T) {
+	client, _ := getNutsInstance()
+	client.Delete("CASKey")
+
+	var (
+		wg      sync.WaitGroup
+		swapped atomic.Int32
+	)
+
+	contend := func(old []byte, prefix string) {
+		swapped.Store(0)
+
+		for i := range 50 {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				ok, err := client.CompareAndSwap("CASKey", old, []byte(fmt.Sprintf("%s %d", prefix, i)), time.Minute)
+				if err != nil {
+					t.Errorf("Impossible to compare and swap the key CASKey: %v", err)
+				}
+
+				if ok {
+					swapped.Add(1)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	}
+
+	contend(nil, "created")
+
+	if swapped.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", swapped.Load())
+	}
+
+	contend(client.Get("CASKey"), "swapped")
+
+	if swapped.Load() != 1 {
+		t.Errorf("Exactly one worker should have swapped the value it read, %d provided", swapped.Load())
+	}
+
+	_ = client.Set("CASCounterKey", core.EncodeCounter(0), time.Minute)
+	swapped.Store(0)
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for done := 0; done < 5; {
+				current := client.Get("CASCounterKey")
+				value, _ := core.DecodeCounter(current)
+
+				ok, err := client.CompareAndSwap("CASCounterKey", current, core.EncodeCounter(value+1), time.Minute)
+				if err != nil {
+					t.Errorf("Impossible to compare and swap the key CASCounterKey: %v", err)
+
+					return
+				}
+
+				if ok {
+					done++
+
+					swapped.Add(1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CASCounterKey")); value != 100 || swapped.Load() != 100 {
+		t.Errorf("The 100 swaps should be counted, %d swaps and the value %d provided", swapped.Load(), value)
+	}
+
+	if ok, _ := client.CompareAndSwap("CASKey", []byte("unexpected"), []byte(baseValue), time.Minute); ok {
+		t.Error("A different value shouldn't be swapped")
+	}
+
+	client.Delete("CASKey")
+
+	if ok, _ := client.CompareAndSwap("CASKey", []byte(baseValue), []byte(baseValue), time.Minute); ok {
+		t.Error("A missing key shouldn't be swapped with a non-nil old value")
+	}
+}
+
+func TestNuts_Increment(t *testing.T) {
+	client, _ := getNutsInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 100 {
+		t.Errorf("The 100 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 30, time.Minute); value != 70 {
+		t.Errorf("The counter should be decremented to 70, %d provided", value)
+	}
+
+	_ = client.Set("NotCounterKey", []byte(baseValue), time.Minute)
+
+	if _, err := client.Increment("NotCounterKey", 1, time.Minute); !errors.Is(err, core.ErrNotCounter) {
+		t.Errorf("Incrementing a value that isn't a counter should return ErrNotCounter, %v provided", err)
+	}
+}
+
+func TestNuts_Namespace(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	configuration.Namespace = "first"
+	first, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the first nuts instance: %v", err)
+	}
+
+	defer func() { _ = first.Close() }()
+
+	configuration.Namespace = "second"
+	second, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the second nuts instance: %v", err)
+	}
+
+	_ = first.Set("NamespaceKey_1", []byte("first"), time.Minute)
+	_ = second.Set("NamespaceKey_1", []byte("second"), time.Minute)
+	_ = second.Set("NamespaceKey_2", []byte("second"), time.Minute)
+
+	if res := first.Get("NamespaceKey_1"); string(res) != "first" {
+		t.Errorf("The first namespace should return its own value, %s provided", res)
+	}
+
+	if res := second.Get("NamespaceKey_1"); string(res) != "second" {
+		t.Errorf("The second namespace should return its own value, %s provided", res)
+	}
+
+	if keys := first.MapKeys("NamespaceKey_"); len(keys) != 1 || keys["1"] != "first" {
+		t.Errorf("The first namespace should only map its own keys, %v provided", keys)
+	}
+
+	if keys, _ := second.ScanKeys("NamespaceKey_", "", 0); len(keys) != 2 || keys[0] != "NamespaceKey_1" || keys[1] != "NamespaceKey_2" {
+		t.Errorf("The second namespace should only scan its own unprefixed keys, %v provided", keys)
+	}
+
+	first.Delete("NamespaceKey_1")
+
+	if res := first.Get("NamespaceKey_1"); res != nil {
+		t.Errorf("The key NamespaceKey_1 should have been deleted from the first namespace, %s provided", res)
+	}
+
+	if res := second.Get("NamespaceKey_1"); string(res) != "second" {
+		t.Errorf("Deleting from the first namespace shouldn't affect the second one, %s provided", res)
+	}
+}
+
+func TestNuts_Bucket(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir()}
+
+	configuration.Bucket = "first-bucket"
+	first, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the first nuts instance: %v", err)
+	}
+
+	defer func() { _ = first.Close() }()
+
+	configuration.Bucket = "second-bucket"
+	second, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the second nuts instance: %v", err)
+	}
+
+	_ = first.Set("BucketKey_1", []byte("first"), time.Minute)
+	_ = second.Set("BucketKey_1", []byte("second"), time.Minute)
+	_ = second.Set("BucketKey_2", []byte("second"), time.Minute)
+
+	if res := first.Get("BucketKey_1"); string(res) != "first" {
+		t.Errorf("The first bucket should return its own value, %s provided", res)
+	}
+
+	if res := first.Get("BucketKey_2"); res != nil {
+		t.Errorf("The first bucket shouldn't see the keys of the second one, %s provided", res)
+	}
+
+	if keys := second.MapKeys("BucketKey_"); len(keys) != 2 || keys["1"] != "second" {
+		t.Errorf("The second bucket should only map its own keys, %v provided", keys)
+	}
+
+	if err = second.Reset(); err != nil {
+		t.Fatalf("Impossible to reset the second bucket: %v", err)
+	}
+
+	if res := first.Get("BucketKey_1"); string(res) != "first" {
+		t.Errorf("Resetting the second bucket shouldn't affect the first one, %s provided", res)
+	}
+
+	if keys := first.MapKeys("BucketKey_"); len(keys) != 1 || keys["1"] != "first" {
+		t.Errorf("The first bucket should keep its keys, %v provided", keys)
+	}
+}
+
+func TestNuts_SegmentSize(t *testing.T) {
+	configuration := core.CacheProvider{Path: t.TempDir(), SegmentSize: 64 << 10}
+
+	client, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	value := bytes.Repeat([]byte("a"), 16<<10)
+	for i := range 20 {
+		if err = client.Set(fmt.Sprintf("SegmentKey%d", i), value, time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key SegmentKey%d: %v", i, err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(configuration.Path, "*.dat"))
+	if len(files) < 4 {
+		t.Errorf("The data files should be rotated every 64KB, %d files provided", len(files))
+	}
+
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.Size() > configuration.SegmentSize {
+			t.Errorf("The data file %s shouldn't exceed the segment size", file)
+		}
+	}
+}
+
+func TestNuts_SyncWrites(t *testing.T) {
+	for _, syncWrites := range []bool{true, false} {
+		configuration := core.CacheProvider{Path: t.TempDir(), SyncWrites: &syncWrites}
+
+		client, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the nuts instance: %v", err)
+		}
+
+		for i := range 100 {
+			if err = client.Set(fmt.Sprintf("SyncKey%d", i), []byte(baseValue), time.Minute); err != nil {
+				t.Fatalf("Impossible to set the key SyncKey%d: %v", i, err)
+			}
+		}
+
+		if err = client.Close(); err != nil {
+			t.Fatalf("Impossible to close the nuts instance: %v", err)
+		}
+
+		client, err = nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to reopen the nuts instance: %v", err)
+		}
+
+		for i := range 100 {
+			if value := client.Get(fmt.Sprintf("SyncKey%d", i)); string(value) != baseValue {
+				t.Errorf("The key SyncKey%d should be readable after the close with the sync writes %t, %s provided", i, syncWrites, value)
+			}
+		}
+
+		_ = client.Close()
+	}
+}
+
+func TestNuts_IndexMode(t *testing.T) {
+	for mode, expected := range map[string]nutsdb.EntryIdxMode{
+		"":                     nutsdb.HintKeyValAndRAMIdxMode,
+		core.IndexModeKeyValue: nutsdb.HintKeyValAndRAMIdxMode,
+		core.IndexModeKey:      nutsdb.HintKeyAndRAMIdxMode,
+	} {
+		configuration := core.CacheProvider{Path: t.TempDir(), IndexMode: mode, Validate: true}
+
+		client, err := nuts.Factory(configuration, zap.NewNop().Sugar(), 0)
+		if err != nil {
+			t.Fatalf("Failed to create the nuts instance with the %q index mode: %v", mode, err)
+		}
+
+		if indexMode := client.(*nuts.Nuts).IndexMode(); indexMode != expected {
+			t.Errorf("The %q index mode should open the DB with the entry index mode %d, %d provided", mode, expected, indexMode)
+		}
+
+		for i := range 50 {
+			key := fmt.Sprintf("IndexKey%d", i)
+			response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(key), key)
+
+			if err = client.SetMultiLevel(key, key, []byte(response), http.Header{}, "", time.Minute, key); err != nil {
+				t.Fatalf("Impossible to set the response %s with the %q index mode: %v", key, mode, err)
+			}
+
+			if err = client.Set(key+"_raw", []byte(baseValue), time.Minute); err != nil {
+				t.Fatalf("Impossible to set the key %s_raw with the %q index mode: %v", key, mode, err)
+			}
+		}
+
+		if _, err = client.Increment("IndexCounter", 2, time.Minute); err != nil {
+			t.Errorf("Impossible to increment the counter with the %q index mode: %v", mode, err)
+		}
+
+		client.Delete("IndexKey0_raw")
+
+		// The values are read back from disk after reopening the DB.
+		_ = client.Close()
+
+		if client, err = nuts.Factory(configuration, zap.NewNop().Sugar(), 0); err != nil {
+			t.Fatalf("Failed to reopen the nuts instance with the %q index mode: %v", mode, err)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://domain.com/index", nil)
+
+		for i := range 50 {
+			key := fmt.Sprintf("IndexKey%d", i)
+
+			if fresh, _ := client.GetMultiLevel(key, req, &core.Revalidator{}); fresh == nil {
+				t.Errorf("The response %s should be fresh with the %q index mode", key, mode)
+			} else if body, _ := io.ReadAll(fresh.Body); string(body) != key {
+				t.Errorf("The response %s should have its body with the %q index mode, %s provided", key, mode, body)
+			}
+
+			if value := client.Get(key + "_raw"); i > 0 && string(value) != baseValue {
+				t.Errorf("The key %s_raw should be stored with the %q index mode, %s provided", key, mode, value)
+			}
+		}
+
+		if client.Exists("IndexKey0_raw") {
+			t.Errorf("The deleted key should not exist with the %q index mode", mode)
+		}
+
+		if counter, _ := client.Increment("IndexCounter", 1, time.Minute); counter != 3 {
+			t.Errorf("The counter should be 3 with the %q index mode, %d provided", mode, counter)
+		}
+
+		if keys := client.MapKeys("IndexKey"); len(keys) != 99 {
+			t.Errorf("The 99 remaining keys should be mapped with the %q index mode, %d provided", mode, len(keys))
+		}
+
+		_ = client.Close()
+	}
+
+	if err := core.ValidateConfig(core.CacheProvider{IndexMode: "btree"}); !errors.Is(err, core.ErrInvalidConfig) {
+		t.Errorf("An unknown index mode should be reported as invalid, %v provided", err)
+	}
+}
+
+func BenchmarkNuts_SyncWrites(b *testing.B) {
+	for _, syncWrites := range []bool{true, false} {
+		b.Run(fmt.Sprintf("sync=%t", syncWrites), func(b *testing.B) {
+			client, err := nuts.Factory(core.CacheProvider{Path: b.TempDir(), SyncWrites: &syncWrites}, zap.NewNop().Sugar(), 0)
+			if err != nil {
+				b.Fatalf("Failed to create the nuts instance: %v", err)
+			}
+
+			defer func() { _ = client.Close() }()
+
+			b.ResetTimer()
+
+			for i := range b.N {
+				_ = client.Set(fmt.Sprintf("SyncBenchKey%d", i), []byte(baseValue), time.Minute)
+			}
+		})
+	}
+}
+
+func TestNuts_Stats(t *testing.T) {
+	client, _ := getNutsInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestNuts_Ping(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create nuts instance: %v", err)
+	}
+
+	if err = client.Ping(context.Background()); err != nil {
+		t.Errorf("Pinging a healthy store shouldn't fail: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err = client.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pinging with a done context should return its error, %v provided", err)
+	}
+
+	_ = client.Close()
+
+	if err = client.Ping(context.Background()); err == nil {
+		t.Error("Pinging a closed store should fail")
+	}
+}
+
+func TestNuts_ImportFromBadger(t *testing.T) {
+	source, err := badger.Factory(core.CacheProvider{}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the Badger instance: %v", err)
+	}
+
+	items := map[string]core.Entry{
+		"ExportMinuteKey": {Value: []byte(baseValue), Duration: time.Minute},
+		"ExportHourKey":   {Value: []byte("My second data"), Duration: time.Hour},
+	}
+
+	for i := range 1500 {
+		items[fmt.Sprintf("ExportKey%04d", i)] = core.Entry{Value: []byte(fmt.Sprintf("value %d", i)), Duration: time.Hour}
+	}
+
+	if err = source.SetMany(items); err != nil {
+		t.Fatalf("Impossible to set the values into Badger: %v", err)
+	}
+
+	if _, err = source.Increment("ExportCounterKey", 3, 0); err != nil {
+		t.Fatalf("Impossible to increment the counter into Badger: %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	if err = source.Export(&snapshot); err != nil {
+		t.Fatalf("Impossible to export the Badger keys: %v", err)
+	}
+
+	client, _ := nuts.Factory(core.CacheProvider{Namespace: "import"}, zap.NewNop().Sugar(), 0)
+	if err = client.Import(&snapshot); err != nil {
+		t.Fatalf("Impossible to import the keys into Nuts: %v", err)
+	}
+
+	for key, item := range items {
+		if res := client.Get(key); !bytes.Equal(res, item.Value) {
+			t.Errorf("The key %s should be imported with the value %s, %s provided", key, item.Value, res)
+		}
+
+		if ttl, _ := client.GetTTL(key); ttl <= item.Duration-5*time.Second || ttl > item.Duration {
+			t.Errorf("The key %s should keep its remaining TTL of %v, %v provided", key, item.Duration, ttl)
+		}
+	}
+
+	if value, _ := core.DecodeCounter(client.Get("ExportCounterKey")); value != 3 {
+		t.Errorf("The counter should be imported, %d provided", value)
+	}
+
+	if err = client.Import(strings.NewReader("\x10truncated")); !errors.Is(err, core.ErrMalformedExport) {
+		t.Errorf("A truncated export should return ErrMalformedExport, %v provided", err)
+	}
+}
+
+func TestNuts_ImportGzipExport(t *testing.T) {
+	source, err := badger.Factory(core.CacheProvider{ExportGzip: true}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the Badger instance: %v", err)
+	}
+
+	items := map[string]core.Entry{}
+	for i := range 1500 {
+		items[fmt.Sprintf("GzipExportKey%04d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Hour}
+	}
+
+	if err = source.SetMany(items); err != nil {
+		t.Fatalf("Impossible to set the values into Badger: %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	if err = source.Export(&snapshot); err != nil {
+		t.Fatalf("Impossible to export the Badger keys: %v", err)
+	}
+
+	if !bytes.HasPrefix(snapshot.Bytes(), []byte{0x1f, 0x8b}) {
+		t.Fatalf("The export should start with the gzip magic bytes, %x provided", snapshot.Bytes()[:min(snapshot.Len(), 4)])
+	}
+
+	client, _ := nuts.Factory(core.CacheProvider{Namespace: "import_gzip"}, zap.NewNop().Sugar(), 0)
+	if err = client.Import(&snapshot); err != nil {
+		t.Fatalf("Impossible to import the gzipped keys into Nuts: %v", err)
+	}
+
+	for key, item := range items {
+		if res := client.Get(key); !bytes.Equal(res, item.Value) {
+			t.Errorf("The key %s should be imported with the value %s, %s provided", key, item.Value, res)
+		}
+	}
+}
+
+func TestNuts_Exists(t *testing.T) {
+	client, _ := getNutsInstance()
+	_ = client.Set("ExistsKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExistsExpiredKey", []byte(baseValue), time.Second)
+
+	if !client.Exists("ExistsKey") {
+		t.Error("The key ExistsKey should exist")
+	}
+
+	if client.Exists(nonExistentKey) {
+		t.Errorf("The key %s should not exist", nonExistentKey)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if client.Exists("ExistsExpiredKey") {
+		t.Error("The expired key ExistsExpiredKey should not exist")
+	}
+}
+
+func TestNuts_KeyHashing(t *testing.T) {
+	client, _ := nuts.Factory(core.CacheProvider{Namespace: "hashing", KeyHashing: core.KeyHashingSHA256}, zap.NewNop().Sugar(), 0)
+
+	first := strings.Repeat("https://domain.com/very/long/path", 100) + "/first"
+	second := strings.Repeat("https://domain.com/very/long/path", 100) + "/second"
+
+	_ = client.Set(first, []byte("first"), time.Minute)
+	_ = client.Set(second, []byte("second"), time.Minute)
+
+	if res := client.Get(first); string(res) != "first" {
+		t.Errorf("The first long key should be readable, %s provided", res)
+	}
+
+	if res := client.Get(second); string(res) != "second" {
+		t.Errorf("The second long key shouldn't collide with the first one, %s provided", res)
+	}
+
+	if keys, _ := client.ScanKeys("https://", "", 0); len(keys) != 2 {
+		t.Errorf("The original keys should be scanned through the reverse index, %d provided", len(keys))
+	}
+}
+
+func TestNuts_GetWithError(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	_ = client.Set("GetWithErrorKey", []byte(baseValue), time.Minute)
+
+	if res, err := client.GetWithError("GetWithErrorKey"); err != nil || string(res) != baseValue {
+		t.Errorf("The key GetWithErrorKey should be returned without error, %s and %v provided", res, err)
+	}
+
+	if res, err := client.GetWithError(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) || res != nil {
+		t.Errorf("A missing key should return ErrKeyNotFound, %s and %v provided", res, err)
+	}
+
+	_ = client.Close()
+
+	if _, err = client.GetWithError("GetWithErrorKey"); !errors.Is(err, core.ErrClosed) || errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A closed store should return ErrClosed, %v provided", err)
+	}
+}
+
+func TestNuts_OperationTimeout(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir(), OperationTimeout: time.Nanosecond}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if err = client.Set("TimeoutKey", []byte(baseValue), time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("The Set should exceed the operation timeout, %v provided", err)
+	}
+
+	if _, err = client.GetWithError("TimeoutKey"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("The Get should exceed the operation timeout, %v provided", err)
+	}
+}
+
+func TestNuts_Sweep(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir(), SweepInterval: time.Hour}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for _, key := range []string{"SweepFirst", "SweepSecond"} {
+		if err = client.Set(key, []byte(baseValue), time.Second); err != nil {
+			t.Fatalf("Impossible to set the key %s: %v", key, err)
+		}
+	}
+
+	if err = client.Set("SweepKept", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key SweepKept: %v", err)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if err = client.(*nuts.Nuts).Sweep(); err != nil {
+		t.Fatalf("Impossible to sweep the expired entries: %v", err)
+	}
+
+	keys := client.MapKeys("Sweep")
+	if len(keys) != 1 {
+		t.Errorf("Only the key SweepKept should remain after the sweep, %v provided", keys)
+	}
+
+	if _, found := keys["Kept"]; !found {
+		t.Errorf("The key SweepKept should remain after the sweep, %v provided", keys)
+	}
+}
+
+func TestNuts_Reset(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 5 {
+		if err = client.Set(fmt.Sprintf("ResetKey_%d", i), []byte(baseValue), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key ResetKey_%d: %v", i, err)
+		}
+	}
+
+	if err = client.Reset(); err != nil {
+		t.Fatalf("Impossible to reset the nuts instance: %v", err)
+	}
+
+	if keys := client.MapKeys(""); len(keys) != 0 {
+		t.Errorf("No key should remain after the reset, %v provided", keys)
+	}
+
+	if err = client.Set("ResetKey_new", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("The store should remain usable after the reset: %v", err)
+	}
+
+	if res := client.Get("ResetKey_new"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", res, baseValue)
+	}
+}
+
+func TestNuts_DumpMeta(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 3 {
+		if err = client.Set(fmt.Sprintf("MetaKey_%d", i), []byte(baseValue), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key MetaKey_%d: %v", i, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = client.(core.MetaDumper).DumpMeta(&buf); err != nil {
+		t.Fatalf("Impossible to dump the keys metadata: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("The dump should contain 3 lines, %d provided: %s", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		var record core.MetaRecord
+		if err = json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("The line %s should be valid JSON: %v", line, err)
+		}
+
+		if !strings.HasPrefix(record.Key, "MetaKey_") || record.Size <= 0 || record.TTL <= 0 || record.TTL > 60 {
+			t.Errorf("Unexpected metadata record %+v", record)
+		}
+	}
+}
+
+func TestNuts_Iterate(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 10 {
+		if err = client.Set(fmt.Sprintf("IterateKey_%d", i), []byte(fmt.Sprintf("IterateValue_%d", i)), time.Minute); err != nil {
+			t.Fatalf("Impossible to set the key IterateKey_%d: %v", i, err)
+		}
+	}
+
+	entries, errs := client.(core.Iterator).Iterate(context.Background())
+	seen := map[string]bool{}
+
+	for entry := range entries {
+		if string(entry.Value) != strings.Replace(entry.Key, "Key", "Value", 1) {
+			t.Errorf("Unexpected value %s for the key %s", entry.Value, entry.Key)
+		}
+
+		seen[entry.Key] = true
+	}
+
+	if err = <-errs; err != nil {
+		t.Errorf("The iteration shouldn't fail, %v provided", err)
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("The iteration should yield 10 entries, %d provided", len(seen))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, errs = client.(core.Iterator).Iterate(ctx)
+
+	<-entries
+	cancel()
+
+	remaining := 0
+	for range entries {
+		remaining++
+	}
+
+	if remaining > 1 {
+		t.Errorf("The cancelled iteration should stop, %d more entries provided", remaining)
+	}
+
+	if err = <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("The cancelled iteration should return context.Canceled, %v provided", err)
+	}
+}
+
+func TestNuts_Watch(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
+
+func TestNuts_ReadOnly(t *testing.T) {
+	path := t.TempDir()
+
+	writer, err := nuts.Factory(core.CacheProvider{Path: path}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	if err = writer.Set("ReadOnlyKey", []byte(baseValue), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key ReadOnlyKey: %v", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Impossible to close the nuts instance: %v", err)
+	}
+
+	client, err := nuts.Factory(core.CacheProvider{Path: path, ReadOnly: true}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to open the nuts instance read-only: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if res := client.Get("ReadOnlyKey"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", res, baseValue)
+	}
+
+	if keys := client.MapKeys("ReadOnly"); keys["Key"] != baseValue {
+		t.Errorf("The read-only instance should map the stored keys, %v provided", keys)
+	}
+
+	if err = client.Set("ReadOnlyNew", []byte(baseValue), time.Minute); !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The Set should return ErrReadOnly, %v provided", err)
+	}
+
+	err = client.SetMultiLevel("ReadOnlyNew", "ReadOnlyNew", []byte(baseValue), http.Header{}, "", time.Minute, "ReadOnlyNew")
+	if !errors.Is(err, core.ErrReadOnly) {
+		t.Errorf("The SetMultiLevel should return ErrReadOnly, %v provided", err)
+	}
+
+	client.Delete("ReadOnlyKey")
+
+	if res := client.Get("ReadOnlyKey"); string(res) != baseValue {
+		t.Errorf("The Delete shouldn't remove the key of a read-only instance, %s provided", res)
+	}
+}
+
+func TestNuts_InvalidateSurrogate(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	responses := map[string]string{
+		"SurrogateFirst":  "group first",
+		"SurrogateSecond": "group",
+		"SurrogateOther":  "other",
+	}
+
+	for key, surrogateKeys := range responses {
+		response := fmt.Sprintf("HTTP/1.1 200 OK\r\nSurrogate-Key: %s\r\nContent-Length: %d\r\n\r\n%s", surrogateKeys, len(baseValue), baseValue)
+		if err = client.SetMultiLevel(key, key, []byte(response), http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Impossible to set the response %s: %v", key, err)
+		}
+	}
+
+	client.Delete("SurrogateFirst")
+
+	if index := client.Get(core.SurrogateKeyPrefix + "first"); index != nil {
+		t.Errorf("The Delete should remove the emptied surrogate index, %s provided", index)
+	}
+
+	if err = client.SetMultiLevel("SurrogateFirst", "SurrogateFirst", []byte("HTTP/1.1 200 OK\r\nSurrogate-Key: group\r\nContent-Length: 0\r\n\r\n"), http.Header{}, "", time.Minute, "SurrogateFirst"); err != nil {
+		t.Fatalf("Impossible to set the response SurrogateFirst: %v", err)
+	}
+
+	deleted, err := client.InvalidateSurrogate("group")
+	if err != nil {
+		t.Fatalf("Impossible to invalidate the surrogate key group: %v", err)
+	}
+
+	if deleted != 2 {
+		t.Errorf("The invalidation should delete the 2 responses tagged by group, %d provided", deleted)
+	}
+
+	if client.Exists("SurrogateFirst") || client.Exists("SurrogateSecond") {
+		t.Error("The responses tagged by group should be deleted")
+	}
+
+	if !client.Exists("SurrogateOther") {
+		t.Error("The response tagged by other shouldn't be deleted")
+	}
+
+	if deleted, _ = client.InvalidateSurrogate("group"); deleted != 0 {
+		t.Errorf("A second invalidation shouldn't delete anything, %d provided", deleted)
+	}
+}
+
+func TestNuts_SetWithMeta(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	meta := map[string]string{"origin": "backend-1", "region": "eu-west", "content-type": "text/html"}
+	if err = client.SetWithMeta("MetaKey", []byte(baseValue), meta, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value with its metadata: %v", err)
+	}
+
+	stored, err := client.GetMeta("MetaKey")
+	if err != nil || !maps.Equal(stored, meta) {
+		t.Errorf("The metadata should round-trip, %v and %v provided", stored, err)
+	}
+
+	if value := client.Get("MetaKey"); string(value) != baseValue {
+		t.Errorf("The value should be stored as is, %s provided", value)
+	}
+
+	if _, err = client.GetMeta(nonExistentKey); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A key without metadata should return ErrKeyNotFound, %v provided", err)
+	}
+
+	if err = client.SetWithMeta("MetaKey", []byte(baseValue), map[string]string{"origin": "backend-2"}, time.Minute); err != nil {
+		t.Fatalf("Impossible to replace the value with its metadata: %v", err)
+	}
+
+	if stored, _ = client.GetMeta("MetaKey"); !maps.Equal(stored, map[string]string{"origin": "backend-2"}) {
+		t.Errorf("The metadata should be replaced, %v provided", stored)
+	}
+
+	if err = client.SetWithMeta("MetaKey", []byte(baseValue), nil, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the value without metadata: %v", err)
+	}
+
+	if _, err = client.GetMeta("MetaKey"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("An empty metadata should delete the previous one, %v provided", err)
+	}
+
+	_ = client.SetWithMeta("MetaKey", []byte(baseValue), meta, time.Minute)
+
+	client.Delete("MetaKey")
+
+	if _, err = client.GetMeta("MetaKey"); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("The metadata should be deleted with the key, %v provided", err)
+	}
+
+	if record := client.Get(core.EntryMetaKeyPrefix + "MetaKey"); record != nil {
+		t.Errorf("The metadata record should be deleted with the key, %s provided", record)
+	}
+}
+
+func TestNuts_OnEvict(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		evicted = map[string]core.EvictReason{}
+	)
+
+	onEvict := func(key string, reason core.EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		evicted[key] = reason
+	}
+
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir(), OnEvict: onEvict}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	_ = client.Set("DeletedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExpiredKey", []byte(baseValue), time.Second)
+	_ = client.Set("AliveKey", []byte(baseValue), time.Minute)
+
+	client.Delete("DeletedKey")
+	client.Delete(nonExistentKey)
+
+	time.Sleep(2 * time.Second)
+
+	if err = client.(*nuts.Nuts).Sweep(); err != nil {
+		t.Fatalf("Impossible to sweep the expired entries: %v", err)
+	}
+
+	// Close delivers the pending notifications.
+	_ = client.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if reason, ok := evicted["DeletedKey"]; !ok || reason != core.EvictDeleted {
+		t.Errorf("The deleted key should be notified as deleted, %v provided", reason)
+	}
+
+	if reason, ok := evicted["ExpiredKey"]; !ok || reason != core.EvictExpired {
+		t.Errorf("The expired key should be notified as expired, %v provided", reason)
+	}
+
+	if _, ok := evicted[nonExistentKey]; ok {
+		t.Error("The deletion of a missing key shouldn't be notified")
+	}
+
+	if _, ok := evicted["AliveKey"]; ok {
+		t.Error("The alive key shouldn't be notified")
+	}
+}
+
+func TestNuts_GetInto(t *testing.T) {
+	client, _ := getNutsInstance()
+	reader := client.(core.ValueReader)
+
+	_ = client.Set("GetIntoKey", []byte(baseValue), time.Minute)
+
+	var seen string
+
+	err := reader.GetInto("GetIntoKey", func(value []byte) error {
+		seen = string(value)
+
+		return nil
+	})
+	if err != nil || seen != baseValue {
+		t.Errorf("The callback should see the stored value, %s and %v provided", seen, err)
+	}
+
+	if err = reader.GetInto(nonExistentKey, func([]byte) error { return nil }); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("A missing key should return ErrKeyNotFound, %v provided", err)
+	}
+
+	callbackErr := errors.New("callback error")
+	if err = reader.GetInto("GetIntoKey", func([]byte) error { return callbackErr }); !errors.Is(err, callbackErr) {
+		t.Errorf("The callback error should be returned, %v provided", err)
+	}
+}
+
+func BenchmarkNuts_GetIntoLargeValue(b *testing.B) {
+	client, _ := getNutsInstance()
+	_ = client.Set("BenchmarkLargeKey", bytes.Repeat([]byte(baseValue), 1<<16), time.Minute)
+
+	reader := client.(core.ValueReader)
+	size := 0
+
+	b.ResetTimer()
+
+	for range b.N {
+		_ = reader.GetInto("BenchmarkLargeKey", func(value []byte) error {
+			size = len(value)
+
+			return nil
+		})
+	}
+
+	_ = size
+}
+
+func TestNuts_DeleteKeys(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir()}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 4 {
+		_ = client.SetWithMeta(fmt.Sprintf("DeleteKeys_%d", i), []byte(baseValue), map[string]string{"index": fmt.Sprint(i)}, time.Minute)
+	}
+
+	events, _ := client.(core.Watcher).Watch(context.Background(), "DeleteKeys_")
+
+	if err = client.DeleteKeys([]string{"DeleteKeys_0", nonExistentKey, "DeleteKeys_2"}); err != nil {
+		t.Fatalf("The missing keys should be ignored: %v", err)
+	}
+
+	for i, deleted := range []bool{true, false, true, false} {
+		key := fmt.Sprintf("DeleteKeys_%d", i)
+		if client.Exists(key) == deleted {
+			t.Errorf("The key %s should be deleted: %t", key, deleted)
+		}
+
+		if _, err = client.GetMeta(key); errors.Is(err, core.ErrKeyNotFound) != deleted {
+			t.Errorf("The metadata of the key %s should be deleted: %t, %v provided", key, deleted, err)
+		}
+	}
+
+	for _, key := range []string{"DeleteKeys_0", "DeleteKeys_2"} {
+		if event := <-events; event.Type != core.EventDelete || event.Key != key {
+			t.Errorf("The deletion of the key %s should be streamed, %v provided", key, event)
+		}
+	}
+}
+
+func TestNuts_DirPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The directory permissions aren't supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "permissions")
+
+	client, err := nuts.Factory(core.CacheProvider{Path: path, DirPermissions: 0o700}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("The directory should be created: %v", err)
+	}
+
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("The directory should be created with the configured permissions, %v provided", info.Mode().Perm())
+	}
+}
+
+func TestNuts_ReadCache(t *testing.T) {
+	client, err := nuts.Factory(core.CacheProvider{Path: t.TempDir(), ReadCacheSize: 2}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the nuts instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	provider := client.(*nuts.Nuts)
+
+	_ = client.Set("ReadCacheKey", []byte(baseValue), time.Minute)
+
+	if value := client.Get("ReadCacheKey"); string(value) != baseValue || provider.ReadCacheHits() != 0 {
+		t.Fatalf("The first read should hit the disk, %s read with %d hits", value, provider.ReadCacheHits())
+	}
+
+	if value := client.Get("ReadCacheKey"); string(value) != baseValue || provider.ReadCacheHits() != 1 {
+		t.Errorf("The second read should hit the memory, %s read with %d hits", value, provider.ReadCacheHits())
+	}
+
+	_ = client.Set("ReadCacheKey", []byte("New value"), time.Minute)
+
+	if value := client.Get("ReadCacheKey"); string(value) != "New value" || provider.ReadCacheHits() != 1 {
+		t.Errorf("A write should evict the memory entry, %s read with %d hits", value, provider.ReadCacheHits())
+	}
+
+	_ = client.Get("ReadCacheKey")
+	client.Delete("ReadCacheKey")
+
+	hits := provider.ReadCacheHits()
+	if value := client.Get("ReadCacheKey"); value != nil || provider.ReadCacheHits() != hits {
+		t.Errorf("Delete should evict the memory entry, %s read with %d hits", value, provider.ReadCacheHits()-hits)
+	}
+
+	_ = client.Set("ExpiringReadCacheKey", []byte(baseValue), time.Second)
+	_ = client.Get("ExpiringReadCacheKey")
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if value := client.Get("ExpiringReadCacheKey"); value != nil {
+		t.Errorf("The memory entry shouldn't outlive the key, %s read", value)
+	}
+}
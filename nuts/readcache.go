@@ -0,0 +1,134 @@
+package nuts
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type readEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// readCache keeps the last values read by Get in memory, the least recently used one is dropped once
+// the size is reached. The generation changes on every eviction so a value read before a write isn't
+// cached, each entry expires with its key.
+type readCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	size    int
+	gen     uint64
+	hits    atomic.Uint64
+}
+
+// newReadCache returns nil when the size isn't positive, the nil cache misses every read.
+func newReadCache(size int) *readCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &readCache{entries: make(map[string]*list.Element), order: list.New(), size: size}
+}
+
+func (c *readCache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+
+	entry := element.Value.(*readEntry)
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits.Add(1)
+
+	return entry.value, true
+}
+
+func (c *readCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen
+}
+
+// set stores the value unless an eviction happened since the given generation, a zero expiresAt
+// keeps the persistent keys until evicted.
+func (c *readCache) set(key string, value []byte, expiresAt time.Time, generation uint64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != generation {
+		return
+	}
+
+	if element, found := c.entries[key]; found {
+		element.Value = &readEntry{key: key, value: value, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*readEntry).key)
+	}
+
+	c.entries[key] = c.order.PushFront(&readEntry{key: key, value: value, expiresAt: expiresAt})
+}
+
+func (c *readCache) evict(keys ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	for _, key := range keys {
+		if element, found := c.entries[key]; found {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *readCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
@@ -0,0 +1,129 @@
+package nuts
+
+import (
+	"sync"
+	"time"
+)
+
+// sweeper periodically deletes the expired entries of a Nuts bucket until stopped.
+type sweeper struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func startSweeper(provider *Nuts, interval time.Duration) *sweeper {
+	s := &sweeper{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+
+			if provider.IsClose() {
+				return
+			}
+
+			_ = provider.Sweep()
+		}
+	}()
+
+	return s
+}
+
+// Stop ends the sweep and waits for the running one to finish.
+func (s *sweeper) Stop() {
+	if s == nil {
+		return
+	}
+
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// expiries tracks the deadline of the keys written with a time to live, the sweep reports the ones gone once
+// their deadline elapsed as expired since nutsdb deletes the expired keys on its own.
+type expiries struct {
+	mu        sync.Mutex
+	deadlines map[string]time.Time
+}
+
+func newExpiries() *expiries {
+	return &expiries{deadlines: map[string]time.Time{}}
+}
+
+// watch records the deadline of the key, a persistent key is forgotten.
+func (e *expiries) watch(key string, ttl uint32) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if ttl == 0 {
+		delete(e.deadlines, key)
+
+		return
+	}
+
+	e.deadlines[key] = time.Now().Add(time.Duration(ttl) * time.Second)
+}
+
+// forget stops tracking the keys, they are deleted explicitly.
+func (e *expiries) forget(keys ...string) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, key := range keys {
+		delete(e.deadlines, key)
+	}
+}
+
+// reset stops tracking every key.
+func (e *expiries) reset() {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	clear(e.deadlines)
+}
+
+// due returns and forgets the keys whose deadline elapsed.
+func (e *expiries) due(now time.Time) []string {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keys := []string{}
+
+	for key, deadline := range e.deadlines {
+		if !deadline.After(now) {
+			keys = append(keys, key)
+			delete(e.deadlines, key)
+		}
+	}
+
+	return keys
+}
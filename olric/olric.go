@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -15,7 +17,6 @@ import (
 	"github.com/buraksezer/olric/config"
 	"github.com/darkweak/storages/core"
 	"github.com/google/uuid"
-	"github.com/pierrec/lz4/v4"
 	"gopkg.in/yaml.v3"
 )
 
@@ -26,11 +27,17 @@ type Olric struct {
 	dm            *sync.Pool
 	stale         time.Duration
 	logger        core.Logger
+	mapper        core.Mapper
+	compression   string
+	clock         core.Clock
 	addresses     []string
 	reconnecting  bool
 	configuration config.Client
 }
 
+// defaultPort completes the configured addresses without port.
+const defaultPort = "3320"
+
 func tryToLoadConfiguration(olricInstance *config.Config, olricConfiguration core.CacheProvider, logger core.Logger) (*config.Config, bool) {
 	var err error
 
@@ -112,6 +119,23 @@ func newEmbeddedOlric(olricConfiguration core.CacheProvider, logger core.Logger)
 
 // Factory function create new Olric instance.
 func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if olricConfiguration.Validate {
+		if err := core.ValidateConfig(olricConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Olric configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(olricConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, olricConfiguration, logger)
+}
+
+func factory(olricConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	if olricConfiguration.URL == "" && olricConfiguration.Configuration != nil {
 		if olricCfg, ok := olricConfiguration.Configuration.(map[string]interface{}); ok {
 			if mode, found := olricCfg["mode"]; found && mode.(string) == "local" {
@@ -129,6 +153,9 @@ func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale ti
 					dm:            nil,
 					stale:         stale,
 					logger:        logger,
+					mapper:        core.ConfiguredMapper(olricConfiguration),
+					compression:   core.ConfiguredCompression(olricConfiguration, logger),
+					clock:         core.ClockOrDefault(olricConfiguration.Clock),
 					configuration: config.Client{},
 					addresses:     strings.Split(olricConfiguration.URL, ","),
 				}, nil
@@ -136,7 +163,14 @@ func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale ti
 		}
 	}
 
-	client, err := olric.NewClusterClient(strings.Split(olricConfiguration.URL, ","))
+	addresses, err := parseAddresses(olricConfiguration.URL)
+	if err != nil {
+		logger.Errorf("Impossible to parse the Olric addresses %s, %v", olricConfiguration.URL, err)
+
+		return nil, err
+	}
+
+	client, err := olric.NewClusterClient(addresses)
 	if err != nil {
 		logger.Errorf("Impossible to connect to Olric, %v", err)
 	}
@@ -146,11 +180,35 @@ func Factory(olricConfiguration core.CacheProvider, logger core.Logger, stale ti
 		dm:            nil,
 		stale:         stale,
 		logger:        logger,
+		mapper:        core.ConfiguredMapper(olricConfiguration),
+		compression:   core.ConfiguredCompression(olricConfiguration, logger),
+		clock:         core.ClockOrDefault(olricConfiguration.Clock),
 		configuration: config.Client{},
-		addresses:     strings.Split(olricConfiguration.URL, ","),
+		addresses:     addresses,
 	}, nil
 }
 
+// parseAddresses returns the comma separated addresses completed by the default Olric port, the client only
+// dials tcp.
+func parseAddresses(url string) ([]string, error) {
+	addresses := []string{}
+
+	for _, value := range strings.Split(url, ",") {
+		network, address, err := core.ParseAddressWithDefaultPort(value, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+
+		if network != "tcp" {
+			return nil, fmt.Errorf("%w: the Olric address %q must be a tcp address", core.ErrInvalidConfig, value)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
 // Name returns the storer name.
 func (provider *Olric) Name() string {
 	return "OLRIC"
@@ -186,7 +244,7 @@ func (provider *Olric) ListKeys() []string {
 	keys := []string{}
 
 	for records.Next() {
-		mapping, err := core.DecodeMapping(provider.Get(records.Key()))
+		mapping, err := provider.mapper.Decode(provider.Get(records.Key()))
 		if err == nil {
 			for _, v := range mapping.GetMapping() {
 				keys = append(keys, v.GetRealKey())
@@ -224,7 +282,7 @@ func (provider *Olric) MapKeys(prefix string) map[string]string {
 	keys := map[string]string{}
 
 	for records.Next() {
-		if strings.HasPrefix(records.Key(), prefix) {
+		if strings.HasPrefix(records.Key(), prefix) && core.ListedKey(prefix, records.Key()) {
 			k, _ := strings.CutPrefix(records.Key(), prefix)
 			keys[k] = string(provider.Get(records.Key()))
 		}
@@ -237,46 +295,56 @@ func (provider *Olric) MapKeys(prefix string) map[string]string {
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Olric) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Olric) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	dm := provider.dm.Get().(olric.DMap)
 	defer provider.dm.Put(dm)
 
 	res, e := dm.Get(context.Background(), key)
 	if e != nil {
-		return fresh, stale
+		return fresh, stale, match
 	}
 
 	val, _ := res.Byte()
-	fresh, stale, _ = core.MappingElection(provider, val, req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
 
-	return fresh, stale
+	return fresh, stale, match
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
 func (provider *Olric) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
 
-	dmap := provider.dm.Get().(olric.DMap)
-	defer provider.dm.Put(dmap)
+		return nil
+	}
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+	now := provider.clock.Now()
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	dmap := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dmap)
 
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Olric, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Olric, %v", variedKey, err)
+	if err := dmap.Put(context.Background(), variedKey, compressed, olric.EX(duration)); err != nil {
+		provider.logger.Errorf("Impossible to set value into Olric, %v", err)
 
 		return err
 	}
 
-	if err := dmap.Put(context.Background(), variedKey, compressed.Bytes(), olric.EX(duration)); err != nil {
-		provider.logger.Errorf("Impossible to set value into Olric, %v", err)
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Olric, %v", variedKey, err)
 
 		return err
 	}
@@ -297,7 +365,7 @@ func (provider *Olric) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	val, err = core.MappingUpdater(variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err = core.MappingUpdaterWith(provider.mapper, variedKey, val, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if err != nil {
 		return err
 	}
@@ -305,6 +373,44 @@ func (provider *Olric) SetMultiLevel(baseKey, variedKey string, value []byte, va
 	return provider.Set(mappingKey, val, time.Hour)
 }
 
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Olric) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to scan the olric keys while reconnecting.")
+
+		return []string{}, ""
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	records, err := dm.Scan(context.Background(), olric.Match("^"+regexp.QuoteMeta(prefix)))
+	if err != nil {
+		if !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		provider.logger.Error("An error occurred while trying to scan keys in Olric: %s\n", err)
+
+		return []string{}, ""
+	}
+
+	keys = []string{}
+
+	for records.Next() {
+		keys = append(keys, records.Key())
+	}
+
+	records.Close()
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Olric) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Olric) Get(key string) []byte {
 	if provider.reconnecting {
@@ -330,6 +436,85 @@ func (provider *Olric) Get(key string) []byte {
 	return val
 }
 
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Olric) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Olric) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Olric) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Olric) GetTTL(key string) (time.Duration, bool) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the olric key ttl while reconnecting.")
+
+		return 0, false
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	res, err := dm.Get(context.Background(), key)
+	if err != nil {
+		return 0, false
+	}
+
+	// Olric stores the expiry as an absolute unix timestamp in milliseconds.
+	if res.TTL() == 0 {
+		return core.NoExpiration, true
+	}
+
+	return time.Until(time.UnixMilli(res.TTL())), true
+}
+
+// Exists method will check the key in Olric provider, the expired entries are evicted by the cluster.
+func (provider *Olric) Exists(key string) bool {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to check the olric key while reconnecting.")
+
+		return false
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	_, err := dm.Get(context.Background(), key)
+	if err != nil {
+		if !errors.Is(err, olric.ErrKeyNotFound) && !errors.Is(err, olric.ErrKeyTooLarge) && !provider.reconnecting {
+			go provider.Reconnect()
+		}
+
+		return false
+	}
+
+	return true
+}
+
 // Set method will store the response in Olric provider.
 func (provider *Olric) Set(key string, value []byte, duration time.Duration) error {
 	if provider.reconnecting {
@@ -355,6 +540,203 @@ func (provider *Olric) Set(key string, value []byte, duration time.Duration) err
 	return err
 }
 
+// SetContext method will store the response unless the context is done.
+func (provider *Olric) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Olric provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Olric) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Olric provider only if the key doesn't exist yet.
+func (provider *Olric) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the olric value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	err := dm.Put(context.Background(), key, value, olric.EX(duration), olric.NX())
+	if errors.Is(err, olric.ErrKeyFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Olric, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Olric provider only if the stored bytes equal old,
+// while holding the key lock.
+func (provider *Olric) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the olric value while reconnecting.")
+
+		return false, errors.New("reconnecting error")
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	swapped, err := provider.compareAndSwap(dm, key, old, value, duration)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Olric, %v", key, err)
+	}
+
+	return swapped, err
+}
+
+func (provider *Olric) compareAndSwap(dm olric.DMap, key string, old, value []byte, duration time.Duration) (bool, error) {
+	lock, err := dm.LockWithTimeout(context.Background(), key, counterLockTimeout, counterLockTimeout)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = lock.Unlock(context.Background()) }()
+
+	var current []byte
+
+	res, err := dm.Get(context.Background(), key)
+	if err == nil {
+		current, err = res.Byte()
+	}
+
+	if err != nil && !errors.Is(err, olric.ErrKeyNotFound) {
+		return false, err
+	}
+
+	if !core.SwapMatches(current, old) {
+		return false, nil
+	}
+
+	return true, dm.Put(context.Background(), key, value, olric.EX(duration))
+}
+
+// counterLockTimeout bounds the time to acquire and to hold the key lock of Increment and CompareAndSwap.
+const counterLockTimeout = 5 * time.Second
+
+// Increment method will add delta to the counter stored in Olric provider while holding the key lock.
+func (provider *Olric) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to set the olric value while reconnecting.")
+
+		return 0, errors.New("reconnecting error")
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	value, err := provider.increment(dm, key, delta, duration)
+	if err != nil && !errors.Is(err, core.ErrNotCounter) {
+		provider.logger.Errorf("Impossible to increment the key %s into Olric, %v", key, err)
+	}
+
+	return value, err
+}
+
+func (provider *Olric) increment(dm olric.DMap, key string, delta int64, duration time.Duration) (int64, error) {
+	lock, err := dm.LockWithTimeout(context.Background(), key, counterLockTimeout, counterLockTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = lock.Unlock(context.Background()) }()
+
+	var current []byte
+
+	res, err := dm.Get(context.Background(), key)
+	if err == nil {
+		current, err = res.Byte()
+	}
+
+	if err != nil && !errors.Is(err, olric.ErrKeyNotFound) {
+		return 0, err
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	var opts []olric.PutOption
+	if duration > 0 {
+		opts = append(opts, olric.EX(duration))
+	}
+
+	return value, dm.Put(context.Background(), key, encoded, opts...)
+}
+
+// Decrement method will subtract delta from the counter stored in Olric provider.
+func (provider *Olric) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Olric provider.
+// The compressed value is copied because Olric may keep a reference to the stored bytes.
+func (provider *Olric) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, bytes.Clone(compressed), duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Olric, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Olric) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Olric) Touch(key string, duration time.Duration) error {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to touch the olric key while reconnecting.")
+
+		return errors.New("reconnecting error")
+	}
+
+	dm := provider.dm.Get().(olric.DMap)
+	defer provider.dm.Put(dm)
+
+	err := dm.Expire(context.Background(), key, duration)
+	if errors.Is(err, olric.ErrKeyNotFound) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Olric, %v", key, err)
+	}
+
+	return err
+}
+
 // Delete method will delete the response in Olric provider if exists corresponding to key param.
 func (provider *Olric) Delete(key string) {
 	if provider.reconnecting {
@@ -363,6 +745,9 @@ func (provider *Olric) Delete(key string) {
 		return
 	}
 
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	dm := provider.dm.Get().(olric.DMap)
 	defer provider.dm.Put(dm)
 
@@ -372,8 +757,8 @@ func (provider *Olric) Delete(key string) {
 	}
 }
 
-// DeleteMany method will delete the responses in Olric provider if exists corresponding to the regex key param.
-func (provider *Olric) DeleteMany(key string) {
+// DeleteMany method will delete the responses in Olric provider if exists corresponding to the prefix pattern param.
+func (provider *Olric) DeleteMany(pattern string) {
 	if provider.reconnecting {
 		provider.logger.Error("Impossible to delete the olric keys while reconnecting.")
 
@@ -383,7 +768,7 @@ func (provider *Olric) DeleteMany(key string) {
 	dmap := provider.dm.Get().(olric.DMap)
 	defer provider.dm.Put(dmap)
 
-	records, err := dmap.Scan(context.Background(), olric.Match(key))
+	records, err := dmap.Scan(context.Background(), olric.Match("^"+regexp.QuoteMeta(core.KeyPrefix(pattern))))
 	if err != nil {
 		if !provider.reconnecting {
 			go provider.Reconnect()
@@ -404,6 +789,101 @@ func (provider *Olric) DeleteMany(key string) {
 	_, _ = dmap.Delete(context.Background(), keys...)
 }
 
+// DeleteKeys method will delete the keys in Olric provider one by one, the missing keys are ignored.
+func (provider *Olric) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Olric provider.
+func (provider *Olric) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Olric provider.
+func (provider *Olric) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Olric provider.
+func (provider *Olric) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Olric provider without altering it.
+func (provider *Olric) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Olric provider and its metadata under a parallel key.
+func (provider *Olric) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Olric provider, core.ErrKeyNotFound if none.
+func (provider *Olric) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the entries count and the in-use memory of the DMap summed over the primary partitions of each member.
+func (provider *Olric) Stats() (core.StorageStats, error) {
+	if provider.reconnecting {
+		provider.logger.Error("Impossible to get the olric stats while reconnecting.")
+
+		return core.StorageStats{}, errors.New("reconnecting error")
+	}
+
+	members, err := provider.Client.Members(context.Background())
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the Olric members, %v", err)
+
+		return core.StorageStats{}, err
+	}
+
+	stats := core.StorageStats{}
+
+	for _, member := range members {
+		memberStats, err := provider.Client.Stats(context.Background(), member.Name)
+		if err != nil {
+			provider.logger.Errorf("Impossible to get the Olric stats of the member %s, %v", member.Name, err)
+
+			return stats, err
+		}
+
+		for _, partition := range memberStats.Partitions {
+			dmap := partition.DMaps["souin-map"]
+			stats.KeyCount += int64(dmap.Length)
+			stats.ApproxSizeBytes += int64(dmap.SlabInfo.Inuse)
+		}
+	}
+
+	return stats, nil
+}
+
+// Ping method will list the Olric cluster members.
+func (provider *Olric) Ping(ctx context.Context) error {
+	if provider.reconnecting {
+		return errors.New("reconnecting error")
+	}
+
+	_, err := provider.Client.Members(ctx)
+
+	return err
+}
+
+// Export method will stream every key of the Olric provider paginating the scanned keys.
+func (provider *Olric) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Olric provider by batches.
+func (provider *Olric) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
 // Init method will initialize Olric provider if needed.
 func (provider *Olric) Init() error {
 	provider.dm = &sync.Pool{
@@ -417,9 +897,11 @@ func (provider *Olric) Init() error {
 	return nil
 }
 
-// Reset method will reset or close provider.
+// Reset method will delete every key.
 func (provider *Olric) Reset() error {
-	return provider.Close(context.Background())
+	provider.DeleteMany("*")
+
+	return nil
 }
 
 func (provider *Olric) Reconnect() {
@@ -433,3 +915,8 @@ func (provider *Olric) Reconnect() {
 		provider.Reconnect()
 	}
 }
+
+// Close method will close the Olric client.
+func (provider *Olric) Close() error {
+	return provider.Client.Close(context.Background())
+}
@@ -1,6 +1,7 @@
 package olric_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -272,3 +273,20 @@ func TestEmbeddedOlric_Init(t *testing.T) {
 		t.Error("Impossible to init Olric provider")
 	}
 }
+
+func TestOlric_Stats(t *testing.T) {
+	client, _ := getOlricInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
@@ -2,9 +2,10 @@ package otter
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,20 +13,45 @@ import (
 
 	"github.com/darkweak/storages/core"
 	"github.com/maypok86/otter"
-	"github.com/pierrec/lz4/v4"
 )
 
 // Otter provider type.
 type Otter struct {
-	cache  *otter.CacheWithVariableTTL[string, []byte]
-	stale  time.Duration
-	logger core.Logger
+	cache       *otter.CacheWithVariableTTL[string, []byte]
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	compression string
+	clock       core.Clock
 }
 
 var instanceMap = sync.Map{}
 
+// counterMutex serializes the counters and compare-and-swap read-modify-write, the cache is shared between the instances.
+var counterMutex sync.Mutex
+
+// counterMaxTTL is used for the counters without expiry, Otter requires a time to live.
+const counterMaxTTL = 365 * 24 * time.Hour
+
 // Factory function create new Otter instance.
 func Factory(otterCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if otterCfg.Validate {
+		if err := core.ValidateConfig(otterCfg); err != nil {
+			logger.Errorf("Impossible to validate the Otter configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(otterCfg, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, otterCfg, logger)
+}
+
+func factory(otterCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	defaultStorageSize := 10_000
 	otterConfiguration := otterCfg.Configuration
 
@@ -49,9 +75,12 @@ func Factory(otterCfg core.CacheProvider, logger core.Logger, stale time.Duratio
 		cache := instance.(otter.CacheWithVariableTTL[string, []byte])
 
 		return &Otter{
-			cache:  &cache,
-			stale:  stale,
-			logger: logger,
+			cache:       &cache,
+			stale:       stale,
+			logger:      logger,
+			mapper:      core.ConfiguredMapper(otterCfg),
+			compression: core.ConfiguredCompression(otterCfg, logger),
+			clock:       core.ClockOrDefault(otterCfg.Clock),
 		}, nil
 	}
 
@@ -69,7 +98,7 @@ func Factory(otterCfg core.CacheProvider, logger core.Logger, stale time.Duratio
 	instanceMap.Store(defaultStorageSize, cache)
 	logger.Infof("otter.storage.size %d", defaultStorageSize)
 
-	return &Otter{cache: &cache, logger: logger, stale: stale}, nil
+	return &Otter{cache: &cache, logger: logger, mapper: core.ConfiguredMapper(otterCfg), compression: core.ConfiguredCompression(otterCfg, logger), clock: core.ClockOrDefault(otterCfg.Clock), stale: stale}, nil
 }
 
 // Name returns the storer name.
@@ -87,7 +116,7 @@ func (provider *Otter) MapKeys(prefix string) map[string]string {
 	keys := map[string]string{}
 
 	provider.cache.Range(func(key string, val []byte) bool {
-		if strings.HasPrefix(key, prefix) {
+		if strings.HasPrefix(key, prefix) && core.ListedKey(prefix, key) {
 			k, _ := strings.CutPrefix(key, prefix)
 			keys[k] = string(val)
 		}
@@ -104,7 +133,7 @@ func (provider *Otter) ListKeys() []string {
 
 	provider.cache.Range(func(key string, value []byte) bool {
 		if strings.HasPrefix(key, core.MappingKeyPrefix) {
-			mapping, err := core.DecodeMapping(value)
+			mapping, err := provider.mapper.Decode(value)
 			if err == nil {
 				for _, v := range mapping.GetMapping() {
 					keys = append(keys, v.GetRealKey())
@@ -118,6 +147,26 @@ func (provider *Otter) ListKeys() []string {
 	return keys
 }
 
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Otter) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	provider.cache.Range(func(key string, _ []byte) bool {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Otter) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Otter) Get(key string) []byte {
 	result, found := provider.cache.Get(key)
@@ -128,8 +177,68 @@ func (provider *Otter) Get(key string) []byte {
 	return result
 }
 
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Otter) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Otter) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Otter) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Otter) GetTTL(key string) (time.Duration, bool) {
+	entry, found := provider.cache.Extension().GetEntryQuietly(key)
+	if !found || entry.HasExpired() {
+		return 0, false
+	}
+
+	if entry.Expiration() == 0 {
+		return core.NoExpiration, true
+	}
+
+	return entry.TTL(), true
+}
+
+// Exists method will check the key in Otter provider without recording a hit.
+func (provider *Otter) Exists(key string) bool {
+	entry, found := provider.cache.Extension().GetEntryQuietly(key)
+
+	return found && !entry.HasExpired()
+}
+
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Otter) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Otter) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	val, found := provider.cache.Get(core.MappingKeyPrefix + key)
 	if !found {
 		provider.logger.Debugf("Impossible to get the mapping key %s in Otter", core.MappingKeyPrefix+key)
@@ -137,43 +246,46 @@ func (provider *Otter) GetMultiLevel(key string, req *http.Request, validator *c
 		return
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, val, req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
 
 	return
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
 func (provider *Otter) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+		return nil
+	}
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	now := provider.clock.Now()
 
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Otter, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Otter, %v", variedKey, err)
-
-		return err
-	}
-
-	inserted := provider.cache.Set(variedKey, compressed.Bytes(), duration)
+	inserted := provider.cache.Set(variedKey, compressed, duration)
 	if !inserted {
 		provider.logger.Errorf("Impossible to set value into Otter, too large for the cost function")
 
 		return nil
 	}
 
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Otter, %v", variedKey, err)
+
+		return err
+	}
+
 	mappingKey := core.MappingKeyPrefix + baseKey
 	item, _ := provider.cache.Get(mappingKey)
 
-	val, e := core.MappingUpdater(variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, e := core.MappingUpdaterWith(provider.mapper, variedKey, item, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if e != nil {
 		return e
 	}
@@ -205,21 +317,198 @@ func (provider *Otter) Set(key string, value []byte, duration time.Duration) err
 	return nil
 }
 
+// SetContext method will store the response unless the context is done.
+func (provider *Otter) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Otter provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Otter) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Otter provider only if the key doesn't exist yet.
+func (provider *Otter) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	return provider.cache.SetIfAbsent(key, value, duration), nil
+}
+
+// CompareAndSwap method will store the response in Otter provider only if the stored bytes equal old.
+func (provider *Otter) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	counterMutex.Lock()
+	defer counterMutex.Unlock()
+
+	current, _ := provider.cache.Get(key)
+	if !core.SwapMatches(current, old) {
+		return false, nil
+	}
+
+	if !provider.cache.Set(key, value, duration) {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Otter, too large for the cost function", key)
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Increment method will add delta to the counter stored in Otter provider.
+func (provider *Otter) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	counterMutex.Lock()
+	defer counterMutex.Unlock()
+
+	current, _ := provider.cache.Get(key)
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		duration = counterMaxTTL
+	}
+
+	if !provider.cache.Set(key, encoded, duration) {
+		provider.logger.Errorf("Impossible to increment the key %s into Otter, too large for the cost function", key)
+	}
+
+	return value, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Otter provider.
+func (provider *Otter) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Otter provider.
+// The compressed value is copied because Otter may keep a reference to the stored bytes.
+func (provider *Otter) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, bytes.Clone(compressed), duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Otter, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Otter) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Otter) Touch(key string, duration time.Duration) error {
+	value, found := provider.cache.Extension().GetQuietly(key)
+	if !found {
+		return core.ErrKeyNotFound
+	}
+
+	if !provider.cache.Set(key, value, duration) {
+		provider.logger.Errorf("Impossible to touch the key %s into Otter, too large for the cost function", key)
+	}
+
+	return nil
+}
+
 // Delete method will delete the response in Otter provider if exists corresponding to key param.
 func (provider *Otter) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	provider.cache.Delete(key)
 }
 
-// DeleteMany method will delete the responses in Otter provider if exists corresponding to the regex key param.
-func (provider *Otter) DeleteMany(key string) {
-	rgKey, e := regexp.Compile(key)
-	if e != nil {
-		return
+// DeleteMany method will delete the responses in Otter provider if exists corresponding to the prefix pattern param.
+func (provider *Otter) DeleteMany(pattern string) {
+	prefix := core.KeyPrefix(pattern)
+
+	provider.cache.DeleteByFunc(func(k string, _ []byte) bool {
+		return strings.HasPrefix(k, prefix)
+	})
+}
+
+// DeleteKeys method will delete the keys in Otter provider one by one, the missing keys are ignored.
+func (provider *Otter) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
 	}
 
-	provider.cache.DeleteByFunc(func(k string, value []byte) bool {
-		return rgKey.MatchString(k)
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Otter provider.
+func (provider *Otter) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Otter provider.
+func (provider *Otter) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Otter provider.
+func (provider *Otter) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Otter provider without altering it.
+func (provider *Otter) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Otter provider and its metadata under a parallel key.
+func (provider *Otter) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Otter provider, core.ErrKeyNotFound if none.
+func (provider *Otter) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number of entries and the size of the stored keys and values.
+func (provider *Otter) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{KeyCount: int64(provider.cache.Size())}
+
+	provider.cache.Range(func(key string, value []byte) bool {
+		stats.ApproxSizeBytes += int64(len(key) + len(value))
+
+		return true
 	})
+
+	return stats, nil
+}
+
+// Ping method will only check the context, the in-memory cache is always reachable.
+func (provider *Otter) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Export method will stream every key of the Otter provider paginating the scanned keys.
+func (provider *Otter) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Otter provider by batches.
+func (provider *Otter) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
 }
 
 // Init method will.
@@ -233,3 +522,10 @@ func (provider *Otter) Reset() error {
 
 	return nil
 }
+
+// Close method will close the Otter cache.
+func (provider *Otter) Close() error {
+	provider.cache.Close()
+
+	return nil
+}
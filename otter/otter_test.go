@@ -2,6 +2,8 @@ package otter_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -183,3 +185,42 @@ func TestOtter_SetMultiLevel_LargeValue(t *testing.T) {
 			len(largeValue), len(retrieved), float64(len(retrieved))/float64(len(largeValue))*100)
 	}
 }
+
+func TestOtter_Stats(t *testing.T) {
+	client, _ := getOtterInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestOtter_MaxValueSize(t *testing.T) {
+	client, _ := otter.Factory(core.CacheProvider{MaxValueSize: 1024}, zap.NewNop().Sugar(), 0)
+
+	atLimit := bytes.Repeat([]byte("a"), 1024)
+	if err := client.SetMultiLevel("LimitKey", "LimitKey", atLimit, http.Header{}, "", time.Minute, "LimitKey"); err != nil {
+		t.Errorf("A value at the limit should be stored, %v provided", err)
+	}
+
+	overLimit := bytes.Repeat([]byte("a"), 1025)
+	if err := client.SetMultiLevel("OverLimitKey", "OverLimitKey", overLimit, http.Header{}, "", time.Minute, "OverLimitKey"); !errors.Is(err, core.ErrValueTooLarge) {
+		t.Errorf("A value over the limit should return ErrValueTooLarge, %v provided", err)
+	}
+
+	if err := client.SetStream("OverLimitKey", bytes.NewReader(overLimit), time.Minute); !errors.Is(err, core.ErrValueTooLarge) {
+		t.Errorf("A stream over the limit should return ErrValueTooLarge, %v provided", err)
+	}
+
+	if res := client.Get("OverLimitKey"); res != nil {
+		t.Errorf("The value over the limit shouldn't be stored, %s provided", res)
+	}
+}
@@ -0,0 +1,1045 @@
+// Package postgres stores the cache entries in a PostgreSQL table shared by the application replicas.
+//
+// Each instance may keep the values it reads in a local read-through cache, enabled with the
+// local_cache_ttl configuration. The writes then NOTIFY the other instances which LISTEN to
+// evict the changed keys from their own local cache, all the instances sharing the table must
+// enable it to publish their writes. The notifications sent while an instance is disconnected
+// are lost, its local cache is cleared on reconnection.
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// DefaultPurgeInterval is the default interval between two expired rows purges.
+	DefaultPurgeInterval = time.Minute
+	// DefaultLocalCacheSize is the default maximum number of entries of the local read-through cache.
+	DefaultLocalCacheSize = 10_000
+
+	// The C collation makes the prefix queries use the primary key index and sorts the keys like Go.
+	createTable = `CREATE TABLE IF NOT EXISTS souin_cache (key TEXT COLLATE "C" PRIMARY KEY, value BYTEA NOT NULL, expires_at TIMESTAMPTZ);
+CREATE INDEX IF NOT EXISTS souin_cache_expires_at ON souin_cache (expires_at)`
+	notExpired = `(expires_at IS NULL OR expires_at > now())`
+	// The NULL duration gives a NULL expiry, the key is then kept without expiration.
+	expiry = `now() + $3::bigint * interval '1 microsecond'`
+	upsert = `INSERT INTO souin_cache (key, value, expires_at) VALUES ($1, $2, ` + expiry + `)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`
+	remainingTTL = `(EXTRACT(EPOCH FROM expires_at - now()) * 1000000)::bigint`
+	likeEscape   = `\`
+
+	channel = "souin_cache_invalidation"
+	// A NOTIFY payload is limited to 8000 bytes, a longer key invalidates the whole local cache.
+	maxPayloadSize = 7999
+	keyPayload     = "k"
+	prefixPayload  = "p"
+	resetPayload   = "*"
+)
+
+// Postgres provider type.
+type Postgres struct {
+	*pgxpool.Pool
+
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	local       *localCache
+	compression string
+	clock       core.Clock
+	cancel      context.CancelFunc
+	done        sync.WaitGroup
+	closed      atomic.Bool
+}
+
+// Factory function create new Postgres instance.
+func Factory(postgresConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if postgresConfiguration.Validate {
+		if err := core.ValidateConfig(postgresConfiguration, checkURL); err != nil {
+			logger.Errorf("Impossible to validate the Postgres configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(postgresConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, postgresConfiguration, logger)
+}
+
+// configuredURL returns the url of the configuration map or of the cache provider.
+func configuredURL(postgresConfiguration core.CacheProvider) string {
+	if pc, ok := postgresConfiguration.Configuration.(map[string]interface{}); ok && pc != nil {
+		if v, found := pc["url"]; found && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+
+	return postgresConfiguration.URL
+}
+
+// checkURL checks the url is given and parses as a connection string, see core.ValidateConfig.
+// The url isn't reported since it may hold the password.
+func checkURL(postgresConfiguration core.CacheProvider) error {
+	url := configuredURL(postgresConfiguration)
+	if url == "" {
+		return fmt.Errorf("%w: no postgres url given", core.ErrInvalidConfig)
+	}
+
+	if _, err := pgxpool.ParseConfig(url); err != nil {
+		return fmt.Errorf("%w: the postgres url can't be parsed", core.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+func factory(postgresConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	url := configuredURL(postgresConfiguration)
+	purgeInterval := DefaultPurgeInterval
+	localCacheTTL := time.Duration(0)
+	localCacheSize := DefaultLocalCacheSize
+
+	if pc, ok := postgresConfiguration.Configuration.(map[string]interface{}); ok && pc != nil {
+		if v, found := pc["purge_interval"]; found && v != nil {
+			if d, err := time.ParseDuration(fmt.Sprint(v)); err == nil && d > 0 {
+				purgeInterval = d
+			}
+		}
+
+		if v, found := pc["local_cache_ttl"]; found && v != nil {
+			if d, err := time.ParseDuration(fmt.Sprint(v)); err == nil {
+				localCacheTTL = d
+			}
+		}
+
+		if v, found := pc["local_cache_size"]; found && v != nil {
+			if size, err := strconv.Atoi(fmt.Sprint(v)); err == nil && size > 0 {
+				localCacheSize = size
+			}
+		}
+	}
+
+	if url == "" {
+		err := errors.New("no postgres url given")
+		logger.Error("Impossible to connect to the Postgres DB.", err)
+
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		cancel()
+		logger.Error("Impossible to open the Postgres DB.", err)
+
+		return nil, err
+	}
+
+	if _, err = pool.Exec(ctx, createTable); err != nil {
+		cancel()
+		pool.Close()
+		logger.Error("Impossible to create the Postgres cache table.", err)
+
+		return nil, err
+	}
+
+	i := &Postgres{
+		Pool:        pool,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(postgresConfiguration),
+		stale:       stale,
+		compression: core.ConfiguredCompression(postgresConfiguration, logger),
+		clock:       core.ClockOrDefault(postgresConfiguration.Clock),
+		cancel:      cancel,
+	}
+
+	i.done.Add(1)
+
+	go i.purge(ctx, purgeInterval)
+
+	if localCacheTTL > 0 {
+		i.local = newLocalCache(localCacheTTL, localCacheSize)
+		i.done.Add(1)
+
+		go i.listen(ctx)
+	}
+
+	return i, nil
+}
+
+// purge deletes the expired rows periodically, Get already ignores them in between.
+func (provider *Postgres) purge(ctx context.Context, interval time.Duration) {
+	defer provider.done.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := provider.Exec(ctx, `DELETE FROM souin_cache WHERE expires_at <= now()`); err != nil && ctx.Err() == nil {
+				provider.logger.Errorf("Impossible to purge the expired keys in Postgres, %v", err)
+			}
+		}
+	}
+}
+
+// listen evicts the keys notified by the other instances from the local cache until the context is done.
+func (provider *Postgres) listen(ctx context.Context) {
+	defer provider.done.Done()
+
+	for {
+		err := provider.waitNotifications(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		provider.logger.Errorf("The Postgres invalidation listener is disconnected, %v", err)
+
+		// The notifications sent until the next LISTEN are lost.
+		provider.local.clear()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (provider *Postgres) waitNotifications(ctx context.Context) error {
+	pooled, err := provider.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The listening connection is never given back to the pool.
+	conn := pooled.Hijack()
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err = conn.Exec(ctx, `LISTEN `+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	provider.local.clear()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		payload := notification.Payload
+
+		switch {
+		case strings.HasPrefix(payload, keyPayload):
+			provider.local.evict(strings.TrimPrefix(payload, keyPayload))
+		case strings.HasPrefix(payload, prefixPayload):
+			provider.local.evictPrefix(strings.TrimPrefix(payload, prefixPayload))
+		default:
+			provider.local.clear()
+		}
+	}
+}
+
+// invalidate evicts the key from the local cache and notifies the other instances.
+func (provider *Postgres) invalidate(kind, key string) {
+	if provider.local == nil {
+		return
+	}
+
+	payload := kind + key
+
+	switch kind {
+	case keyPayload:
+		provider.local.evict(key)
+	case prefixPayload:
+		provider.local.evictPrefix(key)
+	default:
+		provider.local.clear()
+	}
+
+	if len(payload) > maxPayloadSize {
+		payload = resetPayload
+	}
+
+	if _, err := provider.Exec(context.Background(), `SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+		provider.logger.Errorf("Impossible to notify the invalidation of the key %s in Postgres, %v", key, err)
+	}
+}
+
+// expiresIn converts the duration to the stored microseconds, nil means no expiration.
+func expiresIn(duration time.Duration) *int64 {
+	if duration <= 0 {
+		return nil
+	}
+
+	microseconds := duration.Microseconds()
+
+	return &microseconds
+}
+
+// likePrefix escapes the LIKE wildcards of the prefix.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").Replace(prefix)
+}
+
+// Name returns the storer name.
+func (provider *Postgres) Name() string {
+	return "POSTGRES"
+}
+
+// Uuid returns an unique identifier.
+func (provider *Postgres) Uuid() string {
+	config := provider.Config().ConnConfig
+
+	return fmt.Sprintf("%s:%d/%s-%s", config.Host, config.Port, config.Database, provider.stale)
+}
+
+// MapKeys method returns a map with the key and value.
+func (provider *Postgres) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	rows, err := provider.Query(
+		context.Background(),
+		`SELECT key, value FROM souin_cache WHERE key LIKE $1 || '%' ESCAPE '`+likeEscape+`' AND `+notExpired,
+		likePrefix(prefix),
+	)
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys matching %s in Postgres, %v", prefix, err)
+
+		return keys
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err = rows.Scan(&key, &value); err == nil && core.ListedKey(prefix, key) {
+			keys[strings.TrimPrefix(key, prefix)] = string(value)
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *Postgres) ListKeys() []string {
+	keys := []string{}
+
+	for _, val := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(val))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Postgres) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	query := `SELECT key FROM souin_cache WHERE key LIKE $1 || '%' ESCAPE '` + likeEscape + `' AND key >= $2 AND ` + notExpired + ` ORDER BY key`
+	args := []interface{}{likePrefix(prefix), max(prefix, cursor)}
+
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit+1)
+	}
+
+	rows, err := provider.Query(context.Background(), query, args...)
+	if err != nil {
+		provider.logger.Errorf("Impossible to scan the keys matching %s in Postgres, %v", prefix, err)
+
+		return keys, next
+	}
+
+	defer rows.Close()
+
+	read, last := 0, ""
+
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			continue
+		}
+
+		read, last = read+1, key
+
+		if !core.ListedKey(prefix, key) {
+			continue
+		}
+
+		if limit > 0 && len(keys) == limit {
+			next = key
+
+			break
+		}
+
+		keys = append(keys, key)
+	}
+
+	// The skipped internal keys shortened the page, the scan resumes after the last key read.
+	if next == "" && limit > 0 && read > limit {
+		next = core.NextKey(last)
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Postgres) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *Postgres) Get(key string) []byte {
+	result, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in Postgres, %v", key, err)
+	}
+
+	return result
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+// The value is read from the local cache first when enabled.
+func (provider *Postgres) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if value, found := provider.local.get(key); found {
+		return value, nil
+	}
+
+	generation := provider.local.generation()
+
+	var (
+		result    []byte
+		expiresAt *time.Time
+	)
+
+	err := provider.QueryRow(ctx, `SELECT value, expires_at FROM souin_cache WHERE key = $1 AND `+notExpired, key).Scan(&result, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider.local.set(key, result, expiresAt, generation)
+
+	return result, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Postgres) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys using a single query.
+func (provider *Postgres) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	rows, err := provider.Query(context.Background(), `SELECT key, value FROM souin_cache WHERE key = ANY($1) AND `+notExpired, keys)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys from Postgres, %v", err)
+
+		return result
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err = rows.Scan(&key, &value); err == nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Postgres) GetTTL(key string) (time.Duration, bool) {
+	var microseconds *int64
+
+	err := provider.QueryRow(context.Background(), `SELECT `+remainingTTL+` FROM souin_cache WHERE key = $1 AND `+notExpired, key).Scan(&microseconds)
+	if err != nil {
+		return 0, false
+	}
+
+	if microseconds == nil {
+		return core.NoExpiration, true
+	}
+
+	return time.Duration(*microseconds) * time.Microsecond, true
+}
+
+// Exists method will check the key in Postgres provider without selecting its value.
+func (provider *Postgres) Exists(key string) bool {
+	var found int
+
+	err := provider.QueryRow(context.Background(), `SELECT 1 FROM souin_cache WHERE key = $1 AND `+notExpired, key).Scan(&found)
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *Postgres) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Postgres) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in Postgres", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *Postgres) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into Postgres, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Postgres, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in Postgres, %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in Postgres", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in Postgres provider.
+func (provider *Postgres) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response in Postgres provider unless the context is done.
+// A non-positive duration keeps the key without expiration.
+func (provider *Postgres) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.Exec(ctx, upsert, key, value, expiresIn(duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into Postgres, %v", err)
+
+		return err
+	}
+
+	provider.invalidate(keyPayload, key)
+
+	return nil
+}
+
+// SetMany method will store the entries in Postgres provider using a single batch in a transaction.
+func (provider *Postgres) SetMany(items map[string]core.Entry) error {
+	batch := &pgx.Batch{}
+
+	for key, item := range items {
+		batch.Queue(upsert, key, item.Value, expiresIn(item.Duration))
+	}
+
+	err := pgx.BeginFunc(context.Background(), provider, func(tx pgx.Tx) error {
+		return tx.SendBatch(context.Background(), batch).Close()
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into Postgres, %v", err)
+
+		return err
+	}
+
+	for key := range items {
+		provider.invalidate(keyPayload, key)
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Postgres provider only if the key doesn't exist yet.
+// An expired row is replaced as if it didn't exist.
+func (provider *Postgres) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	tag, err := provider.Exec(context.Background(), `INSERT INTO souin_cache (key, value, expires_at) VALUES ($1, $2, `+expiry+`)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at
+WHERE souin_cache.expires_at IS NOT NULL AND souin_cache.expires_at <= now()`, key, value, expiresIn(duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Postgres, %v", key, err)
+
+		return false, err
+	}
+
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+
+	provider.invalidate(keyPayload, key)
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Postgres provider only if the stored bytes equal old.
+// The comparison is part of the update statement, a nil old value creates the key like SetNX.
+func (provider *Postgres) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	tag, err := provider.Exec(context.Background(), `UPDATE souin_cache SET value = $2, expires_at = `+expiry+`
+WHERE key = $1 AND value = $4 AND `+notExpired, key, value, expiresIn(duration), old)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Postgres, %v", key, err)
+
+		return false, err
+	}
+
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+
+	provider.invalidate(keyPayload, key)
+
+	return true, nil
+}
+
+// Increment method will add delta to the counter stored in Postgres provider within a single transaction.
+// A transaction level advisory lock on the key serializes the concurrent increments, even of a missing key.
+func (provider *Postgres) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	var value int64
+
+	err := pgx.BeginFunc(context.Background(), provider, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+			return err
+		}
+
+		var current []byte
+
+		err := tx.QueryRow(context.Background(), `SELECT value FROM souin_cache WHERE key = $1 AND `+notExpired, key).Scan(&current)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		var encoded []byte
+		if value, encoded, err = core.AddCounter(current, delta); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(context.Background(), upsert, key, encoded, expiresIn(duration))
+
+		return err
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into Postgres, %v", key, err)
+
+		return 0, err
+	}
+
+	provider.invalidate(keyPayload, key)
+
+	return value, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Postgres provider.
+func (provider *Postgres) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Postgres provider.
+func (provider *Postgres) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Postgres, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Postgres) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Postgres) Touch(key string, duration time.Duration) error {
+	tag, err := provider.Exec(context.Background(), `UPDATE souin_cache SET expires_at = now() + $2::bigint * interval '1 microsecond' WHERE key = $1 AND `+notExpired, key, expiresIn(duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Postgres, %v", key, err)
+
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrKeyNotFound
+	}
+
+	provider.invalidate(keyPayload, key)
+
+	return nil
+}
+
+// Delete method will delete the response in Postgres provider if exists corresponding to key param.
+func (provider *Postgres) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if _, err := provider.Exec(context.Background(), `DELETE FROM souin_cache WHERE key = $1`, key); err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in Postgres, %v", key, err)
+
+		return
+	}
+
+	provider.invalidate(keyPayload, key)
+}
+
+// DeleteMany method will delete the responses in Postgres provider if exists corresponding to the prefix pattern param.
+func (provider *Postgres) DeleteMany(pattern string) {
+	prefix := core.KeyPrefix(pattern)
+
+	_, err := provider.Exec(context.Background(), `DELETE FROM souin_cache WHERE key LIKE $1 || '%' ESCAPE '`+likeEscape+`'`, likePrefix(prefix))
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in Postgres, %v", pattern, err)
+
+		return
+	}
+
+	provider.invalidate(prefixPayload, prefix)
+}
+
+// DeleteKeys method will delete the keys with their metadata in Postgres provider using a single statement.
+// The missing keys are ignored.
+func (provider *Postgres) DeleteKeys(keys []string) error {
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+
+	if _, err := provider.Exec(context.Background(), `DELETE FROM souin_cache WHERE key = ANY($1)`, deleted); err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in Postgres, %v", err)
+
+		return err
+	}
+
+	for _, key := range deleted {
+		provider.invalidate(keyPayload, key)
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Postgres provider.
+func (provider *Postgres) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Postgres provider.
+func (provider *Postgres) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Postgres provider.
+func (provider *Postgres) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Postgres provider without altering it.
+func (provider *Postgres) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Postgres provider and its metadata under a parallel key.
+func (provider *Postgres) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Postgres provider, core.ErrKeyNotFound if none.
+func (provider *Postgres) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number of live keys and the total size of the table with its indexes.
+func (provider *Postgres) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+
+	err := provider.QueryRow(
+		context.Background(),
+		`SELECT COUNT(*) FILTER (WHERE `+notExpired+`), pg_total_relation_size('souin_cache') FROM souin_cache`,
+	).Scan(&stats.KeyCount, &stats.ApproxSizeBytes)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Postgres stats, %v", err)
+	}
+
+	return stats, err
+}
+
+// Ping method will check the Postgres DB is reachable.
+func (provider *Postgres) Ping(ctx context.Context) error {
+	if provider.closed.Load() {
+		return core.ErrClosed
+	}
+
+	return provider.Pool.Ping(ctx)
+}
+
+// Export method will stream every unexpired key of the Postgres provider with a single query.
+func (provider *Postgres) Export(w io.Writer) error {
+	rows, err := provider.Query(context.Background(), `SELECT key, value, `+remainingTTL+` FROM souin_cache WHERE `+notExpired)
+	if err != nil {
+		provider.logger.Errorf("Impossible to export the Postgres keys, %v", err)
+
+		return err
+	}
+
+	defer rows.Close()
+
+	exporter := core.NewExportWriter(w)
+
+	for rows.Next() {
+		var (
+			key          string
+			value        []byte
+			microseconds *int64
+		)
+
+		if err = rows.Scan(&key, &value, &microseconds); err != nil {
+			provider.logger.Errorf("Impossible to export the Postgres keys, %v", err)
+
+			return err
+		}
+
+		ttl := core.NoExpiration
+		if microseconds != nil {
+			ttl = time.Duration(*microseconds) * time.Microsecond
+		}
+
+		if err = exporter.Write(key, value, ttl); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		provider.logger.Errorf("Impossible to export the Postgres keys, %v", err)
+
+		return err
+	}
+
+	return exporter.Flush()
+}
+
+// Import method will store the exported records in Postgres provider by transactions.
+func (provider *Postgres) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *Postgres) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *Postgres) Reset() error {
+	if _, err := provider.Exec(context.Background(), `DELETE FROM souin_cache`); err != nil {
+		return err
+	}
+
+	provider.invalidate(resetPayload, "")
+
+	return nil
+}
+
+// Close method will stop the purge and listen loops and close the connections pool.
+func (provider *Postgres) Close() error {
+	if !provider.closed.CompareAndSwap(false, true) {
+		return core.ErrClosed
+	}
+
+	provider.cancel()
+	provider.done.Wait()
+	provider.Pool.Close()
+
+	return nil
+}
+
+type localEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// localCache is the read-through cache of an instance, each entry is kept for the ttl at most.
+// The generation changes on every eviction so a value read before an invalidation isn't cached.
+type localCache struct {
+	mu      sync.RWMutex
+	entries map[string]localEntry
+	ttl     time.Duration
+	size    int
+	gen     uint64
+}
+
+func newLocalCache(ttl time.Duration, size int) *localCache {
+	return &localCache{entries: make(map[string]localEntry), ttl: ttl, size: size}
+}
+
+func (c *localCache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, found := c.entries[key]
+	if !found || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (c *localCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.gen
+}
+
+// set stores the value unless an eviction happened since the given generation.
+func (c *localCache) set(key string, value []byte, rowExpiresAt *time.Time, generation uint64) {
+	if c == nil {
+		return
+	}
+
+	expiresAt := time.Now().Add(c.ttl)
+	if rowExpiresAt != nil && rowExpiresAt.Before(expiresAt) {
+		expiresAt = *rowExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != generation {
+		return
+	}
+
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.size {
+		// Drop an arbitrary entry, the map iteration order is random.
+		for k := range c.entries {
+			delete(c.entries, k)
+
+			break
+		}
+	}
+
+	c.entries[key] = localEntry{value: value, expiresAt: expiresAt}
+}
+
+func (c *localCache) evict(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	delete(c.entries, key)
+}
+
+func (c *localCache) evictPrefix(prefix string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *localCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.entries = make(map[string]localEntry)
+}
@@ -6,12 +6,16 @@ replace github.com/darkweak/storages/core => ../core
 
 require (
 	github.com/darkweak/storages/core v0.0.18
-	github.com/pierrec/lz4/v4 v4.1.23
 	github.com/redis/rueidis v1.0.54
 	go.uber.org/zap v1.27.0
 )
 
 require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.23 // indirect
+	go.opentelemetry.io/otel v1.28.0 // indirect
+	go.opentelemetry.io/otel/trace v1.28.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 	google.golang.org/protobuf v1.36.5 // indirect
@@ -3,31 +3,160 @@ package redis
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/darkweak/storages/core"
-	"github.com/pierrec/lz4/v4"
 	redis "github.com/redis/rueidis"
 )
 
+// incrementScript adds ARGV[1] to the 8 bytes big-endian counter and sets its ARGV[2] milliseconds expiry.
+// It returns nil when the value isn't a counter, the Lua numbers being doubles the counters are exact up to 2^53.
+const incrementScript = `local current = redis.call('GET', KEYS[1])
+local value = 0
+if current then
+  if #current ~= 8 then
+    return false
+  end
+  value = struct.unpack('>i8', current)
+end
+value = value + tonumber(ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+  redis.call('SET', KEYS[1], struct.pack('>i8', value), 'PX', ARGV[2])
+else
+  redis.call('SET', KEYS[1], struct.pack('>i8', value))
+end
+return value`
+
+var increment = redis.NewLuaScript(incrementScript)
+
+// compareAndSwapScript sets ARGV[2] with its ARGV[3] milliseconds expiry when the value is ARGV[1].
+// It returns 1 when the value has been swapped and 0 otherwise.
+const compareAndSwapScript = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+  return 0
+end
+if tonumber(ARGV[3]) > 0 then
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+  redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1`
+
+var compareAndSwap = redis.NewLuaScript(compareAndSwapScript)
+
+// keyspaceEvents are the notify-keyspace-events flags required by Watch, the keyspace channel (K) with the
+// generic (g), string ($), expired (x) and evicted (e) events. The A flag stands for every event class.
+const keyspaceEvents = "Kg$xe"
+
+// defaultPort completes the configured addresses without port.
+const defaultPort = "6379"
+
+// keyspaceEventTypes maps the keyspace notifications changing the values to the Watch events, the other ones
+// as the expiry updates are ignored.
+var keyspaceEventTypes = map[string]core.EventType{
+	"set":         core.EventSet,
+	"setrange":    core.EventSet,
+	"append":      core.EventSet,
+	"incrby":      core.EventSet,
+	"incrbyfloat": core.EventSet,
+	"rename_to":   core.EventSet,
+	"copy_to":     core.EventSet,
+	"restore":     core.EventSet,
+	"del":         core.EventDelete,
+	"rename_from": core.EventDelete,
+	"expired":     core.EventDelete,
+	"evicted":     core.EventDelete,
+}
+
+// missingKeyspaceEvents returns the configured notify-keyspace-events flags completed with the ones required
+// by Watch, empty when none is missing.
+func missingKeyspaceEvents(configured string) string {
+	flags := configured
+
+	for _, flag := range keyspaceEvents {
+		if !strings.ContainsRune(flags, flag) && (flag == 'K' || !strings.ContainsRune(flags, 'A')) {
+			flags += string(flag)
+		}
+	}
+
+	if flags == configured {
+		return ""
+	}
+
+	return flags
+}
+
+// keyspacePattern returns the PSUBSCRIBE pattern of the keys starting with the prefix in the database, the
+// glob characters of the prefix are escaped.
+func keyspacePattern(database int, prefix string) string {
+	var pattern strings.Builder
+
+	pattern.WriteString(keyspaceChannel(database))
+
+	for _, r := range prefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			pattern.WriteByte('\\')
+		}
+
+		pattern.WriteRune(r)
+	}
+
+	pattern.WriteByte('*')
+
+	return pattern.String()
+}
+
+// keyspaceChannel returns the prefix of the keyspace notifications channels of the database.
+func keyspaceChannel(database int) string {
+	return fmt.Sprintf("__keyspace@%d__:", database)
+}
+
 // Redis provider type.
 type Redis struct {
 	inClient      redis.Client
 	stale         time.Duration
 	ctx           context.Context
 	logger        core.Logger
+	mapper        core.Mapper
+	compression   string
+	clock         core.Clock
 	configuration redis.ClientOption
 	close         func()
 	hashtags      string
+	watches       context.Context
+	stopWatches   context.CancelFunc
 }
 
 // Factory function create new Redis instance.
 func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if redisConfiguration.Validate {
+		if err := core.ValidateConfig(redisConfiguration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the Redis configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(redisConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, redisConfiguration, logger)
+}
+
+func factory(redisConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	var options redis.ClientOption
 
 	var hashtags string
@@ -38,23 +167,61 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 	}
 
 	if redisConfiguration.Configuration != nil {
+		redisConfigMap, _ := redisConfiguration.Configuration.(map[string]interface{})
+
+		// The URL carries the address, the credentials and the TLS mode, the other keys override it.
+		if value, ok := redisConfigMap["url"].(string); ok && value != "" {
+			if options, err = redis.ParseURL(value); err != nil {
+				logger.Errorf("Impossible to parse the redis url %s: %v", value, err)
+
+				return nil, err
+			}
+		}
+
 		if err := json.Unmarshal(redisConfig, &options); err != nil {
 			logger.Infof("Cannot parse your redis configuration: %+v", err)
 		}
 
-		if redisConfig, ok := redisConfiguration.Configuration.(map[string]interface{}); ok && redisConfig != nil {
-			if value, ok := redisConfig["HashTag"]; ok {
-				if v, ok := value.(string); ok {
-					hashtags = v
-				}
+		if value, ok := redisConfigMap["HashTag"]; ok {
+			if v, ok := value.(string); ok {
+				hashtags = v
 			}
 		}
+
+		if value, ok := redisConfigMap["tls"].(map[string]interface{}); ok && value != nil {
+			if options.TLSConfig, err = tlsConfig(value, options.TLSConfig); err != nil {
+				logger.Errorf("Impossible to load the redis TLS configuration: %v", err)
+
+				return nil, err
+			}
+		}
+	} else if isURL(redisConfiguration.URL) {
+		if options, err = redis.ParseURL(redisConfiguration.URL); err != nil {
+			logger.Errorf("Impossible to parse the redis url %s: %v", redisConfiguration.URL, err)
+
+			return nil, err
+		}
+
+		options.ClientName = "souin-redis"
 	} else {
+		addresses, network, err := core.ParseAddresses(redisConfiguration.URL, defaultPort)
+		if err != nil {
+			logger.Errorf("Impossible to parse the redis addresses %s, %v", redisConfiguration.URL, err)
+
+			return nil, err
+		}
+
 		options = redis.ClientOption{
-			InitAddress: strings.Split(redisConfiguration.URL, ","),
+			InitAddress: addresses,
 			SelectDB:    0,
 			ClientName:  "souin-redis",
 		}
+
+		if network == "unix" {
+			options.DialFn = func(address string, dialer *net.Dialer, _ *tls.Config) (net.Conn, error) {
+				return dialer.Dial(network, address)
+			}
+		}
 	}
 
 	if options.Dialer.Timeout == 0 {
@@ -70,17 +237,78 @@ func Factory(redisConfiguration core.CacheProvider, logger core.Logger, stale ti
 		return nil, err
 	}
 
+	watches, stopWatches := context.WithCancel(context.Background())
+
 	return &Redis{
 		inClient:      cli,
 		ctx:           context.Background(),
 		stale:         stale,
 		configuration: options,
 		logger:        logger,
+		mapper:        core.ConfiguredMapper(redisConfiguration),
+		compression:   core.ConfiguredCompression(redisConfiguration, logger),
+		clock:         core.ClockOrDefault(redisConfiguration.Clock),
 		close:         cli.Close,
 		hashtags:      hashtags,
+		watches:       watches,
+		stopWatches:   stopWatches,
 	}, err
 }
 
+func isURL(url string) bool {
+	for _, scheme := range []string{"redis://", "rediss://", "unix://"} {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tlsConfig builds the client TLS configuration from the cert_file, key_file, ca_file,
+// server_name and insecure_skip_verify keys on top of the one parsed from the URL if any.
+func tlsConfig(configuration map[string]interface{}, base *tls.Config) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		config = base.Clone()
+	}
+
+	certFile, _ := configuration["cert_file"].(string)
+	keyFile, _ := configuration["key_file"].(string)
+
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if caFile, _ := configuration["ca_file"].(string); caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in the CA file %s", caFile)
+		}
+	}
+
+	if serverName, _ := configuration["server_name"].(string); serverName != "" {
+		config.ServerName = serverName
+	}
+
+	if skip, ok := configuration["insecure_skip_verify"].(bool); ok {
+		//nolint:gosec
+		config.InsecureSkipVerify = skip
+	}
+
+	return config, nil
+}
+
 // Name returns the storer name.
 func (provider *Redis) Name() string {
 	return "REDIS"
@@ -116,13 +344,13 @@ func (provider *Redis) ListKeys() []string {
 		for _, element := range scan.Elements {
 			value := provider.Get(element)
 
-			mapping, err := core.DecodeMapping(value)
+			mapping, err := provider.mapper.Decode(value)
 			if err != nil {
 				continue
 			}
 
 			for _, v := range mapping.GetMapping() {
-				if v.GetFreshTime().AsTime().Before(time.Now()) && v.GetStaleTime().AsTime().Before(time.Now()) {
+				if v.GetFreshTime().AsTime().Before(provider.clock.Now()) && v.GetStaleTime().AsTime().Before(provider.clock.Now()) {
 					continue
 				}
 
@@ -154,6 +382,10 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 	}
 
 	for _, key := range elements {
+		if !provider.listed(prefix, key) {
+			continue
+		}
+
 		k, _ := strings.CutPrefix(key, prefix)
 		kvStore[k] = string(provider.Get(key))
 	}
@@ -163,39 +395,49 @@ func (provider *Redis) MapKeys(prefix string) map[string]string {
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Redis) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Redis) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	b, e := provider.inClient.Do(provider.ctx, provider.inClient.B().Get().Key(provider.hashtags+core.MappingKeyPrefix+key).Build()).AsBytes()
 	if e != nil {
 		return
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, b, req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, b, req, validator, provider.logger)
 
 	return
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
 func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(provider.hashtags + variedKey)
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+		return nil
+	}
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	now := provider.clock.Now()
 
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Redis, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Redis, %v", variedKey, err)
+	if err := provider.inClient.Do(provider.ctx, provider.inClient.B().Set().Key(provider.hashtags+variedKey).Value(string(compressed)).Ex(duration+provider.stale).Build()).Error(); err != nil {
+		provider.logger.Errorf("Impossible to set value into Redis, %v", err)
 
 		return err
 	}
 
-	if err := provider.inClient.Do(provider.ctx, provider.inClient.B().Set().Key(provider.hashtags+variedKey).Value(compressed.String()).Ex(duration+provider.stale).Build()).Error(); err != nil {
-		provider.logger.Errorf("Impossible to set value into Redis, %v", err)
+	if err = core.IndexSurrogateKeys(provider, provider.hashtags+variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Redis, %v", variedKey, err)
 
 		return err
 	}
@@ -207,7 +449,7 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 		return err
 	}
 
-	val, err := core.MappingUpdater(provider.hashtags+variedKey, v, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, err := core.MappingUpdaterWith(provider.mapper, provider.hashtags+variedKey, v, provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if err != nil {
 		return err
 	}
@@ -219,6 +461,41 @@ func (provider *Redis) SetMultiLevel(baseKey, variedKey string, value []byte, va
 	return err
 }
 
+// listed returns true when MapKeys and ScanKeys list the key, the internal keys are prefixed by the hashtags.
+func (provider *Redis) listed(prefix, key string) bool {
+	return core.ListedKey(strings.TrimPrefix(prefix, provider.hashtags), strings.TrimPrefix(key, provider.hashtags))
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Redis) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	var scan redis.ScanEntry
+
+	var err error
+
+	keys = []string{}
+
+	for more := true; more; more = scan.Cursor != 0 {
+		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(prefix+"*").Build()).AsScanEntry(); err != nil {
+			provider.logger.Errorf("Cannot scan: %v", err)
+
+			return []string{}, ""
+		}
+
+		for _, key := range scan.Elements {
+			if provider.listed(prefix, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return core.PaginateKeys(keys, prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Redis) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
 // Get method returns the populated response if exists, empty response then.
 func (provider *Redis) Get(key string) []byte {
 	r, e := provider.inClient.Do(provider.ctx, provider.inClient.B().Get().Key(key).Build()).AsBytes()
@@ -229,6 +506,59 @@ func (provider *Redis) Get(key string) []byte {
 	return r
 }
 
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Redis) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Redis) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Redis) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Redis) GetTTL(key string) (time.Duration, bool) {
+	ttl, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Pttl().Key(key).Build()).AsInt64()
+	if err != nil || ttl == -2 {
+		return 0, false
+	}
+
+	if ttl == -1 {
+		return core.NoExpiration, true
+	}
+
+	return time.Duration(ttl) * time.Millisecond, true
+}
+
+// Exists method will check the key in Redis provider using the EXISTS command.
+func (provider *Redis) Exists(key string) bool {
+	count, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Exists().Key(key).Build()).AsInt64()
+
+	return err == nil && count > 0
+}
+
 // Set method will store the response in Etcd provider.
 func (provider *Redis) Set(key string, value []byte, duration time.Duration) error {
 	var cmd redis.Completed
@@ -246,13 +576,149 @@ func (provider *Redis) Set(key string, value []byte, duration time.Duration) err
 	return err
 }
 
+// SetContext method will store the response unless the context is done.
+func (provider *Redis) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Redis provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Redis) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in Redis provider only if the key doesn't exist yet.
+func (provider *Redis) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	var cmd redis.Completed
+	if duration == -1 {
+		cmd = provider.inClient.B().Set().Key(key).Value(string(value)).Nx().Build()
+	} else {
+		cmd = provider.inClient.B().Set().Key(key).Value(string(value)).Nx().Ex(duration + provider.stale).Build()
+	}
+
+	err := provider.inClient.Do(provider.ctx, cmd).Error()
+	if redis.IsRedisNil(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into Redis, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Redis provider only if the stored bytes equal old,
+// using a Lua script to run atomically. A nil old value creates the key like SetNX.
+func (provider *Redis) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	var expiry int64
+	if duration != -1 {
+		expiry = (duration + provider.stale).Milliseconds()
+	}
+
+	swapped, err := compareAndSwap.Exec(
+		provider.ctx,
+		provider.inClient,
+		[]string{key},
+		[]string{string(old), string(value), strconv.FormatInt(expiry, 10)},
+	).AsInt64()
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into Redis, %v", key, err)
+
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
+// Increment method will add delta to the counter stored in Redis provider using a Lua script to run atomically.
+func (provider *Redis) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := increment.Exec(
+		provider.ctx,
+		provider.inClient,
+		[]string{key},
+		[]string{strconv.FormatInt(delta, 10), strconv.FormatInt(duration.Milliseconds(), 10)},
+	).AsInt64()
+	if redis.IsRedisNil(err) {
+		return 0, core.ErrNotCounter
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into Redis, %v", key, err)
+	}
+
+	return value, err
+}
+
+// Decrement method will subtract delta from the counter stored in Redis provider.
+func (provider *Redis) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Redis provider.
+func (provider *Redis) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Redis, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Redis) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Redis) Touch(key string, duration time.Duration) error {
+	updated, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Pexpire().Key(key).Milliseconds((duration + provider.stale).Milliseconds()).Build()).AsBool()
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into Redis, %v", key, err)
+
+		return err
+	}
+
+	if !updated {
+		return core.ErrKeyNotFound
+	}
+
+	return nil
+}
+
 // Delete method will delete the response in Etcd provider if exists corresponding to key param.
 func (provider *Redis) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	_ = provider.inClient.Do(provider.ctx, provider.inClient.B().Del().Key(key).Build())
 }
 
-// DeleteMany method will delete the responses in Redis provider if exists corresponding to the regex key param.
-func (provider *Redis) DeleteMany(key string) {
+// DeleteMany method will delete the responses in Redis provider if exists corresponding to the prefix pattern param.
+func (provider *Redis) DeleteMany(pattern string) {
 	var scan redis.ScanEntry
 
 	var err error
@@ -262,14 +728,191 @@ func (provider *Redis) DeleteMany(key string) {
 	provider.logger.Debugf("Call the DeleteMany function in redis")
 
 	for more := true; more; more = scan.Cursor != 0 {
-		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(key).Build()).AsScanEntry(); err != nil {
+		if scan, err = provider.inClient.Do(context.Background(), provider.inClient.B().Scan().Cursor(scan.Cursor).Match(core.KeyPrefix(pattern)+"*").Build()).AsScanEntry(); err != nil {
 			provider.logger.Errorf("Cannot scan: %v", err)
 		}
 
 		elements = append(elements, scan.Elements...)
 	}
 
-	_ = provider.inClient.Do(provider.ctx, provider.inClient.B().Del().Key(elements...).Build())
+	if len(elements) > 0 {
+		_ = provider.inClient.Do(provider.ctx, provider.inClient.B().Del().Key(elements...).Build())
+	}
+}
+
+// DeleteKeys method will delete the keys in Redis provider one by one, the missing keys are ignored.
+func (provider *Redis) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Redis provider.
+func (provider *Redis) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Redis provider.
+func (provider *Redis) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Redis provider.
+func (provider *Redis) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Redis provider without altering it.
+func (provider *Redis) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Redis provider and its metadata under a parallel key.
+func (provider *Redis) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Redis provider, core.ErrKeyNotFound if none.
+func (provider *Redis) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the DBSIZE key count and the used_memory reported by INFO.
+// The count includes the keys written by other clients of the same database.
+func (provider *Redis) Stats() (core.StorageStats, error) {
+	count, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Dbsize().Build()).AsInt64()
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Redis database size, %v", err)
+
+		return core.StorageStats{}, err
+	}
+
+	info, err := provider.inClient.Do(provider.ctx, provider.inClient.B().Info().Section("memory").Build()).ToString()
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the Redis memory info, %v", err)
+
+		return core.StorageStats{KeyCount: count}, err
+	}
+
+	return core.StorageStats{KeyCount: count, ApproxSizeBytes: usedMemory(info)}, nil
+}
+
+func usedMemory(info string) int64 {
+	for _, line := range strings.Split(info, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "used_memory:"); found {
+			size, _ := strconv.ParseInt(value, 10, 64)
+
+			return size
+		}
+	}
+
+	return 0
+}
+
+// Ping method will send a PING command to the Redis server.
+func (provider *Redis) Ping(ctx context.Context) error {
+	return provider.inClient.Do(ctx, provider.inClient.B().Ping().Build()).Error()
+}
+
+// enableKeyspaceEvents adds the notify-keyspace-events flags required by Watch to the server configuration,
+// a warning is logged when the configuration can't be read or written, on the managed services for example.
+func (provider *Redis) enableKeyspaceEvents(ctx context.Context) {
+	configured, err := provider.inClient.Do(ctx, provider.inClient.B().ConfigGet().Parameter("notify-keyspace-events").Build()).AsStrMap()
+	if err == nil {
+		flags := missingKeyspaceEvents(configured["notify-keyspace-events"])
+		if flags == "" {
+			return
+		}
+
+		err = provider.inClient.Do(ctx, provider.inClient.B().ConfigSet().ParameterValue().ParameterValue("notify-keyspace-events", flags).Build()).Error()
+	}
+
+	if err != nil {
+		provider.logger.Warnf("Impossible to enable the %s keyspace events, they must be enabled on the server for Watch, %v", keyspaceEvents, err)
+	}
+}
+
+// Watch method will stream the changes of the keys starting with the prefix using the keyspace notifications
+// received by a dedicated connection, see core.Watcher. The required notify-keyspace-events flags are enabled
+// when missing and the expired or evicted keys are reported as deleted. On a cluster only the keys of the node
+// serving the subscription are reported, the channel is closed too when the connection is lost.
+func (provider *Redis) Watch(ctx context.Context, prefix string) (<-chan core.Event, error) {
+	provider.enableKeyspaceEvents(ctx)
+
+	// The dedicated connection isn't closed with the client, the watch ends with the provider instead.
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(provider.watches, cancel)
+
+	channel := keyspaceChannel(provider.configuration.SelectDB)
+	events := make(chan core.Event)
+	dedicated, release := provider.inClient.Dedicate()
+
+	var mu sync.Mutex
+
+	closed := false
+	disconnected := dedicated.SetPubSubHooks(redis.PubSubHooks{
+		OnMessage: func(message redis.PubSubMessage) {
+			eventType, ok := keyspaceEventTypes[message.Message]
+			if !ok {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if closed {
+				return
+			}
+
+			select {
+			case events <- core.Event{Type: eventType, Key: strings.TrimPrefix(message.Channel, channel)}:
+			case <-ctx.Done():
+			}
+		},
+	})
+
+	err := dedicated.Do(ctx, dedicated.B().Psubscribe().Pattern(keyspacePattern(provider.configuration.SelectDB, prefix)).Build()).Error()
+	if err != nil {
+		stop()
+		cancel()
+		release()
+		provider.logger.Errorf("Impossible to watch the prefix %s in Redis, %v", prefix, err)
+
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case err := <-disconnected:
+			if err != nil {
+				provider.logger.Errorf("The watch of the prefix %s in Redis has been interrupted, %v", prefix, err)
+			}
+		}
+
+		stop()
+		cancel()
+		release()
+
+		mu.Lock()
+		closed = true
+		close(events)
+		mu.Unlock()
+	}()
+
+	return events, nil
+}
+
+// Export method will stream every key of the Redis provider paginating the scanned keys.
+func (provider *Redis) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in Redis provider by batches.
+func (provider *Redis) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
 }
 
 // Init method will.
@@ -287,3 +930,11 @@ func (provider *Redis) Reset() error {
 func (provider *Redis) Reconnect() {
 	provider.logger.Debug("Doing nothing on reconnect because rueidis handles it!")
 }
+
+// Close method will end the watches and close the Redis client.
+func (provider *Redis) Close() error {
+	provider.stopWatches()
+	provider.close()
+
+	return nil
+}
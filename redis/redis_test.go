@@ -1,7 +1,9 @@
 package redis_test
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -150,3 +152,107 @@ func TestRedis_DeleteMany(t *testing.T) {
 		t.Error("The map should be empty")
 	}
 }
+
+func TestRedis_InvalidConfiguration(t *testing.T) {
+	configurations := []map[string]interface{}{
+		{"url": "http://localhost:6379"},
+		{"url": "rediss://localhost:6379", "tls": map[string]interface{}{"ca_file": t.TempDir() + "/missing.pem"}},
+		{"url": "rediss://localhost:6379", "tls": map[string]interface{}{"cert_file": "missing.crt", "key_file": "missing.key"}},
+	}
+
+	for _, configuration := range configurations {
+		if _, err := redis.Factory(core.CacheProvider{Configuration: configuration}, zap.NewNop().Sugar(), 0); err == nil {
+			t.Errorf("The configuration %v should be rejected", configuration)
+		}
+	}
+}
+
+// The TLS test needs a Redis instance with TLS enabled, the REDIS_TLS_URL env must contain its rediss:// URL.
+func TestRedis_TLS(t *testing.T) {
+	url := os.Getenv("REDIS_TLS_URL")
+	if url == "" {
+		t.Skip("REDIS_TLS_URL is not set")
+	}
+
+	client, err := redis.Factory(core.CacheProvider{Configuration: map[string]interface{}{
+		"url": url,
+		"tls": map[string]interface{}{
+			"ca_file":   os.Getenv("REDIS_TLS_CA_FILE"),
+			"cert_file": os.Getenv("REDIS_TLS_CERT_FILE"),
+			"key_file":  os.Getenv("REDIS_TLS_KEY_FILE"),
+		},
+	}}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to connect to the TLS Redis instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	_ = client.Set("TLSKey", []byte(baseValue), time.Minute)
+
+	if res := client.Get("TLSKey"); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestRedis_Stats(t *testing.T) {
+	client, _ := getRedisInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestRedis_Watch(t *testing.T) {
+	client, err := getRedisInstance()
+	if err != nil {
+		t.Fatalf("Failed to create the instance: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.(core.Watcher).Watch(ctx, "WatchKey_")
+	if err != nil {
+		t.Fatalf("Impossible to watch the keys: %v", err)
+	}
+
+	_ = client.Set("UnwatchedKey", []byte(baseValue), time.Minute)
+	_ = client.Set("WatchKey_1", []byte(baseValue), time.Minute)
+	client.Delete("WatchKey_1")
+
+	for _, expected := range []core.Event{{Type: core.EventSet, Key: "WatchKey_1"}, {Type: core.EventDelete, Key: "WatchKey_1"}} {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Errorf("The %s event of the key %s should be streamed, %s of %s provided", expected.Type, expected.Key, event.Type, event.Key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The %s event of the key %s should be streamed", expected.Type, expected.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("The events should stop once the context is cancelled")
+		}
+	}
+}
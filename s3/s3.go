@@ -0,0 +1,814 @@
+// Package s3 stores the cache entries as objects in an S3 compatible bucket.
+//
+// S3 has no per-object time to live, the expiry is stored in the x-amz-meta-expires-at
+// object metadata and checked lazily on read, the expired objects are treated as
+// misses and removed when delete_expired is enabled. A bucket lifecycle rule should
+// be configured to eventually remove the objects that are never read again.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// The expiry can't be named Expires, minio would send it as the standard header.
+	expiresMetadata = "Expires-At"
+	noSuchKey       = "NoSuchKey"
+	// The ETag preconditions aren't met.
+	preconditionFailed = "PreconditionFailed"
+)
+
+// S3 provider type.
+type S3 struct {
+	*minio.Client
+	stale         time.Duration
+	logger        core.Logger
+	mapper        core.Mapper
+	bucket        string
+	compression   string
+	clock         core.Clock
+	deleteExpired bool
+}
+
+// Factory function create new S3 instance.
+func Factory(s3Configuration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if s3Configuration.Validate {
+		if err := core.ValidateConfig(s3Configuration, core.CheckURL); err != nil {
+			logger.Errorf("Impossible to validate the S3 configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(s3Configuration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, s3Configuration, logger)
+}
+
+func factory(s3Configuration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	endpoint := s3Configuration.URL
+	bucket := "souin"
+	region := ""
+	accessKey, secretKey, sessionToken := "", "", ""
+	secure := true
+	deleteExpired := false
+
+	if sc, ok := s3Configuration.Configuration.(map[string]interface{}); ok && sc != nil {
+		if v, found := sc["endpoint"]; found && v != nil {
+			endpoint = fmt.Sprint(v)
+		}
+
+		if v, found := sc["bucket"]; found && v != nil {
+			bucket = fmt.Sprint(v)
+		}
+
+		if v, found := sc["region"]; found && v != nil {
+			region = fmt.Sprint(v)
+		}
+
+		if v, found := sc["access_key_id"]; found && v != nil {
+			accessKey = fmt.Sprint(v)
+		}
+
+		if v, found := sc["secret_access_key"]; found && v != nil {
+			secretKey = fmt.Sprint(v)
+		}
+
+		if v, found := sc["session_token"]; found && v != nil {
+			sessionToken = fmt.Sprint(v)
+		}
+
+		if v, found := sc["secure"]; found && v != nil {
+			secure, _ = strconv.ParseBool(fmt.Sprint(v))
+		}
+
+		if v, found := sc["delete_expired"]; found && v != nil {
+			deleteExpired, _ = strconv.ParseBool(fmt.Sprint(v))
+		}
+	}
+
+	if scheme, host, found := strings.Cut(endpoint, "://"); found {
+		endpoint = host
+		secure = scheme == "https"
+	}
+
+	if endpoint == "" {
+		err := errors.New("no s3 endpoint given")
+		logger.Error("Impossible to connect to the S3 endpoint.", err)
+
+		return nil, err
+	}
+
+	creds := credentials.NewStaticV4(accessKey, secretKey, sessionToken)
+	if accessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{},
+		})
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{Creds: creds, Secure: secure, Region: region})
+	if err != nil {
+		logger.Error("Impossible to instantiate the S3 client.", err)
+
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(context.Background(), bucket)
+	if err != nil {
+		logger.Error("Impossible to connect to the S3 endpoint.", err)
+
+		return nil, err
+	}
+
+	if !exists {
+		if err = client.MakeBucket(context.Background(), bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+			logger.Errorf("Impossible to create the S3 bucket %s, %v", bucket, err)
+
+			return nil, err
+		}
+	}
+
+	return &S3{
+		Client:        client,
+		stale:         stale,
+		logger:        logger,
+		mapper:        core.ConfiguredMapper(s3Configuration),
+		bucket:        bucket,
+		compression:   core.ConfiguredCompression(s3Configuration, logger),
+		clock:         core.ClockOrDefault(s3Configuration.Clock),
+		deleteExpired: deleteExpired,
+	}, nil
+}
+
+// expiresAt returns the expiry stored in the object metadata, the zero time means no expiration.
+func expiresAt(info minio.ObjectInfo) time.Time {
+	value, err := strconv.ParseInt(info.UserMetadata[expiresMetadata], 10, 64)
+	if err != nil || value == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, value)
+}
+
+func (provider *S3) expired(info minio.ObjectInfo) bool {
+	expiry := expiresAt(info)
+
+	return !expiry.IsZero() && !provider.clock.Now().Before(expiry)
+}
+
+func isNotFound(err error) bool {
+	return minio.ToErrorResponse(err).Code == noSuchKey
+}
+
+// expire treats the object as a miss and removes it if enabled.
+func (provider *S3) expire(ctx context.Context, key string) {
+	if !provider.deleteExpired {
+		return
+	}
+
+	if err := provider.RemoveObject(ctx, provider.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		provider.logger.Errorf("Impossible to delete the expired key %s in S3, %v", key, err)
+	}
+}
+
+// Name returns the storer name.
+func (provider *S3) Name() string {
+	return "S3"
+}
+
+// Uuid returns an unique identifier.
+func (provider *S3) Uuid() string {
+	return fmt.Sprintf("%s-%s-%s", provider.EndpointURL().Host, provider.bucket, provider.stale)
+}
+
+// MapKeys method returns the map of existing keys, each value is read with its own request.
+func (provider *S3) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	for object := range provider.ListObjects(context.Background(), provider.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			provider.logger.Errorf("Impossible to list the keys in S3, %v", object.Err)
+
+			break
+		}
+
+		if !core.ListedKey(prefix, object.Key) {
+			continue
+		}
+
+		if value, err := provider.GetContext(context.Background(), object.Key); err == nil {
+			k, _ := strings.CutPrefix(object.Key, prefix)
+			keys[k] = string(value)
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *S3) ListKeys() []string {
+	keys := []string{}
+
+	for _, value := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(value))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+// The expired objects are listed until they are read or removed by a lifecycle rule.
+func (provider *S3) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	// StartAfter is exclusive, the cursor key itself is checked separately.
+	if cursor > prefix && strings.HasPrefix(cursor, prefix) && core.ListedKey(prefix, cursor) {
+		if _, err := provider.StatObject(context.Background(), provider.bucket, cursor, minio.StatObjectOptions{}); err == nil {
+			keys = append(keys, cursor)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for object := range provider.ListObjects(ctx, provider.bucket, minio.ListObjectsOptions{Prefix: prefix, StartAfter: cursor, Recursive: true}) {
+		if object.Err != nil {
+			provider.logger.Errorf("Impossible to scan the keys in S3, %v", object.Err)
+
+			break
+		}
+
+		if !core.ListedKey(prefix, object.Key) {
+			continue
+		}
+
+		if limit > 0 && len(keys) == limit {
+			next = object.Key
+
+			break
+		}
+
+		keys = append(keys, object.Key)
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *S3) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *S3) Get(key string) []byte {
+	value, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in S3: %v", key, err)
+	}
+
+	return value
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *S3) GetContext(ctx context.Context, key string) ([]byte, error) {
+	object, err := provider.open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = object.Close() }()
+
+	return io.ReadAll(object)
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *S3) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// open returns the object if it exists and isn't expired.
+func (provider *S3) open(ctx context.Context, key string) (*minio.Object, error) {
+	object, err := provider.GetObject(ctx, provider.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+
+		if isNotFound(err) {
+			return nil, core.ErrKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	if provider.expired(info) {
+		_ = object.Close()
+		provider.expire(ctx, key)
+
+		return nil, core.ErrKeyNotFound
+	}
+
+	return object, nil
+}
+
+// GetMany method returns the values of the existing keys, each key is read with its own request.
+func (provider *S3) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value, err := provider.GetContext(context.Background(), key); err == nil {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *S3) GetTTL(key string) (time.Duration, bool) {
+	info, err := provider.StatObject(context.Background(), provider.bucket, key, minio.StatObjectOptions{})
+	if err != nil || provider.expired(info) {
+		return 0, false
+	}
+
+	if expiry := expiresAt(info); !expiry.IsZero() {
+		return expiry.Sub(provider.clock.Now()), true
+	}
+
+	return core.NoExpiration, true
+}
+
+// Exists method will check the key in S3 provider using the object metadata.
+func (provider *S3) Exists(key string) bool {
+	info, err := provider.StatObject(context.Background(), provider.bucket, key, minio.StatObjectOptions{})
+
+	return err == nil && !provider.expired(info)
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *S3) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *S3) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in S3", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *S3) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into S3, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in S3, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in S3: %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in S3", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// putOptions returns the options storing the expiry in the object metadata, a non-positive duration means no expiration.
+func (provider *S3) putOptions(duration time.Duration) minio.PutObjectOptions {
+	expiry := "0"
+	if duration > 0 {
+		expiry = strconv.FormatInt(provider.clock.Now().Add(duration).UnixNano(), 10)
+	}
+
+	return minio.PutObjectOptions{
+		ContentType:  "application/octet-stream",
+		UserMetadata: map[string]string{expiresMetadata: expiry},
+	}
+}
+
+// Set method will store the response in S3 provider.
+func (provider *S3) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+// SetContext method will store the response unless the context is done before the upload completes.
+func (provider *S3) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.PutObject(ctx, provider.bucket, key, bytes.NewReader(value), int64(len(value)), provider.putOptions(duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into S3, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in S3 provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *S3) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNX method will store the response in S3 provider only if the key doesn't exist yet.
+// The upload is conditioned on the object absence, or on the expired object ETag to replace it atomically.
+func (provider *S3) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	opts := provider.putOptions(duration)
+
+	info, err := provider.StatObject(context.Background(), provider.bucket, key, minio.StatObjectOptions{})
+
+	switch {
+	case err == nil && !provider.expired(info):
+		return false, nil
+	case err == nil:
+		opts.SetMatchETag(info.ETag)
+	case isNotFound(err):
+		opts.SetMatchETagExcept("*")
+	default:
+		provider.logger.Errorf("Impossible to set the key %s if not exists into S3, %v", key, err)
+
+		return false, err
+	}
+
+	_, err = provider.PutObject(context.Background(), provider.bucket, key, bytes.NewReader(value), int64(len(value)), opts)
+	if minio.ToErrorResponse(err).Code == preconditionFailed {
+		return false, nil
+	}
+
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into S3, %v", key, err)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in S3 provider only if the stored bytes equal old.
+// The upload is conditioned on the read object ETag and retried when a concurrent writer updated the key in between.
+func (provider *S3) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	for {
+		swapped, conflict, err := provider.compareAndSwap(key, old, value, duration)
+		if err != nil {
+			provider.logger.Errorf("Impossible to compare and swap the key %s into S3, %v", key, err)
+
+			return false, err
+		}
+
+		if !conflict {
+			return swapped, nil
+		}
+	}
+}
+
+func (provider *S3) compareAndSwap(key string, old, value []byte, duration time.Duration) (bool, bool, error) {
+	object, err := provider.GetObject(context.Background(), provider.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return false, false, err
+	}
+
+	defer func() { _ = object.Close() }()
+
+	info, err := object.Stat()
+
+	switch {
+	case isNotFound(err):
+		return false, false, nil
+	case err != nil:
+		return false, false, err
+	case provider.expired(info):
+		return false, false, nil
+	}
+
+	current, err := io.ReadAll(object)
+	if err != nil {
+		return false, false, err
+	}
+
+	if !core.SwapMatches(current, old) {
+		return false, false, nil
+	}
+
+	opts := provider.putOptions(duration)
+	opts.SetMatchETag(info.ETag)
+
+	_, err = provider.PutObject(context.Background(), provider.bucket, key, bytes.NewReader(value), int64(len(value)), opts)
+	if minio.ToErrorResponse(err).Code == preconditionFailed {
+		return false, true, nil
+	}
+
+	return err == nil, false, err
+}
+
+// Increment method will add delta to the counter stored in S3 provider.
+// The upload is conditioned on the read object ETag and retried when a concurrent writer updated the counter in between.
+func (provider *S3) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	for {
+		value, updated, err := provider.increment(key, delta, duration)
+		if err != nil {
+			if !errors.Is(err, core.ErrNotCounter) {
+				provider.logger.Errorf("Impossible to increment the key %s into S3, %v", key, err)
+			}
+
+			return 0, err
+		}
+
+		if updated {
+			return value, nil
+		}
+	}
+}
+
+func (provider *S3) increment(key string, delta int64, duration time.Duration) (int64, bool, error) {
+	opts := provider.putOptions(duration)
+
+	object, err := provider.GetObject(context.Background(), provider.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return 0, false, err
+	}
+
+	defer func() { _ = object.Close() }()
+
+	var current []byte
+
+	info, err := object.Stat()
+
+	switch {
+	case isNotFound(err):
+		opts.SetMatchETagExcept("*")
+	case err != nil:
+		return 0, false, err
+	default:
+		opts.SetMatchETag(info.ETag)
+
+		if !provider.expired(info) {
+			if current, err = io.ReadAll(object); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, false, err
+	}
+
+	_, err = provider.PutObject(context.Background(), provider.bucket, key, bytes.NewReader(encoded), int64(len(encoded)), opts)
+	if minio.ToErrorResponse(err).Code == preconditionFailed {
+		return 0, false, nil
+	}
+
+	return value, err == nil, err
+}
+
+// Decrement method will subtract delta from the counter stored in S3 provider.
+func (provider *S3) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in S3 provider.
+func (provider *S3) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into S3, %v", key, err)
+	}
+
+	return err
+}
+
+type objectReader struct {
+	io.ReadCloser
+	object *minio.Object
+}
+
+func (r *objectReader) Close() error {
+	_ = r.ReadCloser.Close()
+
+	return r.object.Close()
+}
+
+// GetStream method returns a reader decompressing lazily the object stored by SetStream while it's downloaded.
+func (provider *S3) GetStream(key string) (io.ReadCloser, error) {
+	object, err := provider.open(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := core.DecompressStream(object)
+	if err != nil {
+		_ = object.Close()
+
+		return nil, err
+	}
+
+	return &objectReader{ReadCloser: reader, object: object}, nil
+}
+
+// Touch method will update the time to live of the key by copying the object onto itself with the new metadata.
+func (provider *S3) Touch(key string, duration time.Duration) error {
+	info, err := provider.StatObject(context.Background(), provider.bucket, key, minio.StatObjectOptions{})
+	if (err == nil && provider.expired(info)) || isNotFound(err) {
+		return core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.CopyObject(
+		context.Background(),
+		minio.CopyDestOptions{Bucket: provider.bucket, Object: key, UserMetadata: provider.putOptions(duration).UserMetadata, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: provider.bucket, Object: key, MatchETag: info.ETag},
+	)
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into S3, %v", key, err)
+	}
+
+	return err
+}
+
+// Delete method will delete the response in S3 provider if exists corresponding to key param.
+func (provider *S3) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if err := provider.RemoveObject(context.Background(), provider.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in S3, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in S3 provider if exists corresponding to the prefix pattern param.
+// The listed objects are deleted in batches.
+func (provider *S3) DeleteMany(pattern string) {
+	objects := provider.ListObjects(context.Background(), provider.bucket, minio.ListObjectsOptions{Prefix: core.KeyPrefix(pattern), Recursive: true})
+
+	for err := range provider.RemoveObjects(context.Background(), provider.bucket, objects, minio.RemoveObjectsOptions{}) {
+		provider.logger.Errorf("Impossible to delete the key %s in S3, %v", err.ObjectName, err.Err)
+	}
+}
+
+// DeleteKeys method will delete the keys in S3 provider one by one, the missing keys are ignored.
+func (provider *S3) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in S3 provider.
+func (provider *S3) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in S3 provider.
+func (provider *S3) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in S3 provider.
+func (provider *S3) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in S3 provider without altering it.
+func (provider *S3) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in S3 provider and its metadata under a parallel key.
+func (provider *S3) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in S3 provider, core.ErrKeyNotFound if none.
+func (provider *S3) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number and the size of the objects, S3 doesn't expose them so the bucket is listed.
+// The expired objects are counted until they are read or removed by a lifecycle rule.
+func (provider *S3) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+	now := provider.clock.Now()
+
+	for object := range provider.ListObjects(context.Background(), provider.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			provider.logger.Errorf("Impossible to list the keys in S3, %v", object.Err)
+
+			return stats, object.Err
+		}
+
+		stats.KeyCount++
+		stats.ApproxSizeBytes += object.Size
+		stats.OldestEntryAge = max(stats.OldestEntryAge, now.Sub(object.LastModified))
+	}
+
+	return stats, nil
+}
+
+// Ping method will check the S3 bucket exists.
+func (provider *S3) Ping(ctx context.Context) error {
+	found, err := provider.BucketExists(ctx, provider.bucket)
+	if err == nil && !found {
+		err = fmt.Errorf("the bucket %s doesn't exist", provider.bucket)
+	}
+
+	return err
+}
+
+// Export method will stream every key of the S3 provider paginating the scanned keys.
+func (provider *S3) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
+
+// Import method will store the exported records in S3 provider by batches.
+func (provider *S3) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *S3) Init() error {
+	return nil
+}
+
+// Reset method will delete every object of the bucket.
+func (provider *S3) Reset() error {
+	provider.DeleteMany("*")
+
+	return nil
+}
+
+// Close method will do nothing, the S3 client holds no dedicated resource.
+func (provider *S3) Close() error {
+	return nil
+}
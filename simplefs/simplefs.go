@@ -5,11 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,7 +20,6 @@ import (
 	"github.com/darkweak/storages/core"
 	"github.com/dustin/go-humanize"
 	"github.com/jellydator/ttlcache/v3"
-	"github.com/pierrec/lz4/v4"
 )
 
 // Simplefs provider type.
@@ -28,17 +29,95 @@ type Simplefs struct {
 	size          int
 	path          string
 	logger        core.Logger
+	mapper        core.Mapper
+	compression   string
+	clock         core.Clock
 	actualSize    int64
 	directorySize int64
 	mu            sync.Mutex
+	stopped       sync.Once
 }
 
+// expirySuffix names the sidecar file holding the expiry of an entry. The '#' is always escaped in the entry
+// file names so it can't collide with a key, the temporary files written before their rename contain it too.
+const expirySuffix = "#expiry"
+
 func onEvict(path string) error {
-	return os.Remove(path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.Remove(path + expirySuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// writeFile writes the data to a temporary file of the same directory renamed to the path, so the readers
+// either see the previous content or the whole new one.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"#tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+// readExpiry returns the expiry stored in the sidecar of the entry, the zero time when it never expires.
+func readExpiry(path string) (time.Time, error) {
+	content, err := os.ReadFile(path + expirySuffix)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	nanos, err := strconv.ParseInt(string(content), 10, 64)
+	if err != nil || nanos == 0 {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, nanos), nil
 }
 
 // Factory function create new Simplefs instance.
 func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if simplefsCfg.Validate {
+		if err := core.ValidateConfig(simplefsCfg, core.CheckWritablePath); err != nil {
+			logger.Errorf("Impossible to validate the Simplefs configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(simplefsCfg, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, simplefsCfg, logger)
+}
+
+func factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
 	var directorySize int64
 
 	storagePath := simplefsCfg.Path
@@ -111,7 +190,7 @@ func Factory(simplefsCfg core.CacheProvider, logger core.Logger, stale time.Dura
 
 	logger.Infof("Created the storage directory %s if needed", storagePath)
 
-	store := Simplefs{cache: cache, directorySize: directorySize, logger: logger, mu: sync.Mutex{}, path: storagePath, size: size, stale: stale}
+	store := Simplefs{cache: cache, directorySize: directorySize, logger: logger, mapper: core.ConfiguredMapper(simplefsCfg), compression: core.ConfiguredCompression(simplefsCfg, logger), clock: core.ClockOrDefault(simplefsCfg.Clock), mu: sync.Mutex{}, path: storagePath, size: size, stale: stale}
 
 	defer func() {
 		go store.cache.Start()
@@ -138,7 +217,7 @@ func (provider *Simplefs) MapKeys(prefix string) map[string]string {
 	defer provider.mu.Unlock()
 
 	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
-		if strings.HasPrefix(item.Key(), prefix) {
+		if internalKey(item.Key()) && strings.HasPrefix(item.Key(), prefix) && core.ListedKey(prefix, item.Key()) {
 			k, _ := strings.CutPrefix(item.Key(), prefix)
 			keys[k] = string(item.Value())
 		}
@@ -146,6 +225,20 @@ func (provider *Simplefs) MapKeys(prefix string) map[string]string {
 		return true
 	})
 
+	for _, key := range provider.storedKeys() {
+		if !strings.HasPrefix(key, prefix) || !core.ListedKey(prefix, key) {
+			continue
+		}
+
+		value, err := os.ReadFile(provider.entryPath(key))
+		if err != nil {
+			continue
+		}
+
+		k, _ := strings.CutPrefix(key, prefix)
+		keys[k] = string(value)
+	}
+
 	return keys
 }
 
@@ -154,7 +247,20 @@ func (provider *Simplefs) ListKeys() []string {
 	provider.mu.Lock()
 	defer provider.mu.Unlock()
 
-	return provider.cache.Keys()
+	return provider.keys()
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *Simplefs) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return core.PaginateKeys(provider.keys(), prefix, cursor, limit)
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *Simplefs) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
 }
 
 // Get method returns the populated response if exists, empty response then.
@@ -162,29 +268,80 @@ func (provider *Simplefs) Get(key string) []byte {
 	provider.mu.Lock()
 	defer provider.mu.Unlock()
 
-	result := provider.cache.Get(key)
+	result := provider.value(key)
 	if result == nil {
 		provider.logger.Warnf("Impossible to get the key %s in Simplefs", key)
+	}
 
-		return nil
+	return result
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+func (provider *Simplefs) GetContext(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	if strings.HasPrefix(key, core.SurrogateKeyPrefix) {
-		return result.Value()
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
 	}
 
-	byteValue, err := os.ReadFile(strings.Trim(string(result.Value()), ","))
-	if err != nil {
-		provider.logger.Errorf("Impossible to read the file %s from Simplefs: %#v", result.Value(), err)
+	return value, nil
+}
 
-		return result.Value()
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *Simplefs) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys.
+func (provider *Simplefs) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	for _, key := range keys {
+		if value := provider.Get(key); value != nil {
+			result[key] = value
+		}
 	}
 
-	return byteValue
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *Simplefs) GetTTL(key string) (time.Duration, bool) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	result := provider.item(key)
+	if result == nil {
+		return 0, false
+	}
+
+	if result.ExpiresAt().IsZero() {
+		return core.NoExpiration, true
+	}
+
+	return time.Until(result.ExpiresAt()), true
+}
+
+// Exists method will check the key in Simplefs provider without extending its expiry.
+func (provider *Simplefs) Exists(key string) bool {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return provider.item(key) != nil
 }
 
 // GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
 func (provider *Simplefs) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *Simplefs) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
 	provider.mu.Lock()
 
 	val := provider.cache.Get(core.MappingKeyPrefix + key)
@@ -194,50 +351,51 @@ func (provider *Simplefs) GetMultiLevel(key string, req *http.Request, validator
 	if val == nil {
 		provider.logger.Debugf("Impossible to get the mapping key %s in Simplefs", core.MappingKeyPrefix+key)
 
-		return fresh, stale
+		return fresh, stale, match
 	}
 
-	fresh, stale, _ = core.MappingElection(provider, val.Value(), req, validator, provider.logger)
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val.Value(), req, validator, provider.logger)
 
-	return fresh, stale
+	return fresh, stale, match
 }
 
 // SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
 func (provider *Simplefs) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
-	now := time.Now()
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
 
-	compressed := new(bytes.Buffer)
-	writer := lz4.NewWriter(compressed)
+		return nil
+	}
 
-	if _, err := writer.ReadFrom(bytes.NewReader(value)); err != nil {
-		_ = writer.Close()
+	now := provider.clock.Now()
 
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
 		provider.logger.Errorf("Impossible to compress the key %s into Simplefs, %v", variedKey, err)
 
 		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		provider.logger.Errorf("Impossible to close the compressor for key %s into Simplefs, %v", variedKey, err)
-
-		return err
-	}
-
-	provider.recoverEnoughSpaceIfNeeded(int64(compressed.Len()))
+	provider.mu.Lock()
+	err = provider.put(variedKey, compressed, duration)
+	provider.mu.Unlock()
 
-	joinedFP := filepath.Join(provider.path, url.PathEscape(variedKey))
-	//nolint:gosec
-	if err := os.WriteFile(joinedFP, compressed.Bytes(), 0o644); err != nil {
+	if err != nil {
 		provider.logger.Errorf("Impossible to write the file %s from Simplefs: %#v", variedKey, err)
 
 		return nil
 	}
 
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in Simplefs, %v", variedKey, err)
+
+		return err
+	}
+
 	provider.mu.Lock()
 	defer provider.mu.Unlock()
 
-	_ = provider.cache.Set(variedKey, []byte(joinedFP), duration)
-
 	mappingKey := core.MappingKeyPrefix + baseKey
 	item := provider.cache.Get(mappingKey)
 
@@ -247,7 +405,7 @@ func (provider *Simplefs) SetMultiLevel(baseKey, variedKey string, value []byte,
 		item = &ttlcache.Item[string, []byte]{}
 	}
 
-	val, e := core.MappingUpdater(variedKey, item.Value(), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	val, e := core.MappingUpdaterWith(provider.mapper, variedKey, item.Value(), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
 	if e != nil {
 		return e
 	}
@@ -269,87 +427,272 @@ func (provider *Simplefs) Set(key string, value []byte, duration time.Duration)
 	provider.mu.Lock()
 	defer provider.mu.Unlock()
 
-	_ = provider.cache.Set(key, value, duration)
+	if err := provider.put(key, value, duration); err != nil {
+		provider.logger.Errorf("Impossible to write the file %s from Simplefs: %#v", key, err)
+
+		return err
+	}
+
+	return nil
+}
+
+// SetContext method will store the response unless the context is done.
+func (provider *Simplefs) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return provider.Set(key, value, duration)
+}
+
+// SetMany method will store the entries in Simplefs provider one by one.
+// The entries stored before a failure are kept and the first error is returned.
+func (provider *Simplefs) SetMany(items map[string]core.Entry) error {
+	for key, item := range items {
+		if err := provider.Set(key, item.Value, item.Duration); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// SetNX method will store the response in Simplefs provider only if the key doesn't exist yet.
+func (provider *Simplefs) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.item(key) != nil {
+		return false, nil
+	}
+
+	if err := provider.put(key, value, duration); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap method will store the response in Simplefs provider only if the stored bytes equal old.
+func (provider *Simplefs) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if !core.SwapMatches(provider.value(key), old) {
+		return false, nil
+	}
+
+	if err := provider.put(key, value, duration); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Increment method will add delta to the counter stored in Simplefs provider.
+func (provider *Simplefs) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	value, encoded, err := core.AddCounter(provider.value(key), delta)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		duration = ttlcache.NoTTL
+	}
+
+	if err = provider.put(key, encoded, duration); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// Decrement method will subtract delta from the counter stored in Simplefs provider.
+func (provider *Simplefs) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in Simplefs provider.
+// The compressed value is copied because Simplefs may keep a reference to the stored bytes.
+func (provider *Simplefs) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, bytes.Clone(compressed), duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into Simplefs, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *Simplefs) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *Simplefs) Touch(key string, duration time.Duration) error {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	item := provider.item(key)
+	if item == nil {
+		return core.ErrKeyNotFound
+	}
+
+	if internalKey(key) {
+		_ = provider.cache.Set(key, item.Value(), duration)
+
+		return nil
+	}
+
+	return provider.index(key, duration)
+}
+
 // Delete method will delete the response in Simplefs provider if exists corresponding to key param.
 func (provider *Simplefs) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
 	provider.mu.Lock()
 	defer provider.mu.Unlock()
 
-	provider.cache.Delete(key)
+	provider.remove(key)
 }
 
-// DeleteMany method will delete the responses in Simplefs provider if exists corresponding to the regex key param.
-func (provider *Simplefs) DeleteMany(key string) {
-	rgKey, e := regexp.Compile(key)
-	if e != nil {
-		return
+// DeleteMany method will delete the responses in Simplefs provider if exists corresponding to the prefix pattern param.
+func (provider *Simplefs) DeleteMany(pattern string) {
+	prefix := core.KeyPrefix(pattern)
+
+	for _, key := range provider.ListKeys() {
+		if strings.HasPrefix(key, prefix) {
+			provider.Delete(key)
+		}
+	}
+}
+
+// DeleteKeys method will delete the keys in Simplefs provider one by one, the missing keys are ignored.
+func (provider *Simplefs) DeleteKeys(keys []string) error {
+	for _, key := range keys {
+		provider.Delete(key)
+	}
+
+	return nil
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in Simplefs provider.
+func (provider *Simplefs) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in Simplefs provider.
+func (provider *Simplefs) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in Simplefs provider.
+func (provider *Simplefs) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in Simplefs provider without altering it.
+func (provider *Simplefs) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in Simplefs provider and its metadata under a parallel key.
+func (provider *Simplefs) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in Simplefs provider, core.ErrKeyNotFound if none.
+func (provider *Simplefs) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number of entries, the size of the stored files and the age of the oldest expiring entry.
+// The age is computed from the entry expiry and TTL, a touched entry looks as recent as its last touch.
+func (provider *Simplefs) Stats() (core.StorageStats, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	stats := core.StorageStats{
+		KeyCount:        int64(provider.cache.Len()),
+		ApproxSizeBytes: provider.actualSize,
 	}
+	now := time.Now()
 
 	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
-		if rgKey.MatchString(item.Key()) {
-			provider.Delete(item.Key())
+		if item.TTL() > 0 {
+			stats.OldestEntryAge = max(stats.OldestEntryAge, now.Sub(item.ExpiresAt().Add(-item.TTL())))
 		}
 
 		return true
 	})
+
+	return stats, nil
 }
 
-// Init method will.
-func (provider *Simplefs) Init() error {
-	provider.cache.OnInsertion(func(_ context.Context, item *ttlcache.Item[string, []byte]) {
-		if strings.Contains(item.Key(), core.MappingKeyPrefix) || strings.Contains(item.Key(), core.SurrogateKeyPrefix) {
-			return
-		}
+// Ping method will check the Simplefs directory is still accessible.
+func (provider *Simplefs) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		info, err := os.Stat(string(item.Value()))
-		if err != nil {
-			provider.logger.Errorf("impossible to get the file size %s: %#v", item.Key(), err)
+	_, err := os.Stat(provider.path)
 
-			return
-		}
+	return err
+}
 
-		provider.mu.Lock()
-		provider.actualSize += info.Size()
-		provider.logger.Debugf("Actual size add: %d, new: %d", provider.actualSize, info.Size())
-		provider.mu.Unlock()
-	})
+// Export method will stream every key of the Simplefs provider paginating the scanned keys.
+func (provider *Simplefs) Export(w io.Writer) error {
+	return core.ExportKeys(provider, w)
+}
 
+// Import method will store the exported records in Simplefs provider by batches.
+func (provider *Simplefs) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will remove the files of the entries evicted from the index and sum the size of the stored ones.
+func (provider *Simplefs) Init() error {
 	provider.cache.OnEviction(func(_ context.Context, _ ttlcache.EvictionReason, item *ttlcache.Item[string, []byte]) {
-		if strings.Contains(string(item.Value()), core.MappingKeyPrefix) {
+		if internalKey(item.Key()) {
 			return
 		}
 
-		info, err := os.Stat(string(item.Value()))
-		if err != nil {
-			provider.logger.Errorf("impossible to get the file size %s: %#v", item.Key(), err)
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
 
+		// The key was written again since its eviction, its files are the new ones.
+		if provider.cache.Has(item.Key()) {
 			return
 		}
 
-		provider.mu.Lock()
-		provider.actualSize -= info.Size()
-		provider.logger.Debugf("Actual size remove: %d, new: %d", provider.actualSize, info.Size())
-		provider.mu.Unlock()
-
-		if err := onEvict(string(item.Value())); err != nil {
-			provider.logger.Errorf("impossible to remove the file %s: %#v", item.Key(), err)
-		}
+		provider.removeFiles(item.Key())
 	})
 
-	files, _ := os.ReadDir(provider.path)
 	provider.logger.Debugf("Regenerating simplefs cache from files in the given directory.")
 
-	for _, f := range files {
-		if !f.IsDir() {
-			info, _ := f.Info()
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.actualSize = 0
+
+	provider.walk(func(_, path string) {
+		if info, err := os.Stat(path); err == nil {
 			provider.actualSize += info.Size()
 			provider.logger.Debugf("Add %v bytes to the actual size, sum to %v bytes.", info.Size(), provider.actualSize)
 		}
-	}
+	})
 
 	return nil
 }
@@ -361,23 +704,247 @@ func (provider *Simplefs) Reset() error {
 
 	provider.cache.DeleteAll()
 
+	provider.walk(func(key, _ string) {
+		provider.removeFiles(key)
+	})
+
 	return nil
 }
 
-func (provider *Simplefs) recoverEnoughSpaceIfNeeded(size int64) {
-	if provider.directorySize > -1 && provider.actualSize+size > provider.directorySize {
-		provider.mu.Lock()
-		defer provider.mu.Unlock()
+// internalKey tells if the key is a mapping, surrogate or metadata one, kept in memory rather than in a file.
+func internalKey(key string) bool {
+	return strings.HasPrefix(key, core.MappingKeyPrefix) || strings.HasPrefix(key, core.SurrogateKeyPrefix) ||
+		strings.HasPrefix(key, core.EntryMetaKeyPrefix)
+}
+
+// entryPath returns the file of the key, sharded in 256 subdirectories by a hash of the key so a large
+// storage doesn't end in a single huge directory.
+func (provider *Simplefs) entryPath(key string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return filepath.Join(provider.path, fmt.Sprintf("%02x", hash.Sum32()&0xff), url.PathEscape(key))
+}
+
+// walk calls fn with each entry file found in the shard directories. The caller holds the lock.
+func (provider *Simplefs) walk(fn func(key, path string)) {
+	shards, _ := os.ReadDir(provider.path)
+
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 {
+			continue
+		}
+
+		if _, err := strconv.ParseUint(shard.Name(), 16, 8); err != nil {
+			continue
+		}
+
+		files, _ := os.ReadDir(filepath.Join(provider.path, shard.Name()))
+
+		for _, file := range files {
+			if file.IsDir() || strings.Contains(file.Name(), "#") {
+				continue
+			}
+
+			key, err := url.PathUnescape(file.Name())
+			if err != nil {
+				continue
+			}
+
+			fn(key, filepath.Join(provider.path, shard.Name(), file.Name()))
+		}
+	}
+}
+
+// storedKeys returns the keys of the entry files not expired yet, removing the expired ones on the way.
+// The caller holds the lock.
+func (provider *Simplefs) storedKeys() []string {
+	keys := []string{}
+	now := time.Now()
+
+	provider.walk(func(key, path string) {
+		expiresAt, err := readExpiry(path)
+		if err != nil {
+			return
+		}
+
+		if !expiresAt.IsZero() && !now.Before(expiresAt) {
+			provider.remove(key)
+
+			return
+		}
+
+		keys = append(keys, key)
+	})
+
+	return keys
+}
+
+// keys returns the internal keys of the index and the keys stored in files. The caller holds the lock.
+func (provider *Simplefs) keys() []string {
+	keys := provider.storedKeys()
+
+	provider.cache.Range(func(item *ttlcache.Item[string, []byte]) bool {
+		if internalKey(item.Key()) {
+			keys = append(keys, item.Key())
+		}
+
+		return true
+	})
+
+	return keys
+}
+
+// item returns the index item of the key if it's not expired. The entry files missing from the index, like the
+// ones written by a previous instance, are indexed again with their remaining time to live, and the expired ones
+// are removed lazily. The caller holds the lock.
+func (provider *Simplefs) item(key string) *ttlcache.Item[string, []byte] {
+	if result := provider.cache.Get(key, ttlcache.WithDisableTouchOnHit[string, []byte]()); result != nil || internalKey(key) {
+		return result
+	}
+
+	path := provider.entryPath(key)
+
+	expiresAt, err := readExpiry(path)
+	if err != nil {
+		return nil
+	}
+
+	ttl := ttlcache.NoTTL
+	if !expiresAt.IsZero() {
+		if ttl = time.Until(expiresAt); ttl <= 0 {
+			provider.remove(key)
+
+			return nil
+		}
+	}
+
+	return provider.cache.Set(key, []byte(path), ttl)
+}
+
+// value returns the stored value of the key, nil if missing. The caller holds the lock.
+func (provider *Simplefs) value(key string) []byte {
+	result := provider.item(key)
+	if result == nil {
+		return nil
+	}
+
+	if internalKey(key) {
+		return result.Value()
+	}
+
+	byteValue, err := os.ReadFile(string(result.Value()))
+	if err != nil {
+		provider.logger.Errorf("Impossible to read the file %s from Simplefs: %#v", result.Value(), err)
+
+		return nil
+	}
+
+	return byteValue
+}
+
+// put stores the internal keys in the index and the other ones in their entry file written atomically with
+// its expiry sidecar. The caller holds the lock.
+func (provider *Simplefs) put(key string, value []byte, duration time.Duration) error {
+	if internalKey(key) {
+		_ = provider.cache.Set(key, value, duration)
+
+		return nil
+	}
+
+	path := provider.entryPath(key)
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+
+	provider.recoverEnoughSpaceIfNeeded(key, int64(len(value))-previous)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	if err := writeFile(path, value); err != nil {
+		return err
+	}
+
+	provider.actualSize += int64(len(value)) - previous
+
+	return provider.index(key, duration)
+}
+
+// index sets the key in the index for the duration and writes the resulting expiry in the sidecar of its entry
+// file, so both expire together. The caller holds the lock.
+func (provider *Simplefs) index(key string, duration time.Duration) error {
+	path := provider.entryPath(key)
+	item := provider.cache.Set(key, []byte(path), duration)
+
+	var expiresAt int64
+	if !item.ExpiresAt().IsZero() {
+		expiresAt = item.ExpiresAt().UnixNano()
+	}
+
+	if err := writeFile(path+expirySuffix, []byte(strconv.FormatInt(expiresAt, 10))); err != nil {
+		provider.cache.Delete(key)
+
+		return err
+	}
+
+	return nil
+}
+
+// remove drops the key from the index and deletes its files. The caller holds the lock.
+func (provider *Simplefs) remove(key string) {
+	provider.cache.Delete(key)
+
+	if !internalKey(key) {
+		provider.removeFiles(key)
+	}
+}
+
+// removeFiles deletes the entry file of the key and its sidecar. The caller holds the lock.
+func (provider *Simplefs) removeFiles(key string) {
+	path := provider.entryPath(key)
+
+	info, err := os.Stat(path)
+	if err == nil {
+		provider.actualSize -= info.Size()
+		provider.logger.Debugf("Actual size remove: %d, new: %d", provider.actualSize, info.Size())
+	}
+
+	if err := onEvict(path); err != nil {
+		provider.logger.Errorf("impossible to remove the file %s: %#v", key, err)
+	}
+}
+
+// recoverEnoughSpaceIfNeeded removes the least recently used entries until the size fits in the directory
+// size, the key about to be written is kept. The caller holds the lock.
+func (provider *Simplefs) recoverEnoughSpaceIfNeeded(key string, size int64) {
+	for provider.directorySize > -1 && provider.actualSize+size > provider.directorySize {
+		oldest := ""
 
 		provider.cache.RangeBackwards(func(item *ttlcache.Item[string, []byte]) bool {
-			// Remove the oldest item if there is not enough space.
-			//nolint:godox
-			// TODO: open a PR to expose a range that iterate on LRU items.
-			provider.cache.Delete(string(item.Value()))
+			if internalKey(item.Key()) || item.Key() == key {
+				return true
+			}
+
+			oldest = item.Key()
 
 			return false
 		})
 
-		provider.recoverEnoughSpaceIfNeeded(size)
+		if oldest == "" {
+			return
+		}
+
+		provider.remove(oldest)
 	}
 }
+
+// Close method will stop the Simplefs expiration loop.
+func (provider *Simplefs) Close() error {
+	provider.stopped.Do(provider.cache.Stop)
+
+	return nil
+}
@@ -3,7 +3,12 @@ package simplefs_test
 import (
 	"bytes"
 	"fmt"
+	"io/fs"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,7 +25,31 @@ const (
 )
 
 func getSimplefsInstance() (core.Storer, error) {
-	return simplefs.Factory(core.CacheProvider{}, zap.NewNop().Sugar(), 0)
+	return simplefs.Factory(core.CacheProvider{Path: filepath.Join(os.TempDir(), "souin-simplefs")}, zap.NewNop().Sugar(), 0)
+}
+
+func getSimplefsInstanceAt(path string) (core.Storer, error) {
+	return simplefs.Factory(core.CacheProvider{Path: path}, zap.NewNop().Sugar(), 0)
+}
+
+func storedFiles(t *testing.T, path string) []string {
+	t.Helper()
+
+	files := []string{}
+
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			relative, _ := filepath.Rel(path, file)
+			files = append(files, relative)
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Impossible to walk the storage directory: %v", err)
+	}
+
+	return files
 }
 
 // This test ensure that Simplefs options are override by the Souin configuration.
@@ -209,3 +238,175 @@ func TestSimplefs_SetMultiLevel_LargeValue(t *testing.T) {
 			len(largeValue), len(retrieved), float64(len(retrieved))/float64(len(largeValue))*100)
 	}
 }
+
+func TestSimplefs_Stats(t *testing.T) {
+	client, _ := getSimplefsInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestSimplefs_AtomicWrite(t *testing.T) {
+	path := t.TempDir()
+	client, _ := getSimplefsInstanceAt(path)
+
+	defer func() { _ = client.Close() }()
+
+	if err := client.Set("AtomicKey", bytes.Repeat([]byte("a"), 1024), time.Minute); err != nil {
+		t.Fatalf("Impossible to set the key: %v", err)
+	}
+
+	files := storedFiles(t, path)
+	if len(files) != 2 {
+		t.Fatalf("The entry should be stored in a file and its expiry sidecar, %v provided", files)
+	}
+
+	var entry string
+
+	for _, file := range files {
+		if filepath.Dir(file) == "." {
+			t.Errorf("The file %s should be stored in a shard subdirectory", file)
+		}
+
+		if !strings.Contains(file, "#") {
+			entry = filepath.Join(path, file)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 200 {
+			_ = client.Set("AtomicKey", bytes.Repeat([]byte{byte('a' + i%26)}, 1024*(1+i%8)), time.Minute)
+		}
+	}()
+
+	for range 200 {
+		content, err := os.ReadFile(entry)
+		if err != nil {
+			t.Fatalf("The entry file should always exist: %v", err)
+		}
+
+		if len(content)%1024 != 0 || len(bytes.Trim(content, string(content[:1]))) != 0 {
+			t.Fatalf("The entry file should never be read partially written, %d bytes read", len(content))
+		}
+	}
+
+	wg.Wait()
+
+	for _, file := range storedFiles(t, path) {
+		if strings.Contains(file, "#tmp-") {
+			t.Errorf("The temporary file %s should be renamed", file)
+		}
+	}
+}
+
+func TestSimplefs_LazyExpiry(t *testing.T) {
+	path := t.TempDir()
+	client, _ := getSimplefsInstanceAt(path)
+
+	_ = client.Set("ExpiringKey", []byte(baseValue), 200*time.Millisecond)
+	_ = client.Set("PersistentKey", []byte(baseValue), 0)
+	_ = client.Close()
+
+	time.Sleep(300 * time.Millisecond)
+
+	if files := storedFiles(t, path); len(files) != 4 {
+		t.Fatalf("The expired entry should stay on disk until it's read, %v provided", files)
+	}
+
+	reopened, _ := getSimplefsInstanceAt(path)
+
+	defer func() { _ = reopened.Close() }()
+
+	if value := reopened.Get("ExpiringKey"); value != nil {
+		t.Errorf("The key ExpiringKey should be expired, %s provided", value)
+	}
+
+	if files := storedFiles(t, path); len(files) != 2 {
+		t.Errorf("The files of the expired entry should be removed on Get, %v provided", files)
+	}
+
+	if value := reopened.Get("PersistentKey"); string(value) != baseValue {
+		t.Errorf("The key PersistentKey should be loaded from its file, %s provided", value)
+	}
+
+	if ttl, found := reopened.GetTTL("PersistentKey"); !found || ttl != core.NoExpiration {
+		t.Errorf("The key PersistentKey should never expire, %v %v provided", ttl, found)
+	}
+}
+
+func TestSimplefs_LargeValue(t *testing.T) {
+	path := t.TempDir()
+	client, _ := getSimplefsInstanceAt(path)
+
+	largeValue := make([]byte, 16*1024*1024)
+	for i := range largeValue {
+		largeValue[i] = byte(i % 251)
+	}
+
+	if err := client.Set("LargeKey", largeValue, time.Minute); err != nil {
+		t.Fatalf("Impossible to set the large value: %v", err)
+	}
+
+	if !bytes.Equal(client.Get("LargeKey"), largeValue) {
+		t.Error("The large value should be read back entirely")
+	}
+
+	_ = client.Close()
+
+	reopened, _ := getSimplefsInstanceAt(path)
+
+	defer func() { _ = reopened.Close() }()
+
+	if !bytes.Equal(reopened.Get("LargeKey"), largeValue) {
+		t.Error("The large value should be read back entirely from a new instance")
+	}
+}
+
+func TestSimplefs_MapKeys(t *testing.T) {
+	path := t.TempDir()
+	client, _ := getSimplefsInstanceAt(path)
+
+	for i := range 300 {
+		_ = client.Set(fmt.Sprintf("MAP_%d/key?%d", i, i), []byte(fmt.Sprint(i)), time.Minute)
+	}
+
+	_ = client.Set("OTHER_key", []byte(baseValue), time.Minute)
+
+	for _, instance := range []string{"writer", "reopened"} {
+		keys := client.MapKeys("MAP_")
+		if len(keys) != 300 {
+			t.Errorf("The %s instance should map the 300 stored keys, %d provided", instance, len(keys))
+		}
+
+		for i := range 300 {
+			if value := keys[fmt.Sprintf("%d/key?%d", i, i)]; value != fmt.Sprint(i) {
+				t.Errorf("The %s instance should map the key %d to its value, %s provided", instance, i, value)
+			}
+		}
+
+		_ = client.Close()
+		client, _ = getSimplefsInstanceAt(path)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if keys := client.ListKeys(); len(keys) != 301 {
+		t.Errorf("The new instance should list the 301 stored keys, %d provided", len(keys))
+	}
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"net/http"
+
+	caddy "github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/sqlite"
+)
+
+const moduleName = "sqlite"
+
+// SQLite storage.
+type SQLite struct {
+	// Keep the handler configuration.
+	core.Configuration
+}
+
+//nolint:gochecknoinits
+func init() {
+	caddy.RegisterModule(SQLite{})
+}
+
+// CaddyModule returns the Caddy module information.
+func (SQLite) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "storages.cache.sqlite",
+		New: func() caddy.Module { return new(SQLite) },
+	}
+}
+
+// Provision to do the provisioning part.
+func (b *SQLite) Provision(ctx caddy.Context) error {
+	logger := ctx.Logger(b)
+	storer, err := sqlite.Factory(b.Configuration.Provider, logger.Sugar(), b.Configuration.Stale)
+
+	if err != nil {
+		return err
+	}
+
+	core.RegisterStorage(storer)
+
+	return nil
+}
+
+func (b *SQLite) ServeHTTP(rw http.ResponseWriter, rq *http.Request, next caddyhttp.Handler) error {
+	return next.ServeHTTP(rw, rq)
+}
+
+// Interface guards.
+var (
+	_ caddy.Provisioner           = (*SQLite)(nil)
+	_ caddyhttp.MiddlewareHandler = (*SQLite)(nil)
+)
@@ -0,0 +1,804 @@
+//go:build !wasm && !wasi
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	_ "modernc.org/sqlite" // Register the sqlite driver.
+)
+
+const (
+	// DefaultPurgeInterval is the default interval between two expired rows purges.
+	DefaultPurgeInterval = time.Minute
+
+	createTable = `CREATE TABLE IF NOT EXISTS cache (key TEXT PRIMARY KEY, value BLOB, expires_at INTEGER)`
+	notExpired  = `(expires_at IS NULL OR expires_at > ?)`
+	likeEscape  = `\`
+)
+
+// SQLite provider type.
+type SQLite struct {
+	*sql.DB
+
+	stale       time.Duration
+	logger      core.Logger
+	mapper      core.Mapper
+	path        string
+	uid         string
+	compression string
+	clock       core.Clock
+	done        chan struct{}
+}
+
+var enabledSQLiteInstances = sync.Map{}
+
+// Factory function create new SQLite instance.
+func Factory(sqliteConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	if sqliteConfiguration.Validate {
+		if err := core.ValidateConfig(sqliteConfiguration, core.CheckWritablePath); err != nil {
+			logger.Errorf("Impossible to validate the SQLite configuration, %v", err)
+
+			return nil, err
+		}
+	}
+
+	storer, err := factory(sqliteConfiguration, logger, stale)
+	if err != nil {
+		return nil, err
+	}
+
+	return core.Instrument(storer, sqliteConfiguration, logger)
+}
+
+func factory(sqliteConfiguration core.CacheProvider, logger core.Logger, stale time.Duration) (core.Storer, error) {
+	path := sqliteConfiguration.Path
+	purgeInterval := DefaultPurgeInterval
+
+	if sc, ok := sqliteConfiguration.Configuration.(map[string]interface{}); ok && sc != nil {
+		if v, found := sc["path"]; found && v != nil {
+			path = fmt.Sprint(v)
+		}
+
+		if v, found := sc["purge_interval"]; found && v != nil {
+			if d, err := time.ParseDuration(fmt.Sprint(v)); err == nil && d > 0 {
+				purgeInterval = d
+			}
+		}
+	}
+
+	if path == "" {
+		path = ":memory:"
+	}
+
+	uid := path + stale.String()
+
+	if instance, ok := enabledSQLiteInstances.Load(uid); ok {
+		return &SQLite{
+			DB:          instance.(*SQLite).DB,
+			logger:      logger,
+			mapper:      core.ConfiguredMapper(sqliteConfiguration),
+			stale:       stale,
+			path:        path,
+			uid:         uid,
+			compression: core.ConfiguredCompression(sqliteConfiguration, logger),
+			clock:       core.ClockOrDefault(sqliteConfiguration.Clock),
+			done:        instance.(*SQLite).done,
+		}, nil
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=case_sensitive_like(1)")
+	if err != nil {
+		logger.Error("Impossible to open the SQLite DB.", err)
+
+		return nil, err
+	}
+
+	// SQLite allows a single writer, sharing one connection serializes the writes
+	// and keeps the in-memory databases alive.
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(createTable); err != nil {
+		logger.Error("Impossible to create the SQLite cache table.", err)
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	i := &SQLite{
+		DB:          db,
+		logger:      logger,
+		mapper:      core.ConfiguredMapper(sqliteConfiguration),
+		stale:       stale,
+		path:        path,
+		uid:         uid,
+		compression: core.ConfiguredCompression(sqliteConfiguration, logger),
+		clock:       core.ClockOrDefault(sqliteConfiguration.Clock),
+		done:        make(chan struct{}),
+	}
+	enabledSQLiteInstances.Store(uid, i)
+
+	go i.purge(purgeInterval)
+
+	return i, nil
+}
+
+// purge deletes the expired rows periodically, Get already ignores them in between.
+func (provider *SQLite) purge(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-provider.done:
+			return
+		case <-ticker.C:
+			if _, err := provider.Exec(`DELETE FROM cache WHERE expires_at <= ?`, provider.clock.Now().UnixNano()); err != nil {
+				provider.logger.Errorf("Impossible to purge the expired keys in SQLite, %v", err)
+			}
+		}
+	}
+}
+
+// expiresAt converts the duration to the stored expiry, nil means no expiration.
+func (provider *SQLite) expiresAt(duration time.Duration) interface{} {
+	if duration <= 0 {
+		return nil
+	}
+
+	return provider.clock.Now().Add(duration).UnixNano()
+}
+
+// likePrefix escapes the LIKE wildcards of the prefix.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").Replace(prefix)
+}
+
+// Name returns the storer name.
+func (provider *SQLite) Name() string {
+	return "SQLITE"
+}
+
+// Uuid returns an unique identifier.
+func (provider *SQLite) Uuid() string {
+	return fmt.Sprintf("%s-%s", provider.path, provider.stale)
+}
+
+// MapKeys method returns a map with the key and value.
+func (provider *SQLite) MapKeys(prefix string) map[string]string {
+	keys := map[string]string{}
+
+	rows, err := provider.Query(
+		`SELECT key, value FROM cache WHERE key LIKE ? || '%' ESCAPE '`+likeEscape+`' AND `+notExpired,
+		likePrefix(prefix), provider.clock.Now().UnixNano(),
+	)
+	if err != nil {
+		provider.logger.Errorf("Impossible to list the keys matching %s in SQLite, %v", prefix, err)
+
+		return keys
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err = rows.Scan(&key, &value); err == nil && core.ListedKey(prefix, key) {
+			keys[strings.TrimPrefix(key, prefix)] = string(value)
+		}
+	}
+
+	return keys
+}
+
+// ListKeys method returns the list of existing keys.
+func (provider *SQLite) ListKeys() []string {
+	keys := []string{}
+
+	for _, val := range provider.MapKeys(core.MappingKeyPrefix) {
+		mapping, err := provider.mapper.Decode([]byte(val))
+		if err == nil {
+			for _, v := range mapping.GetMapping() {
+				keys = append(keys, v.GetRealKey())
+			}
+		}
+	}
+
+	return keys
+}
+
+// ScanKeys method returns a page of keys matching the prefix starting from the cursor key.
+func (provider *SQLite) ScanKeys(prefix, cursor string, limit int) (keys []string, next string) {
+	keys = []string{}
+
+	query := `SELECT key FROM cache WHERE key LIKE ? || '%' ESCAPE '` + likeEscape + `' AND key >= ? AND ` + notExpired + ` ORDER BY key`
+	args := []interface{}{likePrefix(prefix), max(prefix, cursor), provider.clock.Now().UnixNano()}
+
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit+1)
+	}
+
+	rows, err := provider.Query(query, args...)
+	if err != nil {
+		provider.logger.Errorf("Impossible to scan the keys matching %s in SQLite, %v", prefix, err)
+
+		return keys, next
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	read, last := 0, ""
+
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			continue
+		}
+
+		read, last = read+1, key
+
+		if !core.ListedKey(prefix, key) {
+			continue
+		}
+
+		if limit > 0 && len(keys) == limit {
+			next = key
+
+			break
+		}
+
+		keys = append(keys, key)
+	}
+
+	// The skipped internal keys shortened the page, the scan resumes after the last key read.
+	if next == "" && limit > 0 && read > limit {
+		next = core.NextKey(last)
+	}
+
+	return keys, next
+}
+
+// SortedKeys method returns the keys matching the prefix in lexicographic order.
+func (provider *SQLite) SortedKeys(prefix string) []string {
+	return core.SortedKeys(provider, prefix)
+}
+
+// Get method returns the populated response if exists, empty response then.
+func (provider *SQLite) Get(key string) []byte {
+	result, err := provider.GetContext(context.Background(), key)
+	if err != nil && !errors.Is(err, core.ErrKeyNotFound) {
+		provider.logger.Errorf("Impossible to get the key %s in SQLite, %v", key, err)
+	}
+
+	return result
+}
+
+// GetContext method returns the populated response if exists, the context error is returned if done.
+// The expired rows are ignored until they are purged.
+func (provider *SQLite) GetContext(ctx context.Context, key string) ([]byte, error) {
+	var result []byte
+
+	err := provider.QueryRowContext(ctx, `SELECT value FROM cache WHERE key = ? AND `+notExpired, key, provider.clock.Now().UnixNano()).Scan(&result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, core.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetWithError method returns the value of the key, core.ErrKeyNotFound when missing and the backend error otherwise.
+func (provider *SQLite) GetWithError(key string) ([]byte, error) {
+	return provider.GetContext(context.Background(), key)
+}
+
+// GetMany method returns the values of the existing keys using a single read transaction.
+func (provider *SQLite) GetMany(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+
+	tx, err := provider.Begin()
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys from SQLite, %v", err)
+
+		return result
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`SELECT value FROM cache WHERE key = ? AND ` + notExpired)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the keys from SQLite, %v", err)
+
+		return result
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	now := provider.clock.Now().UnixNano()
+
+	for _, key := range keys {
+		var value []byte
+
+		err = stmt.QueryRow(key, now).Scan(&value)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			provider.logger.Errorf("Impossible to get the keys from SQLite, %v", err)
+
+			return result
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// GetTTL method returns the remaining time to live of the key if exists.
+func (provider *SQLite) GetTTL(key string) (time.Duration, bool) {
+	var expiry sql.NullInt64
+
+	err := provider.QueryRow(`SELECT expires_at FROM cache WHERE key = ? AND `+notExpired, key, provider.clock.Now().UnixNano()).Scan(&expiry)
+	if err != nil {
+		return 0, false
+	}
+
+	if !expiry.Valid {
+		return core.NoExpiration, true
+	}
+
+	return time.Unix(0, expiry.Int64).Sub(provider.clock.Now()), true
+}
+
+// Exists method will check the key in SQLite provider without selecting its value.
+func (provider *SQLite) Exists(key string) bool {
+	var found int
+
+	err := provider.QueryRow(`SELECT 1 FROM cache WHERE key = ? AND `+notExpired, key, provider.clock.Now().UnixNano()).Scan(&found)
+
+	return err == nil
+}
+
+// GetMultiLevel tries to load the key and check if one of linked keys is a fresh/stale candidate.
+func (provider *SQLite) GetMultiLevel(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response) {
+	fresh, stale, _ = provider.GetMultiLevelDebug(key, req, validator)
+
+	return fresh, stale
+}
+
+// GetMultiLevelDebug works like GetMultiLevel and reports the elected varied key with its stored ETag.
+func (provider *SQLite) GetMultiLevelDebug(key string, req *http.Request, validator *core.Revalidator) (fresh *http.Response, stale *http.Response, match core.MultiLevelMatch) {
+	val := provider.Get(core.MappingKeyPrefix + key)
+	if val == nil {
+		provider.logger.Debugf("Impossible to get the mapping key %s in SQLite", core.MappingKeyPrefix+key)
+
+		return fresh, stale, match
+	}
+
+	fresh, stale, match, _ = core.MappingElectionWith(provider.mapper, provider, val, req, validator, provider.logger)
+
+	return fresh, stale, match
+}
+
+// SetMultiLevel tries to store the key with the given value and update the mapping key to store metadata.
+func (provider *SQLite) SetMultiLevel(baseKey, variedKey string, value []byte, variedHeaders http.Header, etag string, duration time.Duration, realKey string) error {
+	duration, cacheable := core.MultiLevelTTL(variedHeaders, duration)
+	if !cacheable {
+		provider.Delete(variedKey)
+
+		return nil
+	}
+
+	now := provider.clock.Now()
+
+	compressed, err := core.Compress(core.MultiLevelCompression(provider.compression, variedHeaders), value)
+	if err != nil {
+		provider.logger.Errorf("Impossible to compress the key %s into SQLite, %v", variedKey, err)
+
+		return err
+	}
+
+	if err = provider.Set(variedKey, compressed, duration+provider.stale); err != nil {
+		return err
+	}
+
+	if err = core.IndexSurrogateKeys(provider, variedKey, value, duration+provider.stale); err != nil {
+		provider.logger.Errorf("Impossible to index the surrogate keys of the key %s in SQLite, %v", variedKey, err)
+
+		return err
+	}
+
+	mappingKey := core.MappingKeyPrefix + baseKey
+
+	val, err := core.MappingUpdaterWith(provider.mapper, variedKey, provider.Get(mappingKey), provider.logger, now, now.Add(duration), now.Add(duration+provider.stale), variedHeaders, etag, realKey)
+	if err != nil {
+		provider.logger.Errorf("Impossible to update the mapping key %s in SQLite, %v", mappingKey, err)
+
+		return err
+	}
+
+	provider.logger.Debugf("Store the new mapping for the key %s in SQLite", variedKey)
+
+	return provider.Set(mappingKey, val, 0)
+}
+
+// Set method will store the response in SQLite provider.
+func (provider *SQLite) Set(key string, value []byte, duration time.Duration) error {
+	return provider.SetContext(context.Background(), key, value, duration)
+}
+
+const upsert = `INSERT INTO cache (key, value, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`
+
+// SetContext method will store the response in SQLite provider unless the context is done.
+func (provider *SQLite) SetContext(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	_, err := provider.ExecContext(ctx, upsert, key, value, provider.expiresAt(duration))
+	if err != nil {
+		provider.logger.Errorf("Impossible to set value into SQLite, %v", err)
+	}
+
+	return err
+}
+
+// SetMany method will store the entries in SQLite provider using a single transaction.
+func (provider *SQLite) SetMany(items map[string]core.Entry) error {
+	tx, err := provider.Begin()
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into SQLite, %v", err)
+
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(upsert)
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into SQLite, %v", err)
+
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for key, item := range items {
+		if _, err = stmt.Exec(key, item.Value, provider.expiresAt(item.Duration)); err != nil {
+			provider.logger.Errorf("Impossible to set the key %s into SQLite, %v", key, err)
+
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		provider.logger.Errorf("Impossible to set values into SQLite, %v", err)
+	}
+
+	return err
+}
+
+// SetNX method will store the response in SQLite provider only if the key doesn't exist yet.
+// An expired row is replaced as if it didn't exist.
+func (provider *SQLite) SetNX(key string, value []byte, duration time.Duration) (bool, error) {
+	res, err := provider.Exec(`INSERT INTO cache (key, value, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at
+WHERE cache.expires_at IS NOT NULL AND cache.expires_at <= ?`, key, value, provider.expiresAt(duration), provider.clock.Now().UnixNano())
+	if err != nil {
+		provider.logger.Errorf("Impossible to set the key %s if not exists into SQLite, %v", key, err)
+
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected == 1, err
+}
+
+// CompareAndSwap method will store the response in SQLite provider only if the stored bytes equal old.
+// The comparison is part of the update statement, a nil old value creates the key like SetNX.
+func (provider *SQLite) CompareAndSwap(key string, old, value []byte, duration time.Duration) (bool, error) {
+	if old == nil {
+		return provider.SetNX(key, value, duration)
+	}
+
+	res, err := provider.Exec(`UPDATE cache SET value = ?, expires_at = ? WHERE key = ? AND value = ? AND `+notExpired,
+		value, provider.expiresAt(duration), key, old, provider.clock.Now().UnixNano())
+	if err != nil {
+		provider.logger.Errorf("Impossible to compare and swap the key %s into SQLite, %v", key, err)
+
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected == 1, err
+}
+
+// Increment method will add delta to the counter stored in SQLite provider within a single transaction.
+// The single shared connection serializes the concurrent increments.
+func (provider *SQLite) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	value, err := provider.increment(key, delta, duration)
+	if err != nil {
+		provider.logger.Errorf("Impossible to increment the key %s into SQLite, %v", key, err)
+	}
+
+	return value, err
+}
+
+func (provider *SQLite) increment(key string, delta int64, duration time.Duration) (int64, error) {
+	tx, err := provider.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	var current []byte
+
+	err = tx.QueryRow(`SELECT value FROM cache WHERE key = ? AND `+notExpired, key, provider.clock.Now().UnixNano()).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	value, encoded, err := core.AddCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.Exec(upsert, key, encoded, provider.expiresAt(duration)); err != nil {
+		return 0, err
+	}
+
+	return value, tx.Commit()
+}
+
+// Decrement method will subtract delta from the counter stored in SQLite provider.
+func (provider *SQLite) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return provider.Increment(key, -delta, duration)
+}
+
+// SetStream method will compress the reader content incrementally and store it in SQLite provider.
+func (provider *SQLite) SetStream(key string, reader io.Reader, duration time.Duration) error {
+	err := core.CompressStream(provider.compression, reader, func(compressed []byte) error {
+		return provider.Set(key, compressed, duration)
+	})
+	if err != nil {
+		provider.logger.Errorf("Impossible to stream the key %s into SQLite, %v", key, err)
+	}
+
+	return err
+}
+
+// GetStream method returns a reader decompressing lazily the value stored by SetStream.
+func (provider *SQLite) GetStream(key string) (io.ReadCloser, error) {
+	value := provider.Get(key)
+	if value == nil {
+		return nil, core.ErrKeyNotFound
+	}
+
+	return core.DecompressStream(bytes.NewReader(value))
+}
+
+// Touch method will update the time to live of the key without altering its value.
+func (provider *SQLite) Touch(key string, duration time.Duration) error {
+	now := provider.clock.Now().UnixNano()
+
+	res, err := provider.Exec(`UPDATE cache SET expires_at = ? WHERE key = ? AND `+notExpired, provider.expiresAt(duration), key, now)
+	if err != nil {
+		provider.logger.Errorf("Impossible to touch the key %s into SQLite, %v", key, err)
+
+		return err
+	}
+
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return core.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// Delete method will delete the response in SQLite provider if exists corresponding to key param.
+func (provider *SQLite) Delete(key string) {
+	core.UnindexSurrogateKeys(provider, key)
+	core.DeleteEntryMeta(provider, key)
+
+	if _, err := provider.Exec(`DELETE FROM cache WHERE key = ?`, key); err != nil {
+		provider.logger.Errorf("Impossible to delete the key %s in SQLite, %v", key, err)
+	}
+}
+
+// DeleteMany method will delete the responses in SQLite provider if exists corresponding to the prefix pattern param.
+func (provider *SQLite) DeleteMany(pattern string) {
+	_, err := provider.Exec(`DELETE FROM cache WHERE key LIKE ? || '%' ESCAPE '`+likeEscape+`'`, likePrefix(core.KeyPrefix(pattern)))
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys matching %s in SQLite, %v", pattern, err)
+	}
+}
+
+// DeleteKeys method will delete the keys with their metadata in SQLite provider using a single transaction.
+// The missing keys are ignored.
+func (provider *SQLite) DeleteKeys(keys []string) error {
+	deleted, unindexErr := core.KeysToDelete(provider, keys)
+
+	tx, err := provider.Begin()
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in SQLite, %v", err)
+
+		return err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`DELETE FROM cache WHERE key = ?`)
+	if err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in SQLite, %v", err)
+
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for _, key := range deleted {
+		if _, err = stmt.Exec(key); err != nil {
+			provider.logger.Errorf("Impossible to delete the key %s in SQLite, %v", key, err)
+
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		provider.logger.Errorf("Impossible to delete the keys in SQLite, %v", err)
+
+		return err
+	}
+
+	return unindexErr
+}
+
+// InvalidateSurrogate method will delete the responses tagged by the surrogate key in SQLite provider.
+func (provider *SQLite) InvalidateSurrogate(surrogateKey string) (int, error) {
+	return core.InvalidateSurrogateKeys(provider, surrogateKey)
+}
+
+// ListSurrogates method returns the surrogate keys indexed in SQLite provider.
+func (provider *SQLite) ListSurrogates() ([]string, error) {
+	return core.ListSurrogateKeys(provider)
+}
+
+// GetBySurrogate method returns the values of the responses tagged by the surrogate key in SQLite provider.
+func (provider *SQLite) GetBySurrogate(surrogateKey string) (map[string][]byte, error) {
+	return core.GetSurrogateEntries(provider, surrogateKey)
+}
+
+// Inspect method returns the details of the key stored in SQLite provider without altering it.
+func (provider *SQLite) Inspect(key string) (*core.EntryInfo, error) {
+	return core.InspectEntry(provider, provider.mapper, key)
+}
+
+// SetWithMeta method will store the response in SQLite provider and its metadata under a parallel key.
+func (provider *SQLite) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	return core.SetWithEntryMeta(provider, key, value, meta, duration)
+}
+
+// GetMeta method returns the metadata stored by SetWithMeta in SQLite provider, core.ErrKeyNotFound if none.
+func (provider *SQLite) GetMeta(key string) (map[string]string, error) {
+	return core.GetEntryMeta(provider, key)
+}
+
+// Stats method returns the number of live keys using the primary key index and the database size from its page count.
+func (provider *SQLite) Stats() (core.StorageStats, error) {
+	stats := core.StorageStats{}
+
+	err := provider.QueryRow(`SELECT COUNT(*) FROM cache WHERE `+notExpired, provider.clock.Now().UnixNano()).Scan(&stats.KeyCount)
+	if err != nil {
+		provider.logger.Errorf("Impossible to count the keys in SQLite, %v", err)
+
+		return stats, err
+	}
+
+	err = provider.QueryRow(`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`).Scan(&stats.ApproxSizeBytes)
+	if err != nil {
+		provider.logger.Errorf("Impossible to get the SQLite database size, %v", err)
+	}
+
+	return stats, err
+}
+
+// Ping method will check the SQLite DB connection is alive.
+func (provider *SQLite) Ping(ctx context.Context) error {
+	return provider.PingContext(ctx)
+}
+
+// Export method will stream every unexpired key of the SQLite provider with a single query.
+func (provider *SQLite) Export(w io.Writer) error {
+	now := provider.clock.Now()
+
+	rows, err := provider.Query(`SELECT key, value, expires_at FROM cache WHERE `+notExpired, now.UnixNano())
+	if err != nil {
+		provider.logger.Errorf("Impossible to export the SQLite keys, %v", err)
+
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	exporter := core.NewExportWriter(w)
+
+	for rows.Next() {
+		var (
+			key    string
+			value  []byte
+			expiry sql.NullInt64
+		)
+
+		if err = rows.Scan(&key, &value, &expiry); err != nil {
+			provider.logger.Errorf("Impossible to export the SQLite keys, %v", err)
+
+			return err
+		}
+
+		ttl := core.NoExpiration
+		if expiry.Valid {
+			ttl = time.Unix(0, expiry.Int64).Sub(now)
+		}
+
+		if err = exporter.Write(key, value, ttl); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		provider.logger.Errorf("Impossible to export the SQLite keys, %v", err)
+
+		return err
+	}
+
+	return exporter.Flush()
+}
+
+// Import method will store the exported records in SQLite provider by transactions.
+func (provider *SQLite) Import(r io.Reader) error {
+	return core.ImportRecords(r, provider.SetMany)
+}
+
+// Init method will.
+func (provider *SQLite) Init() error {
+	return nil
+}
+
+// Reset method will reset or close provider.
+func (provider *SQLite) Reset() error {
+	_, err := provider.Exec(`DELETE FROM cache`)
+
+	return err
+}
+
+// Close method will stop the purge loop and close the SQLite DB, the next Factory call reopens it.
+func (provider *SQLite) Close() error {
+	instance, ok := enabledSQLiteInstances.Load(provider.uid)
+	if !ok || instance.(*SQLite).DB != provider.DB || !enabledSQLiteInstances.CompareAndDelete(provider.uid, instance) {
+		return core.ErrClosed
+	}
+
+	close(provider.done)
+
+	return provider.DB.Close()
+}
@@ -0,0 +1,605 @@
+//go:build !wasm && !wasi
+
+package sqlite_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkweak/storages/core"
+	"github.com/darkweak/storages/sqlite"
+	"go.uber.org/zap"
+)
+
+const (
+	byteKey        = "MyByteKey"
+	nonExistentKey = "NonExistentKey"
+	baseValue      = "My first data"
+)
+
+func getSQLiteInstance() (core.Storer, error) {
+	return sqlite.Factory(core.CacheProvider{}, zap.NewNop().Sugar(), 0)
+}
+
+func TestSQLiteConnectionFactory(t *testing.T) {
+	instance, err := getSQLiteInstance()
+
+	if nil != err {
+		t.Errorf("Shouldn't have panic: %v", err)
+	}
+
+	if nil == instance {
+		t.Error("SQLite should be instanciated")
+	}
+}
+
+func TestIShouldBeAbleToReadAndWriteDataInSQLite(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	_ = client.Set("Test", []byte(baseValue), time.Duration(20)*time.Second)
+
+	res := client.Get("Test")
+	if len(res) == 0 {
+		t.Errorf("Key %s should exist", baseValue)
+	}
+
+	if baseValue != string(res) {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+}
+
+func TestSQLite_GetRequestInCache(t *testing.T) {
+	client, _ := getSQLiteInstance()
+	res := client.Get(nonExistentKey)
+
+	if 0 < len(res) {
+		t.Errorf("Key %s should not exist", nonExistentKey)
+	}
+}
+
+func TestSQLite_SetRequestInCache_TTL(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	_ = client.Set(byteKey, []byte(baseValue), time.Second)
+
+	if res := client.Get(byteKey); string(res) != baseValue {
+		t.Errorf("%s not corresponding to %s", string(res), baseValue)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	if res := client.Get(byteKey); res != nil {
+		t.Errorf("Key %s should be expired, %s provided", byteKey, res)
+	}
+
+	if _, found := client.GetTTL(byteKey); found {
+		t.Errorf("Key %s should be expired", byteKey)
+	}
+
+	if err := client.Touch(byteKey, time.Minute); !errors.Is(err, core.ErrKeyNotFound) {
+		t.Errorf("Touching the expired key %s should return ErrKeyNotFound, %v provided", byteKey, err)
+	}
+}
+
+func TestSQLite_PurgeExpired(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{
+		Path:          t.TempDir() + "/purge.db",
+		Configuration: map[string]interface{}{"purge_interval": "100ms"},
+	}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	_ = client.Set("PurgedKey", []byte(baseValue), 50*time.Millisecond)
+	_ = client.Set("KeptKey", []byte(baseValue), time.Minute)
+
+	time.Sleep(500 * time.Millisecond)
+
+	db, ok := client.(*sqlite.SQLite)
+	if !ok {
+		t.Fatal("The storer should be a SQLite instance")
+	}
+
+	var count int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM cache`).Scan(&count); err != nil || count != 1 {
+		t.Errorf("The expired row should have been purged, %d rows found: %v", count, err)
+	}
+}
+
+func TestSQLite_Clock(t *testing.T) {
+	path := t.TempDir() + "/clock.db"
+	clock := core.NewManualClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	client, err := sqlite.Factory(core.CacheProvider{Path: path, Clock: clock}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	_ = client.Set("ClockKey", []byte(baseValue), 10*time.Second)
+
+	if ttl, found := client.GetTTL("ClockKey"); !found || ttl != 10*time.Second {
+		t.Errorf("The TTL should be exactly 10s on the stopped clock, %v provided", ttl)
+	}
+
+	// A peer whose clock is 30s ahead already sees the key as expired.
+	skewed, err := sqlite.Factory(core.CacheProvider{Path: path, Clock: core.NewManualClock(clock.Now().Add(30 * time.Second))}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the skewed sqlite instance: %v", err)
+	}
+
+	if skewed.Get("ClockKey") != nil {
+		t.Error("The key should be expired for the skewed clock")
+	}
+
+	clock.Advance(9 * time.Second)
+
+	if !bytes.Equal(client.Get("ClockKey"), []byte(baseValue)) {
+		t.Error("The key should still be alive after 9s")
+	}
+
+	clock.Advance(time.Second)
+
+	if client.Get("ClockKey") != nil || client.Exists("ClockKey") {
+		t.Error("The key should be expired after 10s")
+	}
+}
+
+func TestSQLite_TTLJitter(t *testing.T) {
+	clock := core.NewManualClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/jitter.db", Clock: clock, TTLJitter: 0.1, TTLJitterSeed: 1}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	lowest, highest := time.Hour, time.Duration(0)
+
+	for i := range 200 {
+		key := fmt.Sprintf("JitterKey%d", i)
+		_ = client.Set(key, []byte(baseValue), 100*time.Second)
+
+		ttl, found := client.GetTTL(key)
+		if !found || ttl < 90*time.Second || ttl > 110*time.Second {
+			t.Fatalf("The key %s should expire within ±10%% of 100s, %v provided", key, ttl)
+		}
+
+		lowest, highest = min(lowest, ttl), max(highest, ttl)
+	}
+
+	if lowest > 92*time.Second || highest < 108*time.Second {
+		t.Errorf("The expiries should be spread across the window, %v to %v provided", lowest, highest)
+	}
+}
+
+func TestSQLite_GetTTL(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	_ = client.Set("TTLKey", []byte(baseValue), 20*time.Second)
+
+	ttl, found := client.GetTTL("TTLKey")
+	if !found {
+		t.Error("Key TTLKey should exist")
+	}
+
+	if ttl <= 0 || ttl > 20*time.Second {
+		t.Errorf("The TTL should be between 0 and 20s, %v provided", ttl)
+	}
+
+	_ = client.SetMultiLevel("TTLMappedKey", "TTLMappedKey", []byte(baseValue), http.Header{}, "", time.Minute, "TTLMappedKey")
+
+	if ttl, found = client.GetTTL(core.MappingKeyPrefix + "TTLMappedKey"); !found || ttl != core.NoExpiration {
+		t.Errorf("The mapping key should not expire, %v provided", ttl)
+	}
+}
+
+func TestSQLite_SetMultiLevel_LargeValue(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	largeValue := make([]byte, 5*1024*1024)
+	for i := range largeValue {
+		largeValue[i] = byte(i % 256)
+	}
+
+	key := "large_value_test"
+
+	if err := client.SetMultiLevel(key, key, largeValue, http.Header{}, "", time.Minute, key); err != nil {
+		t.Fatalf("Failed to set large value: %v", err)
+	}
+
+	decompressed, err := core.Decompress(client.Get(key))
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+
+	if !bytes.Equal(largeValue, decompressed) {
+		t.Errorf("Data truncation: expected %d bytes, got %d bytes", len(largeValue), len(decompressed))
+	}
+}
+
+func TestSQLite_GetMultiLevelDebug(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	for _, encoding := range []string{"gzip", "br"} {
+		variedKey := "DebugKey-" + encoding
+		response := "HTTP/1.1 200 OK\r\nContent-Encoding: " + encoding + "\r\n\r\n" + baseValue
+		headers := http.Header{"Accept-Encoding": []string{encoding}}
+
+		if err := client.SetMultiLevel("DebugKey", variedKey, []byte(response), headers, `"etag-`+encoding+`"`, time.Minute, variedKey); err != nil {
+			t.Fatalf("Impossible to store the variant %s: %v", variedKey, err)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/debug", nil)
+	req.Header.Set("Accept-Encoding", "br")
+
+	fresh, _, match := client.GetMultiLevelDebug("DebugKey", req, &core.Revalidator{})
+	if fresh == nil || fresh.Header.Get("Content-Encoding") != "br" {
+		t.Fatal("The br variant should be returned as fresh")
+	}
+
+	if match.VariedKey != "DebugKey-br" || match.ETag != `"etag-br"` || match.Stale {
+		t.Errorf("The br variant should be reported as the elected key, %+v provided", match)
+	}
+}
+
+func TestSQLite_JSONMapper(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/mapper.db", Mapper: core.JSONMapper{}}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	response := "HTTP/1.1 200 OK\r\n\r\n" + baseValue
+	if err = client.SetMultiLevel("JSONKey", "JSONKey-varied", []byte(response), http.Header{}, "", time.Minute, "JSONKey"); err != nil {
+		t.Fatalf("Impossible to store the response: %v", err)
+	}
+
+	if mapping := client.Get(core.MappingKeyPrefix + "JSONKey"); !bytes.HasPrefix(mapping, []byte("{")) {
+		t.Errorf("The mapping should be stored as JSON, %s provided", mapping)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://domain.com/json", nil)
+
+	if fresh, _ := client.GetMultiLevel("JSONKey", req, &core.Revalidator{}); fresh == nil {
+		t.Error("The response should be found through the JSON mapping")
+	}
+
+	if keys := client.ListKeys(); len(keys) != 1 || keys[0] != "JSONKey" {
+		t.Errorf("The real key should be listed from the JSON mapping, %v provided", keys)
+	}
+}
+
+func TestSQLite_ConcurrentWrites(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/concurrent.db"}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := range 50 {
+				if err := client.Set(fmt.Sprintf("ConcurrentKey%d_%d", i, j), []byte(baseValue), time.Minute); err != nil {
+					t.Errorf("Impossible to set the key ConcurrentKey%d_%d: %v", i, j, err)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if keys := client.MapKeys("ConcurrentKey"); len(keys) != 1000 {
+		t.Errorf("The 1000 keys should have been stored, %d provided", len(keys))
+	}
+}
+
+func TestSQLite_MapKeys(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	_ = client.Set("MAP_first_1", []byte("1"), time.Minute)
+	_ = client.Set("MAP_first_2", []byte("2"), time.Minute)
+	_ = client.Set("MAPXfirstX3", []byte("3"), time.Minute)
+	_ = client.Set("map_first_4", []byte("4"), time.Minute)
+
+	keys := client.MapKeys("MAP_first_")
+	if len(keys) != 2 || keys["1"] != "1" || keys["2"] != "2" {
+		t.Errorf("The LIKE wildcards should be escaped, %v provided", keys)
+	}
+}
+
+func TestSQLite_ScanKeys(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	items := make(map[string]core.Entry, 1000)
+	for i := range 1000 {
+		items[fmt.Sprintf("ScanKey%04d", i)] = core.Entry{Value: []byte(baseValue), Duration: time.Minute}
+	}
+
+	_ = client.SetMany(items)
+	_ = client.Set("ScanOtherKey", []byte(baseValue), time.Minute)
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("The pagination should have ended after 10 pages")
+		}
+
+		keys, next := client.ScanKeys("ScanKey", cursor, 100)
+		if len(keys) > 100 {
+			t.Errorf("The page should contain at most 100 keys, %d provided", len(keys))
+		}
+
+		for _, key := range keys {
+			if _, ok := items[key]; !ok {
+				t.Errorf("The key %s should not be part of the scan", key)
+			}
+
+			if seen[key] {
+				t.Errorf("The key %s has already been returned", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != len(items) {
+		t.Errorf("The scan should return %d keys, %d provided", len(items), len(seen))
+	}
+}
+
+func TestSQLite_DeleteKeys(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/delete.db"}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the SQLite instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 4 {
+		_ = client.SetWithMeta(fmt.Sprintf("DeleteKeys_%d", i), []byte(baseValue), map[string]string{"index": fmt.Sprint(i)}, time.Minute)
+	}
+
+	if err = client.DeleteKeys([]string{"DeleteKeys_0", nonExistentKey, "DeleteKeys_2"}); err != nil {
+		t.Fatalf("The missing keys should be ignored: %v", err)
+	}
+
+	for i, deleted := range []bool{true, false, true, false} {
+		key := fmt.Sprintf("DeleteKeys_%d", i)
+		if client.Exists(key) == deleted {
+			t.Errorf("The key %s should be deleted: %t", key, deleted)
+		}
+
+		if _, err = client.GetMeta(key); errors.Is(err, core.ErrKeyNotFound) != deleted {
+			t.Errorf("The metadata of the key %s should be deleted: %t, %v provided", key, deleted, err)
+		}
+	}
+}
+
+func TestSQLite_ScanKeys_InternalKeys(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/internal.db"}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Impossible to create the SQLite instance: %v", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	for i := range 10 {
+		key := fmt.Sprintf("UserKey_%d", i)
+		if err = client.SetMultiLevel(key, key, []byte(baseValue), http.Header{}, "", time.Minute, key); err != nil {
+			t.Fatalf("Impossible to set the multi-level key %s: %v", key, err)
+		}
+	}
+
+	if keys := client.MapKeys(""); len(keys) != 10 || keys["UserKey_0"] == "" {
+		t.Errorf("MapKeys should only return the 10 user keys, %v provided", keys)
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+
+	// The mapping keys sorted before the user keys must not stop the pagination.
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatal("The pagination should have ended after 20 pages")
+		}
+
+		keys, next := client.ScanKeys("", cursor, 3)
+		for _, key := range keys {
+			if core.IsInternalKey(key) || seen[key] {
+				t.Errorf("The key %s should not be part of the scan", key)
+			}
+
+			seen[key] = true
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("The scan should return the 10 user keys, %d provided", len(seen))
+	}
+
+	if keys, _ := client.ScanKeys(core.MappingKeyPrefix, "", 0); len(keys) != 10 {
+		t.Errorf("The mapping keys should be scanned with the mapping prefix, %v provided", keys)
+	}
+}
+func TestSQLite_DeleteMany(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	for i := range 10 {
+		_ = client.Set(fmt.Sprintf("SURROGATE_first_%d", i), []byte(baseValue), time.Minute)
+		_ = client.Set(fmt.Sprintf("SURROGATE_second_%d", i), []byte(baseValue), time.Minute)
+	}
+
+	client.DeleteMany("SURROGATE_first_*")
+
+	if keys, _ := client.ScanKeys("SURROGATE_first_", "", 0); len(keys) != 0 {
+		t.Errorf("The keys %v should have been deleted", keys)
+	}
+
+	if keys, _ := client.ScanKeys("SURROGATE_second_", "", 0); len(keys) != 10 {
+		t.Errorf("The 10 keys with the other prefix should be kept, %d provided", len(keys))
+	}
+}
+
+func TestSQLite_SetNX(t *testing.T) {
+	client, _ := getSQLiteInstance()
+	client.Delete("SetNXKey")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := client.SetNX("SetNXKey", []byte(fmt.Sprintf("worker %d", i)), time.Minute)
+			if err != nil {
+				t.Errorf("Impossible to set the key SetNXKey if not exists: %v", err)
+			}
+
+			if ok {
+				created.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("Exactly one worker should have created the key, %d provided", created.Load())
+	}
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); ok {
+		t.Error("The existing key SetNXKey shouldn't be overridden")
+	}
+
+	client.Delete("SetNXKey")
+
+	if ok, _ := client.SetNX("SetNXKey", []byte(baseValue), time.Minute); !ok {
+		t.Error("The deleted key SetNXKey should be created again")
+	}
+}
+
+func TestSQLite_Increment(t *testing.T) {
+	client, _ := getSQLiteInstance()
+	client.Delete("CounterKey")
+
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Increment("CounterKey", 1, time.Minute); err != nil {
+				t.Errorf("Impossible to increment the key CounterKey: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := core.DecodeCounter(client.Get("CounterKey")); value != 100 {
+		t.Errorf("The 100 concurrent increments should be counted, %d provided", value)
+	}
+
+	if value, _ := client.Decrement("CounterKey", 30, time.Minute); value != 70 {
+		t.Errorf("The counter should be decremented to 70, %d provided", value)
+	}
+
+	_ = client.Set("NotCounterKey", []byte(baseValue), time.Minute)
+
+	if _, err := client.Increment("NotCounterKey", 1, time.Minute); !errors.Is(err, core.ErrNotCounter) {
+		t.Errorf("Incrementing a value that isn't a counter should return ErrNotCounter, %v provided", err)
+	}
+}
+
+func TestSQLite_Stats(t *testing.T) {
+	client, _ := getSQLiteInstance()
+
+	for i := range 100 {
+		_ = client.Set(fmt.Sprintf("StatsKey%d", i), []byte(baseValue), time.Minute)
+	}
+
+	stats, err := client.Stats()
+	if err != nil {
+		t.Fatalf("Impossible to get the stats: %v", err)
+	}
+
+	if stats.KeyCount < 100 {
+		t.Errorf("The stats should count at least the 100 stored keys, %d provided", stats.KeyCount)
+	}
+}
+
+func TestSQLite_Ping(t *testing.T) {
+	client, err := sqlite.Factory(core.CacheProvider{Path: t.TempDir() + "/ping.db"}, zap.NewNop().Sugar(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite instance: %v", err)
+	}
+
+	if err = client.Ping(context.Background()); err != nil {
+		t.Errorf("Pinging a healthy store shouldn't fail: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err = client.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pinging with a done context should return its error, %v provided", err)
+	}
+
+	_ = client.Close()
+
+	if err = client.Ping(context.Background()); err == nil {
+		t.Error("Pinging a closed store should fail")
+	}
+}
+
+func TestSQLite_Exists(t *testing.T) {
+	client, _ := getSQLiteInstance()
+	_ = client.Set("ExistsKey", []byte(baseValue), time.Minute)
+	_ = client.Set("ExistsExpiredKey", []byte(baseValue), 500*time.Millisecond)
+
+	if !client.Exists("ExistsKey") {
+		t.Error("The key ExistsKey should exist")
+	}
+
+	if client.Exists(nonExistentKey) {
+		t.Errorf("The key %s should not exist", nonExistentKey)
+	}
+
+	time.Sleep(time.Second)
+
+	if client.Exists("ExistsExpiredKey") {
+		t.Error("The expired key ExistsExpiredKey should not exist")
+	}
+}